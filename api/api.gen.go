@@ -8,8 +8,8 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/url"
 	"path"
@@ -26,6 +26,58 @@ const (
 	BearerAuthScopes = "BearerAuth.Scopes"
 )
 
+// Defines values for ChatActivityActivity.
+const (
+	ChatActivityActivityRecording ChatActivityActivity = "recording"
+	ChatActivityActivityStopped   ChatActivityActivity = "stopped"
+	ChatActivityActivityTyping    ChatActivityActivity = "typing"
+)
+
+// Defines values for ChatActivityRequestActivity.
+const (
+	ChatActivityRequestActivityRecording ChatActivityRequestActivity = "recording"
+	ChatActivityRequestActivityStopped   ChatActivityRequestActivity = "stopped"
+	ChatActivityRequestActivityTyping    ChatActivityRequestActivity = "typing"
+)
+
+// Defines values for MediaScanStatus.
+const (
+	Clean    MediaScanStatus = "clean"
+	Failed   MediaScanStatus = "failed"
+	Infected MediaScanStatus = "infected"
+	Pending  MediaScanStatus = "pending"
+)
+
+// Defines values for MessageCreateRequestMessageType.
+const (
+	MessageCreateRequestMessageTypeEncrypted MessageCreateRequestMessageType = "encrypted"
+	MessageCreateRequestMessageTypeText      MessageCreateRequestMessageType = "text"
+	MessageCreateRequestMessageTypeVoiceNote MessageCreateRequestMessageType = "voice_note"
+)
+
+// Defines values for StorageUsagePlanType.
+const (
+	Basic      StorageUsagePlanType = "basic"
+	Enterprise StorageUsagePlanType = "enterprise"
+	Free       StorageUsagePlanType = "free"
+	Premium    StorageUsagePlanType = "premium"
+)
+
+// Defines values for UploadStatus.
+const (
+	Completed UploadStatus = "completed"
+	Uploading UploadStatus = "uploading"
+)
+
+// Defines values for GetChatGalleryParamsType.
+const (
+	Documents GetChatGalleryParamsType = "documents"
+	Links     GetChatGalleryParamsType = "links"
+	Photos    GetChatGalleryParamsType = "photos"
+	Videos    GetChatGalleryParamsType = "videos"
+	Voice     GetChatGalleryParamsType = "voice"
+)
+
 // AuthLogin defines model for AuthLogin.
 type AuthLogin struct {
 	// AccessToken JWT access token used in authentication
@@ -35,21 +87,15 @@ type AuthLogin struct {
 	RefreshToken *string `json:"refreshToken,omitempty"`
 }
 
-// AuthLoginEmailRequest defines model for AuthLoginEmailRequest.
-type AuthLoginEmailRequest struct {
-	// Email The email address of the user.
+// AuthLoginRequest defines model for AuthLoginRequest.
+type AuthLoginRequest struct {
+	// Email The email address of the user, either the email or the phone number identifies the account.
 	Email *openapi_types.Email `json:"email,omitempty"`
 
 	// Password The password of the user.
-	Password *string `json:"password,omitempty"`
-}
-
-// AuthLoginPhoneNumberRequest defines model for AuthLoginPhoneNumberRequest.
-type AuthLoginPhoneNumberRequest struct {
-	// Password The password of the user.
-	Password *string `json:"password,omitempty"`
+	Password string `json:"password"`
 
-	// PhoneNumber The phone number of the user.
+	// PhoneNumber The phone number of the user, either the email or the phone number identifies the account.
 	PhoneNumber *string `json:"phoneNumber,omitempty"`
 }
 
@@ -64,21 +110,15 @@ type AuthLoginResponse struct {
 	Success *bool `json:"success,omitempty"`
 }
 
-// AuthRegisterEmailRequest defines model for AuthRegisterEmailRequest.
-type AuthRegisterEmailRequest struct {
-	// Email The email address of the user.
+// AuthRegisterRequest defines model for AuthRegisterRequest.
+type AuthRegisterRequest struct {
+	// Email The email address of the user, either the email or the phone number is registered.
 	Email *openapi_types.Email `json:"email,omitempty"`
 
 	// Password The password of the user.
-	Password *string `json:"password,omitempty"`
-}
-
-// AuthRegisterPhoneNumberRequest defines model for AuthRegisterPhoneNumberRequest.
-type AuthRegisterPhoneNumberRequest struct {
-	// Password The password of the user.
-	Password *string `json:"password,omitempty"`
+	Password string `json:"password"`
 
-	// PhoneNumber The phone number of the user.
+	// PhoneNumber The phone number of the user, either the email or the phone number is registered.
 	PhoneNumber *string `json:"phoneNumber,omitempty"`
 }
 
@@ -106,6 +146,39 @@ type Chat struct {
 	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
 }
 
+// ChatActivity defines model for ChatActivity.
+type ChatActivity struct {
+	Activity ChatActivityActivity `json:"activity"`
+	ChatId   string               `json:"chatId"`
+
+	// ExpiresAt When the activity expires unless set again, absent once stopped.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	UserId    string     `json:"userId"`
+}
+
+// ChatActivityActivity defines model for ChatActivity.Activity.
+type ChatActivityActivity string
+
+// ChatActivityRequest defines model for ChatActivityRequest.
+type ChatActivityRequest struct {
+	// Activity What the user is doing in the chat, recording is recording a voice note.
+	Activity ChatActivityRequestActivity `json:"activity"`
+}
+
+// ChatActivityRequestActivity What the user is doing in the chat, recording is recording a voice note.
+type ChatActivityRequestActivity string
+
+// ChatActivityResponse defines model for ChatActivityResponse.
+type ChatActivityResponse struct {
+	Data *ChatActivity `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
+}
+
 // ChatCreateRequest defines model for ChatCreateRequest.
 type ChatCreateRequest struct {
 	// ChatName The name of the chat (for group chats).
@@ -118,6 +191,14 @@ type ChatCreateRequest struct {
 	Participants []string `json:"participants"`
 }
 
+// ChatGallery defines model for ChatGallery.
+type ChatGallery struct {
+	Items []GalleryItem `json:"items"`
+
+	// NextCursor The cursor of the next page, missing on the last page.
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
 // ChatGroup defines model for ChatGroup.
 type ChatGroup struct {
 	// AdminIds The IDs of the admins of the chat group.
@@ -202,8 +283,29 @@ type ChatUpdateRequest struct {
 	Participants *[]string `json:"participants,omitempty"`
 }
 
+// DeviceCiphertext defines model for DeviceCiphertext.
+type DeviceCiphertext struct {
+	// Body The base64 encoded opaque ciphertext.
+	Body string `json:"body"`
+
+	// DeviceId The ID of the recipient's device the payload is addressed to.
+	DeviceId string `json:"deviceId"`
+
+	// RecipientId The ID of the user the payload is addressed to.
+	RecipientId string `json:"recipientId"`
+
+	// Type The session message type as defined by the client protocol e.g. prekey or normal.
+	Type int `json:"type"`
+}
+
 // ErrorGenericResponse defines model for ErrorGenericResponse.
 type ErrorGenericResponse struct {
+	// Code stable machine-readable error code clients can localize
+	Code *string `json:"code,omitempty"`
+
+	// Details per-field validation failures
+	Details *[]FieldError `json:"details,omitempty"`
+
 	// Message description of process outcome
 	Message string `json:"message"`
 
@@ -211,6 +313,38 @@ type ErrorGenericResponse struct {
 	Success bool `json:"success"`
 }
 
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Code stable machine-readable reason
+	Code string `json:"code"`
+
+	// Field the offending request field, dotted for nested fields
+	Field string `json:"field"`
+
+	// Message human-readable reason
+	Message string `json:"message"`
+}
+
+// GalleryItem defines model for GalleryItem.
+type GalleryItem struct {
+	// Links The links of the message, set when listing links.
+	Links *[]string `json:"links,omitempty"`
+	Media *Media    `json:"media,omitempty"`
+
+	// MessageId The ID of the message that shared the media or links.
+	MessageId string    `json:"messageId"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// GenericSuccessResponse defines model for GenericSuccessResponse.
+type GenericSuccessResponse struct {
+	// Message description of process outcome
+	Message string `json:"message"`
+
+	// Success Is the response a success response
+	Success bool `json:"success"`
+}
+
 // GlobalResponses defines model for GlobalResponses.
 type GlobalResponses struct {
 	// ResponseCode The response code.
@@ -240,29 +374,76 @@ type Highlight struct {
 
 // Media defines model for Media.
 type Media struct {
+	// Blurhash Compact placeholder of images & videos to show while they load, see https://blurha.sh.
+	Blurhash *string `json:"blurhash,omitempty"`
+
 	// ChatId The ID of the chat the media is associated with.
 	ChatId *string `json:"chatId,omitempty"`
 
+	// ContentType The MIME type detected from the content of the file.
+	ContentType *string `json:"contentType,omitempty"`
+
+	// DurationMs Duration of audio & video in milliseconds.
+	DurationMs *int64 `json:"durationMs,omitempty"`
+
 	// FileName The name of the media file.
 	FileName *string `json:"fileName,omitempty"`
 
 	// FileSize The size of the media file in bytes.
 	FileSize *int `json:"fileSize,omitempty"`
 
+	// Height Height in pixels of images & videos as displayed.
+	Height *int `json:"height,omitempty"`
+
 	// Id The unique identifier for the media.
 	Id *string `json:"id,omitempty"`
 
-	// MediaType The type of media.
+	// MediaType The type of media, one of image, video, audio or document.
 	MediaType *string `json:"mediaType,omitempty"`
 
-	// MediaUrl URL to the media file.
+	// MediaUrl Signed URL to download the media file, it works without a token until mediaUrlExpiresAt.
 	MediaUrl *string `json:"mediaUrl,omitempty"`
 
+	// MediaUrlExpiresAt When mediaUrl stops working, fetch the media again for a new URL.
+	MediaUrlExpiresAt *time.Time `json:"mediaUrlExpiresAt,omitempty"`
+
+	// MessageId The ID of the message that first shared the media, missing until it is sent.
+	MessageId *string `json:"messageId,omitempty"`
+
+	// ScanStatus Verdict of the malware scanner. Content is only served once clean, or when missing because uploads are not scanned. failed means the scanner could not read the file, e.g. above its size limit.
+	ScanStatus *MediaScanStatus `json:"scanStatus,omitempty"`
+
 	// SenderId The ID of the user who sent the media.
 	SenderId *string `json:"senderId,omitempty"`
 
+	// Thumbnails Downscaled images of images & posters of videos, smallest first.
+	Thumbnails *[]MediaThumbnail `json:"thumbnails,omitempty"`
+
 	// UploadTimestamp The date and time the media was uploaded.
 	UploadTimestamp *time.Time `json:"uploadTimestamp,omitempty"`
+
+	// Waveform Peak levels from 0 to 100 of evenly spaced windows of audio.
+	Waveform *[]int `json:"waveform,omitempty"`
+
+	// Width Width in pixels of images & videos as displayed.
+	Width *int `json:"width,omitempty"`
+}
+
+// MediaScanStatus Verdict of the malware scanner. Content is only served once clean, or when missing because uploads are not scanned. failed means the scanner could not read the file, e.g. above its size limit.
+type MediaScanStatus string
+
+// MediaThumbnail defines model for MediaThumbnail.
+type MediaThumbnail struct {
+	ContentType string `json:"contentType"`
+	FileSize    int    `json:"fileSize"`
+	Height      int    `json:"height"`
+
+	// Size The longest edge in pixels, it names the thumbnail.
+	Size int `json:"size"`
+
+	// Url Signed URL to download the thumbnail, it expires with the mediaUrl.
+	Url   string `json:"url"`
+	Width int    `json:"width"`
 }
 
 // MediaUploadRequest defines model for MediaUploadRequest.
@@ -273,10 +454,10 @@ type MediaUploadRequest struct {
 	// File The media file to upload.
 	File openapi_types.File `json:"file"`
 
-	// MediaType The type of media.
+	// MediaType The type of media, one of image, video, audio or document. It must match the content of the file.
 	MediaType string `json:"mediaType"`
 
-	// SenderId The ID of the user uploading the media.
+	// SenderId The ID of the user uploading the media, it must be the authenticated user.
 	SenderId string `json:"senderId"`
 }
 
@@ -285,6 +466,9 @@ type Message struct {
 	// ChatId The ID of the chat the message belongs to.
 	ChatId *string `json:"chatId,omitempty"`
 
+	// Ciphertexts End-to-end encrypted payloads, one per recipient device.
+	Ciphertexts *[]DeviceCiphertext `json:"ciphertexts,omitempty"`
+
 	// Content The content of the message.
 	Content *string `json:"content,omitempty"`
 
@@ -297,18 +481,25 @@ type Message struct {
 	// Id The unique identifier for the message.
 	Id *string `json:"id,omitempty"`
 
-	// MediaUrls URLs to any media files attached to the message.
+	// MediaIds IDs of the media files attached to the message, see POST /media.
+	MediaIds *[]string `json:"mediaIds,omitempty"`
+
+	// MediaUrls URLs to any media files attached to the message. Deprecated, attach uploaded media with mediaIds.
+	// Deprecated:
 	MediaUrls *[]string `json:"mediaUrls,omitempty"`
 
 	// Mentions IDs of users mentioned in the message.
 	Mentions *[]string `json:"mentions,omitempty"`
 
-	// MessageType The type of message.
+	// MessageType The type of message, e.g. text, encrypted or voice_note.
 	MessageType *string `json:"messageType,omitempty"`
 
 	// RepliedToMessageId The ID of the message this message is a reply to.
 	RepliedToMessageId *string `json:"repliedToMessageId,omitempty"`
 
+	// SenderDeviceId The ID of the sender's device the message was encrypted on.
+	SenderDeviceId *string `json:"senderDeviceId,omitempty"`
+
 	// SenderId The ID of the user who sent the message.
 	SenderId *string `json:"senderId,omitempty"`
 
@@ -317,6 +508,9 @@ type Message struct {
 
 	// Timestamp The date and time the message was sent.
 	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// VoiceNote The audio of a voice note as extracted by the server, unset when it could not be read.
+	VoiceNote *VoiceNote `json:"voiceNote,omitempty"`
 }
 
 // MessageCreateRequest defines model for MessageCreateRequest.
@@ -324,23 +518,44 @@ type MessageCreateRequest struct {
 	// ChatId The ID of the chat to send the message to.
 	ChatId string `json:"chatId"`
 
+	// Ciphertexts End-to-end encrypted payloads, one per recipient device. Required for encrypted messages.
+	Ciphertexts *[]DeviceCiphertext `json:"ciphertexts,omitempty"`
+
 	// Content The content of the message. Required for text messages.
 	Content *string `json:"content,omitempty"`
 
-	// MediaUrls URLs to any media files attached to the message.  Required for media messages.
+	// MediaIds IDs of the media files attached to the message, see POST /media.
+	MediaIds *[]string `json:"mediaIds,omitempty"`
+
+	// MediaUrls URLs to any media files attached to the message. Deprecated, attach uploaded media with mediaIds.
+	// Deprecated:
 	MediaUrls *[]string `json:"mediaUrls,omitempty"`
 
 	// Mentions IDs of users mentioned in the message.
 	Mentions *[]string `json:"mentions,omitempty"`
 
-	// MessageType The type of message.
-	MessageType string `json:"messageType"`
+	// MessageType The type of message. A voice note has no content & a single audio media in mediaIds, its duration & waveform are set by the server.
+	MessageType MessageCreateRequestMessageType `json:"messageType"`
 
 	// RepliedToMessageId The ID of the message this message is a reply to.
 	RepliedToMessageId *string `json:"repliedToMessageId,omitempty"`
 
-	// SenderId The ID of the user sending the message.
-	SenderId string `json:"senderId"`
+	// SenderDeviceId The ID of the sender's device the message was encrypted on. Required for encrypted messages.
+	SenderDeviceId *string `json:"senderDeviceId,omitempty"`
+}
+
+// MessageCreateRequestMessageType The type of message. A voice note has no content & a single audio media in mediaIds, its duration & waveform are set by the server.
+type MessageCreateRequestMessageType string
+
+// MessageSuccessResponse defines model for MessageSuccessResponse.
+type MessageSuccessResponse struct {
+	Data *Message `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
 }
 
 // MessageUpdateRequest defines model for MessageUpdateRequest.
@@ -361,16 +576,110 @@ type MessageUpdateRequest struct {
 	Status *string `json:"status,omitempty"`
 }
 
+// OneTimePreKey defines model for OneTimePreKey.
+type OneTimePreKey struct {
+	KeyId int `json:"keyId"`
+
+	// PublicKey The base64 encoded public key.
+	PublicKey string `json:"publicKey"`
+}
+
+// PlayedReceipt defines model for PlayedReceipt.
+type PlayedReceipt struct {
+	// PlayedAt The date and time the user first played the voice note.
+	PlayedAt time.Time `json:"playedAt"`
+
+	// UserId The ID of the user who played the voice note.
+	UserId string `json:"userId"`
+}
+
+// PreKeyBundle defines model for PreKeyBundle.
+type PreKeyBundle struct {
+	DeviceId string `json:"deviceId"`
+
+	// IdentityKey The base64 encoded public identity key of the device.
+	IdentityKey   string         `json:"identityKey"`
+	OneTimePreKey *OneTimePreKey `json:"oneTimePreKey,omitempty"`
+	SignedPreKey  SignedPreKey   `json:"signedPreKey"`
+	UserId        string         `json:"userId"`
+}
+
+// PreKeyBundlesResponse defines model for PreKeyBundlesResponse.
+type PreKeyBundlesResponse struct {
+	Data *[]PreKeyBundle `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
+}
+
+// PreKeyCount defines model for PreKeyCount.
+type PreKeyCount struct {
+	DeviceId string `json:"deviceId"`
+
+	// Remaining The number of one-time prekeys the server holds for the device.
+	Remaining int64 `json:"remaining"`
+
+	// Replenish Whether the device should upload new one-time prekeys.
+	Replenish bool `json:"replenish"`
+}
+
+// PreKeyCountResponse defines model for PreKeyCountResponse.
+type PreKeyCountResponse struct {
+	Data *PreKeyCount `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
+}
+
+// Presence defines model for Presence.
+type Presence struct {
+	// LastSeenAt When the user was last connected, absent while online or when hidden.
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+
+	// Online Whether the user has an open event stream, false when hidden.
+	Online bool   `json:"online"`
+	UserId string `json:"userId"`
+}
+
+// PresenceResponse defines model for PresenceResponse.
+type PresenceResponse struct {
+	Data *Presence `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
+}
+
+// PublishKeysRequest defines model for PublishKeysRequest.
+type PublishKeysRequest struct {
+	// IdentityKey The base64 encoded public identity key of the device.
+	IdentityKey    string           `json:"identityKey"`
+	OneTimePreKeys *[]OneTimePreKey `json:"oneTimePreKeys,omitempty"`
+	SignedPreKey   SignedPreKey     `json:"signedPreKey"`
+}
+
+// RefreshTokenRequest defines model for RefreshTokenRequest.
+type RefreshTokenRequest struct {
+	// RefreshToken The refresh token returned by the login or by the previous refresh.
+	RefreshToken string `json:"refreshToken"`
+}
+
 // Settings defines model for Settings.
 type Settings struct {
 	// CreatedAt The date and time the settings were created.
 	CreatedAt *time.Time `json:"createdAt,omitempty"`
 
 	// Id The unique identifier for the settings.
-	Id *string `json:"id,omitempty"`
-
-	// Settings The user's settings (JSON string).
-	Settings *string `json:"settings,omitempty"`
+	Id          *string          `json:"id,omitempty"`
+	Preferences *UserPreferences `json:"preferences,omitempty"`
 
 	// UpdatedAt The date and time the settings were last updated.
 	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
@@ -379,6 +688,125 @@ type Settings struct {
 	UserId *string `json:"userId,omitempty"`
 }
 
+// SettingsSuccessResponse defines model for SettingsSuccessResponse.
+type SettingsSuccessResponse struct {
+	Data *Settings `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
+}
+
+// SignedPreKey defines model for SignedPreKey.
+type SignedPreKey struct {
+	KeyId int `json:"keyId"`
+
+	// PublicKey The base64 encoded public key.
+	PublicKey string `json:"publicKey"`
+
+	// Signature The base64 encoded signature of the public key by the identity key.
+	Signature string `json:"signature"`
+}
+
+// StorageUsage defines model for StorageUsage.
+type StorageUsage struct {
+	// ByChat The storage of the user's media per chat, largest first.
+	ByChat []StorageUsageGroup `json:"byChat"`
+
+	// ByMediaType The storage of the user's media per media type, largest first.
+	ByMediaType []StorageUsageGroup `json:"byMediaType"`
+
+	// EnterpriseId The enterprise sharing its quota, set on enterprise plans.
+	EnterpriseId *string `json:"enterpriseId,omitempty"`
+
+	// PlanType The subscription plan, free without an active subscription.
+	PlanType StorageUsagePlanType `json:"planType"`
+
+	// QuotaBytes The storage quota of the plan in bytes, 0 is unlimited.
+	QuotaBytes int64 `json:"quotaBytes"`
+
+	// UsedBytes The storage counted against the quota, by the media of every member on enterprise plans.
+	UsedBytes int64 `json:"usedBytes"`
+
+	// UserBytes The storage taken by the media of the user.
+	UserBytes int64 `json:"userBytes"`
+}
+
+// StorageUsagePlanType The subscription plan, free without an active subscription.
+type StorageUsagePlanType string
+
+// StorageUsageGroup defines model for StorageUsageGroup.
+type StorageUsageGroup struct {
+	Bytes int64 `json:"bytes"`
+
+	// ChatId The chat of the group, set in byChat.
+	ChatId *string `json:"chatId,omitempty"`
+
+	// Count The number of media.
+	Count int `json:"count"`
+
+	// MediaType The media type of the group, set in byMediaType.
+	MediaType *string `json:"mediaType,omitempty"`
+}
+
+// Upload defines model for Upload.
+type Upload struct {
+	// ChatId The ID of the chat the media is being uploaded to.
+	ChatId *string `json:"chatId,omitempty"`
+
+	// ContentType The MIME type detected from the first chunk.
+	ContentType *string `json:"contentType,omitempty"`
+
+	// ExpiresAt When the upload is deleted unless another chunk arrives.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// FileName The name of the media file.
+	FileName *string `json:"fileName,omitempty"`
+
+	// Id The unique identifier for the upload.
+	Id *string `json:"id,omitempty"`
+
+	// Length The size of the whole file in bytes.
+	Length *int64 `json:"length,omitempty"`
+
+	// MediaId The ID of the media the upload completes into.
+	MediaId *string `json:"mediaId,omitempty"`
+
+	// MediaType The declared type of media.
+	MediaType *string `json:"mediaType,omitempty"`
+
+	// Offset The number of bytes received, the next chunk starts here.
+	Offset *int64 `json:"offset,omitempty"`
+
+	// Status The state of the upload.
+	Status *UploadStatus `json:"status,omitempty"`
+}
+
+// UploadStatus The state of the upload.
+type UploadStatus string
+
+// UploadCreateRequest defines model for UploadCreateRequest.
+type UploadCreateRequest struct {
+	// ChatId The ID of the chat the media is being uploaded to.
+	ChatId string `json:"chatId"`
+
+	// FileName The name of the media file.
+	FileName *string `json:"fileName,omitempty"`
+
+	// Length The size of the whole file in bytes.
+	Length int64 `json:"length"`
+
+	// MediaType The type of media, one of image, video, audio or document. It must match the content of the file.
+	MediaType *string `json:"mediaType,omitempty"`
+}
+
+// UploadPreKeysRequest defines model for UploadPreKeysRequest.
+type UploadPreKeysRequest struct {
+	OneTimePreKeys []OneTimePreKey `json:"oneTimePreKeys"`
+}
+
 // User defines model for User.
 type User struct {
 	// Bio A short biography of the user.
@@ -483,6 +911,72 @@ type UserCreatedResponse struct {
 	Success *bool `json:"success,omitempty"`
 }
 
+// UserPreferences defines model for UserPreferences.
+type UserPreferences struct {
+	Accessibility *UserPreferencesAccessibility `json:"accessibility,omitempty"`
+
+	// AutoDownloadMedia When media is downloaded automatically, one of wifi, cellular or never.
+	AutoDownloadMedia *string `json:"autoDownloadMedia,omitempty"`
+
+	// ChatWallpaper Path or ID of the chat wallpaper.
+	ChatWallpaper *string `json:"chatWallpaper,omitempty"`
+
+	// EmojiStyle The emoji style e.g. system, apple or google.
+	EmojiStyle *string `json:"emojiStyle,omitempty"`
+
+	// FontSize Font size for chat messages.
+	FontSize *int `json:"fontSize,omitempty"`
+
+	// HighlightVisibility Who can see the user's highlights, one of everyone, contacts or nobody.
+	HighlightVisibility *string `json:"highlightVisibility,omitempty"`
+
+	// Language The user's preferred language.
+	Language *string `json:"language,omitempty"`
+
+	// LastActiveVisibility Who can see when the user was last active, one of everyone, contacts or nobody.
+	LastActiveVisibility *string `json:"lastActiveVisibility,omitempty"`
+
+	// Notifications Enable/disable notifications.
+	Notifications *bool                   `json:"notifications,omitempty"`
+	Privacy       *UserPreferencesPrivacy `json:"privacy,omitempty"`
+
+	// ReadReceipts Enable/disable read receipts.
+	ReadReceipts *bool `json:"readReceipts,omitempty"`
+
+	// ShowPreviews Show message previews in notifications.
+	ShowPreviews *bool `json:"showPreviews,omitempty"`
+
+	// Sound Enable/disable message sounds.
+	Sound *bool `json:"sound,omitempty"`
+
+	// Theme The application theme e.g. light, dark or system.
+	Theme *string `json:"theme,omitempty"`
+
+	// Vibration Enable/disable message vibration.
+	Vibration *bool `json:"vibration,omitempty"`
+}
+
+// UserPreferencesAccessibility defines model for UserPreferencesAccessibility.
+type UserPreferencesAccessibility struct {
+	// HighContrast Enable/disable the high contrast theme.
+	HighContrast *bool `json:"highContrast,omitempty"`
+
+	// TextToSpeech Enable/disable reading messages aloud.
+	TextToSpeech *bool `json:"textToSpeech,omitempty"`
+}
+
+// UserPreferencesPrivacy defines model for UserPreferencesPrivacy.
+type UserPreferencesPrivacy struct {
+	// AddToGroups Who can add the user to groups, one of everyone, contacts, contacts-of-contacts or nobody.
+	AddToGroups *string `json:"addToGroups,omitempty"`
+
+	// PhoneNumberVisibility Who can see the phone number, one of everyone, contacts or nobody.
+	PhoneNumberVisibility *string `json:"phoneNumberVisibility,omitempty"`
+
+	// ProfilePictureVisibility Who can see the profile picture, one of everyone, contacts or nobody.
+	ProfilePictureVisibility *string `json:"profilePictureVisibility,omitempty"`
+}
+
 // UserSuccessResponse defines model for UserSuccessResponse.
 type UserSuccessResponse struct {
 	Data *User `json:"data,omitempty"`
@@ -536,18 +1030,47 @@ type UserUpdateRequest struct {
 	Username *string `json:"username,omitempty"`
 }
 
+// UsersSuccessResponse defines model for UsersSuccessResponse.
+type UsersSuccessResponse struct {
+	Data *[]User `json:"data,omitempty"`
+
+	// Message description of process outcome
+	Message *string `json:"message,omitempty"`
+
+	// Success Is the response a success response
+	Success *bool `json:"success,omitempty"`
+}
+
+// VoiceNote The audio of a voice note as extracted by the server, unset when it could not be read.
+type VoiceNote struct {
+	// DurationMs The duration of the audio in milliseconds.
+	DurationMs *int64 `json:"durationMs,omitempty"`
+
+	// Waveform Peak levels from 0 to 100 of evenly spaced windows of the audio.
+	Waveform *[]int `json:"waveform,omitempty"`
+}
+
+// DeviceIdParam defines model for deviceIdParam.
+type DeviceIdParam = string
+
 // N400BadRequest defines model for 400BadRequest.
 type N400BadRequest = ErrorGenericResponse
 
 // N401Unauthorized defines model for 401Unauthorized.
 type N401Unauthorized = ErrorGenericResponse
 
+// N404NotFound defines model for 404NotFound.
+type N404NotFound = ErrorGenericResponse
+
 // N500InternalServerError defines model for 500InternalServerError.
 type N500InternalServerError = ErrorGenericResponse
 
 // AuthLoginSuccess defines model for AuthLoginSuccess.
 type AuthLoginSuccess = AuthLoginResponse
 
+// PreKeyCountSuccess defines model for PreKeyCountSuccess.
+type PreKeyCountSuccess = PreKeyCountResponse
+
 // UserRegistrationSuccess defines model for UserRegistrationSuccess.
 type UserRegistrationSuccess = UserCreatedResponse
 
@@ -555,23 +1078,42 @@ type UserRegistrationSuccess = UserCreatedResponse
 type UserSuccess = UserSuccessResponse
 
 // LoginRequest defines model for LoginRequest.
-type LoginRequest struct {
-	union json.RawMessage
-}
+type LoginRequest = AuthLoginRequest
 
 // RegisterRequest defines model for RegisterRequest.
-type RegisterRequest struct {
-	union json.RawMessage
-}
+type RegisterRequest = AuthRegisterRequest
+
+// GetChatGalleryParams defines parameters for GetChatGallery.
+type GetChatGalleryParams struct {
+	// Type The tab of the gallery.
+	Type GetChatGalleryParamsType `form:"type" json:"type"`
 
-// AuthLoginJSONBody defines parameters for AuthLogin.
-type AuthLoginJSONBody struct {
-	union json.RawMessage
+	// Cursor The nextCursor of the previous page.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit The maximum number of items, 30 by default.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
-// AuthRegisterJSONBody defines parameters for AuthRegister.
-type AuthRegisterJSONBody struct {
-	union json.RawMessage
+// GetChatGalleryParamsType defines parameters for GetChatGallery.
+type GetChatGalleryParamsType string
+
+// UploadChunkParams defines parameters for UploadChunk.
+type UploadChunkParams struct {
+	// Offset The offset of the chunk in the file, in bytes.
+	Offset int64 `form:"offset" json:"offset"`
+}
+
+// GetMediaContentParams defines parameters for GetMediaContent.
+type GetMediaContentParams struct {
+	// Expires Unix time the URL expires at.
+	Expires int64 `form:"expires" json:"expires"`
+
+	// Signature HMAC of the media id & expiry.
+	Signature string `form:"signature" json:"signature"`
+
+	// Thumbnail Size of the thumbnail to download instead of the file.
+	Thumbnail *int `form:"thumbnail,omitempty" json:"thumbnail,omitempty"`
 }
 
 // GetMessagesByChatIdParams defines parameters for GetMessagesByChatId.
@@ -581,10 +1123,16 @@ type GetMessagesByChatIdParams struct {
 }
 
 // AuthLoginJSONRequestBody defines body for AuthLogin for application/json ContentType.
-type AuthLoginJSONRequestBody AuthLoginJSONBody
+type AuthLoginJSONRequestBody = AuthLoginRequest
+
+// AuthLogoutJSONRequestBody defines body for AuthLogout for application/json ContentType.
+type AuthLogoutJSONRequestBody = RefreshTokenRequest
+
+// AuthRefreshTokenJSONRequestBody defines body for AuthRefreshToken for application/json ContentType.
+type AuthRefreshTokenJSONRequestBody = RefreshTokenRequest
 
 // AuthRegisterJSONRequestBody defines body for AuthRegister for application/json ContentType.
-type AuthRegisterJSONRequestBody AuthRegisterJSONBody
+type AuthRegisterJSONRequestBody = AuthRegisterRequest
 
 // CreateChatGroupJSONRequestBody defines body for CreateChatGroup for application/json ContentType.
 type CreateChatGroupJSONRequestBody = ChatGroupCreateRequest
@@ -604,15 +1152,27 @@ type CreateHighlightJSONRequestBody = Highlight
 // UpdateHighlightByIdJSONRequestBody defines body for UpdateHighlightById for application/json ContentType.
 type UpdateHighlightByIdJSONRequestBody = Highlight
 
+// PublishDeviceKeysJSONRequestBody defines body for PublishDeviceKeys for application/json ContentType.
+type PublishDeviceKeysJSONRequestBody = PublishKeysRequest
+
+// UploadOneTimePreKeysJSONRequestBody defines body for UploadOneTimePreKeys for application/json ContentType.
+type UploadOneTimePreKeysJSONRequestBody = UploadPreKeysRequest
+
 // UploadMediaMultipartRequestBody defines body for UploadMedia for multipart/form-data ContentType.
 type UploadMediaMultipartRequestBody = MediaUploadRequest
 
+// CreateUploadJSONRequestBody defines body for CreateUpload for application/json ContentType.
+type CreateUploadJSONRequestBody = UploadCreateRequest
+
 // SendMessageJSONRequestBody defines body for SendMessage for application/json ContentType.
 type SendMessageJSONRequestBody = MessageCreateRequest
 
 // UpdateMessageJSONRequestBody defines body for UpdateMessage for application/json ContentType.
 type UpdateMessageJSONRequestBody = MessageUpdateRequest
 
+// SetChatActivityJSONRequestBody defines body for SetChatActivity for application/json ContentType.
+type SetChatActivityJSONRequestBody = ChatActivityRequest
+
 // UpdateUserSettingsJSONRequestBody defines body for UpdateUserSettings for application/json ContentType.
 type UpdateUserSettingsJSONRequestBody = Settings
 
@@ -622,130 +1182,6 @@ type CreateUserJSONRequestBody = UserCreateRequest
 // UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
 type UpdateUserJSONRequestBody = UserUpdateRequest
 
-// AsAuthLoginEmailRequest returns the union data inside the LoginRequest as a AuthLoginEmailRequest
-func (t LoginRequest) AsAuthLoginEmailRequest() (AuthLoginEmailRequest, error) {
-	var body AuthLoginEmailRequest
-	err := json.Unmarshal(t.union, &body)
-	return body, err
-}
-
-// FromAuthLoginEmailRequest overwrites any union data inside the LoginRequest as the provided AuthLoginEmailRequest
-func (t *LoginRequest) FromAuthLoginEmailRequest(v AuthLoginEmailRequest) error {
-	b, err := json.Marshal(v)
-	t.union = b
-	return err
-}
-
-// MergeAuthLoginEmailRequest performs a merge with any union data inside the LoginRequest, using the provided AuthLoginEmailRequest
-func (t *LoginRequest) MergeAuthLoginEmailRequest(v AuthLoginEmailRequest) error {
-	b, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-
-	merged, err := runtime.JSONMerge(t.union, b)
-	t.union = merged
-	return err
-}
-
-// AsAuthLoginPhoneNumberRequest returns the union data inside the LoginRequest as a AuthLoginPhoneNumberRequest
-func (t LoginRequest) AsAuthLoginPhoneNumberRequest() (AuthLoginPhoneNumberRequest, error) {
-	var body AuthLoginPhoneNumberRequest
-	err := json.Unmarshal(t.union, &body)
-	return body, err
-}
-
-// FromAuthLoginPhoneNumberRequest overwrites any union data inside the LoginRequest as the provided AuthLoginPhoneNumberRequest
-func (t *LoginRequest) FromAuthLoginPhoneNumberRequest(v AuthLoginPhoneNumberRequest) error {
-	b, err := json.Marshal(v)
-	t.union = b
-	return err
-}
-
-// MergeAuthLoginPhoneNumberRequest performs a merge with any union data inside the LoginRequest, using the provided AuthLoginPhoneNumberRequest
-func (t *LoginRequest) MergeAuthLoginPhoneNumberRequest(v AuthLoginPhoneNumberRequest) error {
-	b, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-
-	merged, err := runtime.JSONMerge(t.union, b)
-	t.union = merged
-	return err
-}
-
-func (t LoginRequest) MarshalJSON() ([]byte, error) {
-	b, err := t.union.MarshalJSON()
-	return b, err
-}
-
-func (t *LoginRequest) UnmarshalJSON(b []byte) error {
-	err := t.union.UnmarshalJSON(b)
-	return err
-}
-
-// AsAuthRegisterEmailRequest returns the union data inside the RegisterRequest as a AuthRegisterEmailRequest
-func (t RegisterRequest) AsAuthRegisterEmailRequest() (AuthRegisterEmailRequest, error) {
-	var body AuthRegisterEmailRequest
-	err := json.Unmarshal(t.union, &body)
-	return body, err
-}
-
-// FromAuthRegisterEmailRequest overwrites any union data inside the RegisterRequest as the provided AuthRegisterEmailRequest
-func (t *RegisterRequest) FromAuthRegisterEmailRequest(v AuthRegisterEmailRequest) error {
-	b, err := json.Marshal(v)
-	t.union = b
-	return err
-}
-
-// MergeAuthRegisterEmailRequest performs a merge with any union data inside the RegisterRequest, using the provided AuthRegisterEmailRequest
-func (t *RegisterRequest) MergeAuthRegisterEmailRequest(v AuthRegisterEmailRequest) error {
-	b, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-
-	merged, err := runtime.JSONMerge(t.union, b)
-	t.union = merged
-	return err
-}
-
-// AsAuthRegisterPhoneNumberRequest returns the union data inside the RegisterRequest as a AuthRegisterPhoneNumberRequest
-func (t RegisterRequest) AsAuthRegisterPhoneNumberRequest() (AuthRegisterPhoneNumberRequest, error) {
-	var body AuthRegisterPhoneNumberRequest
-	err := json.Unmarshal(t.union, &body)
-	return body, err
-}
-
-// FromAuthRegisterPhoneNumberRequest overwrites any union data inside the RegisterRequest as the provided AuthRegisterPhoneNumberRequest
-func (t *RegisterRequest) FromAuthRegisterPhoneNumberRequest(v AuthRegisterPhoneNumberRequest) error {
-	b, err := json.Marshal(v)
-	t.union = b
-	return err
-}
-
-// MergeAuthRegisterPhoneNumberRequest performs a merge with any union data inside the RegisterRequest, using the provided AuthRegisterPhoneNumberRequest
-func (t *RegisterRequest) MergeAuthRegisterPhoneNumberRequest(v AuthRegisterPhoneNumberRequest) error {
-	b, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-
-	merged, err := runtime.JSONMerge(t.union, b)
-	t.union = merged
-	return err
-}
-
-func (t RegisterRequest) MarshalJSON() ([]byte, error) {
-	b, err := t.union.MarshalJSON()
-	return b, err
-}
-
-func (t *RegisterRequest) UnmarshalJSON(b []byte) error {
-	err := t.union.UnmarshalJSON(b)
-	return err
-}
-
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Index entry for api status
@@ -754,6 +1190,12 @@ type ServerInterface interface {
 	// Logs in and returns JWT-auth-token
 	// (POST /auth/login)
 	AuthLogin(c *fiber.Ctx) error
+	// Logs out by revoking a refresh token
+	// (POST /auth/logout)
+	AuthLogout(c *fiber.Ctx) error
+	// Exchanges a refresh token for new tokens
+	// (POST /auth/refresh-token)
+	AuthRefreshToken(c *fiber.Ctx) error
 	// Registers and returns user information
 	// (POST /auth/register)
 	AuthRegister(c *fiber.Ctx) error
@@ -787,6 +1229,9 @@ type ServerInterface interface {
 	// Update a chat
 	// (PUT /chats/{chatId})
 	UpdateChat(c *fiber.Ctx, chatId string) error
+	// List the shared media of a chat
+	// (GET /chats/{chatId}/media)
+	GetChatGallery(c *fiber.Ctx, chatId string, params GetChatGalleryParams) error
 	// Get all highlights
 	// (GET /highlights)
 	GetAllHighlights(c *fiber.Ctx) error
@@ -802,15 +1247,48 @@ type ServerInterface interface {
 	// Update highlight by ID
 	// (PUT /highlights/{highlightId})
 	UpdateHighlightById(c *fiber.Ctx, highlightId string) error
+	// Remove a device
+	// (DELETE /keys/devices/{deviceId})
+	DeleteDeviceKeys(c *fiber.Ctx, deviceId DeviceIdParam) error
+	// Publish the keys of a device
+	// (PUT /keys/devices/{deviceId})
+	PublishDeviceKeys(c *fiber.Ctx, deviceId DeviceIdParam) error
+	// Upload one-time prekeys
+	// (POST /keys/devices/{deviceId}/prekeys)
+	UploadOneTimePreKeys(c *fiber.Ctx, deviceId DeviceIdParam) error
+	// Count the one-time prekeys left
+	// (GET /keys/devices/{deviceId}/prekeys/count)
+	GetPreKeyCount(c *fiber.Ctx, deviceId DeviceIdParam) error
+	// Fetch the prekey bundles of a user
+	// (GET /keys/users/{userId}/bundles)
+	GetUserPreKeyBundles(c *fiber.Ctx, userId string) error
 	// Upload media
 	// (POST /media)
 	UploadMedia(c *fiber.Ctx) error
+	// Create a resumable upload
+	// (POST /media/uploads)
+	CreateUpload(c *fiber.Ctx) error
+	// Cancel an upload
+	// (DELETE /media/uploads/{uploadId})
+	DeleteUpload(c *fiber.Ctx, uploadId string) error
+	// Get the progress of an upload
+	// (GET /media/uploads/{uploadId})
+	GetUpload(c *fiber.Ctx, uploadId string) error
+	// Upload a chunk
+	// (PATCH /media/uploads/{uploadId})
+	UploadChunk(c *fiber.Ctx, uploadId string, params UploadChunkParams) error
+	// Complete an upload
+	// (POST /media/uploads/{uploadId}/complete)
+	CompleteUpload(c *fiber.Ctx, uploadId string) error
 	// Delete media
 	// (DELETE /media/{mediaId})
 	DeleteMedia(c *fiber.Ctx, mediaId string) error
 	// Get media by ID
 	// (GET /media/{mediaId})
 	GetMediaById(c *fiber.Ctx, mediaId string) error
+	// Download media content
+	// (GET /media/{mediaId}/content)
+	GetMediaContent(c *fiber.Ctx, mediaId string, params GetMediaContentParams) error
 	// Get messages for a chat
 	// (GET /messages)
 	GetMessagesByChatId(c *fiber.Ctx, params GetMessagesByChatIdParams) error
@@ -826,12 +1304,27 @@ type ServerInterface interface {
 	// Update a message
 	// (PUT /messages/{messageId})
 	UpdateMessage(c *fiber.Ctx, messageId string) error
+	// List who played a voice note
+	// (GET /messages/{messageId}/played)
+	GetMessagePlayedReceipts(c *fiber.Ctx, messageId string) error
+	// Mark a voice note played
+	// (POST /messages/{messageId}/played)
+	MarkMessagePlayed(c *fiber.Ctx, messageId string) error
+	// Set the activity of the user in a chat
+	// (POST /realtime/chats/{chatId}/activity)
+	SetChatActivity(c *fiber.Ctx, chatId string) error
+	// Get the presence of a user
+	// (GET /realtime/users/{userId}/presence)
+	GetUserPresence(c *fiber.Ctx, userId string) error
 	// Get user settings
 	// (GET /settings/{userId})
 	GetUserSettings(c *fiber.Ctx, userId string) error
 	// Update user settings
 	// (PUT /settings/{userId})
 	UpdateUserSettings(c *fiber.Ctx, userId string) error
+	// Get the storage usage
+	// (GET /storage/usage)
+	GetStorageUsage(c *fiber.Ctx) error
 	// Get all users
 	// (GET /users)
 	GetAllUsers(c *fiber.Ctx) error
@@ -868,6 +1361,18 @@ func (siw *ServerInterfaceWrapper) AuthLogin(c *fiber.Ctx) error {
 	return siw.Handler.AuthLogin(c)
 }
 
+// AuthLogout operation middleware
+func (siw *ServerInterfaceWrapper) AuthLogout(c *fiber.Ctx) error {
+
+	return siw.Handler.AuthLogout(c)
+}
+
+// AuthRefreshToken operation middleware
+func (siw *ServerInterfaceWrapper) AuthRefreshToken(c *fiber.Ctx) error {
+
+	return siw.Handler.AuthRefreshToken(c)
+}
+
 // AuthRegister operation middleware
 func (siw *ServerInterfaceWrapper) AuthRegister(c *fiber.Ctx) error {
 
@@ -1014,6 +1519,62 @@ func (siw *ServerInterfaceWrapper) UpdateChat(c *fiber.Ctx) error {
 	return siw.Handler.UpdateChat(c, chatId)
 }
 
+// GetChatGallery operation middleware
+func (siw *ServerInterfaceWrapper) GetChatGallery(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "chatId" -------------
+	var chatId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "chatId", c.Params("chatId"), &chatId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter chatId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetChatGalleryParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Required query parameter "type" -------------
+
+	if paramValue := c.Query("type"); paramValue != "" {
+
+	} else {
+		err = fmt.Errorf("Query argument type is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "type", query, &params.Type)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter type: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", query, &params.Cursor)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter cursor: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
+	}
+
+	return siw.Handler.GetChatGallery(c, chatId, params)
+}
+
 // GetAllHighlights operation middleware
 func (siw *ServerInterfaceWrapper) GetAllHighlights(c *fiber.Ctx) error {
 
@@ -1084,1258 +1645,2784 @@ func (siw *ServerInterfaceWrapper) UpdateHighlightById(c *fiber.Ctx) error {
 	return siw.Handler.UpdateHighlightById(c, highlightId)
 }
 
-// UploadMedia operation middleware
-func (siw *ServerInterfaceWrapper) UploadMedia(c *fiber.Ctx) error {
-
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
-
-	return siw.Handler.UploadMedia(c)
-}
-
-// DeleteMedia operation middleware
-func (siw *ServerInterfaceWrapper) DeleteMedia(c *fiber.Ctx) error {
+// DeleteDeviceKeys operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDeviceKeys(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "mediaId" -------------
-	var mediaId string
+	// ------------- Path parameter "deviceId" -------------
+	var deviceId DeviceIdParam
 
-	err = runtime.BindStyledParameterWithOptions("simple", "mediaId", c.Params("mediaId"), &mediaId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "deviceId", c.Params("deviceId"), &deviceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter mediaId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter deviceId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.DeleteMedia(c, mediaId)
+	return siw.Handler.DeleteDeviceKeys(c, deviceId)
 }
 
-// GetMediaById operation middleware
-func (siw *ServerInterfaceWrapper) GetMediaById(c *fiber.Ctx) error {
+// PublishDeviceKeys operation middleware
+func (siw *ServerInterfaceWrapper) PublishDeviceKeys(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "mediaId" -------------
-	var mediaId string
+	// ------------- Path parameter "deviceId" -------------
+	var deviceId DeviceIdParam
 
-	err = runtime.BindStyledParameterWithOptions("simple", "mediaId", c.Params("mediaId"), &mediaId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "deviceId", c.Params("deviceId"), &deviceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter mediaId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter deviceId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.GetMediaById(c, mediaId)
+	return siw.Handler.PublishDeviceKeys(c, deviceId)
 }
 
-// GetMessagesByChatId operation middleware
-func (siw *ServerInterfaceWrapper) GetMessagesByChatId(c *fiber.Ctx) error {
+// UploadOneTimePreKeys operation middleware
+func (siw *ServerInterfaceWrapper) UploadOneTimePreKeys(c *fiber.Ctx) error {
 
 	var err error
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
-
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetMessagesByChatIdParams
+	// ------------- Path parameter "deviceId" -------------
+	var deviceId DeviceIdParam
 
-	var query url.Values
-	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	err = runtime.BindStyledParameterWithOptions("simple", "deviceId", c.Params("deviceId"), &deviceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter deviceId: %w", err).Error())
 	}
 
-	// ------------- Required query parameter "chatId" -------------
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	if paramValue := c.Query("chatId"); paramValue != "" {
+	return siw.Handler.UploadOneTimePreKeys(c, deviceId)
+}
 
-	} else {
-		err = fmt.Errorf("Query argument chatId is required, but not found")
-		c.Status(fiber.StatusBadRequest).JSON(err)
-		return err
-	}
+// GetPreKeyCount operation middleware
+func (siw *ServerInterfaceWrapper) GetPreKeyCount(c *fiber.Ctx) error {
 
-	err = runtime.BindQueryParameter("form", true, true, "chatId", query, &params.ChatId)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter chatId: %w", err).Error())
-	}
+	var err error
 
-	return siw.Handler.GetMessagesByChatId(c, params)
-}
+	// ------------- Path parameter "deviceId" -------------
+	var deviceId DeviceIdParam
 
-// SendMessage operation middleware
-func (siw *ServerInterfaceWrapper) SendMessage(c *fiber.Ctx) error {
+	err = runtime.BindStyledParameterWithOptions("simple", "deviceId", c.Params("deviceId"), &deviceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter deviceId: %w", err).Error())
+	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.SendMessage(c)
+	return siw.Handler.GetPreKeyCount(c, deviceId)
 }
 
-// DeleteMessage operation middleware
-func (siw *ServerInterfaceWrapper) DeleteMessage(c *fiber.Ctx) error {
+// GetUserPreKeyBundles operation middleware
+func (siw *ServerInterfaceWrapper) GetUserPreKeyBundles(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "messageId" -------------
-	var messageId string
+	// ------------- Path parameter "userId" -------------
+	var userId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.DeleteMessage(c, messageId)
+	return siw.Handler.GetUserPreKeyBundles(c, userId)
 }
 
-// GetMessageById operation middleware
-func (siw *ServerInterfaceWrapper) GetMessageById(c *fiber.Ctx) error {
+// UploadMedia operation middleware
+func (siw *ServerInterfaceWrapper) UploadMedia(c *fiber.Ctx) error {
 
-	var err error
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	// ------------- Path parameter "messageId" -------------
-	var messageId string
+	return siw.Handler.UploadMedia(c)
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
-	}
+// CreateUpload operation middleware
+func (siw *ServerInterfaceWrapper) CreateUpload(c *fiber.Ctx) error {
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.GetMessageById(c, messageId)
+	return siw.Handler.CreateUpload(c)
 }
 
-// UpdateMessage operation middleware
-func (siw *ServerInterfaceWrapper) UpdateMessage(c *fiber.Ctx) error {
+// DeleteUpload operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUpload(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "messageId" -------------
-	var messageId string
+	// ------------- Path parameter "uploadId" -------------
+	var uploadId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "uploadId", c.Params("uploadId"), &uploadId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter uploadId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.UpdateMessage(c, messageId)
+	return siw.Handler.DeleteUpload(c, uploadId)
 }
 
-// GetUserSettings operation middleware
-func (siw *ServerInterfaceWrapper) GetUserSettings(c *fiber.Ctx) error {
+// GetUpload operation middleware
+func (siw *ServerInterfaceWrapper) GetUpload(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "userId" -------------
-	var userId string
+	// ------------- Path parameter "uploadId" -------------
+	var uploadId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "uploadId", c.Params("uploadId"), &uploadId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter uploadId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.GetUserSettings(c, userId)
+	return siw.Handler.GetUpload(c, uploadId)
 }
 
-// UpdateUserSettings operation middleware
-func (siw *ServerInterfaceWrapper) UpdateUserSettings(c *fiber.Ctx) error {
+// UploadChunk operation middleware
+func (siw *ServerInterfaceWrapper) UploadChunk(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "userId" -------------
-	var userId string
+	// ------------- Path parameter "uploadId" -------------
+	var uploadId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "uploadId", c.Params("uploadId"), &uploadId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter uploadId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.UpdateUserSettings(c, userId)
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UploadChunkParams
 
-// GetAllUsers operation middleware
-func (siw *ServerInterfaceWrapper) GetAllUsers(c *fiber.Ctx) error {
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+	// ------------- Required query parameter "offset" -------------
 
-	return siw.Handler.GetAllUsers(c)
-}
+	if paramValue := c.Query("offset"); paramValue != "" {
 
-// CreateUser operation middleware
-func (siw *ServerInterfaceWrapper) CreateUser(c *fiber.Ctx) error {
+	} else {
+		err = fmt.Errorf("Query argument offset is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+	err = runtime.BindQueryParameter("form", true, true, "offset", query, &params.Offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
+	}
 
-	return siw.Handler.CreateUser(c)
+	return siw.Handler.UploadChunk(c, uploadId, params)
 }
 
-// DeleteUser operation middleware
-func (siw *ServerInterfaceWrapper) DeleteUser(c *fiber.Ctx) error {
+// CompleteUpload operation middleware
+func (siw *ServerInterfaceWrapper) CompleteUpload(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "userId" -------------
-	var userId string
+	// ------------- Path parameter "uploadId" -------------
+	var uploadId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "uploadId", c.Params("uploadId"), &uploadId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter uploadId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.DeleteUser(c, userId)
+	return siw.Handler.CompleteUpload(c, uploadId)
 }
 
-// GetUserById operation middleware
-func (siw *ServerInterfaceWrapper) GetUserById(c *fiber.Ctx) error {
+// DeleteMedia operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMedia(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "userId" -------------
-	var userId string
+	// ------------- Path parameter "mediaId" -------------
+	var mediaId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "mediaId", c.Params("mediaId"), &mediaId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter mediaId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.GetUserById(c, userId)
+	return siw.Handler.DeleteMedia(c, mediaId)
 }
 
-// UpdateUser operation middleware
-func (siw *ServerInterfaceWrapper) UpdateUser(c *fiber.Ctx) error {
+// GetMediaById operation middleware
+func (siw *ServerInterfaceWrapper) GetMediaById(c *fiber.Ctx) error {
 
 	var err error
 
-	// ------------- Path parameter "userId" -------------
-	var userId string
+	// ------------- Path parameter "mediaId" -------------
+	var mediaId string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "mediaId", c.Params("mediaId"), &mediaId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter mediaId: %w", err).Error())
 	}
 
 	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return siw.Handler.UpdateUser(c, userId)
+	return siw.Handler.GetMediaById(c, mediaId)
 }
 
-// FiberServerOptions provides options for the Fiber server.
-type FiberServerOptions struct {
-	BaseURL     string
-	Middlewares []MiddlewareFunc
-}
+// GetMediaContent operation middleware
+func (siw *ServerInterfaceWrapper) GetMediaContent(c *fiber.Ctx) error {
 
-// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
-func RegisterHandlers(router fiber.Router, si ServerInterface) {
-	RegisterHandlersWithOptions(router, si, FiberServerOptions{})
+	var err error
+
+	// ------------- Path parameter "mediaId" -------------
+	var mediaId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "mediaId", c.Params("mediaId"), &mediaId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter mediaId: %w", err).Error())
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMediaContentParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Required query parameter "expires" -------------
+
+	if paramValue := c.Query("expires"); paramValue != "" {
+
+	} else {
+		err = fmt.Errorf("Query argument expires is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "expires", query, &params.Expires)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter expires: %w", err).Error())
+	}
+
+	// ------------- Required query parameter "signature" -------------
+
+	if paramValue := c.Query("signature"); paramValue != "" {
+
+	} else {
+		err = fmt.Errorf("Query argument signature is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "signature", query, &params.Signature)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter signature: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "thumbnail" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "thumbnail", query, &params.Thumbnail)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter thumbnail: %w", err).Error())
+	}
+
+	return siw.Handler.GetMediaContent(c, mediaId, params)
 }
 
-// RegisterHandlersWithOptions creates http.Handler with additional options
-func RegisterHandlersWithOptions(router fiber.Router, si ServerInterface, options FiberServerOptions) {
-	wrapper := ServerInterfaceWrapper{
-		Handler: si,
+// GetMessagesByChatId operation middleware
+func (siw *ServerInterfaceWrapper) GetMessagesByChatId(c *fiber.Ctx) error {
+
+	var err error
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMessagesByChatIdParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
 	}
 
-	for _, m := range options.Middlewares {
-		router.Use(fiber.Handler(m))
+	// ------------- Required query parameter "chatId" -------------
+
+	if paramValue := c.Query("chatId"); paramValue != "" {
+
+	} else {
+		err = fmt.Errorf("Query argument chatId is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
 	}
 
-	router.Get(options.BaseURL+"/", wrapper.Index)
+	err = runtime.BindQueryParameter("form", true, true, "chatId", query, &params.ChatId)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter chatId: %w", err).Error())
+	}
 
-	router.Post(options.BaseURL+"/auth/login", wrapper.AuthLogin)
+	return siw.Handler.GetMessagesByChatId(c, params)
+}
 
-	router.Post(options.BaseURL+"/auth/register", wrapper.AuthRegister)
+// SendMessage operation middleware
+func (siw *ServerInterfaceWrapper) SendMessage(c *fiber.Ctx) error {
 
-	router.Get(options.BaseURL+"/chatgroups", wrapper.GetAllChatGroups)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	router.Post(options.BaseURL+"/chatgroups", wrapper.CreateChatGroup)
+	return siw.Handler.SendMessage(c)
+}
 
-	router.Delete(options.BaseURL+"/chatgroups/:chatGroupId", wrapper.DeleteChatGroup)
+// DeleteMessage operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMessage(c *fiber.Ctx) error {
 
-	router.Get(options.BaseURL+"/chatgroups/:chatGroupId", wrapper.GetChatGroupById)
+	var err error
 
-	router.Put(options.BaseURL+"/chatgroups/:chatGroupId", wrapper.UpdateChatGroup)
+	// ------------- Path parameter "messageId" -------------
+	var messageId string
 
-	router.Get(options.BaseURL+"/chats", wrapper.GetAllChats)
+	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+	}
 
-	router.Post(options.BaseURL+"/chats", wrapper.CreateChat)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	router.Delete(options.BaseURL+"/chats/:chatId", wrapper.DeleteChat)
+	return siw.Handler.DeleteMessage(c, messageId)
+}
 
-	router.Get(options.BaseURL+"/chats/:chatId", wrapper.GetChatById)
+// GetMessageById operation middleware
+func (siw *ServerInterfaceWrapper) GetMessageById(c *fiber.Ctx) error {
 
-	router.Put(options.BaseURL+"/chats/:chatId", wrapper.UpdateChat)
+	var err error
 
-	router.Get(options.BaseURL+"/highlights", wrapper.GetAllHighlights)
+	// ------------- Path parameter "messageId" -------------
+	var messageId string
 
-	router.Post(options.BaseURL+"/highlights", wrapper.CreateHighlight)
+	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+	}
 
-	router.Delete(options.BaseURL+"/highlights/:highlightId", wrapper.DeleteHighlightById)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	router.Get(options.BaseURL+"/highlights/:highlightId", wrapper.GetHighlightById)
+	return siw.Handler.GetMessageById(c, messageId)
+}
 
-	router.Put(options.BaseURL+"/highlights/:highlightId", wrapper.UpdateHighlightById)
+// UpdateMessage operation middleware
+func (siw *ServerInterfaceWrapper) UpdateMessage(c *fiber.Ctx) error {
 
-	router.Post(options.BaseURL+"/media", wrapper.UploadMedia)
+	var err error
 
-	router.Delete(options.BaseURL+"/media/:mediaId", wrapper.DeleteMedia)
+	// ------------- Path parameter "messageId" -------------
+	var messageId string
 
-	router.Get(options.BaseURL+"/media/:mediaId", wrapper.GetMediaById)
+	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+	}
 
-	router.Get(options.BaseURL+"/messages", wrapper.GetMessagesByChatId)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	router.Post(options.BaseURL+"/messages", wrapper.SendMessage)
+	return siw.Handler.UpdateMessage(c, messageId)
+}
 
-	router.Delete(options.BaseURL+"/messages/:messageId", wrapper.DeleteMessage)
+// GetMessagePlayedReceipts operation middleware
+func (siw *ServerInterfaceWrapper) GetMessagePlayedReceipts(c *fiber.Ctx) error {
 
-	router.Get(options.BaseURL+"/messages/:messageId", wrapper.GetMessageById)
+	var err error
 
-	router.Put(options.BaseURL+"/messages/:messageId", wrapper.UpdateMessage)
+	// ------------- Path parameter "messageId" -------------
+	var messageId string
 
-	router.Get(options.BaseURL+"/settings/:userId", wrapper.GetUserSettings)
+	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+	}
 
-	router.Put(options.BaseURL+"/settings/:userId", wrapper.UpdateUserSettings)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	router.Get(options.BaseURL+"/users", wrapper.GetAllUsers)
+	return siw.Handler.GetMessagePlayedReceipts(c, messageId)
+}
 
-	router.Post(options.BaseURL+"/users", wrapper.CreateUser)
+// MarkMessagePlayed operation middleware
+func (siw *ServerInterfaceWrapper) MarkMessagePlayed(c *fiber.Ctx) error {
 
-	router.Delete(options.BaseURL+"/users/:userId", wrapper.DeleteUser)
+	var err error
 
-	router.Get(options.BaseURL+"/users/:userId", wrapper.GetUserById)
+	// ------------- Path parameter "messageId" -------------
+	var messageId string
 
-	router.Put(options.BaseURL+"/users/:userId", wrapper.UpdateUser)
+	err = runtime.BindStyledParameterWithOptions("simple", "messageId", c.Params("messageId"), &messageId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter messageId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
+	return siw.Handler.MarkMessagePlayed(c, messageId)
 }
 
-type N400BadRequestJSONResponse ErrorGenericResponse
+// SetChatActivity operation middleware
+func (siw *ServerInterfaceWrapper) SetChatActivity(c *fiber.Ctx) error {
 
-type N401UnauthorizedJSONResponse ErrorGenericResponse
+	var err error
 
-type N500InternalServerErrorJSONResponse ErrorGenericResponse
+	// ------------- Path parameter "chatId" -------------
+	var chatId string
 
-type AuthLoginSuccessJSONResponse AuthLoginResponse
+	err = runtime.BindStyledParameterWithOptions("simple", "chatId", c.Params("chatId"), &chatId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter chatId: %w", err).Error())
+	}
 
-type UserRegistrationSuccessJSONResponse UserCreatedResponse
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-type UserSuccessJSONResponse UserSuccessResponse
+	return siw.Handler.SetChatActivity(c, chatId)
+}
 
-type IndexRequestObject struct {
+// GetUserPresence operation middleware
+func (siw *ServerInterfaceWrapper) GetUserPresence(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetUserPresence(c, userId)
 }
 
-type IndexResponseObject interface {
-	VisitIndexResponse(ctx *fiber.Ctx) error
+// GetUserSettings operation middleware
+func (siw *ServerInterfaceWrapper) GetUserSettings(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetUserSettings(c, userId)
+}
+
+// UpdateUserSettings operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUserSettings(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.UpdateUserSettings(c, userId)
+}
+
+// GetStorageUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetStorageUsage(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetStorageUsage(c)
+}
+
+// GetAllUsers operation middleware
+func (siw *ServerInterfaceWrapper) GetAllUsers(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetAllUsers(c)
+}
+
+// CreateUser operation middleware
+func (siw *ServerInterfaceWrapper) CreateUser(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateUser(c)
+}
+
+// DeleteUser operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUser(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteUser(c, userId)
+}
+
+// GetUserById operation middleware
+func (siw *ServerInterfaceWrapper) GetUserById(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetUserById(c, userId)
+}
+
+// UpdateUser operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUser(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", c.Params("userId"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userId: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.UpdateUser(c, userId)
+}
+
+// FiberServerOptions provides options for the Fiber server.
+type FiberServerOptions struct {
+	BaseURL     string
+	Middlewares []MiddlewareFunc
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router fiber.Router, si ServerInterface) {
+	RegisterHandlersWithOptions(router, si, FiberServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router fiber.Router, si ServerInterface, options FiberServerOptions) {
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	for _, m := range options.Middlewares {
+		router.Use(fiber.Handler(m))
+	}
+
+	router.Get(options.BaseURL+"/", wrapper.Index)
+
+	router.Post(options.BaseURL+"/auth/login", wrapper.AuthLogin)
+
+	router.Post(options.BaseURL+"/auth/logout", wrapper.AuthLogout)
+
+	router.Post(options.BaseURL+"/auth/refresh-token", wrapper.AuthRefreshToken)
+
+	router.Post(options.BaseURL+"/auth/register", wrapper.AuthRegister)
+
+	router.Get(options.BaseURL+"/chatgroups", wrapper.GetAllChatGroups)
+
+	router.Post(options.BaseURL+"/chatgroups", wrapper.CreateChatGroup)
+
+	router.Delete(options.BaseURL+"/chatgroups/:chatGroupId", wrapper.DeleteChatGroup)
+
+	router.Get(options.BaseURL+"/chatgroups/:chatGroupId", wrapper.GetChatGroupById)
+
+	router.Put(options.BaseURL+"/chatgroups/:chatGroupId", wrapper.UpdateChatGroup)
+
+	router.Get(options.BaseURL+"/chats", wrapper.GetAllChats)
+
+	router.Post(options.BaseURL+"/chats", wrapper.CreateChat)
+
+	router.Delete(options.BaseURL+"/chats/:chatId", wrapper.DeleteChat)
+
+	router.Get(options.BaseURL+"/chats/:chatId", wrapper.GetChatById)
+
+	router.Put(options.BaseURL+"/chats/:chatId", wrapper.UpdateChat)
+
+	router.Get(options.BaseURL+"/chats/:chatId/media", wrapper.GetChatGallery)
+
+	router.Get(options.BaseURL+"/highlights", wrapper.GetAllHighlights)
+
+	router.Post(options.BaseURL+"/highlights", wrapper.CreateHighlight)
+
+	router.Delete(options.BaseURL+"/highlights/:highlightId", wrapper.DeleteHighlightById)
+
+	router.Get(options.BaseURL+"/highlights/:highlightId", wrapper.GetHighlightById)
+
+	router.Put(options.BaseURL+"/highlights/:highlightId", wrapper.UpdateHighlightById)
+
+	router.Delete(options.BaseURL+"/keys/devices/:deviceId", wrapper.DeleteDeviceKeys)
+
+	router.Put(options.BaseURL+"/keys/devices/:deviceId", wrapper.PublishDeviceKeys)
+
+	router.Post(options.BaseURL+"/keys/devices/:deviceId/prekeys", wrapper.UploadOneTimePreKeys)
+
+	router.Get(options.BaseURL+"/keys/devices/:deviceId/prekeys/count", wrapper.GetPreKeyCount)
+
+	router.Get(options.BaseURL+"/keys/users/:userId/bundles", wrapper.GetUserPreKeyBundles)
+
+	router.Post(options.BaseURL+"/media", wrapper.UploadMedia)
+
+	router.Post(options.BaseURL+"/media/uploads", wrapper.CreateUpload)
+
+	router.Delete(options.BaseURL+"/media/uploads/:uploadId", wrapper.DeleteUpload)
+
+	router.Get(options.BaseURL+"/media/uploads/:uploadId", wrapper.GetUpload)
+
+	router.Patch(options.BaseURL+"/media/uploads/:uploadId", wrapper.UploadChunk)
+
+	router.Post(options.BaseURL+"/media/uploads/:uploadId/complete", wrapper.CompleteUpload)
+
+	router.Delete(options.BaseURL+"/media/:mediaId", wrapper.DeleteMedia)
+
+	router.Get(options.BaseURL+"/media/:mediaId", wrapper.GetMediaById)
+
+	router.Get(options.BaseURL+"/media/:mediaId/content", wrapper.GetMediaContent)
+
+	router.Get(options.BaseURL+"/messages", wrapper.GetMessagesByChatId)
+
+	router.Post(options.BaseURL+"/messages", wrapper.SendMessage)
+
+	router.Delete(options.BaseURL+"/messages/:messageId", wrapper.DeleteMessage)
+
+	router.Get(options.BaseURL+"/messages/:messageId", wrapper.GetMessageById)
+
+	router.Put(options.BaseURL+"/messages/:messageId", wrapper.UpdateMessage)
+
+	router.Get(options.BaseURL+"/messages/:messageId/played", wrapper.GetMessagePlayedReceipts)
+
+	router.Post(options.BaseURL+"/messages/:messageId/played", wrapper.MarkMessagePlayed)
+
+	router.Post(options.BaseURL+"/realtime/chats/:chatId/activity", wrapper.SetChatActivity)
+
+	router.Get(options.BaseURL+"/realtime/users/:userId/presence", wrapper.GetUserPresence)
+
+	router.Get(options.BaseURL+"/settings/:userId", wrapper.GetUserSettings)
+
+	router.Put(options.BaseURL+"/settings/:userId", wrapper.UpdateUserSettings)
+
+	router.Get(options.BaseURL+"/storage/usage", wrapper.GetStorageUsage)
+
+	router.Get(options.BaseURL+"/users", wrapper.GetAllUsers)
+
+	router.Post(options.BaseURL+"/users", wrapper.CreateUser)
+
+	router.Delete(options.BaseURL+"/users/:userId", wrapper.DeleteUser)
+
+	router.Get(options.BaseURL+"/users/:userId", wrapper.GetUserById)
+
+	router.Put(options.BaseURL+"/users/:userId", wrapper.UpdateUser)
+
+}
+
+type N400BadRequestJSONResponse ErrorGenericResponse
+
+type N401UnauthorizedJSONResponse ErrorGenericResponse
+
+type N404NotFoundJSONResponse ErrorGenericResponse
+
+type N500InternalServerErrorJSONResponse ErrorGenericResponse
+
+type AuthLoginSuccessJSONResponse AuthLoginResponse
+
+type PreKeyCountSuccessJSONResponse PreKeyCountResponse
+
+type UserRegistrationSuccessJSONResponse UserCreatedResponse
+
+type UserSuccessJSONResponse UserSuccessResponse
+
+type IndexRequestObject struct {
+}
+
+type IndexResponseObject interface {
+	VisitIndexResponse(ctx *fiber.Ctx) error
+}
+
+type Index200JSONResponse GenericSuccessResponse
+
+func (response Index200JSONResponse) VisitIndexResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLoginRequestObject struct {
+	Body *AuthLoginJSONRequestBody
+}
+
+type AuthLoginResponseObject interface {
+	VisitAuthLoginResponse(ctx *fiber.Ctx) error
+}
+
+type AuthLogin200JSONResponse struct{ AuthLoginSuccessJSONResponse }
+
+func (response AuthLogin200JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLogin400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response AuthLogin400JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLogin401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response AuthLogin401JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLogin500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response AuthLogin500JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLogoutRequestObject struct {
+	Body *AuthLogoutJSONRequestBody
+}
+
+type AuthLogoutResponseObject interface {
+	VisitAuthLogoutResponse(ctx *fiber.Ctx) error
+}
+
+type AuthLogout200JSONResponse GenericSuccessResponse
+
+func (response AuthLogout200JSONResponse) VisitAuthLogoutResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLogout400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response AuthLogout400JSONResponse) VisitAuthLogoutResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type AuthLogout500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response AuthLogout500JSONResponse) VisitAuthLogoutResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRefreshTokenRequestObject struct {
+	Body *AuthRefreshTokenJSONRequestBody
+}
+
+type AuthRefreshTokenResponseObject interface {
+	VisitAuthRefreshTokenResponse(ctx *fiber.Ctx) error
+}
+
+type AuthRefreshToken200JSONResponse struct{ AuthLoginSuccessJSONResponse }
+
+func (response AuthRefreshToken200JSONResponse) VisitAuthRefreshTokenResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRefreshToken400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response AuthRefreshToken400JSONResponse) VisitAuthRefreshTokenResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRefreshToken401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response AuthRefreshToken401JSONResponse) VisitAuthRefreshTokenResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRefreshToken500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response AuthRefreshToken500JSONResponse) VisitAuthRefreshTokenResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRegisterRequestObject struct {
+	Body *AuthRegisterJSONRequestBody
+}
+
+type AuthRegisterResponseObject interface {
+	VisitAuthRegisterResponse(ctx *fiber.Ctx) error
+}
+
+type AuthRegister201JSONResponse struct {
+	UserRegistrationSuccessJSONResponse
+}
+
+func (response AuthRegister201JSONResponse) VisitAuthRegisterResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRegister400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response AuthRegister400JSONResponse) VisitAuthRegisterResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type AuthRegister500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response AuthRegister500JSONResponse) VisitAuthRegisterResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetAllChatGroupsRequestObject struct {
+}
+
+type GetAllChatGroupsResponseObject interface {
+	VisitGetAllChatGroupsResponse(ctx *fiber.Ctx) error
+}
+
+type GetAllChatGroups200JSONResponse []ChatGroup
+
+func (response GetAllChatGroups200JSONResponse) VisitGetAllChatGroupsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetAllChatGroups500JSONResponse GlobalResponses
+
+func (response GetAllChatGroups500JSONResponse) VisitGetAllChatGroupsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type CreateChatGroupRequestObject struct {
+	Body *CreateChatGroupJSONRequestBody
+}
+
+type CreateChatGroupResponseObject interface {
+	VisitCreateChatGroupResponse(ctx *fiber.Ctx) error
+}
+
+type CreateChatGroup201JSONResponse ChatGroup
+
+func (response CreateChatGroup201JSONResponse) VisitCreateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateChatGroup400JSONResponse GlobalResponses
+
+func (response CreateChatGroup400JSONResponse) VisitCreateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type CreateChatGroup500JSONResponse GlobalResponses
+
+func (response CreateChatGroup500JSONResponse) VisitCreateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteChatGroupRequestObject struct {
+	ChatGroupId string `json:"chatGroupId"`
+}
+
+type DeleteChatGroupResponseObject interface {
+	VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteChatGroup204Response struct {
+}
+
+func (response DeleteChatGroup204Response) VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteChatGroup404JSONResponse GlobalResponses
+
+func (response DeleteChatGroup404JSONResponse) VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteChatGroup500JSONResponse GlobalResponses
+
+func (response DeleteChatGroup500JSONResponse) VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGroupByIdRequestObject struct {
+	ChatGroupId string `json:"chatGroupId"`
+}
+
+type GetChatGroupByIdResponseObject interface {
+	VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error
+}
+
+type GetChatGroupById200JSONResponse ChatGroup
+
+func (response GetChatGroupById200JSONResponse) VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGroupById404JSONResponse GlobalResponses
+
+func (response GetChatGroupById404JSONResponse) VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGroupById500JSONResponse GlobalResponses
+
+func (response GetChatGroupById500JSONResponse) VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChatGroupRequestObject struct {
+	ChatGroupId string `json:"chatGroupId"`
+	Body        *UpdateChatGroupJSONRequestBody
+}
+
+type UpdateChatGroupResponseObject interface {
+	VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateChatGroup200JSONResponse ChatGroup
+
+func (response UpdateChatGroup200JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChatGroup400JSONResponse GlobalResponses
+
+func (response UpdateChatGroup400JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChatGroup404JSONResponse GlobalResponses
+
+func (response UpdateChatGroup404JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChatGroup500JSONResponse GlobalResponses
+
+func (response UpdateChatGroup500JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetAllChatsRequestObject struct {
+}
+
+type GetAllChatsResponseObject interface {
+	VisitGetAllChatsResponse(ctx *fiber.Ctx) error
+}
+
+type GetAllChats200JSONResponse []Chat
+
+func (response GetAllChats200JSONResponse) VisitGetAllChatsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetAllChats500JSONResponse GlobalResponses
+
+func (response GetAllChats500JSONResponse) VisitGetAllChatsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type CreateChatRequestObject struct {
+	Body *CreateChatJSONRequestBody
+}
+
+type CreateChatResponseObject interface {
+	VisitCreateChatResponse(ctx *fiber.Ctx) error
+}
+
+type CreateChat201JSONResponse Chat
+
+func (response CreateChat201JSONResponse) VisitCreateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateChat400JSONResponse GlobalResponses
+
+func (response CreateChat400JSONResponse) VisitCreateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type CreateChat500JSONResponse GlobalResponses
+
+func (response CreateChat500JSONResponse) VisitCreateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteChatRequestObject struct {
+	ChatId string `json:"chatId"`
+}
+
+type DeleteChatResponseObject interface {
+	VisitDeleteChatResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteChat204Response struct {
+}
+
+func (response DeleteChat204Response) VisitDeleteChatResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteChat404JSONResponse GlobalResponses
+
+func (response DeleteChat404JSONResponse) VisitDeleteChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteChat500JSONResponse GlobalResponses
+
+func (response DeleteChat500JSONResponse) VisitDeleteChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatByIdRequestObject struct {
+	ChatId string `json:"chatId"`
+}
+
+type GetChatByIdResponseObject interface {
+	VisitGetChatByIdResponse(ctx *fiber.Ctx) error
+}
+
+type GetChatById200JSONResponse Chat
+
+func (response GetChatById200JSONResponse) VisitGetChatByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatById404JSONResponse GlobalResponses
+
+func (response GetChatById404JSONResponse) VisitGetChatByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatById500JSONResponse GlobalResponses
+
+func (response GetChatById500JSONResponse) VisitGetChatByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChatRequestObject struct {
+	ChatId string `json:"chatId"`
+	Body   *UpdateChatJSONRequestBody
+}
+
+type UpdateChatResponseObject interface {
+	VisitUpdateChatResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateChat200JSONResponse Chat
+
+func (response UpdateChat200JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChat400JSONResponse GlobalResponses
+
+func (response UpdateChat400JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChat404JSONResponse GlobalResponses
+
+func (response UpdateChat404JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateChat500JSONResponse GlobalResponses
+
+func (response UpdateChat500JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGalleryRequestObject struct {
+	ChatId string `json:"chatId"`
+	Params GetChatGalleryParams
+}
+
+type GetChatGalleryResponseObject interface {
+	VisitGetChatGalleryResponse(ctx *fiber.Ctx) error
+}
+
+type GetChatGallery200JSONResponse ChatGallery
+
+func (response GetChatGallery200JSONResponse) VisitGetChatGalleryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGallery400JSONResponse GlobalResponses
+
+func (response GetChatGallery400JSONResponse) VisitGetChatGalleryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGallery403JSONResponse GlobalResponses
+
+func (response GetChatGallery403JSONResponse) VisitGetChatGalleryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGallery404JSONResponse GlobalResponses
+
+func (response GetChatGallery404JSONResponse) VisitGetChatGalleryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetChatGallery500JSONResponse GlobalResponses
+
+func (response GetChatGallery500JSONResponse) VisitGetChatGalleryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetAllHighlightsRequestObject struct {
+}
+
+type GetAllHighlightsResponseObject interface {
+	VisitGetAllHighlightsResponse(ctx *fiber.Ctx) error
+}
+
+type GetAllHighlights200JSONResponse []Highlight
+
+func (response GetAllHighlights200JSONResponse) VisitGetAllHighlightsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetAllHighlights500JSONResponse GlobalResponses
+
+func (response GetAllHighlights500JSONResponse) VisitGetAllHighlightsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type CreateHighlightRequestObject struct {
+	Body *CreateHighlightJSONRequestBody
+}
+
+type CreateHighlightResponseObject interface {
+	VisitCreateHighlightResponse(ctx *fiber.Ctx) error
+}
+
+type CreateHighlight201JSONResponse Highlight
+
+func (response CreateHighlight201JSONResponse) VisitCreateHighlightResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateHighlight400JSONResponse GlobalResponses
+
+func (response CreateHighlight400JSONResponse) VisitCreateHighlightResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type CreateHighlight500JSONResponse GlobalResponses
+
+func (response CreateHighlight500JSONResponse) VisitCreateHighlightResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteHighlightByIdRequestObject struct {
+	HighlightId string `json:"highlightId"`
+}
+
+type DeleteHighlightByIdResponseObject interface {
+	VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteHighlightById204Response struct {
+}
+
+func (response DeleteHighlightById204Response) VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteHighlightById404JSONResponse GlobalResponses
+
+func (response DeleteHighlightById404JSONResponse) VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteHighlightById500JSONResponse GlobalResponses
+
+func (response DeleteHighlightById500JSONResponse) VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetHighlightByIdRequestObject struct {
+	HighlightId string `json:"highlightId"`
+}
+
+type GetHighlightByIdResponseObject interface {
+	VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error
+}
+
+type GetHighlightById200JSONResponse Highlight
+
+func (response GetHighlightById200JSONResponse) VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetHighlightById404JSONResponse GlobalResponses
+
+func (response GetHighlightById404JSONResponse) VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetHighlightById500JSONResponse GlobalResponses
+
+func (response GetHighlightById500JSONResponse) VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateHighlightByIdRequestObject struct {
+	HighlightId string `json:"highlightId"`
+	Body        *UpdateHighlightByIdJSONRequestBody
+}
+
+type UpdateHighlightByIdResponseObject interface {
+	VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateHighlightById200JSONResponse Highlight
+
+func (response UpdateHighlightById200JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateHighlightById400JSONResponse GlobalResponses
+
+func (response UpdateHighlightById400JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateHighlightById404JSONResponse GlobalResponses
+
+func (response UpdateHighlightById404JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateHighlightById500JSONResponse GlobalResponses
+
+func (response UpdateHighlightById500JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteDeviceKeysRequestObject struct {
+	DeviceId DeviceIdParam `json:"deviceId"`
+}
+
+type DeleteDeviceKeysResponseObject interface {
+	VisitDeleteDeviceKeysResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteDeviceKeys200JSONResponse GenericSuccessResponse
+
+func (response DeleteDeviceKeys200JSONResponse) VisitDeleteDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteDeviceKeys401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response DeleteDeviceKeys401JSONResponse) VisitDeleteDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteDeviceKeys404JSONResponse struct{ N404NotFoundJSONResponse }
+
+func (response DeleteDeviceKeys404JSONResponse) VisitDeleteDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteDeviceKeys500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response DeleteDeviceKeys500JSONResponse) VisitDeleteDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type PublishDeviceKeysRequestObject struct {
+	DeviceId DeviceIdParam `json:"deviceId"`
+	Body     *PublishDeviceKeysJSONRequestBody
+}
+
+type PublishDeviceKeysResponseObject interface {
+	VisitPublishDeviceKeysResponse(ctx *fiber.Ctx) error
+}
+
+type PublishDeviceKeys200JSONResponse struct{ PreKeyCountSuccessJSONResponse }
+
+func (response PublishDeviceKeys200JSONResponse) VisitPublishDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type PublishDeviceKeys400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response PublishDeviceKeys400JSONResponse) VisitPublishDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type PublishDeviceKeys401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response PublishDeviceKeys401JSONResponse) VisitPublishDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type PublishDeviceKeys409JSONResponse GlobalResponses
+
+func (response PublishDeviceKeys409JSONResponse) VisitPublishDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(409)
+
+	return ctx.JSON(&response)
+}
+
+type PublishDeviceKeys500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response PublishDeviceKeys500JSONResponse) VisitPublishDeviceKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type UploadOneTimePreKeysRequestObject struct {
+	DeviceId DeviceIdParam `json:"deviceId"`
+	Body     *UploadOneTimePreKeysJSONRequestBody
+}
+
+type UploadOneTimePreKeysResponseObject interface {
+	VisitUploadOneTimePreKeysResponse(ctx *fiber.Ctx) error
+}
+
+type UploadOneTimePreKeys200JSONResponse struct{ PreKeyCountSuccessJSONResponse }
+
+func (response UploadOneTimePreKeys200JSONResponse) VisitUploadOneTimePreKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UploadOneTimePreKeys400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response UploadOneTimePreKeys400JSONResponse) VisitUploadOneTimePreKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type UploadOneTimePreKeys401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response UploadOneTimePreKeys401JSONResponse) VisitUploadOneTimePreKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type UploadOneTimePreKeys404JSONResponse struct{ N404NotFoundJSONResponse }
+
+func (response UploadOneTimePreKeys404JSONResponse) VisitUploadOneTimePreKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UploadOneTimePreKeys500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response UploadOneTimePreKeys500JSONResponse) VisitUploadOneTimePreKeysResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetPreKeyCountRequestObject struct {
+	DeviceId DeviceIdParam `json:"deviceId"`
+}
+
+type GetPreKeyCountResponseObject interface {
+	VisitGetPreKeyCountResponse(ctx *fiber.Ctx) error
+}
+
+type GetPreKeyCount200JSONResponse struct{ PreKeyCountSuccessJSONResponse }
+
+func (response GetPreKeyCount200JSONResponse) VisitGetPreKeyCountResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetPreKeyCount401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response GetPreKeyCount401JSONResponse) VisitGetPreKeyCountResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetPreKeyCount404JSONResponse struct{ N404NotFoundJSONResponse }
+
+func (response GetPreKeyCount404JSONResponse) VisitGetPreKeyCountResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetPreKeyCount500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response GetPreKeyCount500JSONResponse) VisitGetPreKeyCountResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundlesRequestObject struct {
+	UserId string `json:"userId"`
+}
+
+type GetUserPreKeyBundlesResponseObject interface {
+	VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error
+}
+
+type GetUserPreKeyBundles200JSONResponse PreKeyBundlesResponse
+
+func (response GetUserPreKeyBundles200JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundles400JSONResponse struct{ N400BadRequestJSONResponse }
+
+func (response GetUserPreKeyBundles400JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundles401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response GetUserPreKeyBundles401JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundles403JSONResponse ErrorGenericResponse
+
+func (response GetUserPreKeyBundles403JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundles404JSONResponse struct{ N404NotFoundJSONResponse }
+
+func (response GetUserPreKeyBundles404JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundles429JSONResponse ErrorGenericResponse
+
+func (response GetUserPreKeyBundles429JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(429)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPreKeyBundles500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
+
+func (response GetUserPreKeyBundles500JSONResponse) VisitGetUserPreKeyBundlesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type UploadMediaRequestObject struct {
+	Body *multipart.Reader
+}
+
+type UploadMediaResponseObject interface {
+	VisitUploadMediaResponse(ctx *fiber.Ctx) error
+}
+
+type UploadMedia201JSONResponse Media
+
+func (response UploadMedia201JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
 }
 
-type Index200JSONResponse ChatCreateRequest
+type UploadMedia400JSONResponse GlobalResponses
 
-func (response Index200JSONResponse) VisitIndexResponse(ctx *fiber.Ctx) error {
+func (response UploadMedia400JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type AuthLoginRequestObject struct {
-	Body *AuthLoginJSONRequestBody
-}
+type UploadMedia403JSONResponse GlobalResponses
 
-type AuthLoginResponseObject interface {
-	VisitAuthLoginResponse(ctx *fiber.Ctx) error
+func (response UploadMedia403JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
 }
 
-type AuthLogin200JSONResponse struct{ AuthLoginSuccessJSONResponse }
+type UploadMedia404JSONResponse GlobalResponses
 
-func (response AuthLogin200JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+func (response UploadMedia404JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type AuthLogin400JSONResponse struct{ N400BadRequestJSONResponse }
+type UploadMedia413JSONResponse GlobalResponses
 
-func (response AuthLogin400JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+func (response UploadMedia413JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+	ctx.Status(413)
 
 	return ctx.JSON(&response)
 }
 
-type AuthLogin401JSONResponse struct{ N401UnauthorizedJSONResponse }
+type UploadMedia415JSONResponse GlobalResponses
 
-func (response AuthLogin401JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+func (response UploadMedia415JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	ctx.Status(415)
 
 	return ctx.JSON(&response)
 }
 
-type AuthLogin500JSONResponse struct {
-	N500InternalServerErrorJSONResponse
-}
+type UploadMedia500JSONResponse GlobalResponses
 
-func (response AuthLogin500JSONResponse) VisitAuthLoginResponse(ctx *fiber.Ctx) error {
+func (response UploadMedia500JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type AuthRegisterRequestObject struct {
-	Body *AuthRegisterJSONRequestBody
+type CreateUploadRequestObject struct {
+	Body *CreateUploadJSONRequestBody
 }
 
-type AuthRegisterResponseObject interface {
-	VisitAuthRegisterResponse(ctx *fiber.Ctx) error
+type CreateUploadResponseObject interface {
+	VisitCreateUploadResponse(ctx *fiber.Ctx) error
 }
 
-type AuthRegister201JSONResponse struct {
-	UserRegistrationSuccessJSONResponse
-}
+type CreateUpload201JSONResponse Upload
 
-func (response AuthRegister201JSONResponse) VisitAuthRegisterResponse(ctx *fiber.Ctx) error {
+func (response CreateUpload201JSONResponse) VisitCreateUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(201)
 
 	return ctx.JSON(&response)
 }
 
-type AuthRegister400JSONResponse struct{ N400BadRequestJSONResponse }
+type CreateUpload400JSONResponse GlobalResponses
 
-func (response AuthRegister400JSONResponse) VisitAuthRegisterResponse(ctx *fiber.Ctx) error {
+func (response CreateUpload400JSONResponse) VisitCreateUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type AuthRegister500JSONResponse struct {
-	N500InternalServerErrorJSONResponse
-}
+type CreateUpload403JSONResponse GlobalResponses
 
-func (response AuthRegister500JSONResponse) VisitAuthRegisterResponse(ctx *fiber.Ctx) error {
+func (response CreateUpload403JSONResponse) VisitCreateUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type GetAllChatGroupsRequestObject struct {
-}
+type CreateUpload404JSONResponse GlobalResponses
 
-type GetAllChatGroupsResponseObject interface {
-	VisitGetAllChatGroupsResponse(ctx *fiber.Ctx) error
+func (response CreateUpload404JSONResponse) VisitCreateUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
 }
 
-type GetAllChatGroups200JSONResponse []ChatGroup
+type CreateUpload413JSONResponse GlobalResponses
 
-func (response GetAllChatGroups200JSONResponse) VisitGetAllChatGroupsResponse(ctx *fiber.Ctx) error {
+func (response CreateUpload413JSONResponse) VisitCreateUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(413)
 
 	return ctx.JSON(&response)
 }
 
-type GetAllChatGroups500JSONResponse GlobalResponses
+type CreateUpload500JSONResponse GlobalResponses
 
-func (response GetAllChatGroups500JSONResponse) VisitGetAllChatGroupsResponse(ctx *fiber.Ctx) error {
+func (response CreateUpload500JSONResponse) VisitCreateUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type CreateChatGroupRequestObject struct {
-	Body *CreateChatGroupJSONRequestBody
+type DeleteUploadRequestObject struct {
+	UploadId string `json:"uploadId"`
 }
 
-type CreateChatGroupResponseObject interface {
-	VisitCreateChatGroupResponse(ctx *fiber.Ctx) error
+type DeleteUploadResponseObject interface {
+	VisitDeleteUploadResponse(ctx *fiber.Ctx) error
 }
 
-type CreateChatGroup201JSONResponse ChatGroup
+type DeleteUpload204Response struct {
+}
 
-func (response CreateChatGroup201JSONResponse) VisitCreateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response DeleteUpload204Response) VisitDeleteUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteUpload403JSONResponse GlobalResponses
+
+func (response DeleteUpload403JSONResponse) VisitDeleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type CreateChatGroup400JSONResponse GlobalResponses
+type DeleteUpload404JSONResponse GlobalResponses
 
-func (response CreateChatGroup400JSONResponse) VisitCreateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response DeleteUpload404JSONResponse) VisitDeleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type CreateChatGroup500JSONResponse GlobalResponses
+type DeleteUpload500JSONResponse GlobalResponses
 
-func (response CreateChatGroup500JSONResponse) VisitCreateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response DeleteUpload500JSONResponse) VisitDeleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteChatGroupRequestObject struct {
-	ChatGroupId string `json:"chatGroupId"`
+type GetUploadRequestObject struct {
+	UploadId string `json:"uploadId"`
 }
 
-type DeleteChatGroupResponseObject interface {
-	VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error
+type GetUploadResponseObject interface {
+	VisitGetUploadResponse(ctx *fiber.Ctx) error
 }
 
-type DeleteChatGroup204Response struct {
+type GetUpload200JSONResponse Upload
+
+func (response GetUpload200JSONResponse) VisitGetUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
 }
 
-func (response DeleteChatGroup204Response) VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error {
-	ctx.Status(204)
-	return nil
+type GetUpload403JSONResponse GlobalResponses
+
+func (response GetUpload403JSONResponse) VisitGetUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
 }
 
-type DeleteChatGroup404JSONResponse GlobalResponses
+type GetUpload404JSONResponse GlobalResponses
 
-func (response DeleteChatGroup404JSONResponse) VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error {
+func (response GetUpload404JSONResponse) VisitGetUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteChatGroup500JSONResponse GlobalResponses
+type GetUpload500JSONResponse GlobalResponses
 
-func (response DeleteChatGroup500JSONResponse) VisitDeleteChatGroupResponse(ctx *fiber.Ctx) error {
+func (response GetUpload500JSONResponse) VisitGetUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type GetChatGroupByIdRequestObject struct {
-	ChatGroupId string `json:"chatGroupId"`
+type UploadChunkRequestObject struct {
+	UploadId string `json:"uploadId"`
+	Params   UploadChunkParams
+	Body     io.Reader
 }
 
-type GetChatGroupByIdResponseObject interface {
-	VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error
+type UploadChunkResponseObject interface {
+	VisitUploadChunkResponse(ctx *fiber.Ctx) error
 }
 
-type GetChatGroupById200JSONResponse ChatGroup
+type UploadChunk200JSONResponse Upload
 
-func (response GetChatGroupById200JSONResponse) VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk200JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type GetChatGroupById404JSONResponse GlobalResponses
+type UploadChunk400JSONResponse GlobalResponses
 
-func (response GetChatGroupById404JSONResponse) VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk400JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type GetChatGroupById500JSONResponse GlobalResponses
+type UploadChunk403JSONResponse GlobalResponses
 
-func (response GetChatGroupById500JSONResponse) VisitGetChatGroupByIdResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk403JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateChatGroupRequestObject struct {
-	ChatGroupId string `json:"chatGroupId"`
-	Body        *UpdateChatGroupJSONRequestBody
-}
+type UploadChunk404JSONResponse GlobalResponses
 
-type UpdateChatGroupResponseObject interface {
-	VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error
+func (response UploadChunk404JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
 }
 
-type UpdateChatGroup200JSONResponse ChatGroup
+type UploadChunk409JSONResponse GlobalResponses
 
-func (response UpdateChatGroup200JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk409JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(409)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateChatGroup400JSONResponse GlobalResponses
+type UploadChunk413JSONResponse GlobalResponses
 
-func (response UpdateChatGroup400JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk413JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+	ctx.Status(413)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateChatGroup404JSONResponse GlobalResponses
+type UploadChunk415JSONResponse GlobalResponses
 
-func (response UpdateChatGroup404JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk415JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	ctx.Status(415)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateChatGroup500JSONResponse GlobalResponses
+type UploadChunk500JSONResponse GlobalResponses
 
-func (response UpdateChatGroup500JSONResponse) VisitUpdateChatGroupResponse(ctx *fiber.Ctx) error {
+func (response UploadChunk500JSONResponse) VisitUploadChunkResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type GetAllChatsRequestObject struct {
+type CompleteUploadRequestObject struct {
+	UploadId string `json:"uploadId"`
 }
 
-type GetAllChatsResponseObject interface {
-	VisitGetAllChatsResponse(ctx *fiber.Ctx) error
+type CompleteUploadResponseObject interface {
+	VisitCompleteUploadResponse(ctx *fiber.Ctx) error
 }
 
-type GetAllChats200JSONResponse []Chat
+type CompleteUpload201JSONResponse Media
 
-func (response GetAllChats200JSONResponse) VisitGetAllChatsResponse(ctx *fiber.Ctx) error {
+func (response CompleteUpload201JSONResponse) VisitCompleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(201)
 
 	return ctx.JSON(&response)
 }
 
-type GetAllChats500JSONResponse GlobalResponses
+type CompleteUpload403JSONResponse GlobalResponses
 
-func (response GetAllChats500JSONResponse) VisitGetAllChatsResponse(ctx *fiber.Ctx) error {
+func (response CompleteUpload403JSONResponse) VisitCompleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type CreateChatRequestObject struct {
-	Body *CreateChatJSONRequestBody
-}
-
-type CreateChatResponseObject interface {
-	VisitCreateChatResponse(ctx *fiber.Ctx) error
-}
-
-type CreateChat201JSONResponse Chat
+type CompleteUpload404JSONResponse GlobalResponses
 
-func (response CreateChat201JSONResponse) VisitCreateChatResponse(ctx *fiber.Ctx) error {
+func (response CompleteUpload404JSONResponse) VisitCompleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type CreateChat400JSONResponse GlobalResponses
+type CompleteUpload409JSONResponse GlobalResponses
 
-func (response CreateChat400JSONResponse) VisitCreateChatResponse(ctx *fiber.Ctx) error {
+func (response CompleteUpload409JSONResponse) VisitCompleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+	ctx.Status(409)
 
 	return ctx.JSON(&response)
 }
 
-type CreateChat500JSONResponse GlobalResponses
+type CompleteUpload500JSONResponse GlobalResponses
 
-func (response CreateChat500JSONResponse) VisitCreateChatResponse(ctx *fiber.Ctx) error {
+func (response CompleteUpload500JSONResponse) VisitCompleteUploadResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteChatRequestObject struct {
-	ChatId string `json:"chatId"`
+type DeleteMediaRequestObject struct {
+	MediaId string `json:"mediaId"`
 }
 
-type DeleteChatResponseObject interface {
-	VisitDeleteChatResponse(ctx *fiber.Ctx) error
+type DeleteMediaResponseObject interface {
+	VisitDeleteMediaResponse(ctx *fiber.Ctx) error
 }
 
-type DeleteChat204Response struct {
+type DeleteMedia204Response struct {
 }
 
-func (response DeleteChat204Response) VisitDeleteChatResponse(ctx *fiber.Ctx) error {
+func (response DeleteMedia204Response) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Status(204)
 	return nil
 }
 
-type DeleteChat404JSONResponse GlobalResponses
+type DeleteMedia403JSONResponse GlobalResponses
 
-func (response DeleteChat404JSONResponse) VisitDeleteChatResponse(ctx *fiber.Ctx) error {
+func (response DeleteMedia403JSONResponse) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteMedia404JSONResponse GlobalResponses
+
+func (response DeleteMedia404JSONResponse) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteChat500JSONResponse GlobalResponses
+type DeleteMedia500JSONResponse GlobalResponses
 
-func (response DeleteChat500JSONResponse) VisitDeleteChatResponse(ctx *fiber.Ctx) error {
+func (response DeleteMedia500JSONResponse) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type GetChatByIdRequestObject struct {
-	ChatId string `json:"chatId"`
+type GetMediaByIdRequestObject struct {
+	MediaId string `json:"mediaId"`
 }
 
-type GetChatByIdResponseObject interface {
-	VisitGetChatByIdResponse(ctx *fiber.Ctx) error
+type GetMediaByIdResponseObject interface {
+	VisitGetMediaByIdResponse(ctx *fiber.Ctx) error
 }
 
-type GetChatById200JSONResponse Chat
+type GetMediaById200JSONResponse Media
 
-func (response GetChatById200JSONResponse) VisitGetChatByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMediaById200JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type GetChatById404JSONResponse GlobalResponses
+type GetMediaById403JSONResponse GlobalResponses
 
-func (response GetChatById404JSONResponse) VisitGetChatByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMediaById403JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type GetMediaById404JSONResponse GlobalResponses
+
+func (response GetMediaById404JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type GetChatById500JSONResponse GlobalResponses
+type GetMediaById500JSONResponse GlobalResponses
 
-func (response GetChatById500JSONResponse) VisitGetChatByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMediaById500JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateChatRequestObject struct {
-	ChatId string `json:"chatId"`
-	Body   *UpdateChatJSONRequestBody
+type GetMediaContentRequestObject struct {
+	MediaId string `json:"mediaId"`
+	Params  GetMediaContentParams
 }
 
-type UpdateChatResponseObject interface {
-	VisitUpdateChatResponse(ctx *fiber.Ctx) error
+type GetMediaContentResponseObject interface {
+	VisitGetMediaContentResponse(ctx *fiber.Ctx) error
 }
 
-type UpdateChat200JSONResponse Chat
+type GetMediaContent200ResponseHeaders struct {
+	AcceptRanges       string
+	CacheControl       string
+	ContentDisposition string
+}
 
-func (response UpdateChat200JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
+type GetMediaContent200AsteriskResponse struct {
+	Body          io.Reader
+	Headers       GetMediaContent200ResponseHeaders
+	ContentType   string
+	ContentLength int64
+}
+
+func (response GetMediaContent200AsteriskResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	ctx.Response().Header.Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", response.ContentType)
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
 	ctx.Status(200)
 
-	return ctx.JSON(&response)
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
 }
 
-type UpdateChat400JSONResponse GlobalResponses
+type GetMediaContent206ResponseHeaders struct {
+	AcceptRanges       string
+	CacheControl       string
+	ContentDisposition string
+	ContentRange       string
+}
 
-func (response UpdateChat400JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+type GetMediaContent206AsteriskResponse struct {
+	Body          io.Reader
+	Headers       GetMediaContent206ResponseHeaders
+	ContentType   string
+	ContentLength int64
+}
 
-	return ctx.JSON(&response)
+func (response GetMediaContent206AsteriskResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	ctx.Response().Header.Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	ctx.Response().Header.Set("Content-Type", response.ContentType)
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
 }
 
-type UpdateChat404JSONResponse GlobalResponses
+type GetMediaContent403JSONResponse GlobalResponses
 
-func (response UpdateChat404JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
+func (response GetMediaContent403JSONResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateChat500JSONResponse GlobalResponses
+type GetMediaContent404JSONResponse GlobalResponses
 
-func (response UpdateChat500JSONResponse) VisitUpdateChatResponse(ctx *fiber.Ctx) error {
+func (response GetMediaContent404JSONResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type GetAllHighlightsRequestObject struct {
-}
+type GetMediaContent409JSONResponse GlobalResponses
 
-type GetAllHighlightsResponseObject interface {
-	VisitGetAllHighlightsResponse(ctx *fiber.Ctx) error
+func (response GetMediaContent409JSONResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(409)
+
+	return ctx.JSON(&response)
 }
 
-type GetAllHighlights200JSONResponse []Highlight
+type GetMediaContent416JSONResponse GlobalResponses
 
-func (response GetAllHighlights200JSONResponse) VisitGetAllHighlightsResponse(ctx *fiber.Ctx) error {
+func (response GetMediaContent416JSONResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(416)
 
 	return ctx.JSON(&response)
 }
 
-type GetAllHighlights500JSONResponse GlobalResponses
+type GetMediaContent500JSONResponse GlobalResponses
 
-func (response GetAllHighlights500JSONResponse) VisitGetAllHighlightsResponse(ctx *fiber.Ctx) error {
+func (response GetMediaContent500JSONResponse) VisitGetMediaContentResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type CreateHighlightRequestObject struct {
-	Body *CreateHighlightJSONRequestBody
+type GetMessagesByChatIdRequestObject struct {
+	Params GetMessagesByChatIdParams
 }
 
-type CreateHighlightResponseObject interface {
-	VisitCreateHighlightResponse(ctx *fiber.Ctx) error
+type GetMessagesByChatIdResponseObject interface {
+	VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error
 }
 
-type CreateHighlight201JSONResponse Highlight
+type GetMessagesByChatId200JSONResponse []Message
 
-func (response CreateHighlight201JSONResponse) VisitCreateHighlightResponse(ctx *fiber.Ctx) error {
+func (response GetMessagesByChatId200JSONResponse) VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type CreateHighlight400JSONResponse GlobalResponses
+type GetMessagesByChatId400JSONResponse GlobalResponses
 
-func (response CreateHighlight400JSONResponse) VisitCreateHighlightResponse(ctx *fiber.Ctx) error {
+func (response GetMessagesByChatId400JSONResponse) VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type CreateHighlight500JSONResponse GlobalResponses
+type GetMessagesByChatId500JSONResponse GlobalResponses
 
-func (response CreateHighlight500JSONResponse) VisitCreateHighlightResponse(ctx *fiber.Ctx) error {
+func (response GetMessagesByChatId500JSONResponse) VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteHighlightByIdRequestObject struct {
-	HighlightId string `json:"highlightId"`
+type SendMessageRequestObject struct {
+	Body *SendMessageJSONRequestBody
 }
 
-type DeleteHighlightByIdResponseObject interface {
-	VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error
+type SendMessageResponseObject interface {
+	VisitSendMessageResponse(ctx *fiber.Ctx) error
 }
 
-type DeleteHighlightById204Response struct {
+type SendMessage201JSONResponse MessageSuccessResponse
+
+func (response SendMessage201JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
 }
 
-func (response DeleteHighlightById204Response) VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error {
-	ctx.Status(204)
-	return nil
+type SendMessage400JSONResponse GlobalResponses
+
+func (response SendMessage400JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(400)
+
+	return ctx.JSON(&response)
 }
 
-type DeleteHighlightById404JSONResponse GlobalResponses
+type SendMessage403JSONResponse GlobalResponses
 
-func (response DeleteHighlightById404JSONResponse) VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response SendMessage403JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteHighlightById500JSONResponse GlobalResponses
+type SendMessage500JSONResponse GlobalResponses
 
-func (response DeleteHighlightById500JSONResponse) VisitDeleteHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response SendMessage500JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type GetHighlightByIdRequestObject struct {
-	HighlightId string `json:"highlightId"`
+type DeleteMessageRequestObject struct {
+	MessageId string `json:"messageId"`
 }
 
-type GetHighlightByIdResponseObject interface {
-	VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error
+type DeleteMessageResponseObject interface {
+	VisitDeleteMessageResponse(ctx *fiber.Ctx) error
 }
 
-type GetHighlightById200JSONResponse Highlight
+type DeleteMessage204Response struct {
+}
 
-func (response GetHighlightById200JSONResponse) VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response DeleteMessage204Response) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteMessage403JSONResponse GlobalResponses
+
+func (response DeleteMessage403JSONResponse) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type GetHighlightById404JSONResponse GlobalResponses
+type DeleteMessage404JSONResponse GlobalResponses
 
-func (response GetHighlightById404JSONResponse) VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response DeleteMessage404JSONResponse) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type GetHighlightById500JSONResponse GlobalResponses
+type DeleteMessage500JSONResponse GlobalResponses
 
-func (response GetHighlightById500JSONResponse) VisitGetHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response DeleteMessage500JSONResponse) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateHighlightByIdRequestObject struct {
-	HighlightId string `json:"highlightId"`
-	Body        *UpdateHighlightByIdJSONRequestBody
+type GetMessageByIdRequestObject struct {
+	MessageId string `json:"messageId"`
 }
 
-type UpdateHighlightByIdResponseObject interface {
-	VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error
+type GetMessageByIdResponseObject interface {
+	VisitGetMessageByIdResponse(ctx *fiber.Ctx) error
 }
 
-type UpdateHighlightById200JSONResponse Highlight
+type GetMessageById200JSONResponse MessageSuccessResponse
 
-func (response UpdateHighlightById200JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessageById200JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateHighlightById400JSONResponse GlobalResponses
+type GetMessageById403JSONResponse GlobalResponses
 
-func (response UpdateHighlightById400JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessageById403JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateHighlightById404JSONResponse GlobalResponses
+type GetMessageById404JSONResponse GlobalResponses
 
-func (response UpdateHighlightById404JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessageById404JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateHighlightById500JSONResponse GlobalResponses
+type GetMessageById500JSONResponse GlobalResponses
 
-func (response UpdateHighlightById500JSONResponse) VisitUpdateHighlightByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessageById500JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type UploadMediaRequestObject struct {
-	Body *multipart.Reader
+type UpdateMessageRequestObject struct {
+	MessageId string `json:"messageId"`
+	Body      *UpdateMessageJSONRequestBody
 }
 
-type UploadMediaResponseObject interface {
-	VisitUploadMediaResponse(ctx *fiber.Ctx) error
+type UpdateMessageResponseObject interface {
+	VisitUpdateMessageResponse(ctx *fiber.Ctx) error
 }
 
-type UploadMedia201JSONResponse Media
+type UpdateMessage200JSONResponse MessageSuccessResponse
 
-func (response UploadMedia201JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
+func (response UpdateMessage200JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type UploadMedia400JSONResponse GlobalResponses
+type UpdateMessage400JSONResponse GlobalResponses
 
-func (response UploadMedia400JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
+func (response UpdateMessage400JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type UploadMedia500JSONResponse GlobalResponses
+type UpdateMessage403JSONResponse GlobalResponses
 
-func (response UploadMedia500JSONResponse) VisitUploadMediaResponse(ctx *fiber.Ctx) error {
+func (response UpdateMessage403JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteMediaRequestObject struct {
-	MediaId string `json:"mediaId"`
+type UpdateMessage404JSONResponse GlobalResponses
+
+func (response UpdateMessage404JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
 }
 
-type DeleteMediaResponseObject interface {
-	VisitDeleteMediaResponse(ctx *fiber.Ctx) error
+type UpdateMessage500JSONResponse GlobalResponses
+
+func (response UpdateMessage500JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
 }
 
-type DeleteMedia204Response struct {
+type GetMessagePlayedReceiptsRequestObject struct {
+	MessageId string `json:"messageId"`
 }
 
-func (response DeleteMedia204Response) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
-	ctx.Status(204)
-	return nil
+type GetMessagePlayedReceiptsResponseObject interface {
+	VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error
 }
 
-type DeleteMedia404JSONResponse GlobalResponses
+type GetMessagePlayedReceipts200JSONResponse []PlayedReceipt
 
-func (response DeleteMedia404JSONResponse) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
+func (response GetMessagePlayedReceipts200JSONResponse) VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteMedia500JSONResponse GlobalResponses
+type GetMessagePlayedReceipts400JSONResponse GlobalResponses
 
-func (response DeleteMedia500JSONResponse) VisitDeleteMediaResponse(ctx *fiber.Ctx) error {
+func (response GetMessagePlayedReceipts400JSONResponse) VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type GetMediaByIdRequestObject struct {
-	MediaId string `json:"mediaId"`
-}
+type GetMessagePlayedReceipts401JSONResponse GlobalResponses
 
-type GetMediaByIdResponseObject interface {
-	VisitGetMediaByIdResponse(ctx *fiber.Ctx) error
+func (response GetMessagePlayedReceipts401JSONResponse) VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
 }
 
-type GetMediaById200JSONResponse Media
+type GetMessagePlayedReceipts403JSONResponse GlobalResponses
 
-func (response GetMediaById200JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessagePlayedReceipts403JSONResponse) VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type GetMediaById404JSONResponse GlobalResponses
+type GetMessagePlayedReceipts404JSONResponse GlobalResponses
 
-func (response GetMediaById404JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessagePlayedReceipts404JSONResponse) VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type GetMediaById500JSONResponse GlobalResponses
+type GetMessagePlayedReceipts500JSONResponse GlobalResponses
 
-func (response GetMediaById500JSONResponse) VisitGetMediaByIdResponse(ctx *fiber.Ctx) error {
+func (response GetMessagePlayedReceipts500JSONResponse) VisitGetMessagePlayedReceiptsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type GetMessagesByChatIdRequestObject struct {
-	Params GetMessagesByChatIdParams
+type MarkMessagePlayedRequestObject struct {
+	MessageId string `json:"messageId"`
 }
 
-type GetMessagesByChatIdResponseObject interface {
-	VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error
+type MarkMessagePlayedResponseObject interface {
+	VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error
 }
 
-type GetMessagesByChatId200JSONResponse []Message
+type MarkMessagePlayed200JSONResponse PlayedReceipt
 
-func (response GetMessagesByChatId200JSONResponse) VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error {
+func (response MarkMessagePlayed200JSONResponse) VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type GetMessagesByChatId400JSONResponse GlobalResponses
+type MarkMessagePlayed400JSONResponse GlobalResponses
 
-func (response GetMessagesByChatId400JSONResponse) VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error {
+func (response MarkMessagePlayed400JSONResponse) VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type GetMessagesByChatId500JSONResponse GlobalResponses
+type MarkMessagePlayed401JSONResponse GlobalResponses
 
-func (response GetMessagesByChatId500JSONResponse) VisitGetMessagesByChatIdResponse(ctx *fiber.Ctx) error {
+func (response MarkMessagePlayed401JSONResponse) VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(401)
 
 	return ctx.JSON(&response)
 }
 
-type SendMessageRequestObject struct {
-	Body *SendMessageJSONRequestBody
-}
-
-type SendMessageResponseObject interface {
-	VisitSendMessageResponse(ctx *fiber.Ctx) error
-}
-
-type SendMessage201JSONResponse Message
+type MarkMessagePlayed403JSONResponse GlobalResponses
 
-func (response SendMessage201JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
+func (response MarkMessagePlayed403JSONResponse) VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type SendMessage400JSONResponse GlobalResponses
+type MarkMessagePlayed404JSONResponse GlobalResponses
 
-func (response SendMessage400JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
+func (response MarkMessagePlayed404JSONResponse) VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(400)
+	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type SendMessage500JSONResponse GlobalResponses
+type MarkMessagePlayed500JSONResponse GlobalResponses
 
-func (response SendMessage500JSONResponse) VisitSendMessageResponse(ctx *fiber.Ctx) error {
+func (response MarkMessagePlayed500JSONResponse) VisitMarkMessagePlayedResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteMessageRequestObject struct {
-	MessageId string `json:"messageId"`
-}
-
-type DeleteMessageResponseObject interface {
-	VisitDeleteMessageResponse(ctx *fiber.Ctx) error
-}
-
-type DeleteMessage204Response struct {
+type SetChatActivityRequestObject struct {
+	ChatId string `json:"chatId"`
+	Body   *SetChatActivityJSONRequestBody
 }
 
-func (response DeleteMessage204Response) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
-	ctx.Status(204)
-	return nil
+type SetChatActivityResponseObject interface {
+	VisitSetChatActivityResponse(ctx *fiber.Ctx) error
 }
 
-type DeleteMessage404JSONResponse GlobalResponses
+type SetChatActivity200JSONResponse ChatActivityResponse
 
-func (response DeleteMessage404JSONResponse) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
+func (response SetChatActivity200JSONResponse) VisitSetChatActivityResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type DeleteMessage500JSONResponse GlobalResponses
+type SetChatActivity400JSONResponse struct{ N400BadRequestJSONResponse }
 
-func (response DeleteMessage500JSONResponse) VisitDeleteMessageResponse(ctx *fiber.Ctx) error {
+func (response SetChatActivity400JSONResponse) VisitSetChatActivityResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type GetMessageByIdRequestObject struct {
-	MessageId string `json:"messageId"`
-}
+type SetChatActivity401JSONResponse struct{ N401UnauthorizedJSONResponse }
 
-type GetMessageByIdResponseObject interface {
-	VisitGetMessageByIdResponse(ctx *fiber.Ctx) error
+func (response SetChatActivity401JSONResponse) VisitSetChatActivityResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
 }
 
-type GetMessageById200JSONResponse Message
+type SetChatActivity403JSONResponse ErrorGenericResponse
 
-func (response GetMessageById200JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
+func (response SetChatActivity403JSONResponse) VisitSetChatActivityResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	ctx.Status(403)
 
 	return ctx.JSON(&response)
 }
 
-type GetMessageById404JSONResponse GlobalResponses
+type SetChatActivity404JSONResponse struct{ N404NotFoundJSONResponse }
 
-func (response GetMessageById404JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
+func (response SetChatActivity404JSONResponse) VisitSetChatActivityResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type GetMessageById500JSONResponse GlobalResponses
+type SetChatActivity500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
 
-func (response GetMessageById500JSONResponse) VisitGetMessageByIdResponse(ctx *fiber.Ctx) error {
+func (response SetChatActivity500JSONResponse) VisitSetChatActivityResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateMessageRequestObject struct {
-	MessageId string `json:"messageId"`
-	Body      *UpdateMessageJSONRequestBody
+type GetUserPresenceRequestObject struct {
+	UserId string `json:"userId"`
 }
 
-type UpdateMessageResponseObject interface {
-	VisitUpdateMessageResponse(ctx *fiber.Ctx) error
+type GetUserPresenceResponseObject interface {
+	VisitGetUserPresenceResponse(ctx *fiber.Ctx) error
 }
 
-type UpdateMessage200JSONResponse Message
+type GetUserPresence200JSONResponse PresenceResponse
 
-func (response UpdateMessage200JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
+func (response GetUserPresence200JSONResponse) VisitGetUserPresenceResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(200)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateMessage400JSONResponse GlobalResponses
+type GetUserPresence400JSONResponse struct{ N400BadRequestJSONResponse }
 
-func (response UpdateMessage400JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
+func (response GetUserPresence400JSONResponse) VisitGetUserPresenceResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(400)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateMessage404JSONResponse GlobalResponses
+type GetUserPresence401JSONResponse struct{ N401UnauthorizedJSONResponse }
+
+func (response GetUserPresence401JSONResponse) VisitGetUserPresenceResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserPresence404JSONResponse struct{ N404NotFoundJSONResponse }
 
-func (response UpdateMessage404JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
+func (response GetUserPresence404JSONResponse) VisitGetUserPresenceResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
 	return ctx.JSON(&response)
 }
 
-type UpdateMessage500JSONResponse GlobalResponses
+type GetUserPresence500JSONResponse struct {
+	N500InternalServerErrorJSONResponse
+}
 
-func (response UpdateMessage500JSONResponse) VisitUpdateMessageResponse(ctx *fiber.Ctx) error {
+func (response GetUserPresence500JSONResponse) VisitGetUserPresenceResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(500)
 
@@ -2350,7 +4437,7 @@ type GetUserSettingsResponseObject interface {
 	VisitGetUserSettingsResponse(ctx *fiber.Ctx) error
 }
 
-type GetUserSettings200JSONResponse Settings
+type GetUserSettings200JSONResponse SettingsSuccessResponse
 
 func (response GetUserSettings200JSONResponse) VisitGetUserSettingsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
@@ -2386,7 +4473,7 @@ type UpdateUserSettingsResponseObject interface {
 	VisitUpdateUserSettingsResponse(ctx *fiber.Ctx) error
 }
 
-type UpdateUserSettings200JSONResponse Settings
+type UpdateUserSettings200JSONResponse SettingsSuccessResponse
 
 func (response UpdateUserSettings200JSONResponse) VisitUpdateUserSettingsResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
@@ -2422,6 +4509,40 @@ func (response UpdateUserSettings500JSONResponse) VisitUpdateUserSettingsRespons
 	return ctx.JSON(&response)
 }
 
+type GetStorageUsageRequestObject struct {
+}
+
+type GetStorageUsageResponseObject interface {
+	VisitGetStorageUsageResponse(ctx *fiber.Ctx) error
+}
+
+type GetStorageUsage200JSONResponse StorageUsage
+
+func (response GetStorageUsage200JSONResponse) VisitGetStorageUsageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetStorageUsage401JSONResponse GlobalResponses
+
+func (response GetStorageUsage401JSONResponse) VisitGetStorageUsageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetStorageUsage500JSONResponse GlobalResponses
+
+func (response GetStorageUsage500JSONResponse) VisitGetStorageUsageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
 type GetAllUsersRequestObject struct {
 }
 
@@ -2429,7 +4550,7 @@ type GetAllUsersResponseObject interface {
 	VisitGetAllUsersResponse(ctx *fiber.Ctx) error
 }
 
-type GetAllUsers200JSONResponse []User
+type GetAllUsers200JSONResponse UsersSuccessResponse
 
 func (response GetAllUsers200JSONResponse) VisitGetAllUsersResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
@@ -2455,7 +4576,7 @@ type CreateUserResponseObject interface {
 	VisitCreateUserResponse(ctx *fiber.Ctx) error
 }
 
-type CreateUser201JSONResponse User
+type CreateUser201JSONResponse UserSuccessResponse
 
 func (response CreateUser201JSONResponse) VisitCreateUserResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
@@ -2560,7 +4681,7 @@ type UpdateUserResponseObject interface {
 	VisitUpdateUserResponse(ctx *fiber.Ctx) error
 }
 
-type UpdateUser200JSONResponse User
+type UpdateUser200JSONResponse UserSuccessResponse
 
 func (response UpdateUser200JSONResponse) VisitUpdateUserResponse(ctx *fiber.Ctx) error {
 	ctx.Response().Header.Set("Content-Type", "application/json")
@@ -2604,6 +4725,12 @@ type StrictServerInterface interface {
 	// Logs in and returns JWT-auth-token
 	// (POST /auth/login)
 	AuthLogin(ctx context.Context, request AuthLoginRequestObject) (AuthLoginResponseObject, error)
+	// Logs out by revoking a refresh token
+	// (POST /auth/logout)
+	AuthLogout(ctx context.Context, request AuthLogoutRequestObject) (AuthLogoutResponseObject, error)
+	// Exchanges a refresh token for new tokens
+	// (POST /auth/refresh-token)
+	AuthRefreshToken(ctx context.Context, request AuthRefreshTokenRequestObject) (AuthRefreshTokenResponseObject, error)
 	// Registers and returns user information
 	// (POST /auth/register)
 	AuthRegister(ctx context.Context, request AuthRegisterRequestObject) (AuthRegisterResponseObject, error)
@@ -2637,6 +4764,9 @@ type StrictServerInterface interface {
 	// Update a chat
 	// (PUT /chats/{chatId})
 	UpdateChat(ctx context.Context, request UpdateChatRequestObject) (UpdateChatResponseObject, error)
+	// List the shared media of a chat
+	// (GET /chats/{chatId}/media)
+	GetChatGallery(ctx context.Context, request GetChatGalleryRequestObject) (GetChatGalleryResponseObject, error)
 	// Get all highlights
 	// (GET /highlights)
 	GetAllHighlights(ctx context.Context, request GetAllHighlightsRequestObject) (GetAllHighlightsResponseObject, error)
@@ -2652,15 +4782,48 @@ type StrictServerInterface interface {
 	// Update highlight by ID
 	// (PUT /highlights/{highlightId})
 	UpdateHighlightById(ctx context.Context, request UpdateHighlightByIdRequestObject) (UpdateHighlightByIdResponseObject, error)
+	// Remove a device
+	// (DELETE /keys/devices/{deviceId})
+	DeleteDeviceKeys(ctx context.Context, request DeleteDeviceKeysRequestObject) (DeleteDeviceKeysResponseObject, error)
+	// Publish the keys of a device
+	// (PUT /keys/devices/{deviceId})
+	PublishDeviceKeys(ctx context.Context, request PublishDeviceKeysRequestObject) (PublishDeviceKeysResponseObject, error)
+	// Upload one-time prekeys
+	// (POST /keys/devices/{deviceId}/prekeys)
+	UploadOneTimePreKeys(ctx context.Context, request UploadOneTimePreKeysRequestObject) (UploadOneTimePreKeysResponseObject, error)
+	// Count the one-time prekeys left
+	// (GET /keys/devices/{deviceId}/prekeys/count)
+	GetPreKeyCount(ctx context.Context, request GetPreKeyCountRequestObject) (GetPreKeyCountResponseObject, error)
+	// Fetch the prekey bundles of a user
+	// (GET /keys/users/{userId}/bundles)
+	GetUserPreKeyBundles(ctx context.Context, request GetUserPreKeyBundlesRequestObject) (GetUserPreKeyBundlesResponseObject, error)
 	// Upload media
 	// (POST /media)
 	UploadMedia(ctx context.Context, request UploadMediaRequestObject) (UploadMediaResponseObject, error)
+	// Create a resumable upload
+	// (POST /media/uploads)
+	CreateUpload(ctx context.Context, request CreateUploadRequestObject) (CreateUploadResponseObject, error)
+	// Cancel an upload
+	// (DELETE /media/uploads/{uploadId})
+	DeleteUpload(ctx context.Context, request DeleteUploadRequestObject) (DeleteUploadResponseObject, error)
+	// Get the progress of an upload
+	// (GET /media/uploads/{uploadId})
+	GetUpload(ctx context.Context, request GetUploadRequestObject) (GetUploadResponseObject, error)
+	// Upload a chunk
+	// (PATCH /media/uploads/{uploadId})
+	UploadChunk(ctx context.Context, request UploadChunkRequestObject) (UploadChunkResponseObject, error)
+	// Complete an upload
+	// (POST /media/uploads/{uploadId}/complete)
+	CompleteUpload(ctx context.Context, request CompleteUploadRequestObject) (CompleteUploadResponseObject, error)
 	// Delete media
 	// (DELETE /media/{mediaId})
 	DeleteMedia(ctx context.Context, request DeleteMediaRequestObject) (DeleteMediaResponseObject, error)
 	// Get media by ID
 	// (GET /media/{mediaId})
 	GetMediaById(ctx context.Context, request GetMediaByIdRequestObject) (GetMediaByIdResponseObject, error)
+	// Download media content
+	// (GET /media/{mediaId}/content)
+	GetMediaContent(ctx context.Context, request GetMediaContentRequestObject) (GetMediaContentResponseObject, error)
 	// Get messages for a chat
 	// (GET /messages)
 	GetMessagesByChatId(ctx context.Context, request GetMessagesByChatIdRequestObject) (GetMessagesByChatIdResponseObject, error)
@@ -2676,12 +4839,27 @@ type StrictServerInterface interface {
 	// Update a message
 	// (PUT /messages/{messageId})
 	UpdateMessage(ctx context.Context, request UpdateMessageRequestObject) (UpdateMessageResponseObject, error)
+	// List who played a voice note
+	// (GET /messages/{messageId}/played)
+	GetMessagePlayedReceipts(ctx context.Context, request GetMessagePlayedReceiptsRequestObject) (GetMessagePlayedReceiptsResponseObject, error)
+	// Mark a voice note played
+	// (POST /messages/{messageId}/played)
+	MarkMessagePlayed(ctx context.Context, request MarkMessagePlayedRequestObject) (MarkMessagePlayedResponseObject, error)
+	// Set the activity of the user in a chat
+	// (POST /realtime/chats/{chatId}/activity)
+	SetChatActivity(ctx context.Context, request SetChatActivityRequestObject) (SetChatActivityResponseObject, error)
+	// Get the presence of a user
+	// (GET /realtime/users/{userId}/presence)
+	GetUserPresence(ctx context.Context, request GetUserPresenceRequestObject) (GetUserPresenceResponseObject, error)
 	// Get user settings
 	// (GET /settings/{userId})
 	GetUserSettings(ctx context.Context, request GetUserSettingsRequestObject) (GetUserSettingsResponseObject, error)
 	// Update user settings
 	// (PUT /settings/{userId})
 	UpdateUserSettings(ctx context.Context, request UpdateUserSettingsRequestObject) (UpdateUserSettingsResponseObject, error)
+	// Get the storage usage
+	// (GET /storage/usage)
+	GetStorageUsage(ctx context.Context, request GetStorageUsageRequestObject) (GetStorageUsageResponseObject, error)
 	// Get all users
 	// (GET /users)
 	GetAllUsers(ctx context.Context, request GetAllUsersRequestObject) (GetAllUsersResponseObject, error)
@@ -2699,36 +4877,328 @@ type StrictServerInterface interface {
 	UpdateUser(ctx context.Context, request UpdateUserRequestObject) (UpdateUserResponseObject, error)
 }
 
-type StrictHandlerFunc func(ctx *fiber.Ctx, args interface{}) (interface{}, error)
+type StrictHandlerFunc func(ctx *fiber.Ctx, args interface{}) (interface{}, error)
+
+type StrictMiddlewareFunc func(f StrictHandlerFunc, operationID string) StrictHandlerFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// Index operation middleware
+func (sh *strictHandler) Index(ctx *fiber.Ctx) error {
+	var request IndexRequestObject
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.Index(ctx.UserContext(), request.(IndexRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Index")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(IndexResponseObject); ok {
+		if err := validResponse.VisitIndexResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// AuthLogin operation middleware
+func (sh *strictHandler) AuthLogin(ctx *fiber.Ctx) error {
+	var request AuthLoginRequestObject
+
+	var body AuthLoginJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.AuthLogin(ctx.UserContext(), request.(AuthLoginRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AuthLogin")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(AuthLoginResponseObject); ok {
+		if err := validResponse.VisitAuthLoginResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// AuthLogout operation middleware
+func (sh *strictHandler) AuthLogout(ctx *fiber.Ctx) error {
+	var request AuthLogoutRequestObject
+
+	var body AuthLogoutJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.AuthLogout(ctx.UserContext(), request.(AuthLogoutRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AuthLogout")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(AuthLogoutResponseObject); ok {
+		if err := validResponse.VisitAuthLogoutResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// AuthRefreshToken operation middleware
+func (sh *strictHandler) AuthRefreshToken(ctx *fiber.Ctx) error {
+	var request AuthRefreshTokenRequestObject
+
+	var body AuthRefreshTokenJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.AuthRefreshToken(ctx.UserContext(), request.(AuthRefreshTokenRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AuthRefreshToken")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(AuthRefreshTokenResponseObject); ok {
+		if err := validResponse.VisitAuthRefreshTokenResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// AuthRegister operation middleware
+func (sh *strictHandler) AuthRegister(ctx *fiber.Ctx) error {
+	var request AuthRegisterRequestObject
+
+	var body AuthRegisterJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.AuthRegister(ctx.UserContext(), request.(AuthRegisterRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AuthRegister")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(AuthRegisterResponseObject); ok {
+		if err := validResponse.VisitAuthRegisterResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetAllChatGroups operation middleware
+func (sh *strictHandler) GetAllChatGroups(ctx *fiber.Ctx) error {
+	var request GetAllChatGroupsRequestObject
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAllChatGroups(ctx.UserContext(), request.(GetAllChatGroupsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAllChatGroups")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetAllChatGroupsResponseObject); ok {
+		if err := validResponse.VisitGetAllChatGroupsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateChatGroup operation middleware
+func (sh *strictHandler) CreateChatGroup(ctx *fiber.Ctx) error {
+	var request CreateChatGroupRequestObject
+
+	var body CreateChatGroupJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateChatGroup(ctx.UserContext(), request.(CreateChatGroupRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateChatGroup")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateChatGroupResponseObject); ok {
+		if err := validResponse.VisitCreateChatGroupResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DeleteChatGroup operation middleware
+func (sh *strictHandler) DeleteChatGroup(ctx *fiber.Ctx, chatGroupId string) error {
+	var request DeleteChatGroupRequestObject
+
+	request.ChatGroupId = chatGroupId
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteChatGroup(ctx.UserContext(), request.(DeleteChatGroupRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteChatGroup")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteChatGroupResponseObject); ok {
+		if err := validResponse.VisitDeleteChatGroupResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetChatGroupById operation middleware
+func (sh *strictHandler) GetChatGroupById(ctx *fiber.Ctx, chatGroupId string) error {
+	var request GetChatGroupByIdRequestObject
 
-type StrictMiddlewareFunc func(f StrictHandlerFunc, operationID string) StrictHandlerFunc
+	request.ChatGroupId = chatGroupId
 
-func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
-	return &strictHandler{ssi: ssi, middlewares: middlewares}
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetChatGroupById(ctx.UserContext(), request.(GetChatGroupByIdRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetChatGroupById")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetChatGroupByIdResponseObject); ok {
+		if err := validResponse.VisitGetChatGroupByIdResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type strictHandler struct {
-	ssi         StrictServerInterface
-	middlewares []StrictMiddlewareFunc
+// UpdateChatGroup operation middleware
+func (sh *strictHandler) UpdateChatGroup(ctx *fiber.Ctx, chatGroupId string) error {
+	var request UpdateChatGroupRequestObject
+
+	request.ChatGroupId = chatGroupId
+
+	var body UpdateChatGroupJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateChatGroup(ctx.UserContext(), request.(UpdateChatGroupRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateChatGroup")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(UpdateChatGroupResponseObject); ok {
+		if err := validResponse.VisitUpdateChatGroupResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-// Index operation middleware
-func (sh *strictHandler) Index(ctx *fiber.Ctx) error {
-	var request IndexRequestObject
+// GetAllChats operation middleware
+func (sh *strictHandler) GetAllChats(ctx *fiber.Ctx) error {
+	var request GetAllChatsRequestObject
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.Index(ctx.UserContext(), request.(IndexRequestObject))
+		return sh.ssi.GetAllChats(ctx.UserContext(), request.(GetAllChatsRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "Index")
+		handler = middleware(handler, "GetAllChats")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(IndexResponseObject); ok {
-		if err := validResponse.VisitIndexResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetAllChatsResponseObject); ok {
+		if err := validResponse.VisitGetAllChatsResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2737,29 +5207,29 @@ func (sh *strictHandler) Index(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// AuthLogin operation middleware
-func (sh *strictHandler) AuthLogin(ctx *fiber.Ctx) error {
-	var request AuthLoginRequestObject
+// CreateChat operation middleware
+func (sh *strictHandler) CreateChat(ctx *fiber.Ctx) error {
+	var request CreateChatRequestObject
 
-	var body AuthLoginJSONRequestBody
+	var body CreateChatJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.AuthLogin(ctx.UserContext(), request.(AuthLoginRequestObject))
+		return sh.ssi.CreateChat(ctx.UserContext(), request.(CreateChatRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "AuthLogin")
+		handler = middleware(handler, "CreateChat")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(AuthLoginResponseObject); ok {
-		if err := validResponse.VisitAuthLoginResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CreateChatResponseObject); ok {
+		if err := validResponse.VisitCreateChatResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2768,29 +5238,85 @@ func (sh *strictHandler) AuthLogin(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// AuthRegister operation middleware
-func (sh *strictHandler) AuthRegister(ctx *fiber.Ctx) error {
-	var request AuthRegisterRequestObject
+// DeleteChat operation middleware
+func (sh *strictHandler) DeleteChat(ctx *fiber.Ctx, chatId string) error {
+	var request DeleteChatRequestObject
 
-	var body AuthRegisterJSONRequestBody
+	request.ChatId = chatId
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteChat(ctx.UserContext(), request.(DeleteChatRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteChat")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteChatResponseObject); ok {
+		if err := validResponse.VisitDeleteChatResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetChatById operation middleware
+func (sh *strictHandler) GetChatById(ctx *fiber.Ctx, chatId string) error {
+	var request GetChatByIdRequestObject
+
+	request.ChatId = chatId
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetChatById(ctx.UserContext(), request.(GetChatByIdRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetChatById")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetChatByIdResponseObject); ok {
+		if err := validResponse.VisitGetChatByIdResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// UpdateChat operation middleware
+func (sh *strictHandler) UpdateChat(ctx *fiber.Ctx, chatId string) error {
+	var request UpdateChatRequestObject
+
+	request.ChatId = chatId
+
+	var body UpdateChatJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.AuthRegister(ctx.UserContext(), request.(AuthRegisterRequestObject))
+		return sh.ssi.UpdateChat(ctx.UserContext(), request.(UpdateChatRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "AuthRegister")
+		handler = middleware(handler, "UpdateChat")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(AuthRegisterResponseObject); ok {
-		if err := validResponse.VisitAuthRegisterResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(UpdateChatResponseObject); ok {
+		if err := validResponse.VisitUpdateChatResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2799,23 +5325,26 @@ func (sh *strictHandler) AuthRegister(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// GetAllChatGroups operation middleware
-func (sh *strictHandler) GetAllChatGroups(ctx *fiber.Ctx) error {
-	var request GetAllChatGroupsRequestObject
+// GetChatGallery operation middleware
+func (sh *strictHandler) GetChatGallery(ctx *fiber.Ctx, chatId string, params GetChatGalleryParams) error {
+	var request GetChatGalleryRequestObject
+
+	request.ChatId = chatId
+	request.Params = params
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetAllChatGroups(ctx.UserContext(), request.(GetAllChatGroupsRequestObject))
+		return sh.ssi.GetChatGallery(ctx.UserContext(), request.(GetChatGalleryRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetAllChatGroups")
+		handler = middleware(handler, "GetChatGallery")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetAllChatGroupsResponseObject); ok {
-		if err := validResponse.VisitGetAllChatGroupsResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetChatGalleryResponseObject); ok {
+		if err := validResponse.VisitGetChatGalleryResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2824,29 +5353,54 @@ func (sh *strictHandler) GetAllChatGroups(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// CreateChatGroup operation middleware
-func (sh *strictHandler) CreateChatGroup(ctx *fiber.Ctx) error {
-	var request CreateChatGroupRequestObject
+// GetAllHighlights operation middleware
+func (sh *strictHandler) GetAllHighlights(ctx *fiber.Ctx) error {
+	var request GetAllHighlightsRequestObject
 
-	var body CreateChatGroupJSONRequestBody
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAllHighlights(ctx.UserContext(), request.(GetAllHighlightsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAllHighlights")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetAllHighlightsResponseObject); ok {
+		if err := validResponse.VisitGetAllHighlightsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateHighlight operation middleware
+func (sh *strictHandler) CreateHighlight(ctx *fiber.Ctx) error {
+	var request CreateHighlightRequestObject
+
+	var body CreateHighlightJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.CreateChatGroup(ctx.UserContext(), request.(CreateChatGroupRequestObject))
+		return sh.ssi.CreateHighlight(ctx.UserContext(), request.(CreateHighlightRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "CreateChatGroup")
+		handler = middleware(handler, "CreateHighlight")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(CreateChatGroupResponseObject); ok {
-		if err := validResponse.VisitCreateChatGroupResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CreateHighlightResponseObject); ok {
+		if err := validResponse.VisitCreateHighlightResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2855,25 +5409,25 @@ func (sh *strictHandler) CreateChatGroup(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// DeleteChatGroup operation middleware
-func (sh *strictHandler) DeleteChatGroup(ctx *fiber.Ctx, chatGroupId string) error {
-	var request DeleteChatGroupRequestObject
+// DeleteHighlightById operation middleware
+func (sh *strictHandler) DeleteHighlightById(ctx *fiber.Ctx, highlightId string) error {
+	var request DeleteHighlightByIdRequestObject
 
-	request.ChatGroupId = chatGroupId
+	request.HighlightId = highlightId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.DeleteChatGroup(ctx.UserContext(), request.(DeleteChatGroupRequestObject))
+		return sh.ssi.DeleteHighlightById(ctx.UserContext(), request.(DeleteHighlightByIdRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "DeleteChatGroup")
+		handler = middleware(handler, "DeleteHighlightById")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(DeleteChatGroupResponseObject); ok {
-		if err := validResponse.VisitDeleteChatGroupResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(DeleteHighlightByIdResponseObject); ok {
+		if err := validResponse.VisitDeleteHighlightByIdResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2882,58 +5436,58 @@ func (sh *strictHandler) DeleteChatGroup(ctx *fiber.Ctx, chatGroupId string) err
 	return nil
 }
 
-// GetChatGroupById operation middleware
-func (sh *strictHandler) GetChatGroupById(ctx *fiber.Ctx, chatGroupId string) error {
-	var request GetChatGroupByIdRequestObject
+// GetHighlightById operation middleware
+func (sh *strictHandler) GetHighlightById(ctx *fiber.Ctx, highlightId string) error {
+	var request GetHighlightByIdRequestObject
 
-	request.ChatGroupId = chatGroupId
+	request.HighlightId = highlightId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetChatGroupById(ctx.UserContext(), request.(GetChatGroupByIdRequestObject))
+		return sh.ssi.GetHighlightById(ctx.UserContext(), request.(GetHighlightByIdRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetChatGroupById")
+		handler = middleware(handler, "GetHighlightById")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetChatGroupByIdResponseObject); ok {
-		if err := validResponse.VisitGetChatGroupByIdResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetHighlightByIdResponseObject); ok {
+		if err := validResponse.VisitGetHighlightByIdResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
 		return fmt.Errorf("unexpected response type: %T", response)
 	}
 	return nil
-}
-
-// UpdateChatGroup operation middleware
-func (sh *strictHandler) UpdateChatGroup(ctx *fiber.Ctx, chatGroupId string) error {
-	var request UpdateChatGroupRequestObject
+}
 
-	request.ChatGroupId = chatGroupId
+// UpdateHighlightById operation middleware
+func (sh *strictHandler) UpdateHighlightById(ctx *fiber.Ctx, highlightId string) error {
+	var request UpdateHighlightByIdRequestObject
 
-	var body UpdateChatGroupJSONRequestBody
+	request.HighlightId = highlightId
+
+	var body UpdateHighlightByIdJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.UpdateChatGroup(ctx.UserContext(), request.(UpdateChatGroupRequestObject))
+		return sh.ssi.UpdateHighlightById(ctx.UserContext(), request.(UpdateHighlightByIdRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "UpdateChatGroup")
+		handler = middleware(handler, "UpdateHighlightById")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(UpdateChatGroupResponseObject); ok {
-		if err := validResponse.VisitUpdateChatGroupResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(UpdateHighlightByIdResponseObject); ok {
+		if err := validResponse.VisitUpdateHighlightByIdResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2942,23 +5496,25 @@ func (sh *strictHandler) UpdateChatGroup(ctx *fiber.Ctx, chatGroupId string) err
 	return nil
 }
 
-// GetAllChats operation middleware
-func (sh *strictHandler) GetAllChats(ctx *fiber.Ctx) error {
-	var request GetAllChatsRequestObject
+// DeleteDeviceKeys operation middleware
+func (sh *strictHandler) DeleteDeviceKeys(ctx *fiber.Ctx, deviceId DeviceIdParam) error {
+	var request DeleteDeviceKeysRequestObject
+
+	request.DeviceId = deviceId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetAllChats(ctx.UserContext(), request.(GetAllChatsRequestObject))
+		return sh.ssi.DeleteDeviceKeys(ctx.UserContext(), request.(DeleteDeviceKeysRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetAllChats")
+		handler = middleware(handler, "DeleteDeviceKeys")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetAllChatsResponseObject); ok {
-		if err := validResponse.VisitGetAllChatsResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(DeleteDeviceKeysResponseObject); ok {
+		if err := validResponse.VisitDeleteDeviceKeysResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2967,29 +5523,31 @@ func (sh *strictHandler) GetAllChats(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// CreateChat operation middleware
-func (sh *strictHandler) CreateChat(ctx *fiber.Ctx) error {
-	var request CreateChatRequestObject
+// PublishDeviceKeys operation middleware
+func (sh *strictHandler) PublishDeviceKeys(ctx *fiber.Ctx, deviceId DeviceIdParam) error {
+	var request PublishDeviceKeysRequestObject
 
-	var body CreateChatJSONRequestBody
+	request.DeviceId = deviceId
+
+	var body PublishDeviceKeysJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.CreateChat(ctx.UserContext(), request.(CreateChatRequestObject))
+		return sh.ssi.PublishDeviceKeys(ctx.UserContext(), request.(PublishDeviceKeysRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "CreateChat")
+		handler = middleware(handler, "PublishDeviceKeys")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(CreateChatResponseObject); ok {
-		if err := validResponse.VisitCreateChatResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(PublishDeviceKeysResponseObject); ok {
+		if err := validResponse.VisitPublishDeviceKeysResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -2998,25 +5556,31 @@ func (sh *strictHandler) CreateChat(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// DeleteChat operation middleware
-func (sh *strictHandler) DeleteChat(ctx *fiber.Ctx, chatId string) error {
-	var request DeleteChatRequestObject
+// UploadOneTimePreKeys operation middleware
+func (sh *strictHandler) UploadOneTimePreKeys(ctx *fiber.Ctx, deviceId DeviceIdParam) error {
+	var request UploadOneTimePreKeysRequestObject
 
-	request.ChatId = chatId
+	request.DeviceId = deviceId
+
+	var body UploadOneTimePreKeysJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.DeleteChat(ctx.UserContext(), request.(DeleteChatRequestObject))
+		return sh.ssi.UploadOneTimePreKeys(ctx.UserContext(), request.(UploadOneTimePreKeysRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "DeleteChat")
+		handler = middleware(handler, "UploadOneTimePreKeys")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(DeleteChatResponseObject); ok {
-		if err := validResponse.VisitDeleteChatResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(UploadOneTimePreKeysResponseObject); ok {
+		if err := validResponse.VisitUploadOneTimePreKeysResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3025,25 +5589,25 @@ func (sh *strictHandler) DeleteChat(ctx *fiber.Ctx, chatId string) error {
 	return nil
 }
 
-// GetChatById operation middleware
-func (sh *strictHandler) GetChatById(ctx *fiber.Ctx, chatId string) error {
-	var request GetChatByIdRequestObject
+// GetPreKeyCount operation middleware
+func (sh *strictHandler) GetPreKeyCount(ctx *fiber.Ctx, deviceId DeviceIdParam) error {
+	var request GetPreKeyCountRequestObject
 
-	request.ChatId = chatId
+	request.DeviceId = deviceId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetChatById(ctx.UserContext(), request.(GetChatByIdRequestObject))
+		return sh.ssi.GetPreKeyCount(ctx.UserContext(), request.(GetPreKeyCountRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetChatById")
+		handler = middleware(handler, "GetPreKeyCount")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetChatByIdResponseObject); ok {
-		if err := validResponse.VisitGetChatByIdResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetPreKeyCountResponseObject); ok {
+		if err := validResponse.VisitGetPreKeyCountResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3052,31 +5616,25 @@ func (sh *strictHandler) GetChatById(ctx *fiber.Ctx, chatId string) error {
 	return nil
 }
 
-// UpdateChat operation middleware
-func (sh *strictHandler) UpdateChat(ctx *fiber.Ctx, chatId string) error {
-	var request UpdateChatRequestObject
-
-	request.ChatId = chatId
+// GetUserPreKeyBundles operation middleware
+func (sh *strictHandler) GetUserPreKeyBundles(ctx *fiber.Ctx, userId string) error {
+	var request GetUserPreKeyBundlesRequestObject
 
-	var body UpdateChatJSONRequestBody
-	if err := ctx.BodyParser(&body); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	}
-	request.Body = &body
+	request.UserId = userId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.UpdateChat(ctx.UserContext(), request.(UpdateChatRequestObject))
+		return sh.ssi.GetUserPreKeyBundles(ctx.UserContext(), request.(GetUserPreKeyBundlesRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "UpdateChat")
+		handler = middleware(handler, "GetUserPreKeyBundles")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(UpdateChatResponseObject); ok {
-		if err := validResponse.VisitUpdateChatResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetUserPreKeyBundlesResponseObject); ok {
+		if err := validResponse.VisitGetUserPreKeyBundlesResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3085,23 +5643,25 @@ func (sh *strictHandler) UpdateChat(ctx *fiber.Ctx, chatId string) error {
 	return nil
 }
 
-// GetAllHighlights operation middleware
-func (sh *strictHandler) GetAllHighlights(ctx *fiber.Ctx) error {
-	var request GetAllHighlightsRequestObject
+// UploadMedia operation middleware
+func (sh *strictHandler) UploadMedia(ctx *fiber.Ctx) error {
+	var request UploadMediaRequestObject
+
+	request.Body = multipart.NewReader(bytes.NewReader(ctx.Request().Body()), string(ctx.Request().Header.MultipartFormBoundary()))
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetAllHighlights(ctx.UserContext(), request.(GetAllHighlightsRequestObject))
+		return sh.ssi.UploadMedia(ctx.UserContext(), request.(UploadMediaRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetAllHighlights")
+		handler = middleware(handler, "UploadMedia")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetAllHighlightsResponseObject); ok {
-		if err := validResponse.VisitGetAllHighlightsResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(UploadMediaResponseObject); ok {
+		if err := validResponse.VisitUploadMediaResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3110,29 +5670,29 @@ func (sh *strictHandler) GetAllHighlights(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// CreateHighlight operation middleware
-func (sh *strictHandler) CreateHighlight(ctx *fiber.Ctx) error {
-	var request CreateHighlightRequestObject
+// CreateUpload operation middleware
+func (sh *strictHandler) CreateUpload(ctx *fiber.Ctx) error {
+	var request CreateUploadRequestObject
 
-	var body CreateHighlightJSONRequestBody
+	var body CreateUploadJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.CreateHighlight(ctx.UserContext(), request.(CreateHighlightRequestObject))
+		return sh.ssi.CreateUpload(ctx.UserContext(), request.(CreateUploadRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "CreateHighlight")
+		handler = middleware(handler, "CreateUpload")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(CreateHighlightResponseObject); ok {
-		if err := validResponse.VisitCreateHighlightResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CreateUploadResponseObject); ok {
+		if err := validResponse.VisitCreateUploadResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3141,25 +5701,25 @@ func (sh *strictHandler) CreateHighlight(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// DeleteHighlightById operation middleware
-func (sh *strictHandler) DeleteHighlightById(ctx *fiber.Ctx, highlightId string) error {
-	var request DeleteHighlightByIdRequestObject
+// DeleteUpload operation middleware
+func (sh *strictHandler) DeleteUpload(ctx *fiber.Ctx, uploadId string) error {
+	var request DeleteUploadRequestObject
 
-	request.HighlightId = highlightId
+	request.UploadId = uploadId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.DeleteHighlightById(ctx.UserContext(), request.(DeleteHighlightByIdRequestObject))
+		return sh.ssi.DeleteUpload(ctx.UserContext(), request.(DeleteUploadRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "DeleteHighlightById")
+		handler = middleware(handler, "DeleteUpload")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(DeleteHighlightByIdResponseObject); ok {
-		if err := validResponse.VisitDeleteHighlightByIdResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(DeleteUploadResponseObject); ok {
+		if err := validResponse.VisitDeleteUploadResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3168,25 +5728,25 @@ func (sh *strictHandler) DeleteHighlightById(ctx *fiber.Ctx, highlightId string)
 	return nil
 }
 
-// GetHighlightById operation middleware
-func (sh *strictHandler) GetHighlightById(ctx *fiber.Ctx, highlightId string) error {
-	var request GetHighlightByIdRequestObject
+// GetUpload operation middleware
+func (sh *strictHandler) GetUpload(ctx *fiber.Ctx, uploadId string) error {
+	var request GetUploadRequestObject
 
-	request.HighlightId = highlightId
+	request.UploadId = uploadId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetHighlightById(ctx.UserContext(), request.(GetHighlightByIdRequestObject))
+		return sh.ssi.GetUpload(ctx.UserContext(), request.(GetUploadRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetHighlightById")
+		handler = middleware(handler, "GetUpload")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetHighlightByIdResponseObject); ok {
-		if err := validResponse.VisitGetHighlightByIdResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetUploadResponseObject); ok {
+		if err := validResponse.VisitGetUploadResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3195,31 +5755,28 @@ func (sh *strictHandler) GetHighlightById(ctx *fiber.Ctx, highlightId string) er
 	return nil
 }
 
-// UpdateHighlightById operation middleware
-func (sh *strictHandler) UpdateHighlightById(ctx *fiber.Ctx, highlightId string) error {
-	var request UpdateHighlightByIdRequestObject
+// UploadChunk operation middleware
+func (sh *strictHandler) UploadChunk(ctx *fiber.Ctx, uploadId string, params UploadChunkParams) error {
+	var request UploadChunkRequestObject
 
-	request.HighlightId = highlightId
+	request.UploadId = uploadId
+	request.Params = params
 
-	var body UpdateHighlightByIdJSONRequestBody
-	if err := ctx.BodyParser(&body); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	}
-	request.Body = &body
+	request.Body = bytes.NewReader(ctx.Request().Body())
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.UpdateHighlightById(ctx.UserContext(), request.(UpdateHighlightByIdRequestObject))
+		return sh.ssi.UploadChunk(ctx.UserContext(), request.(UploadChunkRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "UpdateHighlightById")
+		handler = middleware(handler, "UploadChunk")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(UpdateHighlightByIdResponseObject); ok {
-		if err := validResponse.VisitUpdateHighlightByIdResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(UploadChunkResponseObject); ok {
+		if err := validResponse.VisitUploadChunkResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3228,25 +5785,25 @@ func (sh *strictHandler) UpdateHighlightById(ctx *fiber.Ctx, highlightId string)
 	return nil
 }
 
-// UploadMedia operation middleware
-func (sh *strictHandler) UploadMedia(ctx *fiber.Ctx) error {
-	var request UploadMediaRequestObject
+// CompleteUpload operation middleware
+func (sh *strictHandler) CompleteUpload(ctx *fiber.Ctx, uploadId string) error {
+	var request CompleteUploadRequestObject
 
-	request.Body = multipart.NewReader(bytes.NewReader(ctx.Request().Body()), string(ctx.Request().Header.MultipartFormBoundary()))
+	request.UploadId = uploadId
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.UploadMedia(ctx.UserContext(), request.(UploadMediaRequestObject))
+		return sh.ssi.CompleteUpload(ctx.UserContext(), request.(CompleteUploadRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "UploadMedia")
+		handler = middleware(handler, "CompleteUpload")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(UploadMediaResponseObject); ok {
-		if err := validResponse.VisitUploadMediaResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CompleteUploadResponseObject); ok {
+		if err := validResponse.VisitCompleteUploadResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -3309,6 +5866,34 @@ func (sh *strictHandler) GetMediaById(ctx *fiber.Ctx, mediaId string) error {
 	return nil
 }
 
+// GetMediaContent operation middleware
+func (sh *strictHandler) GetMediaContent(ctx *fiber.Ctx, mediaId string, params GetMediaContentParams) error {
+	var request GetMediaContentRequestObject
+
+	request.MediaId = mediaId
+	request.Params = params
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMediaContent(ctx.UserContext(), request.(GetMediaContentRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMediaContent")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetMediaContentResponseObject); ok {
+		if err := validResponse.VisitGetMediaContentResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
 // GetMessagesByChatId operation middleware
 func (sh *strictHandler) GetMessagesByChatId(ctx *fiber.Ctx, params GetMessagesByChatIdParams) error {
 	var request GetMessagesByChatIdRequestObject
@@ -3454,6 +6039,120 @@ func (sh *strictHandler) UpdateMessage(ctx *fiber.Ctx, messageId string) error {
 	return nil
 }
 
+// GetMessagePlayedReceipts operation middleware
+func (sh *strictHandler) GetMessagePlayedReceipts(ctx *fiber.Ctx, messageId string) error {
+	var request GetMessagePlayedReceiptsRequestObject
+
+	request.MessageId = messageId
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMessagePlayedReceipts(ctx.UserContext(), request.(GetMessagePlayedReceiptsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMessagePlayedReceipts")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetMessagePlayedReceiptsResponseObject); ok {
+		if err := validResponse.VisitGetMessagePlayedReceiptsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// MarkMessagePlayed operation middleware
+func (sh *strictHandler) MarkMessagePlayed(ctx *fiber.Ctx, messageId string) error {
+	var request MarkMessagePlayedRequestObject
+
+	request.MessageId = messageId
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.MarkMessagePlayed(ctx.UserContext(), request.(MarkMessagePlayedRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "MarkMessagePlayed")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(MarkMessagePlayedResponseObject); ok {
+		if err := validResponse.VisitMarkMessagePlayedResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// SetChatActivity operation middleware
+func (sh *strictHandler) SetChatActivity(ctx *fiber.Ctx, chatId string) error {
+	var request SetChatActivityRequestObject
+
+	request.ChatId = chatId
+
+	var body SetChatActivityJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.SetChatActivity(ctx.UserContext(), request.(SetChatActivityRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SetChatActivity")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(SetChatActivityResponseObject); ok {
+		if err := validResponse.VisitSetChatActivityResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetUserPresence operation middleware
+func (sh *strictHandler) GetUserPresence(ctx *fiber.Ctx, userId string) error {
+	var request GetUserPresenceRequestObject
+
+	request.UserId = userId
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetUserPresence(ctx.UserContext(), request.(GetUserPresenceRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetUserPresence")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetUserPresenceResponseObject); ok {
+		if err := validResponse.VisitGetUserPresenceResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
 // GetUserSettings operation middleware
 func (sh *strictHandler) GetUserSettings(ctx *fiber.Ctx, userId string) error {
 	var request GetUserSettingsRequestObject
@@ -3514,6 +6213,31 @@ func (sh *strictHandler) UpdateUserSettings(ctx *fiber.Ctx, userId string) error
 	return nil
 }
 
+// GetStorageUsage operation middleware
+func (sh *strictHandler) GetStorageUsage(ctx *fiber.Ctx) error {
+	var request GetStorageUsageRequestObject
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetStorageUsage(ctx.UserContext(), request.(GetStorageUsageRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetStorageUsage")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetStorageUsageResponseObject); ok {
+		if err := validResponse.VisitGetStorageUsageResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
 // GetAllUsers operation middleware
 func (sh *strictHandler) GetAllUsers(ctx *fiber.Ctx) error {
 	var request GetAllUsersRequestObject
@@ -3660,82 +6384,190 @@ func (sh *strictHandler) UpdateUser(ctx *fiber.Ctx, userId string) error {
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xdfW/bOJP/Knx0Bzy7OCd2sn3Zy1+XdnvdFG23aBIs9tpgQUtji61EqiQVxw383Q98",
-	"0aspWXZsJdkaAdrYojjD4W9mfqSoya3nszhhFKgU3smtx+FbCkK+YAEB/cVbNiX0o/lWffYZlUD1rzhJ",
-	"IuJjSRgdfhGMqu+EH0KM1W+Mwh8T7+TTrfefHCbeifcfw0LU0LQTw9NUhlrEqxiTKJOzGHS860PIKLxP",
-	"4zHw/N6rxWIx8AIQPieJUs478U7Rm/M/3iM2/gK+RGoMmFBCp0iGgCLVE/I5BEAlwZFAn9PR6PgZSrAQ",
-	"M8YDb6DtQjgE3onkKSwG3keYEiELsbsxTSZlbetkN27FQFx3xvVw1rPTQn0jEkaFQdOT0egFDjaxWduA",
-	"X3HO+GugwIn/0YrzHIN8gQNkAT5ASQRYAPJD8L9m36IAS+wtBt6T0dElxakMGSff1Wh61rQsfElldUnN",
-	"gI8lDDV4lcpPR6MzKoFTHJ0DvwauZfWueaYDEloJBFqLxcDLffY89X0QYmua5R23qXVa2ExJ0O2RZF+B",
-	"CiQZSgUgQtGERRGbKdhbmwv1bQg4AD2GS6HcqHCGbQ9F9f+SA5YQtA0mc24IlOIcETphPDbu+VOM55n/",
-	"IszHRHLM5yiACU4jKX7OhrEL1W2frcBW+iovQ5gqZJuW1u8WAytMq5XPq/qQcJYAlzYpYS3nQk2f+lgV",
-	"8ebPC2QamAlWNgrUPOIKBryBJ+cJeCeekJzQqadj1YSDCJs7PvhoWhxcFD1PGEdwkxAbH824KMxwNIQb",
-	"CTRQeGKTchtJYliWv8i/MWG44jWVDLBkEFBXlzW+CAHpSwgHAVcWYRMd0RVsDr2BBzc4TiIl8gsL6WHA",
-	"4H/sV4c+i72BZ4DlnVgJDpvlwd8pPbvaLFiAz0H+Xcoh65jFkd+WjLNzDQdeUqjRIEU1QFS3aJb0X0dP",
-	"nz49Ov7lydNnz9c0RO50S8PXvtU1hqo+YxACT2F5IKVPagwJZ9rLWCp9pgFdjARuwE91wxkWSJjAMEmd",
-	"CBJFKKrKI8LSDxslcNZR/lVZpiIcee9jxiLAtNloTma196t26vhDudbLEDtG7IdYvscxuHWhOIZMB9US",
-	"/aSyw5SzNNGfxc9VrS4Ax+gDZ1qmY/Dqngv9pUuaaq6kqVbVfgPCm3o09OJUursMsASdwlSKKoahnNje",
-	"WRV0PDp+cjA6OjgeXRwdn4xGJ6PR/5Xhrfo7cKe7gUcacJNS8i0FRPQ6Y0KA6xybKVNV4NkIP3X9uORF",
-	"WMh3JridNYg++y2bPtUY2VCIBFCpKMRaOjx3uzWXxCcJtgtul9cULeoyiYRYtGmOy7evrfEzl8b2C8w5",
-	"nqvPaRJsiCBtUnt7M4x+WQ9GTa5reHRj5HqofqxWI8bTunp0r4iSTKU89d+2gbUobx58qo6qZMCrhul+",
-	"rebGsVIIYkLPgsYR5plbNxSVOdfT3c1G1NxfNm5x+2aBou52qs/VUQsLwXyiHMw5OeNnrp/t5QnjIbvJ",
-	"FhUlbpf2r2rktG0arKMi7bWnSCd6h0B95xoxwiHJSNhe7lsDVM4MGINiSQ1Ythc39QF7/4Y+0Cn1JJxN",
-	"SASX3MHJLz++LYclI/rfAtl7UEJ8mfJaVA2lTMTJcFgi50Oj85dkWkZmyolLwc1yYeElvWZEHSJXpMWe",
-	"4+UyT79LiPxnRYg+fNWk83+sg9Y4RTFdhXUHBeRbqcWldtKH7Df+c9dPb9Qi+NX1s9tEbiYlQP2563uY",
-	"GSkWRvfhtUsT/8z182DclcLs77Vc1umEK/xvveXcmnO66S6MHnW/GxHdCX6fy8YtrhGXwOF8MLmEj+3u",
-	"aU8wiSBYay8727kWEss0jwCufewJjoR7I7ucyzJBxXa9K329jtgYRx/Lj+CrdskUeMmCBnjnu+8+C6DO",
-	"UUfup2jmjndNNj9FYRpjesABB3gcQY5u02xcnDswHVWlnucjb8/2laEta+Uy1+9kGkZkGjoCzPrrtTDr",
-	"bPPlmvIyt1h1pSoFEYEmnMVVadloHZ1/S5lsmPO8UwiQhJvaCFSLbynxv6IxZzOKJuwGfUnjRCB2DdzG",
-	"rO9zFLDpodOnSQxC4jjpumgqxribnQXFplbHYf2ofRZm23PBBpP8rFu+ewcBwe4ct1pNs4moyUZAsEJF",
-	"ibjNiAw3D8Yq73dLska2al+VRmI8BcsDnN2fk+8N3Qvy3dG9ykvjuQRRkXM0On4yGuUiCJUwNSc41ndj",
-	"LesuOy4BwavJg0OKtlVjl6s4W9MMuBhaeVZW7XgIoMFa3qIZy3qGfO7eaokYDi7WjR3GDipumA62GDga",
-	"XfdSS2rlqpv48RhUYsyGgSS7myc3LVFy15LMCjssG2RMKObznqC+FtqMshl5uBPcalTCzlhJn/JorTmv",
-	"nHDIKdAdMWAI0hgiRqfiTnNfOu61LNteLAKtllsV9jtEEfuXq2sIiISgkfed0UAfWhSIVLpHIVboBopM",
-	"B4cd6HAmbIOAYGTm268OmXfZfd0sxzjMvHaWueSRcOYEfcQR03nJuQXCUmI/hKBIGLkODYs7m13wznJL",
-	"fXsiVoZi1KGK3VBTbi+QbWYO+XUbSnmdqmNHWx8rp+TXboPRHXaJjw7JioW711pJRCC4YJ23EzL8y5CI",
-	"/INiiUj1Ne8aWv572+Sgu7md9MCsqxuoY2XN7RSlFNnKWqUcXlSnO2YbWliHow5dc42ekqAKlJ5zDfpo",
-	"M68Jj3CT736Jrnloi+EQVdUxt5T1+QGDpWRZN/vwuLPwKOzJ8S1Ex05stjB/C5FdtQvf5u72MXd3ijlA",
-	"M8aj4F97wtOrD2/+OKkPbwueu352wAgCiMg1cOh4cvocpCR06thiX/sklbBdoRlweBDHbjONNl+giJJ5",
-	"HJIF8H+LYuA/6ZcRzd21k5a3nz3KlHrmLR7x2TtBt5/NMXr1u34LAH32klSE6rNePi70N5xcY39ub7CP",
-	"MdUn1XgcEf+zt1hs5ZBPdQJ3csZnrU3rik5mC+EOrM6F/kthTudXkT8mzPXwR4SMSzQmbMpxEs6bz+yf",
-	"s4mcYQ7oFZ0SCsDdFDOlks9bgWXb1A4PnJ9u5eCjWczs5MHEPb5+MiFctDxR15crW/7Lst+wkG4n/nQ+",
-	"JtZw7J9OUzyFDxwmwIH60AqWRDdThD+7sSoaaNMj/WZz6RjQbq3fGGznNSD0U4hFCMHPhwj9yYmEA0aj",
-	"GvZNi+b3bgbeTN35B43m+RvvvbyIk59J+WCOl7Q+48gnbM0TKfaGrk88OvOI5cFiX5JraNpY+M5oOxSz",
-	"RtVOT2PgxMfD9zD7+y/Gv24lZ+VRbIfpavXibtmCfioki92hX7WmjT6XXW0PjgGD7iluxUbLo8x3/8wc",
-	"84PF/Gq3WYuj41/u7Y3KfSDfUiB/ZHGzvNFU+HfJV0riS0gf5FEiH/BVaxAO7vbCu16wtL3rXq+xEYDE",
-	"JBKr62ts/S348/y2O7727irRsRvj9V0o4GxHhQLUQFdsee5z/j7nP4ScT2F271UU9jn/x8z5tbipt3v9",
-	"lBM5P1cZwwTKF4A58NNUhjps6k//m83emz8vPFv8SQdlfbWQpXBgqkkROmHZkybs63hsRuWdfjhD52mS",
-	"MC4Vi+CRve9kOLzRwIl9geMUIhGyr8xzFIfzvwINkOrH1i6bMI4uIIpi84gA6FQrFREfbO7MZCfYDwEd",
-	"H47qomez2SHWVw8Znw7trWL49uzlq/fnrw6OD0eHoYwjgyppXwmMvjL0jsVApVLHG3jXwIVRc3Q4OjrA",
-	"URJib+DdHEzZQYL9rzoLe1Miw3SsB8twQg58FsAU6JCn1C7Lbw7KFw5iEgQRqPQjFF97l3/0rhYDjyVA",
-	"cUK8E++Xw5EeWoJlqCdzqP6ZgmN34ZVKQoizVIK2n66vR+g0s6l1SN27qYZ1FpijYXDj1aoFHo9GW6tP",
-	"tlwSwlGdzOpIBGI0IhQqWPZOPl0pDhLHWKViozICPV41UpyQYnQST7VJzbiuVD9DnMrQ1utTNIIJh/n+",
-	"YimK2FQ/2GOqOy4RoRK4CkZ0qg9waw9VMOUgWMp9s5MzBVu+sG7aorbToFTec95ksEoF0GGl/OfCPT/u",
-	"Xmy74VLdP11escON1VqRpihjl7uqlRtNZcTV9zWUT2wDwFs21e9FmYpvMuVUoDd/Xhwo6Qe69FwJCDrw",
-	"lXDA7fqiGQoXDGWNEIUZwr6mePlkL5X+UyC0hQyZDPURAvvMRxDqZ3VOS5qiTMllwGTLn00wUy+Nugyb",
-	"DvPYVGpxY/TsCgXZaEUFB/XJcSPBD7HULwmKUjytTsZrkKdRlL+GLO4aI/PzAauCpSmosvwK3lLQfEuE",
-	"zKvX2NEU9t5K8K6/xta97uiiPFWvQSIcRRVNi3kp2Vhlv8wpq9NhUkhhnyX32FqyclRrWFQ3WOxzGZdv",
-	"bVcLl71fFoUssvehisV7NC/5aV8IKFX3faD4M7OJsA7nBQibMFiNEMNbP7t4FixMuojAvMFXxehv+vsy",
-	"RhPMcQxSv5f+6dZTBERzOW+QMdhS30tVnAclK9XJ/9US/p4sZ7ISVozOLqw86XO2ShpRJtGEpTR4oKAx",
-	"s4lwB8AMGlNI3uzFXE9wn4AY9R6Q7F7tHlcrk2G5HtF4js5+a8mHqQNaZnO0x1Czw1xb3ejtlGv7h3Z2",
-	"iPZh5dq9lzV5mQFVp+idpfsua4H+lgHrrgAeA/evs/5uhH+HXP/eaX6jl+25/abc3oGx3MMNl+9M4zun",
-	"1Z2R94dE2x8VYXdGmjaWvhZBf2DcvHHK7pmQPyIq7iLhnfj3TjGzm6x374S7ETB7lv1YXKfCrxtSbl6j",
-	"aRWz/r1o2Ae9LqqNdeDYH+2DBcVhSwN62GQ7LBs0m5qSlVfR7sJCu4lCpRnol3PXBFctnF/cs++N2HdY",
-	"Qo0TdNWgMLzNf+/EyPOuOlO1Uv/b5+cFWh4ISS8UeiRMvSgvWGdftVDVlDruExGjfmJSln7Ccs7aA6sl",
-	"/3VGVQux7xlY955gewZzRvNroP6BOP7jcSlL9Dt6lcrwcV5F1ckwTZFGU2q1DfxxGkmSYC6HE8bjg+z9",
-	"iG6Dd5SD7JlpmgE6jK4vFNUk9xRzJQCVpUwpmBLsjH1LiBve6v86UckMfavjuu1z+/TRwOCBUEejzCOh",
-	"jU1IaOaJukXnVL6dKR/1FUnub4/3caBGcUJTR6qeuaohxFSWa9upstWVxIv5y2w/t4amLnX+OEhO4Dqv",
-	"flRUj9dY/JaCrvJ7f08bOu2hZbVm13hKnZt4n+WaUJoBgvHlndUMfS2bd+dAg+IPD+xiXeEsfNk7s7LQ",
-	"c0Wk0l9r2TOrFZhTcLFbd3Hx5yqW4VaOj4pl2RpznXhW1m2XtJvVrtsB18r+zMkDYVtGnUfzQL0VHYNV",
-	"+XIN4rUdBIz6DDX3Sb8eB4zMU/a8kv4SCSunteYtuZ5Cyc5S5r0+dO+A4/2j90fkUvnT95V5O3sVcXhr",
-	"6lYu2hY4ulBJVjC0i6PZWpgPJVznujvMeilKL2beA7Qy3R5FuE4rxirAlRt4RbDuDUjbD9dVDPUXojtj",
-	"dx+pH5U72VDdwaNUuNZ1xleclroU5u8n736TJ6s31XWHx2j/sI9GpdZ62QToMa46D6Ub7SbeLJe37Hk7",
-	"xUxyQ9DZH4Ta5CBUauBSg1ju3xUu1r6BYoHXExNzbJ1cmhp8D2LfROvyaDZN3CAYtJLvzlsl2yPeq6uB",
-	"VCqA7Cfcvb2h83t9b6PILe1U+ZFR5OXqjD1z5daUtafHj8Vz8l2MhnxZLf5TrWT36Uqh23TuevYaMR9H",
-	"AxTANUQs0RXdTON6oTjdMGRCnvw6+nU0xAkZXh95qnOrTr1nGYItPmaKrdnHu3nv1l1NAbLFoH47L9VW",
-	"Qp/T0ej4GcKpDIFKOxmmX1F0pUe83NOra+BzGeq/VcVQwBAes1TmBNfebDn8UqVUU6a1viyxNxWL0YGT",
-	"EOiXKdEY5AyAIkHoNIK64Jf2peTGDmwloLwb+zFfR1R6ep3VN6p3h81A8men+tHfeF7v413x8Lmxh4Dg",
-	"5tv1GQz3YI6fhLz6yoW9q3Q0bnG1+P8AAAD//22ZRQjzoQAA",
+	"H4sIAAAAAAAC/+y9jXMbN5Io/q/g+PtV7e7dSKRk2XFcdfWeLDuJ9mzHZ8nJu7frSoEzLQ6iITALYEQz",
+	"Lv/vr9AA5osYckhRFBWrXJWIJD4ajf5Co9H9ZRCLaS44cK0GL74McirpFDRI/JTADYvhPHlvvrVfqFiy",
+	"XDPBBy8GlymQ81dEp0DijAHXJE6FAnIlJBEciLjC32ihU+CaxVRDQgoF8i+K2KHV4SAaMDNWTnU6iAac",
+	"TmHwopx4EA0k/KtgEpLBCy0LiAYqTmFKDTB6npu2SkvGJ4OvX7/axqD0S5EwwBW8ERPGP9hvzedYcA0c",
+	"/6R5nhmYmODD35VZ0Jfa4P+/hKvBi8H/N6zwM7S/quFpodPGwDh3Ezen5O8XP78jYvw7xJqYaSnjjE8Q",
+	"I5npTGIJicELzRT5ZzEaHT8jOVVqJuTiur9Ggw9wJUGll+Iatr6i0NjrLIoSaUcg2gwRBn/ClAZ5F5vR",
+	"Hnvd/ZDYX+L0620L0pzKBVeW3k5Go5c02fYaX0sp5I/AQbL4g5sutMiXNCGOBSKSZ0AVkDiF+Np/SxKq",
+	"6eBrNDgZHX3khjGFZH9AsnNI65MvgFwXGEPkFQvyyTuhfxAF3z24RtKpHGJ2xRBeJQoZA5lRRbjQ5MoA",
+	"dWiAfDoanXMNktPsAuQNSJxh5/B6GIhCIAggFF+jQSm6Loo4BqXuQiZ2g3VabayZAdtbiaGIFkY1EMbJ",
+	"lcgyMTO86QhDmW9ToAngGt5L+C+Yn4mC622vojb0KnLgxXQM0ug4weFAsymQXMI1zBXJ4EqjEjSyxaoy",
+	"A/dHZSRUJWm2DbwZ/0wC1ZAsA94LS6eLCeNXQk5xQvLXKZ174UioHDMtqZyTBK5okWn1N7+MuwDdjblU",
+	"ahh4jQgjlCMbYksn1L562wDBKunRfMilyEFqZxNQnAd13aJN8/dfL4ltYAnT4Cgx9EcbtDuI2uZHNJA1",
+	"JRoc+MCp2YPLamRDJvA5Z0752HVxmNFsCJ818MTwgbiqtzG0tjj/1/Ibq+Ma3F5TSE1cwJSyLGzZ4U+E",
+	"Jok0yHC2nKGYiADTKVjytq0creep4CVnMNSiVwwU/kbj2LCVMfjgM53mmQH1d5Hyw0TA/3ZfHcZiOogG",
+	"liAHLxx4AVyXGjkIuv+1DnVzYgWxBP1bTbEvTmEW8w7X0jFLfbV3g5//OHr69OnR8ZOTp8++C255ZY78",
+	"o8LJp+Wk4PhrgRaQjfqKeTPmFJSiE1jETu2TQUwuBTKUKHQsptBYIXyGuMCGRpkqKwOuiuCmq0rqNOdj",
+	"yplxTiBQP1D5VX1Oe45wo4+FyIDybv4J2K27YyFFZCmtHzmnB1q2xzBnKQ1sdpxS/Q7PqKF1mdOrX49p",
+	"Sf5qxPtEiiLHz+pvTWgvgU7JeylwzgAiTZ/Led4xm2luZjOtmuMmTHaNaO2DUx0eMqEaUAehPVMuw7Cm",
+	"69mc6Hh0fHIwOjo4Hl0eHb8YjV6MRv+3ToVmvIOwvooGrIMGC87+VUAlH2VpSy2u9NmIPg39C82XUaXf",
+	"WpF1nnR6M9z2mcbECTiigGtjA6wFw3dh7pOaxSynzt8S4sCqRXtOpmGqlkFO693XhvhZCGL3BZWSzs3n",
+	"Ik82pCBEqeveTUZP1iOjrx2sexprdsP0PGT+Vb8AL6ZGEuh5bkaLBhJiIRP7t9IizwGlQwVq2TLIrJau",
+	"NiMNtPBAhRD7awrcGQkWduIak4JnoBRRoAmdUMYjQsdIrYLHQNwKlvLs0xejZ/151kjpnot8vlL+OoyV",
+	"o0bV3nxasa2d2ri+u20kUl1qGqNDEmFs6xqTRKTcfqtj/AdKbgSLwZz1AZG5NbJpYWSN9d/GiquPtFeG",
+	"3PkdGXJmwfZo3Ek4+6rZiRZO9/bV8TvVMVoY+9b8b9uqZsFYq62qhsAuTvmRZhnIgPwvF1j+sYxV3DDn",
+	"GqYhbcjhsz4rpBIdlm6Mv3nKMa1JTicQkSlTCo/2vDI3zC+bmjctbNm1deLG0G1AdiZTxs+Tzt0vjzDY",
+	"UDX4AVmhH/1w279OeFX3zXRne1sqXbzMxqNKiZjhrVSIcMfPQv+2Z1Vb6XE3tnUDiC8LdyEtub5sG5wQ",
+	"IyjRTgkeiwITYs815GdgJjvD9k4KaxBV8LwwBXPa7KBl9+OmPOD6b8gDvQz1XIorlsFHGXBOfPzwpi6y",
+	"7dR/UcT1ITmLdSFbwijVOlcvhsOax2FoYf49n9Qps5AsaDtudHKouGSn5wcUkStMhh3Ly0VfzG1E5J9L",
+	"QuyCV62p86dl0JYFUW1Xhd2oIvmlpsXHPNlzvom/C/3bmWmRPA/9u1tFbjclIbtj13cws7M4MroPrl3Y",
+	"+Gehf3vDrhxmv63FskEmXMF/6x1119zTTX3WuOrdum37G/i7PFJv8fy8QByvMBjhjOUpSA2fA7QxFsk8",
+	"DOqYKnh2QoDHIoGEiJwa2zsux2rC/XYm0pdXh4eHYaHmwvtW7KaEmOUMuC4DBvHrnM4zQRPClL9bg4Ro",
+	"0QTAtj84Ct/Uu3FXg4DOwt6T9neF+i9CsytQygh0T8emJaEGBVeMQ0LG83rMZS6FFrHICBxODl0MChGS",
+	"cCM6sgZ8RyUYjGuYgFzQ+nXMRPUwTOwXWfII6f5gZNKi5BFJYM1K03EGZErjlHE4kEAT/AKjlojp49aq",
+	"SEw5yURMM/ZH0/34y+mb81enl+c/v/vth9PzN69fhelOU5YFWDEHeXDFIEvIDc1YYuMsrijLCglqEPVz",
+	"Ff1gBnjtI63a6mO7PlYDGyRr+VbdD0RpqgtVsdiiX/WKZirsWK2Tip+oWlqILmpIuSU1SKBK8Dqcgx/O",
+	"X7959duH1//98fxDeMdxUxfnMCsXV1cuvMYHR2LjiCRCaxeZw0Hhn+YH1dyKrvv1zn1OiynlS5eDY9qb",
+	"B4fmVUa6XV5kMbl8I+qOzIWdyBi/7tBQ+JOnFjdBhBdOsxQ4yZjSBofYrCFs/hE0cFDPZQhiyVQrTa8p",
+	"JGzlpcZbbFTtwGrZXspXY7KplEpI3PcJo0aELi5qLQmvgGvr7diKV6+199Uyy5mC+25lcju6boEE/mx3",
+	"QOuLqh8zMabZh3o4dxNFfv6zoNy6rANtGLLtoxqFTRHb420X+k9JS3B4qrXNxlUMux2oOetFufLlxNRY",
+	"2iJUIXT9xCZpxiZpwIhc31+b+sE2d9cyJ9kWpzW/NGcxQvZKimnLZnWrDQz+r0Lojj0vB4WELNrBpsW/",
+	"ChZfk7EUMxPc/Jn8XkxzRcSNsywz+secJGISNJWNKFCaTvO+TtNqjXdzs1BFAKw0m2epv7pMNtjkZ/3O",
+	"u2+9bmidY7JCplSli4CeiWlOY03yjMaQiiyxMWhsSidQvvi4YQkIDEpXqZiRWWpO8TqFOTGHgIgoAOLV",
+	"m53qUKXNZb15/dMvz/ivL4/n18/zuRjR5MO/H353ffY24b8vjx9ZhtjYRzFYJcVU3dU0Yzrd/PjoIrm7",
+	"D+9vz9++toeRBDTEaBlJYVnLdfZgGq9HExLE7/D3HILBM0lhg5vfBhTCK/ebGZsWCRONTTKH7SnLMqYg",
+	"Fjxpquuj46ejUY2yGdfPTgaLpyBjKGbQzydiEd+xQOe2CdihGVywPzqGV+yPwPBmZeO5htaSRscno1E5",
+	"RW0JKXhp3JzhJ7Aij5OcfYZMdVI7VSRhKs/ovCUxjkbPgzOuL+dxdbe5kksYXe1dwmaRf4OIa43sIiNH",
+	"QUKSRMTFFNr+FmzcOXXQ+XfBJhwS4nyAiZhxdBQ0NzMiTJOZkNcKuVQUmlD/woBrlhE//msfBrbaWUhz",
+	"Nrw5GmLPYRcah44z/5cLGfvPo+9GT797ejwajezuKzbhVBcS/vPJ1THtcY+3AGlHwJpvh2FoChfP+CQi",
+	"V6DjtIYeDFxDCqGEw8wgcmvXe5ueBq6YVItngipsw+4ZQ0NCAde3OCTElF/gkXwRwF9AJiwuheqUZjMq",
+	"gZguHOQhOXNClykieDa3r70SG/sXG0s4MoSO5zQP+BhiWiggRW6IVBEzHhfajZkcOs8CmQLl1hB3s5FY",
+	"FFmCbSU48raEjX4nOhY3QJhWVphlbMr04T95LVgut6ftQTRA0PDh7xVqEbOXOGszZs43C52rkrWMELND",
+	"a4qf8F1QWkzHPOxCeiVmXMXU4M4J1wUxmwul3Y2GlbgRUVNzLFeO4A77uprQ6Ln00ITDdM3+Xq5rPVqO",
+	"NJajHWCrpuOM3oBpuQjMe6DXJIMbo53QpBgZUXo0GhlcwQ0gcec0RhuHJ2KmSmOg6XM4Oo5ORtHRaBQ9",
+	"exI9DzgZatqrjbIZS3TAXvzVfH0r7fn98SjoeA3bstW2BhxmDQutr3FVNz4qqE6OV5kRVduw+ledBk0m",
+	"+ASUJpBMoEId6kBjSlnBUnJTA1tPjoNzFWtq3nJwnNSHSxvVW9H5R5ntXsl+Hx+B/a4E8T+fHI966N2S",
+	"Ppfjqu37sJ5y27nc3aa1XyMRi+lPXcT5EaXC0pvFTc4wY0Cl6kRO32uVZ13E3nWhXFrWWrjJDut4HzNO",
+	"5Xxndic512RaKE2m1BtE/U5Rt9aJdu3ec+SgZg6aMXRk7dhUb3ZF/Zcg1/HrNjBMgKWL7JZUh+OQMRgx",
+	"pW5FbdX1Z8AkeM2TAy0OgCcEeCznucGku0dUlkxykNUtp7vj7G0FLNzmhsJVqufZi8hpkZtDTBMbP0GW",
+	"iX8LrR0SpiHpdFye8wSJRxHWGJ6k1DA8cGIHOOxx9eQn28CesXOW8YOBOW93vtjkDBxA89qn4GD8VC12",
+	"qpJ2ilCtaZxCGaZfu8YB8v7ni0syLC3jPnFVpaDu8oWEVzM+6hNn41WzAyKXgCLIu/oXAm/QS0f5vM+K",
+	"D8mrcsDINWqvBm0Ej+IlGHFWB+1EQsiaqDuJVun7RcRwM3X3thshrYhrZrMWtOht9ebiIEvHuE20w8J1",
+	"eB+d6mgVj5lGzEU1aSqkfZr1W/k0q4TONA3fuOQZg+RSvF3fM8BU+YEpQokZa95XgXzfrbdf9QyIsa2b",
+	"0TB1EVdDDO8fDnOb83R/sgieqFWH58NM3AxUCE5lANnKrUkdiYv+nFudepE+37nro2Xq/JeyYcfhEEHs",
+	"8Yytry2EG5k0SXzfbCHywZmOqD6rrg5gde/GUhNAM3IDtj6G1D3r89rQlS50MWZWNPh5FJ32f+P3qO0f",
+	"irbXwg9zf/r9kJzWHlnjGYGLkuecp40S48POwB2mnfuAl/sXoQPa3yX6Xt7tiL5uBbqibZMbremkdkZD",
+	"KWcG0aCyL1pPur91+6KPaO5jgHT5B+oktMQfsDKyqc+LeDfWN/EY3q111SOBZbrQvcLr70CIzPVflvzb",
+	"svvEsNRdS+A/yu9tvXbZhRRLvgv9u4NjQgIZu4GugNoFBvmZg3E02WSQi5xxDfNWCpSj8E1KXowzFrsx",
+	"Vj6osK3JNcwPV8pIC0J9hpCAfI93UR8gBpYHONxeVfV/jou0Zi/GbVf8tpUZZUmambsLMusBzsZJasrc",
+	"NCW6gqhGWnlZ8CQLaaCa+u13JLduQz1fk3h8N4JvQK5qCUiDwYWiTejLVGSTK/D6z9y+9et8UW+77TxC",
+	"5RbV3qvUEdgCddX+9TAlep0462Pe/YuQPTQ0atl0t8ETEqY2Z3dHjF53Qt7K2icm4FO1kvM2JMXJca94",
+	"QaPTgLNQgOmvKZRJDO0ERKUYPGPPnhjk1IbxcBDEaZ3MG0nxPSrqkHxavge3s5Drm/mNEK8CHgewlVGl",
+	"LwD40kxtVjf5W6dYcI6hTmV+NhtRLHjGOJRBWilLEuBb1KN2/OUkipCa8zblROTAMd5GE6Ul0GlE8Eau",
+	"Dd7i/dzdCHQH/6cl+3Nrqra7/E2QtDESVPpfMFedx757MDz6J+NaMEHaKnVzk6RFgWuaDx21OdrPh5bl",
+	"B7fPh2pFNIgEXcjae19bNURI/zmXcMNEoXy3wx6vfGoQhNZxAdq85gu8fVo7xZVyQ5EZSNiL7LEeos0v",
+	"3nMJVyCBx6D6ZLR/X2u+Sf6jJgrvJP3RWketBkw2euYWF0Zfl9DfVnx6frBvQrZftCTfSpfFHTssokEZ",
+	"8thrsLK1p7hqdC/v6jpmExdJHaSg8NNCGr9oOMhsPPcZwUMuKOxZZ5a/KOdpzEG65LIZlZP1Q73rUNm8",
+	"jQG9N56/XR6huApC+5cZ9k7hBK5B5pKpTn9i1QJfX2AqXq2IeX9J7bNzweuN8oxytfn1iOm+BGvFuPwK",
+	"Z4rIlQSoXvBwm5G52bKeIdg0H0SDMVUsHqACmbJiOqhjonmrVLUIvkGlL+ca1PI9xnYlG2WUlw/JIjIi",
+	"DJNGm+cZC4+8vnvy3cnRc/u8rMcRuFCQ9AAHy1pAYp/5KBs34rbTMbZ7b4+R/nLu062t2uenxyfHz5/3",
+	"h1X2gFVTY3i1wQoWQlhj+nYmW09zjS2t47MOb+QlT5PLVwmwjhSvY4+Dyt9x9P3Jk9FJPzQuiy4xv3ls",
+	"YTIny65Ie2e3ygscew/SMqfP4tOeJ6EVrIjkruRg11LKLWiuB6O8VyqlsdtSu6LQHtoA+72MrL/V+2B7",
+	"bxCnBb8OIG44zU82zYlv12bWmkAGZlKXFJ9ygU4OnJRQKdkNqA7L+WiDE8jtnwynImMJnR92rH79I071",
+	"qGGz6JwM+ESn4Vnrj5RnqchgySPl49H33x09Pe4pnF34xuoLRuTNasuNFWJ2XBHGtdj8WLdCJiQQZ/Yd",
+	"aP2ZRy/2jwbi6krBSuGFGCQSYmA3kERVsnBLu0pTqRVJoZVC8PmT58+fjZ73QvKqK9TKNqxoyBkx5XMR",
+	"lFwW5623mvUmPc55VsptMZTxDh8T3SmL74LhpoyzaTHtOPPtzdOmfhq0jA5ymOvWoc652Eled+ODbIHb",
+	"miQIrYJAbrQxE6FcQCoVUpMxExNJ83TeaZ0OLsSVxsfir/mEcQDZaVjJjtO+OyO6Nq1cshenW8mDX96S",
+	"bN8/uElZti2VWENbp1ts4M8N4bE4999FyrdkEvR9uddRM4tPCjqBypW5lFisg9ToSt+xOTXw8CTL0JXR",
+	"ldh6JWA7pe7IX1OqUkj+dkjIr5JpODB5FVoSHVt0F8CLBjPT82eezcvi1xtXxFunul2Zovi9zTa8NE1x",
+	"uWFrJih2HXoGxfWP21pcrPWsdL3u+EPw5aToGzUHPZ2CZDEdvoPZb/8j5PVW6hQ073rvxkW/WkMvYjAu",
+	"lBbTsOg3rXknz/lflwvHRPQsqFBVJu7Uxg9S3/05dcw3JvObw/oWR8dPtlra9FGQ34Mgf2Bys5nZ1/N3",
+	"jVdq09coPSqlRLngT0uFcHK7e1Uz0NI71XaFeZd2e3V1+a3ftl6U3W55vdq+zu8oKs/GLHPFJ9cIDjht",
+	"9P0aDWihxSuXzabMb9mVYc2WsrSNISGm75RqFtMsm5cH9hm7YhGJIcuKjEpMDw83bdo0jboyU/5Ksyyn",
+	"eUjsvac6NSO2fDIz36Mdi4+7HVan4nd2oeddCVzwd6JMA/saXM2VhmlEaJ5nGEU3EWLS9ivYRkEdKrgO",
+	"J2X8QXBt/S/mGIXLCb5qOjoJZlDyeU5/YXWKaG+gwGz2CqAux8u+qtw7vBUTHCLLO7FWuH/C5OJvCS73",
+	"+zKtfke6HMuJQt8Fz8JhklZTbLBw3zIEHRfmRBzTjtc4r7nJqjxMmDL/J43Wh6uFhdHV7IbG6/L8e9cL",
+	"xT71jzRWw2caW0dxrvvBZxLYvjehYjALSUiT3tbRto0ogxkWMFkfEUoUPFm5AD8Xtu43sE6hS+EazndA",
+	"EmxmBQNyUEQSKq8NyVgR0Fcs3LCxfUDaey1lj8OtaJfTti5pqhojIkzmR0mVXgmiz7tMYtfDoqlf0h0N",
+	"n/WluMgB4rQXZRrHuxeUhGai6JXdpwdK3ldM1q4lllwKvOxW3SIHK9SWUWzC3ukuE7DVXwfi6uA2Yrd2",
+	"YFhHG9SPEVtWBM2zxVpANc8YW5XTXTSwlUjAlRbrnyUK0Cx0xbveR0fLo6NlHxwtHGadLvRHR8ujo2Xn",
+	"Dur+Qee97om9zvnmXn3+Us93tbhzLljgitB6vheqCHzWkmLQWCM/S0QKXlabYrqWhnxsbc7DQdTepyXF",
+	"JAwISa2ghC5BWlZH4qRvKNO2U1yX4C2kuX5u01yfPF0nyfXifhkKgbiQTM8vDOVaDL4EKkGeFjY0ZYyf",
+	"fvCr//uvl4NogHSOxIC/VtgwMnDw1QzM+JXwqURojLaI5ejB6ftzclHkuZDaZRy2/V4Mh59RaE5jRacF",
+	"ZCoV12KhDu7gJY2vgSfEjGPJBB01l5BlU3ssAz5BoDIWg2NmP3dO4xTI8eGoPfVsNjuk+OuhkJOh66qG",
+	"b87PXr+7eH1wfDg6TPXU5ltn2pWkzq4FeSumwLUBZxANbkAqC+bocHR0QLM8pYNo8PlgIg5yGl8j9w8m",
+	"TKfFGBcraM4OYpHABPhQFtzdA34+qP9wMGVJkoExvZRxEL8tPw4+mTizHDjN2eDF4MnhCJeWU53iZg7N",
+	"fyYQPCtqOSdSFNo7uiA2NRo8Tp0ywtEty5wnNrMrfK5VicJZTH2rZtqY2tl8+Luyx2krHVfJzo6aZUhU",
+	"zRU4QG0BBMahQdCDF//4ZATgdErl3MNNABdtlktzVi1R0wni1S7ukxlnSAudDvHZHSoDETpv/48ozMs8",
+	"Iz60sJF6xPCfkWUGlWXKcUOrEpQoZGzvjyegCaqUNn4N373BWe2dACj90pVlDWGtasIMsZqO3vr/Gt6k",
+	"8Ciu3bCc3uHfjHLSp+PJaPSySg6OvY769Dr6yA2mhWR/QGL6Pe0z29PR6NzgmdPsAqnAld5cQgBvxAR9",
+	"Wwb79nWlIn//9fLAzH6Ajy5rhIDSr0kHotDdhPABbsQ1eKVae8gZEXs5YD8pQjOjtuaEKVVAYkhmbsuO",
+	"uqoiWGbKRj53kYYBZAPaCD1T/boXfPxGTCaQGGNnc2q7U6oRBeaNk2aTDVvT5h4voRvXztHXEvKx9Ghr",
+	"4NQppsp+Z35pkdYCtbmq1wqzLqE5Yag9ptzbTIXyj3Fs6rtFAquTyR2T2bcqiV5/jlPK0Uva2j5bdnZm",
+	"P6mldGVvWbtJ6lIQ38gTlfG5lMoH3aHGSJNTaw+buQuFIcb26UT52lcxHvsX6DXJWRJ/iIgceBsRkO3b",
+	"TTw9dtMcwOxAFrJb09Bd0YJfrWropfbmhCnBXE1OSu+3M/Kam/Ej6NMsMy+wnJv8lgK/1+m3nC5wBlkU",
+	"/kxhXLhZjPPN1/C9HU3Uqu0agMJvmzd+odq4cqt+BE1oljUgrfalhmNjknumbG6HDcEomy6yx1bWW47f",
+	"DLv72gwzcdGpId7aLhQhfJ+VOCyLhFaejGxe49NdUcBLvFpt8/s+0Z/dTWcJVETYRYNNCTH8Evsfz5Ov",
+	"Vl1koGGRRl/h93UazamkU9AgzSRfBuZAhAfMQeSP1bWxB20ai2pYanvjPi3Q30mgZmtFK/6R3yKtnOxy",
+	"t2oQGbvqCu++95No7G4S2oNgok4VUjZ76dIq7JAgRjsXSC5i7ZGuVirDGlGZ89H5qyX6sAiQlr2t3KGo",
+	"uUNd27x57aVrd0/aPnXzfunaRy7r4jJLVISuoe77nAV2dwxY9wTwEGz/ttXfz+C/Q1v/3s38Ti57tO03",
+	"te0DNFZyuLXle5vxvdXqnRnv+2S2PyiDPShpllnpaxnoe2abd27ZPRvkD8gUDxnhvezvO6WZu9F6925w",
+	"dxLMo5X9UFinYV/3Urm2tlpnUIWxZVU9gZwkGePXCvMZVtFNZZg8hkLZDJEcZmXixQhvY3IqNYtZTrlW",
+	"9ddVh+Q9VarME3RWSCWkHSqnEyBUkdh+pwXe9fiW+LO9NsTYz9xX5OWiNtIheYMgU4k5yud2B22pLQe2",
+	"zWLuEiHCQnU/JoLXi96TRLMM5PyuRE4UDEOkY4/BiZ0ec1uan/9VWGDcjDjcsvl8YqQ8FVqoQWRz15g/",
+	"fAYc5QuHYQgSv66nflkOZnM7G3mec18XKACz3e3B2liZ0s8mOVAtkBYPdhF5MjKU6l7rdc2KeSQbk7rx",
+	"XE2cZamH7t7R54gsIBhOLd03yWEf5POTXc7+TmhC6yKmLmEe9UVYX6CrwiDJifMyS+iCBrEvd6OGJqme",
+	"d67w0fxUNdyFo6acro+3pgxVybLae9U9d9ukdYT6LapheZUDp2x6R16c2g7s1nvTmriJ4fLHRz/ORn6c",
+	"tEY1QaJrCoXhl/LvXr6dcqjeh/7a+Nv39FTUsifungqgB+LzKbdn4RzfElVdquM+KWK0G5nk1U9a11mP",
+	"hLVE//WmqiUuoh0T1r0r2B0Ts3cYtYj6G/IWPRyWci6jnlxlNLyp8De09bDU8Isv5NdS8G3CmIobUIT6",
+	"GoLlazCdAtcMq9rbKFGKlXjwoQnTivhygiGDwRYCx1y0CywcwlzVZOihfm++Gnz9tBfvBux6cMlEIsaS",
+	"24R7O5Jf1e/kndA/tAh087DgWiCwWUC54zWCssmDKwHdelpo67oBpl2QQlPtnqE0qrK5VwS2lpkrO2nP",
+	"rasILKpTmMBZabZYwZKcosnbmNRSt4Wm3WHBv2XeHpPTWkFCTaZCaXI0ciCqqEzqLzjgo4axqxSkUixK",
+	"H1fos28gkCRCnkiHtC0zxPbVVqBoX3/9tZwoayU97+2Bxcno+12Kd/92vHyEZehMB/2gjuS2y+JuN3FK",
+	"zwPd/L5EdQwdEy17UOTqwi5hvz68f83FjBMsp2UvBNiEC2kT3LYNxUzQ5OdmyvP95Kpgivg/F1/dpyaz",
+	"6F0guY0ofFiW/wlet3kDWvcuAa00y7JaIejVTHAYuseq7fId2VKbUdlDpBdcRVhKZXCll9CN2R41/GKr",
+	"WH4djm0F95XUQt34xHZAOrDVx5q0YEUg0BgTqKliat5+Cl7+blv/RQWsIZMAvhxDldXsXJhG+Szc/Eqm",
+	"dE6uwJXKmEaEElehrUbSZnSFxfpSUciOy1WXNq2qZd/rpF7WW94P70+4GH+HNm/s470I2u3d2SFruMPP",
+	"qnUj3RiiAry7bxLVogjblMdPjr/f/fKEIFPKy021rAFJo6bFdgXQD575WvRkjSScLyiDylCQsA12gUXU",
+	"VVnxxueSwm5l1UF3MpMQC5koPMNPQdOEanpILsuSTCBtTZ0xdN/a2siOev06nJapVkk2M4fbUzuFhs8m",
+	"8ASMUvSzYIjHGK6EhHKokNyx2t5fd3aba9Mi08wAPjSvWg98OqV+xIPD25nuKczZLjBAr/hDVXBq32K+",
+	"nuz8gOXJlSn05S2hViGRFJulS5ki8DkGMMj868Xlzx9Of3z9239//Pny9LfX/+fs9etXr1/9bS/CE06O",
+	"do5bz85YpNdIQ9qogmgDchC0p7sGLSR1cPvjGHIN++vNRcxNnfhqRm/UpPzQolgtk/ZYpo9yvxuzVKiq",
+	"8hlzQXOM27J+yurr96eXZz9FRBnNQLXdWMSfIqqQN6a6cCJFnkNiRuIQ24zMxMKtbC9XOtBaAqZkYCW2",
+	"fS4ZPLv7e1lvKTBZqoGAYLdX2XaiwV0exO/1+YpbX4B87C8+9OFRlj/K8q3i1pYwDElzCaqYYlbttlzf",
+	"54CXNtR9pOnwi/1jxW3Yq9o9gkOJs5ot/aE49fVTjSy9ojJqVvGmZb3YpFYz+Bpy3XVZVgq+Hidot4jt",
+	"x9Q4GeQE9z1FieqaJLgHbnU4KPnViBqr1PbVqDijPIasMgUCrBCt9mfa4sFEC8taYM9u9EqDJFjWX4OU",
+	"RW4ouqysvegS2jkVj3anmnMpJrJ0fT7yxb7zxY/uKYrfNxTMS5kkpzpUfOI0z4Enlk1MnYEyS7dFCdWO",
+	"eSIyS1mclj4N0yYupATuW7TqXpNTZ0GjXX2FhaumLJnROXpRmIqpxDpLilBbljlqcGeNb20qr+rJj190",
+	"tw/lzMx8d6wafAHSxIJdO+PlKS5qFJwOPQKxAywFpLsm9ajrYUifo4ad+T9ErEEfKPS1NSm6nHfMOEWY",
+	"2yjZaSRYtzg7c3XehfwGzxn7L0l3HKXgWNIdufQCk1pE4Ymr+mialxbuPZ1onPRYPNDYH/AU46GWoFoL",
+	"evRbbeC3oha3a561hp5Sur1Zp0rBdJy5Q5c7YjHeuMHAgCvzERVhiT1/EDskZ3YavPgs3WKYfLZMdGm6",
+	"Kzp1QzpHGJAYc63YCC8JWrJwGJeb4B7Oaju7WPAvb0rzosYt9yuoSwn0IHw++yXjK+eae36tK/9Fi6Pm",
+	"sLeuH8d9S234Sg59wf/1etnkbxNXs7Mbc/ueF8t93S+Zdsp6VTSJd8ga2WiBqwTyPXCVxdIDeV7VdcnT",
+	"/Z4KW/R+8rIdWhztSrM0sursx9NzxOBflH1L/UjN3V4Ua4C1X54skbnD2gom0CdYZQwp41Yb2VcDkdWX",
+	"tsqnCceQWeXkKIOP8CcbVWK6UV1IIGXQlB3fF6wuzT1TaG0m5LUqc4rQZvUDZwrGFCOBxnNCydmrd65k",
+	"BtMWMlCH5ILxiQF+roFIl2NfAlG23JCLIlIApoxDRxQdYvHMoeuu+H7BGfORs88Y5VcixK2J0M4EHK7F",
+	"es6XBYfLAig/vT09a3AkYeWFC07ZmTul3PHb4cKU5fYA6LSYjjllGdGiJBzCuNJAk/rBrQumcoBQepR1",
+	"8pH8+/DfN/AvLdp9lW03iAYpGHWOM57iifPgA1Jtc6YFjA3ODCccGCqVIlvZ2C7i4BVTuVDMVzfu7mI6",
+	"HY+e3RECnK8JEsuh9U3cW4xUXRCa1ei7h9v6St4yc1bHqkJRS5wk/tA2pRkWclUx5RwkGWcivoakYvr9",
+	"0L/3cC5zIk+RMRhdZxFkFYdDWoQ+iTnJqHb+yqNnu4bSsg7D2kSKJVWM5n5ZLR1VT155QW6RHZfaNmzK",
+	"2Kxjy5LmvHVtXs7PfMawluJeROH5q3oIi71o1ZLBTS0/m3G7dGbd2n0KzV7pfBwu1km9XKL4MadLl8Ht",
+	"CQLfDC0ke7K/NvIItWwavLCkfqDKnxd6dTdLxepo78X7fjOH3/y7Cdhzo99rxJ6Docf7cNfSBl5+04HZ",
+	"K5O97SHbGWp2iZSmJVEHOK6uIoZf3F893Yx+2D6HPDfuXbgaLZnuibPR+/kV8KSqru7llpDLCWnnJqMF",
+	"68Fk9V5KytEq+2YNX+R2yHV0j2J7Dx2Uj1TeIxO5FxWL7sm6ldSdbGpHYvnOrKN7TUy+Ppvta7byR6X3",
+	"0MVBmV19I/ttmGd0Dknt1N+Vat02tDfouXbPdjEBt0GRf8o67zxuYcp1Pwp+OAxfDCBo77HdBzfZHmvj",
+	"Xs6Cxmr6uAwuFxF+D9Lissad5eOkastrb/p3FmByh6kBNhZgbYw8iqmupN7G5eLIukFIazl5PrhH/Bg9",
+	"3iVtOjWJK7tdhk3arYw8WM39PCTvW1KPSsAHTYSa8SsHkwSaEKWpLlRLr9kxjJObaReXdw2QK9cNx61u",
+	"Z7yMDF2bvqXyuiEeH+ghpSUN+0i//RN+xlyZUdVQe1UWkG9NKD4abutLRMPOTZLKPVd3GHASaKbZFNrV",
+	"cmis2Q1eAHVFGl9ClrkHbyj+uoreRETcgHTP1uEGXbo2ZiVaJm8Ng+h5zvgkcjlWbB6mJrsojW/sD8kp",
+	"Jx7kMgCj4BkoRZh7xO9EpRkuB4rRzfV3RWV39wi/ik5BgY7smnZEn1zYAjmnHmkPryiXB/0ey3JVICzP",
+	"oVRulBeVWnSQ4beRV6qvsLynLHEX7vFguW21JG2E8cVLuQ9OJLUlVCtpXC5BAY9hZda4WQrOPoNSsgie",
+	"MW4VbgrcJtyYgXTltRQAj4jLMRoQTSbxmwIjIg7JS6FTNOFSliTAvd7GcXDJQG6YYmOWtda+PBecXdrD",
+	"TAOHsPfIAIft6ij51hJuVu9qK1ws5C1rsYMCbVRXxQjLoisMMV24Dg+RmDzsPbyiZqnEI+ceTEQP6oPw",
+	"9RcNZFW0VtLKCk//zuhq+8ZOCfaOLZxNSfmxKOmD4i7nOu/BYCjM7Qu8YYFe9j7Jkl2PWox3mcGw01FO",
+	"NL0Ge/hxVfDsiz+reZkkqhiX85kTI4/IWGIYvwncNgOjm8uFkttpDbva5wJTMMlufVIIMEjKJVOu1h5m",
+	"l8L58NVAoSB5OdegCCaIVs1sPzaZrx3QpIDI7ELHEug1goJlTW0SaG80mDS+M+7fL5zaRxAlUnDVeP7D",
+	"V/r49jVCmDA7QlUrFcGBxCdRmEhR5LawpnI+NJdqImy2Xdh9+VjGcN2VGKnP0xVP7Gik8NGE9+w82uOM",
+	"Jk1UhcNY8dyxovDjR2xzl0kozAQ9dIcPDLVA73dxx8IhzSPdrHFlRceP1jq+k6yGCuT95jQ0ZlVP++Cx",
+	"suMmlR1bZytHcSWXNw5Vy2MQHR3u6EgVSjFnqGBPKjYiLA8mlC9MBNHSU3TvAL7tnaCXOxhqsuJxw5dF",
+	"taEF3A5pq1TN8kPuAzvcGpDvNYptHQ32eLB9KIxUxoN1qM/mg7Evg5dAJcjTQqfm/Zghdjt46EFXJmKa",
+	"RaYkC2Qin+L1IDYeRINCZoMXg1Tr/MVwiA1TofSL56PnoyHN2fDmCKvwOHDaIxv7Hrh57ydFoUtHczm6",
+	"495znsDnweJjZgkTprSVCv7oWztbm29xXFUNhSteHOm1OdXi3aF9C03oWBS6NH9d54/WAb6Q0AmZY8Gh",
+	"4DpV3qwoaB/grS4Zg54BcKLsI/vWxLbS/ZIB7GG4GsZ9FFehkX7EHwPDUbuQ8kGWj3JojfG2etHWOQKW",
+	"4ejqbp/ABhdzfJLKZkl516tW+nOxK/DkQIsD4AkBHss5fm/rJBoCYeMCG5aDYaGRxWFKJ7+jJcSsvxSz",
+	"z33xdlz5lO/sBtBj4W7PHeEe4MLr9+jkx9eXpLoms4P8swZQeYPw9dPX/zcAgSUpejtEAQA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	mongodbadapter "github.com/casbin/mongodb-adapter/v3"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
 	"github.com/mcsamuelshoko/telko-moment-server/configs"
 	"github.com/mcsamuelshoko/telko-moment-server/docs"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/controllers"
@@ -13,12 +16,21 @@ import (
 	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/mongodb"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/lifecycle"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/media"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
 	pkgservices "github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 	"github.com/swaggo/fiber-swagger" // fiber-swagger middleware
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -53,39 +65,72 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Str("app", "MAIN").Msg("failed to load config file")
 	}
-	log.Info().Interface(kName, iName).Msg("Success: Loaded configuration")
+	log.Info().Interface(kName, iName).Interface("config", cfg.Redacted()).Msg("Success: Loaded configuration")
 
 	// Programmatically set Swagger info
 	docs.SwaggerInfo.Title = "Tellme Messenger API"
 	docs.SwaggerInfo.Description = "Codenamed telko-moment-server."
 	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.Host = "localhost" + cfg.Server.Port // Dynamic for local dev
-	docs.SwaggerInfo.BasePath = "/v1"                     // Adjust if needed
+	docs.SwaggerInfo.Host = "localhost:" + cfg.Server.Port // Dynamic for local dev
+	docs.SwaggerInfo.BasePath = "/v1"                      // Adjust if needed
 	docs.SwaggerInfo.Schemes = []string{"http"}
 
+	// ::: Lifecycle
+	shutdownTimeout, err := time.ParseDuration(cfg.Server.ShutdownTimeout)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Invalid shutdown timeout")
+	}
+	drainDelay, err := time.ParseDuration(cfg.Server.ShutdownDrainDelay)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Invalid shutdown drain delay")
+	}
+	if drainDelay >= shutdownTimeout {
+		log.Fatal().Interface(kName, iName).Dur("drainDelay", drainDelay).Dur("timeout", shutdownTimeout).Msg("The shutdown drain delay must be shorter than the shutdown timeout")
+	}
+	lifecycleMgr := lifecycle.NewManager(&log, shutdownTimeout)
+
+	// ::: Tracing (closed last, after everything that emits spans)
+	sampleRatio, err := strconv.ParseFloat(cfg.Tracing.SampleRatio, 64)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Invalid tracing sample ratio")
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), &log, cfg.Tracing.ServiceName, cfg.Tracing.Exporter, sampleRatio)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to set up tracing")
+	}
+	lifecycleMgr.OnClose("tracing", shutdownTracing)
+
 	// Initialize MongoDB connection with timeout
 	log.Info().Interface(kName, iName).Msg("initializing MongoDB connection")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoDB.URI))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoDB.URI).SetMonitor(otelmongo.NewMonitor()))
 	if err != nil {
-		log.Fatal().Err(err).Interface(kName, iName).Str("uri", cfg.MongoDB.URI).Msg("Failed to connect to MongoDB")
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to connect to MongoDB")
 	}
-	defer func() {
-		if err := client.Disconnect(context.Background()); err != nil {
-			log.Error().Err(err).Interface(kName, iName).Msg("Failed to disconnect from MongoDB") // Error, not Fatal
-		}
-	}()
 	log.Info().Interface(kName, iName).Msg("Success: Established MongoDB connection")
 
+	lifecycleMgr.OnClose("mongodb", func(ctx context.Context) error {
+		return client.Disconnect(ctx)
+	})
+
 	db := client.Database(cfg.MongoDB.Database)
 
-	// Create initial indexes
-	// ::: Indexes
-	err = internalmongodb.CreateInitialIndexes(db, &log)
-	if err != nil {
-		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create initial indexes")
-		return
+	// ::: Migrations
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		code := runMigrateCommand(&log, db, os.Args[2:])
+		// the close hooks disconnect MongoDB & flush the traces of the migration
+		if err = lifecycleMgr.Shutdown(); err != nil && code == 0 {
+			code = 1
+		}
+		os.Exit(code)
+	}
+	if cfg.MongoDB.AutoMigrate {
+		if err = applyMigrations(&log, db); err != nil {
+			log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to apply migrations")
+			return
+		}
+		log.Info().Interface(kName, iName).Msg("Success: Applied migrations")
 	}
 
 	// Initialize dependencies
@@ -133,26 +178,135 @@ func main() {
 	// ::: Users
 	userRepo := mongodb.NewUserRepository(&log, db, encryptionSvc, keyHashSvc)
 	userSvc := services.NewUserService(&log, userRepo)
-	userCtrl := controllers.NewUserController(&log, userSvc, settingsSvc, authznSvc)
 
 	// ::: Authentication
 	authctRepo := mongodb.NewAuthenticationRepository(&log, db, encryptionSvc, keyHashSvc)
 	authctSvc := services.NewAuthenticationService(&log, authctRepo)
-	authctCtrl := controllers.NewAuthController(&log, userSvc, authctSvc, settingsSvc, jwtSvc)
+
+	// ::: Registration, the user with their settings & session in one unit of work
+	registrationSvc := services.NewRegistrationService(&log, mongodb.NewUnitOfWork(&log, db), userRepo, settingsRepo, authctRepo)
+	userCtrl := controllers.NewUserController(&log, userSvc, registrationSvc, authznSvc)
+	authctCtrl := controllers.NewAuthController(&log, userSvc, authctSvc, registrationSvc, jwtSvc)
 
 	// ::: Messages
-	msgRepo := mongodb.NewMessageRepository(&log, db)
-	msgSvc := services.NewMessageService(msgRepo)
+	chatKeyRepo := mongodb.NewChatKeyRepository(&log, db, encryptionSvc)
+	msgRepo := mongodb.NewMessageRepository(&log, db, chatKeyRepo)
+	mediaRepo := mongodb.NewMediaRepository(db)
+	chatRepo := mongodb.NewChatRepository(&log, db)
+	msgSvc := services.NewMessageService(&log, msgRepo, mediaRepo, chatRepo, mongodb.NewPlayedReceiptRepository(&log, db))
 	msgCtrl := controllers.NewMessageController(&log, msgSvc)
 
+	// ::: Media, metadata in mongo & content in the configured blob store
+	blobStore, err := newBlobStore(&log, cfg.Storage)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create blob store")
+	}
+	uploadExpiry, err := time.ParseDuration(cfg.Media.UploadExpiry)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Invalid upload expiry")
+	}
+	mediaLimits := services.MediaLimits{
+		MaxUploadBytes:    cfg.Media.MaxUploadBytes,
+		AllowedTypes:      strings.Fields(cfg.Media.AllowedTypes),
+		MaxResumableBytes: cfg.Media.MaxResumableBytes,
+		ChunkMaxBytes:     cfg.Media.ChunkMaxBytes,
+		UploadExpiry:      uploadExpiry,
+	}
+	urlSigner, err := pkgservices.NewHMACURLSigner(&log, cfg.Media.URLSigningKey)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create media URL signer")
+	}
+	urlTTL, err := time.ParseDuration(cfg.Media.URLTTL)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Invalid media URL lifetime")
+	}
+	mediaURLs := services.NewMediaURLs(urlSigner, cfg.Media.PublicURL, urlTTL)
+	mediaProcessor, err := newMediaProcessor(&log, cfg.Media)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create media processor")
+	}
+	mediaTranscoder, err := newMediaTranscoder(&log, cfg.Media)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create media transcoder")
+	}
+	mediaProcessing := services.NewMediaProcessing(&log, blobStore, mediaProcessor, mediaTranscoder)
+	mediaScanner, err := newMediaScanner(&log, cfg.Media)
+	if err != nil {
+		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create media scanner")
+	}
+	// identical uploads of a user share their content, unreferenced content is collected hourly
+	blobRepo := mongodb.NewBlobRepository(&log, db)
+	mediaBlobs := services.NewMediaBlobs(&log, blobRepo, blobStore, mediaProcessing, mediaScanner != nil)
+	lifecycleMgr.Go("blob-gc", func(ctx context.Context) error {
+		return mediaBlobs.CollectGarbage(ctx, time.Hour)
+	})
+	// uploads stay quarantined until scanned, a clamd outage only delays them
+	if mediaScanner != nil {
+		mediaScanning := services.NewMediaScanning(&log, mediaRepo, blobRepo, blobStore, mediaScanner, services.NewLogScanAlertNotifier(&log))
+		lifecycleMgr.Go("media-scan", func(ctx context.Context) error {
+			return mediaScanning.ScanPending(ctx, 5*time.Second)
+		})
+	}
+	// storage quotas of the subscription plans, enterprise members share theirs
+	quotaSvc := services.NewStorageQuotaService(&log, mongodb.NewSubscriptionRepository(&log, db), mediaRepo, services.PlanQuotas{
+		Free:       cfg.Media.QuotaFreeBytes,
+		Basic:      cfg.Media.QuotaBasicBytes,
+		Premium:    cfg.Media.QuotaPremiumBytes,
+		Enterprise: cfg.Media.QuotaEnterpriseBytes,
+	})
+	mediaSvc := services.NewMediaService(&log, mediaRepo, chatRepo, blobStore, mediaURLs, mediaBlobs, quotaSvc, mediaLimits)
+	gallerySvc := services.NewGalleryService(&log, mediaRepo, msgRepo, chatRepo, mediaURLs)
+	mediaCtrl := controllers.NewMediaController(&log, mediaSvc, gallerySvc, quotaSvc)
+
+	// ::: Resumable uploads, chunks are kept in the blob store until completed or expired
+	uploadSvc := services.NewResumableUploadService(&log, mongodb.NewUploadRepository(&log, db), mediaRepo, chatRepo, blobStore, mediaURLs, mediaBlobs, quotaSvc, mediaLimits)
+	uploadCtrl := controllers.NewUploadController(&log, uploadSvc)
+	lifecycleMgr.Go("upload-expiry", func(ctx context.Context) error {
+		return uploadSvc.ExpireUploads(ctx, 10*time.Minute)
+	})
+
+	// ::: Keys (end-to-end encryption)
+	deviceKeyRepo := mongodb.NewDeviceKeyRepository(&log, db)
+	keySvc := services.NewKeyDistributionService(&log, deviceKeyRepo, chatRepo, mongodb.NewRateLimitRepository(&log, db), services.NewLogPreKeyAlertNotifier(&log))
+	keyCtrl := controllers.NewKeyController(&log, keySvc)
+
+	// ::: Presence, held in memory, only when users were last seen is written, lazily
+	presenceSvc := services.NewPresenceService(&log, mongodb.NewLastSeenRepository(&log, db), settingsRepo, chatRepo)
+	presenceCtrl := controllers.NewPresenceController(&log, presenceSvc)
+	lifecycleMgr.Go("presence-flush", func(ctx context.Context) error {
+		return presenceSvc.FlushLastSeen(ctx, time.Minute)
+	})
+
+	// ::: Health
+	healthSvc := services.NewHealthService(&log,
+		internalmongodb.NewHealthChecker(client),
+		services.NewAuthorizationHealthChecker(authznSvc),
+	)
+	healthCtrl := controllers.NewHealthController(&log, healthSvc)
+
 	// ::: Middleware
 	authctMdw := middleware.NewJWTAuthMiddleware(&log, jwtSvc)
 	authCtxMdw := middleware.NewAuthContextMiddleware(&log, userRepo)
 
 	// Setup Fiber app
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: handlers.NewErrorHandler(&log),
+		// bodies are read when a handler asks for them, so that the body limits below reject them unread. Bodies
+		// above BodyLimit are not rejected then, only read lazily. The generated handlers read whole bodies, the
+		// media uploads stream.
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		BodyLimit:                    fiber.DefaultBodyLimit,
+	})
+	// only uploads & chunks may exceed the default, uploads with room for the multipart framing
+	bodyLimitMdw := middleware.NewBodyLimitMiddleware(&log, fiber.DefaultBodyLimit,
+		middleware.BodyLimitRoute{Method: fiber.MethodPost, Path: "/api/v1/media", Limit: cfg.Media.MaxUploadBytes + 1<<20, Stream: true},
+		middleware.BodyLimitRoute{Method: fiber.MethodPatch, Path: "/api/v1/media/uploads/", Limit: cfg.Media.ChunkMaxBytes},
+	)
 
 	// :::: add middleware
+	// Request ID & request scoped logger, first so every other middleware can correlate
+	app.Use(middleware.RequestLogger(&log))
 	// Logging remote IP and Port
 	app.Use(logger.New(logger.Config{
 		Format: "[${ip}]:${port} ${status} - ${method} ${path}\n\n",
@@ -160,31 +314,119 @@ func main() {
 	// Idempotency
 	//app.Use(idempotency.New())	//TODO use later, for now disable any interfering on requests middleware for testing & development speed
 
-	// ::: health & Metrics
-	//app.Use(healthcheck.New())	// Provide a minimal config
-	//app.Get("/metrics", monitor.New())	// Initialize default config (Assign the middleware to /metrics)
+	// ::: Tracing & Metrics
+	app.Use(middleware.Tracing())
+	app.Use(middleware.Metrics())
+	app.Use(bodyLimitMdw.Limit())
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+
+	// ::: OpenAPI validation, requests are checked against the spec embedded in api/api.gen.go before reaching the handlers
+	if cfg.OpenAPI.ValidateRequests {
+		swagger, err := api.GetSwagger()
+		if err != nil {
+			log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to load embedded OpenAPI document")
+		}
+		openAPIMdw, err := middleware.NewOpenAPIValidatorMiddleware(&log, swagger, "/api/v1", cfg.OpenAPI.ValidateResponses)
+		if err != nil {
+			log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to create OpenAPI validator")
+		}
+		app.Use(openAPIMdw.Validate())
+	}
 
 	// Setup routes
-	routesHandler := handlers.NewRoutesHandler(&log, authctMdw, authCtxMdw, userCtrl, settingsCtrl, authctCtrl, msgCtrl)
+	routesHandler := handlers.NewRoutesHandler(&log, authctMdw, authCtxMdw, userCtrl, settingsCtrl, authctCtrl, msgCtrl, keyCtrl, mediaCtrl, uploadCtrl, presenceCtrl, healthCtrl)
 	routesHandler.SetupRoutes(app) // layered
 
 	// handle swagger routes
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
-	// Start server with graceful shutdown
-	log.Info().Str("port", cfg.Server.Port).Msg("Starting server")
-	func() {
-		if err := app.Listen(`:` + cfg.Server.Port); err != nil {
-			log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to listen")
+	// Start server with graceful shutdown, on SIGINT/SIGTERM stop accepting connections & drain in-flight requests
+	lifecycleMgr.OnStop("readiness", func(ctx context.Context) error {
+		healthSvc.SetShuttingDown()
+		return nil
+	})
+	// load balancers only stop routing to the server once they saw /readyz fail, requests keep coming until then
+	lifecycleMgr.OnStop("drain", func(ctx context.Context) error {
+		log.Info().Interface(kName, iName).Dur("delay", drainDelay).Msg("Draining before closing the listener")
+		select {
+		case <-time.After(drainDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-	}()
-
-	// Wait for interrupt signal (e.g., Ctrl+C)
-	err = app.ShutdownWithContext(context.Background())
+	})
+	// event streams never end by themselves, the http server would wait for them
+	lifecycleMgr.OnStop("presence", presenceSvc.Close)
+	lifecycleMgr.OnStop("http", app.ShutdownWithContext)
+	log.Info().Str("port", cfg.Server.Port).Msg("Starting server")
+	err = lifecycleMgr.Run(func() error {
+		return app.Listen(`:` + cfg.Server.Port)
+	})
 	if err != nil {
-		log.Fatal().Err(err).Interface(kName, iName).Msg("Failed to shutdown")
-		return
+		log.Error().Err(err).Interface(kName, iName).Msg("Server stopped with errors")
+		os.Exit(1)
 	}
 	log.Info().Interface(kName, iName).Msg("Server stopped")
 
 }
+
+// newBlobStore creates the blob store of the configured backend
+func newBlobStore(log *zerolog.Logger, cfg configs.StorageConfig) (storage.IBlobStore, error) {
+	if cfg.Backend == "s3" {
+		return storage.NewS3BlobStore(log, storage.S3Options{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			UseSSL:          cfg.S3.UseSSL,
+		})
+	}
+	return storage.NewLocalBlobStore(log, cfg.LocalPath)
+}
+
+// newMediaTranscoder creates the transcoder of audio uploads, voice notes among them, nil when transcoding is disabled
+func newMediaTranscoder(log *zerolog.Logger, cfg configs.MediaConfig) (media.ITranscoder, error) {
+	if !cfg.TranscodeAudio {
+		return nil, nil
+	}
+	if cfg.FFmpegPath == "" {
+		return nil, errors.New("MEDIA_TRANSCODE_AUDIO needs MEDIA_FFMPEG_PATH")
+	}
+	return media.NewFFmpegTranscoder(log, media.FFmpegOptions{FFmpegPath: cfg.FFmpegPath, FFprobePath: cfg.FFprobePath})
+}
+
+// newMediaScanner creates the malware scanner of uploads, nil when scanning is disabled
+func newMediaScanner(log *zerolog.Logger, cfg configs.MediaConfig) (media.IMediaScanner, error) {
+	if cfg.ClamdAddress == "" {
+		return nil, nil
+	}
+	timeout, err := time.ParseDuration(cfg.ScanTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return media.NewClamdScanner(log, media.ClamdOptions{Address: cfg.ClamdAddress, Timeout: timeout})
+}
+
+// newMediaProcessor processes images, & audio & video when ffmpeg is configured
+func newMediaProcessor(log *zerolog.Logger, cfg configs.MediaConfig) (media.IProcessor, error) {
+	var sizes []int
+	for _, field := range strings.Fields(cfg.ThumbnailSizes) {
+		size, _ := strconv.Atoi(field) // checked by the config validation
+		sizes = append(sizes, size)
+	}
+	slices.Sort(sizes)
+	imageOpts := media.ImageOptions{ThumbnailSizes: sizes, MaxPixels: cfg.MaxImagePixels}
+	if cfg.FFmpegPath == "" {
+		return media.Chain(media.NewImageProcessor(log, imageOpts)), nil
+	}
+	ffmpeg, err := media.NewFFmpegProcessor(log, media.FFmpegOptions{
+		FFmpegPath:  cfg.FFmpegPath,
+		FFprobePath: cfg.FFprobePath,
+		Image:       imageOpts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return media.Chain(media.NewImageProcessor(log, imageOpts), ffmpeg), nil
+}
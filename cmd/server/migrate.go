@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/databases/mongodb/migrations"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/mongo"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// migrationTimeout bounds applying pending migrations on startup, long enough to wait out another instance's lock
+const migrationTimeout = 5 * time.Minute
+
+// runMigrateCommand handles `server migrate ...` and returns the process exit code
+func runMigrateCommand(log *zerolog.Logger, db *mongo.Database, args []string) int {
+	const kName = "runMigrateCommand"
+
+	migrator, err := migrations.NewMigrator(log, db, migrations.All(log))
+	if err != nil {
+		log.Error().Err(err).Interface(kName, iName).Msg("Invalid migrations")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = migrations.RunCommand(ctx, migrator, args, os.Stdout)
+	if errors.Is(err, migrations.ErrUsage) {
+		fmt.Fprintln(os.Stderr, "usage: server "+migrations.Usage)
+		return 2
+	}
+	if err != nil {
+		log.Error().Err(err).Interface(kName, iName).Msg("Failed to run migrations")
+		return 1
+	}
+	return 0
+}
+
+// applyMigrations applies every pending migration, used on startup when MONGODB_AUTO_MIGRATE is set
+func applyMigrations(log *zerolog.Logger, db *mongo.Database) error {
+	migrator, err := migrations.NewMigrator(log, db, migrations.All(log))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+	_, err = migrator.Up(ctx, 0)
+	return err
+}
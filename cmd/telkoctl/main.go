@@ -0,0 +1,184 @@
+// telkoctl is the administration CLI of telko-moment-server.
+// It loads the server's configuration (.env, CONFIG_FILE & environment) and works through the same repositories,
+// so encrypted & hashed fields are written exactly as the server would write them.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/configs"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/mongodb"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	pkgservices "github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// logger Interface Name
+const iName = "telkoctl"
+
+// errUsage makes main print the command's usage, the command has already said what is wrong
+var errUsage = errors.New("invalid usage")
+
+// app holds the dependencies shared by the commands
+type app struct {
+	log             *zerolog.Logger
+	cfg             *configs.Config
+	client          *mongo.Client
+	db              *mongo.Database
+	encryptionSvc   pkgservices.IEncryptionService
+	keyHashSvc      pkgservices.ISearchKeyService
+	userRepo        repository.IUserRepository
+	authctRepo      repository.IAuthenticationRepository
+	registrationSvc services.IRegistrationService
+}
+
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"user":         {usage: "user create|disable|enable|reset-password ...", help: "manage user accounts", run: runUser},
+	"session":      {usage: "session revoke <userId>", help: "sign a user out of every device", run: runSession},
+	"policy":       {usage: "policy list | add|remove <sub_rule> <obj> <act> [allow|deny]", help: "manage Casbin policies", run: runPolicy},
+	"migrate":      {usage: "migrate up [version] | down [steps] | status", help: "apply, roll back & list database migrations", run: runMigrate},
+	"reencrypt":    {usage: "reencrypt [-dry-run], with the previous keys in TELKO_OLD_AES_KEY & TELKO_OLD_HMAC_KEY, or \"-\" & stdin", help: "re-encrypt stored data with the configured keys", run: runReEncrypt},
+	"subscription": {usage: "subscription grant [-plan p] [-enterprise id] [-days n] <userId>", help: "subscribe a user to a storage plan", run: runSubscription},
+	"seed":         {usage: "seed [-users n] [-messages n] [-password p]", help: "create demo users with a chat & messages", run: runSeed},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	const kName = "run"
+
+	flags := flag.NewFlagSet(iName, flag.ContinueOnError)
+	verbose := flags.Bool("v", false, "log debug messages")
+	flags.Usage = printUsage
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		printUsage()
+		return 2
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", flags.Arg(0))
+		printUsage()
+		return 2
+	}
+
+	// logs go to stderr, stdout is kept for the commands' results
+	level := zerolog.InfoLevel
+	if *verbose {
+		level = zerolog.DebugLevel
+	}
+	log := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.TimeOnly}).Level(level).With().Timestamp().Logger()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := newApp(ctx, &log)
+	if err != nil {
+		log.Error().Err(err).Interface(kName, iName).Msg("Failed to initialize")
+		return 1
+	}
+	defer a.close()
+
+	err = cmd.run(ctx, a, flags.Args()[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, "usage: "+iName+" "+cmd.usage)
+		return 2
+	}
+	if err != nil {
+		log.Error().Err(err).Interface(kName, iName).Str("command", flags.Arg(0)).Msg("Command failed")
+		return 1
+	}
+	return 0
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("usage: " + iName + " [-v] <command> [arguments]\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-12s %s\n               %s\n", name, commands[name].help, commands[name].usage)
+	}
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// newApp loads the configuration & connects to MongoDB like the server does
+func newApp(ctx context.Context, log *zerolog.Logger) (*app, error) {
+	cfg, err := configs.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(connectCtx, options.Client().ApplyURI(cfg.MongoDB.URI))
+	if err != nil {
+		return nil, fmt.Errorf("connect to MongoDB: %w", err)
+	}
+	db := client.Database(cfg.MongoDB.Database)
+
+	encryptionSvc, err := pkgservices.NewAESEncryptionService(cfg.Encryption.AESKey, log)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+	keyHashSvc, err := pkgservices.NewHMACSearchKeyService(log, cfg.Hashing.HMACSecretKey)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	userRepo := mongodb.NewUserRepository(log, db, encryptionSvc, keyHashSvc)
+	authctRepo := mongodb.NewAuthenticationRepository(log, db, encryptionSvc, keyHashSvc)
+	return &app{
+		log:             log,
+		cfg:             cfg,
+		client:          client,
+		db:              db,
+		encryptionSvc:   encryptionSvc,
+		keyHashSvc:      keyHashSvc,
+		userRepo:        userRepo,
+		authctRepo:      authctRepo,
+		registrationSvc: services.NewRegistrationService(log, mongodb.NewUnitOfWork(log, db), userRepo, mongodb.NewSettingsRepository(log, db), authctRepo),
+	}, nil
+}
+
+func (a *app) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = a.client.Disconnect(ctx)
+}
+
+// parseFlags parses the flags of a sub command, printing their defaults on error
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
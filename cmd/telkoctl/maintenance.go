@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	internalmongodb "github.com/mcsamuelshoko/telko-moment-server/internal/databases/mongodb"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/databases/mongodb/migrations"
+	pkgservices "github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"io"
+	"os"
+	"strings"
+)
+
+func runMigrate(ctx context.Context, a *app, args []string) error {
+	migrator, err := migrations.NewMigrator(a.log, a.db, migrations.All(a.log))
+	if err != nil {
+		return err
+	}
+	err = migrations.RunCommand(ctx, migrator, args, os.Stdout)
+	if errors.Is(err, migrations.ErrUsage) {
+		return errUsage
+	}
+	return err
+}
+
+// oldKeysFromStdin is a placeholder of the environment variables, asking to read the previous keys from stdin
+const oldKeysFromStdin = "-"
+
+// runReEncrypt rotates the master encryption key & optionally the search key: set the new keys in the configuration,
+// then pass the previous ones in TELKO_OLD_AES_KEY & TELKO_OLD_HMAC_KEY. Keys never go on the command line, where
+// the process list & the shell history would show them; set TELKO_OLD_AES_KEY to "-" to read them from stdin instead.
+func runReEncrypt(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be rewritten without writing")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	oldAESKey, oldHMACKey, err := oldKeys(os.Getenv("TELKO_OLD_AES_KEY"), os.Getenv("TELKO_OLD_HMAC_KEY"), os.Stdin)
+	if err != nil {
+		return err
+	}
+	if oldAESKey == "" {
+		fmt.Fprintln(os.Stderr, "TELKO_OLD_AES_KEY is required")
+		return errUsage
+	}
+
+	oldEncryptionSvc, err := pkgservices.NewAESEncryptionService(oldAESKey, a.log)
+	if err != nil {
+		return err
+	}
+	oldKeyHashSvc := a.keyHashSvc
+	if oldHMACKey != "" {
+		if oldKeyHashSvc, err = pkgservices.NewHMACSearchKeyService(a.log, oldHMACKey); err != nil {
+			return err
+		}
+	}
+
+	report, err := internalmongodb.ReEncrypt(ctx, a.db, a.log,
+		internalmongodb.KeyRing{Encryption: oldEncryptionSvc, SearchKey: oldKeyHashSvc},
+		internalmongodb.KeyRing{Encryption: a.encryptionSvc, SearchKey: a.keyHashSvc},
+		*dryRun,
+	)
+	if report != nil {
+		verb := "re-encrypted"
+		if *dryRun {
+			verb = "would re-encrypt"
+		}
+		fmt.Printf("%s %d user(s) & %d chat key(s), %d document(s) already use the new keys\n", verb, report.Users, report.ChatKeys, report.AlreadyCurrent)
+		for _, skipped := range report.Skipped {
+			fmt.Printf("skipped %s\n", skipped)
+		}
+		if oldHMACKey != "" && !*dryRun {
+			fmt.Println("the search key changed: every user has to sign in again")
+		}
+	}
+	return err
+}
+
+// oldKeys returns the previous master & search keys, reading them from in, the master key on the first line & the
+// optional search key on the second, when aesKey is "-"
+func oldKeys(aesKey string, hmacKey string, in io.Reader) (string, string, error) {
+	if aesKey != oldKeysFromStdin {
+		return aesKey, hmacKey, nil
+	}
+	scanner := bufio.NewScanner(in)
+	var lines []string
+	for len(lines) < 2 && scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", fmt.Errorf("read the previous keys from stdin: %w", err)
+	}
+	lines = append(lines, "", "")
+	return lines[0], lines[1], nil
+}
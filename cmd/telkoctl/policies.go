@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	mongodbadapter "github.com/casbin/mongodb-adapter/v3"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// authznModelFilePath is resolved from the working directory, like the server does
+const authznModelFilePath = "configs/casbin/abac_model.conf"
+
+func runPolicy(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	// the adapter opens its own connection, only connect when a policy command runs
+	adapter, err := mongodbadapter.NewAdapter(a.cfg.MongoDB.URI + "/" + a.cfg.MongoDB.Database)
+	if err != nil {
+		return fmt.Errorf("initialize MongoDB adapter: %w", err)
+	}
+	authznSvc, err := services.NewCasbinAuthorizationService(a.log, authznModelFilePath, adapter)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		policies, err := authznSvc.Policies()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SUB_RULE\tOBJ\tACT\tEFT")
+		for _, policy := range policies {
+			fmt.Fprintln(w, strings.Join(policy, "\t"))
+		}
+		return w.Flush()
+	case "add", "remove":
+		subRule, obj, act, eft, err := policyArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if args[0] == "add" {
+			added, err := authznSvc.AddPolicy(subRule, obj, act, eft)
+			if err != nil {
+				return err
+			}
+			if !added {
+				fmt.Println("policy already exists")
+				return nil
+			}
+			fmt.Println("added policy")
+			return nil
+		}
+		removed, err := authznSvc.RemovePolicy(subRule, obj, act, eft)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			fmt.Println("policy does not exist")
+			return nil
+		}
+		fmt.Println("removed policy")
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+// policyArgs reads <sub_rule> <obj> <act> [eft], the effect defaults to allow
+func policyArgs(args []string) (string, string, string, string, error) {
+	switch len(args) {
+	case 3:
+		return args[0], args[1], args[2], services.EffectAllow, nil
+	case 4:
+		if args[3] != services.EffectAllow && args[3] != services.EffectDeny {
+			fmt.Fprintln(os.Stderr, "the effect must be allow or deny")
+			return "", "", "", "", errUsage
+		}
+		return args[0], args[1], args[2], args[3], nil
+	default:
+		return "", "", "", "", errUsage
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/mongodb"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+var demoNames = []string{"Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Grace", "Heidi"}
+
+var demoLines = []string{
+	"Hey everyone 👋",
+	"Did you see the new release?",
+	"Yes! The voice notes are great.",
+	"Lunch at noon?",
+	"Count me in.",
+	"Sharing the slides in a minute.",
+	"Thanks, that helps a lot.",
+	"See you tomorrow!",
+}
+
+// runSeed creates demo users sharing a group chat, plus a direct chat between the first two.
+// Existing demo users are reused, so it can run repeatedly.
+func runSeed(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	userCount := flags.Int("users", 3, fmt.Sprintf("number of demo users, at most %d", len(demoNames)))
+	messageCount := flags.Int("messages", 20, "number of messages in the group chat")
+	password := flags.String("password", "Demo-pass1!", "password of the demo users")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *userCount < 2 || *userCount > len(demoNames) || *messageCount < 0 {
+		return errUsage
+	}
+	if !utils.IsStrongPassword(*password) {
+		return apperrors.Validation(apperrors.CodeWeakPassword, "password is weak", apperrors.InvalidField("password", "password is weak"))
+	}
+
+	users := make([]*models.User, 0, *userCount)
+	for _, name := range demoNames[:*userCount] {
+		user, err := seedUser(ctx, a, name, *password)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+	}
+
+	chatRepo := mongodb.NewChatRepository(a.log, a.db)
+	msgRepo := mongodb.NewMessageRepository(a.log, a.db, mongodb.NewChatKeyRepository(a.log, a.db, a.encryptionSvc))
+
+	participants := make([]primitive.ObjectID, 0, len(users))
+	for _, user := range users {
+		participants = append(participants, user.ID)
+	}
+
+	now := time.Now()
+	group, err := chatRepo.Create(ctx, &models.Chat{
+		Type:         models.ChatTypeGroup,
+		Name:         "Demo group",
+		MemberCount:  int64(len(users)),
+		Participants: participants,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		return err
+	}
+	direct, err := chatRepo.Create(ctx, &models.Chat{
+		Type:         models.ChatTypeDirect,
+		MemberCount:  2,
+		Participants: participants[:2],
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		return err
+	}
+
+	// spread the messages over the last hours, oldest first
+	start := now.Add(-time.Duration(*messageCount) * 5 * time.Minute)
+	for i := 0; i < *messageCount; i++ {
+		sentAt := start.Add(time.Duration(i) * 5 * time.Minute)
+		if err = seedMessage(ctx, msgRepo, group.ID, users[i%len(users)].ID, demoLines[i%len(demoLines)], sentAt); err != nil {
+			return err
+		}
+	}
+	for i, line := range []string{"Got a minute?", "Sure, what's up?"} {
+		if err = seedMessage(ctx, msgRepo, direct.ID, users[i].ID, line, now); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("seeded %d user(s) with password %q\n", len(users), *password)
+	for _, user := range users {
+		fmt.Printf("  %s  %s\n", user.ID.Hex(), user.Email)
+	}
+	fmt.Printf("group chat %s with %d message(s), direct chat %s\n", group.ID.Hex(), *messageCount, direct.ID.Hex())
+	return nil
+}
+
+// seedUser returns the demo user named name, creating it with default settings when it does not exist
+func seedUser(ctx context.Context, a *app, name string, password string) (*models.User, error) {
+	email := "demo." + strings.ToLower(name) + "@telko.test"
+	existing, err := a.userRepo.GetByEmail(ctx, email)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, err
+	}
+
+	user := models.GetUserDefaultsFromHeaders(map[string]string{})
+	user.FirstName, user.LastName = name, "Demo"
+	user.Email, user.Username = email, email
+	if user.Password, err = utils.HashPassword(password); err != nil {
+		return nil, err
+	}
+	return a.registrationSvc.Register(ctx, user, models.GetSettingsDefaultsFromHeaders(map[string]string{}))
+}
+
+func seedMessage(ctx context.Context, msgRepo repository.MessageRepository, chatID, senderID primitive.ObjectID, content string, sentAt time.Time) error {
+	_, err := msgRepo.Create(ctx, &models.Message{
+		ChatID:      chatID,
+		SenderID:    senderID,
+		MessageType: models.MessageTypeText,
+		Content:     content,
+		Timestamp:   primitive.NewDateTimeFromTime(sentAt),
+		Status:      "sent",
+		CreatedAt:   sentAt,
+		UpdatedAt:   sentAt,
+	})
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"os"
+	"slices"
+	"time"
+)
+
+func runSubscription(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || args[0] != "grant" {
+		return errUsage
+	}
+	return subscriptionGrant(ctx, a, args[1:])
+}
+
+// subscriptionGrant subscribes a user to a plan starting now, until payments are integrated
+func subscriptionGrant(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("subscription grant", flag.ContinueOnError)
+	plan := flags.String("plan", models.PlanBasic, "plan: free, basic, premium or enterprise")
+	enterprise := flags.String("enterprise", "", "id of the enterprise whose members share the quota, required by the enterprise plan")
+	days := flags.Int("days", 0, "length of the subscription in days, 0 never ends")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	if !slices.Contains([]string{models.PlanFree, models.PlanBasic, models.PlanPremium, models.PlanEnterprise}, *plan) {
+		fmt.Fprintln(os.Stderr, "-plan must be free, basic, premium or enterprise")
+		return errUsage
+	}
+	if (*plan == models.PlanEnterprise) != (*enterprise != "") {
+		fmt.Fprintln(os.Stderr, "-enterprise is required by & only allowed with the enterprise plan")
+		return errUsage
+	}
+	if *days < 0 {
+		fmt.Fprintln(os.Stderr, "-days must not be negative")
+		return errUsage
+	}
+
+	user, err := a.userRepo.GetByID(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	subscription := &models.Subscription{
+		UserId:    user.ID,
+		PlanType:  *plan,
+		StartDate: now,
+		Status:    models.SubscriptionStatusActive,
+	}
+	if *enterprise != "" {
+		if subscription.EnterpriseId, err = primitive.ObjectIDFromHex(*enterprise); err != nil {
+			fmt.Fprintln(os.Stderr, "-enterprise must be an object id")
+			return errUsage
+		}
+	}
+	if *days > 0 {
+		subscription.EndDate = now.AddDate(0, 0, *days)
+	}
+
+	created, err := mongodb.NewSubscriptionRepository(a.log, a.db).Create(ctx, subscription)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("subscribed user %s to the %s plan (subscription %s)\n", flags.Arg(0), created.PlanType, created.Id.Hex())
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"math/big"
+	"os"
+	"time"
+)
+
+func runUser(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "create":
+		return userCreate(ctx, a, args[1:])
+	case "disable":
+		return userDisable(ctx, a, args[1:])
+	case "enable":
+		return userEnable(ctx, a, args[1:])
+	case "reset-password":
+		return userResetPassword(ctx, a, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func runSession(ctx context.Context, a *app, args []string) error {
+	if len(args) != 2 || args[0] != "revoke" {
+		return errUsage
+	}
+	if _, err := a.userRepo.GetByID(ctx, args[1]); err != nil {
+		return err
+	}
+	if err := revokeSessions(ctx, a, args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("revoked the sessions of user %s\n", args[1])
+	return nil
+}
+
+// userCreate registers a user like the API does, with default settings
+func userCreate(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := flags.String("email", "", "email address, required unless -phone is set")
+	phone := flags.String("phone", "", "phone number, required unless -email is set")
+	password := flags.String("password", "", "password, generated when empty")
+	firstName := flags.String("first-name", "", "first name")
+	lastName := flags.String("last-name", "", "last name")
+	username := flags.String("username", "", "username, defaults to the email or phone number")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	user := models.GetUserDefaultsFromHeaders(map[string]string{})
+	user.FirstName, user.LastName = *firstName, *lastName
+	switch {
+	case *email != "":
+		if !utils.IsValidEmail(*email) {
+			return apperrors.Validation(apperrors.CodeInvalidEmail, "invalid email", apperrors.InvalidField("email", "email format is invalid"))
+		}
+		if _, err := a.userRepo.GetByEmail(ctx, *email); !errors.Is(err, apperrors.ErrNotFound) {
+			if err != nil {
+				return err
+			}
+			return apperrors.Conflict(apperrors.CodeEmailTaken, "email has already been used")
+		}
+		user.Email, user.Username = *email, *email
+	case *phone != "":
+		if !utils.IsValidPhoneNumber(*phone) {
+			return apperrors.Validation(apperrors.CodeInvalidPhoneNumber, "invalid phone number", apperrors.InvalidField("phoneNumber", "phone number format is invalid"))
+		}
+		if _, err := a.userRepo.GetByPhoneNumber(ctx, *phone); !errors.Is(err, apperrors.ErrNotFound) {
+			if err != nil {
+				return err
+			}
+			return apperrors.Conflict(apperrors.CodePhoneNumberTaken, "phone number has already been used")
+		}
+		user.PhoneNumber, user.Username = *phone, *phone
+	default:
+		fmt.Fprintln(os.Stderr, "-email or -phone is required")
+		return errUsage
+	}
+	if *username != "" {
+		user.Username = *username
+	}
+
+	plainPassword, generated, err := passwordOrGenerate(*password)
+	if err != nil {
+		return err
+	}
+	if user.Password, err = utils.HashPassword(plainPassword); err != nil {
+		return err
+	}
+
+	createdUser, err := a.registrationSvc.Register(ctx, user, models.GetSettingsDefaultsFromHeaders(map[string]string{}))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created user %s\n", createdUser.ID.Hex())
+	if generated {
+		fmt.Printf("password: %s\n", plainPassword)
+	}
+	return nil
+}
+
+// userDisable blocks the user from signing in & revokes their sessions
+func userDisable(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user disable", flag.ContinueOnError)
+	status := flags.String("status", models.UserStatusDeactivated, "status to set: deactivated or banned")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	if *status != models.UserStatusDeactivated && *status != models.UserStatusBanned {
+		fmt.Fprintln(os.Stderr, "-status must be deactivated or banned")
+		return errUsage
+	}
+
+	if err := setUserStatus(ctx, a, flags.Arg(0), *status); err != nil {
+		return err
+	}
+	if err := revokeSessions(ctx, a, flags.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Printf("user %s is %s\n", flags.Arg(0), *status)
+	return nil
+}
+
+func userEnable(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := setUserStatus(ctx, a, args[0], models.UserStatusActive); err != nil {
+		return err
+	}
+	fmt.Printf("user %s is %s\n", args[0], models.UserStatusActive)
+	return nil
+}
+
+// userResetPassword replaces the user's password & revokes their sessions
+func userResetPassword(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	password := flags.String("password", "", "new password, generated when empty")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	user, err := a.userRepo.GetByID(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	plainPassword, generated, err := passwordOrGenerate(*password)
+	if err != nil {
+		return err
+	}
+	if user.Password, err = utils.HashPassword(plainPassword); err != nil {
+		return err
+	}
+	user.UpdatedAt = time.Now()
+	if _, err = a.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err = revokeSessions(ctx, a, flags.Arg(0)); err != nil {
+		return err
+	}
+
+	fmt.Printf("reset the password of user %s\n", flags.Arg(0))
+	if generated {
+		fmt.Printf("password: %s\n", plainPassword)
+	}
+	return nil
+}
+
+func setUserStatus(ctx context.Context, a *app, userID string, status string) error {
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.Status = status
+	user.UpdatedAt = time.Now()
+	_, err = a.userRepo.Update(ctx, user)
+	return err
+}
+
+// revokeSessions deletes the user's refresh token, access tokens already issued expire on their own
+func revokeSessions(ctx context.Context, a *app, userID string) error {
+	return a.authctRepo.DeleteByUserID(ctx, userID)
+}
+
+// passwordOrGenerate validates password, or generates a strong one when it is empty
+func passwordOrGenerate(password string) (string, bool, error) {
+	if password != "" {
+		if !utils.IsStrongPassword(password) {
+			msg := "password is weak, please make it min-chars=8 and include a [Number], & [special character], & [small letter], & [uppercase letter]"
+			return "", false, apperrors.Validation(apperrors.CodeWeakPassword, msg, apperrors.InvalidField("password", msg))
+		}
+		return password, false, nil
+	}
+
+	// one character of each required class, then random ones from all of them
+	classes := []string{"ABCDEFGHJKLMNPQRSTUVWXYZ", "abcdefghijkmnopqrstuvwxyz", "23456789", "!@#$%^&*?"}
+	all := classes[0] + classes[1] + classes[2] + classes[3]
+	generated := make([]byte, 0, 16)
+	for i := 0; i < 16; i++ {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+		if err != nil {
+			return "", false, err
+		}
+		generated = append(generated, set[n.Int64()])
+	}
+	// move the required characters away from the start
+	for i := len(generated) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", false, err
+		}
+		generated[i], generated[j.Int64()] = generated[j.Int64()], generated[i]
+	}
+	return string(generated), true, nil
+}
@@ -7,31 +7,90 @@ import (
 	"path/filepath"
 )
 
+// Struct tags understood by the loader:
+//   - env:        environment variable the field is read from
+//   - envDefault: value used when neither the environment nor the config file sets the field
+//   - validate:   comma separated rules checked after loading, see validate.go
+//   - secret:     "true" redacts the value from errors & logs
+
 type JwtConfig struct {
-	Issuer                     string `env:"JWT_ISSUER" envDefault:"telko_moment"`
-	Secret                     string `env:"JWT_SECRET" envDefault:"secret"`
-	TokenDuration              string `env:"JWT_TOKEN_DURATION" envDefault:"1h"`
-	RefreshTokenSecret         string `env:"JWT_REFRESH_TOKEN_SECRET" envDefault:"secret"`
-	RefreshTokenDuration       string `env:"JWT_REFRESH_TOKEN_DURATION" envDefault:"24h"`
-	RefreshTokenDaysMultiplier string `env:"JWT_REFRESH_TOKEN_DAYS_MULTIPLIER" envDefault:"24h"`
+	Issuer                     string `json:"issuer" yaml:"issuer" env:"JWT_ISSUER" envDefault:"telko_moment" validate:"required"`
+	Secret                     string `json:"secret" yaml:"secret" env:"JWT_SECRET" validate:"required,hexmin=32" secret:"true"`
+	TokenDuration              string `json:"tokenDuration" yaml:"tokenDuration" env:"JWT_TOKEN_DURATION" envDefault:"1h" validate:"required,duration"`
+	RefreshTokenSecret         string `json:"refreshTokenSecret" yaml:"refreshTokenSecret" env:"JWT_REFRESH_TOKEN_SECRET" validate:"required,hexmin=32" secret:"true"`
+	RefreshTokenDuration       string `json:"refreshTokenDuration" yaml:"refreshTokenDuration" env:"JWT_REFRESH_TOKEN_DURATION" envDefault:"24h" validate:"required,duration"`
+	RefreshTokenDaysMultiplier string `json:"refreshTokenDaysMultiplier" yaml:"refreshTokenDaysMultiplier" env:"JWT_REFRESH_TOKEN_DAYS_MULTIPLIER" envDefault:"7" validate:"required,int"`
+}
+type StorageConfig struct {
+	Backend   string `json:"backend" yaml:"backend" env:"STORAGE_BACKEND" envDefault:"local" validate:"required,oneof=local s3"`
+	LocalPath string `json:"localPath" yaml:"localPath" env:"STORAGE_LOCAL_PATH" envDefault:"data/media" validate:"required"`
+	S3        struct {
+		Endpoint        string `json:"endpoint" yaml:"endpoint" env:"STORAGE_S3_ENDPOINT"`
+		Region          string `json:"region" yaml:"region" env:"STORAGE_S3_REGION"`
+		Bucket          string `json:"bucket" yaml:"bucket" env:"STORAGE_S3_BUCKET"`
+		AccessKeyID     string `json:"accessKeyId" yaml:"accessKeyId" env:"STORAGE_S3_ACCESS_KEY_ID" secret:"true"`
+		SecretAccessKey string `json:"secretAccessKey" yaml:"secretAccessKey" env:"STORAGE_S3_SECRET_ACCESS_KEY" secret:"true"`
+		UseSSL          bool   `json:"useSSL" yaml:"useSSL" env:"STORAGE_S3_USE_SSL" envDefault:"true"`
+	} `json:"s3" yaml:"s3"`
+}
+type MediaConfig struct {
+	MaxUploadBytes    int64  `json:"maxUploadBytes" yaml:"maxUploadBytes" env:"MEDIA_MAX_UPLOAD_BYTES" envDefault:"26214400" validate:"required"`
+	AllowedTypes      string `json:"allowedTypes" yaml:"allowedTypes" env:"MEDIA_ALLOWED_TYPES" envDefault:"image/jpeg image/png image/gif image/webp video/mp4 video/webm audio/mpeg audio/wave application/ogg application/pdf" validate:"required"`
+	MaxResumableBytes int64  `json:"maxResumableBytes" yaml:"maxResumableBytes" env:"MEDIA_MAX_RESUMABLE_BYTES" envDefault:"2147483648" validate:"required"`
+	ChunkMaxBytes     int64  `json:"chunkMaxBytes" yaml:"chunkMaxBytes" env:"MEDIA_CHUNK_MAX_BYTES" envDefault:"8388608" validate:"required"`
+	UploadExpiry      string `json:"uploadExpiry" yaml:"uploadExpiry" env:"MEDIA_UPLOAD_EXPIRY" envDefault:"24h" validate:"required,duration"`
+	PublicURL         string `json:"publicUrl" yaml:"publicUrl" env:"MEDIA_PUBLIC_URL" envDefault:"http://localhost:8080/api/v1" validate:"required"`
+	URLSigningKey     string `json:"urlSigningKey" yaml:"urlSigningKey" env:"MEDIA_URL_SIGNING_KEY" validate:"required,hexmin=32" secret:"true"`
+	URLTTL            string `json:"urlTtl" yaml:"urlTtl" env:"MEDIA_URL_TTL" envDefault:"1h" validate:"required,duration"`
+	ThumbnailSizes    string `json:"thumbnailSizes" yaml:"thumbnailSizes" env:"MEDIA_THUMBNAIL_SIZES" envDefault:"96 320 960" validate:"required,sizes"`
+	MaxImagePixels    int    `json:"maxImagePixels" yaml:"maxImagePixels" env:"MEDIA_MAX_IMAGE_PIXELS" envDefault:"50000000" validate:"required"`
+	FFmpegPath        string `json:"ffmpegPath" yaml:"ffmpegPath" env:"MEDIA_FFMPEG_PATH"` // audio & video are not processed when empty
+	FFprobePath       string `json:"ffprobePath" yaml:"ffprobePath" env:"MEDIA_FFPROBE_PATH" envDefault:"ffprobe"`
+	TranscodeAudio    bool   `json:"transcodeAudio" yaml:"transcodeAudio" env:"MEDIA_TRANSCODE_AUDIO" envDefault:"false"` // to Opus in Ogg, needs ffmpeg
+	ClamdAddress      string `json:"clamdAddress" yaml:"clamdAddress" env:"MEDIA_CLAMD_ADDRESS"`                          // uploads are not scanned when empty
+	ScanTimeout       string `json:"scanTimeout" yaml:"scanTimeout" env:"MEDIA_SCAN_TIMEOUT" envDefault:"2m" validate:"required,duration"`
+	// storage quotas in bytes per subscription plan, 0 is unlimited. Enterprise members share the enterprise quota.
+	QuotaFreeBytes       int64 `json:"quotaFreeBytes" yaml:"quotaFreeBytes" env:"MEDIA_QUOTA_FREE_BYTES" envDefault:"2147483648"`
+	QuotaBasicBytes      int64 `json:"quotaBasicBytes" yaml:"quotaBasicBytes" env:"MEDIA_QUOTA_BASIC_BYTES" envDefault:"10737418240"`
+	QuotaPremiumBytes    int64 `json:"quotaPremiumBytes" yaml:"quotaPremiumBytes" env:"MEDIA_QUOTA_PREMIUM_BYTES" envDefault:"107374182400"`
+	QuotaEnterpriseBytes int64 `json:"quotaEnterpriseBytes" yaml:"quotaEnterpriseBytes" env:"MEDIA_QUOTA_ENTERPRISE_BYTES" envDefault:"1099511627776"`
 }
 type Config struct {
 	MongoDB struct {
-		URI      string `env:"MONGODB_URI" envDefault:"mongodb://localhost:27017"`
-		Database string `env:"MONGODB_DB" envDefault:"tel_mont_db"`
-	} `json:"mongodb"`
+		URI         string `json:"uri" yaml:"uri" env:"MONGODB_URI" envDefault:"mongodb://localhost:27017" validate:"required,mongouri" secret:"true"`
+		Database    string `json:"database" yaml:"database" env:"MONGODB_DB" envDefault:"tel_mont_db" validate:"required"`
+		AutoMigrate bool   `json:"autoMigrate" yaml:"autoMigrate" env:"MONGODB_AUTO_MIGRATE" envDefault:"true"`
+	} `json:"mongodb" yaml:"mongodb"`
 	Server struct {
-		Port string `env:"SERVER_PORT" envDefault:":8080"`
-	} `json:"server"`
-	Jwt        JwtConfig `json:"jwt"`
+		Port            string `json:"port" yaml:"port" env:"SERVER_PORT" envDefault:"8080" validate:"required,port"`
+		ShutdownTimeout string `json:"shutdownTimeout" yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT" envDefault:"30s" validate:"required,duration"`
+		// how long the server keeps serving once unready, until load balancers stopped sending it requests
+		ShutdownDrainDelay string `json:"shutdownDrainDelay" yaml:"shutdownDrainDelay" env:"SERVER_SHUTDOWN_DRAIN_DELAY" envDefault:"5s" validate:"required,duration"`
+	} `json:"server" yaml:"server"`
+	Jwt        JwtConfig `json:"jwt" yaml:"jwt"`
 	Encryption struct {
-		AESKey string `env:"ENC_AES_KEY" envDefault:"0123456789abcdef"`
-	} `json:"encryption"`
+		AESKey string `json:"aesKey" yaml:"aesKey" env:"ENC_AES_KEY" validate:"required,aeskey" secret:"true"`
+	} `json:"encryption" yaml:"encryption"`
+	OpenAPI struct {
+		ValidateRequests  bool `json:"validateRequests" yaml:"validateRequests" env:"OPENAPI_VALIDATE_REQUESTS" envDefault:"true"`
+		ValidateResponses bool `json:"validateResponses" yaml:"validateResponses" env:"OPENAPI_VALIDATE_RESPONSES" envDefault:"false"`
+	} `json:"openapi" yaml:"openapi"`
+	Tracing struct {
+		Exporter    string `json:"exporter" yaml:"exporter" env:"TRACING_EXPORTER" envDefault:"none" validate:"required,oneof=none otlp stdout"`
+		ServiceName string `json:"serviceName" yaml:"serviceName" env:"OTEL_SERVICE_NAME" envDefault:"telko-moment-server" validate:"required"`
+		SampleRatio string `json:"sampleRatio" yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO" envDefault:"1" validate:"required,ratio"`
+	} `json:"tracing" yaml:"tracing"`
+	Storage StorageConfig `json:"storage" yaml:"storage"`
+	Media   MediaConfig   `json:"media" yaml:"media"`
 	Hashing struct {
-		HMACSecretKey string `env:"HMAC_SECRET_KEY" envDefault:"0123456789abcdef"`
-	} `json:"hashing"`
+		HMACSecretKey string `json:"hmacSecretKey" yaml:"hmacSecretKey" env:"HMAC_SECRET_KEY" validate:"required,hexmin=32" secret:"true"`
+	} `json:"hashing" yaml:"hashing"`
 }
 
+// LoadConfig builds the configuration from, in increasing order of precedence,
+// the envDefault tags, the optional config file named by CONFIG_FILE (.yaml, .yml or .json)
+// and the environment (including a .env file in the working directory).
+// All validation errors are returned at once as ValidationErrors, with secret values redacted.
 func LoadConfig() (*Config, error) {
 	// Determine the root directory of the project
 	rootDir, err := os.Getwd()
@@ -49,22 +108,22 @@ func LoadConfig() (*Config, error) {
 
 	var config Config
 
-	// Load the ones left by -> envconfig.Process()
-	config.MongoDB.Database = os.Getenv("MONGODB_DB")
-	config.MongoDB.URI = os.Getenv("MONGODB_URI")
-
-	config.Server.Port = os.Getenv("SERVER_PORT")
+	// Defaults first, so the config file & environment only override what they set
+	parseErrs := applyDefaults(&config)
 
-	config.Jwt.Issuer = os.Getenv("JWT_ISSUER")
-	config.Jwt.Secret = os.Getenv("JWT_SECRET")
-	config.Jwt.TokenDuration = os.Getenv("JWT_TOKEN_DURATION")
-	config.Jwt.RefreshTokenSecret = os.Getenv("JWT_REFRESH_TOKEN_SECRET")
-	config.Jwt.RefreshTokenDuration = os.Getenv("JWT_REFRESH_TOKEN_DURATION")
-	config.Jwt.RefreshTokenDaysMultiplier = os.Getenv("JWT_REFRESH_TOKEN_DAYS_MULTIPLIER")
+	if configFile := os.Getenv("CONFIG_FILE"); configFile != "" {
+		if err = loadFile(configFile, &config); err != nil {
+			log.Error().Err(err).Str("file", configFile).Msg("Failed to load config file")
+			return nil, err
+		}
+		log.Info().Str("file", configFile).Msg("Loaded config file")
+	}
 
-	config.Encryption.AESKey = os.Getenv("ENC_AES_KEY")
+	parseErrs = append(parseErrs, applyEnv(&config)...)
 
-	config.Hashing.HMACSecretKey = os.Getenv("HMAC_SECRET_KEY")
+	if err = validate(&config, parseErrs); err != nil {
+		return nil, err
+	}
 
 	// Return the loaded configuration
 	return &config, nil
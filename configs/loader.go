@@ -0,0 +1,177 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// field is a leaf of the config struct that is bound to an environment variable
+type field struct {
+	path  string // dotted path, e.g. Jwt.Secret
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+// name identifies the field in errors, preferring the environment variable operators actually set
+func (f field) name() string {
+	if env := f.tag.Get("env"); env != "" {
+		return env
+	}
+	return f.path
+}
+
+func (f field) isSecret() bool {
+	return f.tag.Get("secret") == "true"
+}
+
+// display returns the field's current value, redacted for secrets
+func (f field) display() string {
+	if f.isSecret() {
+		return redacted
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// fields walks the config struct and returns every tagged leaf field
+func fields(v reflect.Value, prefix string) []field {
+	var result []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		path := sf.Name
+		if prefix != "" {
+			path = prefix + "." + sf.Name
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			result = append(result, fields(fv, path)...)
+			continue
+		}
+		result = append(result, field{path: path, value: fv, tag: sf.Tag})
+	}
+	return result
+}
+
+// applyDefaults sets every field that has an envDefault tag, defaults that do not convert are returned
+func applyDefaults(cfg *Config) ValidationErrors {
+	var errs ValidationErrors
+	for _, f := range fields(reflect.ValueOf(cfg).Elem(), "") {
+		if def, ok := f.tag.Lookup("envDefault"); ok {
+			if err := setValue(f.value, def); err != nil {
+				errs = append(errs, f.parseError(def, err))
+			}
+		}
+	}
+	return errs
+}
+
+// applyEnv overrides fields whose environment variable is set to a non-empty value, values that do not convert are
+// returned and leave the field as it was
+func applyEnv(cfg *Config) ValidationErrors {
+	var errs ValidationErrors
+	for _, f := range fields(reflect.ValueOf(cfg).Elem(), "") {
+		env := f.tag.Get("env")
+		if env == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			if err := setValue(f.value, value); err != nil {
+				errs = append(errs, f.parseError(value, err))
+			}
+		}
+	}
+	return errs
+}
+
+// parseError describes a raw value setValue rejected, the parser's error is not included as it quotes the value
+func (f field) parseError(raw string, err error) FieldError {
+	value := raw
+	if f.isSecret() {
+		value = redacted
+	}
+	var message string
+	switch {
+	case f.value.Type() == reflect.TypeOf(time.Duration(0)):
+		message = "must be a duration such as 30s, 1h or 24h"
+	case f.value.Kind() == reflect.Int || f.value.Kind() == reflect.Int64:
+		message = "must be an integer"
+	case f.value.Kind() == reflect.Bool:
+		message = "must be true or false"
+	default:
+		message = err.Error()
+	}
+	return FieldError{Field: f.name(), Value: value, Message: message}
+}
+
+// loadFile overlays a YAML or JSON config file onto cfg, keys missing from the file keep their current value
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file format %q, expected .yaml, .yml or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		// decoder errors can quote file content, which may hold secrets
+		return fmt.Errorf("failed to parse config file %s: invalid %s", path, strings.TrimPrefix(filepath.Ext(path), "."))
+	}
+	return nil
+}
+
+// setValue converts raw into the field's kind, the field is left unchanged when raw does not convert
+func setValue(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported config field kind %s", v.Kind())
+	}
+	return nil
+}
+
+// Redacted returns a flat view of the configuration, keyed by environment variable, that is safe to log
+func (c *Config) Redacted() map[string]string {
+	result := make(map[string]string)
+	for _, f := range fields(reflect.ValueOf(c).Elem(), "") {
+		result[f.name()] = f.display()
+	}
+	return result
+}
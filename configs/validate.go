@@ -0,0 +1,140 @@
+package configs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes a single invalid configuration value
+type FieldError struct {
+	Field   string // environment variable or dotted path
+	Value   string // redacted for secrets
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s=%q: %s", e.Field, e.Value, e.Message)
+}
+
+// ValidationErrors collects every invalid configuration value so operators can fix them in one go
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("invalid configuration (%d errors): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// validate checks every field against its validate tag, parseErrs are the values that did not convert to their
+// field's type and are reported alongside. Supported rules:
+//   - required: must not be empty, or zero for numbers
+//   - duration: parses with time.ParseDuration
+//   - int:      parses as a base 10 integer, for string fields (numeric fields are checked as they load)
+//   - sizes:    space separated positive integers
+//   - port:     integer between 1 and 65535
+//   - hexmin=N: hex encoded, decoding to at least N bytes
+//   - aeskey:   hex encoded, decoding to 16, 24 or 32 bytes
+//   - mongouri: mongodb:// or mongodb+srv:// URI
+//   - oneof=A B: one of the space separated values
+//   - ratio:    float between 0 and 1
+func validate(cfg *Config, parseErrs ValidationErrors) error {
+	errs := parseErrs
+	for _, f := range fields(reflect.ValueOf(cfg).Elem(), "") {
+		rules := f.tag.Get("validate")
+		if rules == "" {
+			continue
+		}
+		value := fmt.Sprint(f.value.Interface())
+		if f.value.IsZero() {
+			value = ""
+		}
+		for _, rule := range strings.Split(rules, ",") {
+			name, arg, _ := strings.Cut(rule, "=")
+			if value == "" && name != "required" {
+				// emptiness is only reported by required
+				continue
+			}
+			if msg := checkRule(name, arg, value); msg != "" {
+				errs = append(errs, FieldError{Field: f.name(), Value: f.display(), Message: msg})
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkRule returns an error message, or an empty string when value satisfies the rule
+func checkRule(name, arg, value string) string {
+	switch name {
+	case "required":
+		if value == "" {
+			return "is required"
+		}
+	case "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			return "must be a duration such as 30s, 1h or 24h"
+		}
+	case "int":
+		if _, err := strconv.Atoi(value); err != nil {
+			return "must be an integer"
+		}
+	case "sizes":
+		for _, field := range strings.Fields(value) {
+			if size, err := strconv.Atoi(field); err != nil || size < 1 {
+				return "must be space separated positive integers"
+			}
+		}
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return "must be a port number between 1 and 65535"
+		}
+	case "hexmin":
+		minBytes, _ := strconv.Atoi(arg)
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return "must be hex encoded"
+		}
+		if len(decoded) < minBytes {
+			return fmt.Sprintf("must decode to at least %d bytes, got %d", minBytes, len(decoded))
+		}
+	case "aeskey":
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return "must be hex encoded"
+		}
+		switch len(decoded) {
+		case 16, 24, 32:
+		default:
+			return fmt.Sprintf("must decode to 16, 24 or 32 bytes, got %d", len(decoded))
+		}
+	case "mongouri":
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "mongodb" && u.Scheme != "mongodb+srv") || u.Host == "" {
+			return "must be a mongodb:// or mongodb+srv:// URI"
+		}
+	case "oneof":
+		if !slices.Contains(strings.Fields(arg), value) {
+			return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(arg), ", "))
+		}
+	case "ratio":
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return "must be a number between 0 and 1"
+		}
+	default:
+		return fmt.Sprintf("unknown validation rule %q", name)
+	}
+	return ""
+}
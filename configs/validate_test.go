@@ -0,0 +1,107 @@
+package configs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckRule(t *testing.T) {
+	for _, tc := range []struct {
+		rule, value string
+		valid       bool
+	}{
+		{"required", "x", true},
+		{"required", "", false},
+		{"duration", "90s", true},
+		{"duration", "90", false},
+		{"int", "7", true},
+		{"int", "seven", false},
+		{"sizes", "96 320 960", true},
+		{"sizes", "96 0", false},
+		{"sizes", "96 large", false},
+		{"port", "8080", true},
+		{"port", "0", false},
+		{"port", "65536", false},
+		{"hexmin=4", "00010203", true},
+		{"hexmin=4", "000102", false},
+		{"hexmin=4", "zz010203", false},
+		{"aeskey", testAESKey, true},
+		{"aeskey", testSecret, true},
+		{"aeskey", "0001020304", false},
+		{"aeskey", "not hex", false},
+		{"mongouri", "mongodb://localhost:27017", true},
+		{"mongouri", "mongodb+srv://cluster.example.com", true},
+		{"mongouri", "http://localhost:27017", false},
+		{"mongouri", "mongodb://", false},
+		{"oneof=local s3", "s3", true},
+		{"oneof=local s3", "gcs", false},
+		{"ratio", "0.25", true},
+		{"ratio", "1.5", false},
+		{"ratio", "half", false},
+		{"unknown", "x", false},
+	} {
+		name, arg, _ := strings.Cut(tc.rule, "=")
+		msg := checkRule(name, arg, tc.value)
+		if (msg == "") != tc.valid {
+			t.Errorf("checkRule(%s, %q) = %q, want valid %v", tc.rule, tc.value, msg, tc.valid)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		setTestEnv(t)
+		cfg := &Config{}
+		if errs := applyDefaults(cfg); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		if errs := applyEnv(cfg); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		return cfg
+	}
+	if err := validate(valid(), nil); err != nil {
+		t.Fatalf("validate() of a valid config = %v", err)
+	}
+
+	// every invalid field is reported at once, a field stops at its first failing rule
+	cfg := valid()
+	cfg.Jwt.Secret = ""
+	cfg.Encryption.AESKey = "0001"
+	cfg.Storage.Backend = "gcs"
+	cfg.Tracing.SampleRatio = "2"
+	cfg.Storage.LocalPath = ""
+	parseErr := FieldError{Field: "MEDIA_MAX_UPLOAD_BYTES", Value: "lots", Message: "must be an integer"}
+	err := validate(cfg, ValidationErrors{parseErr})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("validate() = %v, want ValidationErrors", err)
+	}
+	got := map[string]string{}
+	for _, fe := range errs {
+		got[fe.Field] = fe.Message
+	}
+	want := map[string]string{
+		"MEDIA_MAX_UPLOAD_BYTES": "must be an integer",
+		"JWT_SECRET":             "is required",
+		"ENC_AES_KEY":            "must decode to 16, 24 or 32 bytes, got 2",
+		"STORAGE_BACKEND":        "must be one of local, s3",
+		"TRACING_SAMPLE_RATIO":   "must be a number between 0 and 1",
+		"STORAGE_LOCAL_PATH":     "is required",
+	}
+	if len(got) != len(want) || len(errs) != len(want) {
+		t.Fatalf("errors = %v, want %v", got, want)
+	}
+	for field, msg := range want {
+		if got[field] != msg {
+			t.Errorf("%s: %q, want %q", field, got[field], msg)
+		}
+	}
+	if strings.Contains(err.Error(), "0001") {
+		t.Errorf("error leaks the AES key: %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid configuration (6 errors): ") {
+		t.Errorf("error = %q", err.Error())
+	}
+}
@@ -0,0 +1,502 @@
+package controllers
+
+import (
+	"github.com/mcsamuelshoko/telko-moment-server/api"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Mapping between the persistence models & the generated API types used by the strict server handlers.
+
+// ptr returns a pointer to v, the generated types model optional fields as pointers
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// optionalString omits empty strings from responses
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optionalEmail omits empty emails, openapi_types.Email refuses to marshal an invalid (empty) address
+func optionalEmail(s string) *openapi_types.Email {
+	if s == "" {
+		return nil
+	}
+	email := openapi_types.Email(s)
+	return &email
+}
+
+// optionalInt omits zero numbers from responses
+func optionalInt[T ~int | ~int64](v T) *T {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+// optionalTime omits zero times from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// optionalObjectID omits unset object ids from responses
+func optionalObjectID(id primitive.ObjectID) *string {
+	if id.IsZero() {
+		return nil
+	}
+	return ptr(id.Hex())
+}
+
+// objectIDsFromHex parses the ids of a request, naming field in the validation error
+func objectIDsFromHex(field string, ids []string) ([]primitive.ObjectID, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid id", apperrors.InvalidField(field, "not a valid id")).WithErr(err)
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	return objectIDs, nil
+}
+
+// :::: USERS
+
+// toAPIUser is the sanitized user, the password hash is never returned
+func toAPIUser(u *models.User) api.User {
+	return api.User{
+		Bio:                optionalString(u.Bio),
+		Country:            optionalString(u.Country),
+		CreatedAt:          optionalTime(u.CreatedAt),
+		Email:              optionalEmail(u.Email),
+		FirstName:          optionalString(u.FirstName),
+		Id:                 optionalObjectID(u.ID),
+		LanguagePreference: optionalString(u.LanguagePreference),
+		LastName:           optionalString(u.LastName),
+		PhoneNumber:        optionalString(u.PhoneNumber),
+		ProfilePicture:     optionalString(u.ProfilePicture),
+		Status:             optionalString(u.Status),
+		Timezone:           optionalString(u.Timezone),
+		UpdatedAt:          optionalTime(u.UpdatedAt),
+		UserType:           optionalString(u.UserType),
+		Username:           optionalString(u.Username),
+	}
+}
+
+func toAPIUsers(users []models.User) []api.User {
+	apiUsers := make([]api.User, 0, len(users))
+	for i := range users {
+		apiUsers = append(apiUsers, toAPIUser(&users[i]))
+	}
+	return apiUsers
+}
+
+// userFromCreateRequest builds the user to create, the password is hashed by the caller
+func userFromCreateRequest(body *api.UserCreateRequest) *models.User {
+	user := &models.User{
+		FirstName: body.FirstName,
+		LastName:  body.LastName,
+		Username:  body.Username,
+		Password:  body.Password,
+		Email:     string(body.Email),
+		UserType:  body.UserType,
+	}
+	applyOptional(&user.PhoneNumber, body.PhoneNumber)
+	applyOptional(&user.ProfilePicture, body.ProfilePicture)
+	applyOptional(&user.Status, body.Status)
+	applyOptional(&user.Bio, body.Bio)
+	applyOptional(&user.LanguagePreference, body.LanguagePreference)
+	applyOptional(&user.Timezone, body.Timezone)
+	applyOptional(&user.Country, body.Country)
+	return user
+}
+
+// applyUserUpdate applies the fields present in the update onto user, a new password is hashed by the caller
+func applyUserUpdate(user *models.User, body *api.UserUpdateRequest) {
+	applyOptional(&user.FirstName, body.FirstName)
+	applyOptional(&user.LastName, body.LastName)
+	applyOptional(&user.Username, body.Username)
+	if body.Email != nil {
+		user.Email = string(*body.Email)
+	}
+	applyOptional(&user.PhoneNumber, body.PhoneNumber)
+	applyOptional(&user.UserType, body.UserType)
+	applyOptional(&user.ProfilePicture, body.ProfilePicture)
+	applyOptional(&user.Status, body.Status)
+	applyOptional(&user.Bio, body.Bio)
+	applyOptional(&user.LanguagePreference, body.LanguagePreference)
+	applyOptional(&user.Timezone, body.Timezone)
+	applyOptional(&user.Country, body.Country)
+}
+
+// applyOptional overwrites dst when the request carries the field
+func applyOptional[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// :::: SETTINGS
+
+func toAPISettings(s *models.Settings) api.Settings {
+	p := s.Preferences
+	return api.Settings{
+		CreatedAt: optionalTime(s.CreatedAt),
+		Id:        optionalObjectID(s.ID),
+		Preferences: &api.UserPreferences{
+			Accessibility: &api.UserPreferencesAccessibility{
+				HighContrast: ptr(p.Accessibility.HighContrast),
+				TextToSpeech: ptr(p.Accessibility.TextToSpeech),
+			},
+			AutoDownloadMedia:    ptr(p.AutoDownloadMedia),
+			ChatWallpaper:        ptr(p.ChatWallpaper),
+			EmojiStyle:           ptr(p.EmojiStyle),
+			FontSize:             ptr(p.FontSize),
+			HighlightVisibility:  ptr(p.HighlightVisibility),
+			Language:             ptr(p.Language),
+			LastActiveVisibility: ptr(p.LastActiveVisibility),
+			Notifications:        ptr(p.Notifications),
+			Privacy: &api.UserPreferencesPrivacy{
+				AddToGroups:              ptr(p.Privacy.AddToGroups),
+				PhoneNumberVisibility:    ptr(p.Privacy.PhoneNumberVisibility),
+				ProfilePictureVisibility: ptr(p.Privacy.ProfilePictureVisibility),
+			},
+			ReadReceipts: ptr(p.ReadReceipts),
+			ShowPreviews: ptr(p.ShowPreviews),
+			Sound:        ptr(p.Sound),
+			Theme:        ptr(p.Theme),
+			Vibration:    ptr(p.Vibration),
+		},
+		UpdatedAt: optionalTime(s.UpdatedAt),
+		UserId:    optionalObjectID(s.UserId),
+	}
+}
+
+// applySettingsUpdate applies the preferences present in the update, ids & timestamps stay server owned
+func applySettingsUpdate(settings *models.Settings, body *api.Settings) {
+	if body.Preferences == nil {
+		return
+	}
+	p, dst := body.Preferences, &settings.Preferences
+	applyOptional(&dst.Theme, p.Theme)
+	applyOptional(&dst.Notifications, p.Notifications)
+	applyOptional(&dst.Sound, p.Sound)
+	applyOptional(&dst.Vibration, p.Vibration)
+	applyOptional(&dst.FontSize, p.FontSize)
+	applyOptional(&dst.Language, p.Language)
+	applyOptional(&dst.ShowPreviews, p.ShowPreviews)
+	applyOptional(&dst.AutoDownloadMedia, p.AutoDownloadMedia)
+	applyOptional(&dst.ReadReceipts, p.ReadReceipts)
+	applyOptional(&dst.LastActiveVisibility, p.LastActiveVisibility)
+	applyOptional(&dst.HighlightVisibility, p.HighlightVisibility)
+	applyOptional(&dst.ChatWallpaper, p.ChatWallpaper)
+	applyOptional(&dst.EmojiStyle, p.EmojiStyle)
+	if p.Accessibility != nil {
+		applyOptional(&dst.Accessibility.HighContrast, p.Accessibility.HighContrast)
+		applyOptional(&dst.Accessibility.TextToSpeech, p.Accessibility.TextToSpeech)
+	}
+	if p.Privacy != nil {
+		applyOptional(&dst.Privacy.ProfilePictureVisibility, p.Privacy.ProfilePictureVisibility)
+		applyOptional(&dst.Privacy.PhoneNumberVisibility, p.Privacy.PhoneNumberVisibility)
+		applyOptional(&dst.Privacy.AddToGroups, p.Privacy.AddToGroups)
+	}
+}
+
+// :::: MESSAGES
+
+func toAPIMessage(m *models.Message) api.Message {
+	message := api.Message{
+		ChatId:             optionalObjectID(m.ChatID),
+		Content:            optionalString(m.Content),
+		EditedMessage:      ptr(m.EditedMessage),
+		Id:                 optionalObjectID(m.ID),
+		MessageType:        optionalString(m.MessageType),
+		RepliedToMessageId: optionalObjectID(m.RepliedToMessageID),
+		SenderDeviceId:     optionalString(m.SenderDeviceID),
+		SenderId:           optionalObjectID(m.SenderID),
+		Status:             optionalString(m.Status),
+	}
+	if m.Timestamp != 0 {
+		message.Timestamp = ptr(m.Timestamp.Time())
+	}
+	if m.EditedTimestamp != 0 {
+		message.EditedTimestamp = ptr(m.EditedTimestamp.Time())
+	}
+	if len(m.MediaIDs) > 0 {
+		mediaIds := make([]string, 0, len(m.MediaIDs))
+		for _, id := range m.MediaIDs {
+			mediaIds = append(mediaIds, id.Hex())
+		}
+		message.MediaIds = &mediaIds
+	}
+	if len(m.MediaUrls) > 0 {
+		message.MediaUrls = ptr(m.MediaUrls)
+	}
+	if len(m.Mentions) > 0 {
+		mentions := make([]string, 0, len(m.Mentions))
+		for _, mention := range m.Mentions {
+			mentions = append(mentions, mention.Hex())
+		}
+		message.Mentions = &mentions
+	}
+	if m.VoiceNote != nil {
+		message.VoiceNote = &api.VoiceNote{DurationMs: optionalInt(m.VoiceNote.DurationMs)}
+		if len(m.VoiceNote.Waveform) > 0 {
+			message.VoiceNote.Waveform = ptr(m.VoiceNote.Waveform)
+		}
+	}
+	if len(m.Ciphertexts) > 0 {
+		ciphertexts := make([]api.DeviceCiphertext, 0, len(m.Ciphertexts))
+		for _, ct := range m.Ciphertexts {
+			ciphertexts = append(ciphertexts, api.DeviceCiphertext{
+				Body:        ct.Body,
+				DeviceId:    ct.DeviceID,
+				RecipientId: ct.RecipientID.Hex(),
+				Type:        ct.Type,
+			})
+		}
+		message.Ciphertexts = &ciphertexts
+	}
+	return message
+}
+
+func toAPIPlayedReceipt(r *models.PlayedReceipt) api.PlayedReceipt {
+	return api.PlayedReceipt{PlayedAt: r.PlayedAt, UserId: r.UserID.Hex()}
+}
+
+// messageFromCreateRequest parses the ids of the request into the message senderID sends
+func messageFromCreateRequest(senderID primitive.ObjectID, body *api.MessageCreateRequest) (*models.Message, error) {
+	ids, err := objectIDsFromHex("chatId", []string{body.ChatId})
+	if err != nil {
+		return nil, err
+	}
+	message := &models.Message{ChatID: ids[0], SenderID: senderID, MessageType: string(body.MessageType)}
+
+	applyOptional(&message.Content, body.Content)
+	applyOptional(&message.MediaUrls, body.MediaUrls)
+	applyOptional(&message.SenderDeviceID, body.SenderDeviceId)
+	if body.MediaIds != nil {
+		if message.MediaIDs, err = objectIDsFromHex("mediaIds", *body.MediaIds); err != nil {
+			return nil, err
+		}
+	}
+	if body.Mentions != nil {
+		if message.Mentions, err = objectIDsFromHex("mentions", *body.Mentions); err != nil {
+			return nil, err
+		}
+	}
+	if body.RepliedToMessageId != nil {
+		if ids, err = objectIDsFromHex("repliedToMessageId", []string{*body.RepliedToMessageId}); err != nil {
+			return nil, err
+		}
+		message.RepliedToMessageID = ids[0]
+	}
+	if body.Ciphertexts != nil {
+		for _, ct := range *body.Ciphertexts {
+			if ids, err = objectIDsFromHex("ciphertexts.recipientId", []string{ct.RecipientId}); err != nil {
+				return nil, err
+			}
+			message.Ciphertexts = append(message.Ciphertexts, models.DeviceCiphertext{
+				RecipientID: ids[0],
+				DeviceID:    ct.DeviceId,
+				Type:        ct.Type,
+				Body:        ct.Body,
+			})
+		}
+	}
+	return message, nil
+}
+
+// applyMessageUpdate applies the fields present in the update onto message
+func applyMessageUpdate(message *models.Message, body *api.MessageUpdateRequest) error {
+	if body.Content != nil && *body.Content != message.Content {
+		message.Content = *body.Content
+		message.EditedMessage = true
+		message.EditedTimestamp = primitive.NewDateTimeFromTime(time.Now())
+	}
+	applyOptional(&message.MediaUrls, body.MediaUrls)
+	applyOptional(&message.Status, body.Status)
+	if body.Mentions != nil {
+		mentions, err := objectIDsFromHex("mentions", *body.Mentions)
+		if err != nil {
+			return err
+		}
+		message.Mentions = mentions
+	}
+	if body.RepliedToMessageId != nil {
+		ids, err := objectIDsFromHex("repliedToMessageId", []string{*body.RepliedToMessageId})
+		if err != nil {
+			return err
+		}
+		message.RepliedToMessageID = ids[0]
+	}
+	return nil
+}
+
+// toAPIMedia maps media metadata, the storage key stays internal
+func toAPIMedia(m *models.Media) api.Media {
+	media := api.Media{
+		ChatId:            optionalObjectID(m.ChatId),
+		ContentType:       optionalString(m.ContentType),
+		FileName:          optionalString(m.FileName),
+		FileSize:          ptr(m.FileSize),
+		Id:                optionalObjectID(m.Id),
+		MessageId:         optionalObjectID(m.MessageId),
+		MediaType:         optionalString(m.MediaType),
+		MediaUrl:          optionalString(m.MediaUrl),
+		MediaUrlExpiresAt: optionalTime(m.MediaUrlExpiresAt),
+		SenderId:          optionalObjectID(m.SenderId),
+		Width:             optionalInt(m.Width),
+		Height:            optionalInt(m.Height),
+		DurationMs:        optionalInt(m.DurationMs),
+		Blurhash:          optionalString(m.Blurhash),
+	}
+	if m.UploadTimestamp != 0 {
+		media.UploadTimestamp = ptr(m.UploadTimestamp.Time())
+	}
+	if m.ScanStatus != "" {
+		media.ScanStatus = ptr(api.MediaScanStatus(m.ScanStatus))
+	}
+	if len(m.Waveform) > 0 {
+		media.Waveform = ptr(m.Waveform)
+	}
+	if len(m.Thumbnails) > 0 {
+		thumbnails := make([]api.MediaThumbnail, 0, len(m.Thumbnails))
+		for _, t := range m.Thumbnails {
+			thumbnails = append(thumbnails, api.MediaThumbnail{
+				ContentType: t.ContentType,
+				FileSize:    t.FileSize,
+				Height:      t.Height,
+				Size:        t.Size,
+				Url:         t.Url,
+				Width:       t.Width,
+			})
+		}
+		media.Thumbnails = &thumbnails
+	}
+	return media
+}
+
+func toAPIChatGallery(g *services.ChatGallery) api.ChatGallery {
+	gallery := api.ChatGallery{Items: make([]api.GalleryItem, 0, len(g.Items)), NextCursor: optionalString(g.NextCursor)}
+	for _, item := range g.Items {
+		apiItem := api.GalleryItem{MessageId: item.MessageID.Hex(), SentAt: item.SentAt}
+		if item.Media != nil {
+			apiItem.Media = ptr(toAPIMedia(item.Media))
+		}
+		if len(item.Links) > 0 {
+			apiItem.Links = ptr(item.Links)
+		}
+		gallery.Items = append(gallery.Items, apiItem)
+	}
+	return gallery
+}
+
+func toAPIStorageUsage(r *services.StorageReport) api.StorageUsage {
+	usage := api.StorageUsage{
+		ByChat:       make([]api.StorageUsageGroup, 0, len(r.ByChat)),
+		ByMediaType:  make([]api.StorageUsageGroup, 0, len(r.ByMediaType)),
+		EnterpriseId: optionalObjectID(r.EnterpriseID),
+		PlanType:     api.StorageUsagePlanType(r.PlanType),
+		QuotaBytes:   r.QuotaBytes,
+		UsedBytes:    r.UsedBytes,
+		UserBytes:    r.UserBytes,
+	}
+	for _, group := range r.ByChat {
+		usage.ByChat = append(usage.ByChat, api.StorageUsageGroup{ChatId: optionalObjectID(group.ChatId), Bytes: group.Bytes, Count: group.Count})
+	}
+	for _, group := range r.ByMediaType {
+		usage.ByMediaType = append(usage.ByMediaType, api.StorageUsageGroup{MediaType: optionalString(group.MediaType), Bytes: group.Bytes, Count: group.Count})
+	}
+	return usage
+}
+
+func toAPIUpload(u *models.Upload) api.Upload {
+	return api.Upload{
+		ChatId:      optionalObjectID(u.ChatID),
+		ContentType: optionalString(u.ContentType),
+		ExpiresAt:   ptr(u.ExpiresAt),
+		FileName:    optionalString(u.FileName),
+		Id:          optionalObjectID(u.ID),
+		Length:      ptr(u.Length),
+		MediaId:     optionalObjectID(u.MediaID),
+		MediaType:   optionalString(u.MediaType),
+		Offset:      ptr(u.Offset),
+		Status:      ptr(api.UploadStatus(u.Status)),
+	}
+}
+
+func uploadFromCreateRequest(body *api.UploadCreateRequest, senderID string) *services.ResumableUpload {
+	upload := &services.ResumableUpload{ChatID: body.ChatId, SenderID: senderID, Length: body.Length}
+	applyOptional(&upload.MediaType, body.MediaType)
+	applyOptional(&upload.FileName, body.FileName)
+	return upload
+}
+
+func publishKeysFromRequest(body *api.PublishKeysRequest) *models.PublishKeysRequest {
+	req := &models.PublishKeysRequest{
+		IdentityKey: body.IdentityKey,
+		SignedPreKey: models.SignedPreKey{
+			KeyID:     body.SignedPreKey.KeyId,
+			PublicKey: body.SignedPreKey.PublicKey,
+			Signature: body.SignedPreKey.Signature,
+		},
+	}
+	if body.OneTimePreKeys != nil {
+		req.OneTimePreKeys = oneTimePreKeysFromAPI(*body.OneTimePreKeys)
+	}
+	return req
+}
+
+func oneTimePreKeysFromAPI(preKeys []api.OneTimePreKey) []models.OneTimePreKey {
+	result := make([]models.OneTimePreKey, 0, len(preKeys))
+	for _, preKey := range preKeys {
+		result = append(result, models.OneTimePreKey{KeyID: preKey.KeyId, PublicKey: preKey.PublicKey})
+	}
+	return result
+}
+
+func toAPIPreKeyCount(c *models.PreKeyCountResponse) api.PreKeyCount {
+	return api.PreKeyCount{DeviceId: c.DeviceID, Remaining: c.Remaining, Replenish: c.Replenish}
+}
+
+func toAPIPreKeyBundle(b *models.PreKeyBundle) api.PreKeyBundle {
+	bundle := api.PreKeyBundle{
+		DeviceId:    b.DeviceID,
+		IdentityKey: b.IdentityKey,
+		SignedPreKey: api.SignedPreKey{
+			KeyId:     b.SignedPreKey.KeyID,
+			PublicKey: b.SignedPreKey.PublicKey,
+			Signature: b.SignedPreKey.Signature,
+		},
+		UserId: b.UserID.Hex(),
+	}
+	if b.OneTimePreKey != nil {
+		bundle.OneTimePreKey = &api.OneTimePreKey{KeyId: b.OneTimePreKey.KeyID, PublicKey: b.OneTimePreKey.PublicKey}
+	}
+	return bundle
+}
+
+func toAPIChatActivity(a *models.ChatActivity) api.ChatActivity {
+	return api.ChatActivity{
+		Activity:  api.ChatActivityActivity(a.Activity),
+		ChatId:    a.ChatID.Hex(),
+		ExpiresAt: a.ExpiresAt,
+		UserId:    a.UserID.Hex(),
+	}
+}
+
+func toAPIPresence(p *models.Presence) api.Presence {
+	return api.Presence{LastSeenAt: p.LastSeenAt, Online: p.Online, UserId: p.UserID.Hex()}
+}
@@ -1,11 +1,16 @@
 package controllers
 
 import (
+	"context"
 	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
 	pkgservices "github.com/mcsamuelshoko/telko-moment-server/pkg/services"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
 
@@ -14,36 +19,45 @@ import (
 
 type IAuthenticationController interface {
 	CreateRefreshToken(c *fiber.Ctx) error
-	UpdateRefreshToken(c *fiber.Ctx) error
+	// UpdateRefreshToken Exchanges a refresh token for new tokens
+	// (POST /auth/refresh-token)
+	UpdateRefreshToken(ctx context.Context, request api.AuthRefreshTokenRequestObject) (api.AuthRefreshTokenResponseObject, error)
 	CancelRefreshToken(c *fiber.Ctx) error
 
-	Login(c *fiber.Ctx) error
-	Register(c *fiber.Ctx) error
-	Logout(c *fiber.Ctx) error
+	// Login Logs in and returns JWT-auth-token
+	// (POST /auth/login)
+	Login(ctx context.Context, request api.AuthLoginRequestObject) (api.AuthLoginResponseObject, error)
+	// Register Registers and returns user information
+	// (POST /auth/register)
+	Register(ctx context.Context, request api.AuthRegisterRequestObject) (api.AuthRegisterResponseObject, error)
+	// Logout Logs out by revoking a refresh token
+	// (POST /auth/logout)
+	Logout(ctx context.Context, request api.AuthLogoutRequestObject) (api.AuthLogoutResponseObject, error)
 }
 
 type AuthenticationController struct {
-	iName           string
-	authService     services.IAuthenticationService
-	log             *zerolog.Logger
-	userService     services.IUserService
-	settingsService services.ISettingsService
-	jwtService      pkgservices.IJWTService
+	iName               string
+	authService         services.IAuthenticationService
+	log                 *zerolog.Logger
+	userService         services.IUserService
+	registrationService services.IRegistrationService
+	jwtService          pkgservices.IJWTService
 }
 
-func NewAuthController(log *zerolog.Logger, userSvc services.IUserService, authSvc services.IAuthenticationService, settingsSvc services.ISettingsService, jwtSvc pkgservices.IJWTService) IAuthenticationController {
+func NewAuthController(log *zerolog.Logger, userSvc services.IUserService, authSvc services.IAuthenticationService, registrationSvc services.IRegistrationService, jwtSvc pkgservices.IJWTService) IAuthenticationController {
 	return &AuthenticationController{
-		iName:           "AuthenticationController",
-		log:             log,
-		authService:     authSvc,
-		settingsService: settingsSvc,
-		userService:     userSvc,
-		jwtService:      jwtSvc,
+		iName:               "AuthenticationController",
+		log:                 log,
+		authService:         authSvc,
+		registrationService: registrationSvc,
+		userService:         userSvc,
+		jwtService:          jwtSvc,
 	}
 }
 
 func (a *AuthenticationController) CreateRefreshToken(c *fiber.Ctx) error {
 	const kName = "CreateRefreshToken"
+	logger := logging.FromContext(c.UserContext(), a.log)
 
 	user := c.Locals("user").(*jwt.Token) // Get the user from the context (assuming you have middleware)
 	claims := user.Claims.(jwt.MapClaims)
@@ -52,90 +66,92 @@ func (a *AuthenticationController) CreateRefreshToken(c *fiber.Ctx) error {
 	refreshToken, err := a.jwtService.GenerateRefreshToken(userID) // Generate a refresh token using your utility function
 	if err != nil {
 		msg := "Failed to generate refresh token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": msg})
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return apperrors.Wrap(err, msg)
 	}
 
 	// Store the refresh token in the database, associating it with the user.
-	err = a.authService.SaveRefreshToken(c.Context(), userID, refreshToken, a.jwtService.GetRefreshTokenDuration()) // Implement this function in your database layer.
+	err = a.authService.SaveRefreshToken(c.UserContext(), userID, refreshToken, a.jwtService.GetRefreshTokenDuration()) // Implement this function in your database layer.
 	if err != nil {
 		msg := "Failed to save refresh token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return apperrors.Wrap(err, msg)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse(fiber.Map{"refreshToken": refreshToken}, "Created Refresh Token"))
 }
 
-func (a *AuthenticationController) UpdateRefreshToken(c *fiber.Ctx) error {
+func (a *AuthenticationController) UpdateRefreshToken(ctx context.Context, request api.AuthRefreshTokenRequestObject) (api.AuthRefreshTokenResponseObject, error) {
+	logger := logging.FromContext(ctx, a.log)
 	// used by logger
 	const kName = "UpdateRefreshToken"
 
-	updateRequest := new(models.UpdateTokenRequest)
-	err := c.BodyParser(updateRequest)
+	refreshToken := request.Body.RefreshToken
+	userID, err := a.authService.GetUserIDFromRefreshToken(ctx, refreshToken)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to parse update-token request")
-	}
-
-	userID, err := a.authService.GetUserIDFromRefreshToken(c.Context(), updateRequest.RefreshToken) // Implement this function in your database layer.
-	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Invalid or expired refresh token, could not get userId from token")
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid or expired refresh token"))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Invalid or expired refresh token, could not get userId from token")
+		return nil, apperrors.Wrap(err, "Invalid or expired refresh token")
 	}
 
 	// Verify the refresh token's validity (e.g., check expiration, signature).
-	if !a.jwtService.VerifyRefreshToken(updateRequest.RefreshToken) {
-		a.log.Error().Interface(kName, a.iName).Msg("Invalid refresh token")
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid refresh token"))
+	if !a.jwtService.VerifyRefreshToken(refreshToken) {
+		logger.Error().Interface(kName, a.iName).Msg("Invalid refresh token")
+		return nil, apperrors.Unauthorized(apperrors.CodeInvalidRefreshToken, "Invalid refresh token")
 	}
 
 	// Generate a new access token.
-	accessToken, err := a.jwtService.GenerateAccessToken(userID) // Generate an access token using your utility function
+	accessToken, err := a.jwtService.GenerateAccessToken(userID)
 	if err != nil {
 		msg := "Failed to generate access token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return nil, apperrors.Wrap(err, msg)
 	}
 
 	// Optionally, generate a new refresh token.
 	newRefreshToken, err := a.jwtService.GenerateRefreshToken(userID)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("failed to generate new refresh token")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to generate new refresh token"))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("failed to generate new refresh token")
+		return nil, apperrors.Wrap(err, "Failed to generate new refresh token")
 	}
 
 	// Update by replacing the old refresh token with the new one in the database.
-	err = a.authService.UpdateUserRefreshToken(c.Context(), userID, newRefreshToken, a.jwtService.GetRefreshTokenDuration())
+	err = a.authService.UpdateUserRefreshToken(ctx, userID, newRefreshToken, a.jwtService.GetRefreshTokenDuration())
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("failed to save new refresh token")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to save new refresh token"))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("failed to save new refresh token")
+		return nil, apperrors.Wrap(err, "Failed to save new refresh token")
 	}
 
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(fiber.Map{"accessToken": accessToken, "refreshToken": newRefreshToken}, "Token refreshed"))
+	return api.AuthRefreshToken200JSONResponse{AuthLoginSuccessJSONResponse: api.AuthLoginSuccessJSONResponse{
+		Data:    &api.AuthLogin{AccessToken: &accessToken, RefreshToken: &newRefreshToken},
+		Message: ptr("Token refreshed"),
+		Success: ptr(true),
+	}}, nil
 }
 
 func (a *AuthenticationController) CancelRefreshToken(c *fiber.Ctx) error {
+	logger := logging.FromContext(c.UserContext(), a.log)
 	// used by logger
 	const kName = "CancelRefreshToken"
 
 	logoutRequest := new(models.LogoutRequest)
 	err := c.BodyParser(logoutRequest)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to parse logout request")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to parse logout request")
 	}
 
 	// Delete the refresh token from the database.
-	err = a.authService.RevokeRefreshToken(c.Context(), logoutRequest.RefreshToken) // Implement this function in your database layer.
+	err = a.authService.RevokeRefreshToken(c.UserContext(), logoutRequest.RefreshToken) // Implement this function in your database layer.
 	if err != nil {
 		msg := "Failed to cancel refresh token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return apperrors.Wrap(err, msg)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(nil, "Token cancelled"))
 }
 
-func (a *AuthenticationController) Login(c *fiber.Ctx) error {
+func (a *AuthenticationController) Login(ctx context.Context, request api.AuthLoginRequestObject) (api.AuthLoginResponseObject, error) {
+	logger := logging.FromContext(ctx, a.log)
 	//loginRequest := new(models.LoginRequestUsername)
 	//if err := c.BodyParser(loginRequest); err != nil {
 	//	a.log.Error().Err(err).Msg("Failed to parse login request")
@@ -145,269 +161,234 @@ func (a *AuthenticationController) Login(c *fiber.Ctx) error {
 	//user, err := a.userService.GetUserByUsername(c.Context(), loginRequest.Username)
 	//if err != nil {
 	//	a.log.Error().Err(err).Str("username", loginRequest.Username).Msg("User not found")
-	//	return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid credentials"))
+	//	return apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid credentials")
 	//}
 	//
 	//if !utils.CheckPasswordHash(loginRequest.Password, user.Password) {
 	//	a.log.Error().Str("username", loginRequest.Username).Msg("Invalid password")
-	//	return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid credentials"))
+	//	return apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid credentials")
 	//}
 
 	const kName = "Login"
 
-	loginRequest := new(models.LoginRequestEmail)
-	if err := c.BodyParser(loginRequest); err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to parse login request")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Invalid request body"))
+	loginRequest := request.Body
+	var user *models.User
+	var err error
+	switch {
+	case loginRequest.Email != nil:
+		user, err = a.userService.GetUserByEmail(ctx, string(*loginRequest.Email))
+	case loginRequest.PhoneNumber != nil:
+		user, err = a.userService.GetUserByPhoneNumber(ctx, *loginRequest.PhoneNumber)
+	default:
+		logger.Error().Interface(kName, a.iName).Msg("Login request has neither an email nor a phone number")
+		return nil, apperrors.Validation(apperrors.CodeInvalidBody, "An email or a phone number is required",
+			apperrors.RequiredField("email"), apperrors.RequiredField("phoneNumber"))
 	}
-
-	user, err := a.userService.GetUserByEmail(c.Context(), loginRequest.Email)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Str("email", loginRequest.Email).Msg("User not found")
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid credentials"))
+		metrics.Logins.WithLabelValues(metrics.ResultFailure).Inc()
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to look up user")
+			return nil, apperrors.Wrap(err, "Failed to login")
+		}
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("User not found")
+		return nil, apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid credentials")
 	}
 
 	if !utils.CheckPasswordHash(loginRequest.Password, user.Password) {
-		a.log.Error().Interface(kName, a.iName).Str("email", loginRequest.Email).Msg("Invalid password")
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid credentials"))
+		logger.Error().Interface(kName, a.iName).Str("userId", user.ID.Hex()).Msg("Invalid password")
+		metrics.Logins.WithLabelValues(metrics.ResultFailure).Inc()
+		return nil, apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid credentials")
+	}
+	if !user.IsActive() {
+		logger.Warn().Interface(kName, a.iName).Str("userId", user.ID.Hex()).Str("status", user.Status).Msg("Disabled user tried to login")
+		metrics.Logins.WithLabelValues(metrics.ResultFailure).Inc()
+		return nil, apperrors.Forbidden(apperrors.CodeUserDisabled, "User is disabled")
 	}
 
 	accessToken, err := a.jwtService.GenerateAccessToken(user.ID.Hex())
 	if err != nil {
 		msg := "Failed to generate access token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return nil, apperrors.Wrap(err, msg)
 	}
 
 	refreshToken, err := a.jwtService.GenerateRefreshToken(user.ID.Hex())
 	if err != nil {
 		msg := "Failed to generate refresh token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return nil, apperrors.Wrap(err, msg)
 	}
 
-	err = a.authService.SaveRefreshToken(c.Context(), user.ID.Hex(), refreshToken, a.jwtService.GetRefreshTokenDuration())
+	err = a.authService.SaveRefreshToken(ctx, user.ID.Hex(), refreshToken, a.jwtService.GetRefreshTokenDuration())
 	if err != nil {
 		msg := "Failed to save refresh token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return nil, apperrors.Wrap(err, msg)
 	}
-	var data map[string]interface{} = make(map[string]interface{})
-	data["accessToken"] = accessToken
-	data["refreshToken"] = refreshToken
 
 	msg := "Login successful"
+	metrics.Logins.WithLabelValues(metrics.ResultSuccess).Inc()
 
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(data, msg))
+	return api.AuthLogin200JSONResponse{AuthLoginSuccessJSONResponse: api.AuthLoginSuccessJSONResponse{
+		Data:    &api.AuthLogin{AccessToken: &accessToken, RefreshToken: &refreshToken},
+		Message: &msg,
+		Success: ptr(true),
+	}}, nil
 }
 
-func (a *AuthenticationController) Register(c *fiber.Ctx) error {
+func (a *AuthenticationController) Register(ctx context.Context, request api.AuthRegisterRequestObject) (api.AuthRegisterResponseObject, error) {
 	const kName = "Register"
+	logger := logging.FromContext(ctx, a.log)
 
-	// get data from dto
-	registerRequest := new(models.RegisterRequest)
-	emailRegisterRequest := new(models.RegisterRequestEmail)
-
+	registerRequest := request.Body
 	failedRegErrMsg := "Failed to register user"
 
-	err1 := c.BodyParser(registerRequest)
-	if err1 != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err1).Msg("Failed to parse register request")
-	}
-	err2 := c.BodyParser(emailRegisterRequest)
-	if err2 != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err2).Msg("Failed to parse register request")
-	}
-	if err1 != nil && err2 != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err1).Err(err2).Msg("Failed to identify register request type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Invalid request body"))
-	}
-
 	// user defaults
-	user := models.GetUserDefaultsFromHeaders(utils.GetHeaderMap(c))
+	user := models.GetUserDefaultsFromHeaders(utils.HeaderMapFromContext(ctx))
+	var createdUser *models.User
 	var err error
-	var regUserResponse fiber.Map
-	var responseStatus int
 
-	//register user using email
-	regUserResponse, err, responseStatus = a.registerUsingEmail(c, *emailRegisterRequest, err2, user, failedRegErrMsg)
-	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to register email-registration user")
-		return c.Status(responseStatus).JSON(utils.ErrorResponse(err.Error()))
-	}
-	if regUserResponse != nil {
-		a.log.Info().Interface(kName, a.iName).Msg("User registered successfully with Email")
-		return c.Status(fiber.StatusCreated).JSON(regUserResponse)
-	}
-	//register user using phoneNumber
-	regUserResponse, err, responseStatus = a.registrationUsingPhoneNumber(c, *registerRequest, err1, user, failedRegErrMsg)
-	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to register phoneNumber-registration user")
-		return c.Status(responseStatus).JSON(utils.ErrorResponse(err.Error()))
-	}
-	if regUserResponse != nil {
-		a.log.Info().Msg("User registered successfully with Phone Number")
-		return c.Status(fiber.StatusCreated).JSON(regUserResponse)
-	}
-
-	// This code should never be reached given the previous error checks
-	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Unexpected error"))
+	switch {
+	case registerRequest.Email != nil:
+		//register user using email
+		createdUser, err = a.registerUsingEmail(ctx, string(*registerRequest.Email), registerRequest.Password, user, failedRegErrMsg)
+		if err != nil {
+			logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to register email-registration user")
+			return nil, err
+		}
+		logger.Info().Interface(kName, a.iName).Msg("User registered successfully with Email")
+	case registerRequest.PhoneNumber != nil:
+		//register user using phoneNumber
+		createdUser, err = a.registrationUsingPhoneNumber(ctx, *registerRequest.PhoneNumber, registerRequest.Password, user, failedRegErrMsg)
+		if err != nil {
+			logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to register phoneNumber-registration user")
+			return nil, err
+		}
+		logger.Info().Interface(kName, a.iName).Msg("User registered successfully with Phone Number")
+	default:
+		logger.Error().Interface(kName, a.iName).Msg("Failed to identify register request type")
+		return nil, apperrors.Validation(apperrors.CodeInvalidBody, "An email or a phone number is required",
+			apperrors.RequiredField("email"), apperrors.RequiredField("phoneNumber"))
+	}
+
+	// Return the created user without sensitive information
+	return api.AuthRegister201JSONResponse{UserRegistrationSuccessJSONResponse: api.UserRegistrationSuccessJSONResponse{
+		Data:    ptr(toAPIUser(createdUser)),
+		Message: ptr("User registered successfully"),
+		Success: ptr(true),
+	}}, nil
 }
 
-func (a *AuthenticationController) Logout(c *fiber.Ctx) error {
+func (a *AuthenticationController) Logout(ctx context.Context, request api.AuthLogoutRequestObject) (api.AuthLogoutResponseObject, error) {
+	logger := logging.FromContext(ctx, a.log)
 	// used by logger
 	const kName = "Logout"
 
-	logoutRequest := new(models.LogoutRequest)
-	err := c.BodyParser(logoutRequest)
-	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to parse logout request")
-	}
-
 	// Delete the refresh token from the database.
-	err = a.authService.RevokeRefreshToken(c.Context(), logoutRequest.RefreshToken) // Implement this function in your database layer.
+	err := a.authService.RevokeRefreshToken(ctx, request.Body.RefreshToken)
 	if err != nil {
 		msg := "Failed to cancel refresh token"
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("failed to logout"))
+		logger.Error().Interface(kName, a.iName).Err(err).Msg(msg)
+		return nil, apperrors.Wrap(err, "failed to logout")
 	}
 
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(nil, "User logged out"))
+	return api.AuthLogout200JSONResponse{Message: "User logged out", Success: true}, nil
 }
 
-func (a *AuthenticationController) registerUsingEmail(c *fiber.Ctx, emailRegisterRequest models.RegisterRequestEmail, err2 error, user *models.User, failedRegErrMsg string) (fiber.Map, error, int) {
+func (a *AuthenticationController) registerUsingEmail(ctx context.Context, email string, password string, user *models.User, failedRegErrMsg string) (*models.User, error) {
 	const kName = "registerUsingEmail"
+	logger := logging.FromContext(ctx, a.log)
 
 	var err error
 
 	//check if email is valid
-	if !(utils.IsValidEmail(emailRegisterRequest.Email)) {
-		a.log.Error().Interface(kName, a.iName).Msg("Email format is invalid")
-		return nil, errors.New("invalid email"), fiber.StatusBadRequest
+	if !(utils.IsValidEmail(email)) {
+		logger.Error().Interface(kName, a.iName).Msg("Email format is invalid")
+		return nil, apperrors.Validation(apperrors.CodeInvalidEmail, "invalid email", apperrors.InvalidField("email", "email format is invalid"))
 	}
 	//check if password is strong
-	if !(utils.IsStrongPassword(emailRegisterRequest.Password)) {
-		a.log.Error().Interface(kName, a.iName).Msg("Password is weak")
-		return nil, errors.New("password is weak"), fiber.StatusBadRequest
+	if !(utils.IsStrongPassword(password)) {
+		logger.Error().Interface(kName, a.iName).Msg("Password is weak")
+		return nil, apperrors.Validation(apperrors.CodeWeakPassword, "password is weak", apperrors.InvalidField("password", "password is weak"))
 	}
 
 	//check if user exists
-	_, err = a.userService.GetUserByEmail(c.Context(), emailRegisterRequest.Email)
-	if err != nil {
-		a.log.Info().Interface(kName, a.iName).Msg("User does not exist, & can be registered")
+	_, err = a.userService.GetUserByEmail(ctx, email)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		logger.Info().Interface(kName, a.iName).Msg("User does not exist, & can be registered")
+	} else if err != nil {
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to check if user exists")
+		return nil, apperrors.Wrap(err, failedRegErrMsg)
 	} else {
-		err = errors.New("email has already been used, please try another one")
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("User already exists")
-		return nil, err, fiber.StatusConflict
+		err = apperrors.Conflict(apperrors.CodeEmailTaken, "email has already been used, please try another one")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("User already exists")
+		return nil, err
 	}
 
 	//register user using Email
-	if err2 == nil {
-		user.Email = emailRegisterRequest.Email
-		user.Password, err = utils.HashPassword(emailRegisterRequest.Password)
-		user.Username = emailRegisterRequest.Email
-		if err != nil {
-			a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash password")
-			return nil, errors.New("server had an error"), fiber.StatusInternalServerError
-		}
-
-		createdUser, err := a.userService.CreateUser(c.Context(), user)
-		if err != nil {
-			a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create user")
-			return nil, errors.New(failedRegErrMsg), fiber.StatusInternalServerError
-		}
-
-		err = a.createSettingsForUser(c, createdUser)
-		if err != nil {
-			a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create user settings")
-			return nil, errors.New(failedRegErrMsg), fiber.StatusInternalServerError
-
-		}
-
-		// Return the created user without sensitive information
-		return utils.SuccessResponse(
-			createdUser.Sanitize(),
-			"User registered successfully",
-		), nil, fiber.StatusCreated
+	user.Email = email
+	user.Password, err = utils.HashPassword(password)
+	user.Username = email
+	if err != nil {
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash password")
+		return nil, apperrors.Internal(apperrors.CodeInternal, "server had an error").WithErr(err)
 	}
 
-	a.log.Error().Interface(kName, a.iName).Err(err2).Msg("Unexpected Error, Failed to register user with Email")
-	return nil, errors.New("unexpected email registration error"), fiber.StatusInternalServerError
+	settings := models.GetSettingsDefaultsFromHeaders(utils.HeaderMapFromContext(ctx))
+	createdUser, err := a.registrationService.Register(ctx, user, settings)
+	if err != nil {
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to register user")
+		return nil, apperrors.Wrap(err, failedRegErrMsg)
+	}
 
+	return createdUser, nil
 }
 
-func (a *AuthenticationController) registrationUsingPhoneNumber(c *fiber.Ctx, registerRequest models.RegisterRequest, err1 error, user *models.User, failedRegErrMsg string) (fiber.Map, error, int) {
+func (a *AuthenticationController) registrationUsingPhoneNumber(ctx context.Context, phoneNumber string, password string, user *models.User, failedRegErrMsg string) (*models.User, error) {
 	const kName = "registerUsingPhoneNumber"
+	logger := logging.FromContext(ctx, a.log)
 
 	var err error
 
 	//check if user exists
-	_, err = a.userService.GetUserByPhoneNumber(c.Context(), registerRequest.PhoneNumber)
-	if err != nil {
-		a.log.Info().Msg("User does not exist, & can be registered")
+	_, err = a.userService.GetUserByPhoneNumber(ctx, phoneNumber)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		logger.Info().Msg("User does not exist, & can be registered")
+	} else if err != nil {
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to check if user exists")
+		return nil, apperrors.Wrap(err, failedRegErrMsg)
 	} else {
-		err = errors.New("phone number has already been used, please try another one")
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("User already exists")
-		return nil, err, fiber.StatusConflict
+		err = apperrors.Conflict(apperrors.CodePhoneNumberTaken, "phone number has already been used, please try another one")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("User already exists")
+		return nil, err
 	}
 
 	//check if PhoneNumber is valid
-	if !(utils.IsValidPhoneNumber(registerRequest.PhoneNumber)) {
-		a.log.Error().Interface(kName, a.iName).Msg("Phone number format is invalid")
-		return nil, errors.New("invalid phone number"), fiber.StatusBadRequest
+	if !(utils.IsValidPhoneNumber(phoneNumber)) {
+		logger.Error().Interface(kName, a.iName).Msg("Phone number format is invalid")
+		return nil, apperrors.Validation(apperrors.CodeInvalidPhoneNumber, "invalid phone number", apperrors.InvalidField("phoneNumber", "phone number format is invalid"))
 	}
 	//check if password is strong
-	if !(utils.IsStrongPassword(registerRequest.Password)) {
-		a.log.Error().Interface(kName, a.iName).Msg("Password is weak")
-		return nil, errors.New("password is weak, please make it min-chars=8 and include a [Number], & [special character], & [small letter], & [uppercase letter]"), fiber.StatusBadRequest
+	if !(utils.IsStrongPassword(password)) {
+		logger.Error().Interface(kName, a.iName).Msg("Password is weak")
+		msg := "password is weak, please make it min-chars=8 and include a [Number], & [special character], & [small letter], & [uppercase letter]"
+		return nil, apperrors.Validation(apperrors.CodeWeakPassword, msg, apperrors.InvalidField("password", msg))
 	}
 
-	if err1 == nil {
-		user.PhoneNumber = registerRequest.PhoneNumber
-		user.Password, err = utils.HashPassword(registerRequest.Password)
-		user.Username = registerRequest.PhoneNumber
-		if err != nil {
-			a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash password")
-			return nil, errors.New("server had an error"), fiber.StatusInternalServerError
-		}
-
-		createdUser, err := a.userService.CreateUser(c.Context(), user)
-		if err != nil {
-			a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create user")
-			return nil, errors.New(failedRegErrMsg), fiber.StatusInternalServerError
-		}
-		err = a.createSettingsForUser(c, createdUser)
-		if err != nil {
-			a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create user settings")
-			return nil, errors.New(failedRegErrMsg), fiber.StatusInternalServerError
-		}
-
-		// Return the created user without sensitive information
-		return utils.SuccessResponse(
-			createdUser.Sanitize(),
-			"User registered successfully",
-		), nil, fiber.StatusCreated
+	user.PhoneNumber = phoneNumber
+	user.Password, err = utils.HashPassword(password)
+	user.Username = phoneNumber
+	if err != nil {
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash password")
+		return nil, apperrors.Internal(apperrors.CodeInternal, "server had an error").WithErr(err)
 	}
 
-	a.log.Error().Interface(kName, a.iName).Err(err1).Msg("Unexpected Error, Failed to register user with Phone Number")
-	return nil, errors.New("unexpected phone number registration error"), fiber.StatusInternalServerError
-}
-
-func (a *AuthenticationController) createSettingsForUser(c *fiber.Ctx, createdUser *models.User) error {
-	const kName = "createSettingsForUser"
-
-	settings := models.GetSettingsDefaultsFromHeaders(utils.GetHeaderMap(c))
-	settings.UserId = createdUser.ID
-	_, err := a.settingsService.Create(c.Context(), settings)
+	settings := models.GetSettingsDefaultsFromHeaders(utils.HeaderMapFromContext(ctx))
+	createdUser, err := a.registrationService.Register(ctx, user, settings)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create user's settings")
-		// Handle settings creation error, perhaps delete the user that was created.
-		// Rollback user creation if settings creation fails.
-		deleteErr := a.userService.DeleteUser(c.Context(), createdUser.ID.String())
-		if deleteErr != nil {
-			a.log.Error().Interface(kName, a.iName).Err(deleteErr).Msg("Failed to delete user after settings creation error")
-		}
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to register user")
+		return nil, apperrors.Wrap(err, failedRegErrMsg)
 	}
-	return nil
+
+	return createdUser, nil
 }
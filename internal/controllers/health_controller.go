@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/rs/zerolog"
+)
+
+type IHealthController interface {
+	// Livez liveness probe, ok as long as the process serves requests
+	// (GET /livez)
+	Livez(c *fiber.Ctx) error
+
+	// Readyz readiness probe, 503 while any dependency check fails or during shutdown
+	// (GET /readyz)
+	Readyz(c *fiber.Ctx) error
+}
+
+type HealthController struct {
+	iName         string
+	logger        *zerolog.Logger
+	healthService services.IHealthService
+}
+
+func NewHealthController(log *zerolog.Logger, healthSvc services.IHealthService) IHealthController {
+	return &HealthController{
+		iName:         "HealthController",
+		logger:        log,
+		healthService: healthSvc,
+	}
+}
+
+func (h *HealthController) Livez(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.healthService.Live())
+}
+
+func (h *HealthController) Readyz(c *fiber.Ctx) error {
+	report := h.healthService.Ready(c.UserContext())
+	if report.Status != models.HealthStatusUp {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.Status(fiber.StatusOK).JSON(report)
+}
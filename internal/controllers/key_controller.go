@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+)
+
+type IKeyController interface {
+	// PublishDeviceKeys publish identity key, signed prekey & optional one-time prekeys of a device
+	// (PUT /keys/devices/{deviceId})
+	PublishDeviceKeys(ctx context.Context, request api.PublishDeviceKeysRequestObject) (api.PublishDeviceKeysResponseObject, error)
+
+	// UploadOneTimePreKeys replenish the one-time prekeys of a device
+	// (POST /keys/devices/{deviceId}/prekeys)
+	UploadOneTimePreKeys(ctx context.Context, request api.UploadOneTimePreKeysRequestObject) (api.UploadOneTimePreKeysResponseObject, error)
+
+	// GetPreKeyCount get the number of one-time prekeys left for a device
+	// (GET /keys/devices/{deviceId}/prekeys/count)
+	GetPreKeyCount(ctx context.Context, request api.GetPreKeyCountRequestObject) (api.GetPreKeyCountResponseObject, error)
+
+	// DeleteDeviceKeys remove a device and its keys
+	// (DELETE /keys/devices/{deviceId})
+	DeleteDeviceKeys(ctx context.Context, request api.DeleteDeviceKeysRequestObject) (api.DeleteDeviceKeysResponseObject, error)
+
+	// GetUserPreKeyBundles fetch a prekey bundle for every device of a user
+	// (GET /keys/users/{userId}/bundles)
+	GetUserPreKeyBundles(ctx context.Context, request api.GetUserPreKeyBundlesRequestObject) (api.GetUserPreKeyBundlesResponseObject, error)
+}
+
+type KeyController struct {
+	iName      string
+	logger     *zerolog.Logger
+	keyService services.IKeyDistributionService
+}
+
+func NewKeyController(log *zerolog.Logger, keySvc services.IKeyDistributionService) IKeyController {
+	return &KeyController{
+		iName:      "KeyController",
+		logger:     log,
+		keyService: keySvc,
+	}
+}
+
+func (k *KeyController) PublishDeviceKeys(ctx context.Context, request api.PublishDeviceKeysRequestObject) (api.PublishDeviceKeysResponseObject, error) {
+	const kName = "PublishDeviceKeys"
+	logger := logging.FromContext(ctx, k.logger)
+
+	user, err := k.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := k.keyService.PublishKeys(ctx, user, request.DeviceId, publishKeysFromRequest(request.Body))
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("Failed to publish device keys")
+		return nil, apperrors.Wrap(err, "Failed to publish device keys")
+	}
+	return api.PublishDeviceKeys200JSONResponse{PreKeyCountSuccessJSONResponse: api.PreKeyCountSuccessJSONResponse{
+		Data:    ptr(toAPIPreKeyCount(count)),
+		Message: ptr("Device keys published"),
+		Success: ptr(true),
+	}}, nil
+}
+
+func (k *KeyController) UploadOneTimePreKeys(ctx context.Context, request api.UploadOneTimePreKeysRequestObject) (api.UploadOneTimePreKeysResponseObject, error) {
+	const kName = "UploadOneTimePreKeys"
+	logger := logging.FromContext(ctx, k.logger)
+
+	user, err := k.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := k.keyService.UploadOneTimePreKeys(ctx, user, request.DeviceId, oneTimePreKeysFromAPI(request.Body.OneTimePreKeys))
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("Failed to upload one-time prekeys")
+		return nil, apperrors.Wrap(err, "Failed to upload one-time prekeys")
+	}
+	return api.UploadOneTimePreKeys200JSONResponse{PreKeyCountSuccessJSONResponse: api.PreKeyCountSuccessJSONResponse{
+		Data:    ptr(toAPIPreKeyCount(count)),
+		Message: ptr("One-time prekeys uploaded"),
+		Success: ptr(true),
+	}}, nil
+}
+
+func (k *KeyController) GetPreKeyCount(ctx context.Context, request api.GetPreKeyCountRequestObject) (api.GetPreKeyCountResponseObject, error) {
+	const kName = "GetPreKeyCount"
+	logger := logging.FromContext(ctx, k.logger)
+
+	user, err := k.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := k.keyService.GetPreKeyCount(ctx, user, request.DeviceId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("Failed to count one-time prekeys")
+		return nil, apperrors.Wrap(err, "Failed to count one-time prekeys")
+	}
+	return api.GetPreKeyCount200JSONResponse{PreKeyCountSuccessJSONResponse: api.PreKeyCountSuccessJSONResponse{
+		Data:    ptr(toAPIPreKeyCount(count)),
+		Message: ptr("One-time prekey count"),
+		Success: ptr(true),
+	}}, nil
+}
+
+func (k *KeyController) DeleteDeviceKeys(ctx context.Context, request api.DeleteDeviceKeysRequestObject) (api.DeleteDeviceKeysResponseObject, error) {
+	const kName = "DeleteDeviceKeys"
+	logger := logging.FromContext(ctx, k.logger)
+
+	user, err := k.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = k.keyService.RemoveDevice(ctx, user, request.DeviceId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("Failed to remove device keys")
+		return nil, apperrors.Wrap(err, "Failed to remove device keys")
+	}
+	return api.DeleteDeviceKeys200JSONResponse{Message: "Device keys removed", Success: true}, nil
+}
+
+func (k *KeyController) GetUserPreKeyBundles(ctx context.Context, request api.GetUserPreKeyBundlesRequestObject) (api.GetUserPreKeyBundlesResponseObject, error) {
+	const kName = "GetUserPreKeyBundles"
+	logger := logging.FromContext(ctx, k.logger)
+
+	user, err := k.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := k.keyService.GetPreKeyBundles(ctx, user, request.UserId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("Failed to get prekey bundles")
+		return nil, apperrors.Wrap(err, "Failed to get prekey bundles")
+	}
+	if len(bundles) == 0 {
+		return nil, apperrors.NotFound(apperrors.CodeDeviceNotFound, "User has no devices with published keys")
+	}
+	data := make([]api.PreKeyBundle, 0, len(bundles))
+	for i := range bundles {
+		data = append(data, toAPIPreKeyBundle(&bundles[i]))
+	}
+	return api.GetUserPreKeyBundles200JSONResponse{Data: &data, Message: ptr("Prekey bundles"), Success: ptr(true)}, nil
+}
+
+// userFromContext returns the authenticated user attached by the strict server's authentication
+func (k *KeyController) userFromContext(ctx context.Context) (*models.User, error) {
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logging.FromContext(ctx, k.logger).Error().Interface("userFromContext", k.iName).Msg("Failed to get user object from context")
+		return nil, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
+	}
+	return user, nil
+}
@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"github.com/rs/zerolog"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxMediaFieldLen bounds the text fields of an upload, they only hold ids & media types
+const maxMediaFieldLen = 64
+
+type IMediaController interface {
+	// UploadMedia Upload media, streaming the file to the blob store as it arrives
+	// (POST /media)
+	UploadMedia(c *fiber.Ctx) error
+
+	// GetMediaById Get media by ID
+	// (GET /media/{mediaId})
+	GetMediaById(ctx context.Context, request api.GetMediaByIdRequestObject) (api.GetMediaByIdResponseObject, error)
+
+	// DeleteMedia Delete media
+	// (DELETE /media/{mediaId})
+	DeleteMedia(ctx context.Context, request api.DeleteMediaRequestObject) (api.DeleteMediaResponseObject, error)
+
+	// GetMediaContent Download media content
+	// (GET /media/{mediaId}/content)
+	GetMediaContent(ctx context.Context, request api.GetMediaContentRequestObject) (api.GetMediaContentResponseObject, error)
+
+	// GetChatGallery List the shared media of a chat
+	// (GET /chats/{chatId}/media)
+	GetChatGallery(ctx context.Context, request api.GetChatGalleryRequestObject) (api.GetChatGalleryResponseObject, error)
+
+	// GetStorageUsage Get the storage usage
+	// (GET /storage/usage)
+	GetStorageUsage(ctx context.Context, request api.GetStorageUsageRequestObject) (api.GetStorageUsageResponseObject, error)
+}
+
+type MediaController struct {
+	iName          string
+	logger         *zerolog.Logger
+	mediaService   services.IMediaService
+	galleryService services.IGalleryService
+	quotaService   services.IStorageQuotaService
+}
+
+func NewMediaController(log *zerolog.Logger, mediaSvc services.IMediaService, gallerySvc services.IGalleryService, quotaSvc services.IStorageQuotaService) IMediaController {
+	return &MediaController{
+		iName:          "MediaController",
+		logger:         log,
+		mediaService:   mediaSvc,
+		galleryService: gallerySvc,
+		quotaService:   quotaSvc,
+	}
+}
+
+func (m *MediaController) UploadMedia(c *fiber.Ctx) error {
+	const kName = "UploadMedia"
+	ctx := c.UserContext()
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	mediaType, params, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if err != nil || mediaType != fiber.MIMEMultipartForm || params["boundary"] == "" {
+		return apperrors.Validation(apperrors.CodeInvalidBody, "Expected a multipart/form-data body")
+	}
+
+	// the parts are read as they arrive, the text fields come first & the file streams to storage
+	body := multipart.NewReader(middleware.BodyStream(c), params["boundary"])
+	fields := map[string]string{}
+	for {
+		part, err := body.NextPart()
+		if errors.Is(err, io.EOF) {
+			return apperrors.Validation(apperrors.CodeValidation, "No file was uploaded", apperrors.RequiredField("file"))
+		}
+		if err != nil {
+			logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to read multipart body")
+			return apperrors.Validation(apperrors.CodeInvalidBody, "Invalid multipart body").WithErr(err)
+		}
+		if part.FormName() != "file" {
+			if err = readMediaField(part, fields); err != nil {
+				return err
+			}
+			continue
+		}
+
+		upload, err := uploadFromFields(fields, part)
+		if err != nil {
+			return err
+		}
+		if upload.SenderID != user.ID.Hex() {
+			return apperrors.Forbidden(apperrors.CodeForbidden, "Media can only be uploaded as the authenticated user")
+		}
+		media, err := m.mediaService.UploadMedia(ctx, upload)
+		if err != nil {
+			logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to upload media")
+			return apperrors.Wrap(err, "Failed to upload media")
+		}
+		return c.Status(fiber.StatusCreated).JSON(toAPIMedia(media))
+	}
+}
+
+func (m *MediaController) GetMediaById(ctx context.Context, request api.GetMediaByIdRequestObject) (api.GetMediaByIdResponseObject, error) {
+	const kName = "GetMediaById"
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	media, err := m.mediaService.GetMediaById(ctx, user.ID.Hex(), request.MediaId)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get media")
+		return nil, apperrors.Wrap(err, "Failed to get media")
+	}
+	return api.GetMediaById200JSONResponse(toAPIMedia(media)), nil
+}
+
+func (m *MediaController) DeleteMedia(ctx context.Context, request api.DeleteMediaRequestObject) (api.DeleteMediaResponseObject, error) {
+	const kName = "DeleteMedia"
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.mediaService.DeleteMedia(ctx, user.ID.Hex(), request.MediaId); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to delete media")
+		return nil, apperrors.Wrap(err, "Failed to delete media")
+	}
+	return api.DeleteMedia204Response{}, nil
+}
+
+func (m *MediaController) GetMediaContent(ctx context.Context, request api.GetMediaContentRequestObject) (api.GetMediaContentResponseObject, error) {
+	const kName = "GetMediaContent"
+	logger := logging.FromContext(ctx, m.logger)
+
+	byteRange := parseByteRange(utils.HeaderMapFromContext(ctx)[fiber.HeaderRange])
+	thumbnail := 0
+	if request.Params.Thumbnail != nil {
+		thumbnail = *request.Params.Thumbnail
+	}
+	content, err := m.mediaService.GetMediaContent(ctx, request.MediaId, thumbnail, request.Params.Expires, request.Params.Signature, byteRange)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get media content")
+		return nil, apperrors.Wrap(err, "Failed to get media content")
+	}
+
+	contentType := content.ContentType
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	// the URL is the credential & the content of a media never changes, shared caches may keep it until the URL expires
+	maxAge := max(time.Until(time.Unix(request.Params.Expires, 0)), 0)
+	cacheControl := "public, max-age=" + strconv.Itoa(int(maxAge.Seconds())) + ", immutable"
+	disposition := "inline"
+	if content.FileName != "" {
+		disposition = mime.FormatMediaType("inline", map[string]string{"filename": content.FileName})
+	}
+
+	if content.Partial {
+		return api.GetMediaContent206AsteriskResponse{
+			Body:          content.Body,
+			ContentLength: content.Length,
+			ContentType:   contentType,
+			Headers: api.GetMediaContent206ResponseHeaders{
+				AcceptRanges:       "bytes",
+				CacheControl:       cacheControl,
+				ContentDisposition: disposition,
+				ContentRange:       fmt.Sprintf("bytes %d-%d/%d", content.Offset, content.Offset+content.Length-1, content.Size),
+			},
+		}, nil
+	}
+	return api.GetMediaContent200AsteriskResponse{
+		Body:          content.Body,
+		ContentLength: content.Length,
+		ContentType:   contentType,
+		Headers: api.GetMediaContent200ResponseHeaders{
+			AcceptRanges:       "bytes",
+			CacheControl:       cacheControl,
+			ContentDisposition: disposition,
+		},
+	}, nil
+}
+
+func (m *MediaController) GetChatGallery(ctx context.Context, request api.GetChatGalleryRequestObject) (api.GetChatGalleryResponseObject, error) {
+	const kName = "GetChatGallery"
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor, limit := "", 0
+	if request.Params.Cursor != nil {
+		cursor = *request.Params.Cursor
+	}
+	if request.Params.Limit != nil {
+		limit = *request.Params.Limit
+	}
+	gallery, err := m.galleryService.GetChatGallery(ctx, user.ID.Hex(), request.ChatId, string(request.Params.Type), cursor, limit)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get chat gallery")
+		return nil, apperrors.Wrap(err, "Failed to get chat gallery")
+	}
+	return api.GetChatGallery200JSONResponse(toAPIChatGallery(gallery)), nil
+}
+
+func (m *MediaController) GetStorageUsage(ctx context.Context, _ api.GetStorageUsageRequestObject) (api.GetStorageUsageResponseObject, error) {
+	const kName = "GetStorageUsage"
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report, err := m.quotaService.GetUsage(ctx, user.ID.Hex())
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get storage usage")
+		return nil, apperrors.Wrap(err, "Failed to get storage usage")
+	}
+	return api.GetStorageUsage200JSONResponse(toAPIStorageUsage(report)), nil
+}
+
+func (m *MediaController) userFromContext(ctx context.Context) (*models.User, error) {
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logging.FromContext(ctx, m.logger).Error().Interface("userFromContext", m.iName).Msg("Failed to get user object from context")
+		return nil, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
+	}
+	return user, nil
+}
+
+// readMediaField reads a text part of an upload into fields
+func readMediaField(part *multipart.Part, fields map[string]string) error {
+	value, err := io.ReadAll(io.LimitReader(part, maxMediaFieldLen+1))
+	if err != nil {
+		return apperrors.Validation(apperrors.CodeInvalidBody, "Invalid multipart body").WithErr(err)
+	}
+	if len(value) > maxMediaFieldLen {
+		return apperrors.Validation(apperrors.CodeValidation, "Invalid upload field", apperrors.InvalidField(part.FormName(), "is too long"))
+	}
+	fields[part.FormName()] = string(value)
+	return nil
+}
+
+// uploadFromFields checks the text fields read before the file part
+func uploadFromFields(fields map[string]string, file *multipart.Part) (*services.MediaUpload, error) {
+	var missing []apperrors.FieldError
+	for _, name := range []string{"chatId", "senderId", "mediaType"} {
+		if fields[name] == "" {
+			missing = append(missing, apperrors.RequiredField(name))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "chatId, senderId & mediaType must be sent before the file", missing...)
+	}
+	return &services.MediaUpload{
+		ChatID:    fields["chatId"],
+		SenderID:  fields["senderId"],
+		MediaType: fields["mediaType"],
+		FileName:  file.FileName(),
+		Content:   file,
+	}, nil
+}
+
+// parseByteRange parses a Range header of a single byte range, other headers are ignored & the whole file is served
+func parseByteRange(header string) *services.ByteRange {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok || (first == "" && last == "") {
+		return nil
+	}
+	byteRange := &services.ByteRange{Start: -1, End: -1}
+	for _, bound := range []struct {
+		value string
+		dst   *int64
+	}{{first, &byteRange.Start}, {last, &byteRange.End}} {
+		if bound.value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(bound.value, 10, 64)
+		if err != nil || n < 0 {
+			return nil
+		}
+		*bound.dst = n
+	}
+	return byteRange
+}
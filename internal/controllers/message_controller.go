@@ -1,10 +1,13 @@
 package controllers
 
 import (
-	"github.com/gofiber/fiber/v2"
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
-	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
 	"github.com/rs/zerolog"
 	"time"
 )
@@ -12,27 +15,27 @@ import (
 type IMessageController interface {
 	// CreateMessage Create a new message
 	// (POST /messages)
-	CreateMessage(c *fiber.Ctx) error
-
-	// GetAllMessages Get all messages
-	// (GET /messages)
-	GetAllMessages(c *fiber.Ctx) error
+	CreateMessage(ctx context.Context, request api.SendMessageRequestObject) (api.SendMessageResponseObject, error)
 
 	// GetMessageById Get a message by ID
 	// (GET /messages/{messageId})
-	GetMessageById(c *fiber.Ctx, messageId string) error
-
-	// GetMessagesByUserId Get a message by ID
-	// (GET /messages/user/{userId})
-	GetMessagesByUserId(c *fiber.Ctx, userId string) error
+	GetMessageById(ctx context.Context, request api.GetMessageByIdRequestObject) (api.GetMessageByIdResponseObject, error)
 
 	// UpdateMessage Update a message
 	// (PUT /messages/{messageId})
-	UpdateMessage(c *fiber.Ctx, messageId string) error
+	UpdateMessage(ctx context.Context, request api.UpdateMessageRequestObject) (api.UpdateMessageResponseObject, error)
 
 	// DeleteMessage Delete a message
 	// (DELETE /messages/{messageId})
-	DeleteMessage(c *fiber.Ctx, messageId string) error
+	DeleteMessage(ctx context.Context, request api.DeleteMessageRequestObject) (api.DeleteMessageResponseObject, error)
+
+	// MarkMessagePlayed Mark a voice note played
+	// (POST /messages/{messageId}/played)
+	MarkMessagePlayed(ctx context.Context, request api.MarkMessagePlayedRequestObject) (api.MarkMessagePlayedResponseObject, error)
+
+	// GetMessagePlayedReceipts List who played a voice note
+	// (GET /messages/{messageId}/played)
+	GetMessagePlayedReceipts(ctx context.Context, request api.GetMessagePlayedReceiptsRequestObject) (api.GetMessagePlayedReceiptsResponseObject, error)
 }
 
 type MessageController struct {
@@ -49,83 +52,133 @@ func NewMessageController(log *zerolog.Logger, messageSvc services.IMessageServi
 	}
 }
 
-func (m MessageController) CreateMessage(c *fiber.Ctx) error {
+func (m MessageController) CreateMessage(ctx context.Context, request api.SendMessageRequestObject) (api.SendMessageResponseObject, error) {
 	const kName = "CreateMessage"
-	message := new(models.Message)
-	err := c.BodyParser(message)
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Invalid request body"))
+		return nil, err
+	}
+	message, err := messageFromCreateRequest(user.ID, request.Body)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to read message from request body")
+		return nil, err
 	}
 	//add properties to message
 	message.CreatedAt = time.Now()
 
 	//create message via service
-	createdMsg, err := m.messageService.Create(c.Context(), message)
+	createdMsg, err := m.messageService.Create(ctx, message)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to create message")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to create message"))
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to create message")
+		return nil, apperrors.Wrap(err, "Failed to create message")
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse(createdMsg, "Created message"))
-}
-
-func (m MessageController) GetAllMessages(c *fiber.Ctx) error {
-	const kName = "GetAllMessages"
-
-	//TODO implement me
-	panic("implement me")
+	return api.SendMessage201JSONResponse{Data: ptr(toAPIMessage(createdMsg)), Message: ptr("Created message"), Success: ptr(true)}, nil
 }
 
-func (m MessageController) GetMessageById(c *fiber.Ctx, messageId string) error {
+func (m MessageController) GetMessageById(ctx context.Context, request api.GetMessageByIdRequestObject) (api.GetMessageByIdResponseObject, error) {
 	const kName = "GetMessageById"
+	logger := logging.FromContext(ctx, m.logger)
 
-	message, err := m.messageService.GetById(c.Context(), messageId)
+	user, err := m.userFromContext(ctx)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get message")
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse("Failed to get message"))
+		return nil, err
 	}
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(message, "Message Found"))
-}
-
-func (m MessageController) GetMessagesByUserId(c *fiber.Ctx, userId string) error {
-	const kName = "GetMessagesByUserId"
-
-	senderMsgs, err := m.messageService.GetBySenderId(c.Context(), userId)
+	message, err := m.messageService.GetById(ctx, user.ID.Hex(), request.MessageId)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get sender id")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to get sender id"))
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get message")
+		return nil, apperrors.Wrap(err, "Failed to get message")
 	}
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(senderMsgs, "Messages Found"))
+	return api.GetMessageById200JSONResponse{Data: ptr(toAPIMessage(message)), Message: ptr("Message Found"), Success: ptr(true)}, nil
 }
 
-func (m MessageController) UpdateMessage(c *fiber.Ctx, messageId string) error {
+func (m MessageController) UpdateMessage(ctx context.Context, request api.UpdateMessageRequestObject) (api.UpdateMessageResponseObject, error) {
 	const kName = "UpdateMessage"
+	logger := logging.FromContext(ctx, m.logger)
 
-	message := new(models.Message)
-	err := c.BodyParser(message)
+	user, err := m.userFromContext(ctx)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Invalid request body"))
+		return nil, err
+	}
+	message, err := m.messageService.GetById(ctx, user.ID.Hex(), request.MessageId)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get message to update")
+		return nil, apperrors.Wrap(err, "Failed to update message")
+	}
+	if err = applyMessageUpdate(message, request.Body); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to read message update from request body")
+		return nil, err
 	}
 
 	message.UpdatedAt = time.Now()
-	err = m.messageService.Update(c.Context(), message)
+	err = m.messageService.Update(ctx, user.ID.Hex(), message)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to update message")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to update message"))
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to update message")
+		return nil, apperrors.Wrap(err, "Failed to update message")
 	}
 
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(message, "Updated message"))
+	return api.UpdateMessage200JSONResponse{Data: ptr(toAPIMessage(message)), Message: ptr("Updated message"), Success: ptr(true)}, nil
 }
 
-func (m MessageController) DeleteMessage(c *fiber.Ctx, messageId string) error {
+func (m MessageController) DeleteMessage(ctx context.Context, request api.DeleteMessageRequestObject) (api.DeleteMessageResponseObject, error) {
 	const kName = "DeleteMessage"
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = m.messageService.Delete(ctx, user.ID.Hex(), request.MessageId)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to delete message")
+		return nil, apperrors.Wrap(err, "Failed to delete message")
+	}
+	return api.DeleteMessage204Response{}, nil
+}
+
+func (m MessageController) MarkMessagePlayed(ctx context.Context, request api.MarkMessagePlayedRequestObject) (api.MarkMessagePlayedResponseObject, error) {
+	const kName = "MarkMessagePlayed"
+	logger := logging.FromContext(ctx, m.logger)
+
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := m.messageService.MarkPlayed(ctx, user.ID.Hex(), request.MessageId)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to mark message played")
+		return nil, apperrors.Wrap(err, "Failed to mark message played")
+	}
+	return api.MarkMessagePlayed200JSONResponse(toAPIPlayedReceipt(receipt)), nil
+}
+
+func (m MessageController) GetMessagePlayedReceipts(ctx context.Context, request api.GetMessagePlayedReceiptsRequestObject) (api.GetMessagePlayedReceiptsResponseObject, error) {
+	const kName = "GetMessagePlayedReceipts"
+	logger := logging.FromContext(ctx, m.logger)
 
-	err := m.messageService.Delete(c.Context(), messageId)
+	user, err := m.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := m.messageService.GetPlayedReceipts(ctx, user.ID.Hex(), request.MessageId)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to delete message")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to delete message"))
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get played receipts")
+		return nil, apperrors.Wrap(err, "Failed to get played receipts")
+	}
+	response := make(api.GetMessagePlayedReceipts200JSONResponse, 0, len(receipts))
+	for i := range receipts {
+		response = append(response, toAPIPlayedReceipt(&receipts[i]))
+	}
+	return response, nil
+}
+
+func (m MessageController) userFromContext(ctx context.Context) (*models.User, error) {
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logging.FromContext(ctx, m.logger).Error().Interface("userFromContext", m.iName).Msg("Failed to get user object from context")
+		return nil, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
 	}
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(nil, "Message Deleted"))
+	return user, nil
 }
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// eventsHeartbeat keeps idle streams open through proxies & finds the clients that went away. fasthttp's pipe & the
+// socket buffer the writes after a client closed its connection, it goes offline within about three heartbeats.
+const eventsHeartbeat = 15 * time.Second
+
+type IPresenceController interface {
+	// StreamEvents stream the presence & chat activity events of the authenticated user as server-sent events
+	// (GET /realtime/events)
+	StreamEvents(c *fiber.Ctx) error
+
+	// SetChatActivity tell the other participants of a chat the user is typing, recording or stopped
+	// (POST /realtime/chats/{chatId}/activity)
+	SetChatActivity(ctx context.Context, request api.SetChatActivityRequestObject) (api.SetChatActivityResponseObject, error)
+
+	// GetUserPresence get whether a user is online or when they were last seen
+	// (GET /realtime/users/{userId}/presence)
+	GetUserPresence(ctx context.Context, request api.GetUserPresenceRequestObject) (api.GetUserPresenceResponseObject, error)
+}
+
+type PresenceController struct {
+	iName           string
+	logger          *zerolog.Logger
+	presenceService services.IPresenceService
+}
+
+func NewPresenceController(log *zerolog.Logger, presenceSvc services.IPresenceService) IPresenceController {
+	return &PresenceController{
+		iName:           "PresenceController",
+		logger:          log,
+		presenceService: presenceSvc,
+	}
+}
+
+func (p *PresenceController) StreamEvents(c *fiber.Ctx) error {
+	const kName = "StreamEvents"
+	logger := logging.FromContext(c.UserContext(), p.logger)
+
+	user, err := p.userFromContext(c.UserContext())
+	if err != nil {
+		return err
+	}
+	stream, err := p.presenceService.Connect(c.UserContext(), user.ID.Hex())
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to connect presence stream")
+		return apperrors.Wrap(err, "Failed to connect presence stream")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // nginx would hold the events back
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer stream.Close()
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+		// the comment sends the headers right away
+		_, _ = w.WriteString(": connected\n\n")
+		for {
+			if err := w.Flush(); err != nil {
+				// the client went away
+				return
+			}
+			select {
+			case event, ok := <-stream.Events():
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event.Data)
+				if err != nil {
+					logger.Error().Interface(kName, p.iName).Err(err).Str("event", event.Type).Msg("Failed to encode event")
+					continue
+				}
+				_, _ = w.WriteString("event: " + event.Type + "\ndata: " + string(data) + "\n\n")
+			case <-heartbeat.C:
+				_, _ = w.WriteString(": heartbeat\n\n")
+			}
+		}
+	})
+	return nil
+}
+
+func (p *PresenceController) SetChatActivity(ctx context.Context, request api.SetChatActivityRequestObject) (api.SetChatActivityResponseObject, error) {
+	const kName = "SetChatActivity"
+	logger := logging.FromContext(ctx, p.logger)
+
+	user, err := p.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := p.presenceService.SetActivity(ctx, user.ID.Hex(), request.ChatId, string(request.Body.Activity))
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to set chat activity")
+		return nil, apperrors.Wrap(err, "Failed to set chat activity")
+	}
+	return api.SetChatActivity200JSONResponse{Data: ptr(toAPIChatActivity(activity)), Message: ptr("Chat activity sent"), Success: ptr(true)}, nil
+}
+
+func (p *PresenceController) GetUserPresence(ctx context.Context, request api.GetUserPresenceRequestObject) (api.GetUserPresenceResponseObject, error) {
+	const kName = "GetUserPresence"
+	logger := logging.FromContext(ctx, p.logger)
+
+	user, err := p.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	presence, err := p.presenceService.GetPresence(ctx, user.ID.Hex(), request.UserId)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to get user presence")
+		return nil, apperrors.Wrap(err, "Failed to get user presence")
+	}
+	return api.GetUserPresence200JSONResponse{Data: ptr(toAPIPresence(presence)), Message: ptr("User presence"), Success: ptr(true)}, nil
+}
+
+// userFromContext returns the authenticated user attached by the authentication
+func (p *PresenceController) userFromContext(ctx context.Context) (*models.User, error) {
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logging.FromContext(ctx, p.logger).Error().Interface("userFromContext", p.iName).Msg("Failed to get user object from context")
+		return nil, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
+	}
+	return user, nil
+}
@@ -1,11 +1,14 @@
 package controllers
 
 import (
-	"errors"
+	"context"
 	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
 	"github.com/rs/zerolog"
 	"time"
@@ -15,11 +18,11 @@ type ISettingsController interface {
 
 	// GetUserSettings get user settings
 	// (GET /settings/{userId}
-	GetUserSettings(c *fiber.Ctx, userId string) error
+	GetUserSettings(ctx context.Context, request api.GetUserSettingsRequestObject) (api.GetUserSettingsResponseObject, error)
 
 	// UpdateUserSettings Update user settings
 	// (PUT /settings/{userId})
-	UpdateUserSettings(c *fiber.Ctx, userId string) error
+	UpdateUserSettings(ctx context.Context, request api.UpdateUserSettingsRequestObject) (api.UpdateUserSettingsResponseObject, error)
 
 	// CreateUserSettings creates settings for a user
 	CreateUserSettings(c *fiber.Ctx, userId string) error
@@ -43,11 +46,12 @@ func NewSettingsController(log *zerolog.Logger, settingsSvc services.ISettingsSe
 
 func (s *SettingsController) CreateUserSettings(c *fiber.Ctx, userId string) error {
 	const kName = "CreateUserSettings"
+	logger := logging.FromContext(c.UserContext(), s.logger)
 	// Convert userId to primitive.ObjectID
 	objectID, err := utils.StringToObjectID(userId)
 	if err != nil {
-		s.logger.Error().Interface(kName, s.iName).Err(err).Str("userId", userId).Msg("error parsing user id")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Failed to create userId for settings"))
+		logger.Error().Interface(kName, s.iName).Err(err).Str("userId", userId).Msg("error parsing user id")
+		return apperrors.Validation(apperrors.CodeInvalidID, "Failed to create userId for settings").WithErr(err)
 	}
 
 	// Create default UserSettings
@@ -55,73 +59,81 @@ func (s *SettingsController) CreateUserSettings(c *fiber.Ctx, userId string) err
 	settings.UserId = objectID
 
 	// create to persist user settings in db
-	_, err = s.settingsService.Create(c.Context(), settings)
+	_, err = s.settingsService.Create(c.UserContext(), settings)
 	if err != nil {
-		s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to create user settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to create user settings"))
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to create user settings")
+		return apperrors.Wrap(err, "Failed to create user settings")
 	}
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(settings, "Created user settings"))
 }
 
-func (s *SettingsController) GetUserSettings(c *fiber.Ctx, userId string) error {
+func (s *SettingsController) GetUserSettings(ctx context.Context, request api.GetUserSettingsRequestObject) (api.GetUserSettingsResponseObject, error) {
 	const kName = "GetUserSettings"
+	logger := logging.FromContext(ctx, s.logger)
+	userId := request.UserId
 
 	// Authorize
-	can, status, response, err := s.isAuthorizedForSettingsResource(c, userId, services.ActionRead)
+	can, err := s.isAuthorizedForSettingsResource(ctx, userId, services.ActionRead)
 	if err != nil {
-		s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to authorize for Settings-Resource")
-		return c.Status(status).JSON(response)
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to authorize for Settings-Resource")
+		return nil, err
 	}
 
 	if can {
-		userSettings, err := s.settingsService.GetByUserId(c.Context(), userId)
+		userSettings, err := s.settingsService.GetByUserId(ctx, userId)
 		if err != nil {
-			s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to get user settings")
-			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse("Could not find user settings"))
+			logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to get user settings")
+			return nil, apperrors.Wrap(err, "Could not find user settings")
 		}
-		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(userSettings, "User settings"))
+		return api.GetUserSettings200JSONResponse{Data: ptr(toAPISettings(userSettings)), Message: ptr("User settings"), Success: ptr(true)}, nil
 	}
-	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to get user settings, unexpected error occurred"))
+	return nil, apperrors.Internal(apperrors.CodeInternal, "Failed to get user settings, unexpected error occurred")
 }
 
-func (s *SettingsController) UpdateUserSettings(c *fiber.Ctx, userId string) error {
+func (s *SettingsController) UpdateUserSettings(ctx context.Context, request api.UpdateUserSettingsRequestObject) (api.UpdateUserSettingsResponseObject, error) {
 	const kName = "UpdateUserSettings"
+	logger := logging.FromContext(ctx, s.logger)
+	userId := request.UserId
 
-	can, status, response, err := s.isAuthorizedForSettingsResource(c, userId, services.ActionUpdate)
+	can, err := s.isAuthorizedForSettingsResource(ctx, userId, services.ActionUpdate)
 	if err != nil {
-		s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to authorize for Settings-Resource")
-		return c.Status(status).JSON(response)
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to authorize for Settings-Resource")
+		return nil, err
 	}
 	if can {
-		settingsUpdate := new(models.Settings)
-		err := c.BodyParser(settingsUpdate)
+		settingsUpdate, err := s.settingsService.GetByUserId(ctx, userId)
 		if err != nil {
-			s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to retrieve user settings from request body")
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Could not parse request body for settings update"))
+			logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to get user settings to update")
+			return nil, apperrors.Wrap(err, "Could not find user settings")
 		}
+		applySettingsUpdate(settingsUpdate, request.Body)
 
 		settingsUpdate.UpdatedAt = time.Now()
-		err = s.settingsService.Update(c.Context(), settingsUpdate)
+		err = s.settingsService.Update(ctx, settingsUpdate)
 		if err != nil {
-			s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to update user settings")
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to update user settings"))
+			logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to update user settings")
+			return nil, apperrors.Wrap(err, "Failed to update user settings")
 		}
 
-		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(settingsUpdate, "Updated user settings"))
+		return api.UpdateUserSettings200JSONResponse{Data: ptr(toAPISettings(settingsUpdate)), Message: ptr("Updated user settings"), Success: ptr(true)}, nil
 	}
 
-	s.logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to update user settings due to unexpected error")
-	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to update user settings due to unexpected error"))
+	logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to update user settings due to unexpected error")
+	return nil, apperrors.Internal(apperrors.CodeInternal, "Failed to update user settings due to unexpected error")
 }
 
-func (s *SettingsController) isAuthorizedForSettingsResource(c *fiber.Ctx, userId string, action string) (bool, int, fiber.Map, error) {
+func (s *SettingsController) isAuthorizedForSettingsResource(ctx context.Context, userId string, action string) (bool, error) {
 	const kName = "isAuthorizedForSettingsResource"
+	logger := logging.FromContext(ctx, s.logger)
 
-	user, ok := c.Context().Value(middleware.UserObjectContextKey).(*models.User)
-	if !ok || user.ID.Hex() != userId {
-		msg := "Failed to get user object from context"
-		s.logger.Error().Interface(kName, s.iName).Msg(msg)
-		return false, fiber.StatusInternalServerError, utils.ErrorResponse("Could not determine user context"), errors.New(msg)
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logger.Error().Interface(kName, s.iName).Msg("Failed to get user object from context")
+		return false, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
+	}
+	if user.ID.Hex() != userId {
+		logger.Error().Interface(kName, s.iName).Str("userId", userId).Msg("User is not the owner of the requested settings")
+		return false, apperrors.Forbidden(apperrors.CodeForbidden, "Not permitted to "+action+" the requested settings")
 	}
 
 	// TODO: fix the authorization configs to work as intended
@@ -146,5 +158,5 @@ func (s *SettingsController) isAuthorizedForSettingsResource(c *fiber.Ctx, userI
 	//}
 
 	const can = true //TODO: fix this allow all clause
-	return can, fiber.StatusOK, nil, nil
+	return can, nil
 }
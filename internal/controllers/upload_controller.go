@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+)
+
+type IUploadController interface {
+	// CreateUpload Create a resumable upload
+	// (POST /media/uploads)
+	CreateUpload(ctx context.Context, request api.CreateUploadRequestObject) (api.CreateUploadResponseObject, error)
+
+	// GetUpload Get the progress of an upload
+	// (GET /media/uploads/{uploadId})
+	GetUpload(ctx context.Context, request api.GetUploadRequestObject) (api.GetUploadResponseObject, error)
+
+	// UploadChunk Upload a chunk
+	// (PATCH /media/uploads/{uploadId})
+	UploadChunk(ctx context.Context, request api.UploadChunkRequestObject) (api.UploadChunkResponseObject, error)
+
+	// DeleteUpload Cancel an upload
+	// (DELETE /media/uploads/{uploadId})
+	DeleteUpload(ctx context.Context, request api.DeleteUploadRequestObject) (api.DeleteUploadResponseObject, error)
+
+	// CompleteUpload Complete an upload
+	// (POST /media/uploads/{uploadId}/complete)
+	CompleteUpload(ctx context.Context, request api.CompleteUploadRequestObject) (api.CompleteUploadResponseObject, error)
+}
+
+type UploadController struct {
+	iName         string
+	logger        *zerolog.Logger
+	uploadService services.IResumableUploadService
+}
+
+func NewUploadController(log *zerolog.Logger, uploadSvc services.IResumableUploadService) IUploadController {
+	return &UploadController{
+		iName:         "UploadController",
+		logger:        log,
+		uploadService: uploadSvc,
+	}
+}
+
+func (u *UploadController) CreateUpload(ctx context.Context, request api.CreateUploadRequestObject) (api.CreateUploadResponseObject, error) {
+	const kName = "CreateUpload"
+	logger := logging.FromContext(ctx, u.logger)
+
+	user, err := u.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if request.Body == nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidBody, "Invalid request body")
+	}
+	upload, err := u.uploadService.CreateUpload(ctx, uploadFromCreateRequest(request.Body, user.ID.Hex()))
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("Failed to create upload")
+		return nil, apperrors.Wrap(err, "Failed to create upload")
+	}
+	return api.CreateUpload201JSONResponse(toAPIUpload(upload)), nil
+}
+
+func (u *UploadController) GetUpload(ctx context.Context, request api.GetUploadRequestObject) (api.GetUploadResponseObject, error) {
+	const kName = "GetUpload"
+	logger := logging.FromContext(ctx, u.logger)
+
+	user, err := u.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	upload, err := u.uploadService.GetUpload(ctx, user.ID.Hex(), request.UploadId)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("Failed to get upload")
+		return nil, apperrors.Wrap(err, "Failed to get upload")
+	}
+	return api.GetUpload200JSONResponse(toAPIUpload(upload)), nil
+}
+
+func (u *UploadController) UploadChunk(ctx context.Context, request api.UploadChunkRequestObject) (api.UploadChunkResponseObject, error) {
+	const kName = "UploadChunk"
+	logger := logging.FromContext(ctx, u.logger)
+
+	user, err := u.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	upload, err := u.uploadService.UploadChunk(ctx, user.ID.Hex(), request.UploadId, request.Params.Offset, request.Body)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("Failed to upload chunk")
+		return nil, apperrors.Wrap(err, "Failed to upload chunk")
+	}
+	return api.UploadChunk200JSONResponse(toAPIUpload(upload)), nil
+}
+
+func (u *UploadController) DeleteUpload(ctx context.Context, request api.DeleteUploadRequestObject) (api.DeleteUploadResponseObject, error) {
+	const kName = "DeleteUpload"
+	logger := logging.FromContext(ctx, u.logger)
+
+	user, err := u.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = u.uploadService.DeleteUpload(ctx, user.ID.Hex(), request.UploadId); err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("Failed to delete upload")
+		return nil, apperrors.Wrap(err, "Failed to delete upload")
+	}
+	return api.DeleteUpload204Response{}, nil
+}
+
+func (u *UploadController) CompleteUpload(ctx context.Context, request api.CompleteUploadRequestObject) (api.CompleteUploadResponseObject, error) {
+	const kName = "CompleteUpload"
+	logger := logging.FromContext(ctx, u.logger)
+
+	user, err := u.userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	media, err := u.uploadService.CompleteUpload(ctx, user.ID.Hex(), request.UploadId)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("Failed to complete upload")
+		return nil, apperrors.Wrap(err, "Failed to complete upload")
+	}
+	return api.CompleteUpload201JSONResponse(toAPIMedia(media)), nil
+}
+
+func (u *UploadController) userFromContext(ctx context.Context) (*models.User, error) {
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logging.FromContext(ctx, u.logger).Error().Interface("userFromContext", u.iName).Msg("Failed to get user object from context")
+		return nil, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
+	}
+	return user, nil
+}
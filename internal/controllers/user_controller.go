@@ -1,11 +1,13 @@
 package controllers
 
 import (
-	"errors"
-	"github.com/gofiber/fiber/v2"
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/api"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
 	"github.com/rs/zerolog"
 )
@@ -14,167 +16,157 @@ type IUserController interface {
 
 	// CreateUser Create a new user
 	// (POST /users)
-	CreateUser(c *fiber.Ctx) error
+	CreateUser(ctx context.Context, request api.CreateUserRequestObject) (api.CreateUserResponseObject, error)
 
 	// GetAllUsers Get all users
 	// (GET /users)
-	GetAllUsers(c *fiber.Ctx) error
+	GetAllUsers(ctx context.Context, request api.GetAllUsersRequestObject) (api.GetAllUsersResponseObject, error)
 
 	// GetUserById Get a user by ID
 	// (GET /users/{userId})
-	GetUserById(c *fiber.Ctx, userId string) error
+	GetUserById(ctx context.Context, request api.GetUserByIdRequestObject) (api.GetUserByIdResponseObject, error)
 
 	// UpdateUser Update a user
 	// (PUT /users/{userId})
-	UpdateUser(c *fiber.Ctx, userId string) error
+	UpdateUser(ctx context.Context, request api.UpdateUserRequestObject) (api.UpdateUserResponseObject, error)
 
 	// DeleteUser Delete a user
 	// (DELETE /users/{userId})
-	DeleteUser(c *fiber.Ctx, userId string) error
+	DeleteUser(ctx context.Context, request api.DeleteUserRequestObject) (api.DeleteUserResponseObject, error)
 }
 
 type UserController struct {
 	iName                string
 	log                  *zerolog.Logger
 	userService          services.IUserService
-	settingsService      services.ISettingsService
+	registrationService  services.IRegistrationService
 	authorizationService services.IAuthorizationService
 }
 
-//var _ api.ServerInterface = (*UserController)(nil)
-
-func NewUserController(log *zerolog.Logger, service services.IUserService, settingsSvc services.ISettingsService, authznSvc services.IAuthorizationService) IUserController {
+func NewUserController(log *zerolog.Logger, service services.IUserService, registrationSvc services.IRegistrationService, authznSvc services.IAuthorizationService) IUserController {
 	return &UserController{
 		iName:                "UserController",
 		userService:          service,
 		log:                  log,
-		settingsService:      settingsSvc,
+		registrationService:  registrationSvc,
 		authorizationService: authznSvc,
 	}
 }
 
-func (ctrl *UserController) CreateUser(c *fiber.Ctx) error {
+func (ctrl *UserController) CreateUser(ctx context.Context, request api.CreateUserRequestObject) (api.CreateUserResponseObject, error) {
 	const kName = "CreateUser"
-	user := new(models.User)
-	if err := c.BodyParser(user); err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to parse user body")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Invalid request body"))
-	}
+	logger := logging.FromContext(ctx, ctrl.log)
+	user := userFromCreateRequest(request.Body)
 
 	// Hash user password
 	hashedPassword, err := utils.HashPassword(user.Password)
 	if err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to hash password")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to create user"))
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to hash password")
+		return nil, apperrors.Wrap(err, "Failed to create user")
 	} else {
 		user.Password = hashedPassword
 	}
 
-	// Create User in DB
-	createdUser, err := ctrl.userService.CreateUser(c.Context(), user)
+	// Create the user with default settings
+	settings := models.GetSettingsDefaultsFromHeaders(utils.HeaderMapFromContext(ctx))
+	createdUser, err := ctrl.registrationService.Register(ctx, user, settings)
 	if err != nil {
 		msg := "Failed to create user"
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg(msg)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse(msg))
-	}
-
-	// Create Default UserSettings
-	settings := models.GetSettingsDefaultsFromHeaders(utils.GetHeaderMap(c)) // Using a method in models package.
-	settings.UserId = createdUser.ID
-
-	_, err = ctrl.settingsService.Create(c.Context(), settings)
-	if err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to create user settings")
-		// Handle settings creation error, perhaps delete the user that was created.
-		// Rollback user creation if settings creation fails.
-		deleteErr := ctrl.userService.DeleteUser(c.Context(), createdUser.ID.String())
-		if deleteErr != nil {
-			ctrl.log.Error().Interface(kName, ctrl.iName).Err(deleteErr).Msg("Failed to delete user after settings creation error")
-		}
-
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to create user settings"))
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Msg(msg)
+		return nil, apperrors.Wrap(err, msg)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse(createdUser.Sanitize(), "User created"))
+	return api.CreateUser201JSONResponse{Data: ptr(toAPIUser(createdUser)), Message: ptr("User created"), Success: ptr(true)}, nil
 }
 
-func (ctrl *UserController) GetAllUsers(c *fiber.Ctx) error {
+func (ctrl *UserController) GetAllUsers(ctx context.Context, _ api.GetAllUsersRequestObject) (api.GetAllUsersResponseObject, error) {
 	const kName = "GetAllUsers"
+	logger := logging.FromContext(ctx, ctrl.log)
 
 	//TODO: make sure the page and the limit come from the request and not solid values
-	users, err := ctrl.userService.ListUsers(c.Context(), 0, 50)
+	users, err := ctrl.userService.ListUsers(ctx, 0, 50)
 	if err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to get list of users")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to get list of user"))
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to get list of users")
+		return nil, apperrors.Wrap(err, "Failed to get list of user")
 	}
-	// sanitizeUsers
-	sanitizedUsers := make([]map[string]interface{}, 0)
-	for _, user := range users {
-		sanitizedUsers = append(sanitizedUsers, user.Sanitize())
-	}
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(sanitizedUsers, "Users Listed"))
+	return api.GetAllUsers200JSONResponse{Data: ptr(toAPIUsers(users)), Message: ptr("Users Listed"), Success: ptr(true)}, nil
 }
 
-func (ctrl *UserController) GetUserById(c *fiber.Ctx, userId string) error {
+func (ctrl *UserController) GetUserById(ctx context.Context, request api.GetUserByIdRequestObject) (api.GetUserByIdResponseObject, error) {
 	const kName = "GetUserById"
+	logger := logging.FromContext(ctx, ctrl.log)
+	userId := request.UserId
 
-	can, status, response, err := ctrl.isAuthorizedForUsersResource(c, userId, services.ActionRead)
+	can, err := ctrl.isAuthorizedForUsersResource(ctx, userId, services.ActionRead)
 	if err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to authorize for Users-Resource")
-		return c.Status(status).JSON(response)
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to authorize for Users-Resource")
+		return nil, err
 	}
 	if can {
-		user, err := ctrl.userService.GetUserByID(c.Context(), userId)
+		user, err := ctrl.userService.GetUserByID(ctx, userId)
 		if err != nil {
-			ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", userId).Msg("Failed to get user")
-			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse("Failed to get user"))
+			logger.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", userId).Msg("Failed to get user")
+			return nil, apperrors.Wrap(err, "Failed to get user")
 		}
-		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(user.Sanitize(), "User found")) // Return the user object directly
+		return api.GetUserById200JSONResponse{UserSuccessJSONResponse: api.UserSuccessJSONResponse{
+			Data: ptr(toAPIUser(user)), Message: ptr("User found"), Success: ptr(true),
+		}}, nil
 	} else {
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Failed to get user, not permitted"))
+		return nil, apperrors.Forbidden(apperrors.CodeForbidden, "Failed to get user, not permitted")
 	}
-
-	//ctrl.log.Error().Interface(kName, ctrl.iName).Msg("Failed to get user-by-id due to unexpected error")
-	//return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to get user due to unexpected error"))
-
 }
 
-func (ctrl *UserController) UpdateUser(c *fiber.Ctx, userId string) error {
+func (ctrl *UserController) UpdateUser(ctx context.Context, request api.UpdateUserRequestObject) (api.UpdateUserResponseObject, error) {
 	const kName = "UpdateUser"
-	user := new(models.User)
-	if err := c.BodyParser(user); err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to parse user body")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse("Invalid request body"))
+	logger := logging.FromContext(ctx, ctrl.log)
+	userId := request.UserId
+
+	user, err := ctrl.userService.GetUserByID(ctx, userId)
+	if err != nil {
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", userId).Msg("Failed to get user to update")
+		return nil, apperrors.Wrap(err, "Failed to update user")
+	}
+	applyUserUpdate(user, request.Body)
+	if request.Body.Password != nil {
+		if user.Password, err = utils.HashPassword(*request.Body.Password); err != nil {
+			logger.Error().Interface(kName, ctrl.iName).Err(err).Msg("Failed to hash password")
+			return nil, apperrors.Wrap(err, "Failed to update user")
+		}
 	}
 
-	updatedUser, err := ctrl.userService.UpdateUser(c.Context(), user)
+	updatedUser, err := ctrl.userService.UpdateUser(ctx, user)
 	if err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", userId).Msg("Failed to update user")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to update user"))
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", userId).Msg("Failed to update user")
+		return nil, apperrors.Wrap(err, "Failed to update user")
 	}
 
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(updatedUser.Sanitize(), "User updated"))
+	return api.UpdateUser200JSONResponse{Data: ptr(toAPIUser(updatedUser)), Message: ptr("User updated"), Success: ptr(true)}, nil
 }
 
-func (ctrl *UserController) DeleteUser(c *fiber.Ctx, userId string) error {
+func (ctrl *UserController) DeleteUser(ctx context.Context, request api.DeleteUserRequestObject) (api.DeleteUserResponseObject, error) {
 	const kName = "DeleteUser"
-	err := ctrl.userService.DeleteUser(c.Context(), userId)
+	logger := logging.FromContext(ctx, ctrl.log)
+	err := ctrl.userService.DeleteUser(ctx, request.UserId)
 	if err != nil {
-		ctrl.log.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", userId).Msg("Failed to delete user")
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Failed to delete user"))
+		logger.Error().Interface(kName, ctrl.iName).Err(err).Str("userID", request.UserId).Msg("Failed to delete user")
+		return nil, apperrors.Wrap(err, "Failed to delete user")
 	}
 
-	return c.Status(fiber.StatusNoContent).JSON(utils.SuccessResponse(nil, "User deleted"))
+	return api.DeleteUser204Response{}, nil
 }
 
-func (ctrl *UserController) isAuthorizedForUsersResource(c *fiber.Ctx, userId string, action string) (bool, int, fiber.Map, error) {
+func (ctrl *UserController) isAuthorizedForUsersResource(ctx context.Context, userId string, action string) (bool, error) {
 	const kName = "isAuthorizedForUsersResource"
+	logger := logging.FromContext(ctx, ctrl.log)
 
-	user, ok := c.Context().Value(middleware.UserObjectContextKey).(*models.User)
-	if !ok || user.ID.Hex() != userId {
-		msg := "Failed to get user object from context"
-		ctrl.log.Error().Interface(kName, ctrl.iName).Msg(msg)
-		return false, fiber.StatusInternalServerError, utils.ErrorResponse("Could not determine user context"), errors.New(msg)
+	user, ok := middleware.UserFromContext(ctx)
+	if !ok {
+		logger.Error().Interface(kName, ctrl.iName).Msg("Failed to get user object from context")
+		return false, apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Could not determine user context")
+	}
+	if user.ID.Hex() != userId {
+		logger.Error().Interface(kName, ctrl.iName).Str("userId", userId).Msg("User is not the owner of the requested user resource")
+		return false, apperrors.Forbidden(apperrors.CodeForbidden, "Not permitted to "+action+" the requested user")
 	}
 
 	// TODO: fix the authorization configs to work as intended
@@ -198,5 +190,5 @@ func (ctrl *UserController) isAuthorizedForUsersResource(c *fiber.Ctx, userId st
 	//}
 
 	const can = true //TODO: fix this allow all clause
-	return can, fiber.StatusOK, nil, nil
+	return can, nil
 }
@@ -0,0 +1,18 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/services"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// NewHealthChecker pings the primary, the server is not ready while MongoDB is unreachable
+func NewHealthChecker(client *mongo.Client) services.IHealthChecker {
+	return services.HealthCheckerFunc{
+		CheckName: "mongodb",
+		Fn: func(ctx context.Context) error {
+			return client.Ping(ctx, readpref.Primary())
+		},
+	}
+}
@@ -20,6 +20,14 @@ func CreateInitialIndexes(db *mongo.Database, log *zerolog.Logger) error {
 		log.Error().Str("indexes", "CreateInitialIndexes").Err(err).Msg("Failed to create indexes for settings collection")
 		return err
 	}
+	if err := createIndexesForChatKeys(db, log); err != nil {
+		log.Error().Str("indexes", "CreateInitialIndexes").Err(err).Msg("Failed to create indexes for chat_keys collection")
+		return err
+	}
+	if err := createIndexesForDeviceKeys(db, log); err != nil {
+		log.Error().Str("indexes", "CreateInitialIndexes").Err(err).Msg("Failed to create indexes for device keys collections")
+		return err
+	}
 	return nil
 }
 
@@ -59,4 +67,34 @@ func createIndexesForSettings(db *mongo.Database, log *zerolog.Logger) error {
 	return nil
 }
 
+func createIndexesForChatKeys(db *mongo.Database, log *zerolog.Logger) error {
+	const loggerFunctionName = "createIndexesForChatKeys"
+	k := models.ChatKey{}
+	err := k.CreateUniqueIndexes(db)
+	if err != nil {
+		log.Error().Str("indexes", loggerFunctionName).Err(err).Msg("Failed to create indexes for chat_keys collection")
+		return err
+	}
+	log.Info().Str("indexes", loggerFunctionName).Msg("Created indexes for chat_keys collection")
+	return nil
+}
+
+func createIndexesForDeviceKeys(db *mongo.Database, log *zerolog.Logger) error {
+	const loggerFunctionName = "createIndexesForDeviceKeys"
+	d := models.DeviceKey{}
+	err := d.CreateUniqueIndexes(db)
+	if err != nil {
+		log.Error().Str("indexes", loggerFunctionName).Err(err).Msg("Failed to create indexes for device_keys collection")
+		return err
+	}
+	o := models.OneTimePreKey{}
+	err = o.CreateUniqueIndexes(db)
+	if err != nil {
+		log.Error().Str("indexes", loggerFunctionName).Err(err).Msg("Failed to create indexes for one_time_prekeys collection")
+		return err
+	}
+	log.Info().Str("indexes", loggerFunctionName).Msg("Created indexes for device keys collections")
+	return nil
+}
+
 // Add more index creation functions for other collections (e.g., Settings, Authentication)
@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Usage describes the arguments understood by RunCommand
+const Usage = "migrate up [version] | down [steps] | status"
+
+// ErrUsage is returned by RunCommand when the arguments do not match Usage
+var ErrUsage = errors.New("usage: " + Usage)
+
+// RunCommand runs `up [version]`, `down [steps]` or `status`, shared by the server's migrate sub command & telkoctl.
+// Results are written to out.
+func RunCommand(ctx context.Context, migrator *Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "up":
+		target, err := commandArg(args, 0)
+		if err != nil {
+			return err
+		}
+		count, err := migrator.Up(ctx, target)
+		_, _ = fmt.Fprintf(out, "applied %d migration(s)\n", count)
+		return err
+	case "down":
+		steps, err := commandArg(args, 1)
+		if err != nil {
+			return err
+		}
+		count, err := migrator.Down(ctx, steps)
+		_, _ = fmt.Fprintf(out, "rolled back %d migration(s)\n", count)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tAPPLIED AT\tDESCRIPTION")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, appliedAt, status.Description)
+		}
+		return w.Flush()
+	default:
+		return ErrUsage
+	}
+}
+
+// commandArg parses the optional positive integer after the sub command, falling back to def
+func commandArg(args []string, def int) (int, error) {
+	if len(args) < 2 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n <= 0 || len(args) > 2 {
+		return 0, ErrUsage
+	}
+	return n, nil
+}
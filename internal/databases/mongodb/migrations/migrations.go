@@ -0,0 +1,322 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	internalmongodb "github.com/mcsamuelshoko/telko-moment-server/internal/databases/mongodb"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All returns the migrations of the application database.
+// New migrations are appended with the next version, applied ones must never be edited.
+func All(log *zerolog.Logger) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create initial indexes",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// creating an index that already exists with the same options is a no-op
+				return internalmongodb.CreateInitialIndexes(db, log)
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{
+					"users":            {"unique_username_hash", "unique_email_and_phone_number_hash"},
+					"authentications":  {"unique_user_id", "unique_refresh_token_hash"},
+					"settings":         {"unique_user_id"},
+					"chat_keys":        {"unique_chat_id"},
+					"device_keys":      {"unique_user_id_device_id"},
+					"one_time_prekeys": {"unique_user_id_device_id_key_id"},
+				})
+			},
+		},
+		{
+			Version:     2,
+			Description: "rename users.UsernameHash to usernameHash",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return renameField(ctx, db.Collection("users"), "UsernameHash", "usernameHash")
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return renameField(ctx, db.Collection("users"), "usernameHash", "UsernameHash")
+			},
+		},
+		{
+			Version:     3,
+			Description: "index only the authentications that hold a refresh token",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// registration creates the session before its first refresh token, a full unique index
+				// would let only one session at a time lack the token
+				return replaceIndex(ctx, db.Collection("authentications"), mongo.IndexModel{
+					Keys: bson.D{{Key: "refreshTokenHash", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("unique_refresh_token_hash").
+						SetPartialFilterExpression(bson.M{"refreshTokenHash": bson.M{"$type": "string"}}),
+				})
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return replaceIndex(ctx, db.Collection("authentications"), mongo.IndexModel{
+					Keys:    bson.D{{Key: "refreshTokenHash", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("unique_refresh_token_hash"),
+				})
+			},
+		},
+		{
+			Version:     4,
+			Description: "index resumable uploads by expiry",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// not a TTL index, the expired uploads' chunks must be deleted from the blob store with them
+				_, err := db.Collection("uploads").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+					Options: options.Index().SetName("expires_at"),
+				})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{"uploads": {"expires_at"}})
+			},
+		},
+		{
+			Version:     5,
+			Description: "index deduplicated media blobs by owner & hash & by references",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// the unique index settles concurrent uploads of the same file by the same owner
+				_, err := db.Collection("blobs").Indexes().CreateMany(ctx, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "ownerId", Value: 1}, {Key: "hash", Value: 1}},
+						Options: options.Index().SetUnique(true).SetName("unique_owner_id_hash"),
+					},
+					{
+						Keys:    bson.D{{Key: "refCount", Value: 1}, {Key: "updatedAt", Value: 1}},
+						Options: options.Index().SetName("ref_count_updated_at"),
+					},
+				})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{"blobs": {"unique_owner_id_hash", "ref_count_updated_at"}})
+			},
+		},
+		{
+			Version:     6,
+			Description: "index the chat galleries & attach sent media to their messages",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// only media attached to a message are listed, so the index skips unsent uploads
+				_, err := db.Collection("medias").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys: bson.D{{Key: "chatId", Value: 1}, {Key: "mediaType", Value: 1}, {Key: "_id", Value: -1}},
+					Options: options.Index().SetName("chat_id_media_type_id").
+						SetPartialFilterExpression(bson.M{"messageId": bson.M{"$exists": true}}),
+				})
+				if err != nil {
+					return err
+				}
+				// the content of sent messages is encrypted, links are only found in messages sent from now on
+				_, err = db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys: bson.D{{Key: "chatId", Value: 1}, {Key: "_id", Value: -1}},
+					Options: options.Index().SetName("chat_id_id_has_links").
+						SetPartialFilterExpression(bson.M{"hasLinks": true}),
+				})
+				if err != nil {
+					return err
+				}
+				return attachSentMedia(ctx, db)
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				// the attached message ids are kept, the application sets them too
+				return dropIndexes(ctx, db, map[string][]string{
+					"medias":   {"chat_id_media_type_id"},
+					"messages": {"chat_id_id_has_links"},
+				})
+			},
+		},
+		{
+			Version:     7,
+			Description: "index the media quarantined until scanned for malware",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// media are only pending for a moment, the index holds the scanner's queue & stays small
+				_, err := db.Collection("medias").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys: bson.D{{Key: "scanStatus", Value: 1}, {Key: "_id", Value: 1}},
+					Options: options.Index().SetName("scan_status_pending_id").
+						SetPartialFilterExpression(bson.M{"scanStatus": "pending"}),
+				})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{"medias": {"scan_status_pending_id"}})
+			},
+		},
+		{
+			Version:     8,
+			Description: "index storage accounting & active subscriptions",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// covers the sum of the storage of a sender by chat & media type
+				_, err := db.Collection("medias").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "senderId", Value: 1}, {Key: "chatId", Value: 1}, {Key: "mediaType", Value: 1}, {Key: "fileSize", Value: 1}},
+					Options: options.Index().SetName("sender_id_chat_id_media_type_file_size"),
+				})
+				if err != nil {
+					return err
+				}
+				_, err = db.Collection("subscriptions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "status", Value: 1}, {Key: "startDate", Value: -1}},
+						Options: options.Index().SetName("user_id_status_start_date"),
+					},
+					{
+						Keys: bson.D{{Key: "enterpriseId", Value: 1}, {Key: "planType", Value: 1}, {Key: "status", Value: 1}},
+						Options: options.Index().SetName("enterprise_id_plan_type_status").
+							SetPartialFilterExpression(bson.M{"enterpriseId": bson.M{"$exists": true}}),
+					},
+				})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{
+					"medias":        {"sender_id_chat_id_media_type_file_size"},
+					"subscriptions": {"user_id_status_start_date", "enterprise_id_plan_type_status"},
+				})
+			},
+		},
+		{
+			Version:     9,
+			Description: "one played receipt per voice note & user",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("played_receipts").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "messageId", Value: 1}, {Key: "userId", Value: 1}},
+					Options: options.Index().SetName("unique_message_id_user_id").SetUnique(true),
+				})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{"played_receipts": {"unique_message_id_user_id"}})
+			},
+		},
+		{
+			Version:     10,
+			Description: "tag one-time prekeys with the identity key of their device",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				// untagged prekeys are never handed out, they must belong to the identity their device has now
+				return tagPreKeys(ctx, db)
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("one_time_prekeys").UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"identityKey": ""}})
+				return err
+			},
+		},
+		{
+			Version:     11,
+			Description: "expire ended rate limit windows",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("rate_limits").Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+					Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+				})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db, map[string][]string{"rate_limits": {"expires_at_ttl"}})
+			},
+		},
+	}
+}
+
+// replaceIndex drops the index named like index, if any, & creates index
+func replaceIndex(ctx context.Context, collection *mongo.Collection, index mongo.IndexModel) error {
+	_, err := collection.Indexes().DropOne(ctx, *index.Options.Name)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	_, err = collection.Indexes().CreateOne(ctx, index)
+	return err
+}
+
+// dropIndexes drops the named indexes per collection, indexes or collections that do not exist are skipped
+func dropIndexes(ctx context.Context, db *mongo.Database, indexes map[string][]string) error {
+	for collection, names := range indexes {
+		for _, name := range names {
+			_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+			if err != nil && !isNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renameField renames from to to on every document still carrying from
+func renameField(ctx context.Context, collection *mongo.Collection, from, to string) error {
+	_, err := collection.UpdateMany(ctx,
+		bson.M{from: bson.M{"$exists": true}},
+		bson.M{"$rename": bson.M{from: to}},
+	)
+	return err
+}
+
+// attachSentMedia sets the message of the media that messages attached, oldest message first so forwarded media keep
+// the message they were first sent with
+func attachSentMedia(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection("messages").Find(ctx,
+		bson.M{"mediaIds.0": bson.M{"$exists": true}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetProjection(bson.M{"mediaIds": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var message struct {
+			ID       primitive.ObjectID   `bson:"_id"`
+			MediaIDs []primitive.ObjectID `bson:"mediaIds"`
+		}
+		if err = cursor.Decode(&message); err != nil {
+			return err
+		}
+		_, err = db.Collection("medias").UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": message.MediaIDs}, "messageId": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"messageId": message.ID}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// tagPreKeys sets the identity key of each device on its one-time prekeys that have none
+func tagPreKeys(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection("device_keys").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"userId": 1, "deviceId": 1, "identityKey": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var device struct {
+			UserID      primitive.ObjectID `bson:"userId"`
+			DeviceID    string             `bson:"deviceId"`
+			IdentityKey string             `bson:"identityKey"`
+		}
+		if err = cursor.Decode(&device); err != nil {
+			return err
+		}
+		_, err = db.Collection("one_time_prekeys").UpdateMany(ctx,
+			bson.M{"userId": device.UserID, "deviceId": device.DeviceID, "identityKey": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"identityKey": device.IdentityKey}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// isNotFound reports the IndexNotFound & NamespaceNotFound server errors
+func isNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == 27 || cmdErr.Code == 26
+	}
+	return false
+}
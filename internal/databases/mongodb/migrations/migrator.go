@@ -0,0 +1,285 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	// CollectionName holds one document per applied migration, keyed by version
+	CollectionName = "migrations"
+	// LockCollectionName holds the lock taken while migrations are applied or rolled back
+	LockCollectionName = "migrations_lock"
+
+	lockID            = "lock"
+	lockTTL           = 10 * time.Minute // a crashed instance releases the lock after this long
+	lockRenewInterval = lockTTL / 5      // the holder extends the lock this often while it migrates
+	lockPollInterval  = time.Second
+)
+
+// ErrLocked is returned when another instance holds the lock until the context is done
+var ErrLocked = errors.New("migrations are locked by another instance")
+
+// errLockLost cancels the migrations when the lock expired & another instance may have taken it
+var errLockLost = errors.New("migrations lock was lost")
+
+// Migration is a versioned, reversible change of the database, applied in ascending version order.
+// A migration is recorded once its Up step returns, so both steps must be safe to run again after a crash.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// Status of a known migration, AppliedAt is nil while it is pending
+type Status struct {
+	Version     int
+	Description string
+	AppliedAt   *time.Time
+}
+
+// record is the document stored in the migrations collection
+type record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+type Migrator struct {
+	iName      string
+	log        *zerolog.Logger
+	db         *mongo.Database
+	migrations []Migration
+	owner      string // identifies this instance in the lock document
+}
+
+// NewMigrator sorts the migrations by version, versions must be positive & unique
+func NewMigrator(log *zerolog.Logger, db *mongo.Database, migrations []Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, migration := range sorted {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has a non positive version %d", migration.Description, migration.Version)
+		}
+		if i > 0 && sorted[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migration version %d is used twice", migration.Version)
+		}
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("migration %d has no up or down step", migration.Version)
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	return &Migrator{
+		iName:      "Migrator",
+		log:        log,
+		db:         db,
+		migrations: sorted,
+		owner:      hostname + "/" + uuid.New().String(),
+	}, nil
+}
+
+// Status lists every known migration with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Description: migration.Description}
+		if rec, ok := applied[migration.Version]; ok {
+			appliedAt := rec.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies the pending migrations up to & including target, a target of 0 applies all of them.
+// It returns the number of migrations applied.
+func (m *Migrator) Up(ctx context.Context, target int) (int, error) {
+	const kName = "Up"
+
+	count := 0
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if target > 0 && migration.Version > target {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			m.log.Info().Interface(kName, m.iName).Int("version", migration.Version).Str("description", migration.Description).Msg("applying migration")
+			if err = migration.Up(ctx, m.db); err != nil {
+				return fmt.Errorf("migration %d up: %w", migration.Version, err)
+			}
+			rec := record{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now().UTC()}
+			if _, err = m.db.Collection(CollectionName).InsertOne(ctx, rec); err != nil {
+				return fmt.Errorf("record migration %d: %w", migration.Version, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+// It returns the number of migrations rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	const kName = "Down"
+
+	count := 0
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			m.log.Info().Interface(kName, m.iName).Int("version", migration.Version).Str("description", migration.Description).Msg("rolling back migration")
+			if err = migration.Down(ctx, m.db); err != nil {
+				return fmt.Errorf("migration %d down: %w", migration.Version, err)
+			}
+			if _, err = m.db.Collection(CollectionName).DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+				return fmt.Errorf("unrecord migration %d: %w", migration.Version, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// applied returns the recorded migrations by version
+func (m *Migrator) applied(ctx context.Context) (map[int]record, error) {
+	cursor, err := m.db.Collection(CollectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// withLock runs fn while holding the lock, waiting for other instances to release it until ctx is done.
+// The lock is renewed while fn runs, the context of fn is cancelled when it is lost.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	const kName = "withLock"
+
+	for {
+		err := m.acquireLock(ctx)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrLocked) {
+			return err
+		}
+		m.log.Info().Interface(kName, m.iName).Msg("waiting for another instance to finish migrating")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrLocked, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	defer func() {
+		// released even when ctx is cancelled, otherwise other instances wait for the lock to expire
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := m.db.Collection(LockCollectionName).DeleteOne(releaseCtx, bson.M{"_id": lockID, "owner": m.owner})
+		if err != nil {
+			m.log.Error().Interface(kName, m.iName).Err(err).Msg("failed to release migrations lock, it expires on its own")
+		}
+	}()
+
+	lockCtx, lost := context.WithCancelCause(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		m.renewLock(lockCtx, lost)
+	}()
+	defer func() {
+		lost(nil)
+		<-renewed
+	}()
+	err := fn(lockCtx)
+	if err != nil && errors.Is(context.Cause(lockCtx), errLockLost) {
+		return fmt.Errorf("%w: %w", errLockLost, err)
+	}
+	return err
+}
+
+// renewLock extends the lock every lockRenewInterval until ctx is done, calling lost once another instance
+// may have taken it
+func (m *Migrator) renewLock(ctx context.Context, lost context.CancelCauseFunc) {
+	const kName = "renewLock"
+
+	ticker := time.NewTicker(lockRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		result, err := m.db.Collection(LockCollectionName).UpdateOne(ctx,
+			bson.M{"_id": lockID, "owner": m.owner},
+			bson.M{"$set": bson.M{"expiresAt": time.Now().UTC().Add(lockTTL)}},
+		)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// the lock is still held until it expires, the next tick tries again
+			m.log.Warn().Interface(kName, m.iName).Err(err).Msg("failed to renew migrations lock")
+			continue
+		}
+		if result.MatchedCount == 0 {
+			m.log.Error().Interface(kName, m.iName).Msg("migrations lock expired, stopping the migrations")
+			lost(errLockLost)
+			return
+		}
+	}
+}
+
+// acquireLock takes the lock when it is free or expired, the unique _id makes the upsert fail while it is held
+func (m *Migrator) acquireLock(ctx context.Context) error {
+	now := time.Now().UTC()
+	_, err := m.db.Collection(LockCollectionName).UpdateOne(ctx,
+		bson.M{"_id": lockID, "expiresAt": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"owner": m.owner, "lockedAt": now, "expiresAt": now.Add(lockTTL)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	}
+	return err
+}
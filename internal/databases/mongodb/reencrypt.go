@@ -0,0 +1,170 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// KeyRing pairs the master encryption key & the search key of one key generation
+type KeyRing struct {
+	Encryption services.IEncryptionService
+	SearchKey  services.ISearchKeyService
+}
+
+// ReEncryptReport counts the documents seen by ReEncrypt
+type ReEncryptReport struct {
+	Users          int      // users rewritten with the new keys
+	ChatKeys       int      // chat data keys re-wrapped with the new master key
+	AlreadyCurrent int      // documents that already decrypt with the new keys
+	Skipped        []string // "<collection>/<id>: reason" for documents decrypting with neither key
+}
+
+// ReEncrypt rewrites the users' encrypted & hashed fields and re-wraps the chat data keys from the old keys to the new ones.
+// Messages are encrypted with the chat data keys, which do not change, so they are left untouched.
+// Refresh tokens are only stored as search key hashes: rotating the search key signs every user out.
+//
+// AES-CFB decrypts with any key, a document is only rewritten once it is proven to decrypt with the old keys
+// (its username or email hashes back to the stored search key, a chat key unwraps to a valid AES key),
+// which makes running it again after a partial failure safe. With dryRun nothing is written.
+func ReEncrypt(ctx context.Context, db *mongo.Database, log *zerolog.Logger, from, to KeyRing, dryRun bool) (*ReEncryptReport, error) {
+	report := &ReEncryptReport{}
+	if err := reEncryptUsers(ctx, db.Collection("users"), log, from, to, dryRun, report); err != nil {
+		return report, err
+	}
+	if err := reEncryptChatKeys(ctx, db.Collection("chat_keys"), log, from, to, dryRun, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func reEncryptUsers(ctx context.Context, collection *mongo.Collection, log *zerolog.Logger, from, to KeyRing, dryRun bool, report *ReEncryptReport) error {
+	const loggerFunctionName = "reEncryptUsers"
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var stored models.User
+		if err = cursor.Decode(&stored); err != nil {
+			return err
+		}
+
+		user := stored
+		if !userDecryptsWith(&user, from) {
+			current := stored
+			if userDecryptsWith(&current, to) {
+				report.AlreadyCurrent++
+				continue
+			}
+			report.Skipped = append(report.Skipped, fmt.Sprintf("users/%s: decrypts with neither key", stored.ID.Hex()))
+			continue
+		}
+
+		if err = user.HashFields(to.SearchKey); err != nil {
+			return fmt.Errorf("hash user %s: %w", stored.ID.Hex(), err)
+		}
+		if err = user.EncryptFields(to.Encryption); err != nil {
+			return fmt.Errorf("encrypt user %s: %w", stored.ID.Hex(), err)
+		}
+		if !dryRun {
+			if _, err = collection.UpdateOne(ctx, bson.M{"_id": stored.ID}, bson.M{"$set": user}); err != nil {
+				return fmt.Errorf("update user %s: %w", stored.ID.Hex(), err)
+			}
+		}
+		report.Users++
+	}
+	if err = cursor.Err(); err != nil {
+		return err
+	}
+
+	log.Info().Str("reencrypt", loggerFunctionName).Int("users", report.Users).Bool("dryRun", dryRun).Msg("Re-encrypted users")
+	return nil
+}
+
+// userDecryptsWith decrypts user in place and reports whether a decrypted search field hashes back to its stored hash
+func userDecryptsWith(user *models.User, keys KeyRing) bool {
+	usernameHash, emailHash := user.UsernameHash, user.EmailHash
+	if usernameHash == "" && emailHash == "" {
+		// nothing to verify against
+		return false
+	}
+	if err := user.DecryptFields(keys.Encryption); err != nil {
+		return false
+	}
+	if usernameHash != "" {
+		hashed, err := keys.SearchKey.GenerateSearchKey(user.Username)
+		return err == nil && hashed == usernameHash
+	}
+	hashed, err := keys.SearchKey.GenerateSearchKey(user.Email)
+	return err == nil && hashed == emailHash
+}
+
+func reEncryptChatKeys(ctx context.Context, collection *mongo.Collection, log *zerolog.Logger, from, to KeyRing, dryRun bool, report *ReEncryptReport) error {
+	const loggerFunctionName = "reEncryptChatKeys"
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var stored models.ChatKey
+		if err = cursor.Decode(&stored); err != nil {
+			return err
+		}
+
+		chatKey := stored
+		if !chatKeyDecryptsWith(&chatKey, from) {
+			current := stored
+			if chatKeyDecryptsWith(&current, to) {
+				report.AlreadyCurrent++
+				continue
+			}
+			report.Skipped = append(report.Skipped, fmt.Sprintf("chat_keys/%s: decrypts with neither key", stored.ID.Hex()))
+			continue
+		}
+
+		if err = chatKey.EncryptFields(to.Encryption); err != nil {
+			return fmt.Errorf("encrypt chat key %s: %w", stored.ID.Hex(), err)
+		}
+		if !dryRun {
+			if _, err = collection.UpdateOne(ctx, bson.M{"_id": stored.ID}, bson.M{"$set": bson.M{"key": chatKey.Key}}); err != nil {
+				return fmt.Errorf("update chat key %s: %w", stored.ID.Hex(), err)
+			}
+		}
+		report.ChatKeys++
+	}
+	if err = cursor.Err(); err != nil {
+		return err
+	}
+
+	log.Info().Str("reencrypt", loggerFunctionName).Int("chatKeys", report.ChatKeys).Bool("dryRun", dryRun).Msg("Re-wrapped chat keys")
+	return nil
+}
+
+// chatKeyDecryptsWith unwraps the chat key in place and reports whether it is a hex encoded AES key
+func chatKeyDecryptsWith(chatKey *models.ChatKey, keys KeyRing) bool {
+	if err := chatKey.DecryptFields(keys.Encryption); err != nil {
+		return false
+	}
+	key, err := hex.DecodeString(chatKey.Key)
+	if err != nil {
+		return false
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return true
+	default:
+		return false
+	}
+}
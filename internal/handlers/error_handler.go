@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"github.com/rs/zerolog"
+)
+
+// NewErrorHandler is the central fiber.ErrorHandler: errors returned by handlers & middleware are mapped onto
+// an HTTP status and a response carrying the stable `code` field and, for validation errors, per-field `details`
+func NewErrorHandler(log *zerolog.Logger) fiber.ErrorHandler {
+	const kName = "ErrorHandler"
+	const iName = "ErrorHandler"
+
+	return func(c *fiber.Ctx, err error) error {
+		logger := logging.FromContext(c.UserContext(), log)
+
+		appErr := toAppError(err)
+		status := appErr.HTTPStatus()
+		if status >= fiber.StatusInternalServerError {
+			logger.Error().Interface(kName, iName).Err(err).Str("code", appErr.Code).Int("status", status).Msg("request failed")
+		} else {
+			logger.Debug().Interface(kName, iName).Err(err).Str("code", appErr.Code).Int("status", status).Msg("request rejected")
+		}
+
+		if len(appErr.Fields) > 0 {
+			return c.Status(status).JSON(utils.CodedErrorResponse(appErr.Code, appErr.Message, appErr.Fields))
+		}
+		return c.Status(status).JSON(utils.CodedErrorResponse(appErr.Code, appErr.Message))
+	}
+}
+
+// toAppError maps fiber's own errors (unknown route, body too large...) onto the taxonomy
+func toAppError(err error) *apperrors.Error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case fiber.StatusNotFound:
+			return apperrors.NotFound(apperrors.CodeRouteNotFound, fiberErr.Message)
+		case fiber.StatusMethodNotAllowed:
+			return apperrors.Validation(apperrors.CodeMethodNotAllow, fiberErr.Message).WithStatus(fiberErr.Code)
+		case fiber.StatusRequestEntityTooLarge:
+			return apperrors.Validation(apperrors.CodeTooLarge, fiberErr.Message).WithStatus(fiberErr.Code)
+		case fiber.StatusTooManyRequests:
+			return apperrors.RateLimited(apperrors.CodeRateLimited, fiberErr.Message)
+		case fiber.StatusUnauthorized:
+			return apperrors.Unauthorized(apperrors.CodeUnauthenticated, fiberErr.Message)
+		case fiber.StatusForbidden:
+			return apperrors.Forbidden(apperrors.CodeForbidden, fiberErr.Message)
+		case fiber.StatusServiceUnavailable:
+			return apperrors.Unavailable(apperrors.CodeUnavailable, fiberErr.Message)
+		}
+		if fiberErr.Code < fiber.StatusInternalServerError {
+			return apperrors.Validation(apperrors.CodeBadRequest, fiberErr.Message).WithStatus(fiberErr.Code)
+		}
+		return apperrors.Internal(apperrors.CodeInternal, "Internal server error").WithErr(err)
+	}
+	return apperrors.From(err)
+}
@@ -1,11 +1,17 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
-	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ContextKey is a custom type for context keys
@@ -30,48 +36,72 @@ func NewAuthContextMiddleware(log *zerolog.Logger, userRepo repository.IUserRepo
 	}
 }
 
-func (acm *AuthContextMiddleware) AddUserContext() fiber.Handler {
-	const kName = "AddUserContext"
+// UserFromContext returns the authenticated user stored by the middleware, strict server handlers only receive a context.Context
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(UserObjectContextKey).(*models.User)
+	return user, ok
+}
 
+func (acm *AuthContextMiddleware) AddUserContext() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		acm.logger.Debug().Interface(kName, acm.iName).Msg("adding user context")
-
-		userIDStr, ok := c.Context().Value(UserIDStrContextKey).(string)
-		if !ok || userIDStr == "" {
-			//http.Error(w, "Unauthorized: Missing user identifier", http.StatusUnauthorized)
-			acm.logger.Error().Interface(kName, acm.iName).Msg("Invalid user id from context")
-			return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Missing user identifier"))
+		if err := acm.AttachUser(c); err != nil {
+			return err
 		}
+		return c.Next()
+	}
+}
 
-		//userID, err := primitive.ObjectIDFromHex(userIDStr)
-		//if err != nil {
-		//	//http.Error(w, "Unauthorized: Invalid user identifier", http.StatusUnauthorized)
-		//	acm.logger.Error().Err(err).Msg("Invalid user id hex() string value from context")
-		//	return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid user id hex string"))
-		//}
+// AttachUser is the body of AddUserContext without calling the next handler, for operations authenticated by the strict server.
+func (acm *AuthContextMiddleware) AttachUser(c *fiber.Ctx) error {
+	const kName = "AttachUser"
 
-		// Fetch the user object
-		user, err := acm.userRepo.GetByID(c.Context(), userIDStr)
-		if err != nil {
-			if errors.Is(err, err) { // Use specific errors
-				//http.Error(w, "Unauthorized: User not found", http.StatusUnauthorized)
-				acm.logger.Error().Interface(kName, acm.iName).Err(err).Msg("Unauthorized: User not found")
-				return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("User not found"))
-			} else {
-				// Log the actual error
-				//http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-				acm.logger.Error().Interface(kName, acm.iName).Err(err).Msg("Error while getting user")
-				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse("Error while getting user"))
-			}
-		}
+	logger := logging.FromContext(c.UserContext(), acm.logger)
+	logger.Debug().Interface(kName, acm.iName).Msg("adding user context")
 
-		// Add user object and validated userID to context
-		//ctx := context.WithValue(r.Context(), UserObjectContextKey, user)
-		//ctx = context.WithValue(ctx, UserIDContextKey, user.ID) // Add the ObjectID
-		c.Locals(UserObjectContextKey, user)
-		//c.Locals(UserIDContextKey, userID)
+	userIDStr, ok := c.Context().Value(UserIDStrContextKey).(string)
+	if !ok || userIDStr == "" {
+		//http.Error(w, "Unauthorized: Missing user identifier", http.StatusUnauthorized)
+		logger.Error().Interface(kName, acm.iName).Msg("Invalid user id from context")
+		return apperrors.Unauthorized(apperrors.CodeMissingUserCtx, "Missing user identifier")
+	}
 
-		//next.ServeHTTP(w, r.WithContext(ctx))
-		return c.Next()
+	//userID, err := primitive.ObjectIDFromHex(userIDStr)
+	//if err != nil {
+	//	//http.Error(w, "Unauthorized: Invalid user identifier", http.StatusUnauthorized)
+	//	acm.logger.Error().Err(err).Msg("Invalid user id hex() string value from context")
+	//	return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid user id hex string"))
+	//}
+
+	// Fetch the user object
+	user, err := acm.userRepo.GetByID(c.UserContext(), userIDStr)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			// the token is valid but its user has been deleted
+			logger.Error().Interface(kName, acm.iName).Err(err).Msg("Unauthorized: User not found")
+			return apperrors.Unauthorized(apperrors.CodeUserNotFound, "User not found").WithErr(err)
+		}
+		// Log the actual error
+		logger.Error().Interface(kName, acm.iName).Err(err).Msg("Error while getting user")
+		return apperrors.Wrap(err, "Error while getting user")
 	}
+	if !user.IsActive() {
+		// access tokens issued before the user was disabled are still valid until they expire
+		logger.Warn().Interface(kName, acm.iName).Str("userId", user.ID.Hex()).Str("status", user.Status).Msg("Forbidden: User is disabled")
+		return apperrors.Forbidden(apperrors.CodeUserDisabled, "User is disabled")
+	}
+
+	// Add user object and validated userID to context
+	//ctx := context.WithValue(r.Context(), UserObjectContextKey, user)
+	//ctx = context.WithValue(ctx, UserIDContextKey, user.ID) // Add the ObjectID
+	c.Locals(UserObjectContextKey, user)
+	//c.Locals(UserIDContextKey, userID)
+
+	// tag the request span & logger with the authenticated user
+	trace.SpanFromContext(c.UserContext()).SetAttributes(attribute.String(tracing.AttributeUserID, user.ID.Hex()))
+	userLogger := logger.With().Str(logging.FieldUserID, user.ID.Hex()).Logger()
+	ctx := logging.WithLogger(c.UserContext(), &userLogger)
+	c.SetUserContext(context.WithValue(ctx, UserObjectContextKey, user))
+
+	//next.ServeHTTP(w, r.WithContext(ctx))
+	return nil
 }
@@ -4,4 +4,6 @@ import "github.com/gofiber/fiber/v2"
 
 type IAuthMiddleware interface {
 	Authenticate() fiber.Handler
+	// Verify authenticates the request without continuing the handler chain
+	Verify(c *fiber.Ctx) error
 }
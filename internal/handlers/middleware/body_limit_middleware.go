@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// bodyLimitKey holds the body limit of a streamed route in the fiber locals
+	bodyLimitKey = "bodyLimit"
+	// maxUnreadBody is read from what a handler left of a streamed body, a closing multipart boundary fits
+	maxUnreadBody = 4 << 10
+)
+
+// BodyLimitRoute raises the body limit of the requests with Method to Path, a Path ending with "/" matches every
+// path below it
+type BodyLimitRoute struct {
+	Method string
+	Path   string
+	Limit  int64
+	Stream bool // the handler reads the body through BodyStream, chunked bodies are not read up front
+}
+
+// BodyLimitMiddleware rejects request bodies above the limit of their route. The app streams request bodies, so
+// they are rejected before being read, which fiber's single BodyLimit cannot do per route.
+type BodyLimitMiddleware struct {
+	iName        string
+	log          *zerolog.Logger
+	defaultLimit int64
+	routes       []BodyLimitRoute
+}
+
+func NewBodyLimitMiddleware(log *zerolog.Logger, defaultLimit int64, routes ...BodyLimitRoute) *BodyLimitMiddleware {
+	return &BodyLimitMiddleware{
+		iName:        "BodyLimitMiddleware",
+		log:          log,
+		defaultLimit: defaultLimit,
+		routes:       routes,
+	}
+}
+
+// Limit checks the declared length of bodies, chunked bodies are read up to the limit unless their route is streamed.
+// Register it before anything reading the body, such as the OpenAPI validation.
+func (m *BodyLimitMiddleware) Limit() fiber.Handler {
+	const kName = "Limit"
+
+	return func(c *fiber.Ctx) error {
+		route := m.routeOf(c.Method(), c.Path())
+		limit := route.Limit
+		length := c.Request().Header.ContentLength()
+		if length > 0 && int64(length) > limit {
+			logging.FromContext(c.UserContext(), m.log).Debug().Interface(kName, m.iName).Int("length", length).Int64("limit", limit).Msg("request body too large")
+			// the body is left unread, it would be taken for the next request of the connection
+			c.Context().SetConnectionClose()
+			return tooLarge(limit)
+		}
+		if route.Stream {
+			c.Locals(bodyLimitKey, limit)
+			err := c.Next()
+			discardUnread(c)
+			return err
+		}
+		if length != -1 {
+			return c.Next()
+		}
+
+		// chunked, the length is only known once read
+		stream := c.Request().BodyStream()
+		if stream == nil {
+			return c.Next()
+		}
+		body, err := io.ReadAll(io.LimitReader(stream, limit+1))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return apperrors.Validation(apperrors.CodeInvalidBody, "Failed to read the request body").WithErr(err)
+		}
+		if int64(len(body)) > limit {
+			logging.FromContext(c.UserContext(), m.log).Debug().Interface(kName, m.iName).Int64("limit", limit).Msg("chunked request body too large")
+			return tooLarge(limit)
+		}
+		c.Request().SetBodyRaw(body)
+		return c.Next()
+	}
+}
+
+// routeOf returns the route raising the body limit of the request, one with the default limit unless raised
+func (m *BodyLimitMiddleware) routeOf(method string, path string) BodyLimitRoute {
+	for _, route := range m.routes {
+		if route.Method != method {
+			continue
+		}
+		if path == route.Path || (strings.HasSuffix(route.Path, "/") && strings.HasPrefix(path, route.Path)) {
+			return route
+		}
+	}
+	return BodyLimitRoute{Method: method, Path: path, Limit: m.defaultLimit}
+}
+
+// BodyStream returns the body of a request to a streamed route, reads past the route's limit fail with a 413
+func BodyStream(c *fiber.Ctx) io.Reader {
+	stream := c.Context().RequestBodyStream()
+	if stream == nil {
+		// the app does not stream request bodies, the body is read already
+		return bytes.NewReader(c.Body())
+	}
+	limit, ok := c.Locals(bodyLimitKey).(int64)
+	if !ok {
+		return stream
+	}
+	return &limitedBody{r: stream, limit: limit, remaining: limit}
+}
+
+// discardUnread reads what the handler left of a streamed body, fasthttp does not, & closes the connection when more
+// than a few bytes are left
+func discardUnread(c *fiber.Ctx) {
+	stream := c.Context().RequestBodyStream()
+	if stream == nil {
+		return
+	}
+	n, err := io.Copy(io.Discard, io.LimitReader(stream, maxUnreadBody))
+	if err != nil || n == maxUnreadBody {
+		c.Context().SetConnectionClose()
+	}
+}
+
+// limitedBody fails once more than limit bytes are read, the declared length of chunked bodies is unknown
+type limitedBody struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, tooLarge(l.limit)
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, tooLarge(l.limit)
+	}
+	return n, err
+}
+
+func tooLarge(limit int64) error {
+	return apperrors.Validation(apperrors.CodeTooLarge, "Request body exceeds "+strconv.FormatInt(limit, 10)+" bytes").
+		WithStatus(fiber.StatusRequestEntityTooLarge)
+}
@@ -3,8 +3,9 @@ package middleware
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
-	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
 	"github.com/rs/zerolog"
 	"strings"
 )
@@ -33,64 +34,73 @@ func NewJWTAuthMiddleware(log *zerolog.Logger, jwtService services.IJWTService)
 
 // Authenticate verifies the JWT from the Authorization header and adds the userID to the context.
 func (jam *JWTAuthMiddleware) Authenticate() fiber.Handler {
-	const kName = "Authenticate"
-
 	return func(c *fiber.Ctx) error {
-		jam.log.Debug().Interface(kName, jam.iName).Msg("authenticating request")
-
-		// 1. Get the Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			jam.log.Debug().Interface(kName, jam.iName).Msg("Authorization header missing")
-			//http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Authorization header required"))
-		}
-
-		// 2. Check if it's a Bearer token
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			jam.log.Debug().Interface(kName, jam.iName).Str("header", authHeader).Msg("Authorization header format must be Bearer {token}")
-			//http.Error(w, "Authorization header format must be Bearer {token}", http.StatusUnauthorized)
-			return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Authorization header requires Bearer-Token"))
+		if err := jam.Verify(c); err != nil {
+			return err
 		}
-		tokenString := parts[1]
+		return c.Next()
+	}
+}
 
-		// 3. Verify the access token using the JWTService
-		token, err := jam.jwtService.VerifyAccessToken(tokenString)
-		if err != nil {
-			// Log the specific JWT validation error
-			jam.log.Info().Interface(kName, jam.iName).Err(err).Msg("Invalid or expired access token")
-			return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid or expired access token"))
-		}
+// Verify is the body of Authenticate without calling the next handler, for operations authenticated by the strict server.
+func (jam *JWTAuthMiddleware) Verify(c *fiber.Ctx) error {
+	const kName = "Verify"
 
-		// 4. Check if the token is valid and extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// 5. Extract the UserID (subject 'sub' claim)
-			userIDStr, ok := claims["sub"].(string)
-			if !ok || userIDStr == "" {
-				jam.log.Debug().Interface(kName, jam.iName).Interface("claims", claims).Msg("Invalid token: 'sub' claim is missing or not a string")
-				jam.log.Error().Interface(kName, jam.iName).Err(err).Msg("Invalid access token claims")
-				return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Invalid token"))
-			}
+	logger := logging.FromContext(c.UserContext(), jam.log)
+	logger.Debug().Interface(kName, jam.iName).Msg("authenticating request")
 
-			// 6. Add the extracted userID string to the request context
-			//ctx := context.WithValue(c.Context(), UserIDStrContextKey, userIDStr)
-			c.Locals(UserIDStrContextKey, userIDStr) // Store the token in the context
+	// 1. Get the Authorization header
+	authHeader := c.Get("Authorization")
+	if authHeader == "" {
+		logger.Debug().Interface(kName, jam.iName).Msg("Authorization header missing")
+		//http.Error(w, "Authorization header required", http.StatusUnauthorized)
+		return apperrors.Unauthorized(apperrors.CodeUnauthenticated, "Authorization header required")
+	}
 
-			// 7. Call the next handler in the chain with the new context
-			//next.ServeHTTP(w, r.WithContext(ctx))
+	// 2. Check if it's a Bearer token
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		logger.Debug().Interface(kName, jam.iName).Str("header", authHeader).Msg("Authorization header format must be Bearer {token}")
+		//http.Error(w, "Authorization header format must be Bearer {token}", http.StatusUnauthorized)
+		return apperrors.Unauthorized(apperrors.CodeInvalidToken, "Authorization header requires Bearer-Token")
+	}
+	tokenString := parts[1]
 
-			//jam.log.Debug().Interface(kName, jam.iName).Interface(kName, jam.iName).
-			//	//Interface("claims", claims).
-			//	Interface("params", c.AllParams()).
-			//	Interface("context", c.Context().UserValue(UserIDStrContextKey)).
-			//	Msg("dumped claims and context-key")
+	// 3. Verify the access token using the JWTService
+	token, err := jam.jwtService.VerifyAccessToken(tokenString)
+	if err != nil {
+		// Log the specific JWT validation error
+		logger.Info().Interface(kName, jam.iName).Err(err).Msg("Invalid or expired access token")
+		return apperrors.Unauthorized(apperrors.CodeInvalidToken, "Invalid or expired access token")
+	}
 
-		} else {
-			jam.log.Warn().Interface(kName, jam.iName).Interface("Authenticate", "JWTAuthMiddleware").Bool("tokenValid", token.Valid).Msg("Token claims invalid or token is not valid")
-			//http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
-			return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse("Token is invalid"))
+	// 4. Check if the token is valid and extract claims
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		// 5. Extract the UserID (subject 'sub' claim)
+		userIDStr, ok := claims["sub"].(string)
+		if !ok || userIDStr == "" {
+			logger.Debug().Interface(kName, jam.iName).Interface("claims", claims).Msg("Invalid token: 'sub' claim is missing or not a string")
+			logger.Error().Interface(kName, jam.iName).Err(err).Msg("Invalid access token claims")
+			return apperrors.Unauthorized(apperrors.CodeInvalidToken, "Invalid token")
 		}
-		return c.Next()
+
+		// 6. Add the extracted userID string to the request context
+		//ctx := context.WithValue(c.Context(), UserIDStrContextKey, userIDStr)
+		c.Locals(UserIDStrContextKey, userIDStr) // Store the token in the context
+
+		// 7. Call the next handler in the chain with the new context
+		//next.ServeHTTP(w, r.WithContext(ctx))
+
+		//jam.log.Debug().Interface(kName, jam.iName).Interface(kName, jam.iName).
+		//	//Interface("claims", claims).
+		//	Interface("params", c.AllParams()).
+		//	Interface("context", c.Context().UserValue(UserIDStrContextKey)).
+		//	Msg("dumped claims and context-key")
+
+	} else {
+		logger.Warn().Interface(kName, jam.iName).Interface("Authenticate", "JWTAuthMiddleware").Bool("tokenValid", token.Valid).Msg("Token claims invalid or token is not valid")
+		//http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
+		return apperrors.Unauthorized(apperrors.CodeInvalidToken, "Token is invalid")
 	}
+	return nil
 }
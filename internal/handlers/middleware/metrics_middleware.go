@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners cannot blow up label cardinality
+const unmatchedRoute = "unmatched"
+
+// Metrics records request latency labelled by route template, register it before the routes
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+
+		route := unmatchedRoute
+		if r := c.Route(); r != nil && r.Method != "USE" {
+			route = r.Path
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Method(), route, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// responseStatus is the status the request is answered with, when err is set
+// the error handler has not written the response yet so the status is derived from err
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return apperrors.From(err).HTTPStatus()
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"net/http"
+	"strings"
+)
+
+// mimeUploadChunk is the content type of the chunks of resumable uploads, as in the tus protocol
+const mimeUploadChunk = "application/offset+octet-stream"
+
+// OpenAPIValidatorMiddleware validates requests, and optionally responses, against the embedded OpenAPI document.
+// Routes the document does not describe are passed through untouched.
+type OpenAPIValidatorMiddleware struct {
+	iName             string
+	log               *zerolog.Logger
+	router            routers.Router
+	options           *openapi3filter.Options
+	streamOptions     *openapi3filter.Options // options without body validation
+	validateResponses bool
+}
+
+// NewOpenAPIValidatorMiddleware serves the document under basePath (e.g. "/api/v1") whatever host the request was sent to.
+// validateResponses is meant for development & tests, a response drifting from the spec is replaced by a 500.
+func NewOpenAPIValidatorMiddleware(log *zerolog.Logger, swagger *openapi3.T, basePath string, validateResponses bool) (*OpenAPIValidatorMiddleware, error) {
+	// the document's servers carry the development host, match on the path only
+	swagger.Servers = openapi3.Servers{{URL: basePath}}
+
+	router, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		MultiError: true,
+		// authentication is enforced by the JWTAuthMiddleware on the route groups
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	streamOptions := *options
+	streamOptions.ExcludeRequestBody = true
+
+	return &OpenAPIValidatorMiddleware{
+		iName:             "OpenAPIValidatorMiddleware",
+		log:               log,
+		router:            router,
+		options:           options,
+		streamOptions:     &streamOptions,
+		validateResponses: validateResponses,
+	}, nil
+}
+
+// Validate rejects requests that do not match the operation's parameters & body with a 400 carrying per-field details.
+func (m *OpenAPIValidatorMiddleware) Validate() fiber.Handler {
+	const kName = "Validate"
+
+	return func(c *fiber.Ctx) error {
+		logger := logging.FromContext(c.UserContext(), m.log)
+
+		// the file parts carry their own content type (image/jpeg, video/mp4...) & upload chunks are raw bytes,
+		// the validator has no decoder for either, the handlers parse & check these bodies themselves. They are
+		// left unread, uploads stream to the blob store.
+		contentType := c.Get(fiber.HeaderContentType)
+		streamed := strings.HasPrefix(contentType, fiber.MIMEMultipartForm) || strings.HasPrefix(contentType, mimeUploadChunk)
+		var req *http.Request
+		var err error
+		if streamed {
+			req, err = requestWithoutBody(c)
+		} else {
+			req, err = adaptor.ConvertRequest(c, false)
+		}
+		if err != nil {
+			logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert request for validation")
+			return apperrors.Internal(apperrors.CodeInternal, "Failed to read request").WithErr(err)
+		}
+
+		route, pathParams, err := m.router.FindRoute(req)
+		if err != nil {
+			// not described by the spec (health, metrics, keys...), or a method fiber will reject itself
+			return c.Next()
+		}
+
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    m.options,
+		}
+		if streamed {
+			requestInput.Options = m.streamOptions
+		}
+		if err = openapi3filter.ValidateRequest(c.UserContext(), requestInput); err != nil {
+			logger.Debug().Interface(kName, m.iName).Err(err).Str("operation", route.Operation.OperationID).Msg("request does not match the API specification")
+			return apperrors.Validation(apperrors.CodeValidation, "Request does not match the API specification", collectFieldErrors(err, "", nil)...).WithErr(err)
+		}
+
+		if !m.validateResponses {
+			return c.Next()
+		}
+		// errors are rendered by the error handler, in its own envelope rather than the operation's error schemas,
+		// only success responses are checked
+		if err = c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return nil
+		}
+
+		header := http.Header{}
+		for key, values := range c.GetRespHeaders() {
+			header[http.CanonicalHeaderKey(key)] = values
+		}
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 c.Response().StatusCode(),
+			Header:                 header,
+			Options:                m.options,
+		}
+		responseInput.SetBodyBytes(c.Response().Body())
+		if err = openapi3filter.ValidateResponse(c.UserContext(), responseInput); err != nil {
+			logger.Error().Interface(kName, m.iName).Err(err).Str("operation", route.Operation.OperationID).Msg("response does not match the API specification")
+			return apperrors.Internal(apperrors.CodeResponseInvalid, "Response does not match the API specification").WithErr(err)
+		}
+		return nil
+	}
+}
+
+// collectFieldErrors flattens kin-openapi's nested errors into field errors, body fields are named by their dotted JSON path
+func collectFieldErrors(err error, field string, out []apperrors.FieldError) []apperrors.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			out = collectFieldErrors(inner, field, out)
+		}
+		return out
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			field = e.Parameter.Name
+		}
+		if e.Err == nil {
+			return append(out, apperrors.InvalidField(fieldOrBody(field), e.Reason))
+		}
+		if errors.Is(e.Err, openapi3filter.ErrInvalidRequired) {
+			return append(out, apperrors.RequiredField(fieldOrBody(field)))
+		}
+		return collectFieldErrors(e.Err, field, out)
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			field = strings.Trim(field+"."+strings.Join(pointer, "."), ".")
+		}
+		if e.SchemaField == "required" {
+			return append(out, apperrors.RequiredField(fieldOrBody(field)))
+		}
+		return append(out, apperrors.InvalidField(fieldOrBody(field), e.Reason))
+	default:
+		return append(out, apperrors.InvalidField(fieldOrBody(field), err.Error()))
+	}
+}
+
+// requestWithoutBody converts the request line & headers, adaptor.ConvertRequest would read the whole body
+func requestWithoutBody(c *fiber.Ctx) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(c.UserContext(), c.Method(), c.OriginalURL(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		req.Header.Add(string(key), string(value))
+	})
+	return req, nil
+}
+
+// fieldOrBody names errors about the request body as a whole
+func fieldOrBody(field string) string {
+	if field == "" {
+		return "body"
+	}
+	return field
+}
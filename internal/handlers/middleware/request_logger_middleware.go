@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// RequestLogger assigns or propagates X-Request-ID and stores a child logger tagged with it in c.UserContext(),
+// components retrieve it with logging.FromContext. Register it first so every other middleware sees the request ID.
+func RequestLogger(log *zerolog.Logger) fiber.Handler {
+	const kName = "RequestLogger"
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		requestLog := log.With().
+			Str(logging.FieldRequestID, utils.GetRequestID(c)).
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Logger()
+		c.SetUserContext(logging.WithLogger(c.UserContext(), &requestLog))
+
+		err := c.Next()
+
+		// the route template is only known once routing is done
+		route := unmatchedRoute
+		if r := c.Route(); r != nil && r.Method != "USE" {
+			route = r.Path
+		}
+		requestLog.Debug().Interface(kName, "middleware").
+			Str("route", route).
+			Int("status", c.Response().StatusCode()).
+			Dur("latency", time.Since(start)).
+			Msg("request completed")
+		return err
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier adapts the fiber request headers to propagation.TextMapCarrier
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Tracing starts a server span for every request, continuing the trace of an incoming W3C traceparent header.
+// The span context is stored in c.UserContext(), which controllers pass down to services & repositories.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c: c})
+		ctx, span := tracing.Tracer().Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+				attribute.String("client.address", utils.GetClientIP(c)),
+				attribute.String("user_agent.original", utils.GetUserAgent(c)),
+				attribute.String(tracing.AttributeRequestID, utils.GetRequestID(c)),
+			),
+		)
+		defer span.End()
+		// correlate log lines with the trace
+		if logger := logging.FromContext(ctx, nil); logger != nil && span.SpanContext().IsValid() {
+			traceLogger := logger.With().Str(logging.FieldTraceID, span.SpanContext().TraceID().String()).Logger()
+			ctx = logging.WithLogger(ctx, &traceLogger)
+		}
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		if r := c.Route(); r != nil && r.Method != "USE" {
+			span.SetName(c.Method() + " " + r.Path)
+			span.SetAttributes(attribute.String("http.route", r.Path))
+		}
+		status := responseStatus(c, err)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fiber.ErrInternalServerError.Message)
+		}
+		return err
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
 	"github.com/mcsamuelshoko/telko-moment-server/api"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/controllers"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/handlers/middleware"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/utils"
 	"github.com/rs/zerolog"
 )
@@ -18,6 +21,11 @@ type RoutesHandler struct {
 	settingsController controllers.ISettingsController
 	authController     controllers.IAuthenticationController
 	msgController      controllers.IMessageController
+	keyController      controllers.IKeyController
+	mediaController    controllers.IMediaController
+	uploadController   controllers.IUploadController
+	presenceController controllers.IPresenceController
+	healthController   controllers.IHealthController
 }
 
 // NewRoutesHandler creates a new RoutesHandler instance.
@@ -29,6 +37,11 @@ func NewRoutesHandler(
 	settingsController controllers.ISettingsController,
 	authController controllers.IAuthenticationController,
 	msgController controllers.IMessageController,
+	keyController controllers.IKeyController,
+	mediaController controllers.IMediaController,
+	uploadController controllers.IUploadController,
+	presenceController controllers.IPresenceController,
+	healthController controllers.IHealthController,
 ) *RoutesHandler {
 
 	return &RoutesHandler{
@@ -40,19 +53,18 @@ func NewRoutesHandler(
 		authController:     authController,
 		authCtxMiddleware:  authCtxMiddleware,
 		msgController:      msgController,
+		keyController:      keyController,
+		mediaController:    mediaController,
+		uploadController:   uploadController,
+		presenceController: presenceController,
+		healthController:   healthController,
 	}
 }
 
-func (r RoutesHandler) SetupRoutes(app *fiber.App) {
-	const kName = "SetupRoutes"
-
-	//pointing to handler out of laziness to refactor
-	handler := &r
-
-	// Wrap the handler with the generated server interface wrapper
-	wrapper := api.ServerInterfaceWrapper{Handler: handler}
-	//si := api.ServerInterface(handler)
+// RoutesHandler implements the generated strict server, an operation added to the spec does not compile until implemented
+var _ api.StrictServerInterface = (*RoutesHandler)(nil)
 
+func (r *RoutesHandler) SetupRoutes(app *fiber.App) {
 	// ::: ENTRY
 	entry := app.Group("/")
 	entry.Get("/", func(c *fiber.Ctx) error {
@@ -66,217 +78,307 @@ func (r RoutesHandler) SetupRoutes(app *fiber.App) {
 				"Hello, World!"))
 	})
 
+	// ::: HEALTH (probes stay outside /api so they are never behind auth)
+	entry.Get("/livez", r.healthController.Livez)
+	entry.Get("/readyz", r.healthController.Readyz)
+
 	// ::: API ROUTING SETUP
 	apiRoute := app.Group("/api")
 	v1 := apiRoute.Group("/v1")
 
-	//// Register the routes
-	//api.RegisterHandlers(v1, si)
-
-	// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::: //
-
-	// ::: ENTRY
-	v1.Get("/", wrapper.Index)
-
-	// ::: AUTH
-	auth := v1.Group("/auth")
-	auth.Post("/login", wrapper.AuthLogin)
-	auth.Post("/register", wrapper.AuthRegister)
-	auth.Post("/refresh-token", func(ctx *fiber.Ctx) error {
-		return r.authController.UpdateRefreshToken(ctx)
-	})
-	auth.Post("/logout", func(ctx *fiber.Ctx) error {
-		return r.authController.Logout(ctx)
-	})
-
-	// ::: USERS
-	users := v1.Group("/users")
-
-	// use middleware that apply
-	users.Use(r.authMiddleware.Authenticate()).Use(r.authCtxMiddleware.AddUserContext())
+	// ::: REALTIME EVENTS (not part of the spec: OpenAPI 3.0 can not describe the events of a server-sent event stream,
+	// & the handler writes to the connection as events arrive, until a failed flush tells the client went away)
+	v1.Get("/realtime/events", r.authMiddleware.Authenticate(), r.authCtxMiddleware.AddUserContext(), r.presenceController.StreamEvents)
 
-	// add routes that use the middleware
-	users.Get("/", wrapper.GetAllUsers)
-	users.Get("/:userId", wrapper.GetUserById)
+	// ::: MEDIA UPLOADS (described by the spec, but the generated handler reads the whole body before it is called,
+	// this one streams the file part to the blob store)
+	v1.Post("/media", r.authMiddleware.Authenticate(), r.authCtxMiddleware.AddUserContext(), r.mediaController.UploadMedia)
 
-	users.Post("/", wrapper.CreateUser)
-	users.Put("/:userId", wrapper.UpdateUser)
-	users.Delete("/:userId", wrapper.DeleteUser)
-
-	// ::: SETTINGS
-	settings := v1.Group("/settings")
-	// use middleware that apply
-	settings.Use(r.authMiddleware.Authenticate()).Use(r.authCtxMiddleware.AddUserContext())
-	settings.Get("/:userId", wrapper.GetUserSettings)
-	settings.Put("/:userId", wrapper.UpdateUserSettings)
+	// ::: SPEC OPERATIONS
+	// the last middleware wraps the others, so errors of the authentication are rendered too
+	api.RegisterHandlers(v1, api.NewStrictHandler(r, []api.StrictMiddlewareFunc{
+		r.withRequestHeaders,
+		r.authenticateSecured,
+		r.renderErrors,
+	}))
+}
 
-	// ... Setup routes for other resources (chats, messages, etc.)
+// ::::::::::::::::::::::::::::::  STRICT MIDDLEWARE :::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// withRequestHeaders exposes the request headers to handlers, strict handlers only receive the user context
+func (r *RoutesHandler) withRequestHeaders(f api.StrictHandlerFunc, _ string) api.StrictHandlerFunc {
+	return func(c *fiber.Ctx, request interface{}) (interface{}, error) {
+		c.SetUserContext(utils.WithHeaderMap(c.UserContext(), utils.GetHeaderMap(c)))
+		return f(c, request)
+	}
 }
 
-func (r RoutesHandler) GetAllChatGroups(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+// authenticateSecured authenticates the operations the spec secures with BearerAuth, the generated wrapper flags them
+// with their scopes before calling the strict handler
+func (r *RoutesHandler) authenticateSecured(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	const kName = "authenticateSecured"
+
+	return func(c *fiber.Ctx, request interface{}) (interface{}, error) {
+		if c.Context().UserValue(api.BearerAuthScopes) == nil {
+			return f(c, request)
+		}
+		logging.FromContext(c.UserContext(), r.logger).Debug().Interface(kName, r.iName).Str("operation", operationID).Msg("authenticating secured operation")
+
+		if err := r.authMiddleware.Verify(c); err != nil {
+			return nil, err
+		}
+		if err := r.authCtxMiddleware.AttachUser(c); err != nil {
+			return nil, err
+		}
+		return f(c, request)
+	}
 }
 
-func (r RoutesHandler) CreateChatGroup(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+// renderErrors hands errors to the app's error handler, the generated handler would otherwise flatten them into a 400
+func (r *RoutesHandler) renderErrors(f api.StrictHandlerFunc, _ string) api.StrictHandlerFunc {
+	return func(c *fiber.Ctx, request interface{}) (interface{}, error) {
+		response, err := f(c, request)
+		if err != nil {
+			return nil, c.App().ErrorHandler(c, err)
+		}
+		return response, nil
+	}
 }
 
-func (r RoutesHandler) DeleteChatGroup(c *fiber.Ctx, chatGroupId string) error {
-	//TODO implement me
-	panic("implement me")
+// notImplemented is returned by the operations of the spec the server does not serve yet
+func notImplemented(operationID string) error {
+	return apperrors.Internal(apperrors.CodeNotImplemented, operationID+" is not implemented yet").WithStatus(fiber.StatusNotImplemented)
 }
 
-func (r RoutesHandler) GetChatGroupById(c *fiber.Ctx, chatGroupId string) error {
-	//TODO implement me
-	panic("implement me")
+// ::::::::::::::::::::::::::::::  NOT IMPLEMENTED :::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (r *RoutesHandler) GetAllChatGroups(_ context.Context, _ api.GetAllChatGroupsRequestObject) (api.GetAllChatGroupsResponseObject, error) {
+	return nil, notImplemented("GetAllChatGroups")
 }
 
-func (r RoutesHandler) UpdateChatGroup(c *fiber.Ctx, chatGroupId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) CreateChatGroup(_ context.Context, _ api.CreateChatGroupRequestObject) (api.CreateChatGroupResponseObject, error) {
+	return nil, notImplemented("CreateChatGroup")
 }
 
-func (r RoutesHandler) GetAllChats(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) DeleteChatGroup(_ context.Context, _ api.DeleteChatGroupRequestObject) (api.DeleteChatGroupResponseObject, error) {
+	return nil, notImplemented("DeleteChatGroup")
 }
 
-func (r RoutesHandler) CreateChat(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) GetChatGroupById(_ context.Context, _ api.GetChatGroupByIdRequestObject) (api.GetChatGroupByIdResponseObject, error) {
+	return nil, notImplemented("GetChatGroupById")
 }
 
-func (r RoutesHandler) DeleteChat(c *fiber.Ctx, chatId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) UpdateChatGroup(_ context.Context, _ api.UpdateChatGroupRequestObject) (api.UpdateChatGroupResponseObject, error) {
+	return nil, notImplemented("UpdateChatGroup")
 }
 
-func (r RoutesHandler) GetChatById(c *fiber.Ctx, chatId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) GetAllChats(_ context.Context, _ api.GetAllChatsRequestObject) (api.GetAllChatsResponseObject, error) {
+	return nil, notImplemented("GetAllChats")
 }
 
-func (r RoutesHandler) UpdateChat(c *fiber.Ctx, chatId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) CreateChat(_ context.Context, _ api.CreateChatRequestObject) (api.CreateChatResponseObject, error) {
+	return nil, notImplemented("CreateChat")
 }
 
-func (r RoutesHandler) GetAllHighlights(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) DeleteChat(_ context.Context, _ api.DeleteChatRequestObject) (api.DeleteChatResponseObject, error) {
+	return nil, notImplemented("DeleteChat")
 }
 
-func (r RoutesHandler) CreateHighlight(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) GetChatById(_ context.Context, _ api.GetChatByIdRequestObject) (api.GetChatByIdResponseObject, error) {
+	return nil, notImplemented("GetChatById")
 }
 
-func (r RoutesHandler) DeleteHighlightById(c *fiber.Ctx, highlightId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) UpdateChat(_ context.Context, _ api.UpdateChatRequestObject) (api.UpdateChatResponseObject, error) {
+	return nil, notImplemented("UpdateChat")
 }
 
-func (r RoutesHandler) GetHighlightById(c *fiber.Ctx, highlightId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) GetAllHighlights(_ context.Context, _ api.GetAllHighlightsRequestObject) (api.GetAllHighlightsResponseObject, error) {
+	return nil, notImplemented("GetAllHighlights")
 }
 
-func (r RoutesHandler) UpdateHighlightById(c *fiber.Ctx, highlightId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) CreateHighlight(_ context.Context, _ api.CreateHighlightRequestObject) (api.CreateHighlightResponseObject, error) {
+	return nil, notImplemented("CreateHighlight")
 }
 
-func (r RoutesHandler) UploadMedia(c *fiber.Ctx) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) DeleteHighlightById(_ context.Context, _ api.DeleteHighlightByIdRequestObject) (api.DeleteHighlightByIdResponseObject, error) {
+	return nil, notImplemented("DeleteHighlightById")
 }
 
-func (r RoutesHandler) DeleteMedia(c *fiber.Ctx, mediaId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) GetHighlightById(_ context.Context, _ api.GetHighlightByIdRequestObject) (api.GetHighlightByIdResponseObject, error) {
+	return nil, notImplemented("GetHighlightById")
 }
 
-func (r RoutesHandler) GetMediaById(c *fiber.Ctx, mediaId string) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) UpdateHighlightById(_ context.Context, _ api.UpdateHighlightByIdRequestObject) (api.UpdateHighlightByIdResponseObject, error) {
+	return nil, notImplemented("UpdateHighlightById")
 }
 
-func (r RoutesHandler) GetMessagesByChatId(c *fiber.Ctx, params api.GetMessagesByChatIdParams) error {
-	//TODO implement me
-	panic("implement me")
+func (r *RoutesHandler) GetMessagesByChatId(_ context.Context, _ api.GetMessagesByChatIdRequestObject) (api.GetMessagesByChatIdResponseObject, error) {
+	return nil, notImplemented("GetMessagesByChatId")
 }
 
 // ::::::::::::::::::::::::::::::  ROUTES ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
 // :::: ENTRY -or- INDEX
 
-func (r RoutesHandler) Index(c *fiber.Ctx) error {
+func (r *RoutesHandler) Index(ctx context.Context, _ api.IndexRequestObject) (api.IndexResponseObject, error) {
 	const kName = "Index"
 
-	r.logger.Debug().Interface(kName, r.iName).Msg("[route]:GET:/api/v1/")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse(nil, "Ready to serve!"))
-
+	logging.FromContext(ctx, r.logger).Debug().Interface(kName, r.iName).Msg("[route]:GET:/api/v1/")
+	return api.Index200JSONResponse{Success: true, Message: "Ready to serve!"}, nil
 }
 
 // :::: AUTH
 
-func (r RoutesHandler) AuthLogin(c *fiber.Ctx) error {
-	return r.authController.Login(c)
+func (r *RoutesHandler) AuthLogin(ctx context.Context, request api.AuthLoginRequestObject) (api.AuthLoginResponseObject, error) {
+	return r.authController.Login(ctx, request)
+}
+
+func (r *RoutesHandler) AuthRegister(ctx context.Context, request api.AuthRegisterRequestObject) (api.AuthRegisterResponseObject, error) {
+	return r.authController.Register(ctx, request)
+}
+
+func (r *RoutesHandler) AuthRefreshToken(ctx context.Context, request api.AuthRefreshTokenRequestObject) (api.AuthRefreshTokenResponseObject, error) {
+	return r.authController.UpdateRefreshToken(ctx, request)
 }
 
-func (r RoutesHandler) AuthRegister(c *fiber.Ctx) error {
-	return r.authController.Register(c)
+func (r *RoutesHandler) AuthLogout(ctx context.Context, request api.AuthLogoutRequestObject) (api.AuthLogoutResponseObject, error) {
+	return r.authController.Logout(ctx, request)
 }
 
 // :::: SETTINGS
 
-func (r RoutesHandler) GetUserSettings(c *fiber.Ctx, userId string) error {
-	return r.settingsController.GetUserSettings(c, userId)
+func (r *RoutesHandler) GetUserSettings(ctx context.Context, request api.GetUserSettingsRequestObject) (api.GetUserSettingsResponseObject, error) {
+	return r.settingsController.GetUserSettings(ctx, request)
 }
 
-func (r RoutesHandler) UpdateUserSettings(c *fiber.Ctx, userId string) error {
-	return r.settingsController.UpdateUserSettings(c, userId)
+func (r *RoutesHandler) UpdateUserSettings(ctx context.Context, request api.UpdateUserSettingsRequestObject) (api.UpdateUserSettingsResponseObject, error) {
+	return r.settingsController.UpdateUserSettings(ctx, request)
 }
 
 // :::: USERS
 
-func (r RoutesHandler) GetAllUsers(c *fiber.Ctx) error {
-	return r.userController.GetAllUsers(c)
+func (r *RoutesHandler) GetAllUsers(ctx context.Context, request api.GetAllUsersRequestObject) (api.GetAllUsersResponseObject, error) {
+	return r.userController.GetAllUsers(ctx, request)
 }
 
-func (r RoutesHandler) CreateUser(c *fiber.Ctx) error {
-	return r.userController.CreateUser(c)
+func (r *RoutesHandler) CreateUser(ctx context.Context, request api.CreateUserRequestObject) (api.CreateUserResponseObject, error) {
+	return r.userController.CreateUser(ctx, request)
 }
 
-func (r RoutesHandler) DeleteUser(c *fiber.Ctx, userId string) error {
-	return r.userController.DeleteUser(c, userId)
+func (r *RoutesHandler) DeleteUser(ctx context.Context, request api.DeleteUserRequestObject) (api.DeleteUserResponseObject, error) {
+	return r.userController.DeleteUser(ctx, request)
 }
 
-func (r RoutesHandler) GetUserById(c *fiber.Ctx, userId string) error {
-	return r.userController.GetUserById(c, userId)
+func (r *RoutesHandler) GetUserById(ctx context.Context, request api.GetUserByIdRequestObject) (api.GetUserByIdResponseObject, error) {
+	return r.userController.GetUserById(ctx, request)
 }
 
-func (r RoutesHandler) UpdateUser(c *fiber.Ctx, userId string) error {
-	return r.userController.UpdateUser(c, userId)
+func (r *RoutesHandler) UpdateUser(ctx context.Context, request api.UpdateUserRequestObject) (api.UpdateUserResponseObject, error) {
+	return r.userController.UpdateUser(ctx, request)
 }
 
 // :::: MESSAGES
 
-func (r RoutesHandler) SendMessage(c *fiber.Ctx) error {
-	return r.msgController.CreateMessage(c)
+func (r *RoutesHandler) SendMessage(ctx context.Context, request api.SendMessageRequestObject) (api.SendMessageResponseObject, error) {
+	return r.msgController.CreateMessage(ctx, request)
+}
+
+func (r *RoutesHandler) DeleteMessage(ctx context.Context, request api.DeleteMessageRequestObject) (api.DeleteMessageResponseObject, error) {
+	return r.msgController.DeleteMessage(ctx, request)
+}
+
+func (r *RoutesHandler) GetMessageById(ctx context.Context, request api.GetMessageByIdRequestObject) (api.GetMessageByIdResponseObject, error) {
+	return r.msgController.GetMessageById(ctx, request)
+}
+
+func (r *RoutesHandler) MarkMessagePlayed(ctx context.Context, request api.MarkMessagePlayedRequestObject) (api.MarkMessagePlayedResponseObject, error) {
+	return r.msgController.MarkMessagePlayed(ctx, request)
+}
+
+func (r *RoutesHandler) GetMessagePlayedReceipts(ctx context.Context, request api.GetMessagePlayedReceiptsRequestObject) (api.GetMessagePlayedReceiptsResponseObject, error) {
+	return r.msgController.GetMessagePlayedReceipts(ctx, request)
+}
+
+func (r *RoutesHandler) UpdateMessage(ctx context.Context, request api.UpdateMessageRequestObject) (api.UpdateMessageResponseObject, error) {
+	return r.msgController.UpdateMessage(ctx, request)
+}
+
+// :::: MEDIA
+
+// UploadMedia is served by the streaming route of SetupRoutes, registered first, the generated handler would
+// buffer the whole upload
+func (r *RoutesHandler) UploadMedia(_ context.Context, _ api.UploadMediaRequestObject) (api.UploadMediaResponseObject, error) {
+	return nil, notImplemented("UploadMedia")
+}
+
+func (r *RoutesHandler) DeleteMedia(ctx context.Context, request api.DeleteMediaRequestObject) (api.DeleteMediaResponseObject, error) {
+	return r.mediaController.DeleteMedia(ctx, request)
+}
+
+func (r *RoutesHandler) GetMediaById(ctx context.Context, request api.GetMediaByIdRequestObject) (api.GetMediaByIdResponseObject, error) {
+	return r.mediaController.GetMediaById(ctx, request)
+}
+
+func (r *RoutesHandler) GetMediaContent(ctx context.Context, request api.GetMediaContentRequestObject) (api.GetMediaContentResponseObject, error) {
+	return r.mediaController.GetMediaContent(ctx, request)
+}
+
+func (r *RoutesHandler) GetChatGallery(ctx context.Context, request api.GetChatGalleryRequestObject) (api.GetChatGalleryResponseObject, error) {
+	return r.mediaController.GetChatGallery(ctx, request)
+}
+
+func (r *RoutesHandler) GetStorageUsage(ctx context.Context, request api.GetStorageUsageRequestObject) (api.GetStorageUsageResponseObject, error) {
+	return r.mediaController.GetStorageUsage(ctx, request)
+}
+
+func (r *RoutesHandler) CreateUpload(ctx context.Context, request api.CreateUploadRequestObject) (api.CreateUploadResponseObject, error) {
+	return r.uploadController.CreateUpload(ctx, request)
+}
+
+func (r *RoutesHandler) GetUpload(ctx context.Context, request api.GetUploadRequestObject) (api.GetUploadResponseObject, error) {
+	return r.uploadController.GetUpload(ctx, request)
 }
 
-func (r RoutesHandler) DeleteMessage(c *fiber.Ctx, messageId string) error {
-	return r.msgController.DeleteMessage(c, messageId)
+func (r *RoutesHandler) UploadChunk(ctx context.Context, request api.UploadChunkRequestObject) (api.UploadChunkResponseObject, error) {
+	return r.uploadController.UploadChunk(ctx, request)
 }
 
-func (r RoutesHandler) GetMessageById(c *fiber.Ctx, messageId string) error {
-	return r.msgController.GetMessageById(c, messageId)
+func (r *RoutesHandler) DeleteUpload(ctx context.Context, request api.DeleteUploadRequestObject) (api.DeleteUploadResponseObject, error) {
+	return r.uploadController.DeleteUpload(ctx, request)
+}
+
+func (r *RoutesHandler) CompleteUpload(ctx context.Context, request api.CompleteUploadRequestObject) (api.CompleteUploadResponseObject, error) {
+	return r.uploadController.CompleteUpload(ctx, request)
+}
+
+// :::: KEYS
+
+func (r *RoutesHandler) PublishDeviceKeys(ctx context.Context, request api.PublishDeviceKeysRequestObject) (api.PublishDeviceKeysResponseObject, error) {
+	return r.keyController.PublishDeviceKeys(ctx, request)
+}
+
+func (r *RoutesHandler) DeleteDeviceKeys(ctx context.Context, request api.DeleteDeviceKeysRequestObject) (api.DeleteDeviceKeysResponseObject, error) {
+	return r.keyController.DeleteDeviceKeys(ctx, request)
+}
+
+func (r *RoutesHandler) UploadOneTimePreKeys(ctx context.Context, request api.UploadOneTimePreKeysRequestObject) (api.UploadOneTimePreKeysResponseObject, error) {
+	return r.keyController.UploadOneTimePreKeys(ctx, request)
+}
+
+func (r *RoutesHandler) GetPreKeyCount(ctx context.Context, request api.GetPreKeyCountRequestObject) (api.GetPreKeyCountResponseObject, error) {
+	return r.keyController.GetPreKeyCount(ctx, request)
+}
+
+func (r *RoutesHandler) GetUserPreKeyBundles(ctx context.Context, request api.GetUserPreKeyBundlesRequestObject) (api.GetUserPreKeyBundlesResponseObject, error) {
+	return r.keyController.GetUserPreKeyBundles(ctx, request)
+}
+
+// :::: REALTIME
+
+func (r *RoutesHandler) SetChatActivity(ctx context.Context, request api.SetChatActivityRequestObject) (api.SetChatActivityResponseObject, error) {
+	return r.presenceController.SetChatActivity(ctx, request)
 }
 
-func (r RoutesHandler) UpdateMessage(c *fiber.Ctx, messageId string) error {
-	return r.msgController.UpdateMessage(c, messageId)
+func (r *RoutesHandler) GetUserPresence(ctx context.Context, request api.GetUserPresenceRequestObject) (api.GetUserPresenceResponseObject, error) {
+	return r.presenceController.GetUserPresence(ctx, request)
 }
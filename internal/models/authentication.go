@@ -27,13 +27,13 @@ type Authentication struct {
 func (a *Authentication) CreateUniqueIndexes(db *mongo.Database) error {
 	// Create unique index for userId
 	userIdIndex := mongo.IndexModel{
-		Keys:    bson.D{{"userId", 1}},
+		Keys:    bson.D{{Key: "userId", Value: 1}},
 		Options: options.Index().SetUnique(true).SetName("unique_user_id"),
 	}
 
 	// Create unique index for refreshToken
 	refreshTokenHashIndex := mongo.IndexModel{
-		Keys:    bson.D{{"refreshTokenHash", 1}},
+		Keys:    bson.D{{Key: "refreshTokenHash", Value: 1}},
 		Options: options.Index().SetUnique(true).SetName("unique_refresh_token_hash"),
 	}
 
@@ -104,30 +104,12 @@ type LoginRequestUsername struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// LoginRequestEmail represents the data needed for a login attempt
-type LoginRequestEmail struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-}
-
 // LoginResponse represents the data returned after a successful login
 type LoginResponse struct {
 	Token        string `json:"token"`
 	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
-// RegisterRequest represents the data needed for a registration attempt
-type RegisterRequest struct {
-	PhoneNumber string `json:"phoneNumber" validate:"required,phoneNumber"`
-	Password    string `json:"password" validate:"required"`
-}
-
-// RegisterRequestEmail represents the data needed for a registration attempt
-type RegisterRequestEmail struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-}
-
 type UpdateTokenRequest struct {
 	RefreshToken string `json:"refreshToken,omitempty"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Blob is stored media content, shared by the Media of an owner who uploaded the same file more than once.
+// It is found by the SHA-256 of the uploaded content & deleted by the garbage collector once RefCount drops to 0.
+type Blob struct {
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	OwnerID       primitive.ObjectID `json:"ownerId" bson:"ownerId"` // blobs are never shared between users, who could otherwise probe for files
+	Hash          string             `json:"hash" bson:"hash"`       // hex SHA-256 of the uploaded content
+	StorageKey    string             `json:"-" bson:"storageKey"`
+	ContentType   string             `json:"contentType" bson:"contentType"`
+	Size          int64              `json:"size" bson:"size"` // of the stored content, sanitized content may differ from the upload
+	RefCount      int                `json:"refCount" bson:"refCount"`
+	MediaMetadata `bson:",inline"`
+	MediaScan     `bson:",inline"` // scanned once for all the media sharing the blob
+	CreatedAt     time.Time        `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt     time.Time        `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"slices"
 	"time"
 )
 
@@ -15,11 +16,17 @@ const (
 )
 
 type Chat struct {
-	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	MemberCount   int64              `json:"memberCount,omitempty" bson:"memberCount,omitempty"`
-	Type          string             `json:"type" bson:"type"`
-	Name          string             `json:"name,omitempty" bson:"name,omitempty"`
-	CreatedAt     time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
-	UpdatedAt     time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
-	LastMessageID primitive.ObjectID `json:"lastMessageId,omitempty" bson:"lastMessageId,omitempty"`
+	ID            primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	MemberCount   int64                `json:"memberCount,omitempty" bson:"memberCount,omitempty"`
+	Type          string               `json:"type" bson:"type"`
+	Name          string               `json:"name,omitempty" bson:"name,omitempty"`
+	Participants  []primitive.ObjectID `json:"participants,omitempty" bson:"participants,omitempty"`
+	CreatedAt     time.Time            `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt     time.Time            `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+	LastMessageID primitive.ObjectID   `json:"lastMessageId,omitempty" bson:"lastMessageId,omitempty"`
+}
+
+// HasParticipant reports whether userID takes part in the chat
+func (c *Chat) HasParticipant(userID primitive.ObjectID) bool {
+	return slices.Contains(c.Participants, userID)
 }
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// ChatKey holds the per-chat data key used to encrypt message content at rest.
+// The data key itself is wrapped with the server master key before it is stored,
+// destroying the ChatKey document makes every message in the chat unreadable (crypto-shredding).
+type ChatKey struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ChatID    primitive.ObjectID `json:"chatId" bson:"chatId"`
+	Key       string             `json:"-" bson:"key"` // hex encoded AES data key
+	CreatedAt time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+}
+
+// CreateUniqueIndexes creates a unique index for chatId, a chat only ever has one active data key
+func (k *ChatKey) CreateUniqueIndexes(db *mongo.Database) error {
+	chatIdIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "chatId", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("unique_chat_id"),
+	}
+
+	// Create indexes
+	_, err := db.Collection("chat_keys").Indexes().
+		CreateMany(context.Background(), []mongo.IndexModel{chatIdIndex})
+
+	return err
+}
+
+// EncryptFields wraps the data key with the master key before saving
+func (k *ChatKey) EncryptFields(encSvc services.IEncryptionService) error {
+	if k.Key != "" {
+		encrypted, err := encSvc.Encrypt(k.Key)
+		if err != nil {
+			return err
+		}
+		k.Key = encrypted
+	}
+	return nil
+}
+
+// DecryptFields unwraps the data key after retrieval
+func (k *ChatKey) DecryptFields(encSvc services.IEncryptionService) error {
+	if k.Key != "" {
+		decrypted, err := encSvc.Decrypt(k.Key)
+		if err != nil {
+			return err
+		}
+		k.Key = decrypted
+	}
+	return nil
+}
+
+// :::: DEFAULTS FUNCTION(S)
+
+// NewChatKey generates a fresh AES-256 data key for the given chat
+func NewChatKey(chatID primitive.ObjectID) (*ChatKey, error) {
+	key, err := services.GenerateAESKey(32)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatKey{
+		ChatID:    chatID,
+		Key:       key,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// PreKeyLowWatermark is the number of remaining one-time prekeys below which a device is asked to replenish
+const PreKeyLowWatermark = 10
+
+// SignedPreKey is a medium-term public key signed with the device's identity key
+type SignedPreKey struct {
+	KeyID     int    `json:"keyId" bson:"keyId"`
+	PublicKey string `json:"publicKey" bson:"publicKey"` // base64 encoded
+	Signature string `json:"signature" bson:"signature"` // base64 encoded
+}
+
+// DeviceKey holds the public identity of one of a user's devices, the server only ever stores public keys
+type DeviceKey struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `json:"userId" bson:"userId"`
+	DeviceID     string             `json:"deviceId" bson:"deviceId"`
+	IdentityKey  string             `json:"identityKey" bson:"identityKey"` // base64 encoded
+	SignedPreKey SignedPreKey       `json:"signedPreKey" bson:"signedPreKey"`
+	CreatedAt    time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt    time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+// OneTimePreKey is a single use public key, it is deleted as soon as a peer fetches it
+type OneTimePreKey struct {
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"-" bson:"userId"`
+	DeviceID    string             `json:"-" bson:"deviceId"`
+	IdentityKey string             `json:"-" bson:"identityKey"` // of the device when the prekey was uploaded
+	KeyID       int                `json:"keyId" bson:"keyId"`
+	PublicKey   string             `json:"publicKey" bson:"publicKey"` // base64 encoded
+	CreatedAt   time.Time          `json:"-" bson:"createdAt,omitempty"`
+}
+
+// :::: UNIQUE INDEXES
+
+// CreateUniqueIndexes creates a unique index for a user's device
+func (d *DeviceKey) CreateUniqueIndexes(db *mongo.Database) error {
+	userDeviceIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "deviceId", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("unique_user_id_device_id"),
+	}
+
+	// Create indexes
+	_, err := db.Collection("device_keys").Indexes().
+		CreateMany(context.Background(), []mongo.IndexModel{userDeviceIndex})
+
+	return err
+}
+
+// CreateUniqueIndexes creates a unique index for a device's prekey ids
+func (o *OneTimePreKey) CreateUniqueIndexes(db *mongo.Database) error {
+	deviceKeyIdIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "deviceId", Value: 1}, {Key: "keyId", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("unique_user_id_device_id_key_id"),
+	}
+
+	// Create indexes
+	_, err := db.Collection("one_time_prekeys").Indexes().
+		CreateMany(context.Background(), []mongo.IndexModel{deviceKeyIdIndex})
+
+	return err
+}
+
+// :::: REQUEST RESPONSE
+
+// PublishKeysRequest is sent by a device to publish or rotate its public keys
+type PublishKeysRequest struct {
+	IdentityKey    string          `json:"identityKey" validate:"required"`
+	SignedPreKey   SignedPreKey    `json:"signedPreKey" validate:"required"`
+	OneTimePreKeys []OneTimePreKey `json:"oneTimePreKeys,omitempty"`
+}
+
+// UploadPreKeysRequest is sent by a device to replenish its one-time prekeys
+type UploadPreKeysRequest struct {
+	OneTimePreKeys []OneTimePreKey `json:"oneTimePreKeys" validate:"required"`
+}
+
+// PreKeyBundle is what a peer needs to start a session with one device,
+// OneTimePreKey is nil when the device has run out of one-time prekeys
+type PreKeyBundle struct {
+	UserID        primitive.ObjectID `json:"userId"`
+	DeviceID      string             `json:"deviceId"`
+	IdentityKey   string             `json:"identityKey"`
+	SignedPreKey  SignedPreKey       `json:"signedPreKey"`
+	OneTimePreKey *OneTimePreKey     `json:"oneTimePreKey,omitempty"`
+}
+
+// PreKeyCountResponse tells a device how many one-time prekeys the server still holds for it
+type PreKeyCountResponse struct {
+	DeviceID  string `json:"deviceId"`
+	Remaining int64  `json:"remaining"`
+	Replenish bool   `json:"replenish"`
+}
@@ -1,14 +1,88 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+// Defined Media.MediaType constants
+// for the Media Model
+const (
+	MediaTypeImage    = "image"
+	MediaTypeVideo    = "video"
+	MediaTypeAudio    = "audio"
+	MediaTypeDocument = "document"
+)
+
+// Defined MediaScan.ScanStatus constants, media without a status were stored while scanning was disabled
+const (
+	ScanStatusPending  = "pending"  // quarantined until scanned
+	ScanStatusClean    = "clean"    // nothing was found
+	ScanStatusInfected = "infected" // malware was found
+	ScanStatusFailed   = "failed"   // the scanner rejected the content, e.g. above its size limit
+)
 
 type Media struct {
-	Id              primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	ChatId          primitive.ObjectID `json:"chatId" bson:"chatId"`
-	SenderId        primitive.ObjectID `json:"senderId" bson:"senderId"`
-	MediaType       string             `json:"mediaType" bson:"mediaType"`
-	FileName        string             `json:"fileName" bson:"fileName"`
-	FileSize        int                `json:"fileSize" bson:"fileSize"`
-	MediaUrl        string             `json:"mediaUrl" bson:"mediaUrl"`
-	UploadTimestamp primitive.DateTime `json:"uploadTimestamp" bson:"uploadTimestamp"`
+	Id                primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ChatId            primitive.ObjectID `json:"chatId" bson:"chatId"`
+	SenderId          primitive.ObjectID `json:"senderId" bson:"senderId"`
+	MediaType         string             `json:"mediaType" bson:"mediaType"`
+	ContentType       string             `json:"contentType,omitempty" bson:"contentType,omitempty"` // MIME type detected from the content
+	FileName          string             `json:"fileName" bson:"fileName"`
+	FileSize          int                `json:"fileSize" bson:"fileSize"`
+	StorageKey        string             `json:"-" bson:"storageKey,omitempty"` // key of the content in the blob store
+	MediaUrl          string             `json:"mediaUrl" bson:"mediaUrl"`
+	MediaUrlExpiresAt time.Time          `json:"mediaUrlExpiresAt,omitempty" bson:"-"` // set with the signed MediaUrl of stored content
+	UploadTimestamp   primitive.DateTime `json:"uploadTimestamp" bson:"uploadTimestamp"`
+	BlobID            primitive.ObjectID `json:"-" bson:"blobId,omitempty"`                      // the deduplicated content, unset for media stored before deduplication
+	MessageId         primitive.ObjectID `json:"messageId,omitempty" bson:"messageId,omitempty"` // the message that first attached the media, unset until it is sent
+	MediaMetadata     `bson:",inline"`
+	MediaScan         `bson:",inline"`
+}
+
+// MediaScan is the verdict of the malware scanner on the content, only clean or unscanned content is served
+type MediaScan struct {
+	ScanStatus    string `json:"scanStatus,omitempty" bson:"scanStatus,omitempty"`
+	ScanSignature string `json:"-" bson:"scanSignature,omitempty"` // name of the malware found
+}
+
+// Downloadable tells whether the content may be served
+func (s MediaScan) Downloadable() bool {
+	return s.ScanStatus == "" || s.ScanStatus == ScanStatusClean
+}
+
+// MediaMetadata is extracted from the content by the media processors, when they can read it
+type MediaMetadata struct {
+	Width      int              `json:"width,omitempty" bson:"width,omitempty"`
+	Height     int              `json:"height,omitempty" bson:"height,omitempty"`
+	DurationMs int64            `json:"durationMs,omitempty" bson:"durationMs,omitempty"` // of audio & video
+	Blurhash   string           `json:"blurhash,omitempty" bson:"blurhash,omitempty"`     // placeholder shown while images & videos load
+	Waveform   []int            `json:"waveform,omitempty" bson:"waveform,omitempty"`     // peak levels from 0 to 100 of audio
+	Thumbnails []MediaThumbnail `json:"thumbnails,omitempty" bson:"thumbnails,omitempty"` // smallest first
+}
+
+// MediaThumbnail is a downscaled image of an image or the poster of a video
+type MediaThumbnail struct {
+	Size        int    `json:"size" bson:"size"` // the longest edge in pixels, it names the thumbnail
+	Width       int    `json:"width" bson:"width"`
+	Height      int    `json:"height" bson:"height"`
+	ContentType string `json:"contentType" bson:"contentType"`
+	FileSize    int    `json:"fileSize" bson:"fileSize"`
+	StorageKey  string `json:"-" bson:"storageKey"`
+	Url         string `json:"url,omitempty" bson:"-"` // signed like Media.MediaUrl
+}
+
+// MediaTypeOf returns the MediaType of content with the MIME type contentType
+func MediaTypeOf(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return MediaTypeImage
+	case strings.HasPrefix(contentType, "video/"):
+		return MediaTypeVideo
+	case strings.HasPrefix(contentType, "audio/"), contentType == "application/ogg":
+		return MediaTypeAudio
+	default:
+		return MediaTypeDocument
+	}
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
 )
@@ -10,23 +11,98 @@ const (
 // MessageStatus
 )
 
+// Defined Message.MessageType constants
+// for the Message Model
+const (
+	MessageTypeText      = "text"
+	MessageTypeEncrypted = "encrypted"  // end-to-end encrypted, the server only relays Ciphertexts
+	MessageTypeVoiceNote = "voice_note" // a single attached audio media, played receipts tell who listened to it
+)
+
+// DeviceCiphertext is an end-to-end encrypted payload addressed to a single recipient device
+type DeviceCiphertext struct {
+	RecipientID primitive.ObjectID `json:"recipientId" bson:"recipientId"`
+	DeviceID    string             `json:"deviceId" bson:"deviceId"`
+	Type        int                `json:"type" bson:"type"` // session message type as defined by the client protocol e.g. prekey or normal
+	Body        string             `json:"body" bson:"body"` // base64 encoded opaque ciphertext
+}
+
+// VoiceNote is what clients need to render a voice note without fetching its media, as extracted by the server
+type VoiceNote struct {
+	DurationMs int64 `json:"durationMs,omitempty" bson:"durationMs,omitempty"`
+	Waveform   []int `json:"waveform,omitempty" bson:"waveform,omitempty"` // peak levels from 0 to 100
+}
+
 type Message struct {
 	ID                 primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
 	ChatID             primitive.ObjectID   `json:"chatId" bson:"chatId"`
 	SenderID           primitive.ObjectID   `json:"senderId" bson:"senderId"`
 	MessageType        string               `json:"messageType" bson:"messageType"`
 	Content            string               `json:"content,omitempty" bson:"content,omitempty"`
-	MediaUrls          []string             `json:"mediaUrls,omitempty" bson:"mediaUrls,omitempty"`
+	MediaIDs           []primitive.ObjectID `json:"mediaIds,omitempty" bson:"mediaIds,omitempty"`   // uploaded Media attached to the message
+	MediaUrls          []string             `json:"mediaUrls,omitempty" bson:"mediaUrls,omitempty"` // Deprecated: attach uploaded media with MediaIDs
 	Timestamp          primitive.DateTime   `json:"timestamp" bson:"timestamp"`
 	EditedTimestamp    primitive.DateTime   `json:"editedTimestamp,omitempty" bson:"editedTimestamp,omitempty"`
 	EditedMessage      bool                 `json:"editedMessage" bson:"editedMessage"`
 	Status             string               `json:"status" bson:"status"`
 	Mentions           []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty"`
 	RepliedToMessageID primitive.ObjectID   `json:"repliedToMessageId,omitempty" bson:"repliedToMessageId,omitempty"`
+	Encrypted          bool                 `json:"-" bson:"encrypted"`          // Content & MediaUrls are encrypted with the chat's data key
+	HasLinks           bool                 `json:"-" bson:"hasLinks,omitempty"` // Content held links when sent, the encrypted content cannot be searched
+	SenderDeviceID     string               `json:"senderDeviceId,omitempty" bson:"senderDeviceId,omitempty"`
+	Ciphertexts        []DeviceCiphertext   `json:"ciphertexts,omitempty" bson:"ciphertexts,omitempty"`
+	VoiceNote          *VoiceNote           `json:"voiceNote,omitempty" bson:"voiceNote,omitempty"` // set on voice notes from the attached audio
 	CreatedAt          time.Time            `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
 	UpdatedAt          time.Time            `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
 }
 
+// EncryptFields Encrypt message content with the chat's data key before saving
+func (m *Message) EncryptFields(encSvc services.IEncryptionService) error {
+	if m.Encrypted {
+		return nil
+	}
+	if m.Content != "" {
+		encrypted, err := encSvc.Encrypt(m.Content)
+		if err != nil {
+			return err
+		}
+		m.Content = encrypted
+	}
+	for i, mediaUrl := range m.MediaUrls {
+		encrypted, err := encSvc.Encrypt(mediaUrl)
+		if err != nil {
+			return err
+		}
+		m.MediaUrls[i] = encrypted
+	}
+	m.Encrypted = true
+	return nil
+}
+
+// DecryptFields Decrypt message content with the chat's data key after retrieval,
+// messages stored before encryption at rest was introduced are left untouched
+func (m *Message) DecryptFields(encSvc services.IEncryptionService) error {
+	if !m.Encrypted {
+		return nil
+	}
+	if m.Content != "" {
+		decrypted, err := encSvc.Decrypt(m.Content)
+		if err != nil {
+			return err
+		}
+		m.Content = decrypted
+	}
+	for i, mediaUrl := range m.MediaUrls {
+		decrypted, err := encSvc.Decrypt(mediaUrl)
+		if err != nil {
+			return err
+		}
+		m.MediaUrls[i] = decrypted
+	}
+	m.Encrypted = false
+	return nil
+}
+
 //// CreateUniqueIndexes creates unique indexes for username, phoneNumber and email
 //func (m *Message) CreateUniqueIndexes(db *mongo.Database) error {
 //	// Create unique index for username-hash & email+phone-hash
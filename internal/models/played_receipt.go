@@ -0,0 +1,16 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// PlayedReceipt records that a recipient played a voice note. It is kept apart from the read status of the
+// message, a voice note can be read without being listened to.
+type PlayedReceipt struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	MessageID primitive.ObjectID `json:"messageId" bson:"messageId"`
+	ChatID    primitive.ObjectID `json:"chatId" bson:"chatId"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	PlayedAt  time.Time          `json:"playedAt" bson:"playedAt"` // the first time the user played it
+}
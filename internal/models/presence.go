@@ -0,0 +1,49 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// :::: CONSTANTS
+
+// activities of a user in a chat, shown by its other participants until they expire
+const (
+	ChatActivityTyping    = "typing"
+	ChatActivityRecording = "recording" // recording a voice note
+	ChatActivityStopped   = "stopped"
+)
+
+// events of the real-time stream
+const (
+	RealtimeEventPresence = "presence"
+	RealtimeEventActivity = "activity"
+)
+
+// LastSeen records when a user was last connected. It is written lazily, when their last stream disconnects, never
+// while they are online.
+type LastSeen struct {
+	UserID     primitive.ObjectID `json:"userId" bson:"_id"`
+	LastSeenAt time.Time          `json:"lastSeenAt" bson:"lastSeenAt"`
+}
+
+// Presence of a user as a viewer may see it, Online & LastSeenAt are hidden by the user's LastActiveVisibility
+type Presence struct {
+	UserID     primitive.ObjectID `json:"userId"`
+	Online     bool               `json:"online"`
+	LastSeenAt *time.Time         `json:"lastSeenAt,omitempty"`
+}
+
+// ChatActivity tells the participants of a chat that a user is typing or recording, it is never persisted
+type ChatActivity struct {
+	ChatID    primitive.ObjectID `json:"chatId"`
+	UserID    primitive.ObjectID `json:"userId"`
+	Activity  string             `json:"activity"`
+	ExpiresAt *time.Time         `json:"expiresAt,omitempty"` // unless refreshed, absent once stopped
+}
+
+// RealtimeEvent is sent to the connected streams of a user, Data is a Presence or a ChatActivity
+type RealtimeEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// RateWindow counts the calls made under a key in a fixed window, it is deleted once the window ended
+type RateWindow struct {
+	ID        string    `json:"-" bson:"_id"` // the key & the start of the window
+	Calls     int64     `json:"-" bson:"calls"`
+	ExpiresAt time.Time `json:"-" bson:"expiresAt"`
+}
@@ -77,7 +77,7 @@ type Settings struct {
 func (s *Settings) CreateUniqueIndexes(db *mongo.Database) error {
 	// Create unique index for userId
 	userIdIndex := mongo.IndexModel{
-		Keys:    bson.D{{"userId", 1}},
+		Keys:    bson.D{{Key: "userId", Value: 1}},
 		Options: options.Index().SetUnique(true).SetName("unique_user_id"),
 	}
 
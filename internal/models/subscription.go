@@ -1,16 +1,53 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
+// Defined Subscription.PlanType constants
+// for the Subscription Model
+const (
+	PlanFree       = "free"
+	PlanBasic      = "basic"
+	PlanPremium    = "premium"
+	PlanEnterprise = "enterprise"
+)
+
+// Defined Subscription.Status constants
+// for the Subscription Model
+const (
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusExpired  = "expired"
+	SubscriptionStatusCanceled = "canceled"
+)
+
+// Subscription is the plan of a user, users without an active subscription are on the free plan.
+// Enterprise subscriptions are seats of an enterprise, whose members share the storage quota of the enterprise.
 type Subscription struct {
-	Id            primitive.ObjectID //(unique, primary key)
-	userId        primitive.ObjectID //(references users collection)
-	planType      string             //(Free, Basic, Premium, Enterprise)
-	startDate     primitive.DateTime
-	endDate       primitive.DateTime //(optional)
-	status        string             //(active, expired, canceled)
-	paymentMethod string             //(e.g., credit card, PayPal)
-	paymentStatus string             //(paid, pending, failed)
-	createdAt     primitive.DateTime
-	updatedAt     primitive.DateTime
+	Id            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserId        primitive.ObjectID `json:"userId" bson:"userId"`
+	EnterpriseId  primitive.ObjectID `json:"enterpriseId,omitempty" bson:"enterpriseId,omitempty"` // set on enterprise plans
+	PlanType      string             `json:"planType" bson:"planType"`                             // free, basic, premium or enterprise
+	StartDate     time.Time          `json:"startDate" bson:"startDate"`
+	EndDate       time.Time          `json:"endDate,omitempty" bson:"endDate,omitempty"`             // open ended when unset
+	Status        string             `json:"status" bson:"status"`                                   // active, expired or canceled
+	PaymentMethod string             `json:"paymentMethod,omitempty" bson:"paymentMethod,omitempty"` // e.g. credit card, PayPal
+	PaymentStatus string             `json:"paymentStatus,omitempty" bson:"paymentStatus,omitempty"` // paid, pending or failed
+	CreatedAt     time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt     time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+// ActiveAt tells whether the subscription grants its plan at t
+func (s *Subscription) ActiveAt(t time.Time) bool {
+	return s.Status == SubscriptionStatusActive && !s.StartDate.After(t) && (s.EndDate.IsZero() || t.Before(s.EndDate))
+}
+
+// StorageUsage is the storage taken by the media of a sender in a chat of a media type, the sum of Media.FileSize
+// counting each deduplicated blob once
+type StorageUsage struct {
+	ChatId    primitive.ObjectID `json:"chatId" bson:"chatId"`
+	MediaType string             `json:"mediaType" bson:"mediaType"`
+	Bytes     int64              `json:"bytes" bson:"bytes"`
+	Count     int                `json:"count" bson:"count"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Defined Upload.Status constants
+// for the Upload Model
+const (
+	UploadStatusUploading = "uploading"
+	UploadStatusCompleted = "completed"
+)
+
+// Upload is a resumable upload, its chunks are stored as separate parts until they are assembled into the Media
+type Upload struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ChatID      primitive.ObjectID `json:"chatId" bson:"chatId"`
+	SenderID    primitive.ObjectID `json:"senderId" bson:"senderId"`
+	MediaID     primitive.ObjectID `json:"mediaId" bson:"mediaId"`                             // id of the Media the upload completes into, set on creation
+	MediaType   string             `json:"mediaType,omitempty" bson:"mediaType,omitempty"`     // declared by the client
+	ContentType string             `json:"contentType,omitempty" bson:"contentType,omitempty"` // detected from the first chunk
+	FileName    string             `json:"fileName,omitempty" bson:"fileName,omitempty"`
+	Length      int64              `json:"length" bson:"length"`     // size of the whole file
+	Offset      int64              `json:"offset" bson:"offset"`     // bytes received so far
+	Parts       []string           `json:"-" bson:"parts,omitempty"` // blob store keys of the chunks, in order
+	Status      string             `json:"status" bson:"status"`
+	ExpiresAt   time.Time          `json:"expiresAt" bson:"expiresAt"`
+	CreatedAt   time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt   time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
@@ -25,7 +25,7 @@ type User struct {
 	FirstName          string             `json:"firstName" bson:"firstName"`
 	LastName           string             `json:"lastName" bson:"lastName"`
 	Username           string             `json:"username" bson:"username"`
-	UsernameHash       string             `json:"-" bson:"UsernameHash"`
+	UsernameHash       string             `json:"-" bson:"usernameHash"`
 	Password           string             `json:"password,omitempty" bson:"password"`
 	Email              string             `json:"email" bson:"email"`
 	EmailHash          string             `json:"-" bson:"emailHash"`
@@ -42,16 +42,21 @@ type User struct {
 	UpdatedAt          time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
 }
 
+// IsActive reports whether the user may sign in, users created before statuses were set count as active
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
+}
+
 // CreateUniqueIndexes creates unique indexes for username, phoneNumber and email
 func (u *User) CreateUniqueIndexes(db *mongo.Database) error {
 	// Create unique index for username-hash & email+phone-hash
 	usernameHashIndex := mongo.IndexModel{
-		Keys:    bson.D{{"usernameHash", 1}},
+		Keys:    bson.D{{Key: "usernameHash", Value: 1}},
 		Options: options.Index().SetUnique(true).SetName("unique_username_hash"),
 	}
 
 	emailAndPhoneNumberHashIndex := mongo.IndexModel{
-		Keys:    bson.D{{"emailHash", 1}, {"phoneNumberHash", 1}},
+		Keys:    bson.D{{Key: "emailHash", Value: 1}, {Key: "phoneNumberHash", Value: 1}},
 		Options: options.Index().SetUnique(true).SetName("unique_email_and_phone_number_hash"),
 	}
 
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+)
+
+type IBlobRepository interface {
+	// Create records a blob with a single reference, it fails with a conflict when the owner already has its hash
+	Create(ctx context.Context, blob *models.Blob) (*models.Blob, error)
+	// Acquire adds a reference to the blob of ownerId with hash & returns it. An unreferenced blob is acquired
+	// as long as the garbage collector has not deleted it.
+	Acquire(ctx context.Context, ownerId string, hash string) (*models.Blob, error)
+	// Release removes a reference from the blob
+	Release(ctx context.Context, id string) error
+	// ListUnreferenced returns up to limit blobs without references, least recently updated first
+	ListUnreferenced(ctx context.Context, limit int) ([]models.Blob, error)
+	GetByID(ctx context.Context, id string) (*models.Blob, error)
+	// SetScanStatus records the verdict of the malware scanner on the content of the blob
+	SetScanStatus(ctx context.Context, id string, status string, signature string) error
+	// DeleteUnreferenced deletes the blob, it fails with a conflict when the blob was referenced again
+	DeleteUnreferenced(ctx context.Context, id string) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+)
+
+// ErrChatKeyNotFound is returned when a chat has no data key, either it was never created or it has been shredded
+var ErrChatKeyNotFound = apperrors.NotFound(apperrors.CodeChatKeyNotFound, "Chat key not found")
+
+type IChatKeyRepository interface {
+	// GetOrCreateByChatID returns the chat's unwrapped data key, generating one on first use
+	GetOrCreateByChatID(ctx context.Context, chatId string) (*models.ChatKey, error)
+	// GetByChatID returns the chat's unwrapped data key or ErrChatKeyNotFound
+	GetByChatID(ctx context.Context, chatId string) (*models.ChatKey, error)
+	// DeleteByChatID destroys the chat's data key, crypto-shredding all of its messages
+	DeleteByChatID(ctx context.Context, chatId string) error
+}
@@ -6,7 +6,7 @@ import (
 )
 
 type ChatRepository interface {
-	Create(ctx context.Context, chat *models.Chat) error
+	Create(ctx context.Context, chat *models.Chat) (*models.Chat, error)
 	GetByID(ctx context.Context, id string) (*models.Chat, error)
 	List(ctx context.Context, page, limit int) ([]models.Chat, error)
 	ListByUserId(ctx context.Context, id string, page, limit int) ([]models.Chat, error)
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+)
+
+type IDeviceKeyRepository interface {
+	// Upsert creates or replaces a device's identity & signed prekey, then deletes the device's one-time prekeys of
+	// any other identity
+	Upsert(ctx context.Context, deviceKey *models.DeviceKey) (*models.DeviceKey, error)
+	GetByUserID(ctx context.Context, userId string) ([]models.DeviceKey, error)
+	GetByUserIDAndDeviceID(ctx context.Context, userId string, deviceId string) (*models.DeviceKey, error)
+	DeleteByUserIDAndDeviceID(ctx context.Context, userId string, deviceId string) error
+
+	// AddOneTimePreKeys stores new one-time prekeys of the device's identityKey, keys whose keyId already exists are
+	// ignored
+	AddOneTimePreKeys(ctx context.Context, userId string, deviceId string, identityKey string, preKeys []models.OneTimePreKey) error
+	// ConsumeOneTimePreKey atomically removes and returns one of the device's one-time prekeys of identityKey, nil
+	// when none are left. Prekeys of a rotated identity are never handed out, even before Upsert deleted them.
+	ConsumeOneTimePreKey(ctx context.Context, userId string, deviceId string, identityKey string) (*models.OneTimePreKey, error)
+	CountOneTimePreKeys(ctx context.Context, userId string, deviceId string) (int64, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+)
+
+type ILastSeenRepository interface {
+	// Save records when users were last seen, a time before the recorded one of a user is ignored
+	Save(ctx context.Context, lastSeen []models.LastSeen) error
+	// GetByUserId returns when the user was last seen
+	GetByUserId(ctx context.Context, userId string) (*models.LastSeen, error)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type MediaRepository interface {
@@ -13,4 +14,19 @@ type MediaRepository interface {
 	List(ctx context.Context, page, limit int) ([]models.Media, error)
 	Update(ctx context.Context, media *models.Media) error
 	Delete(ctx context.Context, id string) error
+	// AttachToMessage sets the message of the media that no message attached yet, a media keeps its first message
+	AttachToMessage(ctx context.Context, messageId string, mediaIds []primitive.ObjectID) error
+	// DetachFromMessage unsets the message of the media it attached
+	DetachFromMessage(ctx context.Context, messageId string) error
+	// ListGallery lists the media of a chat of mediaType that messages attached, newest first. It continues after
+	// the media with the id before unless it is empty.
+	ListGallery(ctx context.Context, chatId string, mediaType string, before string, limit int) ([]models.Media, error)
+	// ListByScanStatus returns up to limit media with the scan status, oldest first
+	ListByScanStatus(ctx context.Context, status string, limit int) ([]models.Media, error)
+	// SetScanStatus records the verdict of the malware scanner on the content of the media
+	SetScanStatus(ctx context.Context, id string, status string, signature string) error
+	// StorageUsage sums the bytes stored for the media the senders uploaded per chat & media type, counting only the
+	// media up to the id until unless it is zero. Media sharing a deduplicated blob are stored once: the blob's bytes
+	// count in the group of the first of them & the others count 0 bytes.
+	StorageUsage(ctx context.Context, senderIds []primitive.ObjectID, until primitive.ObjectID) ([]models.StorageUsage, error)
 }
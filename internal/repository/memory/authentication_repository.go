@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"time"
+)
+
+type authenticationRepository struct {
+	authentications  *collection[models.Authentication]
+	SearchKeyHashSvc services.ISearchKeyService
+}
+
+func NewAuthenticationRepository(keyHashSvc services.ISearchKeyService) repository.IAuthenticationRepository {
+	return &authenticationRepository{
+		authentications: newCollection[models.Authentication]("Authentication", apperrors.CodeNotFound,
+			func(auth *models.Authentication) string { return auth.UserID.Hex() },
+			func(auth *models.Authentication) string { return auth.RefreshTokenHash },
+		),
+		SearchKeyHashSvc: keyHashSvc,
+	}
+}
+
+func (a authenticationRepository) Create(_ context.Context, auth *models.Authentication) (*models.Authentication, error) {
+	id := newID(auth.ID)
+	doc := *auth
+	doc.ID = id
+	if err := a.authentications.insert(id, &doc); err != nil {
+		return nil, err
+	}
+	auth.ID = id
+	return auth, nil
+}
+
+func (a authenticationRepository) GetList(_ context.Context) (*[]models.Authentication, error) {
+	authList, err := a.authentications.list(nil, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &authList, nil
+}
+
+func (a authenticationRepository) GetByUserID(_ context.Context, userID string) (*models.Authentication, error) {
+	ID, err := a.authentications.parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return a.authentications.first(func(auth *models.Authentication) bool { return auth.UserID == ID })
+}
+
+func (a authenticationRepository) UpdateByUserID(ctx context.Context, userID string, auth *models.Authentication) (*models.Authentication, error) {
+	existing, err := a.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = a.authentications.update(existing.ID, auth); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+func (a authenticationRepository) Delete(_ context.Context, ID string) error {
+	return a.authentications.deleteByID(ID)
+}
+
+func (a authenticationRepository) DeleteByUserID(_ context.Context, userID string) error {
+	ID, err := a.authentications.parseID(userID)
+	if err != nil {
+		return err
+	}
+	_, err = a.authentications.deleteWhere(func(auth *models.Authentication) bool { return auth.UserID == ID })
+	return err
+}
+
+// SaveRefreshToken updates refresh token and adds a fresh one if it does not exist for the user
+func (a authenticationRepository) SaveRefreshToken(ctx context.Context, userID string, refreshToken string, tokenDuration time.Duration) error {
+	ID, err := a.authentications.parseID(userID)
+	if err != nil {
+		return err
+	}
+	refreshTokenHash, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	for {
+		auth, err := a.GetByUserID(ctx, userID)
+		exists := err == nil
+		if !exists {
+			if !errors.Is(err, apperrors.ErrNotFound) {
+				return err
+			}
+			auth = models.GetAuthenticationDefaults()
+			auth.ID = newID(auth.ID)
+			auth.UserID = ID
+		}
+
+		auth.RefreshTokenHash = refreshTokenHash
+		auth.IsActive = true // a previous token may have been revoked
+		auth.UpdatedAt = time.Now()
+		auth.LastLogin = time.Now()
+		auth.ExpiresAt = time.Now().Add(tokenDuration)
+
+		if exists {
+			_, err = a.authentications.update(auth.ID, auth)
+			return err
+		}
+		err = a.authentications.insert(auth.ID, auth)
+		if errors.Is(err, apperrors.ErrConflict) {
+			// retry as an update when a concurrent save created the user's document first
+			if _, lookupErr := a.GetByUserID(ctx, userID); lookupErr == nil {
+				continue
+			}
+		}
+		return err
+	}
+}
+
+// activeToken returns the active, unexpired authentication holding refreshToken
+func (a authenticationRepository) activeToken(refreshToken string) (*models.Authentication, error) {
+	if refreshToken == "" {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Refresh token is required", apperrors.RequiredField("refreshToken"))
+	}
+	hashedRefreshToken, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	auth, err := a.authentications.first(func(auth *models.Authentication) bool {
+		return auth.RefreshTokenHash == hashedRefreshToken && auth.IsActive && auth.ExpiresAt.After(now)
+	})
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, apperrors.Unauthorized(apperrors.CodeInvalidRefreshToken, "Invalid or expired refresh token").WithErr(err)
+	}
+	return auth, err
+}
+
+func (a authenticationRepository) GetUserIDFromRefreshToken(_ context.Context, refreshToken string) (string, error) {
+	auth, err := a.activeToken(refreshToken)
+	if err != nil {
+		return "", err
+	}
+	return auth.UserID.Hex(), nil
+}
+
+func (a authenticationRepository) RevokeRefreshToken(_ context.Context, refreshToken string) error {
+	auth, err := a.activeToken(refreshToken)
+	if err != nil {
+		return err
+	}
+	// deactivate token & expire it
+	auth.IsActive = false
+	auth.ExpiresAt = time.Now()
+	_, err = a.authentications.update(auth.ID, auth)
+	return err
+}
+
+func (a authenticationRepository) DeleteRefreshToken(_ context.Context, refreshToken string) error {
+	hashedRefreshToken, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
+	if err != nil {
+		return err
+	}
+	_, err = a.authentications.deleteWhere(func(auth *models.Authentication) bool { return auth.RefreshTokenHash == hashedRefreshToken })
+	return err
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"sort"
+	"sync"
+	"time"
+)
+
+type blobRepository struct {
+	mu    sync.Mutex // serializes the reference counting, like MongoDB's atomic $inc
+	blobs *collection[models.Blob]
+}
+
+func NewBlobRepository() repository.IBlobRepository {
+	return &blobRepository{blobs: newCollection[models.Blob]("Blob", apperrors.CodeBlobNotFound,
+		func(blob *models.Blob) string { return blob.OwnerID.Hex() + "/" + blob.Hash },
+	)}
+}
+
+func (b *blobRepository) Create(_ context.Context, blob *models.Blob) (*models.Blob, error) {
+	created := *blob
+	created.ID = newID(created.ID)
+	created.RefCount = 1
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if err := b.blobs.insert(created.ID, &created); err != nil {
+		return nil, err
+	}
+	return b.blobs.byID(created.ID.Hex())
+}
+
+func (b *blobRepository) Acquire(_ context.Context, ownerId string, hash string) (*models.Blob, error) {
+	ownerID, err := b.blobs.parseID(ownerId)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blob, err := b.blobs.first(func(blob *models.Blob) bool { return blob.OwnerID == ownerID && blob.Hash == hash })
+	if err != nil {
+		return nil, err
+	}
+	return b.blobs.update(blob.ID, bson.M{"refCount": blob.RefCount + 1, "updatedAt": time.Now()})
+}
+
+func (b *blobRepository) Release(_ context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blob, err := b.blobs.byID(id)
+	if err != nil {
+		return err
+	}
+	if blob.RefCount <= 0 {
+		return b.blobs.notFound()
+	}
+	_, err = b.blobs.update(blob.ID, bson.M{"refCount": blob.RefCount - 1, "updatedAt": time.Now()})
+	return err
+}
+
+func (b *blobRepository) ListUnreferenced(_ context.Context, limit int) ([]models.Blob, error) {
+	blobs, err := b.blobs.list(func(blob *models.Blob) bool { return blob.RefCount <= 0 }, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(blobs, func(i, j int) bool { return blobs[i].UpdatedAt.Before(blobs[j].UpdatedAt) })
+	if limit > 0 && len(blobs) > limit {
+		blobs = blobs[:limit]
+	}
+	return blobs, nil
+}
+
+func (b *blobRepository) GetByID(_ context.Context, id string) (*models.Blob, error) {
+	return b.blobs.byID(id)
+}
+
+func (b *blobRepository) SetScanStatus(_ context.Context, id string, status string, signature string) error {
+	blobID, err := b.blobs.parseID(id)
+	if err != nil {
+		return err
+	}
+	_, err = b.blobs.update(blobID, bson.M{"scanStatus": status, "scanSignature": signature, "updatedAt": time.Now()})
+	return err
+}
+
+func (b *blobRepository) DeleteUnreferenced(_ context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blob, err := b.blobs.byID(id)
+	if err != nil {
+		return err
+	}
+	if blob.RefCount > 0 {
+		return apperrors.Conflict(apperrors.CodeBlobReferenced, "The blob is referenced")
+	}
+	return b.blobs.deleteByID(id)
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+)
+
+type chatGroupRepository struct {
+	chatGroups *collection[models.ChatGroup]
+}
+
+func NewChatGroupRepository() repository.ChatGroupRepository {
+	return &chatGroupRepository{chatGroups: newCollection[models.ChatGroup]("Chat group", apperrors.CodeChatGroupNotFound)}
+}
+
+func (c chatGroupRepository) Create(_ context.Context, chatGroup *models.ChatGroup) error {
+	id := newID(chatGroup.ID)
+	doc := *chatGroup
+	doc.ID = id
+	if err := c.chatGroups.insert(id, &doc); err != nil {
+		return err
+	}
+	chatGroup.ID = id
+	return nil
+}
+
+func (c chatGroupRepository) GetByID(_ context.Context, id string) (*models.ChatGroup, error) {
+	return c.chatGroups.byID(id)
+}
+
+func (c chatGroupRepository) List(_ context.Context, page, limit int) ([]models.ChatGroup, error) {
+	return c.chatGroups.list(nil, page, limit)
+}
+
+func (c chatGroupRepository) Update(_ context.Context, chatGroup *models.ChatGroup) error {
+	_, err := c.chatGroups.update(chatGroup.ID, chatGroup)
+	return err
+}
+
+func (c chatGroupRepository) UpdateWithFilter(_ context.Context, chatGroupId string, updateData map[string]interface{}) error {
+	objectID, err := c.chatGroups.parseID(chatGroupId)
+	if err != nil {
+		return err
+	}
+	_, err = c.chatGroups.update(objectID, updateData)
+	return err
+}
+
+func (c chatGroupRepository) Delete(_ context.Context, id string) error {
+	return c.chatGroups.deleteByID(id)
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+)
+
+type chatKeyRepository struct {
+	chatKeys          *collection[models.ChatKey]
+	EncryptionService services.IEncryptionService // master key, wraps the per-chat data keys
+}
+
+func NewChatKeyRepository(encryptSvc services.IEncryptionService) repository.IChatKeyRepository {
+	return &chatKeyRepository{
+		chatKeys: newCollection[models.ChatKey]("Chat key", apperrors.CodeChatKeyNotFound,
+			func(chatKey *models.ChatKey) string { return chatKey.ChatID.Hex() },
+		),
+		EncryptionService: encryptSvc,
+	}
+}
+
+func (k chatKeyRepository) GetOrCreateByChatID(ctx context.Context, chatId string) (*models.ChatKey, error) {
+	chatID, err := k.chatKeys.parseID(chatId)
+	if err != nil {
+		return nil, err
+	}
+	chatKey, err := models.NewChatKey(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if err = chatKey.EncryptFields(k.EncryptionService); err != nil {
+		return nil, err
+	}
+
+	// the unique chatId keeps the first key when concurrent writers race on a new chat
+	chatKey.ID = newID(chatKey.ID)
+	err = k.chatKeys.insert(chatKey.ID, chatKey)
+	if err != nil && !errors.Is(err, apperrors.ErrConflict) {
+		return nil, err
+	}
+	return k.GetByChatID(ctx, chatId)
+}
+
+func (k chatKeyRepository) GetByChatID(_ context.Context, chatId string) (*models.ChatKey, error) {
+	chatID, err := k.chatKeys.parseID(chatId)
+	if err != nil {
+		return nil, err
+	}
+	chatKey, err := k.chatKeys.first(func(chatKey *models.ChatKey) bool { return chatKey.ChatID == chatID })
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, repository.ErrChatKeyNotFound
+		}
+		return nil, err
+	}
+	if err = chatKey.DecryptFields(k.EncryptionService); err != nil {
+		return nil, err
+	}
+	return chatKey, nil
+}
+
+func (k chatKeyRepository) DeleteByChatID(_ context.Context, chatId string) error {
+	chatID, err := k.chatKeys.parseID(chatId)
+	if err != nil {
+		return err
+	}
+	_, err = k.chatKeys.deleteWhere(func(chatKey *models.ChatKey) bool { return chatKey.ChatID == chatID })
+	return err
+}
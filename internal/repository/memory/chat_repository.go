@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+)
+
+type chatRepository struct {
+	chats *collection[models.Chat]
+}
+
+func NewChatRepository() repository.ChatRepository {
+	return &chatRepository{chats: newCollection[models.Chat]("Chat", apperrors.CodeChatNotFound)}
+}
+
+func (c chatRepository) Create(_ context.Context, chat *models.Chat) (*models.Chat, error) {
+	id := newID(chat.ID)
+	doc := *chat
+	doc.ID = id
+	if err := c.chats.insert(id, &doc); err != nil {
+		return nil, err
+	}
+	chat.ID = id
+	return chat, nil
+}
+
+func (c chatRepository) GetByID(_ context.Context, id string) (*models.Chat, error) {
+	return c.chats.byID(id)
+}
+
+func (c chatRepository) List(_ context.Context, page, limit int) ([]models.Chat, error) {
+	return c.chats.list(nil, page, limit)
+}
+
+func (c chatRepository) ListByUserId(_ context.Context, id string, page, limit int) ([]models.Chat, error) {
+	participantID, err := c.chats.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.chats.list(func(chat *models.Chat) bool {
+		for _, participant := range chat.Participants {
+			if participant == participantID {
+				return true
+			}
+		}
+		return false
+	}, page, limit)
+}
+
+func (c chatRepository) Update(_ context.Context, chat *models.Chat) error {
+	_, err := c.chats.update(chat.ID, chat)
+	return err
+}
+
+func (c chatRepository) Delete(_ context.Context, id string) error {
+	return c.chats.deleteByID(id)
+}
@@ -0,0 +1,334 @@
+// Package memory implements the repository interfaces in memory, for tests & local development without MongoDB.
+// The implementations follow the MongoDB ones: documents are stored bson encoded (so omitempty fields &
+// millisecond timestamps round-trip the same way), listed in _id order, unique indexes are enforced
+// & errors are the same apperrors.
+package memory
+
+import (
+	"bytes"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"sync"
+)
+
+// collection is a thread-safe set of documents keyed by _id, the in-memory counterpart of a MongoDB collection
+type collection[T any] struct {
+	mu           sync.RWMutex
+	docs         map[primitive.ObjectID][]byte
+	resource     string                // names the entity in error messages
+	notFoundCode string                // code of the errors for missing documents
+	unique       []func(doc *T) string // keys of the unique indexes, documents with an empty key are not indexed
+}
+
+func newCollection[T any](resource string, notFoundCode string, unique ...func(doc *T) string) *collection[T] {
+	return &collection[T]{
+		docs:         map[primitive.ObjectID][]byte{},
+		resource:     resource,
+		notFoundCode: notFoundCode,
+		unique:       unique,
+	}
+}
+
+// insert stores doc under id, it fails with a conflict when id or a unique key is taken
+func (c *collection[T]) insert(id primitive.ObjectID, doc *T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.docs[id]; exists {
+		return c.duplicate()
+	}
+	if err := c.checkUnique(id, doc); err != nil {
+		return err
+	}
+	return c.put(id, doc)
+}
+
+// byID returns the document with the hex id, malformed ids are validation errors & missing documents not found errors
+func (c *collection[T]) byID(id string) (*T, error) {
+	objectID, err := c.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	doc, found, err := c.get(objectID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, c.notFound()
+	}
+	return doc, nil
+}
+
+// first returns the oldest document matching filter or a not found error
+func (c *collection[T]) first(filter func(doc *T) bool) (*T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	docs, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, c.notFound()
+	}
+	return &docs[0], nil
+}
+
+// list returns a 1-based page of the documents matching filter in _id order, pages below 1 are the first page
+// & a limit of 0 or less returns every document. A nil filter matches every document.
+func (c *collection[T]) list(filter func(doc *T) bool, page, limit int) ([]T, error) {
+	c.mu.RLock()
+	docs, err := c.find(filter)
+	c.mu.RUnlock()
+	if err != nil || limit <= 0 {
+		return docs, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * limit
+	if start >= len(docs) {
+		return nil, nil
+	}
+	end := start + limit
+	if end > len(docs) {
+		end = len(docs)
+	}
+	return docs[start:end], nil
+}
+
+// listBefore returns the documents matching filter newest first like a cursor page, continuing after the document
+// with the hex id before unless it is empty. A limit of 0 or less returns every document.
+func (c *collection[T]) listBefore(filter func(doc *T) bool, before string, limit int) ([]T, error) {
+	var beforeID primitive.ObjectID
+	if before != "" {
+		var err error
+		if beforeID, err = c.parseID(before); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var docs []T
+	ids := c.sortedIDs()
+	for i := len(ids) - 1; i >= 0; i-- {
+		id := ids[i]
+		if len(docs) == limit && limit > 0 {
+			break
+		}
+		if !beforeID.IsZero() && bytes.Compare(id[:], beforeID[:]) >= 0 {
+			continue
+		}
+		doc, _, err := c.get(id)
+		if err != nil {
+			return nil, err
+		}
+		if filter == nil || filter(doc) {
+			docs = append(docs, *doc)
+		}
+	}
+	return docs, nil
+}
+
+// update applies the fields of update to the document stored under id like a MongoDB $set & returns the result,
+// fields update omits keep their stored value
+func (c *collection[T]) update(id primitive.ObjectID, update interface{}) (*T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc, found, err := c.merged(id, update)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, c.notFound()
+	}
+	if err = c.checkUnique(id, doc); err != nil {
+		return nil, err
+	}
+	if err = c.put(id, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// replace stores doc under id like a MongoDB replaceOne, unlike update the fields doc omits are removed
+func (c *collection[T]) replace(id primitive.ObjectID, doc *T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.docs[id]; !found {
+		return c.notFound()
+	}
+	if err := c.checkUnique(id, doc); err != nil {
+		return err
+	}
+	return c.put(id, doc)
+}
+
+// deleteByID removes the document with the hex id, deleting a missing document is not an error
+func (c *collection[T]) deleteByID(id string) error {
+	objectID, err := c.parseID(id)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.docs, objectID)
+	return nil
+}
+
+// deleteWhere removes the documents matching filter & returns how many were removed
+func (c *collection[T]) deleteWhere(filter func(doc *T) bool) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deleted := 0
+	for id := range c.docs {
+		doc, _, err := c.get(id)
+		if err != nil {
+			return deleted, err
+		}
+		if filter(doc) {
+			delete(c.docs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// :::: helpers, the callers hold the lock
+
+func (c *collection[T]) put(id primitive.ObjectID, doc *T) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	c.docs[id] = raw
+	return nil
+}
+
+func (c *collection[T]) get(id primitive.ObjectID) (*T, bool, error) {
+	raw, ok := c.docs[id]
+	if !ok {
+		return nil, false, nil
+	}
+	doc := new(T)
+	if err := bson.Unmarshal(raw, doc); err != nil {
+		return nil, false, err
+	}
+	return doc, true, nil
+}
+
+func (c *collection[T]) find(filter func(doc *T) bool) ([]T, error) {
+	var docs []T
+	for _, id := range c.sortedIDs() {
+		doc, _, err := c.get(id)
+		if err != nil {
+			return nil, err
+		}
+		if filter == nil || filter(doc) {
+			docs = append(docs, *doc)
+		}
+	}
+	return docs, nil
+}
+
+// sortedIDs returns the ids in _id order
+func (c *collection[T]) sortedIDs() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(c.docs))
+	for id := range c.docs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+	return ids
+}
+
+func (c *collection[T]) merged(id primitive.ObjectID, update interface{}) (*T, bool, error) {
+	raw, ok := c.docs[id]
+	if !ok {
+		return nil, false, nil
+	}
+	var stored, fields bson.D
+	if err := bson.Unmarshal(raw, &stored); err != nil {
+		return nil, false, err
+	}
+	updateRaw, err := bson.Marshal(update)
+	if err != nil {
+		return nil, false, err
+	}
+	if err = bson.Unmarshal(updateRaw, &fields); err != nil {
+		return nil, false, err
+	}
+
+	for _, field := range fields {
+		if field.Key == "_id" {
+			continue
+		}
+		replaced := false
+		for i := range stored {
+			if stored[i].Key == field.Key {
+				stored[i].Value = field.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			stored = append(stored, field)
+		}
+	}
+
+	if raw, err = bson.Marshal(stored); err != nil {
+		return nil, false, err
+	}
+	doc := new(T)
+	if err = bson.Unmarshal(raw, doc); err != nil {
+		return nil, false, err
+	}
+	return doc, true, nil
+}
+
+// checkUnique fails when a document other than the one stored under id has one of doc's unique keys
+func (c *collection[T]) checkUnique(id primitive.ObjectID, doc *T) error {
+	if len(c.unique) == 0 {
+		return nil
+	}
+	for otherID := range c.docs {
+		if otherID == id {
+			continue
+		}
+		other, _, err := c.get(otherID)
+		if err != nil {
+			return err
+		}
+		for _, key := range c.unique {
+			if want := key(doc); want != "" && key(other) == want {
+				return c.duplicate()
+			}
+		}
+	}
+	return nil
+}
+
+func (c *collection[T]) parseID(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, apperrors.Validation(apperrors.CodeInvalidID, "Invalid "+c.resource+" id").WithErr(err)
+	}
+	return objectID, nil
+}
+
+func (c *collection[T]) notFound() error {
+	return apperrors.NotFound(c.notFoundCode, c.resource+" not found")
+}
+
+func (c *collection[T]) duplicate() error {
+	return apperrors.Conflict(apperrors.CodeDuplicate, c.resource+" already exists")
+}
+
+// newID returns id, or a new ObjectID when it is not set
+func newID(id primitive.ObjectID) primitive.ObjectID {
+	if id.IsZero() {
+		return primitive.NewObjectID()
+	}
+	return id
+}
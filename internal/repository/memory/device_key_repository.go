@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+type deviceKeyRepository struct {
+	deviceKeys *collection[models.DeviceKey]     // device identity & signed prekeys
+	preKeys    *collection[models.OneTimePreKey] // one-time prekeys
+}
+
+func NewDeviceKeyRepository() repository.IDeviceKeyRepository {
+	return &deviceKeyRepository{
+		deviceKeys: newCollection[models.DeviceKey]("Device", apperrors.CodeDeviceNotFound,
+			func(deviceKey *models.DeviceKey) string { return deviceKey.UserID.Hex() + "\x00" + deviceKey.DeviceID },
+		),
+		preKeys: newCollection[models.OneTimePreKey]("Device", apperrors.CodeDeviceNotFound,
+			func(preKey *models.OneTimePreKey) string {
+				return fmt.Sprintf("%s\x00%s\x00%d", preKey.UserID.Hex(), preKey.DeviceID, preKey.KeyID)
+			},
+		),
+	}
+}
+
+func (d deviceKeyRepository) device(userID primitive.ObjectID, deviceId string) func(deviceKey *models.DeviceKey) bool {
+	return func(deviceKey *models.DeviceKey) bool {
+		return deviceKey.UserID == userID && deviceKey.DeviceID == deviceId
+	}
+}
+
+func (d deviceKeyRepository) devicePreKeys(userID primitive.ObjectID, deviceId string) func(preKey *models.OneTimePreKey) bool {
+	return func(preKey *models.OneTimePreKey) bool {
+		return preKey.UserID == userID && preKey.DeviceID == deviceId
+	}
+}
+
+func (d deviceKeyRepository) Upsert(_ context.Context, deviceKey *models.DeviceKey) (*models.DeviceKey, error) {
+	for {
+		existing, err := d.deviceKeys.first(d.device(deviceKey.UserID, deviceKey.DeviceID))
+		if err == nil {
+			updated, err := d.deviceKeys.update(existing.ID, bson.M{
+				"identityKey":  deviceKey.IdentityKey,
+				"signedPreKey": deviceKey.SignedPreKey,
+				"updatedAt":    time.Now(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err = d.deleteStalePreKeys(deviceKey); err != nil {
+				return nil, err
+			}
+			return updated, nil
+		}
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			return nil, err
+		}
+
+		now := time.Now()
+		created := &models.DeviceKey{
+			ID:           primitive.NewObjectID(),
+			UserID:       deviceKey.UserID,
+			DeviceID:     deviceKey.DeviceID,
+			IdentityKey:  deviceKey.IdentityKey,
+			SignedPreKey: deviceKey.SignedPreKey,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		err = d.deviceKeys.insert(created.ID, created)
+		if err == nil {
+			if err = d.deleteStalePreKeys(deviceKey); err != nil {
+				return nil, err
+			}
+			return d.deviceKeys.byID(created.ID.Hex())
+		}
+		// a concurrent upsert created the device first, update it instead
+		if !errors.Is(err, apperrors.ErrConflict) {
+			return nil, err
+		}
+	}
+}
+
+// deleteStalePreKeys deletes the device's one-time prekeys of any identity but the current one
+func (d deviceKeyRepository) deleteStalePreKeys(deviceKey *models.DeviceKey) error {
+	devicePreKeys := d.devicePreKeys(deviceKey.UserID, deviceKey.DeviceID)
+	_, err := d.preKeys.deleteWhere(func(preKey *models.OneTimePreKey) bool {
+		return devicePreKeys(preKey) && preKey.IdentityKey != deviceKey.IdentityKey
+	})
+	return err
+}
+
+func (d deviceKeyRepository) GetByUserID(_ context.Context, userId string) ([]models.DeviceKey, error) {
+	userID, err := d.deviceKeys.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+	return d.deviceKeys.list(func(deviceKey *models.DeviceKey) bool { return deviceKey.UserID == userID }, 1, 0)
+}
+
+func (d deviceKeyRepository) GetByUserIDAndDeviceID(_ context.Context, userId string, deviceId string) (*models.DeviceKey, error) {
+	userID, err := d.deviceKeys.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+	return d.deviceKeys.first(d.device(userID, deviceId))
+}
+
+func (d deviceKeyRepository) DeleteByUserIDAndDeviceID(_ context.Context, userId string, deviceId string) error {
+	userID, err := d.deviceKeys.parseID(userId)
+	if err != nil {
+		return err
+	}
+	if _, err = d.preKeys.deleteWhere(d.devicePreKeys(userID, deviceId)); err != nil {
+		return err
+	}
+	_, err = d.deviceKeys.deleteWhere(d.device(userID, deviceId))
+	return err
+}
+
+func (d deviceKeyRepository) AddOneTimePreKeys(_ context.Context, userId string, deviceId string, identityKey string, preKeys []models.OneTimePreKey) error {
+	if len(preKeys) == 0 {
+		return nil
+	}
+	userID, err := d.preKeys.parseID(userId)
+	if err != nil {
+		return err
+	}
+
+	for _, preKey := range preKeys {
+		preKey.ID = primitive.NewObjectID()
+		preKey.UserID = userID
+		preKey.DeviceID = deviceId
+		preKey.IdentityKey = identityKey
+		preKey.CreatedAt = time.Now()
+		// already uploaded keyIds are skipped without stopping the batch
+		err = d.preKeys.insert(preKey.ID, &preKey)
+		if err != nil && !errors.Is(err, apperrors.ErrConflict) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d deviceKeyRepository) ConsumeOneTimePreKey(_ context.Context, userId string, deviceId string, identityKey string) (*models.OneTimePreKey, error) {
+	userID, err := d.preKeys.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		devicePreKeys := d.devicePreKeys(userID, deviceId)
+		preKeys, err := d.preKeys.list(func(preKey *models.OneTimePreKey) bool {
+			return devicePreKeys(preKey) && preKey.IdentityKey == identityKey
+		}, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(preKeys) == 0 {
+			return nil, nil
+		}
+		sort.Slice(preKeys, func(i, j int) bool { return preKeys[i].KeyID < preKeys[j].KeyID })
+
+		// a prekey is handed out to exactly one peer, when a concurrent caller deleted it first try the next one
+		preKey := preKeys[0]
+		deleted, err := d.preKeys.deleteWhere(func(other *models.OneTimePreKey) bool { return other.ID == preKey.ID })
+		if err != nil {
+			return nil, err
+		}
+		if deleted == 1 {
+			return &preKey, nil
+		}
+	}
+}
+
+func (d deviceKeyRepository) CountOneTimePreKeys(_ context.Context, userId string, deviceId string) (int64, error) {
+	userID, err := d.preKeys.parseID(userId)
+	if err != nil {
+		return 0, err
+	}
+	preKeys, err := d.preKeys.list(d.devicePreKeys(userID, deviceId), 1, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(preKeys)), nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+)
+
+type highlightRepository struct {
+	highlights *collection[models.Highlight]
+}
+
+func NewHighlightRepository() repository.HighlightRepository {
+	return &highlightRepository{highlights: newCollection[models.Highlight]("Highlight", apperrors.CodeHighlightNotFound)}
+}
+
+func (h highlightRepository) Create(_ context.Context, highlight *models.Highlight) error {
+	id := newID(highlight.Id)
+	doc := *highlight
+	doc.Id = id
+	if err := h.highlights.insert(id, &doc); err != nil {
+		return err
+	}
+	highlight.Id = id
+	return nil
+}
+
+func (h highlightRepository) GetByID(_ context.Context, id string) (*models.Highlight, error) {
+	return h.highlights.byID(id)
+}
+
+func (h highlightRepository) GetByUserId(_ context.Context, userId string, page, limit int) ([]models.Highlight, error) {
+	userID, err := h.highlights.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+	return h.highlights.list(func(highlight *models.Highlight) bool { return highlight.UserId == userID }, page, limit)
+}
+
+func (h highlightRepository) List(_ context.Context, page, limit int) ([]models.Highlight, error) {
+	return h.highlights.list(nil, page, limit)
+}
+
+func (h highlightRepository) Update(_ context.Context, highlight *models.Highlight) error {
+	_, err := h.highlights.update(highlight.Id, highlight)
+	return err
+}
+
+func (h highlightRepository) Delete(_ context.Context, id string) error {
+	return h.highlights.deleteByID(id)
+}
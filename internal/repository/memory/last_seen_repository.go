@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"sync"
+)
+
+type lastSeenRepository struct {
+	mu       sync.Mutex // makes saving atomic, like MongoDB's $max upsert
+	lastSeen *collection[models.LastSeen]
+}
+
+func NewLastSeenRepository() repository.ILastSeenRepository {
+	return &lastSeenRepository{lastSeen: newCollection[models.LastSeen]("LastSeen", apperrors.CodeLastSeenNotFound)}
+}
+
+func (l *lastSeenRepository) Save(_ context.Context, lastSeen []models.LastSeen) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range lastSeen {
+		seen := lastSeen[i]
+		existing, err := l.lastSeen.byID(seen.UserID.Hex())
+		if errors.Is(err, apperrors.ErrNotFound) {
+			if err = l.lastSeen.insert(seen.UserID, &seen); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if seen.LastSeenAt.After(existing.LastSeenAt) {
+			if err = l.lastSeen.replace(seen.UserID, &seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *lastSeenRepository) GetByUserId(_ context.Context, userId string) (*models.LastSeen, error) {
+	return l.lastSeen.byID(userId)
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"slices"
+)
+
+type mediaRepository struct {
+	medias *collection[models.Media]
+}
+
+func NewMediaRepository() repository.MediaRepository {
+	return &mediaRepository{medias: newCollection[models.Media]("Media", apperrors.CodeMediaNotFound)}
+}
+
+func (m mediaRepository) Create(_ context.Context, media *models.Media) error {
+	id := newID(media.Id)
+	doc := *media
+	doc.Id = id
+	if err := m.medias.insert(id, &doc); err != nil {
+		return err
+	}
+	media.Id = id
+	return nil
+}
+
+func (m mediaRepository) GetByID(_ context.Context, id string) (*models.Media, error) {
+	return m.medias.byID(id)
+}
+
+func (m mediaRepository) GetByChatId(_ context.Context, chatId string, page, limit int) ([]models.Media, error) {
+	chatID, err := m.medias.parseID(chatId)
+	if err != nil {
+		return nil, err
+	}
+	return m.medias.list(func(media *models.Media) bool { return media.ChatId == chatID }, page, limit)
+}
+
+func (m mediaRepository) GetBySenderId(_ context.Context, senderId string, page, limit int) ([]models.Media, error) {
+	senderID, err := m.medias.parseID(senderId)
+	if err != nil {
+		return nil, err
+	}
+	return m.medias.list(func(media *models.Media) bool { return media.SenderId == senderID }, page, limit)
+}
+
+func (m mediaRepository) List(_ context.Context, page, limit int) ([]models.Media, error) {
+	return m.medias.list(nil, page, limit)
+}
+
+func (m mediaRepository) Update(_ context.Context, media *models.Media) error {
+	_, err := m.medias.update(media.Id, media)
+	return err
+}
+
+func (m mediaRepository) Delete(_ context.Context, id string) error {
+	return m.medias.deleteByID(id)
+}
+
+func (m mediaRepository) AttachToMessage(_ context.Context, messageId string, mediaIds []primitive.ObjectID) error {
+	messageID, err := m.medias.parseID(messageId)
+	if err != nil {
+		return err
+	}
+	for _, id := range mediaIds {
+		media, err := m.medias.byID(id.Hex())
+		if errors.Is(err, apperrors.ErrNotFound) || (err == nil && !media.MessageId.IsZero()) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = m.medias.update(id, bson.M{"messageId": messageID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m mediaRepository) DetachFromMessage(_ context.Context, messageId string) error {
+	messageID, err := m.medias.parseID(messageId)
+	if err != nil {
+		return err
+	}
+	attached, err := m.medias.list(func(media *models.Media) bool { return media.MessageId == messageID }, 1, 0)
+	if err != nil {
+		return err
+	}
+	for _, media := range attached {
+		media.MessageId = primitive.NilObjectID
+		if err = m.medias.replace(media.Id, &media); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m mediaRepository) ListGallery(_ context.Context, chatId string, mediaType string, before string, limit int) ([]models.Media, error) {
+	chatID, err := m.medias.parseID(chatId)
+	if err != nil {
+		return nil, err
+	}
+	return m.medias.listBefore(func(media *models.Media) bool {
+		return media.ChatId == chatID && media.MediaType == mediaType && !media.MessageId.IsZero()
+	}, before, limit)
+}
+
+func (m mediaRepository) ListByScanStatus(_ context.Context, status string, limit int) ([]models.Media, error) {
+	return m.medias.list(func(media *models.Media) bool { return media.ScanStatus == status }, 1, limit)
+}
+
+func (m mediaRepository) SetScanStatus(_ context.Context, id string, status string, signature string) error {
+	mediaID, err := m.medias.parseID(id)
+	if err != nil {
+		return err
+	}
+	_, err = m.medias.update(mediaID, bson.M{"scanStatus": status, "scanSignature": signature})
+	return err
+}
+
+func (m mediaRepository) StorageUsage(_ context.Context, senderIds []primitive.ObjectID, until primitive.ObjectID) ([]models.StorageUsage, error) {
+	medias, err := m.medias.list(func(media *models.Media) bool {
+		return slices.Contains(senderIds, media.SenderId) && (until.IsZero() || bytes.Compare(media.Id[:], until[:]) <= 0)
+	}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	var usage []models.StorageUsage
+	stored := map[primitive.ObjectID]bool{} // blobs counted by an older media, listed oldest first
+	for _, media := range medias {
+		i := slices.IndexFunc(usage, func(u models.StorageUsage) bool { return u.ChatId == media.ChatId && u.MediaType == media.MediaType })
+		if i < 0 {
+			usage = append(usage, models.StorageUsage{ChatId: media.ChatId, MediaType: media.MediaType})
+			i = len(usage) - 1
+		}
+		if media.BlobID.IsZero() || !stored[media.BlobID] {
+			usage[i].Bytes += int64(media.FileSize)
+			stored[media.BlobID] = true
+		}
+		usage[i].Count++
+	}
+	return usage, nil
+}
@@ -0,0 +1,34 @@
+package memory_test
+
+import (
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/memory"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/repositorytest"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestRepositories(t *testing.T) {
+	log := zerolog.Nop()
+	repositorytest.Run(t, func(t *testing.T, keys repositorytest.Keys) repositorytest.Repositories {
+		chatKeys := memory.NewChatKeyRepository(keys.Encryption)
+		return repositorytest.Repositories{
+			Users:           memory.NewUserRepository(keys.Encryption, keys.SearchKey),
+			Settings:        memory.NewSettingsRepository(),
+			LastSeen:        memory.NewLastSeenRepository(),
+			Chats:           memory.NewChatRepository(),
+			ChatKeys:        chatKeys,
+			Messages:        memory.NewMessageRepository(&log, chatKeys),
+			PlayedReceipts:  memory.NewPlayedReceiptRepository(),
+			ChatGroups:      memory.NewChatGroupRepository(),
+			Highlights:      memory.NewHighlightRepository(),
+			Media:           memory.NewMediaRepository(),
+			Uploads:         memory.NewUploadRepository(),
+			Blobs:           memory.NewBlobRepository(),
+			Subscriptions:   memory.NewSubscriptionRepository(),
+			DeviceKeys:      memory.NewDeviceKeyRepository(),
+			Authentications: memory.NewAuthenticationRepository(keys.SearchKey),
+			RateLimits:      memory.NewRateLimitRepository(),
+			UnitOfWork:      memory.NewUnitOfWork(),
+		}
+	})
+}
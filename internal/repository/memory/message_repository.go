@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type messageRepository struct {
+	messages    *collection[models.Message]
+	logger      *zerolog.Logger
+	chatKeyRepo repository.IChatKeyRepository
+}
+
+// NewMessageRepository encrypts messages with the data keys of chatKeyRepo, like the MongoDB repository
+func NewMessageRepository(log *zerolog.Logger, chatKeyRepo repository.IChatKeyRepository) repository.MessageRepository {
+	return &messageRepository{
+		messages:    newCollection[models.Message]("Message", apperrors.CodeMessageNotFound),
+		logger:      log,
+		chatKeyRepo: chatKeyRepo,
+	}
+}
+
+func (m messageRepository) chatEncryptionService(ctx context.Context, chatID primitive.ObjectID, create bool) (services.IEncryptionService, error) {
+	var chatKey *models.ChatKey
+	var err error
+	if create {
+		chatKey, err = m.chatKeyRepo.GetOrCreateByChatID(ctx, chatID.Hex())
+	} else {
+		chatKey, err = m.chatKeyRepo.GetByChatID(ctx, chatID.Hex())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return services.NewAESEncryptionService(chatKey.Key, m.logger)
+}
+
+// decryptMessage decrypts a message with the key of its chat, cached in encSvcs when given, a message of a shredded
+// chat or one that fails to decrypt is left without content
+func (m messageRepository) decryptMessage(ctx context.Context, message *models.Message, encSvcs map[primitive.ObjectID]services.IEncryptionService) error {
+	if !message.Encrypted {
+		return nil
+	}
+	encSvc, ok := encSvcs[message.ChatID]
+	if !ok {
+		var err error
+		encSvc, err = m.chatEncryptionService(ctx, message.ChatID, false)
+		if err != nil && !errors.Is(err, repository.ErrChatKeyNotFound) {
+			return err
+		}
+		if encSvcs != nil {
+			encSvcs[message.ChatID] = encSvc // nil once the chat is shredded
+		}
+	}
+	if encSvc == nil || message.DecryptFields(encSvc) != nil {
+		message.Content, message.MediaUrls = "", nil
+	}
+	return nil
+}
+
+// decryptMessages decrypts listed messages with one key lookup per chat
+func (m messageRepository) decryptMessages(ctx context.Context, messages []models.Message) error {
+	encSvcs := map[primitive.ObjectID]services.IEncryptionService{}
+	for i := range messages {
+		if err := m.decryptMessage(ctx, &messages[i], encSvcs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m messageRepository) decrypted(ctx context.Context, message *models.Message, err error) (*models.Message, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err = m.decryptMessage(ctx, message, nil); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (m messageRepository) Create(ctx context.Context, message *models.Message) (*models.Message, error) {
+	if message.ChatID.IsZero() {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Message has no chat", apperrors.RequiredField("chatId"))
+	}
+
+	// Encrypt content with the chat's data key before saving
+	encSvc, err := m.chatEncryptionService(ctx, message.ChatID, true)
+	if err != nil {
+		return nil, err
+	}
+	if err = message.EncryptFields(encSvc); err != nil {
+		return nil, err
+	}
+
+	id := newID(message.ID)
+	doc := *message
+	doc.ID = id
+	if err = m.messages.insert(id, &doc); err != nil {
+		return nil, err
+	}
+	message.ID = id
+
+	// Decrypt for use
+	if err = message.DecryptFields(encSvc); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (m messageRepository) GetByID(ctx context.Context, id string) (*models.Message, error) {
+	message, err := m.messages.byID(id)
+	return m.decrypted(ctx, message, err)
+}
+
+func (m messageRepository) GetByChatID(ctx context.Context, chatId string) (*models.Message, error) {
+	chatID, err := m.messages.parseID(chatId)
+	if err != nil {
+		return nil, err
+	}
+	message, err := m.messages.first(func(message *models.Message) bool { return message.ChatID == chatID })
+	return m.decrypted(ctx, message, err)
+}
+
+func (m messageRepository) GetBySenderID(ctx context.Context, userId string) (*models.Message, error) {
+	senderID, err := m.messages.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+	message, err := m.messages.first(func(message *models.Message) bool { return message.SenderID == senderID })
+	return m.decrypted(ctx, message, err)
+}
+
+func (m messageRepository) List(ctx context.Context, page, limit int) ([]models.Message, error) {
+	messages, err := m.messages.list(nil, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.decryptMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (m messageRepository) Update(ctx context.Context, message *models.Message) error {
+	// Encrypt content with the chat's data key before saving
+	encSvc, err := m.chatEncryptionService(ctx, message.ChatID, false)
+	if err != nil {
+		return err
+	}
+	if err = message.EncryptFields(encSvc); err != nil {
+		return err
+	}
+	_, updateErr := m.messages.update(message.ID, message)
+	if err = message.DecryptFields(encSvc); err != nil {
+		return err
+	}
+	return updateErr
+}
+
+func (m messageRepository) Delete(_ context.Context, id string) error {
+	return m.messages.deleteByID(id)
+}
+
+func (m messageRepository) ListWithLinks(ctx context.Context, chatId string, before string, limit int) ([]models.Message, error) {
+	chatID, err := m.messages.parseID(chatId)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := m.messages.listBefore(func(message *models.Message) bool {
+		return message.ChatID == chatID && message.HasLinks
+	}, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.decryptMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"sort"
+	"sync"
+)
+
+type playedReceiptRepository struct {
+	mu       sync.Mutex // makes marking atomic, like MongoDB's upsert
+	receipts *collection[models.PlayedReceipt]
+}
+
+func NewPlayedReceiptRepository() repository.IPlayedReceiptRepository {
+	return &playedReceiptRepository{receipts: newCollection[models.PlayedReceipt]("Receipt", apperrors.CodeReceiptNotFound,
+		func(receipt *models.PlayedReceipt) string {
+			return receipt.MessageID.Hex() + "/" + receipt.UserID.Hex()
+		},
+	)}
+}
+
+func (p *playedReceiptRepository) MarkPlayed(_ context.Context, receipt *models.PlayedReceipt) (*models.PlayedReceipt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.receipts.first(func(other *models.PlayedReceipt) bool {
+		return other.MessageID == receipt.MessageID && other.UserID == receipt.UserID
+	})
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, err
+	}
+	created := *receipt
+	created.ID = newID(created.ID)
+	if err = p.receipts.insert(created.ID, &created); err != nil {
+		return nil, err
+	}
+	return p.receipts.byID(created.ID.Hex())
+}
+
+func (p *playedReceiptRepository) ListByMessageId(_ context.Context, messageId string) ([]models.PlayedReceipt, error) {
+	messageID, err := p.receipts.parseID(messageId)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := p.receipts.list(func(receipt *models.PlayedReceipt) bool { return receipt.MessageID == messageID }, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(receipts, func(i, j int) bool { return receipts[i].PlayedAt.Before(receipts[j].PlayedAt) })
+	return receipts, nil
+}
+
+func (p *playedReceiptRepository) DeleteByMessageId(_ context.Context, messageId string) error {
+	messageID, err := p.receipts.parseID(messageId)
+	if err != nil {
+		return err
+	}
+	_, err = p.receipts.deleteWhere(func(receipt *models.PlayedReceipt) bool { return receipt.MessageID == messageID })
+	return err
+}
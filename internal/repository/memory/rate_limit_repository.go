@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type rateLimitRepository struct {
+	mu      sync.Mutex
+	windows map[string]models.RateWindow
+}
+
+func NewRateLimitRepository() repository.IRateLimitRepository {
+	return &rateLimitRepository{windows: map[string]models.RateWindow{}}
+}
+
+func (r *rateLimitRepository) Hit(_ context.Context, key string, windowStart time.Time, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := key + "@" + strconv.FormatInt(windowStart.Unix(), 10)
+	current, ok := r.windows[id]
+	if !ok {
+		if len(r.windows) >= 1024 {
+			r.prune(windowStart)
+		}
+		current = models.RateWindow{ID: id, ExpiresAt: windowStart.Add(window)}
+	}
+	current.Calls++
+	r.windows[id] = current
+	return current.Calls, nil
+}
+
+// prune forgets the windows that ended, like the TTL index of MongoDB, the caller holds the lock
+func (r *rateLimitRepository) prune(now time.Time) {
+	for id, window := range r.windows {
+		if !now.Before(window.ExpiresAt) {
+			delete(r.windows, id)
+		}
+	}
+}
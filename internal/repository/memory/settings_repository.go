@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"slices"
+)
+
+type settingsRepository struct {
+	settings *collection[models.Settings]
+}
+
+func NewSettingsRepository() repository.ISettingsRepository {
+	return &settingsRepository{
+		settings: newCollection[models.Settings]("Settings", apperrors.CodeSettingsNotFound,
+			func(settings *models.Settings) string { return settings.UserId.Hex() },
+		),
+	}
+}
+
+func (s settingsRepository) Create(_ context.Context, settings *models.Settings) (*models.Settings, error) {
+	id := newID(settings.ID)
+	doc := *settings
+	doc.ID = id
+	if err := s.settings.insert(id, &doc); err != nil {
+		return nil, err
+	}
+	settings.ID = id
+	return settings, nil
+}
+
+func (s settingsRepository) GetByID(_ context.Context, id string) (*models.Settings, error) {
+	return s.settings.byID(id)
+}
+
+func (s settingsRepository) GetByUserID(_ context.Context, userId string) (*models.Settings, error) {
+	userID, err := s.settings.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.settings.first(func(settings *models.Settings) bool { return settings.UserId == userID })
+}
+
+func (s settingsRepository) ListByUserIDs(_ context.Context, userIds []primitive.ObjectID) ([]models.Settings, error) {
+	return s.settings.list(func(settings *models.Settings) bool { return slices.Contains(userIds, settings.UserId) }, 1, 0)
+}
+
+func (s settingsRepository) List(_ context.Context, page, limit int) ([]models.Settings, error) {
+	return s.settings.list(nil, page, limit)
+}
+
+func (s settingsRepository) Update(_ context.Context, settings *models.Settings) error {
+	_, err := s.settings.update(settings.ID, settings)
+	return err
+}
+
+func (s settingsRepository) Delete(_ context.Context, id string) error {
+	return s.settings.deleteByID(id)
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"slices"
+	"time"
+)
+
+type subscriptionRepository struct {
+	subscriptions *collection[models.Subscription]
+}
+
+func NewSubscriptionRepository() repository.ISubscriptionRepository {
+	return &subscriptionRepository{subscriptions: newCollection[models.Subscription]("Subscription", apperrors.CodeSubscriptionNotFound)}
+}
+
+func (s *subscriptionRepository) Create(_ context.Context, subscription *models.Subscription) (*models.Subscription, error) {
+	created := *subscription
+	created.Id = newID(created.Id)
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if err := s.subscriptions.insert(created.Id, &created); err != nil {
+		return nil, err
+	}
+	return s.subscriptions.byID(created.Id.Hex())
+}
+
+func (s *subscriptionRepository) GetActiveByUserId(_ context.Context, userId string, at time.Time) (*models.Subscription, error) {
+	userID, err := s.subscriptions.parseID(userId)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.subscriptions.list(func(subscription *models.Subscription) bool {
+		return subscription.UserId == userID && subscription.ActiveAt(at)
+	}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return nil, s.subscriptions.notFound()
+	}
+	latest := slices.MaxFunc(active, func(a, b models.Subscription) int { return a.StartDate.Compare(b.StartDate) })
+	return &latest, nil
+}
+
+func (s *subscriptionRepository) ListEnterpriseMembers(_ context.Context, enterpriseId string, at time.Time) ([]primitive.ObjectID, error) {
+	enterpriseID, err := s.subscriptions.parseID(enterpriseId)
+	if err != nil {
+		return nil, err
+	}
+	seats, err := s.subscriptions.list(func(subscription *models.Subscription) bool {
+		return subscription.EnterpriseId == enterpriseID && subscription.PlanType == models.PlanEnterprise && subscription.ActiveAt(at)
+	}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	var members []primitive.ObjectID
+	for _, seat := range seats {
+		if !slices.Contains(members, seat.UserId) {
+			members = append(members, seat.UserId)
+		}
+	}
+	return members, nil
+}
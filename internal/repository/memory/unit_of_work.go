@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+)
+
+type unitOfWork struct{}
+
+// NewUnitOfWork reverts failed units of work with the undo functions registered with repository.OnRollback,
+// the in-memory repositories have no transactions
+func NewUnitOfWork() repository.IUnitOfWork {
+	return unitOfWork{}
+}
+
+func (unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return repository.RunWithRollbacks(ctx, fn)
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"sync"
+	"time"
+)
+
+type uploadRepository struct {
+	mu      sync.Mutex // serializes the conditional updates, like a MongoDB findAndModify
+	uploads *collection[models.Upload]
+}
+
+func NewUploadRepository() repository.IUploadRepository {
+	return &uploadRepository{uploads: newCollection[models.Upload]("Upload", apperrors.CodeUploadNotFound)}
+}
+
+func (u *uploadRepository) Create(_ context.Context, upload *models.Upload) (*models.Upload, error) {
+	created := *upload
+	created.ID = primitive.NewObjectID()
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if err := u.uploads.insert(created.ID, &created); err != nil {
+		return nil, err
+	}
+	return u.uploads.byID(created.ID.Hex())
+}
+
+func (u *uploadRepository) GetByID(_ context.Context, id string) (*models.Upload, error) {
+	upload, err := u.uploads.byID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.ExpiresAt.After(time.Now()) {
+		return nil, u.uploads.notFound()
+	}
+	return upload, nil
+}
+
+func (u *uploadRepository) AppendPart(ctx context.Context, id string, offset int64, part string, size int64, contentType string, expiresAt time.Time) (*models.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	upload, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != models.UploadStatusUploading || upload.Offset != offset {
+		return nil, repository.UploadConflict(upload)
+	}
+	upload.Offset += size
+	upload.Parts = append(upload.Parts, part)
+	upload.ExpiresAt = expiresAt
+	upload.UpdatedAt = time.Now()
+	if contentType != "" {
+		upload.ContentType = contentType
+	}
+	return u.uploads.update(upload.ID, upload)
+}
+
+func (u *uploadRepository) Complete(ctx context.Context, id string, expiresAt time.Time) (*models.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	previous, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if previous.Status != models.UploadStatusUploading {
+		return nil, repository.UploadConflict(previous)
+	}
+	completed := *previous
+	completed.Status = models.UploadStatusCompleted
+	completed.Parts = nil
+	completed.ExpiresAt = expiresAt
+	completed.UpdatedAt = time.Now()
+	// replaced rather than merged, the parts are unset
+	if err = u.uploads.replace(completed.ID, &completed); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+func (u *uploadRepository) ListExpired(_ context.Context, now time.Time, limit int) ([]models.Upload, error) {
+	uploads, err := u.uploads.list(func(upload *models.Upload) bool { return !upload.ExpiresAt.After(now) }, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(uploads, func(i, j int) bool { return uploads[i].ExpiresAt.Before(uploads[j].ExpiresAt) })
+	if limit > 0 && len(uploads) > limit {
+		uploads = uploads[:limit]
+	}
+	return uploads, nil
+}
+
+func (u *uploadRepository) Delete(_ context.Context, id string) error {
+	return u.uploads.deleteByID(id)
+}
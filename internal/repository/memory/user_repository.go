@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+)
+
+type userRepository struct {
+	users                *collection[models.User]
+	EncryptionService    services.IEncryptionService
+	SearchKeyHashService services.ISearchKeyService
+}
+
+func NewUserRepository(encryptSvc services.IEncryptionService, sKeyHashSvc services.ISearchKeyService) repository.IUserRepository {
+	return &userRepository{
+		users: newCollection[models.User]("User", apperrors.CodeUserNotFound,
+			func(user *models.User) string { return user.UsernameHash },
+			func(user *models.User) string { return user.EmailHash + "\x00" + user.PhoneNumberHash },
+		),
+		EncryptionService:    encryptSvc,
+		SearchKeyHashService: sKeyHashSvc,
+	}
+}
+
+func (u userRepository) Create(_ context.Context, user *models.User) (*models.User, error) {
+	//Hash fields used in search & encrypt before saving
+	if err := user.HashFields(u.SearchKeyHashService); err != nil {
+		return nil, err
+	}
+	if err := user.EncryptFields(u.EncryptionService); err != nil {
+		return nil, err
+	}
+
+	id := newID(user.ID)
+	doc := *user
+	doc.ID = id
+	if err := u.users.insert(id, &doc); err != nil {
+		return nil, err
+	}
+	user.ID = id
+
+	// Decrypt for use
+	if err := user.DecryptFields(u.EncryptionService); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u userRepository) GetByID(_ context.Context, id string) (*models.User, error) {
+	return u.decrypted(u.users.byID(id))
+}
+
+func (u userRepository) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	hashedEmail, err := u.SearchKeyHashService.GenerateSearchKey(email)
+	if err != nil {
+		return nil, err
+	}
+	return u.decrypted(u.users.first(func(user *models.User) bool { return user.EmailHash == hashedEmail }))
+}
+
+func (u userRepository) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	hashedUsername, err := u.SearchKeyHashService.GenerateSearchKey(username)
+	if err != nil {
+		return nil, err
+	}
+	return u.decrypted(u.users.first(func(user *models.User) bool { return user.UsernameHash == hashedUsername }))
+}
+
+func (u userRepository) GetByPhoneNumber(_ context.Context, phoneNumber string) (*models.User, error) {
+	hashedPhoneNumber, err := u.SearchKeyHashService.GenerateSearchKey(phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	return u.decrypted(u.users.first(func(user *models.User) bool { return user.PhoneNumberHash == hashedPhoneNumber }))
+}
+
+func (u userRepository) decrypted(user *models.User, err error) (*models.User, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err = user.DecryptFields(u.EncryptionService); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u userRepository) List(_ context.Context, page, limit int) ([]models.User, error) {
+	users, err := u.users.list(nil, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	// users that fail to decrypt are listed as stored, like the MongoDB repository does
+	for i := range users {
+		_ = users[i].DecryptFields(u.EncryptionService)
+	}
+	return users, nil
+}
+
+func (u userRepository) Update(_ context.Context, user *models.User) (*models.User, error) {
+	// Hash & encrypt a copy, the caller keeps working with the plaintext user
+	doc := *user
+	if err := doc.HashFields(u.SearchKeyHashService); err != nil {
+		return nil, err
+	}
+	if err := doc.EncryptFields(u.EncryptionService); err != nil {
+		return nil, err
+	}
+	return u.decrypted(u.users.update(user.ID, doc))
+}
+
+func (u userRepository) Delete(_ context.Context, id string) error {
+	return u.users.deleteByID(id)
+}
@@ -13,4 +13,7 @@ type MessageRepository interface {
 	List(ctx context.Context, page, limit int) ([]models.Message, error)
 	Update(ctx context.Context, message *models.Message) error
 	Delete(ctx context.Context, id string) error
+	// ListWithLinks lists the messages of a chat that held links when sent, newest first. It continues after the
+	// message with the id before unless it is empty. Messages of a shredded chat are left encrypted.
+	ListWithLinks(ctx context.Context, chatId string, before string, limit int) ([]models.Message, error)
 }
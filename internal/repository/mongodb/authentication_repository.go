@@ -3,10 +3,13 @@ package mongodb
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 
@@ -36,6 +39,10 @@ func NewAuthenticationRepository(log *zerolog.Logger, db *mongo.Database, encryp
 
 func (a AuthenticationRepository) Create(ctx context.Context, auth *models.Authentication) (*models.Authentication, error) {
 	const kName = "Create"
+	defer metrics.ObserveMongo("AuthenticationRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 	// Hash fields
 	//err := auth.HashFields(a.SearchKeyHashSvc,"")
 	//if err != nil {
@@ -50,8 +57,8 @@ func (a AuthenticationRepository) Create(ctx context.Context, auth *models.Authe
 
 	result, err := a.Collection.InsertOne(ctx, auth)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create authentication record")
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to create authentication record")
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	auth.ID = result.InsertedID.(primitive.ObjectID)
 	return auth, nil
@@ -59,24 +66,28 @@ func (a AuthenticationRepository) Create(ctx context.Context, auth *models.Authe
 
 func (a AuthenticationRepository) GetList(ctx context.Context) (*[]models.Authentication, error) {
 	const kName = "GetList"
+	defer metrics.ObserveMongo("AuthenticationRepository", "GetList")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "GetList")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
-	cursor, err := a.Collection.Find(ctx, bson.M{})
+	cursor, err := a.Collection.Find(ctx, bson.M{}, pageOptions(1, 0)) // every document, oldest first
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get authentication list")
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get authentication list")
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to close cursor")
+			logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to close cursor")
 		}
 	}(cursor, ctx)
 
 	var authList []models.Authentication
 	//var decryptedAuthList []models.Authentication
 	if err := cursor.All(ctx, &authList); err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to decode authentication list")
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to decode authentication list")
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 
 	//Decrypt List before sharing to user
@@ -94,18 +105,22 @@ func (a AuthenticationRepository) GetList(ctx context.Context) (*[]models.Authen
 
 func (a AuthenticationRepository) GetByUserID(ctx context.Context, userID string) (*models.Authentication, error) {
 	const kName = "GetByUserID"
+	defer metrics.ObserveMongo("AuthenticationRepository", "GetByUserID")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "GetByUserID")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	ID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert Auth-GetByUserid to object id")
-		a.Logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert auth-user-id:" + userID)
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert Auth-GetByUserid to object id")
+		logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert auth-user-id:" + userID)
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	var auth models.Authentication
 	err = a.Collection.FindOne(ctx, bson.M{"userId": ID}).Decode(&auth)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to get authentication by user ID")
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to get authentication by user ID")
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// Decrypt sensitive fields before sharing
 	//if err := auth.DecryptFields(a.EncryptionService); err != nil {
@@ -117,12 +132,16 @@ func (a AuthenticationRepository) GetByUserID(ctx context.Context, userID string
 
 func (a AuthenticationRepository) UpdateByUserID(ctx context.Context, userID string, auth *models.Authentication) (*models.Authentication, error) {
 	const kName = "UpdateByUserID"
+	defer metrics.ObserveMongo("AuthenticationRepository", "UpdateByUserID")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "UpdateByUserID")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	ID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert id to object id")
-		a.Logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert id:" + userID)
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert id to object id")
+		logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert id:" + userID)
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// Encrypt sensitive fields before saving
 	//if err := auth.EncryptFields(a.EncryptionService); err != nil {
@@ -132,43 +151,54 @@ func (a AuthenticationRepository) UpdateByUserID(ctx context.Context, userID str
 
 	filter := bson.M{"userId": ID}
 	update := bson.M{"$set": auth}
-	_, err = a.Collection.UpdateOne(ctx, filter, update)
+	res, err := a.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to update authentication by user ID")
-		return nil, err
+		logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to update authentication by user ID")
+		return nil, mapError(err, apperrors.CodeNotFound, "Authentication")
+	}
+	if res.MatchedCount == 0 {
+		return nil, apperrors.NotFound(apperrors.CodeNotFound, "Authentication not found")
 	}
 	return auth, nil
 }
 
 func (a AuthenticationRepository) Delete(ctx context.Context, ID string) error {
 	const kName = "Delete"
+	defer metrics.ObserveMongo("AuthenticationRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	objectID, err := primitive.ObjectIDFromHex(ID)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("ID", ID).Msg("Invalid ID format")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Str("ID", ID).Msg("Invalid ID format")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	_, err = a.Collection.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("ID", ID).Msg("Failed to delete authentication")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Str("ID", ID).Msg("Failed to delete authentication")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	return nil
 }
 
 func (a AuthenticationRepository) DeleteByUserID(ctx context.Context, userID string) error {
 	const kName = "DeleteByUserID"
+	defer metrics.ObserveMongo("AuthenticationRepository", "DeleteByUserID")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "DeleteByUserID")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	ID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert id to auth-object id")
-		a.Logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert auth-user-id:" + userID)
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert id to auth-object id")
+		logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert auth-user-id:" + userID)
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	_, err = a.Collection.DeleteOne(ctx, bson.M{"userId": ID})
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to delete authentication by user ID")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to delete authentication by user ID")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	return nil
 }
@@ -176,28 +206,32 @@ func (a AuthenticationRepository) DeleteByUserID(ctx context.Context, userID str
 // SaveRefreshToken updates refresh token and adds a fresh one if it does not exist for the user
 func (a AuthenticationRepository) SaveRefreshToken(ctx context.Context, userID string, refreshToken string, tokenDuration time.Duration) error {
 	const kName = "SaveRefreshToken"
+	defer metrics.ObserveMongo("AuthenticationRepository", "SaveRefreshToken")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "SaveRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	ID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert id to object id")
-		a.Logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert id:" + userID)
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to convert id to object id")
+		logger.Debug().Interface(kName, a.iName).Err(err).Msg("Failed to convert id:" + userID)
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// search if user already exists
 	var auth models.Authentication
 	err = a.Collection.FindOne(ctx, bson.M{"userId": ID}).Decode(&auth)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to get authentication by user ID")
+		logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to get authentication by user ID")
 		// creating new auth object for user
 		auth = *models.GetAuthenticationDefaults()
-		a.Logger.Info().Interface(kName, a.iName).Str("userID", userID).Msg("Created new authentication from defaults")
+		logger.Info().Interface(kName, a.iName).Str("userID", userID).Msg("Created new authentication from defaults")
 	}
 
 	// Hash field(s) for search
 	refreshTokenHash, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to generate search key")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to generate search key")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// Encrypt Refresh Token before saving
 	//encRefreshToken, err := a.EncryptionService.Encrypt(refreshToken)
@@ -209,6 +243,7 @@ func (a AuthenticationRepository) SaveRefreshToken(ctx context.Context, userID s
 	// Assign to Fields
 	//auth.RefreshToken = encRefreshToken
 	auth.RefreshTokenHash = refreshTokenHash
+	auth.IsActive = true // a previous token may have been revoked
 	auth.UpdatedAt = time.Now()
 	auth.LastLogin = time.Now()
 	auth.ExpiresAt = time.Now().Add(tokenDuration)
@@ -216,12 +251,12 @@ func (a AuthenticationRepository) SaveRefreshToken(ctx context.Context, userID s
 	// Prepare query and update
 	opts := options.Update().SetUpsert(true)
 	filter := bson.M{"userId": ID}
-	update := bson.D{{"$set", auth}}
+	update := bson.D{{Key: "$set", Value: auth}}
 
 	_, err = a.Collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to save refresh token")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Str("userID", userID).Msg("Failed to save refresh token")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// ########### dumping log #################
 	//a.Logger.Debug().Interface(kName, a.iName).
@@ -234,18 +269,22 @@ func (a AuthenticationRepository) SaveRefreshToken(ctx context.Context, userID s
 
 func (a AuthenticationRepository) GetUserIDFromRefreshToken(ctx context.Context, refreshToken string) (string, error) {
 	const kName = "GetUserIDFromRefreshToken"
+	defer metrics.ObserveMongo("AuthenticationRepository", "GetUserIDFromRefreshToken")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "GetUserIDFromRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	// Input validation
 	if refreshToken == "" {
-		a.Logger.Error().Interface(kName, a.iName).Msg("RefreshToken is empty")
-		return "", fmt.Errorf("refresh token cannot be empty")
+		logger.Error().Interface(kName, a.iName).Msg("RefreshToken is empty")
+		return "", apperrors.Validation(apperrors.CodeValidation, "Refresh token is required", apperrors.RequiredField("refreshToken"))
 	}
 
 	// Hash token for search
 	hashedRefreshToken, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash refresh token")
-		return "", err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash refresh token")
+		return "", mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// ########### dumping log #################
 	//a.Logger.Debug().Interface(kName, a.iName).
@@ -266,16 +305,16 @@ func (a AuthenticationRepository) GetUserIDFromRefreshToken(ctx context.Context,
 
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			a.Logger.Warn().Interface(kName, a.iName).Msg("No active refresh token found")
-			return "", fmt.Errorf("invalid or expired refresh token")
+			logger.Warn().Interface(kName, a.iName).Msg("No active refresh token found")
+			return "", apperrors.Unauthorized(apperrors.CodeInvalidRefreshToken, "Invalid or expired refresh token").WithErr(err)
 		}
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get user ID from refresh token")
-		return "", err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get user ID from refresh token")
+		return "", mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 
 	if result.UserID.Hex() == "" {
-		a.Logger.Warn().Interface(kName, a.iName).Msg("Refresh token found but user ID is empty")
-		return "", fmt.Errorf("invalid refresh token: no user associated")
+		logger.Warn().Interface(kName, a.iName).Msg("Refresh token found but user ID is empty")
+		return "", apperrors.Unauthorized(apperrors.CodeInvalidRefreshToken, "Invalid refresh token, no user associated")
 	}
 
 	return result.UserID.Hex(), nil
@@ -283,18 +322,22 @@ func (a AuthenticationRepository) GetUserIDFromRefreshToken(ctx context.Context,
 
 func (a AuthenticationRepository) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
 	const kName = "RevokeRefreshToken"
+	defer metrics.ObserveMongo("AuthenticationRepository", "RevokeRefreshToken")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "RevokeRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	// Input validation
 	if refreshToken == "" {
-		a.Logger.Error().Interface(kName, a.iName).Msg("RefreshToken is empty")
-		return fmt.Errorf("refresh token cannot be empty")
+		logger.Error().Interface(kName, a.iName).Msg("RefreshToken is empty")
+		return apperrors.Validation(apperrors.CodeValidation, "Refresh token is required", apperrors.RequiredField("refreshToken"))
 	}
 
 	// Hash token for search
 	hashedRefreshToken, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash refresh token")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash refresh token")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	// ########### dumping log #################
 	//a.Logger.Debug().Interface(kName, a.iName).
@@ -315,24 +358,24 @@ func (a AuthenticationRepository) RevokeRefreshToken(ctx context.Context, refres
 
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			a.Logger.Warn().Interface(kName, a.iName).Msg("No active refresh token found")
-			return fmt.Errorf("invalid or expired refresh token")
+			logger.Warn().Interface(kName, a.iName).Msg("No active refresh token found")
+			return apperrors.Unauthorized(apperrors.CodeInvalidRefreshToken, "Invalid or expired refresh token").WithErr(err)
 		}
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get user ID from refresh token")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get user ID from refresh token")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 
 	// deactivate token & expire it
 	result.IsActive = false
 	result.ExpiresAt = time.Now()
-	a.Logger.Debug().Interface(kName, a.iName).Msg("Deactivating refreshToken")
+	logger.Debug().Interface(kName, a.iName).Msg("Deactivating refreshToken")
 	opts := options.FindOneAndUpdate().SetUpsert(false)
-	filter := bson.D{{"_id", result.ID}}
-	update := bson.D{{"$set", result}}
+	filter := bson.D{{Key: "_id", Value: result.ID}}
+	update := bson.D{{Key: "$set", Value: result}}
 	err = a.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to deactivate refresh token")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to deactivate refresh token")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 
 	return nil
@@ -341,17 +384,21 @@ func (a AuthenticationRepository) RevokeRefreshToken(ctx context.Context, refres
 
 func (a AuthenticationRepository) DeleteRefreshToken(ctx context.Context, refreshToken string) error {
 	const kName = "DeleteRefreshToken"
+	defer metrics.ObserveMongo("AuthenticationRepository", "DeleteRefreshToken")()
+	ctx, span := tracing.Start(ctx, "AuthenticationRepository", "DeleteRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.Logger)
 
 	// Hash token for search
 	hashedRefreshToken, err := a.SearchKeyHashSvc.GenerateSearchKey(refreshToken)
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash refresh token")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to hash refresh token")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	_, err = a.Collection.DeleteOne(ctx, bson.M{"refreshTokenHash": hashedRefreshToken})
 	if err != nil {
-		a.Logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to delete refresh token")
-		return err
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to delete refresh token")
+		return mapError(err, apperrors.CodeNotFound, "Authentication")
 	}
 	return nil
 }
@@ -0,0 +1,201 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type blobRepository struct {
+	iName      string
+	logger     *zerolog.Logger
+	Collection *mongo.Collection
+}
+
+func NewBlobRepository(log *zerolog.Logger, db *mongo.Database) repository.IBlobRepository {
+	return &blobRepository{
+		iName:      "BlobRepository",
+		logger:     log,
+		Collection: db.Collection("blobs"),
+	}
+}
+
+func (b blobRepository) Create(ctx context.Context, blob *models.Blob) (*models.Blob, error) {
+	const kName = "Create"
+	defer metrics.ObserveMongo("BlobRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	created := *blob
+	if created.ID.IsZero() {
+		created.ID = primitive.NewObjectID()
+	}
+	created.RefCount = 1
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	// the unique owner & hash index settles concurrent uploads of the same file, the loser acquires the winner's blob
+	if _, err := b.Collection.InsertOne(ctx, created); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to insert blob")
+		}
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	return &created, nil
+}
+
+func (b blobRepository) Acquire(ctx context.Context, ownerId string, hash string) (*models.Blob, error) {
+	const kName = "Acquire"
+	defer metrics.ObserveMongo("BlobRepository", "Acquire")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "Acquire")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	ownerID, err := primitive.ObjectIDFromHex(ownerId)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to convert owner id to object id")
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+
+	update := bson.M{"$inc": bson.M{"refCount": 1}, "$set": bson.M{"updatedAt": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	blob := &models.Blob{}
+	err = b.Collection.FindOneAndUpdate(ctx, bson.M{"ownerId": ownerID, "hash": hash}, update, opts).Decode(blob)
+	if err != nil {
+		// a miss is the common case of a new file
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	return blob, nil
+}
+
+func (b blobRepository) Release(ctx context.Context, id string) error {
+	const kName = "Release"
+	defer metrics.ObserveMongo("BlobRepository", "Release")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "Release")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	blobID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to convert blob id to object id")
+		return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	update := bson.M{"$inc": bson.M{"refCount": -1}, "$set": bson.M{"updatedAt": time.Now()}}
+	result, err := b.Collection.UpdateOne(ctx, bson.M{"_id": blobID, "refCount": bson.M{"$gt": 0}}, update)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to release blob: " + id)
+		return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeBlobNotFound, "Blob not found")
+	}
+	return nil
+}
+
+func (b blobRepository) ListUnreferenced(ctx context.Context, limit int) ([]models.Blob, error) {
+	const kName = "ListUnreferenced"
+	defer metrics.ObserveMongo("BlobRepository", "ListUnreferenced")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "ListUnreferenced")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	opts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := b.Collection.Find(ctx, bson.M{"refCount": bson.M{"$lte": 0}}, opts)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to find unreferenced blobs")
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to close cursor")
+		}
+	}(cursor, ctx)
+
+	var blobs []models.Blob
+	if err := cursor.All(ctx, &blobs); err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to decode unreferenced blobs")
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	return blobs, nil
+}
+
+func (b blobRepository) GetByID(ctx context.Context, id string) (*models.Blob, error) {
+	const kName = "GetByID"
+	defer metrics.ObserveMongo("BlobRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	blobID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to convert blob id to object id")
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	blob := &models.Blob{}
+	if err = b.Collection.FindOne(ctx, bson.M{"_id": blobID}).Decode(blob); err != nil {
+		return nil, mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	return blob, nil
+}
+
+func (b blobRepository) SetScanStatus(ctx context.Context, id string, status string, signature string) error {
+	const kName = "SetScanStatus"
+	defer metrics.ObserveMongo("BlobRepository", "SetScanStatus")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "SetScanStatus")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	blobID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to convert blob id to object id")
+		return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	update := bson.M{"$set": bson.M{"scanStatus": status, "scanSignature": signature, "updatedAt": time.Now()}}
+	result, err := b.Collection.UpdateOne(ctx, bson.M{"_id": blobID}, update)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to set scan status of blob: " + id)
+		return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeBlobNotFound, "Blob not found")
+	}
+	return nil
+}
+
+func (b blobRepository) DeleteUnreferenced(ctx context.Context, id string) error {
+	const kName = "DeleteUnreferenced"
+	defer metrics.ObserveMongo("BlobRepository", "DeleteUnreferenced")()
+	ctx, span := tracing.Start(ctx, "BlobRepository", "DeleteUnreferenced")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.logger)
+
+	blobID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to convert blob id to object id")
+		return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	// matching on the count makes the delete race safely with Acquire, a blob acquired in between is kept
+	result, err := b.Collection.DeleteOne(ctx, bson.M{"_id": blobID, "refCount": bson.M{"$lte": 0}})
+	if err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("failed to delete blob: " + id)
+		return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+	}
+	if result.DeletedCount == 0 {
+		if err = b.Collection.FindOne(ctx, bson.M{"_id": blobID}).Err(); err != nil {
+			return mapError(err, apperrors.CodeBlobNotFound, "Blob")
+		}
+		return apperrors.Conflict(apperrors.CodeBlobReferenced, "The blob is referenced")
+	}
+	return nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type chatGroupRepository struct {
@@ -22,54 +25,70 @@ func NewChatGroupRepository(db *mongo.Database) repository.ChatGroupRepository {
 }
 
 func (c chatGroupRepository) Create(ctx context.Context, chatGroup *models.ChatGroup) error {
-	_, err := c.Collection.InsertOne(ctx, chatGroup)
+	defer metrics.ObserveMongo("ChatGroupRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "ChatGroupRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	res, err := c.Collection.InsertOne(ctx, chatGroup)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to insert chat_group")
-		return err
+		logger.Error().Err(err).Msg("failed to insert chat_group")
+		return mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
+	chatGroup.ID = res.InsertedID.(primitive.ObjectID)
 	return nil
 }
 
 func (c chatGroupRepository) GetByID(ctx context.Context, id string) (*models.ChatGroup, error) {
+	defer metrics.ObserveMongo("ChatGroupRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "ChatGroupRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// chat group ID to search for
 	cgID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert id to object id")
-		log.Debug().Err(err).Msg("failed to convert id:" + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Err(err).Msg("failed to convert id:" + id)
+		return nil, mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 
 	chatGroup := &models.ChatGroup{}
 	err = c.Collection.FindOne(ctx, bson.M{"_id": cgID}).Decode(chatGroup)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find chat_group with id: " + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find chat_group with id: " + id)
+		return nil, mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 	return chatGroup, nil
 }
 
 func (c chatGroupRepository) List(ctx context.Context, page, limit int) ([]models.ChatGroup, error) {
-	skip := (page - 1) * limit
-	opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	defer metrics.ObserveMongo("ChatGroupRepository", "List")()
+	ctx, span := tracing.Start(ctx, "ChatGroupRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	opts := pageOptions(page, limit)
 	cursor, err := c.Collection.Find(ctx, bson.M{}, opts)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find chat_groups from collection")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find chat_groups from collection")
+		return nil, mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 	var chatGroups []models.ChatGroup
 	err = cursor.All(ctx, &chatGroups)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find chat_groups through cursor")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find chat_groups through cursor")
+		return nil, mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 	return chatGroups, nil
 }
 
 func (c chatGroupRepository) UpdateWithFilter(ctx context.Context, chatGroupId string, updateData map[string]interface{}) error {
+	defer metrics.ObserveMongo("ChatGroupRepository", "UpdateWithFilter")()
+	ctx, span := tracing.Start(ctx, "ChatGroupRepository", "UpdateWithFilter")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	objectID, err := primitive.ObjectIDFromHex(chatGroupId)
 	if err != nil {
-		log.Error().Err(err).Msg("invalid chat group ID format")
-		return err
+		logger.Error().Err(err).Msg("invalid chat group ID format")
+		return mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 
 	bsonUpdate := bson.M{}
@@ -77,38 +96,52 @@ func (c chatGroupRepository) UpdateWithFilter(ctx context.Context, chatGroupId s
 		bsonUpdate[key] = value
 	}
 
-	_, err = c.Collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bsonUpdate})
+	res, err := c.Collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bsonUpdate})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update chat_group")
-		return err
+		logger.Error().Err(err).Msg("failed to update chat_group")
+		return mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeChatGroupNotFound, "Chat group not found")
 	}
 	return nil
 }
 
 func (c chatGroupRepository) Update(ctx context.Context, chatGroup *models.ChatGroup) error {
-	filter := bson.M{"id": chatGroup.ID}
-	update := bson.M{"$set": bson.M{}}
-	_, err := c.Collection.UpdateOne(ctx, filter, update)
+	defer metrics.ObserveMongo("ChatGroupRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "ChatGroupRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	filter := bson.M{"_id": chatGroup.ID}
+	update := bson.M{"$set": chatGroup}
+	res, err := c.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update chat_group with id: " + chatGroup.ID.String())
-		return err
+		logger.Error().Err(err).Msg("failed to update chat_group with id: " + chatGroup.ID.Hex())
+		return mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeChatGroupNotFound, "Chat group not found")
 	}
 	return nil
 }
 
 func (c chatGroupRepository) Delete(ctx context.Context, id string) error {
+	defer metrics.ObserveMongo("ChatGroupRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "ChatGroupRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// chat group ID to search for
 	cgID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert id to object id")
-		log.Debug().Err(err).Msg("failed to convert id:" + id)
-		return err
+		logger.Error().Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Err(err).Msg("failed to convert id:" + id)
+		return mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 
 	_, err = c.Collection.DeleteOne(ctx, bson.M{"_id": cgID})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete chat_group with id: " + id)
-		return err
+		logger.Error().Err(err).Msg("failed to delete chat_group with id: " + id)
+		return mapError(err, apperrors.CodeChatGroupNotFound, "Chat group")
 	}
 	return nil
 }
@@ -0,0 +1,123 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type chatKeyRepository struct {
+	iName             string
+	Collection        *mongo.Collection
+	logger            *zerolog.Logger
+	EncryptionService services.IEncryptionService // master key, wraps the per-chat data keys
+}
+
+func NewChatKeyRepository(log *zerolog.Logger, db *mongo.Database, encryptSvc services.IEncryptionService) repository.IChatKeyRepository {
+	return &chatKeyRepository{
+		iName:             "ChatKeyRepository",
+		Collection:        db.Collection("chat_keys"),
+		logger:            log,
+		EncryptionService: encryptSvc,
+	}
+}
+
+func (k chatKeyRepository) GetOrCreateByChatID(ctx context.Context, chatId string) (*models.ChatKey, error) {
+	const kName = "GetOrCreateByChatID"
+	defer metrics.ObserveMongo("ChatKeyRepository", "GetOrCreateByChatID")()
+	ctx, span := tracing.Start(ctx, "ChatKeyRepository", "GetOrCreateByChatID")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.logger)
+
+	chatID, err := primitive.ObjectIDFromHex(chatId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to convert chat id to object id")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+
+	chatKey, err := models.NewChatKey(chatID)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to generate chat data key")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+	err = chatKey.EncryptFields(k.EncryptionService)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to wrap chat data key")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+
+	// $setOnInsert keeps the first key when concurrent writers race on a new chat
+	opts := options.Update().SetUpsert(true)
+	_, err = k.Collection.UpdateOne(ctx, bson.M{"chatId": chatID}, bson.M{"$setOnInsert": chatKey}, opts)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to upsert chat data key")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+
+	return k.GetByChatID(ctx, chatId)
+}
+
+func (k chatKeyRepository) GetByChatID(ctx context.Context, chatId string) (*models.ChatKey, error) {
+	const kName = "GetByChatID"
+	defer metrics.ObserveMongo("ChatKeyRepository", "GetByChatID")()
+	ctx, span := tracing.Start(ctx, "ChatKeyRepository", "GetByChatID")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.logger)
+
+	chatID, err := primitive.ObjectIDFromHex(chatId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to convert chat id to object id")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+
+	chatKey := &models.ChatKey{}
+	err = k.Collection.FindOne(ctx, bson.M{"chatId": chatID}).Decode(chatKey)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Warn().Interface(kName, k.iName).Msg("no data key for chat id: " + chatId)
+			return nil, repository.ErrChatKeyNotFound
+		}
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to find chat data key")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+
+	err = chatKey.DecryptFields(k.EncryptionService)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to unwrap chat data key")
+		return nil, mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+	return chatKey, nil
+}
+
+func (k chatKeyRepository) DeleteByChatID(ctx context.Context, chatId string) error {
+	const kName = "DeleteByChatID"
+	defer metrics.ObserveMongo("ChatKeyRepository", "DeleteByChatID")()
+	ctx, span := tracing.Start(ctx, "ChatKeyRepository", "DeleteByChatID")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.logger)
+
+	chatID, err := primitive.ObjectIDFromHex(chatId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to convert chat id to object id")
+		return mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+
+	_, err = k.Collection.DeleteOne(ctx, bson.M{"chatId": chatID})
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to delete chat data key for chat id: " + chatId)
+		return mapError(err, apperrors.CodeChatKeyNotFound, "Chat key")
+	}
+	logger.Info().Interface(kName, k.iName).Msg("destroyed data key for chat id: " + chatId)
+	return nil
+}
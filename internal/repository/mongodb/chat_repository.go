@@ -4,6 +4,10 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -27,11 +31,15 @@ func NewChatRepository(log *zerolog.Logger, db *mongo.Database) repository.ChatR
 
 func (c chatRepository) Create(ctx context.Context, chat *models.Chat) (*models.Chat, error) {
 	const kName = "CreateChat"
+	defer metrics.ObserveMongo("ChatRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "ChatRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, c.logger)
 
 	res, err := c.Collection.InsertOne(ctx, chat)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to create chat")
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to create chat")
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 	chat.ID = res.InsertedID.(primitive.ObjectID)
 	return chat, nil
@@ -39,104 +47,112 @@ func (c chatRepository) Create(ctx context.Context, chat *models.Chat) (*models.
 
 func (c chatRepository) GetByID(ctx context.Context, id string) (*models.Chat, error) {
 	const kName = "GetByID"
+	defer metrics.ObserveMongo("ChatRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "ChatRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, c.logger)
 
 	// chat ID to search for
 	chatID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to convert id to object id")
-		c.logger.Debug().Interface(kName, c.iName).Err(err).Msg("failed to convert Chat GetById -- id:" + id)
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Interface(kName, c.iName).Err(err).Msg("failed to convert Chat GetById -- id:" + id)
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 
 	chat := &models.Chat{}
 	err = c.Collection.FindOne(ctx, bson.M{"_id": chatID}).Decode(chat)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chat with id: " + chatID.String())
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chat with id: " + chatID.String())
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 	return chat, nil
 }
 
 func (c chatRepository) List(ctx context.Context, page, limit int) ([]models.Chat, error) {
 	const kName = "List"
+	defer metrics.ObserveMongo("ChatRepository", "List")()
+	ctx, span := tracing.Start(ctx, "ChatRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, c.logger)
 
-	skip := (page - 1) * limit
-	findOptions := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit))
+	findOptions := pageOptions(page, limit)
 
 	var chats []models.Chat
 
 	cursor, err := c.Collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find all chats list")
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find all chats list")
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to close cursor")
+			logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to close cursor")
 		}
 	}(cursor, ctx)
 
 	for cursor.Next(ctx) {
 		var chat models.Chat
 		if err := cursor.Decode(&chat); err != nil {
-			c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to decode chat")
-			return nil, err
+			logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to decode chat")
+			return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 		}
 		chats = append(chats, chat)
 
 	}
 	if err := cursor.Err(); err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chats")
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chats")
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 	return chats, nil
 }
 
 func (c chatRepository) ListByUserId(ctx context.Context, id string, page, limit int) ([]models.Chat, error) {
 	const kName = "ListByUserId"
+	defer metrics.ObserveMongo("ChatRepository", "ListByUserId")()
+	ctx, span := tracing.Start(ctx, "ChatRepository", "ListByUserId")
+	defer span.End()
+	logger := logging.FromContext(ctx, c.logger)
 
 	// participant ID to search for
 	participantID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to convert id to object id for listing")
-		c.logger.Debug().Interface(kName, c.iName).Err(err).Msg("failed to convert ListByUser id:" + id)
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to convert id to object id for listing")
+		logger.Debug().Interface(kName, c.iName).Err(err).Msg("failed to convert ListByUser id:" + id)
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
-	skip := (page - 1) * limit
-	findOptions := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	findOptions := pageOptions(page, limit)
 	// Filter to find chats where the participant ID is in the participants array
 	filter := bson.M{"participants": bson.M{"$in": []primitive.ObjectID{participantID}}}
 
 	var chats []models.Chat
 	cursor, err := c.Collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chat listByUserId")
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chat listByUserId")
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to close cursor")
+			logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to close cursor")
 		}
 	}(cursor, ctx)
 
 	for cursor.Next(ctx) {
 		var chat models.Chat
 		if err := cursor.Decode(&chat); err != nil {
-			c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to decode chat")
-			return nil, err
+			logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to decode chat")
+			return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 		}
 		chats = append(chats, chat)
 
 	}
 	if err := cursor.Err(); err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chats by userId")
-		return nil, err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to find chats by userId")
+		return nil, mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 	return chats, nil
 
@@ -144,13 +160,20 @@ func (c chatRepository) ListByUserId(ctx context.Context, id string, page, limit
 
 func (c chatRepository) Update(ctx context.Context, chat *models.Chat) error {
 	const kName = "Update"
+	defer metrics.ObserveMongo("ChatRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "ChatRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, c.logger)
 
 	filter := bson.M{"_id": chat.ID}
 	opts := options.Update().SetUpsert(false)
-	_, err := c.Collection.UpdateOne(ctx, filter, bson.M{"$set": chat}, opts)
+	res, err := c.Collection.UpdateOne(ctx, filter, bson.M{"$set": chat}, opts)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to update chat with id: " + chat.ID.String())
-		return err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to update chat with id: " + chat.ID.Hex())
+		return mapError(err, apperrors.CodeChatNotFound, "Chat")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeChatNotFound, "Chat not found")
 	}
 	return nil
 
@@ -158,19 +181,23 @@ func (c chatRepository) Update(ctx context.Context, chat *models.Chat) error {
 
 func (c chatRepository) Delete(ctx context.Context, id string) error {
 	const kName = "Delete"
+	defer metrics.ObserveMongo("ChatRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "ChatRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, c.logger)
 
 	// chat ID to search for
 	chatID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to convert id to object id for deletion")
-		c.logger.Debug().Interface(kName, c.iName).Err(err).Msg("failed to convert Delete id:" + id)
-		return err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to convert id to object id for deletion")
+		logger.Debug().Interface(kName, c.iName).Err(err).Msg("failed to convert Delete id:" + id)
+		return mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 
 	_, err = c.Collection.DeleteOne(ctx, bson.M{"_id": chatID})
 	if err != nil {
-		c.logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to delete chat with id: " + id)
-		return err
+		logger.Error().Interface(kName, c.iName).Err(err).Msg("failed to delete chat with id: " + id)
+		return mapError(err, apperrors.CodeChatNotFound, "Chat")
 	}
 	return nil
 }
@@ -0,0 +1,235 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type deviceKeyRepository struct {
+	iName             string
+	logger            *zerolog.Logger
+	Collection        *mongo.Collection // device identity & signed prekeys
+	PreKeysCollection *mongo.Collection // one-time prekeys
+}
+
+func NewDeviceKeyRepository(log *zerolog.Logger, db *mongo.Database) repository.IDeviceKeyRepository {
+	return &deviceKeyRepository{
+		iName:             "DeviceKeyRepository",
+		logger:            log,
+		Collection:        db.Collection("device_keys"),
+		PreKeysCollection: db.Collection("one_time_prekeys"),
+	}
+}
+
+func (d deviceKeyRepository) Upsert(ctx context.Context, deviceKey *models.DeviceKey) (*models.DeviceKey, error) {
+	const kName = "Upsert"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "Upsert")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "Upsert")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	filter := bson.M{"userId": deviceKey.UserID, "deviceId": deviceKey.DeviceID}
+	update := bson.M{
+		"$set": bson.M{
+			"identityKey":  deviceKey.IdentityKey,
+			"signedPreKey": deviceKey.SignedPreKey,
+			"updatedAt":    time.Now(),
+		},
+		"$setOnInsert": bson.M{"createdAt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	updated := &models.DeviceKey{}
+	err := d.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(updated)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to upsert device key for device: " + deviceKey.DeviceID)
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	// prekeys are only handed out for the identity they were uploaded with, the ones of a previous identity are
+	// merely cleaned up & a failed cleanup is retried by the next publish
+	stale := bson.M{"userId": deviceKey.UserID, "deviceId": deviceKey.DeviceID, "identityKey": bson.M{"$ne": deviceKey.IdentityKey}}
+	if _, err = d.PreKeysCollection.DeleteMany(ctx, stale); err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to delete one-time prekeys of rotated device: " + deviceKey.DeviceID)
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return updated, nil
+}
+
+func (d deviceKeyRepository) GetByUserID(ctx context.Context, userId string) ([]models.DeviceKey, error) {
+	const kName = "GetByUserID"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "GetByUserID")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "GetByUserID")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to convert user id to object id")
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	cursor, err := d.Collection.Find(ctx, bson.M{"userId": userID}, pageOptions(1, 0)) // every device, oldest first
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to find device keys")
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to close cursor")
+		}
+	}(cursor, ctx)
+
+	var deviceKeys []models.DeviceKey
+	if err := cursor.All(ctx, &deviceKeys); err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to decode device keys")
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return deviceKeys, nil
+}
+
+func (d deviceKeyRepository) GetByUserIDAndDeviceID(ctx context.Context, userId string, deviceId string) (*models.DeviceKey, error) {
+	const kName = "GetByUserIDAndDeviceID"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "GetByUserIDAndDeviceID")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "GetByUserIDAndDeviceID")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to convert user id to object id")
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	deviceKey := &models.DeviceKey{}
+	err = d.Collection.FindOne(ctx, bson.M{"userId": userID, "deviceId": deviceId}).Decode(deviceKey)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to find device key for device: " + deviceId)
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return deviceKey, nil
+}
+
+func (d deviceKeyRepository) DeleteByUserIDAndDeviceID(ctx context.Context, userId string, deviceId string) error {
+	const kName = "DeleteByUserIDAndDeviceID"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "DeleteByUserIDAndDeviceID")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "DeleteByUserIDAndDeviceID")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to convert user id to object id")
+		return mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	filter := bson.M{"userId": userID, "deviceId": deviceId}
+	_, err = d.PreKeysCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to delete one-time prekeys for device: " + deviceId)
+		return mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	_, err = d.Collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to delete device key for device: " + deviceId)
+		return mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return nil
+}
+
+func (d deviceKeyRepository) AddOneTimePreKeys(ctx context.Context, userId string, deviceId string, identityKey string, preKeys []models.OneTimePreKey) error {
+	const kName = "AddOneTimePreKeys"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "AddOneTimePreKeys")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "AddOneTimePreKeys")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	if len(preKeys) == 0 {
+		return nil
+	}
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to convert user id to object id")
+		return mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	docs := make([]interface{}, 0, len(preKeys))
+	for _, preKey := range preKeys {
+		preKey.ID = primitive.NilObjectID
+		preKey.UserID = userID
+		preKey.DeviceID = deviceId
+		preKey.IdentityKey = identityKey
+		preKey.CreatedAt = time.Now()
+		docs = append(docs, preKey)
+	}
+
+	// unordered so that already uploaded keyIds are skipped without stopping the batch
+	_, err = d.PreKeysCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to insert one-time prekeys for device: " + deviceId)
+		return mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return nil
+}
+
+func (d deviceKeyRepository) ConsumeOneTimePreKey(ctx context.Context, userId string, deviceId string, identityKey string) (*models.OneTimePreKey, error) {
+	const kName = "ConsumeOneTimePreKey"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "ConsumeOneTimePreKey")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "ConsumeOneTimePreKey")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to convert user id to object id")
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	// FindOneAndDelete guarantees a prekey is handed out to exactly one peer
+	opts := options.FindOneAndDelete().SetSort(bson.M{"keyId": 1})
+	preKey := &models.OneTimePreKey{}
+	err = d.PreKeysCollection.FindOneAndDelete(ctx, bson.M{"userId": userID, "deviceId": deviceId, "identityKey": identityKey}, opts).Decode(preKey)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Warn().Interface(kName, d.iName).Msg("no one-time prekeys left for device: " + deviceId)
+			return nil, nil
+		}
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to consume one-time prekey for device: " + deviceId)
+		return nil, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return preKey, nil
+}
+
+func (d deviceKeyRepository) CountOneTimePreKeys(ctx context.Context, userId string, deviceId string) (int64, error) {
+	const kName = "CountOneTimePreKeys"
+	defer metrics.ObserveMongo("DeviceKeyRepository", "CountOneTimePreKeys")()
+	ctx, span := tracing.Start(ctx, "DeviceKeyRepository", "CountOneTimePreKeys")
+	defer span.End()
+	logger := logging.FromContext(ctx, d.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to convert user id to object id")
+		return 0, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+
+	count, err := d.PreKeysCollection.CountDocuments(ctx, bson.M{"userId": userID, "deviceId": deviceId})
+	if err != nil {
+		logger.Error().Interface(kName, d.iName).Err(err).Msg("failed to count one-time prekeys for device: " + deviceId)
+		return 0, mapError(err, apperrors.CodeDeviceNotFound, "Device")
+	}
+	return count, nil
+}
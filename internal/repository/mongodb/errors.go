@@ -0,0 +1,32 @@
+package mongodb
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mapError translates driver errors into domain errors so services & controllers never depend on the driver.
+// notFoundCode is returned for mongo.ErrNoDocuments, resource names the entity in user facing messages.
+// Domain errors & unknown errors are returned unchanged.
+func mapError(err error, notFoundCode string, resource string) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	var invalidByte hex.InvalidByteError
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return apperrors.NotFound(notFoundCode, resource+" not found").WithErr(err)
+	case mongo.IsDuplicateKeyError(err):
+		return apperrors.Conflict(apperrors.CodeDuplicate, resource+" already exists").WithErr(err)
+	case errors.Is(err, primitive.ErrInvalidHex), errors.As(err, &invalidByte):
+		return apperrors.Validation(apperrors.CodeInvalidID, "Invalid "+resource+" id").WithErr(err)
+	}
+	return err
+}
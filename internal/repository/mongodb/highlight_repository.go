@@ -4,72 +4,87 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type highlightRepository struct {
 	Collection *mongo.Collection
 }
 
-func newHighlightRepository(db *mongo.Database) repository.HighlightRepository {
+func NewHighlightRepository(db *mongo.Database) repository.HighlightRepository {
 	return &highlightRepository{
 		Collection: db.Collection("highlights"),
 	}
 }
 
 func (h highlightRepository) Create(ctx context.Context, highlight *models.Highlight) error {
-	_, err := h.Collection.InsertOne(ctx, highlight)
+	defer metrics.ObserveMongo("HighlightRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "HighlightRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	res, err := h.Collection.InsertOne(ctx, highlight)
 	if err != nil {
-		log.Error().Err(err).Msg("Error inserting new highlight")
-		return err
+		logger.Error().Err(err).Msg("Error inserting new highlight")
+		return mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
+	highlight.Id = res.InsertedID.(primitive.ObjectID)
 	return nil
 }
 
 func (h highlightRepository) GetByID(ctx context.Context, id string) (*models.Highlight, error) {
+	defer metrics.ObserveMongo("HighlightRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "HighlightRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// highlight ID to search for
 	highlightID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert Highlight id to object id")
-		log.Debug().Err(err).Msg("failed to convert Highlight GetById id:" + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert Highlight id to object id")
+		logger.Debug().Err(err).Msg("failed to convert Highlight GetById id:" + id)
+		return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
 
 	highlight := models.Highlight{}
 	err = h.Collection.FindOne(ctx, bson.M{"_id": highlightID}).Decode(&highlight)
 	if err != nil {
-		log.Error().Err(err).Msg("Error finding highlight with id: " + id)
-		return nil, err
+		logger.Error().Err(err).Msg("Error finding highlight with id: " + id)
+		return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
 	return &highlight, nil
 }
 
 func (h highlightRepository) GetByUserId(ctx context.Context, userId string, page, limit int) ([]models.Highlight, error) {
+	defer metrics.ObserveMongo("HighlightRepository", "GetByUserId")()
+	ctx, span := tracing.Start(ctx, "HighlightRepository", "GetByUserId")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// user ID to search for
 	userID, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert Highlight - User id to object id")
-		log.Debug().Err(err).Msg("failed to convert GetByUserId id:" + userId)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert Highlight - User id to object id")
+		logger.Debug().Err(err).Msg("failed to convert GetByUserId id:" + userId)
+		return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
-	skip := (page - 1) * limit
-	findOptions := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	findOptions := pageOptions(page, limit)
 	cursor, err := h.Collection.Find(ctx, bson.M{"userId": userID}, findOptions)
 	if err != nil {
-		log.Error().Err(err).Msg("Error finding highlights in collection")
-		return nil, err
+		logger.Error().Err(err).Msg("Error finding highlights in collection")
+		return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
 	defer cursor.Close(ctx)
 	var highlights []models.Highlight
 	for cursor.Next(ctx) {
 		var highlight models.Highlight
 		if err := cursor.Decode(&highlight); err != nil {
-			log.Error().Err(err).Msg("Error decoding highlight")
-			return nil, err
+			logger.Error().Err(err).Msg("Error decoding highlight")
+			return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 		}
 		highlights = append(highlights, highlight)
 	}
@@ -77,20 +92,23 @@ func (h highlightRepository) GetByUserId(ctx context.Context, userId string, pag
 }
 
 func (h highlightRepository) List(ctx context.Context, page, limit int) ([]models.Highlight, error) {
-	skip := (page - 1) * limit
-	findOptions := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	defer metrics.ObserveMongo("HighlightRepository", "List")()
+	ctx, span := tracing.Start(ctx, "HighlightRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	findOptions := pageOptions(page, limit)
 	cursor, err := h.Collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		log.Error().Err(err).Msg("Error finding highlights in collection")
-		return nil, err
+		logger.Error().Err(err).Msg("Error finding highlights in collection")
+		return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
 	defer cursor.Close(ctx)
 	var highlights []models.Highlight
 	for cursor.Next(ctx) {
 		var highlight models.Highlight
 		if err := cursor.Decode(&highlight); err != nil {
-			log.Error().Err(err).Msg("Error decoding highlight")
-			return nil, err
+			logger.Error().Err(err).Msg("Error decoding highlight")
+			return nil, mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 		}
 		highlights = append(highlights, highlight)
 	}
@@ -98,28 +116,38 @@ func (h highlightRepository) List(ctx context.Context, page, limit int) ([]model
 }
 
 func (h highlightRepository) Update(ctx context.Context, highlight *models.Highlight) error {
-	filter := bson.M{"id": highlight.Id}
-	opts := options.FindOneAndUpdate().SetUpsert(true)
-	_, err := h.Collection.UpdateOne(ctx, filter, opts)
+	defer metrics.ObserveMongo("HighlightRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "HighlightRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	filter := bson.M{"_id": highlight.Id}
+	res, err := h.Collection.UpdateOne(ctx, filter, bson.M{"$set": highlight})
 	if err != nil {
-		log.Error().Err(err).Msg("Error updating highlight with id: " + highlight.Id.String())
-		return err
+		logger.Error().Err(err).Msg("Error updating highlight with id: " + highlight.Id.Hex())
+		return mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeHighlightNotFound, "Highlight not found")
 	}
 	return nil
 }
 
 func (h highlightRepository) Delete(ctx context.Context, id string) error {
+	defer metrics.ObserveMongo("HighlightRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "HighlightRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// highlight ID to search for
 	highlightID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert Highlight-Delete-id to object id")
-		log.Debug().Err(err).Msg("failed to convert  Highlight-Delete-id:" + id)
-		return err
+		logger.Error().Err(err).Msg("failed to convert Highlight-Delete-id to object id")
+		logger.Debug().Err(err).Msg("failed to convert  Highlight-Delete-id:" + id)
+		return mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
 	_, err = h.Collection.DeleteOne(ctx, bson.M{"_id": highlightID})
 	if err != nil {
-		log.Error().Err(err).Msg("Error deleting highlight with id: " + id)
-		return err
+		logger.Error().Err(err).Msg("Error deleting highlight with id: " + id)
+		return mapError(err, apperrors.CodeHighlightNotFound, "Highlight")
 	}
 	return nil
 }
@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type lastSeenRepository struct {
+	iName      string
+	logger     *zerolog.Logger
+	Collection *mongo.Collection
+}
+
+func NewLastSeenRepository(log *zerolog.Logger, db *mongo.Database) repository.ILastSeenRepository {
+	return &lastSeenRepository{
+		iName:      "LastSeenRepository",
+		logger:     log,
+		Collection: db.Collection("last_seen"),
+	}
+}
+
+func (l lastSeenRepository) Save(ctx context.Context, lastSeen []models.LastSeen) error {
+	const kName = "Save"
+	if len(lastSeen) == 0 {
+		return nil
+	}
+	defer metrics.ObserveMongo("LastSeenRepository", "Save")()
+	ctx, span := tracing.Start(ctx, "LastSeenRepository", "Save")
+	defer span.End()
+	logger := logging.FromContext(ctx, l.logger)
+
+	// $max keeps the latest time when servers flush out of order
+	writes := make([]mongo.WriteModel, 0, len(lastSeen))
+	for _, seen := range lastSeen {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": seen.UserID}).
+			SetUpdate(bson.M{"$max": bson.M{"lastSeenAt": seen.LastSeenAt}}).
+			SetUpsert(true))
+	}
+	if _, err := l.Collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		logger.Error().Interface(kName, l.iName).Err(err).Int("users", len(lastSeen)).Msg("failed to save last seen")
+		return mapError(err, apperrors.CodeLastSeenNotFound, "LastSeen")
+	}
+	return nil
+}
+
+func (l lastSeenRepository) GetByUserId(ctx context.Context, userId string) (*models.LastSeen, error) {
+	const kName = "GetByUserId"
+	defer metrics.ObserveMongo("LastSeenRepository", "GetByUserId")()
+	ctx, span := tracing.Start(ctx, "LastSeenRepository", "GetByUserId")
+	defer span.End()
+	logger := logging.FromContext(ctx, l.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, l.iName).Err(err).Msg("failed to convert user id to object id")
+		return nil, mapError(err, apperrors.CodeLastSeenNotFound, "LastSeen")
+	}
+	lastSeen := &models.LastSeen{}
+	if err = l.Collection.FindOne(ctx, bson.M{"_id": userID}).Decode(lastSeen); err != nil {
+		logger.Debug().Interface(kName, l.iName).Err(err).Msg("failed to find last seen of user: " + userId)
+		return nil, mapError(err, apperrors.CodeLastSeenNotFound, "LastSeen")
+	}
+	return lastSeen, nil
+}
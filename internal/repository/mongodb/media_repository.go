@@ -4,6 +4,10 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,138 +26,335 @@ func NewMediaRepository(db *mongo.Database) repository.MediaRepository {
 }
 
 func (m mediaRepository) Create(ctx context.Context, media *models.Media) error {
-	_, err := m.Collection.InsertOne(ctx, media)
+	defer metrics.ObserveMongo("MediaRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	res, err := m.Collection.InsertOne(ctx, media)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to insert media")
-		return err
+		logger.Error().Err(err).Msg("failed to insert media")
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
+	media.Id = res.InsertedID.(primitive.ObjectID)
 	return nil
 }
 
 func (m mediaRepository) GetByID(ctx context.Context, id string) (*models.Media, error) {
+	defer metrics.ObserveMongo("MediaRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// media ID to search for
 	mediaID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert Media-GetById id to object id")
-		log.Debug().Err(err).Msg("failed to convert media getBy-id:" + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert Media-GetById id to object id")
+		logger.Debug().Err(err).Msg("failed to convert media getBy-id:" + id)
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	media := &models.Media{}
 	err = m.Collection.FindOne(ctx, bson.M{"_id": mediaID}).Decode(media)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find media with id: " + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find media with id: " + id)
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	return media, nil
 }
 
 func (m mediaRepository) GetByChatId(ctx context.Context, chatId string, page, limit int) ([]models.Media, error) {
+	defer metrics.ObserveMongo("MediaRepository", "GetByChatId")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "GetByChatId")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// chat ID to search for
 	chatID, err := primitive.ObjectIDFromHex(chatId)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert Media-GetByChatId-id to object id")
-		log.Debug().Err(err).Msg("failed to convert media-ChatId id:" + chatId)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert Media-GetByChatId-id to object id")
+		logger.Debug().Err(err).Msg("failed to convert media-ChatId id:" + chatId)
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
-	skip := (page - 1) * limit
-	findOptions := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	findOptions := pageOptions(page, limit)
 	cursor, err := m.Collection.Find(ctx, bson.M{"chatId": chatID}, findOptions)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find media in collection from mediaRepository.GetByChatId")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find media in collection from mediaRepository.GetByChatId")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to close cursor in mediaRepository.GetByChatId")
+			logger.Error().Err(err).Msg("failed to close cursor in mediaRepository.GetByChatId")
 		}
 	}(cursor, ctx)
 	var results []models.Media
 	if err := cursor.All(ctx, &results); err != nil {
-		log.Error().Err(err).Msg("failed to decode results in mediaRepository.GetByChatId")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to decode results in mediaRepository.GetByChatId")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	return results, nil
 }
 
 func (m mediaRepository) GetBySenderId(ctx context.Context, senderId string, page, limit int) ([]models.Media, error) {
+	defer metrics.ObserveMongo("MediaRepository", "GetBySenderId")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "GetBySenderId")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 
 	// sender ID to search for
 	senderID, err := primitive.ObjectIDFromHex(senderId)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert id to object id")
-		log.Debug().Err(err).Msg("failed to convert id:" + senderId)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Err(err).Msg("failed to convert id:" + senderId)
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
-	skip := (page - 1) * limit
-	findOptions := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	findOptions := pageOptions(page, limit)
 	cursor, err := m.Collection.Find(ctx, bson.M{"senderId": senderID}, findOptions)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find media in collection mediaRepository.GetBySenderId")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find media in collection mediaRepository.GetBySenderId")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to close cursor in mediaRepository.GetBySenderId")
+			logger.Error().Err(err).Msg("failed to close cursor in mediaRepository.GetBySenderId")
 		}
 	}(cursor, ctx)
 
 	var results []models.Media
 	if err := cursor.All(ctx, &results); err != nil {
-		log.Error().Err(err).Msg("failed to decode results")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to decode results")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	return results, nil
 }
 
 func (m mediaRepository) List(ctx context.Context, page, limit int) ([]models.Media, error) {
-	skip := (page - 1) * limit
-	findOptions := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+	defer metrics.ObserveMongo("MediaRepository", "List")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	findOptions := pageOptions(page, limit)
 	cursor, err := m.Collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to find media in collection from mediaRepository.List")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find media in collection from mediaRepository.List")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to close cursor in mediaRepository.List")
+			logger.Error().Err(err).Msg("failed to close cursor in mediaRepository.List")
 		}
 	}(cursor, ctx)
 	var results []models.Media
 	if err := cursor.All(ctx, &results); err != nil {
-		log.Error().Err(err).Msg("failed to decode results")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to decode results")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 	return results, nil
 }
 
 func (m mediaRepository) Update(ctx context.Context, media *models.Media) error {
-	filter := bson.D{{"_id", media.Id}}
-	update := bson.D{{"$set", media}}
+	defer metrics.ObserveMongo("MediaRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	filter := bson.D{{Key: "_id", Value: media.Id}}
+	update := bson.D{{Key: "$set", Value: media}}
 	opts := options.Update().SetUpsert(false)
-	_, err := m.Collection.UpdateOne(ctx, filter, update, opts)
+	res, err := m.Collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update media with id: " + media.Id.String())
-		return err
+		logger.Error().Err(err).Msg("failed to update media with id: " + media.Id.Hex())
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeMediaNotFound, "Media not found")
 	}
 	return nil
 }
 
 func (m mediaRepository) Delete(ctx context.Context, id string) error {
+	defer metrics.ObserveMongo("MediaRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
 	// media ID to search for
 	mediaID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to convert id to object id")
-		log.Debug().Err(err).Msg("failed to convert id:" + id)
-		return err
+		logger.Error().Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Err(err).Msg("failed to convert id:" + id)
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
 	}
 
 	_, err = m.Collection.DeleteOne(ctx, bson.M{"_id": mediaID})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete media with id: " + id)
-		return err
+		logger.Error().Err(err).Msg("failed to delete media with id: " + id)
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	return nil
+}
+
+func (m mediaRepository) AttachToMessage(ctx context.Context, messageId string, mediaIds []primitive.ObjectID) error {
+	defer metrics.ObserveMongo("MediaRepository", "AttachToMessage")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "AttachToMessage")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	messageID, err := primitive.ObjectIDFromHex(messageId)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to convert message id to object id")
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	if len(mediaIds) == 0 {
+		return nil
+	}
+	// forwarded media keep the message they were first sent with
+	filter := bson.M{"_id": bson.M{"$in": mediaIds}, "messageId": bson.M{"$exists": false}}
+	if _, err = m.Collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"messageId": messageID}}); err != nil {
+		logger.Error().Err(err).Msg("failed to attach media to message: " + messageId)
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	return nil
+}
+
+func (m mediaRepository) DetachFromMessage(ctx context.Context, messageId string) error {
+	defer metrics.ObserveMongo("MediaRepository", "DetachFromMessage")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "DetachFromMessage")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	messageID, err := primitive.ObjectIDFromHex(messageId)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to convert message id to object id")
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	if _, err = m.Collection.UpdateMany(ctx, bson.M{"messageId": messageID}, bson.M{"$unset": bson.M{"messageId": ""}}); err != nil {
+		logger.Error().Err(err).Msg("failed to detach media from message: " + messageId)
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	return nil
+}
+
+func (m mediaRepository) ListGallery(ctx context.Context, chatId string, mediaType string, before string, limit int) ([]models.Media, error) {
+	defer metrics.ObserveMongo("MediaRepository", "ListGallery")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "ListGallery")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	chatID, err := primitive.ObjectIDFromHex(chatId)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to convert chat id to object id")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	filter := bson.M{"chatId": chatID, "mediaType": mediaType, "messageId": bson.M{"$exists": true}}
+	findOptions, err := cursorPage(filter, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := m.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to find media in collection from mediaRepository.ListGallery")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to close cursor in mediaRepository.ListGallery")
+		}
+	}(cursor, ctx)
+	var results []models.Media
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error().Err(err).Msg("failed to decode results in mediaRepository.ListGallery")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	return results, nil
+}
+
+func (m mediaRepository) ListByScanStatus(ctx context.Context, status string, limit int) ([]models.Media, error) {
+	defer metrics.ObserveMongo("MediaRepository", "ListByScanStatus")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "ListByScanStatus")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := m.Collection.Find(ctx, bson.M{"scanStatus": status}, findOptions)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to find media in collection from mediaRepository.ListByScanStatus")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to close cursor in mediaRepository.ListByScanStatus")
+		}
+	}(cursor, ctx)
+	var results []models.Media
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error().Err(err).Msg("failed to decode results in mediaRepository.ListByScanStatus")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	return results, nil
+}
+
+func (m mediaRepository) SetScanStatus(ctx context.Context, id string, status string, signature string) error {
+	defer metrics.ObserveMongo("MediaRepository", "SetScanStatus")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "SetScanStatus")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	mediaID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to convert media id to object id")
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	result, err := m.Collection.UpdateOne(ctx, bson.M{"_id": mediaID}, bson.M{"$set": bson.M{"scanStatus": status, "scanSignature": signature}})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to set scan status of media: " + id)
+		return mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeMediaNotFound, "Media not found")
 	}
 	return nil
 }
+
+func (m mediaRepository) StorageUsage(ctx context.Context, senderIds []primitive.ObjectID, until primitive.ObjectID) ([]models.StorageUsage, error) {
+	defer metrics.ObserveMongo("MediaRepository", "StorageUsage")()
+	ctx, span := tracing.Start(ctx, "MediaRepository", "StorageUsage")
+	defer span.End()
+	logger := logging.FromContext(ctx, &log.Logger)
+	if len(senderIds) == 0 {
+		return nil, nil
+	}
+	match := bson.M{"senderId": bson.M{"$in": senderIds}}
+	if !until.IsZero() {
+		match["_id"] = bson.M{"$lte": until}
+	}
+	// the media of a blob are grouped oldest first, only the first one counts the blob's bytes. Media stored before
+	// deduplication have no blob & count on their own.
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"$ifNull": bson.A{"$blobId", "$_id"}},
+			"fileSize": bson.M{"$first": "$fileSize"},
+			"media":    bson.M{"$push": bson.M{"chatId": "$chatId", "mediaType": "$mediaType"}},
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$media", "includeArrayIndex": "i"}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"chatId": "$media.chatId", "mediaType": "$media.mediaType"},
+			"bytes": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$i", 0}}, bson.M{"$toLong": "$fileSize"}, 0}}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "chatId": "$_id.chatId", "mediaType": "$_id.mediaType", "bytes": 1, "count": 1}}},
+	}
+	cursor, err := m.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to aggregate media in collection from mediaRepository.StorageUsage")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to close cursor in mediaRepository.StorageUsage")
+		}
+	}(cursor, ctx)
+	var usage []models.StorageUsage
+	if err := cursor.All(ctx, &usage); err != nil {
+		logger.Error().Err(err).Msg("failed to decode results in mediaRepository.StorageUsage")
+		return nil, mapError(err, apperrors.CodeMediaNotFound, "Media")
+	}
+	return usage, nil
+}
@@ -2,56 +2,156 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type messageRepository struct {
-	Collection *mongo.Collection
-	iName      string
-	logger     *zerolog.Logger
+	Collection  *mongo.Collection
+	iName       string
+	logger      *zerolog.Logger
+	chatKeyRepo repository.IChatKeyRepository
 }
 
-func NewMessageRepository(log *zerolog.Logger, db *mongo.Database) repository.MessageRepository {
+func NewMessageRepository(log *zerolog.Logger, db *mongo.Database, chatKeyRepo repository.IChatKeyRepository) repository.MessageRepository {
 	return &messageRepository{
-		iName:      "MessageRepository",
-		Collection: db.Collection("messages"),
-		logger:     log,
+		iName:       "MessageRepository",
+		Collection:  db.Collection("messages"),
+		logger:      log,
+		chatKeyRepo: chatKeyRepo,
 	}
 }
 
+// chatEncryptionService returns an encryption service keyed with the chat's data key,
+// when create is set a data key is generated for chats that do not have one yet
+func (m messageRepository) chatEncryptionService(ctx context.Context, chatID primitive.ObjectID, create bool) (services.IEncryptionService, error) {
+	var chatKey *models.ChatKey
+	var err error
+	if create {
+		chatKey, err = m.chatKeyRepo.GetOrCreateByChatID(ctx, chatID.Hex())
+	} else {
+		chatKey, err = m.chatKeyRepo.GetByChatID(ctx, chatID.Hex())
+	}
+	if err != nil {
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	return services.NewAESEncryptionService(chatKey.Key, m.logger)
+}
+
+// decryptMessage decrypts a stored message with the key of its chat, cached in encSvcs when given, a message of a
+// shredded chat or one that fails to decrypt is left without content
+func (m messageRepository) decryptMessage(ctx context.Context, message *models.Message, encSvcs map[primitive.ObjectID]services.IEncryptionService) error {
+	if !message.Encrypted {
+		return nil
+	}
+	encSvc, ok := encSvcs[message.ChatID]
+	if !ok {
+		var err error
+		encSvc, err = m.chatEncryptionService(ctx, message.ChatID, false)
+		if err != nil && !errors.Is(err, repository.ErrChatKeyNotFound) {
+			return err
+		}
+		if encSvcs != nil {
+			encSvcs[message.ChatID] = encSvc // nil once the chat is shredded
+		}
+	}
+	if encSvc == nil {
+		message.Content, message.MediaUrls = "", nil
+		return nil
+	}
+	if err := message.DecryptFields(encSvc); err != nil {
+		logging.FromContext(ctx, m.logger).Error().Interface("decryptMessage", m.iName).Err(err).Msg("failed to decrypt message with id: " + message.ID.Hex())
+		message.Content, message.MediaUrls = "", nil
+	}
+	return nil
+}
+
+// decryptMessages decrypts listed messages with one key lookup per chat
+func (m messageRepository) decryptMessages(ctx context.Context, messages []models.Message) error {
+	encSvcs := map[primitive.ObjectID]services.IEncryptionService{}
+	for i := range messages {
+		if err := m.decryptMessage(ctx, &messages[i], encSvcs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m messageRepository) Create(ctx context.Context, message *models.Message) (*models.Message, error) {
 	const kName = "Create"
+	defer metrics.ObserveMongo("MessageRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
+
+	if message.ChatID.IsZero() {
+		err := apperrors.Validation(apperrors.CodeValidation, "Message has no chat", apperrors.RequiredField("chatId"))
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Cannot encrypt message without a chat")
+		return nil, err
+	}
+
+	// Encrypt content with the chat's data key before saving
+	encSvc, err := m.chatEncryptionService(ctx, message.ChatID, true)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to get chat encryption key")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	err = message.EncryptFields(encSvc)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to encrypt message")
+		return nil, mapError(err, apperrors.CodeInternal, "Message")
+	}
 
 	res, err := m.Collection.InsertOne(ctx, message)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Error inserting message")
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Error inserting message")
+		return nil, mapError(err, apperrors.CodeInternal, "Message")
 	}
 	message.ID = res.InsertedID.(primitive.ObjectID)
+
+	// Decrypt for use
+	err = message.DecryptFields(encSvc)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to decrypt new message")
+		return nil, mapError(err, apperrors.CodeInternal, "Message")
+	}
 	return message, nil
 
 }
 
 func (m messageRepository) GetByID(ctx context.Context, id string) (*models.Message, error) {
 	const kName = "GetByID"
+	defer metrics.ObserveMongo("MessageRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
 	// message ID to search for
 	messageID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert Message-GetById-id to object id")
-		m.logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert message-GetById id:" + id)
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert Message-GetById-id to object id")
+		logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert message-GetById id:" + id)
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 	message := &models.Message{}
 	err = m.Collection.FindOne(ctx, bson.M{"_id": messageID}).Decode(message)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Error finding message in GetByID")
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Error finding message in GetByID")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	err = m.decryptMessage(ctx, message, nil)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to decrypt message with id: " + message.ID.Hex())
+		return nil, mapError(err, apperrors.CodeInternal, "Message")
 	}
 	return message, nil
 
@@ -59,38 +159,56 @@ func (m messageRepository) GetByID(ctx context.Context, id string) (*models.Mess
 
 func (m messageRepository) GetByChatID(ctx context.Context, chatId string) (*models.Message, error) {
 	const kName = "GetByChatID"
+	defer metrics.ObserveMongo("MessageRepository", "GetByChatID")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "GetByChatID")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
 
 	// chat ID to search for
 	chatID, err := primitive.ObjectIDFromHex(chatId)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert Message-GetByChatId-id to object id")
-		m.logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert message-GetByChat id:" + chatId)
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert Message-GetByChatId-id to object id")
+		logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert message-GetByChat id:" + chatId)
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 	message := &models.Message{}
-	err = m.Collection.FindOne(ctx, bson.M{"chatId": chatID}).Decode(message)
+	err = m.Collection.FindOne(ctx, bson.M{"chatId": chatID}, firstOptions()).Decode(message)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Error finding message in GetByChatId")
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Error finding message in GetByChatId")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	err = m.decryptMessage(ctx, message, nil)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to decrypt message with id: " + message.ID.Hex())
+		return nil, mapError(err, apperrors.CodeInternal, "Message")
 	}
 	return message, nil
 
 }
 func (m messageRepository) GetBySenderID(ctx context.Context, userId string) (*models.Message, error) {
 	const kName = "GetBySenderID"
+	defer metrics.ObserveMongo("MessageRepository", "GetBySenderID")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "GetBySenderID")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
 
 	// sender user ID to search for
 	senderID, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert id to object id")
-		m.logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert id:" + userId)
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert id:" + userId)
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 	message := &models.Message{}
-	err = m.Collection.FindOne(ctx, bson.M{"senderId": senderID}).Decode(message)
+	err = m.Collection.FindOne(ctx, bson.M{"senderId": senderID}, firstOptions()).Decode(message)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("Error finding message in GetBySenderId")
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Error finding message in GetBySenderId")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	err = m.decryptMessage(ctx, message, nil)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to decrypt message with id: " + message.ID.Hex())
+		return nil, mapError(err, apperrors.CodeInternal, "Message")
 	}
 	return message, nil
 
@@ -98,65 +216,136 @@ func (m messageRepository) GetBySenderID(ctx context.Context, userId string) (*m
 
 func (m messageRepository) List(ctx context.Context, page, limit int) ([]models.Message, error) {
 	const kName = "List"
+	defer metrics.ObserveMongo("MessageRepository", "List")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
 
-	// Calculate how many documents to skip
-	skip := (page - 1) * limit
-
-	// Create options with pagination parameters
-	findOptions := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit))
+	findOptions := pageOptions(page, limit)
 
 	// Execute the find operation with options
 	cursor, err := m.Collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to query settings")
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to query settings")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 
 	// Don't forget to close the cursor when we're done
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to close cursor in messageRepository.List")
+			logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to close cursor in messageRepository.List")
 		}
 	}(cursor, ctx)
 
 	// Parse all the documents
 	var messages []models.Message
 	if err := cursor.All(ctx, &messages); err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to decode messages")
-		return nil, err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to decode messages")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+
+	if err = m.decryptMessages(ctx, messages); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to get chat encryption keys")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 	return messages, nil
 
 }
 
+func (m messageRepository) ListWithLinks(ctx context.Context, chatId string, before string, limit int) ([]models.Message, error) {
+	const kName = "ListWithLinks"
+	defer metrics.ObserveMongo("MessageRepository", "ListWithLinks")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "ListWithLinks")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
+
+	chatID, err := primitive.ObjectIDFromHex(chatId)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert chat id to object id")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	filter := bson.M{"chatId": chatID, "hasLinks": true}
+	findOptions, err := cursorPage(filter, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := m.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to query messages with links")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to close cursor in messageRepository.ListWithLinks")
+		}
+	}(cursor, ctx)
+
+	var messages []models.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to decode messages")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	if err = m.decryptMessages(ctx, messages); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to get chat encryption keys")
+		return nil, mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	return messages, nil
+}
+
 func (m messageRepository) Update(ctx context.Context, message *models.Message) error {
 	const kName = "Update"
+	defer metrics.ObserveMongo("MessageRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
 
-	_, err := m.Collection.UpdateOne(ctx, bson.M{"id": message.ID}, bson.M{"$set": message})
+	// Encrypt content with the chat's data key before saving
+	encSvc, err := m.chatEncryptionService(ctx, message.ChatID, false)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to update message with id: " + message.ID.String())
-		return err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to get chat encryption key for message with id: " + message.ID.Hex())
+		return mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	err = message.EncryptFields(encSvc)
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to encrypt message with id: " + message.ID.Hex())
+		return mapError(err, apperrors.CodeInternal, "Message")
+	}
+
+	res, err := m.Collection.UpdateOne(ctx, bson.M{"_id": message.ID}, bson.M{"$set": message})
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to update message with id: " + message.ID.Hex())
+		return mapError(err, apperrors.CodeMessageNotFound, "Message")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeMessageNotFound, "Message not found")
+	}
+	if err = message.DecryptFields(encSvc); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to decrypt updated message with id: " + message.ID.Hex())
+		return mapError(err, apperrors.CodeInternal, "Message")
 	}
 	return nil
 }
 
 func (m messageRepository) Delete(ctx context.Context, id string) error {
 	const kName = "Delete"
+	defer metrics.ObserveMongo("MessageRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "MessageRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.logger)
 
 	// message ID to search for
 	messageID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert id to object id")
-		m.logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert id:" + id)
-		return err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to convert id to object id")
+		logger.Debug().Interface(kName, m.iName).Err(err).Msg("failed to convert id:" + id)
+		return mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 	_, err = m.Collection.DeleteOne(ctx, bson.M{"_id": messageID})
 	if err != nil {
-		m.logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to delete message with id: " + id)
-		return err
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("failed to delete message with id: " + id)
+		return mapError(err, apperrors.CodeMessageNotFound, "Message")
 	}
 	return nil
 }
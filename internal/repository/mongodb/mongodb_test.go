@@ -0,0 +1,70 @@
+package mongodb_test
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/databases/mongodb/migrations"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/mongodb"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/repositorytest"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestRepositories runs the conformance suite against the MongoDB server at MONGODB_TEST_URI,
+// every test gets its own database which is dropped afterwards
+func TestRepositories(t *testing.T) {
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI is not set")
+	}
+	log := zerolog.Nop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+	if err = client.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	repositorytest.Run(t, func(t *testing.T, keys repositorytest.Keys) repositorytest.Repositories {
+		db := client.Database("telko_test_" + primitive.NewObjectID().Hex())
+		t.Cleanup(func() { _ = db.Drop(context.Background()) })
+
+		migrator, err := migrations.NewMigrator(&log, db, migrations.All(&log))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = migrator.Up(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+
+		chatKeys := mongodb.NewChatKeyRepository(&log, db, keys.Encryption)
+		return repositorytest.Repositories{
+			Users:           mongodb.NewUserRepository(&log, db, keys.Encryption, keys.SearchKey),
+			Settings:        mongodb.NewSettingsRepository(&log, db),
+			LastSeen:        mongodb.NewLastSeenRepository(&log, db),
+			Chats:           mongodb.NewChatRepository(&log, db),
+			ChatKeys:        chatKeys,
+			Messages:        mongodb.NewMessageRepository(&log, db, chatKeys),
+			PlayedReceipts:  mongodb.NewPlayedReceiptRepository(&log, db),
+			ChatGroups:      mongodb.NewChatGroupRepository(db),
+			Highlights:      mongodb.NewHighlightRepository(db),
+			Media:           mongodb.NewMediaRepository(db),
+			Uploads:         mongodb.NewUploadRepository(&log, db),
+			Blobs:           mongodb.NewBlobRepository(&log, db),
+			Subscriptions:   mongodb.NewSubscriptionRepository(&log, db),
+			DeviceKeys:      mongodb.NewDeviceKeyRepository(&log, db),
+			Authentications: mongodb.NewAuthenticationRepository(&log, db, keys.Encryption, keys.SearchKey),
+			RateLimits:      mongodb.NewRateLimitRepository(&log, db),
+			UnitOfWork:      mongodb.NewUnitOfWork(&log, db),
+		}
+	})
+}
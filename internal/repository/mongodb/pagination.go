@@ -0,0 +1,44 @@
+package mongodb
+
+import (
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pageOptions returns the find options of a 1-based page in creation order, pages below 1 are the first page
+// and a limit of 0 or less returns every document.
+func pageOptions(page, limit int) *options.FindOptions {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if limit <= 0 {
+		return opts
+	}
+	if page < 1 {
+		page = 1
+	}
+	return opts.SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+}
+
+// firstOptions makes FindOne return the oldest matching document
+func firstOptions() *options.FindOneOptions {
+	return options.FindOne().SetSort(bson.D{{Key: "_id", Value: 1}})
+}
+
+// cursorPage adds the _id condition of a page newest first to filter & returns its find options. The page
+// continues after the document with the id before unless it is empty, a limit of 0 or less returns every document.
+func cursorPage(filter bson.M, before string, limit int) (*options.FindOptions, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if before == "" {
+		return opts, nil
+	}
+	beforeID, err := primitive.ObjectIDFromHex(before)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid cursor").WithErr(err)
+	}
+	filter["_id"] = bson.M{"$lt": beforeID}
+	return opts, nil
+}
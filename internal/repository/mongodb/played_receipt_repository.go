@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type playedReceiptRepository struct {
+	iName      string
+	logger     *zerolog.Logger
+	Collection *mongo.Collection
+}
+
+func NewPlayedReceiptRepository(log *zerolog.Logger, db *mongo.Database) repository.IPlayedReceiptRepository {
+	return &playedReceiptRepository{
+		iName:      "PlayedReceiptRepository",
+		logger:     log,
+		Collection: db.Collection("played_receipts"),
+	}
+}
+
+func (p playedReceiptRepository) MarkPlayed(ctx context.Context, receipt *models.PlayedReceipt) (*models.PlayedReceipt, error) {
+	const kName = "MarkPlayed"
+	defer metrics.ObserveMongo("PlayedReceiptRepository", "MarkPlayed")()
+	ctx, span := tracing.Start(ctx, "PlayedReceiptRepository", "MarkPlayed")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.logger)
+
+	id := receipt.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	// the unique messageId & userId index makes concurrent plays upsert a single receipt
+	filter := bson.M{"messageId": receipt.MessageID, "userId": receipt.UserID}
+	update := bson.M{"$setOnInsert": bson.M{"_id": id, "chatId": receipt.ChatID, "playedAt": receipt.PlayedAt}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	marked := &models.PlayedReceipt{}
+	if err := p.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(marked); err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("failed to mark message played: " + receipt.MessageID.Hex())
+		return nil, mapError(err, apperrors.CodeReceiptNotFound, "Receipt")
+	}
+	return marked, nil
+}
+
+func (p playedReceiptRepository) ListByMessageId(ctx context.Context, messageId string) ([]models.PlayedReceipt, error) {
+	const kName = "ListByMessageId"
+	defer metrics.ObserveMongo("PlayedReceiptRepository", "ListByMessageId")()
+	ctx, span := tracing.Start(ctx, "PlayedReceiptRepository", "ListByMessageId")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.logger)
+
+	messageID, err := primitive.ObjectIDFromHex(messageId)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("failed to convert message id to object id")
+		return nil, mapError(err, apperrors.CodeReceiptNotFound, "Receipt")
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "playedAt", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := p.Collection.Find(ctx, bson.M{"messageId": messageID}, opts)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("failed to list receipts of message: " + messageId)
+		return nil, mapError(err, apperrors.CodeReceiptNotFound, "Receipt")
+	}
+	var receipts []models.PlayedReceipt
+	if err = cursor.All(ctx, &receipts); err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("failed to decode receipts of message: " + messageId)
+		return nil, mapError(err, apperrors.CodeReceiptNotFound, "Receipt")
+	}
+	return receipts, nil
+}
+
+func (p playedReceiptRepository) DeleteByMessageId(ctx context.Context, messageId string) error {
+	const kName = "DeleteByMessageId"
+	defer metrics.ObserveMongo("PlayedReceiptRepository", "DeleteByMessageId")()
+	ctx, span := tracing.Start(ctx, "PlayedReceiptRepository", "DeleteByMessageId")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.logger)
+
+	messageID, err := primitive.ObjectIDFromHex(messageId)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("failed to convert message id to object id")
+		return mapError(err, apperrors.CodeReceiptNotFound, "Receipt")
+	}
+	if _, err = p.Collection.DeleteMany(ctx, bson.M{"messageId": messageID}); err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("failed to delete receipts of message: " + messageId)
+		return mapError(err, apperrors.CodeReceiptNotFound, "Receipt")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strconv"
+	"time"
+)
+
+type rateLimitRepository struct {
+	iName      string
+	logger     *zerolog.Logger
+	Collection *mongo.Collection
+}
+
+// NewRateLimitRepository stores the rate windows in a collection whose TTL index deletes the ended ones
+func NewRateLimitRepository(log *zerolog.Logger, db *mongo.Database) repository.IRateLimitRepository {
+	return &rateLimitRepository{
+		iName:      "RateLimitRepository",
+		logger:     log,
+		Collection: db.Collection("rate_limits"),
+	}
+}
+
+func (r rateLimitRepository) Hit(ctx context.Context, key string, windowStart time.Time, window time.Duration) (int64, error) {
+	const kName = "Hit"
+	defer metrics.ObserveMongo("RateLimitRepository", "Hit")()
+	ctx, span := tracing.Start(ctx, "RateLimitRepository", "Hit")
+	defer span.End()
+	logger := logging.FromContext(ctx, r.logger)
+
+	filter := bson.M{"_id": key + "@" + strconv.FormatInt(windowStart.Unix(), 10)}
+	update := bson.M{
+		"$inc":         bson.M{"calls": 1},
+		"$setOnInsert": bson.M{"expiresAt": windowStart.Add(window)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	rateWindow := &models.RateWindow{}
+	err := r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(rateWindow)
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent call opened the window first, count in it
+		err = r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(rateWindow)
+	}
+	if err != nil {
+		logger.Error().Interface(kName, r.iName).Err(err).Msg("failed to count call of key: " + key)
+		return 0, mapError(err, apperrors.CodeNotFound, "RateWindow")
+	}
+	return rateWindow.Calls, nil
+}
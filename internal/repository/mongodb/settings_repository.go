@@ -4,6 +4,10 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -24,116 +28,166 @@ func NewSettingsRepository(log *zerolog.Logger, db *mongo.Database) repository.I
 }
 
 func (s settingsRepository) Create(ctx context.Context, settings *models.Settings) (*models.Settings, error) {
+	defer metrics.ObserveMongo("SettingsRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
 	result, err := s.Collection.InsertOne(ctx, settings)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to create settings")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to create settings")
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 	settings.ID = result.InsertedID.(primitive.ObjectID)
 	return settings, nil
 }
 
 func (s settingsRepository) GetByID(ctx context.Context, id string) (*models.Settings, error) {
+	defer metrics.ObserveMongo("SettingsRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
 	// settings ID to search for
 	settingsID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to convert settings id to object id")
-		s.Logger.Debug().Err(err).Msg("failed to convert GetByID id:" + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert settings id to object id")
+		logger.Debug().Err(err).Msg("failed to convert GetByID id:" + id)
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 
 	settings := &models.Settings{}
 	err = s.Collection.FindOne(ctx, bson.M{"_id": settingsID}).Decode(settings)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to find settings with id: " + id)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find settings with id: " + id)
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 	return settings, nil
 
 }
 
 func (s settingsRepository) GetByUserID(ctx context.Context, userId string) (*models.Settings, error) {
+	defer metrics.ObserveMongo("SettingsRepository", "GetByUserID")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "GetByUserID")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
 	// user ID to search for
 	userID, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to convert userId to object id")
-		s.Logger.Debug().Err(err).Msg("failed to convert GetByUserID id:" + userId)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to convert userId to object id")
+		logger.Debug().Err(err).Msg("failed to convert GetByUserID id:" + userId)
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 
 	settings := &models.Settings{}
 	err = s.Collection.FindOne(ctx, bson.M{"userId": userID}).Decode(settings)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to find settings with id: " + userId)
-		return nil, err
+		logger.Error().Err(err).Msg("failed to find settings with id: " + userId)
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 	return settings, nil
 }
 
-func (s settingsRepository) List(ctx context.Context, page, limit int) ([]models.Settings, error) {
-	// Calculate how many documents to skip
-	skip := (page - 1) * limit
+func (s settingsRepository) ListByUserIDs(ctx context.Context, userIds []primitive.ObjectID) ([]models.Settings, error) {
+	defer metrics.ObserveMongo("SettingsRepository", "ListByUserIDs")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "ListByUserIDs")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
+
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+	cursor, err := s.Collection.Find(ctx, bson.M{"userId": bson.M{"$in": userIds}})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to query settings of users")
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to close cursor")
+		}
+	}(cursor, ctx)
 
-	// Create options with pagination parameters
-	findOptions := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit))
+	var settingsList []models.Settings
+	if err = cursor.All(ctx, &settingsList); err != nil {
+		logger.Error().Err(err).Msg("failed to decode settings of users")
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
+	}
+	return settingsList, nil
+}
+
+func (s settingsRepository) List(ctx context.Context, page, limit int) ([]models.Settings, error) {
+	defer metrics.ObserveMongo("SettingsRepository", "List")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
+	findOptions := pageOptions(page, limit)
 
 	// Execute the find operation with options
 	cursor, err := s.Collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to query settings")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to query settings")
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 
 	// Don't forget to close the cursor when we're done
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
 		err := cursor.Close(ctx)
 		if err != nil {
-			s.Logger.Error().Err(err).Msg("failed to close cursor")
+			logger.Error().Err(err).Msg("failed to close cursor")
 		}
 	}(cursor, ctx)
 
 	// Parse all the documents
 	var settingsList []models.Settings
 	if err = cursor.All(ctx, &settingsList); err != nil {
-		s.Logger.Error().Err(err).Msg("failed to decode settingsList")
-		return nil, err
+		logger.Error().Err(err).Msg("failed to decode settingsList")
+		return nil, mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 
 	return settingsList, nil
 }
 
 func (s settingsRepository) Update(ctx context.Context, settings *models.Settings) error {
+	defer metrics.ObserveMongo("SettingsRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
 	// Use the _id field from the settings model for the filter
 	// Create an update document with $set to update the settings fields
 	// Specify the options
-	filter := bson.D{{"_id", settings.ID}}
-	update := bson.D{{"$set", settings}}
+	filter := bson.D{{Key: "_id", Value: settings.ID}}
+	update := bson.D{{Key: "$set", Value: settings}}
 	opts := options.Update().SetUpsert(false)
 
 	// Execute the update operation
-	_, err := s.Collection.UpdateOne(ctx, filter, update, opts)
+	res, err := s.Collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to update settings with id: " + settings.ID.String())
-		return err
+		logger.Error().Err(err).Msg("failed to update settings with id: " + settings.ID.Hex())
+		return mapError(err, apperrors.CodeSettingsNotFound, "Settings")
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound(apperrors.CodeSettingsNotFound, "Settings not found")
 	}
 
 	return nil
 }
 
 func (s settingsRepository) Delete(ctx context.Context, id string) error {
+	defer metrics.ObserveMongo("SettingsRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.Logger)
 	// settings ID to search for
 	settingsID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to convert Settings, Delete id to object id")
-		s.Logger.Debug().Err(err).Msg("failed to convert Settings Delete id:" + id)
-		return err
+		logger.Error().Err(err).Msg("failed to convert Settings, Delete id to object id")
+		logger.Debug().Err(err).Msg("failed to convert Settings Delete id:" + id)
+		return mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 	_, err = s.Collection.DeleteOne(ctx, bson.M{"_id": settingsID})
 	if err != nil {
-		s.Logger.Error().Err(err).Msg("failed to delete settings with id: " + id)
-		return err
+		logger.Error().Err(err).Msg("failed to delete settings with id: " + id)
+		return mapError(err, apperrors.CodeSettingsNotFound, "Settings")
 	}
 	return nil
 }
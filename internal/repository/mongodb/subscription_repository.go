@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type subscriptionRepository struct {
+	iName      string
+	logger     *zerolog.Logger
+	Collection *mongo.Collection
+}
+
+func NewSubscriptionRepository(log *zerolog.Logger, db *mongo.Database) repository.ISubscriptionRepository {
+	return &subscriptionRepository{
+		iName:      "SubscriptionRepository",
+		logger:     log,
+		Collection: db.Collection("subscriptions"),
+	}
+}
+
+func (s subscriptionRepository) Create(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error) {
+	const kName = "Create"
+	defer metrics.ObserveMongo("SubscriptionRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "SubscriptionRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.logger)
+
+	created := *subscription
+	if created.Id.IsZero() {
+		created.Id = primitive.NewObjectID()
+	}
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if _, err := s.Collection.InsertOne(ctx, created); err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("failed to insert subscription")
+		return nil, mapError(err, apperrors.CodeSubscriptionNotFound, "Subscription")
+	}
+	return &created, nil
+}
+
+func (s subscriptionRepository) GetActiveByUserId(ctx context.Context, userId string, at time.Time) (*models.Subscription, error) {
+	const kName = "GetActiveByUserId"
+	defer metrics.ObserveMongo("SubscriptionRepository", "GetActiveByUserId")()
+	ctx, span := tracing.Start(ctx, "SubscriptionRepository", "GetActiveByUserId")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.logger)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("failed to convert user id to object id")
+		return nil, mapError(err, apperrors.CodeSubscriptionNotFound, "Subscription")
+	}
+	filter := activeAt(bson.M{"userId": userID}, at)
+	opts := options.FindOne().SetSort(bson.D{{Key: "startDate", Value: -1}})
+	subscription := &models.Subscription{}
+	if err = s.Collection.FindOne(ctx, filter, opts).Decode(subscription); err != nil {
+		// users without a subscription are on the free plan
+		return nil, mapError(err, apperrors.CodeSubscriptionNotFound, "Subscription")
+	}
+	return subscription, nil
+}
+
+func (s subscriptionRepository) ListEnterpriseMembers(ctx context.Context, enterpriseId string, at time.Time) ([]primitive.ObjectID, error) {
+	const kName = "ListEnterpriseMembers"
+	defer metrics.ObserveMongo("SubscriptionRepository", "ListEnterpriseMembers")()
+	ctx, span := tracing.Start(ctx, "SubscriptionRepository", "ListEnterpriseMembers")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.logger)
+
+	enterpriseID, err := primitive.ObjectIDFromHex(enterpriseId)
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("failed to convert enterprise id to object id")
+		return nil, mapError(err, apperrors.CodeSubscriptionNotFound, "Subscription")
+	}
+	filter := activeAt(bson.M{"enterpriseId": enterpriseID, "planType": models.PlanEnterprise}, at)
+	userIds, err := s.Collection.Distinct(ctx, "userId", filter)
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("failed to list members of enterprise: " + enterpriseId)
+		return nil, mapError(err, apperrors.CodeSubscriptionNotFound, "Subscription")
+	}
+	members := make([]primitive.ObjectID, 0, len(userIds))
+	for _, userId := range userIds {
+		if id, ok := userId.(primitive.ObjectID); ok {
+			members = append(members, id)
+		}
+	}
+	return members, nil
+}
+
+// activeAt narrows filter to the subscriptions active at, like models.Subscription.ActiveAt
+func activeAt(filter bson.M, at time.Time) bson.M {
+	filter["status"] = models.SubscriptionStatusActive
+	filter["startDate"] = bson.M{"$lte": at}
+	filter["$or"] = bson.A{
+		bson.M{"endDate": bson.M{"$exists": false}},
+		bson.M{"endDate": bson.M{"$gt": at}},
+	}
+	return filter
+}
@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sync"
+)
+
+type unitOfWork struct {
+	iName  string
+	client *mongo.Client
+	logger *zerolog.Logger
+
+	mu           sync.Mutex
+	probed       bool // whether transaction support is known
+	transactions bool
+}
+
+// NewUnitOfWork runs units of work in MongoDB transactions. Standalone servers, usually only found in development,
+// have no transactions: there the writes are reverted with the undo functions registered with repository.OnRollback.
+func NewUnitOfWork(log *zerolog.Logger, db *mongo.Database) repository.IUnitOfWork {
+	return &unitOfWork{
+		iName:  "UnitOfWork",
+		client: db.Client(),
+		logger: log,
+	}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	const kName = "Do"
+	ctx, span := tracing.Start(ctx, "UnitOfWork", "Do")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	if mongo.SessionFromContext(ctx) != nil {
+		// nested, the outer transaction commits
+		return fn(ctx)
+	}
+	if !u.supportsTransactions(ctx) {
+		return repository.RunWithRollbacks(ctx, fn)
+	}
+
+	session, err := u.client.StartSession()
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to start session")
+		return apperrors.Unavailable(apperrors.CodeUnavailable, "Database is unavailable").WithErr(err)
+	}
+	defer session.EndSession(ctx)
+
+	// WithTransaction retries fn on transient errors, fn must not rely on state from a previous attempt
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("transaction failed")
+		return err
+	}
+	return nil
+}
+
+// supportsTransactions reports whether the server is a replica set member or a mongos,
+// standalone servers reject transactions
+func (u *unitOfWork) supportsTransactions(ctx context.Context) bool {
+	const kName = "supportsTransactions"
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.probed {
+		return u.transactions
+	}
+	logger := logging.FromContext(ctx, u.logger)
+
+	var hello bson.M
+	err := u.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		// not cached, the next unit of work asks again
+		logger.Warn().Interface(kName, u.iName).Err(err).Msg("failed to check transaction support, running without a transaction")
+		return false
+	}
+	_, replicaSet := hello["setName"]
+	u.transactions = replicaSet || hello["msg"] == "isdbgrid"
+	u.probed = true
+	if !u.transactions {
+		logger.Warn().Interface(kName, u.iName).Msg("MongoDB is a standalone server, units of work run without transactions & are reverted with compensating writes")
+	}
+	return u.transactions
+}
@@ -0,0 +1,202 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type uploadRepository struct {
+	iName      string
+	logger     *zerolog.Logger
+	Collection *mongo.Collection
+}
+
+func NewUploadRepository(log *zerolog.Logger, db *mongo.Database) repository.IUploadRepository {
+	return &uploadRepository{
+		iName:      "UploadRepository",
+		logger:     log,
+		Collection: db.Collection("uploads"),
+	}
+}
+
+func (u uploadRepository) Create(ctx context.Context, upload *models.Upload) (*models.Upload, error) {
+	const kName = "Create"
+	defer metrics.ObserveMongo("UploadRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "UploadRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	created := *upload
+	created.ID = primitive.NewObjectID()
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if _, err := u.Collection.InsertOne(ctx, created); err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to insert upload")
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	return &created, nil
+}
+
+func (u uploadRepository) GetByID(ctx context.Context, id string) (*models.Upload, error) {
+	const kName = "GetByID"
+	defer metrics.ObserveMongo("UploadRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "UploadRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	uploadID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to convert upload id to object id")
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+
+	upload := &models.Upload{}
+	err = u.Collection.FindOne(ctx, bson.M{"_id": uploadID, "expiresAt": bson.M{"$gt": time.Now()}}).Decode(upload)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to find upload with id: " + id)
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	return upload, nil
+}
+
+func (u uploadRepository) AppendPart(ctx context.Context, id string, offset int64, part string, size int64, contentType string, expiresAt time.Time) (*models.Upload, error) {
+	const kName = "AppendPart"
+	defer metrics.ObserveMongo("UploadRepository", "AppendPart")()
+	ctx, span := tracing.Start(ctx, "UploadRepository", "AppendPart")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	uploadID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to convert upload id to object id")
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+
+	set := bson.M{"expiresAt": expiresAt, "updatedAt": time.Now()}
+	if contentType != "" {
+		set["contentType"] = contentType
+	}
+	// matching on the offset makes concurrent chunks for the same offset race on this update, only one is recorded
+	filter := bson.M{
+		"_id":       uploadID,
+		"status":    models.UploadStatusUploading,
+		"offset":    offset,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{
+		"$set":  set,
+		"$inc":  bson.M{"offset": size},
+		"$push": bson.M{"parts": part},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	updated := &models.Upload{}
+	err = u.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, u.conflict(ctx, id)
+	}
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to append part to upload: " + id)
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	return updated, nil
+}
+
+func (u uploadRepository) Complete(ctx context.Context, id string, expiresAt time.Time) (*models.Upload, error) {
+	const kName = "Complete"
+	defer metrics.ObserveMongo("UploadRepository", "Complete")()
+	ctx, span := tracing.Start(ctx, "UploadRepository", "Complete")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	uploadID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to convert upload id to object id")
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+
+	filter := bson.M{"_id": uploadID, "status": models.UploadStatusUploading, "expiresAt": bson.M{"$gt": time.Now()}}
+	update := bson.M{
+		"$set":   bson.M{"status": models.UploadStatusCompleted, "expiresAt": expiresAt, "updatedAt": time.Now()},
+		"$unset": bson.M{"parts": ""},
+	}
+
+	previous := &models.Upload{}
+	err = u.Collection.FindOneAndUpdate(ctx, filter, update).Decode(previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, u.conflict(ctx, id)
+	}
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to complete upload: " + id)
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	return previous, nil
+}
+
+func (u uploadRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Upload, error) {
+	const kName = "ListExpired"
+	defer metrics.ObserveMongo("UploadRepository", "ListExpired")()
+	ctx, span := tracing.Start(ctx, "UploadRepository", "ListExpired")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	opts := options.Find().SetSort(bson.D{{Key: "expiresAt", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := u.Collection.Find(ctx, bson.M{"expiresAt": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to find expired uploads")
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err := cursor.Close(ctx)
+		if err != nil {
+			logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to close cursor")
+		}
+	}(cursor, ctx)
+
+	var uploads []models.Upload
+	if err := cursor.All(ctx, &uploads); err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to decode expired uploads")
+		return nil, mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	return uploads, nil
+}
+
+func (u uploadRepository) Delete(ctx context.Context, id string) error {
+	const kName = "Delete"
+	defer metrics.ObserveMongo("UploadRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "UploadRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.logger)
+
+	uploadID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to convert upload id to object id")
+		return mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	if _, err = u.Collection.DeleteOne(ctx, bson.M{"_id": uploadID}); err != nil {
+		logger.Error().Interface(kName, u.iName).Err(err).Msg("failed to delete upload: " + id)
+		return mapError(err, apperrors.CodeUploadNotFound, "Upload")
+	}
+	return nil
+}
+
+// conflict explains why a conditional update of the upload matched nothing
+func (u uploadRepository) conflict(ctx context.Context, id string) error {
+	upload, err := u.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repository.UploadConflict(upload)
+}
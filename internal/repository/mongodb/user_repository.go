@@ -5,7 +5,11 @@ import (
 	"errors"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
 	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -32,142 +36,160 @@ func NewUserRepository(log *zerolog.Logger, db *mongo.Database, encryptSvc servi
 }
 
 func (u userRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	defer metrics.ObserveMongo("UserRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "Create")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	//Hash fields used in search
 	err := user.HashFields(u.SearchKeyHashService)
 	if err != nil {
-		u.Log.Error().Err(err).Msg("error hashing user fields")
-		return nil, err
+		logger.Error().Err(err).Msg("error hashing user fields")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	//Encrypt fields before saving
 	err = user.EncryptFields(u.EncryptionService)
 	if err != nil {
-		u.Log.Error().Interface("Create", u.iName).Err(err).Msg("error encrypting user")
-		return nil, err
+		logger.Error().Interface("Create", u.iName).Err(err).Msg("error encrypting user")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 
 	// Insert User
 	res, err := u.Collection.InsertOne(ctx, user)
 	if err != nil {
-		u.Log.Error().Interface("Create", u.iName).Err(err).Msg("Failed to create user")
-		return nil, err
+		logger.Error().Interface("Create", u.iName).Err(err).Msg("Failed to create user")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	user.ID = res.InsertedID.(primitive.ObjectID)
 
 	// Decrypt for use
 	err = user.DecryptFields(u.EncryptionService)
 	if err != nil {
-		u.Log.Error().Interface("Create", u.iName).Err(err).Msg("Failed to decrypt new user")
-		return nil, err
+		logger.Error().Interface("Create", u.iName).Err(err).Msg("Failed to decrypt new user")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	return user, nil
 }
 
 func (u userRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	defer metrics.ObserveMongo("UserRepository", "GetByID")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "GetByID")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	// user ID to search for
 	userID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		u.Log.Error().Interface("GetByID", u.iName).Err(err).Msg("Failed to convert id to object id")
-		u.Log.Debug().Interface("GetByID", u.iName).Err(err).Msg("Failed to convert id:" + id)
-		return nil, err
+		logger.Error().Interface("GetByID", u.iName).Err(err).Msg("Failed to convert id to object id")
+		logger.Debug().Interface("GetByID", u.iName).Err(err).Msg("Failed to convert id:" + id)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 
 	user := &models.User{}
 	err = u.Collection.FindOne(ctx, bson.M{"_id": userID}).Decode(user)
 	if err != nil {
-		u.Log.Error().Interface("GetByID", u.iName).Err(err).Msg("Failed to find user with id: " + id)
-		return nil, err
+		logger.Error().Interface("GetByID", u.iName).Err(err).Msg("Failed to find user with id: " + id)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = user.DecryptFields(u.EncryptionService)
 	if err != nil {
-		u.Log.Error().Err(err).Interface("GetByID", u.iName).Msg("Failed to decrypt user with id: " + id)
-		return nil, err
+		logger.Error().Err(err).Interface("GetByID", u.iName).Msg("Failed to decrypt user with id: " + id)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	return user, nil
 
 }
 
 func (u userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	defer metrics.ObserveMongo("UserRepository", "GetByEmail")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "GetByEmail")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	user := &models.User{}
 	// encrypt before search
 	hashedEmail, err := u.SearchKeyHashService.GenerateSearchKey(email)
 	if err != nil {
-		u.Log.Debug().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to hash user Email: " + email)
-		u.Log.Error().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to hash user Email")
-		return nil, err
+		logger.Debug().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to hash user Email: " + email)
+		logger.Error().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to hash user Email")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = u.Collection.FindOne(ctx, bson.M{"emailHash": hashedEmail}).Decode(user)
 	if err != nil {
-		u.Log.Debug().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to find user with email: " + email + " :::: " + hashedEmail)
-		u.Log.Error().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to find user with provided email")
-		return nil, err
+		logger.Debug().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to find user with email: " + email + " :::: " + hashedEmail)
+		logger.Error().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to find user with provided email")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = user.DecryptFields(u.EncryptionService)
 	if err != nil {
-		u.Log.Error().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to decrypt user with email: " + email)
-		return nil, err
+		logger.Error().Interface("GetByEmail", u.iName).Err(err).Msg("Failed to decrypt user with email: " + email)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	return user, nil
 }
 
 func (u userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	defer metrics.ObserveMongo("UserRepository", "GetByUsername")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "GetByUsername")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	user := &models.User{}
 	// encrypt before search
 	hashedUsername, err := u.SearchKeyHashService.GenerateSearchKey(username)
 	if err != nil {
-		u.Log.Debug().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to hash user with username: " + username + " :::: " + hashedUsername)
-		u.Log.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to hash username: ")
-		return nil, err
+		logger.Debug().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to hash user with username: " + username + " :::: " + hashedUsername)
+		logger.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to hash username: ")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = u.Collection.FindOne(ctx, bson.M{"usernameHash": hashedUsername}).Decode(user)
 	if err != nil {
-		u.Log.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to find user with username: " + username)
-		u.Log.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to find user with provided username")
-		return nil, err
+		logger.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to find user with username: " + username)
+		logger.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to find user with provided username")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = user.DecryptFields(u.EncryptionService)
 	if err != nil {
-		u.Log.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to decrypt user with username: " + username)
-		return nil, err
+		logger.Error().Interface("GetByUsername", u.iName).Err(err).Msg("Failed to decrypt user with username: " + username)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	return user, nil
 }
 
 func (u userRepository) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*models.User, error) {
+	defer metrics.ObserveMongo("UserRepository", "GetByPhoneNumber")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "GetByPhoneNumber")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	user := &models.User{}
 	// encrypt before search
 	hashedPhoneNumber, err := u.SearchKeyHashService.GenerateSearchKey(phoneNumber)
 	if err != nil {
-		u.Log.Error().Interface("GetByPhoneNumber", u.iName).Err(err).
+		logger.Error().Interface("GetByPhoneNumber", u.iName).Err(err).
 			Msg("Failed to encrypt user with phoneNumber: " + phoneNumber + " :::: " + hashedPhoneNumber)
-		return nil, err
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = u.Collection.FindOne(ctx, bson.M{"phoneNumberHash": hashedPhoneNumber}).Decode(user)
 	if err != nil {
-		u.Log.Error().Interface("GetByPhoneNumber", u.iName).Err(err).Msg("Failed to find user with phone number: " + phoneNumber)
-		return nil, err
+		logger.Error().Interface("GetByPhoneNumber", u.iName).Err(err).Msg("Failed to find user with phone number: " + phoneNumber)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	err = user.DecryptFields(u.EncryptionService)
 	if err != nil {
-		u.Log.Error().Interface("GetByPhoneNumber", u.iName).Err(err).Msg("Failed to decrypt user with phone number: " + phoneNumber)
-		return nil, err
+		logger.Error().Interface("GetByPhoneNumber", u.iName).Err(err).Msg("Failed to decrypt user with phone number: " + phoneNumber)
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	return user, nil
 }
 
 func (u userRepository) List(ctx context.Context, page, limit int) ([]models.User, error) {
-	// Calculate how many documents to skip
-	skip := (page - 1) * limit
-
-	// Create options with pagination parameters
-	findOptions := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit))
+	defer metrics.ObserveMongo("UserRepository", "List")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "List")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
+	findOptions := pageOptions(page, limit)
 
 	// Execute the find operation with options
 	cursor, err := u.Collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		u.Log.Error().Err(err).Msg("Failed to query users")
-		return nil, err
+		logger.Error().Err(err).Msg("Failed to query users")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 
 	// Don't forget to close the cursor when we're done
@@ -176,15 +198,15 @@ func (u userRepository) List(ctx context.Context, page, limit int) ([]models.Use
 	// Parse all the documents
 	var users []models.User
 	if err = cursor.All(ctx, &users); err != nil {
-		u.Log.Error().Err(err).Msg("Failed to decode users")
-		return nil, err
+		logger.Error().Err(err).Msg("Failed to decode users")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 
 	// decrypt user fields
 	for i := 0; i < len(users); i++ {
 		err = users[i].DecryptFields(u.EncryptionService)
 		if err != nil {
-			u.Log.Error().Err(err).Msg("Failed to decrypt user with username: " + users[i].Username)
+			logger.Error().Err(err).Msg("Failed to decrypt user with username: " + users[i].Username)
 		}
 
 	}
@@ -193,6 +215,10 @@ func (u userRepository) List(ctx context.Context, page, limit int) ([]models.Use
 }
 
 func (u userRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	defer metrics.ObserveMongo("UserRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "Update")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	//// Use the _id field from the user model for the filter
 	//// Create an update document with $set to update the user fields
 	//// Specify the options
@@ -209,45 +235,67 @@ func (u userRepository) Update(ctx context.Context, user *models.User) (*models.
 	//
 	//return nil
 
+	// Hash & encrypt a copy, the caller keeps working with the plaintext user
+	doc := *user
+	err := doc.HashFields(u.SearchKeyHashService)
+	if err != nil {
+		logger.Error().Interface("Update", u.iName).Err(err).Msg("error hashing user fields")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
+	}
+	err = doc.EncryptFields(u.EncryptionService)
+	if err != nil {
+		logger.Error().Interface("Update", u.iName).Err(err).Msg("error encrypting user")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
+	}
+
 	// Create a filter using the _id field
-	filter := bson.D{{"_id", user.ID}}
+	filter := bson.D{{Key: "_id", Value: user.ID}}
 
 	// Create an update document, excluding the _id field
-	update := bson.D{{"$set", user}}
+	update := bson.D{{Key: "$set", Value: doc}}
 
 	// Options to return the updated document
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(true)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
 	// Create a variable to store the updated user
 	var updatedUser models.User
 
 	// Execute the update and retrieve the updated document
-	err := u.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedUser)
+	err = u.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedUser)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			u.Log.Warn().Msg("No document found to update with id: " + user.ID.String())
-			return nil, err
+			logger.Warn().Msg("No document found to update with id: " + user.ID.String())
+			return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 		}
-		u.Log.Error().Err(err).Msg("Failed to update user with id: " + user.ID.String())
-		return nil, err
+		logger.Error().Err(err).Msg("Failed to update user with id: " + user.ID.String())
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
+	}
+	err = updatedUser.DecryptFields(u.EncryptionService)
+	if err != nil {
+		logger.Error().Interface("Update", u.iName).Err(err).Msg("Failed to decrypt updated user")
+		return nil, mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 
 	return &updatedUser, nil
 }
 
 func (u userRepository) Delete(ctx context.Context, id string) error {
+	defer metrics.ObserveMongo("UserRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "UserRepository", "Delete")
+	defer span.End()
+	logger := logging.FromContext(ctx, u.Log)
 	// user ID to search for
 	userID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		u.Log.Error().Err(err).Msg("Failed to convert id to object id")
-		u.Log.Debug().Err(err).Msg("Failed to convert id:" + id)
-		return err
+		logger.Error().Err(err).Msg("Failed to convert id to object id")
+		logger.Debug().Err(err).Msg("Failed to convert id:" + id)
+		return mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 
 	_, err = u.Collection.DeleteOne(ctx, bson.M{"_id": userID})
 	if err != nil {
-		u.Log.Error().Err(err).Msg("Failed to delete user with id: " + id)
-		return err
+		logger.Error().Err(err).Msg("Failed to delete user with id: " + id)
+		return mapError(err, apperrors.CodeUserNotFound, "User")
 	}
 	return nil
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+)
+
+type IPlayedReceiptRepository interface {
+	// MarkPlayed records that the user of receipt played the voice note, replaying it keeps the receipt of the first
+	// play, which is returned
+	MarkPlayed(ctx context.Context, receipt *models.PlayedReceipt) (*models.PlayedReceipt, error)
+	// ListByMessageId lists the receipts of a voice note, first played first
+	ListByMessageId(ctx context.Context, messageId string) ([]models.PlayedReceipt, error)
+	// DeleteByMessageId deletes the receipts of a voice note
+	DeleteByMessageId(ctx context.Context, messageId string) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// IRateLimitRepository counts calls in fixed windows, the count of a key is shared by every server using the storage
+type IRateLimitRepository interface {
+	// Hit counts a call under key in the window of the given length starting at windowStart & returns the calls
+	// counted in it so far, the window is forgotten once it ended
+	Hit(ctx context.Context, key string, windowStart time.Time, window time.Duration) (int64, error)
+}
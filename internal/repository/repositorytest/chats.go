@@ -0,0 +1,379 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func testChats(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create, update and delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		created, err := repos.Chats.Create(ctx, &models.Chat{Type: models.ChatTypeGroup, Name: "team", CreatedAt: time.Now()})
+		requireNoError(t, err)
+		if created.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+
+		created.Name = "renamed"
+		requireNoError(t, repos.Chats.Update(ctx, created))
+		found, err := repos.Chats.GetByID(ctx, created.ID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "name", "renamed", found.Name)
+		requireEqual(t, "type", models.ChatTypeGroup, found.Type)
+
+		missing := &models.Chat{ID: primitive.NewObjectID(), Type: models.ChatTypeDirect}
+		requireError(t, repos.Chats.Update(ctx, missing), apperrors.ErrNotFound, apperrors.CodeChatNotFound)
+		_, err = repos.Chats.GetByID(ctx, missing.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeChatNotFound)
+
+		requireNoError(t, repos.Chats.Delete(ctx, created.ID.Hex()))
+		_, err = repos.Chats.GetByID(ctx, created.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeChatNotFound)
+		_, err = repos.Chats.GetByID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+
+	t.Run("list by participant", func(t *testing.T) {
+		repos := newRepositories(t)
+		alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+		var aliceChats []primitive.ObjectID
+		for i, participants := range [][]primitive.ObjectID{{alice, bob}, {bob}, {alice}, {alice, bob}} {
+			created, err := repos.Chats.Create(ctx, &models.Chat{Type: models.ChatTypeGroup, MemberCount: int64(i), Participants: participants})
+			requireNoError(t, err)
+			if participants[0] == alice {
+				aliceChats = append(aliceChats, created.ID)
+			}
+		}
+
+		chats, err := repos.Chats.ListByUserId(ctx, alice.Hex(), 1, 0)
+		requireNoError(t, err)
+		requireEqual(t, "alice's chats", len(aliceChats), len(chats))
+		for i, chat := range chats {
+			requireEqual(t, "order", aliceChats[i], chat.ID)
+		}
+
+		page, err := repos.Chats.ListByUserId(ctx, alice.Hex(), 2, 2)
+		requireNoError(t, err)
+		requireEqual(t, "second page", 1, len(page))
+		requireEqual(t, "second page id", aliceChats[2], page[0].ID)
+
+		none, err := repos.Chats.ListByUserId(ctx, missingID, 1, 10)
+		requireNoError(t, err)
+		requireEqual(t, "chats of a stranger", 0, len(none))
+
+		all, err := repos.Chats.List(ctx, 1, 3)
+		requireNoError(t, err)
+		requireEqual(t, "first page of every chat", 3, len(all))
+	})
+}
+
+func testChatKeys(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("get or create keeps the first key", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID := primitive.NewObjectID().Hex()
+		_, err := repos.ChatKeys.GetByChatID(ctx, chatID)
+		if !errors.Is(err, repository.ErrChatKeyNotFound) {
+			t.Fatalf("expected ErrChatKeyNotFound, got %v", err)
+		}
+
+		first, err := repos.ChatKeys.GetOrCreateByChatID(ctx, chatID)
+		requireNoError(t, err)
+		if first.Key == "" {
+			t.Fatal("GetOrCreateByChatID returned an empty key")
+		}
+		second, err := repos.ChatKeys.GetOrCreateByChatID(ctx, chatID)
+		requireNoError(t, err)
+		requireEqual(t, "key", first.Key, second.Key)
+		found, err := repos.ChatKeys.GetByChatID(ctx, chatID)
+		requireNoError(t, err)
+		requireEqual(t, "unwrapped key", first.Key, found.Key)
+
+		other, err := repos.ChatKeys.GetOrCreateByChatID(ctx, primitive.NewObjectID().Hex())
+		requireNoError(t, err)
+		if other.Key == first.Key {
+			t.Fatal("two chats share a data key")
+		}
+	})
+
+	t.Run("delete shreds the key", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID := primitive.NewObjectID().Hex()
+		_, err := repos.ChatKeys.GetOrCreateByChatID(ctx, chatID)
+		requireNoError(t, err)
+		requireNoError(t, repos.ChatKeys.DeleteByChatID(ctx, chatID))
+		requireNoError(t, repos.ChatKeys.DeleteByChatID(ctx, chatID))
+		_, err = repos.ChatKeys.GetByChatID(ctx, chatID)
+		if !errors.Is(err, repository.ErrChatKeyNotFound) {
+			t.Fatalf("expected ErrChatKeyNotFound, got %v", err)
+		}
+		_, err = repos.ChatKeys.GetByChatID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
+
+func newMessage(chatID, senderID primitive.ObjectID, content string) *models.Message {
+	return &models.Message{
+		ChatID:      chatID,
+		SenderID:    senderID,
+		MessageType: models.MessageTypeText,
+		Content:     content,
+		MediaUrls:   []string{"https://media.telko.test/" + content},
+		Timestamp:   primitive.NewDateTimeFromTime(time.Now()),
+		Status:      "sent",
+	}
+}
+
+func testMessages(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("encrypted at rest", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID, senderID := primitive.NewObjectID(), primitive.NewObjectID()
+		created, err := repos.Messages.Create(ctx, newMessage(chatID, senderID, "hello"))
+		requireNoError(t, err)
+		if created.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+		requireEqual(t, "content", "hello", created.Content)
+		requireEqual(t, "encrypted", false, created.Encrypted)
+
+		for name, lookup := range map[string]func() (*models.Message, error){
+			"GetByID":       func() (*models.Message, error) { return repos.Messages.GetByID(ctx, created.ID.Hex()) },
+			"GetByChatID":   func() (*models.Message, error) { return repos.Messages.GetByChatID(ctx, chatID.Hex()) },
+			"GetBySenderID": func() (*models.Message, error) { return repos.Messages.GetBySenderID(ctx, senderID.Hex()) },
+		} {
+			found, err := lookup()
+			requireNoError(t, err)
+			requireEqual(t, name+" id", created.ID, found.ID)
+			requireEqual(t, name+" content", "hello", found.Content)
+			requireEqual(t, name+" media url", "https://media.telko.test/hello", found.MediaUrls[0])
+		}
+
+		// the chat got a data key on its first message
+		_, err = repos.ChatKeys.GetByChatID(ctx, chatID.Hex())
+		requireNoError(t, err)
+
+		_, err = repos.Messages.Create(ctx, newMessage(primitive.NilObjectID, senderID, "lost"))
+		requireError(t, err, apperrors.ErrValidation, "")
+	})
+
+	t.Run("first message of a chat", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID := primitive.NewObjectID()
+		first, err := repos.Messages.Create(ctx, newMessage(chatID, primitive.NewObjectID(), "first"))
+		requireNoError(t, err)
+		_, err = repos.Messages.Create(ctx, newMessage(chatID, primitive.NewObjectID(), "second"))
+		requireNoError(t, err)
+
+		found, err := repos.Messages.GetByChatID(ctx, chatID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "id", first.ID, found.ID)
+		_, err = repos.Messages.GetByChatID(ctx, missingID)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeMessageNotFound)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repos := newRepositories(t)
+		created, err := repos.Messages.Create(ctx, newMessage(primitive.NewObjectID(), primitive.NewObjectID(), "draft"))
+		requireNoError(t, err)
+
+		created.Content = "edited"
+		created.EditedMessage = true
+		requireNoError(t, repos.Messages.Update(ctx, created))
+		requireEqual(t, "caller's content", "edited", created.Content)
+		found, err := repos.Messages.GetByID(ctx, created.ID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "content", "edited", found.Content)
+		requireEqual(t, "edited", true, found.EditedMessage)
+
+		missing := newMessage(created.ChatID, created.SenderID, "ghost")
+		missing.ID = primitive.NewObjectID()
+		requireError(t, repos.Messages.Update(ctx, missing), apperrors.ErrNotFound, apperrors.CodeMessageNotFound)
+	})
+
+	t.Run("voice notes", func(t *testing.T) {
+		repos := newRepositories(t)
+		message := newMessage(primitive.NewObjectID(), primitive.NewObjectID(), "")
+		message.MessageType = models.MessageTypeVoiceNote
+		message.MediaUrls = nil
+		message.MediaIDs = []primitive.ObjectID{primitive.NewObjectID()}
+		message.VoiceNote = &models.VoiceNote{DurationMs: 4200, Waveform: []int{10, 100, 40}}
+		created, err := repos.Messages.Create(ctx, message)
+		requireNoError(t, err)
+
+		found, err := repos.Messages.GetByID(ctx, created.ID.Hex())
+		requireNoError(t, err)
+		if found.VoiceNote == nil {
+			t.Fatal("the voice note was not stored")
+		}
+		requireEqual(t, "duration", int64(4200), found.VoiceNote.DurationMs)
+		requireEqual(t, "waveform", 3, len(found.VoiceNote.Waveform))
+		requireEqual(t, "peak", 100, found.VoiceNote.Waveform[1])
+	})
+
+	t.Run("shredded chats", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID := primitive.NewObjectID()
+		created, err := repos.Messages.Create(ctx, newMessage(chatID, primitive.NewObjectID(), "secret"))
+		requireNoError(t, err)
+		kept, err := repos.Messages.Create(ctx, newMessage(primitive.NewObjectID(), primitive.NewObjectID(), "kept"))
+		requireNoError(t, err)
+
+		requireNoError(t, repos.ChatKeys.DeleteByChatID(ctx, chatID.Hex()))
+		// reads blank the shredded message like listing does
+		shredded, err := repos.Messages.GetByID(ctx, created.ID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "read shredded is encrypted", true, shredded.Encrypted)
+		requireEqual(t, "read shredded content", "", shredded.Content)
+		shredded, err = repos.Messages.GetByChatID(ctx, chatID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "first of shredded chat content", "", shredded.Content)
+
+		// listing blanks the shredded message & decrypts the others
+		messages, err := repos.Messages.List(ctx, 1, 0)
+		requireNoError(t, err)
+		requireEqual(t, "messages", 2, len(messages))
+		requireEqual(t, "shredded id", created.ID, messages[0].ID)
+		requireEqual(t, "shredded is encrypted", true, messages[0].Encrypted)
+		requireEqual(t, "shredded content", "", messages[0].Content)
+		requireEqual(t, "shredded media urls", 0, len(messages[0].MediaUrls))
+		requireEqual(t, "kept id", kept.ID, messages[1].ID)
+		requireEqual(t, "kept content", "kept", messages[1].Content)
+
+		requireNoError(t, repos.Messages.Delete(ctx, created.ID.Hex()))
+		_, err = repos.Messages.GetByID(ctx, created.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeMessageNotFound)
+	})
+
+	t.Run("messages with links", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID := primitive.NewObjectID()
+		var ids []primitive.ObjectID
+		for i, content := range []string{"see https://a.test", "no link", "and https://b.test"} {
+			message := newMessage(chatID, primitive.NewObjectID(), content)
+			message.HasLinks = i != 1
+			created, err := repos.Messages.Create(ctx, message)
+			requireNoError(t, err)
+			ids = append(ids, created.ID)
+		}
+		other := newMessage(primitive.NewObjectID(), primitive.NewObjectID(), "https://c.test")
+		other.HasLinks = true
+		_, err := repos.Messages.Create(ctx, other)
+		requireNoError(t, err)
+
+		page, err := repos.Messages.ListWithLinks(ctx, chatID.Hex(), "", 1)
+		requireNoError(t, err)
+		requireEqual(t, "first page", 1, len(page))
+		requireEqual(t, "newest", ids[2], page[0].ID)
+		requireEqual(t, "decrypted", "and https://b.test", page[0].Content)
+		page, err = repos.Messages.ListWithLinks(ctx, chatID.Hex(), page[0].ID.Hex(), 0)
+		requireNoError(t, err)
+		requireEqual(t, "rest", 1, len(page))
+		requireEqual(t, "oldest", ids[0], page[0].ID)
+		_, err = repos.Messages.ListWithLinks(ctx, chatID.Hex(), malformedID, 1)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
+
+func testPlayedReceipts(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("the first play is kept per user", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID, messageID, otherID := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+		start := time.Now().Truncate(time.Millisecond)
+
+		first, err := repos.PlayedReceipts.MarkPlayed(ctx, &models.PlayedReceipt{MessageID: messageID, ChatID: chatID, UserID: bob, PlayedAt: start})
+		requireNoError(t, err)
+		if first.ID.IsZero() {
+			t.Fatal("MarkPlayed did not assign an id")
+		}
+		replayed, err := repos.PlayedReceipts.MarkPlayed(ctx, &models.PlayedReceipt{MessageID: messageID, ChatID: chatID, UserID: bob, PlayedAt: start.Add(time.Hour)})
+		requireNoError(t, err)
+		requireEqual(t, "replayed id", first.ID, replayed.ID)
+		requireEqual(t, "replayed at", true, replayed.PlayedAt.Equal(start))
+		_, err = repos.PlayedReceipts.MarkPlayed(ctx, &models.PlayedReceipt{MessageID: messageID, ChatID: chatID, UserID: alice, PlayedAt: start.Add(-time.Minute)})
+		requireNoError(t, err)
+		_, err = repos.PlayedReceipts.MarkPlayed(ctx, &models.PlayedReceipt{MessageID: otherID, ChatID: chatID, UserID: alice, PlayedAt: start})
+		requireNoError(t, err)
+
+		receipts, err := repos.PlayedReceipts.ListByMessageId(ctx, messageID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "receipts", 2, len(receipts))
+		requireEqual(t, "first played", alice, receipts[0].UserID)
+		requireEqual(t, "then played", bob, receipts[1].UserID)
+		requireEqual(t, "chat", chatID, receipts[1].ChatID)
+
+		requireNoError(t, repos.PlayedReceipts.DeleteByMessageId(ctx, messageID.Hex()))
+		receipts, err = repos.PlayedReceipts.ListByMessageId(ctx, messageID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "receipts after delete", 0, len(receipts))
+		receipts, err = repos.PlayedReceipts.ListByMessageId(ctx, otherID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "receipts of another message", 1, len(receipts))
+
+		_, err = repos.PlayedReceipts.ListByMessageId(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
+
+func testChatGroups(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create, update and delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		creator := primitive.NewObjectID()
+		chatGroup := &models.ChatGroup{
+			Name:      "book club",
+			Type:      models.ChatTypeGroup,
+			ChatID:    primitive.NewObjectID(),
+			CreatorID: creator,
+			Members:   []primitive.ObjectID{creator},
+			AdminIDs:  []primitive.ObjectID{creator},
+			CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+		}
+		requireNoError(t, repos.ChatGroups.Create(ctx, chatGroup))
+		if chatGroup.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+
+		chatGroup.Members = append(chatGroup.Members, primitive.NewObjectID())
+		requireNoError(t, repos.ChatGroups.Update(ctx, chatGroup))
+		found, err := repos.ChatGroups.GetByID(ctx, chatGroup.ID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "members", 2, len(found.Members))
+		requireEqual(t, "creator", creator, found.CreatorID)
+
+		requireNoError(t, repos.ChatGroups.UpdateWithFilter(ctx, chatGroup.ID.Hex(), map[string]interface{}{"description": "monthly reads"}))
+		found, err = repos.ChatGroups.GetByID(ctx, chatGroup.ID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "description", "monthly reads", found.Description)
+		requireEqual(t, "name", "book club", found.Name)
+
+		requireError(t, repos.ChatGroups.UpdateWithFilter(ctx, missingID, map[string]interface{}{"name": "ghost"}),
+			apperrors.ErrNotFound, apperrors.CodeChatGroupNotFound)
+		missing := *chatGroup
+		missing.ID = primitive.NewObjectID()
+		requireError(t, repos.ChatGroups.Update(ctx, &missing), apperrors.ErrNotFound, apperrors.CodeChatGroupNotFound)
+
+		groups, err := repos.ChatGroups.List(ctx, 1, 10)
+		requireNoError(t, err)
+		requireEqual(t, "groups", 1, len(groups))
+
+		requireNoError(t, repos.ChatGroups.Delete(ctx, chatGroup.ID.Hex()))
+		_, err = repos.ChatGroups.GetByID(ctx, chatGroup.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeChatGroupNotFound)
+		requireError(t, repos.ChatGroups.Delete(ctx, malformedID), apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
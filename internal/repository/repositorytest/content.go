@@ -0,0 +1,444 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func testHighlights(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create, list by user, update and delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID()
+		var ids []primitive.ObjectID
+		for i, owner := range []primitive.ObjectID{userID, primitive.NewObjectID(), userID} {
+			highlight := &models.Highlight{
+				UserId:    owner,
+				Quote:     "quote",
+				Item:      string(rune('a' + i)),
+				Timestamp: primitive.NewDateTimeFromTime(time.Now()),
+			}
+			requireNoError(t, repos.Highlights.Create(ctx, highlight))
+			if highlight.Id.IsZero() {
+				t.Fatal("Create did not assign an id")
+			}
+			if owner == userID {
+				ids = append(ids, highlight.Id)
+			}
+		}
+
+		highlights, err := repos.Highlights.GetByUserId(ctx, userID.Hex(), 1, 0)
+		requireNoError(t, err)
+		requireEqual(t, "user's highlights", 2, len(highlights))
+		requireEqual(t, "order", ids[0], highlights[0].Id)
+		requireEqual(t, "item", "c", highlights[1].Item)
+
+		highlights[0].Quote = "changed"
+		requireNoError(t, repos.Highlights.Update(ctx, &highlights[0]))
+		found, err := repos.Highlights.GetByID(ctx, ids[0].Hex())
+		requireNoError(t, err)
+		requireEqual(t, "quote", "changed", found.Quote)
+		missing := highlights[1]
+		missing.Id = primitive.NewObjectID()
+		requireError(t, repos.Highlights.Update(ctx, &missing), apperrors.ErrNotFound, apperrors.CodeHighlightNotFound)
+
+		all, err := repos.Highlights.List(ctx, 2, 2)
+		requireNoError(t, err)
+		requireEqual(t, "second page", 1, len(all))
+
+		requireNoError(t, repos.Highlights.Delete(ctx, ids[0].Hex()))
+		_, err = repos.Highlights.GetByID(ctx, ids[0].Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeHighlightNotFound)
+		_, err = repos.Highlights.GetByUserId(ctx, malformedID, 1, 10)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
+
+func testMedia(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create, list by chat and sender, update and delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID, senderID := primitive.NewObjectID(), primitive.NewObjectID()
+		var ids []primitive.ObjectID
+		for i := 0; i < 3; i++ {
+			media := &models.Media{
+				ChatId:          chatID,
+				SenderId:        senderID,
+				MediaType:       "image",
+				FileName:        "photo.jpg",
+				FileSize:        1024 * (i + 1),
+				MediaUrl:        "https://media.telko.test/photo.jpg",
+				UploadTimestamp: primitive.NewDateTimeFromTime(time.Now()),
+			}
+			if i == 2 {
+				media.ChatId = primitive.NewObjectID()
+			}
+			requireNoError(t, repos.Media.Create(ctx, media))
+			if media.Id.IsZero() {
+				t.Fatal("Create did not assign an id")
+			}
+			ids = append(ids, media.Id)
+		}
+
+		byChat, err := repos.Media.GetByChatId(ctx, chatID.Hex(), 1, 0)
+		requireNoError(t, err)
+		requireEqual(t, "chat's media", 2, len(byChat))
+		requireEqual(t, "order", ids[0], byChat[0].Id)
+		bySender, err := repos.Media.GetBySenderId(ctx, senderID.Hex(), 2, 2)
+		requireNoError(t, err)
+		requireEqual(t, "sender's second page", 1, len(bySender))
+		requireEqual(t, "sender's second page id", ids[2], bySender[0].Id)
+
+		byChat[1].FileName = "renamed.jpg"
+		requireNoError(t, repos.Media.Update(ctx, &byChat[1]))
+		found, err := repos.Media.GetByID(ctx, ids[1].Hex())
+		requireNoError(t, err)
+		requireEqual(t, "file name", "renamed.jpg", found.FileName)
+		requireEqual(t, "file size", 2048, found.FileSize)
+		missing := *found
+		missing.Id = primitive.NewObjectID()
+		requireError(t, repos.Media.Update(ctx, &missing), apperrors.ErrNotFound, apperrors.CodeMediaNotFound)
+
+		all, err := repos.Media.List(ctx, 1, 0)
+		requireNoError(t, err)
+		requireEqual(t, "media", 3, len(all))
+
+		requireNoError(t, repos.Media.Delete(ctx, ids[1].Hex()))
+		_, err = repos.Media.GetByID(ctx, ids[1].Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeMediaNotFound)
+		_, err = repos.Media.GetByID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+
+	t.Run("gallery of the media messages attached", func(t *testing.T) {
+		repos := newRepositories(t)
+		chatID, messageID, forwardID := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		var ids []primitive.ObjectID
+		for _, mediaType := range []string{"image", "image", "video", "image", "image"} {
+			media := &models.Media{ChatId: chatID, SenderId: primitive.NewObjectID(), MediaType: mediaType, FileName: "shared"}
+			requireNoError(t, repos.Media.Create(ctx, media))
+			ids = append(ids, media.Id)
+		}
+
+		// the last image was never sent
+		requireNoError(t, repos.Media.AttachToMessage(ctx, messageID.Hex(), ids[:4]))
+		requireNoError(t, repos.Media.AttachToMessage(ctx, forwardID.Hex(), []primitive.ObjectID{ids[0], primitive.NewObjectID()}))
+		found, err := repos.Media.GetByID(ctx, ids[0].Hex())
+		requireNoError(t, err)
+		requireEqual(t, "first message", messageID, found.MessageId)
+
+		page, err := repos.Media.ListGallery(ctx, chatID.Hex(), "image", "", 2)
+		requireNoError(t, err)
+		requireEqual(t, "first page", 2, len(page))
+		requireEqual(t, "newest", ids[3], page[0].Id)
+		requireEqual(t, "next", ids[1], page[1].Id)
+		page, err = repos.Media.ListGallery(ctx, chatID.Hex(), "image", page[1].Id.Hex(), 2)
+		requireNoError(t, err)
+		requireEqual(t, "last page", 1, len(page))
+		requireEqual(t, "oldest", ids[0], page[0].Id)
+		videos, err := repos.Media.ListGallery(ctx, chatID.Hex(), "video", "", 0)
+		requireNoError(t, err)
+		requireEqual(t, "videos", 1, len(videos))
+		_, err = repos.Media.ListGallery(ctx, chatID.Hex(), "image", malformedID, 2)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+
+		requireNoError(t, repos.Media.DetachFromMessage(ctx, messageID.Hex()))
+		page, err = repos.Media.ListGallery(ctx, chatID.Hex(), "image", "", 0)
+		requireNoError(t, err)
+		requireEqual(t, "detached", 0, len(page))
+	})
+
+	t.Run("storage usage per chat & media type", func(t *testing.T) {
+		repos := newRepositories(t)
+		sender, other, chatA, chatB := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		for _, media := range []*models.Media{
+			{ChatId: chatA, SenderId: sender, MediaType: "image", FileSize: 100},
+			{ChatId: chatA, SenderId: sender, MediaType: "image", FileSize: 250},
+			{ChatId: chatA, SenderId: sender, MediaType: "video", FileSize: 1000},
+			{ChatId: chatB, SenderId: other, MediaType: "image", FileSize: 40},
+			{ChatId: chatB, SenderId: primitive.NewObjectID(), MediaType: "image", FileSize: 7},
+		} {
+			requireNoError(t, repos.Media.Create(ctx, media))
+		}
+
+		usage, err := repos.Media.StorageUsage(ctx, []primitive.ObjectID{sender}, primitive.NilObjectID)
+		requireNoError(t, err)
+		requireEqual(t, "groups", 2, len(usage))
+		bytes := map[string]int64{}
+		for _, u := range usage {
+			requireEqual(t, "chat", chatA, u.ChatId)
+			bytes[u.MediaType] += u.Bytes
+			if u.MediaType == "image" {
+				requireEqual(t, "images", 2, u.Count)
+			}
+		}
+		requireEqual(t, "image bytes", int64(350), bytes["image"])
+		requireEqual(t, "video bytes", int64(1000), bytes["video"])
+
+		usage, err = repos.Media.StorageUsage(ctx, []primitive.ObjectID{sender, other}, primitive.NilObjectID)
+		requireNoError(t, err)
+		requireEqual(t, "groups of both senders", 3, len(usage))
+		usage, err = repos.Media.StorageUsage(ctx, nil, primitive.NilObjectID)
+		requireNoError(t, err)
+		requireEqual(t, "no senders", 0, len(usage))
+	})
+
+	t.Run("storage usage counts deduplicated blobs once", func(t *testing.T) {
+		repos := newRepositories(t)
+		sender, chatA, chatB, blob := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		var recorded []primitive.ObjectID
+		for _, media := range []*models.Media{
+			{ChatId: chatA, SenderId: sender, MediaType: "image", FileSize: 100, BlobID: blob},
+			{ChatId: chatB, SenderId: sender, MediaType: "image", FileSize: 100, BlobID: blob},
+			{ChatId: chatB, SenderId: sender, MediaType: "image", FileSize: 30},
+		} {
+			requireNoError(t, repos.Media.Create(ctx, media))
+			recorded = append(recorded, media.Id)
+		}
+		sum := func(until primitive.ObjectID) (bytes map[primitive.ObjectID]int64, count int) {
+			t.Helper()
+			usage, err := repos.Media.StorageUsage(ctx, []primitive.ObjectID{sender}, until)
+			requireNoError(t, err)
+			bytes = map[primitive.ObjectID]int64{}
+			for _, u := range usage {
+				bytes[u.ChatId] += u.Bytes
+				count += u.Count
+			}
+			return bytes, count
+		}
+
+		// the blob counts in the chat it was first uploaded to, the re-upload only adds a media
+		bytes, count := sum(primitive.NilObjectID)
+		requireEqual(t, "chat of the first upload", int64(100), bytes[chatA])
+		requireEqual(t, "chat of the re-upload", int64(30), bytes[chatB])
+		requireEqual(t, "media", 3, count)
+
+		// media recorded after until are left out
+		bytes, count = sum(recorded[1])
+		requireEqual(t, "re-upload until itself", int64(0), bytes[chatB])
+		requireEqual(t, "media until the re-upload", 2, count)
+	})
+
+	t.Run("media quarantined until scanned", func(t *testing.T) {
+		repos := newRepositories(t)
+		var ids []primitive.ObjectID
+		for _, status := range []string{models.ScanStatusPending, "", models.ScanStatusPending, models.ScanStatusPending} {
+			media := &models.Media{ChatId: primitive.NewObjectID(), SenderId: primitive.NewObjectID(), MediaType: "document", FileName: "report.pdf"}
+			media.ScanStatus = status
+			requireNoError(t, repos.Media.Create(ctx, media))
+			ids = append(ids, media.Id)
+		}
+
+		pending, err := repos.Media.ListByScanStatus(ctx, models.ScanStatusPending, 2)
+		requireNoError(t, err)
+		requireEqual(t, "pending page", 2, len(pending))
+		requireEqual(t, "oldest first", ids[0], pending[0].Id)
+		requireEqual(t, "next", ids[2], pending[1].Id)
+
+		requireNoError(t, repos.Media.SetScanStatus(ctx, ids[0].Hex(), models.ScanStatusInfected, "Eicar-Test-Signature"))
+		found, err := repos.Media.GetByID(ctx, ids[0].Hex())
+		requireNoError(t, err)
+		requireEqual(t, "status", models.ScanStatusInfected, found.ScanStatus)
+		requireEqual(t, "signature", "Eicar-Test-Signature", found.ScanSignature)
+		requireEqual(t, "file name", "report.pdf", found.FileName)
+		pending, err = repos.Media.ListByScanStatus(ctx, models.ScanStatusPending, 0)
+		requireNoError(t, err)
+		requireEqual(t, "still pending", 2, len(pending))
+
+		err = repos.Media.SetScanStatus(ctx, primitive.NewObjectID().Hex(), models.ScanStatusClean, "")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeMediaNotFound)
+		err = repos.Media.SetScanStatus(ctx, malformedID, models.ScanStatusClean, "")
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
+
+func testUploads(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	newUpload := func(t *testing.T, repos Repositories, expiresAt time.Time) *models.Upload {
+		t.Helper()
+		upload, err := repos.Uploads.Create(ctx, &models.Upload{
+			ChatID:    primitive.NewObjectID(),
+			SenderID:  primitive.NewObjectID(),
+			MediaID:   primitive.NewObjectID(),
+			MediaType: "video",
+			FileName:  "holiday.mp4",
+			Length:    300,
+			Status:    models.UploadStatusUploading,
+			ExpiresAt: expiresAt,
+		})
+		requireNoError(t, err)
+		if upload.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+		return upload
+	}
+
+	t.Run("chunks are appended at the current offset", func(t *testing.T) {
+		repos := newRepositories(t)
+		upload := newUpload(t, repos, time.Now().Add(time.Hour))
+		id := upload.ID.Hex()
+
+		updated, err := repos.Uploads.AppendPart(ctx, id, 0, "part-1", 100, "video/mp4", time.Now().Add(2*time.Hour))
+		requireNoError(t, err)
+		requireEqual(t, "offset", int64(100), updated.Offset)
+		requireEqual(t, "content type", "video/mp4", updated.ContentType)
+
+		// a chunk for an offset another chunk already filled loses
+		_, err = repos.Uploads.AppendPart(ctx, id, 0, "part-1-again", 100, "", time.Now().Add(2*time.Hour))
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeUploadOffsetMismatch)
+
+		_, err = repos.Uploads.AppendPart(ctx, id, 100, "part-2", 200, "", time.Now().Add(2*time.Hour))
+		requireNoError(t, err)
+		found, err := repos.Uploads.GetByID(ctx, id)
+		requireNoError(t, err)
+		requireEqual(t, "offset", int64(300), found.Offset)
+		requireEqual(t, "parts", 2, len(found.Parts))
+		requireEqual(t, "last part", "part-2", found.Parts[1])
+		requireEqual(t, "content type", "video/mp4", found.ContentType)
+
+		previous, err := repos.Uploads.Complete(ctx, id, time.Now().Add(time.Hour))
+		requireNoError(t, err)
+		requireEqual(t, "completed parts", 2, len(previous.Parts))
+		found, err = repos.Uploads.GetByID(ctx, id)
+		requireNoError(t, err)
+		requireEqual(t, "status", models.UploadStatusCompleted, found.Status)
+		requireEqual(t, "parts after completion", 0, len(found.Parts))
+		requireEqual(t, "media id", upload.MediaID, found.MediaID)
+
+		_, err = repos.Uploads.Complete(ctx, id, time.Now().Add(time.Hour))
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeUploadCompleted)
+		_, err = repos.Uploads.AppendPart(ctx, id, 300, "part-3", 1, "", time.Now().Add(time.Hour))
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeUploadCompleted)
+
+		requireNoError(t, repos.Uploads.Delete(ctx, id))
+		_, err = repos.Uploads.GetByID(ctx, id)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUploadNotFound)
+		_, err = repos.Uploads.AppendPart(ctx, missingID, 0, "part", 1, "", time.Now().Add(time.Hour))
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUploadNotFound)
+		_, err = repos.Uploads.GetByID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+
+	t.Run("expired uploads", func(t *testing.T) {
+		repos := newRepositories(t)
+		later := newUpload(t, repos, time.Now().Add(-time.Minute))
+		earlier := newUpload(t, repos, time.Now().Add(-time.Hour))
+		newUpload(t, repos, time.Now().Add(time.Hour))
+
+		_, err := repos.Uploads.GetByID(ctx, later.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUploadNotFound)
+		_, err = repos.Uploads.AppendPart(ctx, later.ID.Hex(), 0, "part", 1, "", time.Now().Add(time.Hour))
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUploadNotFound)
+
+		expired, err := repos.Uploads.ListExpired(ctx, time.Now(), 10)
+		requireNoError(t, err)
+		requireEqual(t, "expired uploads", 2, len(expired))
+		requireEqual(t, "oldest first", earlier.ID, expired[0].ID)
+		expired, err = repos.Uploads.ListExpired(ctx, time.Now(), 1)
+		requireNoError(t, err)
+		requireEqual(t, "limited", 1, len(expired))
+	})
+}
+
+func testBlobs(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("references are counted per owner & hash", func(t *testing.T) {
+		repos := newRepositories(t)
+		owner := primitive.NewObjectID()
+		blob, err := repos.Blobs.Create(ctx, &models.Blob{
+			OwnerID:       owner,
+			Hash:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			StorageKey:    "blobs/" + owner.Hex() + "/1",
+			ContentType:   "image/png",
+			Size:          4,
+			MediaMetadata: models.MediaMetadata{Width: 2, Height: 1},
+		})
+		requireNoError(t, err)
+		if blob.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+		requireEqual(t, "references", 1, blob.RefCount)
+		id := blob.ID.Hex()
+
+		// the same file of the same owner is one blob, another owner gets its own
+		_, err = repos.Blobs.Create(ctx, &models.Blob{OwnerID: owner, Hash: blob.Hash, StorageKey: "blobs/" + owner.Hex() + "/2"})
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeDuplicate)
+		_, err = repos.Blobs.Acquire(ctx, primitive.NewObjectID().Hex(), blob.Hash)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeBlobNotFound)
+
+		acquired, err := repos.Blobs.Acquire(ctx, owner.Hex(), blob.Hash)
+		requireNoError(t, err)
+		requireEqual(t, "acquired blob", blob.ID, acquired.ID)
+		requireEqual(t, "references", 2, acquired.RefCount)
+		requireEqual(t, "metadata", 2, acquired.Width)
+
+		requireNoError(t, repos.Blobs.Release(ctx, id))
+		unreferenced, err := repos.Blobs.ListUnreferenced(ctx, 10)
+		requireNoError(t, err)
+		requireEqual(t, "unreferenced blobs", 0, len(unreferenced))
+		err = repos.Blobs.DeleteUnreferenced(ctx, id)
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeBlobReferenced)
+
+		requireNoError(t, repos.Blobs.Release(ctx, id))
+		err = repos.Blobs.Release(ctx, id)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeBlobNotFound)
+		unreferenced, err = repos.Blobs.ListUnreferenced(ctx, 10)
+		requireNoError(t, err)
+		requireEqual(t, "unreferenced blobs", 1, len(unreferenced))
+
+		// an unreferenced blob is revived until the collector deletes it
+		revived, err := repos.Blobs.Acquire(ctx, owner.Hex(), blob.Hash)
+		requireNoError(t, err)
+		requireEqual(t, "references", 1, revived.RefCount)
+		requireNoError(t, repos.Blobs.Release(ctx, id))
+
+		requireNoError(t, repos.Blobs.DeleteUnreferenced(ctx, id))
+		_, err = repos.Blobs.Acquire(ctx, owner.Hex(), blob.Hash)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeBlobNotFound)
+		err = repos.Blobs.DeleteUnreferenced(ctx, id)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeBlobNotFound)
+		err = repos.Blobs.Release(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+
+	t.Run("scan verdicts are recorded once per blob", func(t *testing.T) {
+		repos := newRepositories(t)
+		blob, err := repos.Blobs.Create(ctx, &models.Blob{
+			OwnerID:    primitive.NewObjectID(),
+			Hash:       "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752",
+			StorageKey: "blobs/scanned",
+			MediaScan:  models.MediaScan{ScanStatus: models.ScanStatusPending},
+		})
+		requireNoError(t, err)
+		id := blob.ID.Hex()
+
+		found, err := repos.Blobs.GetByID(ctx, id)
+		requireNoError(t, err)
+		requireEqual(t, "status", models.ScanStatusPending, found.ScanStatus)
+		requireNoError(t, repos.Blobs.SetScanStatus(ctx, id, models.ScanStatusClean, ""))
+		found, err = repos.Blobs.GetByID(ctx, id)
+		requireNoError(t, err)
+		requireEqual(t, "status", models.ScanStatusClean, found.ScanStatus)
+		requireEqual(t, "references", 1, found.RefCount)
+
+		// later uploads of the file share the verdict
+		acquired, err := repos.Blobs.Acquire(ctx, blob.OwnerID.Hex(), blob.Hash)
+		requireNoError(t, err)
+		requireEqual(t, "acquired status", models.ScanStatusClean, acquired.ScanStatus)
+
+		_, err = repos.Blobs.GetByID(ctx, primitive.NewObjectID().Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeBlobNotFound)
+		err = repos.Blobs.SetScanStatus(ctx, primitive.NewObjectID().Hex(), models.ScanStatusClean, "")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeBlobNotFound)
+		_, err = repos.Blobs.GetByID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
@@ -0,0 +1,288 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func testDeviceKeys(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("upsert replaces the device's keys", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID()
+		first, err := repos.DeviceKeys.Upsert(ctx, &models.DeviceKey{
+			UserID:       userID,
+			DeviceID:     "phone",
+			IdentityKey:  "identity-1",
+			SignedPreKey: models.SignedPreKey{KeyID: 1, PublicKey: "spk-1", Signature: "sig-1"},
+		})
+		requireNoError(t, err)
+		if first.ID.IsZero() {
+			t.Fatal("Upsert did not return the stored id")
+		}
+		second, err := repos.DeviceKeys.Upsert(ctx, &models.DeviceKey{
+			UserID:       userID,
+			DeviceID:     "phone",
+			IdentityKey:  "identity-2",
+			SignedPreKey: models.SignedPreKey{KeyID: 2, PublicKey: "spk-2", Signature: "sig-2"},
+		})
+		requireNoError(t, err)
+		requireEqual(t, "id", first.ID, second.ID)
+		requireEqual(t, "identity key", "identity-2", second.IdentityKey)
+		requireEqual(t, "signed prekey", 2, second.SignedPreKey.KeyID)
+
+		_, err = repos.DeviceKeys.Upsert(ctx, &models.DeviceKey{UserID: userID, DeviceID: "laptop", IdentityKey: "identity-3"})
+		requireNoError(t, err)
+		devices, err := repos.DeviceKeys.GetByUserID(ctx, userID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "devices", 2, len(devices))
+		requireEqual(t, "oldest device", "phone", devices[0].DeviceID)
+
+		found, err := repos.DeviceKeys.GetByUserIDAndDeviceID(ctx, userID.Hex(), "laptop")
+		requireNoError(t, err)
+		requireEqual(t, "identity key", "identity-3", found.IdentityKey)
+		_, err = repos.DeviceKeys.GetByUserIDAndDeviceID(ctx, userID.Hex(), "tablet")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeDeviceNotFound)
+		_, err = repos.DeviceKeys.GetByUserID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+
+	t.Run("one-time prekeys", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID().Hex()
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID, "phone", "identity", []models.OneTimePreKey{
+			{KeyID: 7, PublicKey: "pk-7"},
+			{KeyID: 3, PublicKey: "pk-3"},
+		}))
+		// already uploaded keyIds are ignored, the rest of the batch is stored
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID, "phone", "identity", []models.OneTimePreKey{
+			{KeyID: 3, PublicKey: "pk-3-again"},
+			{KeyID: 5, PublicKey: "pk-5"},
+		}))
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID, "laptop", "identity", []models.OneTimePreKey{{KeyID: 1, PublicKey: "pk-1"}}))
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID, "phone", "identity", nil))
+
+		count, err := repos.DeviceKeys.CountOneTimePreKeys(ctx, userID, "phone")
+		requireNoError(t, err)
+		requireEqual(t, "prekeys", int64(3), count)
+
+		for _, want := range []models.OneTimePreKey{{KeyID: 3, PublicKey: "pk-3"}, {KeyID: 5, PublicKey: "pk-5"}, {KeyID: 7, PublicKey: "pk-7"}} {
+			preKey, err := repos.DeviceKeys.ConsumeOneTimePreKey(ctx, userID, "phone", "identity")
+			requireNoError(t, err)
+			if preKey == nil {
+				t.Fatalf("expected prekey %d, got none", want.KeyID)
+			}
+			requireEqual(t, "key id", want.KeyID, preKey.KeyID)
+			requireEqual(t, "public key", want.PublicKey, preKey.PublicKey)
+		}
+		preKey, err := repos.DeviceKeys.ConsumeOneTimePreKey(ctx, userID, "phone", "identity")
+		requireNoError(t, err)
+		if preKey != nil {
+			t.Fatalf("expected no prekey left, got %d", preKey.KeyID)
+		}
+
+		// deleting a device removes its remaining prekeys
+		_, err = repos.DeviceKeys.Upsert(ctx, &models.DeviceKey{UserID: mustObjectID(t, userID), DeviceID: "laptop", IdentityKey: "identity"})
+		requireNoError(t, err)
+		requireNoError(t, repos.DeviceKeys.DeleteByUserIDAndDeviceID(ctx, userID, "laptop"))
+		count, err = repos.DeviceKeys.CountOneTimePreKeys(ctx, userID, "laptop")
+		requireNoError(t, err)
+		requireEqual(t, "laptop prekeys", int64(0), count)
+		_, err = repos.DeviceKeys.GetByUserIDAndDeviceID(ctx, userID, "laptop")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeDeviceNotFound)
+	})
+}
+
+func testDeviceKeyRotation(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("a new identity key deletes the one-time prekeys", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID()
+		publish := func(identityKey string, signedPreKeyID int) {
+			t.Helper()
+			_, err := repos.DeviceKeys.Upsert(ctx, &models.DeviceKey{
+				UserID:       userID,
+				DeviceID:     "phone",
+				IdentityKey:  identityKey,
+				SignedPreKey: models.SignedPreKey{KeyID: signedPreKeyID, PublicKey: "spk", Signature: "sig"},
+			})
+			requireNoError(t, err)
+		}
+		count := func() int64 {
+			t.Helper()
+			count, err := repos.DeviceKeys.CountOneTimePreKeys(ctx, userID.Hex(), "phone")
+			requireNoError(t, err)
+			return count
+		}
+
+		publish("identity-1", 1)
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID.Hex(), "phone", "identity-1", []models.OneTimePreKey{
+			{KeyID: 1, PublicKey: "pk-1"},
+			{KeyID: 2, PublicKey: "pk-2"},
+		}))
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID.Hex(), "laptop", "identity-1", []models.OneTimePreKey{{KeyID: 1, PublicKey: "pk-1"}}))
+
+		// rotating only the signed prekey keeps them
+		publish("identity-1", 2)
+		requireEqual(t, "prekeys after a signed prekey rotation", int64(2), count())
+
+		publish("identity-2", 3)
+		requireEqual(t, "prekeys after an identity rotation", int64(0), count())
+		// a prekey upload of the previous identity racing the rotation is never handed out with the new one
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID.Hex(), "phone", "identity-1", []models.OneTimePreKey{{KeyID: 9, PublicKey: "pk-9"}}))
+		preKey, err := repos.DeviceKeys.ConsumeOneTimePreKey(ctx, userID.Hex(), "phone", "identity-2")
+		requireNoError(t, err)
+		if preKey != nil {
+			t.Fatalf("expected no prekey of the previous identity, got %d", preKey.KeyID)
+		}
+		publish("identity-2", 4)
+		requireEqual(t, "prekeys after the next publish", int64(0), count())
+		laptop, err := repos.DeviceKeys.CountOneTimePreKeys(ctx, userID.Hex(), "laptop")
+		requireNoError(t, err)
+		requireEqual(t, "prekeys of another device", int64(1), laptop)
+
+		// the prekeys uploaded for the new identity are handed out
+		requireNoError(t, repos.DeviceKeys.AddOneTimePreKeys(ctx, userID.Hex(), "phone", "identity-2", []models.OneTimePreKey{{KeyID: 1, PublicKey: "pk-1-new"}}))
+		preKey, err = repos.DeviceKeys.ConsumeOneTimePreKey(ctx, userID.Hex(), "phone", "identity-2")
+		requireNoError(t, err)
+		if preKey == nil {
+			t.Fatal("expected the prekey of the new identity, got none")
+		}
+		requireEqual(t, "public key", "pk-1-new", preKey.PublicKey)
+	})
+}
+
+func testAuthentications(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("refresh token lifecycle", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID().Hex()
+		requireNoError(t, repos.Authentications.SaveRefreshToken(ctx, userID, "token-1", time.Hour))
+
+		found, err := repos.Authentications.GetUserIDFromRefreshToken(ctx, "token-1")
+		requireNoError(t, err)
+		requireEqual(t, "user id", userID, found)
+		_, err = repos.Authentications.GetUserIDFromRefreshToken(ctx, "unknown")
+		requireError(t, err, apperrors.ErrUnauthorized, apperrors.CodeInvalidRefreshToken)
+		_, err = repos.Authentications.GetUserIDFromRefreshToken(ctx, "")
+		requireError(t, err, apperrors.ErrValidation, "")
+
+		// a new token replaces the previous one
+		requireNoError(t, repos.Authentications.SaveRefreshToken(ctx, userID, "token-2", time.Hour))
+		_, err = repos.Authentications.GetUserIDFromRefreshToken(ctx, "token-1")
+		requireError(t, err, apperrors.ErrUnauthorized, apperrors.CodeInvalidRefreshToken)
+		list, err := repos.Authentications.GetList(ctx)
+		requireNoError(t, err)
+		requireEqual(t, "authentications", 1, len(*list))
+
+		requireNoError(t, repos.Authentications.RevokeRefreshToken(ctx, "token-2"))
+		_, err = repos.Authentications.GetUserIDFromRefreshToken(ctx, "token-2")
+		requireError(t, err, apperrors.ErrUnauthorized, apperrors.CodeInvalidRefreshToken)
+		requireError(t, repos.Authentications.RevokeRefreshToken(ctx, "token-2"), apperrors.ErrUnauthorized, apperrors.CodeInvalidRefreshToken)
+
+		// signing in again after a revocation issues a usable token
+		requireNoError(t, repos.Authentications.SaveRefreshToken(ctx, userID, "token-3", time.Hour))
+		found, err = repos.Authentications.GetUserIDFromRefreshToken(ctx, "token-3")
+		requireNoError(t, err)
+		requireEqual(t, "user id", userID, found)
+
+		requireNoError(t, repos.Authentications.DeleteRefreshToken(ctx, "token-3"))
+		_, err = repos.Authentications.GetByUserID(ctx, userID)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeNotFound)
+	})
+
+	t.Run("expired tokens", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID().Hex()
+		requireNoError(t, repos.Authentications.SaveRefreshToken(ctx, userID, "token", -time.Minute))
+		_, err := repos.Authentications.GetUserIDFromRefreshToken(ctx, "token")
+		requireError(t, err, apperrors.ErrUnauthorized, apperrors.CodeInvalidRefreshToken)
+	})
+
+	t.Run("sessions without a refresh token", func(t *testing.T) {
+		repos := newRepositories(t)
+		// registration creates sessions before their first refresh token
+		var userIDs []string
+		for i := 0; i < 2; i++ {
+			auth := models.GetAuthenticationDefaults()
+			auth.UserID = primitive.NewObjectID()
+			auth.IsActive = false
+			_, err := repos.Authentications.Create(ctx, auth)
+			requireNoError(t, err)
+			userIDs = append(userIDs, auth.UserID.Hex())
+		}
+
+		requireNoError(t, repos.Authentications.SaveRefreshToken(ctx, userIDs[0], "token", time.Hour))
+		found, err := repos.Authentications.GetUserIDFromRefreshToken(ctx, "token")
+		requireNoError(t, err)
+		requireEqual(t, "user id", userIDs[0], found)
+	})
+
+	t.Run("one document per user", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID()
+		auth := models.GetAuthenticationDefaults()
+		auth.UserID = userID
+		auth.RefreshTokenHash = "hash-1"
+		created, err := repos.Authentications.Create(ctx, auth)
+		requireNoError(t, err)
+		if created.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+
+		again := models.GetAuthenticationDefaults()
+		again.UserID = userID
+		again.RefreshTokenHash = "hash-2"
+		_, err = repos.Authentications.Create(ctx, again)
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeDuplicate)
+
+		created.AuthProvider = "password"
+		_, err = repos.Authentications.UpdateByUserID(ctx, userID.Hex(), created)
+		requireNoError(t, err)
+		found, err := repos.Authentications.GetByUserID(ctx, userID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "auth provider", "password", found.AuthProvider)
+		_, err = repos.Authentications.UpdateByUserID(ctx, missingID, created)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeNotFound)
+
+		requireNoError(t, repos.Authentications.DeleteByUserID(ctx, userID.Hex()))
+		_, err = repos.Authentications.GetByUserID(ctx, userID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeNotFound)
+		requireNoError(t, repos.Authentications.Delete(ctx, created.ID.Hex()))
+	})
+}
+
+func mustObjectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(hex)
+	requireNoError(t, err)
+	return id
+}
+
+func testRateLimits(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("calls are counted per key & window", func(t *testing.T) {
+		repos := newRepositories(t)
+		start := time.Now().Truncate(time.Hour)
+		hit := func(key string, windowStart time.Time) int64 {
+			t.Helper()
+			calls, err := repos.RateLimits.Hit(ctx, key, windowStart, time.Hour)
+			requireNoError(t, err)
+			return calls
+		}
+
+		for want := int64(1); want <= 3; want++ {
+			requireEqual(t, "calls", want, hit("alice/bob", start))
+		}
+		requireEqual(t, "calls of another key", int64(1), hit("alice/carol", start))
+		requireEqual(t, "calls in the next window", int64(1), hit("alice/bob", start.Add(time.Hour)))
+	})
+}
@@ -0,0 +1,135 @@
+// Package repositorytest is a conformance suite for the repository interfaces, every implementation runs it
+// so that services behave the same whichever storage backs them.
+package repositorytest
+
+import (
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+// Repositories are the implementations under test, they share one storage
+type Repositories struct {
+	Users           repository.IUserRepository
+	Settings        repository.ISettingsRepository
+	LastSeen        repository.ILastSeenRepository
+	Chats           repository.ChatRepository
+	ChatKeys        repository.IChatKeyRepository
+	Messages        repository.MessageRepository
+	PlayedReceipts  repository.IPlayedReceiptRepository
+	ChatGroups      repository.ChatGroupRepository
+	Highlights      repository.HighlightRepository
+	Media           repository.MediaRepository
+	Uploads         repository.IUploadRepository
+	Blobs           repository.IBlobRepository
+	Subscriptions   repository.ISubscriptionRepository
+	DeviceKeys      repository.IDeviceKeyRepository
+	Authentications repository.IAuthenticationRepository
+	RateLimits      repository.IRateLimitRepository
+	UnitOfWork      repository.IUnitOfWork
+}
+
+// Factory returns repositories backed by fresh, empty storage, it is called once per test
+type Factory func(t *testing.T, keys Keys) Repositories
+
+// Keys are the services the repositories hash & encrypt with
+type Keys struct {
+	Encryption services.IEncryptionService
+	SearchKey  services.ISearchKeyService
+}
+
+// Run runs the whole suite against the repositories newRepositories returns
+func Run(t *testing.T, newRepositories Factory) {
+	suites := []struct {
+		name string
+		run  func(t *testing.T, newRepositories func(t *testing.T) Repositories)
+	}{
+		{"Users", testUsers},
+		{"Settings", testSettings},
+		{"LastSeen", testLastSeen},
+		{"Chats", testChats},
+		{"ChatKeys", testChatKeys},
+		{"Messages", testMessages},
+		{"PlayedReceipts", testPlayedReceipts},
+		{"ChatGroups", testChatGroups},
+		{"Highlights", testHighlights},
+		{"Media", testMedia},
+		{"Uploads", testUploads},
+		{"Blobs", testBlobs},
+		{"Subscriptions", testSubscriptions},
+		{"DeviceKeys", testDeviceKeys},
+		{"DeviceKeyRotation", testDeviceKeyRotation},
+		{"Authentications", testAuthentications},
+		{"RateLimits", testRateLimits},
+		{"UnitOfWork", testUnitOfWork},
+	}
+	for _, suite := range suites {
+		t.Run(suite.name, func(t *testing.T) {
+			suite.run(t, func(t *testing.T) Repositories {
+				return newRepositories(t, NewKeys(t))
+			})
+		})
+	}
+}
+
+// NewKeys generates random encryption & search keys
+func NewKeys(t *testing.T) Keys {
+	t.Helper()
+	log := zerolog.Nop()
+	aesKey, err := services.GenerateAESKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacKey, err := services.GenerateAESKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptionSvc, err := services.NewAESEncryptionService(aesKey, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchKeySvc, err := services.NewHMACSearchKeyService(&log, hmacKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Keys{Encryption: encryptionSvc, SearchKey: searchKeySvc}
+}
+
+// :::: ASSERTIONS
+
+func requireNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// requireError fails unless err is of kind & carries code, an empty code matches any code
+func requireError(t *testing.T, err error, kind *apperrors.Error, code string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected a %s error, got nil", kind.Kind)
+	}
+	if !errors.Is(err, kind) {
+		t.Fatalf("expected a %s error, got %v", kind.Kind, err)
+	}
+	if code != "" && apperrors.From(err).Code != code {
+		t.Fatalf("expected code %s, got %s (%v)", code, apperrors.From(err).Code, err)
+	}
+}
+
+func requireEqual[T comparable](t *testing.T, field string, want, got T) {
+	t.Helper()
+	if want != got {
+		t.Fatalf("%s: want %v, got %v", field, want, got)
+	}
+}
+
+// missingID is a well-formed id no test ever stores
+const missingID = "000000000000000000000001"
+
+// malformedID is not a valid hex ObjectID
+const malformedID = "not-an-id"
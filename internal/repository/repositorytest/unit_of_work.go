@@ -0,0 +1,51 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"testing"
+)
+
+func testUnitOfWork(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("commits", func(t *testing.T) {
+		repos := newRepositories(t)
+		err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			_, err := repos.Users.Create(ctx, newUser("alice"))
+			return err
+		})
+		requireNoError(t, err)
+		_, err = repos.Users.GetByUsername(ctx, "alice")
+		requireNoError(t, err)
+	})
+
+	t.Run("rolls back", func(t *testing.T) {
+		repos := newRepositories(t)
+		failure := apperrors.Conflict(apperrors.CodeDuplicate, "second write failed")
+		err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			created, err := repos.Users.Create(ctx, newUser("alice"))
+			if err != nil {
+				return err
+			}
+			userID := created.ID.Hex()
+			repository.OnRollback(ctx, func(ctx context.Context) error { return repos.Users.Delete(ctx, userID) })
+
+			// nested units of work join the outer one
+			return repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+				_, err := repos.Users.Create(ctx, newUser("bob"))
+				if err != nil {
+					return err
+				}
+				return failure
+			})
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("got error %v, want %v", err, failure)
+		}
+		_, err = repos.Users.GetByUsername(ctx, "alice")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+	})
+}
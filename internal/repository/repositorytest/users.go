@@ -0,0 +1,293 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func newUser(name string) *models.User {
+	return &models.User{
+		FirstName:   name,
+		LastName:    "Test",
+		Username:    name,
+		Email:       name + "@telko.test",
+		PhoneNumber: "+2637" + name,
+		Password:    "hashed-" + name,
+		UserType:    "user",
+		Status:      models.UserStatusActive,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func testUsers(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create and look up", func(t *testing.T) {
+		repos := newRepositories(t)
+		created, err := repos.Users.Create(ctx, newUser("alice"))
+		requireNoError(t, err)
+		if created.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+		requireEqual(t, "email", "alice@telko.test", created.Email)
+
+		lookups := map[string]func() (*models.User, error){
+			"GetByID":       func() (*models.User, error) { return repos.Users.GetByID(ctx, created.ID.Hex()) },
+			"GetByEmail":    func() (*models.User, error) { return repos.Users.GetByEmail(ctx, " ALICE@telko.test ") },
+			"GetByUsername": func() (*models.User, error) { return repos.Users.GetByUsername(ctx, "Alice") },
+			"GetByPhone":    func() (*models.User, error) { return repos.Users.GetByPhoneNumber(ctx, "+2637alice") },
+		}
+		for name, lookup := range lookups {
+			found, err := lookup()
+			requireNoError(t, err)
+			requireEqual(t, name+" id", created.ID, found.ID)
+			requireEqual(t, name+" username", "alice", found.Username)
+			requireEqual(t, name+" email", "alice@telko.test", found.Email)
+			requireEqual(t, name+" phone number", "+2637alice", found.PhoneNumber)
+			requireEqual(t, name+" first name", "alice", found.FirstName)
+		}
+	})
+
+	t.Run("unique username and email", func(t *testing.T) {
+		repos := newRepositories(t)
+		_, err := repos.Users.Create(ctx, newUser("bob"))
+		requireNoError(t, err)
+
+		sameUsername := newUser("bob")
+		sameUsername.Email, sameUsername.PhoneNumber = "other@telko.test", "+2637other"
+		_, err = repos.Users.Create(ctx, sameUsername)
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeDuplicate)
+
+		sameEmail := newUser("bob")
+		sameEmail.Username = "bobby"
+		_, err = repos.Users.Create(ctx, sameEmail)
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeDuplicate)
+	})
+
+	t.Run("missing and malformed ids", func(t *testing.T) {
+		repos := newRepositories(t)
+		_, err := repos.Users.GetByID(ctx, missingID)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+		_, err = repos.Users.GetByEmail(ctx, "nobody@telko.test")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+		_, err = repos.Users.GetByID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+		requireError(t, repos.Users.Delete(ctx, malformedID), apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repos := newRepositories(t)
+		created, err := repos.Users.Create(ctx, newUser("carol"))
+		requireNoError(t, err)
+
+		created.Email = "carol@example.test"
+		created.Bio = "hello"
+		updated, err := repos.Users.Update(ctx, created)
+		requireNoError(t, err)
+		requireEqual(t, "email", "carol@example.test", updated.Email)
+		requireEqual(t, "bio", "hello", updated.Bio)
+		requireEqual(t, "caller's email", "carol@example.test", created.Email)
+
+		found, err := repos.Users.GetByEmail(ctx, "carol@example.test")
+		requireNoError(t, err)
+		requireEqual(t, "id", created.ID, found.ID)
+		_, err = repos.Users.GetByEmail(ctx, "carol@telko.test")
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+
+		missing := newUser("dave")
+		missing.ID = primitive.NewObjectID()
+		_, err = repos.Users.Update(ctx, missing)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+		_, err = repos.Users.GetByID(ctx, missing.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+	})
+
+	t.Run("list and delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		var ids []primitive.ObjectID
+		for _, name := range []string{"erin", "frank", "grace"} {
+			created, err := repos.Users.Create(ctx, newUser(name))
+			requireNoError(t, err)
+			ids = append(ids, created.ID)
+		}
+
+		all, err := repos.Users.List(ctx, 1, 0)
+		requireNoError(t, err)
+		requireEqual(t, "users", 3, len(all))
+		for i, user := range all {
+			requireEqual(t, "order", ids[i], user.ID)
+		}
+		requireEqual(t, "decrypted email", "erin@telko.test", all[0].Email)
+
+		secondPage, err := repos.Users.List(ctx, 2, 2)
+		requireNoError(t, err)
+		requireEqual(t, "second page", 1, len(secondPage))
+		requireEqual(t, "second page id", ids[2], secondPage[0].ID)
+
+		requireNoError(t, repos.Users.Delete(ctx, ids[0].Hex()))
+		requireNoError(t, repos.Users.Delete(ctx, ids[0].Hex()))
+		_, err = repos.Users.GetByID(ctx, ids[0].Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeUserNotFound)
+		all, err = repos.Users.List(ctx, 0, 10)
+		requireNoError(t, err)
+		requireEqual(t, "users after delete", 2, len(all))
+	})
+}
+
+func testSettings(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("one settings document per user", func(t *testing.T) {
+		repos := newRepositories(t)
+		userID := primitive.NewObjectID()
+		settings := models.GetSettingsDefaultsFromHeaders(map[string]string{})
+		settings.UserId = userID
+		created, err := repos.Settings.Create(ctx, settings)
+		requireNoError(t, err)
+		if created.ID.IsZero() {
+			t.Fatal("Create did not assign an id")
+		}
+
+		again := models.GetSettingsDefaultsFromHeaders(map[string]string{})
+		again.UserId = userID
+		_, err = repos.Settings.Create(ctx, again)
+		requireError(t, err, apperrors.ErrConflict, apperrors.CodeDuplicate)
+
+		found, err := repos.Settings.GetByUserID(ctx, userID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "id", created.ID, found.ID)
+		_, err = repos.Settings.GetByUserID(ctx, missingID)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeSettingsNotFound)
+	})
+
+	t.Run("list by user ids", func(t *testing.T) {
+		repos := newRepositories(t)
+		var userIDs []primitive.ObjectID
+		for i := 0; i < 3; i++ {
+			settings := models.GetSettingsDefaultsFromHeaders(map[string]string{})
+			settings.UserId = primitive.NewObjectID()
+			_, err := repos.Settings.Create(ctx, settings)
+			requireNoError(t, err)
+			userIDs = append(userIDs, settings.UserId)
+		}
+
+		// users without settings are left out
+		found, err := repos.Settings.ListByUserIDs(ctx, []primitive.ObjectID{userIDs[0], userIDs[2], primitive.NewObjectID()})
+		requireNoError(t, err)
+		requireEqual(t, "settings", 2, len(found))
+		for _, settings := range found {
+			if settings.UserId != userIDs[0] && settings.UserId != userIDs[2] {
+				t.Fatalf("unexpected settings of user %s", settings.UserId.Hex())
+			}
+		}
+
+		found, err = repos.Settings.ListByUserIDs(ctx, nil)
+		requireNoError(t, err)
+		requireEqual(t, "settings", 0, len(found))
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		settings := models.GetSettingsDefaultsFromHeaders(map[string]string{})
+		settings.UserId = primitive.NewObjectID()
+		created, err := repos.Settings.Create(ctx, settings)
+		requireNoError(t, err)
+
+		created.Preferences.Theme = "dark"
+		requireNoError(t, repos.Settings.Update(ctx, created))
+		found, err := repos.Settings.GetByID(ctx, created.ID.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "theme", "dark", found.Preferences.Theme)
+
+		missing := *created
+		missing.ID = primitive.NewObjectID()
+		missing.UserId = primitive.NewObjectID()
+		requireError(t, repos.Settings.Update(ctx, &missing), apperrors.ErrNotFound, apperrors.CodeSettingsNotFound)
+
+		requireNoError(t, repos.Settings.Delete(ctx, created.ID.Hex()))
+		_, err = repos.Settings.GetByID(ctx, created.ID.Hex())
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeSettingsNotFound)
+		_, err = repos.Settings.GetByID(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
+
+func testSubscriptions(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("active subscriptions & enterprise members", func(t *testing.T) {
+		repos := newRepositories(t)
+		now := time.Now()
+		user, member, enterprise := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		for _, subscription := range []*models.Subscription{
+			{UserId: user, PlanType: models.PlanBasic, Status: models.SubscriptionStatusActive, StartDate: now.Add(-48 * time.Hour)},
+			{UserId: user, PlanType: models.PlanPremium, Status: models.SubscriptionStatusActive, StartDate: now.Add(-time.Hour), EndDate: now.Add(time.Hour)},
+			{UserId: user, PlanType: models.PlanEnterprise, Status: models.SubscriptionStatusCanceled, StartDate: now.Add(-time.Minute), EnterpriseId: enterprise},
+			{UserId: member, PlanType: models.PlanEnterprise, Status: models.SubscriptionStatusActive, StartDate: now.Add(-time.Hour), EnterpriseId: enterprise},
+			{UserId: primitive.NewObjectID(), PlanType: models.PlanEnterprise, Status: models.SubscriptionStatusActive, StartDate: now.Add(time.Hour), EnterpriseId: enterprise},
+		} {
+			created, err := repos.Subscriptions.Create(ctx, subscription)
+			requireNoError(t, err)
+			if created.Id.IsZero() {
+				t.Fatal("Create did not assign an id")
+			}
+		}
+
+		// the latest started of the active subscriptions wins, canceled & future ones are ignored
+		active, err := repos.Subscriptions.GetActiveByUserId(ctx, user.Hex(), now)
+		requireNoError(t, err)
+		requireEqual(t, "plan", models.PlanPremium, active.PlanType)
+		active, err = repos.Subscriptions.GetActiveByUserId(ctx, user.Hex(), now.Add(2*time.Hour))
+		requireNoError(t, err)
+		requireEqual(t, "plan after the premium one ended", models.PlanBasic, active.PlanType)
+		_, err = repos.Subscriptions.GetActiveByUserId(ctx, primitive.NewObjectID().Hex(), now)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeSubscriptionNotFound)
+		_, err = repos.Subscriptions.GetActiveByUserId(ctx, malformedID, now)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+
+		members, err := repos.Subscriptions.ListEnterpriseMembers(ctx, enterprise.Hex(), now)
+		requireNoError(t, err)
+		requireEqual(t, "members", 1, len(members))
+		requireEqual(t, "member", member, members[0])
+		members, err = repos.Subscriptions.ListEnterpriseMembers(ctx, primitive.NewObjectID().Hex(), now)
+		requireNoError(t, err)
+		requireEqual(t, "members of another enterprise", 0, len(members))
+	})
+}
+
+func testLastSeen(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("the latest time is kept", func(t *testing.T) {
+		repos := newRepositories(t)
+		user, other := primitive.NewObjectID(), primitive.NewObjectID()
+		seen := time.Now().Truncate(time.Millisecond).UTC()
+		requireNoError(t, repos.LastSeen.Save(ctx, []models.LastSeen{
+			{UserID: user, LastSeenAt: seen},
+			{UserID: other, LastSeenAt: seen.Add(-time.Hour)},
+		}))
+		requireNoError(t, repos.LastSeen.Save(ctx, nil))
+
+		// a server flushing late must not move it back
+		requireNoError(t, repos.LastSeen.Save(ctx, []models.LastSeen{
+			{UserID: user, LastSeenAt: seen.Add(-time.Minute)},
+			{UserID: other, LastSeenAt: seen},
+		}))
+		found, err := repos.LastSeen.GetByUserId(ctx, user.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "last seen", seen, found.LastSeenAt.UTC())
+		found, err = repos.LastSeen.GetByUserId(ctx, other.Hex())
+		requireNoError(t, err)
+		requireEqual(t, "last seen of the other user", seen, found.LastSeenAt.UTC())
+
+		_, err = repos.LastSeen.GetByUserId(ctx, missingID)
+		requireError(t, err, apperrors.ErrNotFound, apperrors.CodeLastSeenNotFound)
+		_, err = repos.LastSeen.GetByUserId(ctx, malformedID)
+		requireError(t, err, apperrors.ErrValidation, apperrors.CodeInvalidID)
+	})
+}
@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ISettingsRepository interface {
 	Create(ctx context.Context, settings *models.Settings) (*models.Settings, error)
 	GetByID(ctx context.Context, id string) (*models.Settings, error)
 	GetByUserID(ctx context.Context, userId string) (*models.Settings, error)
+	// ListByUserIDs returns the settings of the users in one query, users without settings are left out
+	ListByUserIDs(ctx context.Context, userIds []primitive.ObjectID) ([]models.Settings, error)
 	List(ctx context.Context, page, limit int) ([]models.Settings, error)
 	Update(ctx context.Context, settings *models.Settings) error
 	Delete(ctx context.Context, id string) error
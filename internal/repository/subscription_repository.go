@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type ISubscriptionRepository interface {
+	Create(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error)
+	// GetActiveByUserId returns the subscription of userId active at, the latest started one when several are
+	GetActiveByUserId(ctx context.Context, userId string, at time.Time) (*models.Subscription, error)
+	// ListEnterpriseMembers returns the users holding a subscription of the enterprise active at
+	ListEnterpriseMembers(ctx context.Context, enterpriseId string, at time.Time) ([]primitive.ObjectID, error)
+}
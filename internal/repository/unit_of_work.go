@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+// IUnitOfWork runs several repository calls as one atomic operation
+type IUnitOfWork interface {
+	// Do runs fn, the repository calls fn makes with the context it receives are committed together or not at all.
+	// Calls nested in a running unit of work join it.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type rollbacksKey struct{}
+
+// rollbacks are the undo functions registered in a unit of work that runs without a transaction
+type rollbacks struct {
+	undo []func(ctx context.Context) error
+}
+
+// OnRollback registers undo to revert a write made in a unit of work when the unit of work fails.
+// Transactions discard the writes themselves, so undo only runs when the storage has none, e.g. a standalone MongoDB.
+func OnRollback(ctx context.Context, undo func(ctx context.Context) error) {
+	if r, ok := ctx.Value(rollbacksKey{}).(*rollbacks); ok {
+		r.undo = append(r.undo, undo)
+	}
+}
+
+// RunWithRollbacks runs fn as a unit of work without a transaction: when fn fails the undo functions it
+// registered with OnRollback run in reverse order. Their errors are joined to fn's error.
+func RunWithRollbacks(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(rollbacksKey{}).(*rollbacks); ok {
+		// nested, the outer unit of work rolls back
+		return fn(ctx)
+	}
+
+	r := &rollbacks{}
+	err := fn(context.WithValue(ctx, rollbacksKey{}, r))
+	if err == nil {
+		return nil
+	}
+
+	// undo even when the request was cancelled, a half written unit of work is worse than a late response
+	undoCtx := context.WithoutCancel(ctx)
+	errs := []error{err}
+	for i := len(r.undo) - 1; i >= 0; i-- {
+		if undoErr := r.undo[i](undoCtx); undoErr != nil {
+			errs = append(errs, undoErr)
+		}
+	}
+	return errors.Join(errs...)
+}
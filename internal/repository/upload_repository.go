@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"strconv"
+	"time"
+)
+
+type IUploadRepository interface {
+	Create(ctx context.Context, upload *models.Upload) (*models.Upload, error)
+	// GetByID returns the upload, expired uploads are not found even before they are deleted
+	GetByID(ctx context.Context, id string) (*models.Upload, error)
+	// AppendPart atomically records a chunk of size bytes stored under part & extends the expiry. It fails with
+	// a conflict when the upload is no longer uploading at offset, e.g. a concurrent chunk won.
+	// contentType is stored when not empty.
+	AppendPart(ctx context.Context, id string, offset int64, part string, size int64, contentType string, expiresAt time.Time) (*models.Upload, error)
+	// Complete marks the upload completed & forgets its parts, it returns the upload as it was before
+	Complete(ctx context.Context, id string, expiresAt time.Time) (*models.Upload, error)
+	// ListExpired returns up to limit uploads that expired before now, oldest first
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Upload, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// UploadConflict is the error of an AppendPart or Complete the state of upload does not allow
+func UploadConflict(upload *models.Upload) error {
+	if upload.Status == models.UploadStatusCompleted {
+		return apperrors.Conflict(apperrors.CodeUploadCompleted, "The upload is already completed")
+	}
+	return apperrors.Conflict(apperrors.CodeUploadOffsetMismatch, "The upload is at offset "+strconv.FormatInt(upload.Offset, 10))
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 	"time"
 )
@@ -31,10 +33,13 @@ func NewAuthenticationService(log *zerolog.Logger, repo repository.IAuthenticati
 
 func (a AuthenticationService) SaveRefreshToken(ctx context.Context, userID string, refreshToken string, tokenDuration time.Duration) error {
 	const kName = "SaveRefreshToken"
+	ctx, span := tracing.Start(ctx, "AuthenticationService", "SaveRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.log)
 
 	err := a.repo.SaveRefreshToken(ctx, userID, refreshToken, tokenDuration)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to save refresh token")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to save refresh token")
 		return err
 	}
 	return nil
@@ -42,10 +47,13 @@ func (a AuthenticationService) SaveRefreshToken(ctx context.Context, userID stri
 
 func (a AuthenticationService) GetUserIDFromRefreshToken(ctx context.Context, refreshToken string) (string, error) {
 	const kName = "GetUserIDFromRefreshToken"
+	ctx, span := tracing.Start(ctx, "AuthenticationService", "GetUserIDFromRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.log)
 
 	userID, err := a.repo.GetUserIDFromRefreshToken(ctx, refreshToken)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get user ID from refresh token")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to get user ID from refresh token")
 		return "", err
 	}
 	return userID, nil
@@ -53,19 +61,25 @@ func (a AuthenticationService) GetUserIDFromRefreshToken(ctx context.Context, re
 
 func (a AuthenticationService) UpdateUserRefreshToken(ctx context.Context, userID string, refreshToken string, tokenDuration time.Duration) error {
 	const kName = "UpdateUserRefreshToken"
+	ctx, span := tracing.Start(ctx, "AuthenticationService", "UpdateUserRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.log)
 
 	err := a.repo.SaveRefreshToken(ctx, userID, refreshToken, tokenDuration)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to update refresh token")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to update refresh token")
 		return err
 	}
 	return nil
 }
 func (a AuthenticationService) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
 	const kName = "RevokeRefreshToken"
+	ctx, span := tracing.Start(ctx, "AuthenticationService", "RevokeRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.log)
 	err := a.repo.RevokeRefreshToken(ctx, refreshToken)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to revoke refresh token")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to revoke refresh token")
 		return err
 	}
 	return nil
@@ -73,10 +87,13 @@ func (a AuthenticationService) RevokeRefreshToken(ctx context.Context, refreshTo
 
 func (a AuthenticationService) DeleteRefreshToken(ctx context.Context, refreshToken string) error {
 	const kName = "DeleteRefreshToken"
+	ctx, span := tracing.Start(ctx, "AuthenticationService", "DeleteRefreshToken")
+	defer span.End()
+	logger := logging.FromContext(ctx, a.log)
 
 	err := a.repo.DeleteRefreshToken(ctx, refreshToken)
 	if err != nil {
-		a.log.Error().Interface(kName, a.iName).Err(err).Msg("Failed to delete refresh token")
+		logger.Error().Interface(kName, a.iName).Err(err).Msg("Failed to delete refresh token")
 		return err
 	}
 	return nil
@@ -6,7 +6,11 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/persist"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
+	"sync/atomic"
 )
 
 // Define Effect constants
@@ -58,6 +62,14 @@ type IAuthorizationService interface {
 	Can(ctx context.Context, user *models.User, resource interface{}, action string) (bool, error)
 	// LoadPolicies adds policies to the adapter. it adds the default policies defined by code when called at runtime
 	LoadPolicies() error
+	// PoliciesLoaded reports whether LoadPolicies has completed successfully, used for readiness
+	PoliciesLoaded() bool
+	// AddPolicy adds a policy rule, it reports false when the rule already exists
+	AddPolicy(subRule string, obj string, act string, eft string) (bool, error)
+	// RemovePolicy removes a policy rule, it reports false when the rule does not exist
+	RemovePolicy(subRule string, obj string, act string, eft string) (bool, error)
+	// Policies lists the stored policy rules as [sub_rule, obj, act, eft]
+	Policies() ([][]string, error)
 }
 
 // casbinAuthorizationService handles authorization using Casbin
@@ -65,6 +77,7 @@ type casbinAuthorizationService struct {
 	iName    string
 	logger   *zerolog.Logger
 	enforcer *casbin.Enforcer
+	loaded   atomic.Bool
 }
 
 func NewCasbinAuthorizationService(log *zerolog.Logger, modelFilePath string, adapter persist.BatchAdapter) (IAuthorizationService, error) {
@@ -152,32 +165,46 @@ func (s *casbinAuthorizationService) LoadPolicies() error {
 	//	return err
 	//}
 	s.logger.Debug().Interface(kName, s.iName).Msg("finished policy additions")
+	s.loaded.Store(true)
 	return nil
 }
 
+func (s *casbinAuthorizationService) PoliciesLoaded() bool {
+	return s.loaded.Load()
+}
+
 // Can CheckPermission checks if a user has permission to perform an action on a resource
 func (s *casbinAuthorizationService) Can(ctx context.Context, user *models.User, resource interface{}, action string) (bool, error) {
 	const kName = "Can"
+	ctx, span := tracing.Start(ctx, "CasbinAuthorizationService", "Can")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.logger)
 
-	s.logger.Debug().Interface(kName, s.iName).Msg(action + " :: on user ID " + user.ID.Hex())
+	logger.Debug().Interface(kName, s.iName).Msg(action + " :: on user ID " + user.ID.Hex())
 
 	// For ABAC, we pass the actual objects rather than just IDs
 	allowed, err := s.enforcer.Enforce(UserWrapper{ID: user.ID.Hex()}, resource, action)
 	if err != nil {
 		return false, fmt.Errorf("failed to check permission: %v", err)
 	}
+	if !allowed {
+		metrics.AuthorizationDenials.WithLabelValues(action).Inc()
+	}
 
 	return allowed, nil
 }
 
 // AddPolicy adds a new policy rule
-func (s *casbinAuthorizationService) AddPolicy(subRule string, obj string, act string) error {
-	_, err := s.enforcer.AddPolicy(subRule, obj, act)
-	return err
+func (s *casbinAuthorizationService) AddPolicy(subRule string, obj string, act string, eft string) (bool, error) {
+	return s.enforcer.AddPolicy(subRule, obj, act, eft)
 }
 
 // RemovePolicy removes a policy rule
-func (s *casbinAuthorizationService) RemovePolicy(subRule string, obj string, act string) error {
-	_, err := s.enforcer.RemovePolicy(subRule, obj, act)
-	return err
+func (s *casbinAuthorizationService) RemovePolicy(subRule string, obj string, act string, eft string) (bool, error) {
+	return s.enforcer.RemovePolicy(subRule, obj, act, eft)
+}
+
+// Policies lists the policy rules
+func (s *casbinAuthorizationService) Policies() ([][]string, error) {
+	return s.enforcer.GetPolicy()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 )
 
 type chatGroupService interface {
@@ -25,22 +26,32 @@ func NewChatGroupService(repo repository.ChatGroupRepository) *ChatGroupService
 }
 
 func (c ChatGroupService) Create(ctx context.Context, chatGroup *models.ChatGroup) error {
+	ctx, span := tracing.Start(ctx, "ChatGroupService", "Create")
+	defer span.End()
 	return c.repo.Create(ctx, chatGroup)
 }
 
 func (c ChatGroupService) GetById(ctx context.Context, chatGroupId string) (*models.ChatGroup, error) {
+	ctx, span := tracing.Start(ctx, "ChatGroupService", "GetById")
+	defer span.End()
 	return c.repo.GetByID(ctx, chatGroupId)
 }
 
 func (c ChatGroupService) List(ctx context.Context, page, limit int) ([]models.ChatGroup, error) {
+	ctx, span := tracing.Start(ctx, "ChatGroupService", "List")
+	defer span.End()
 	return c.repo.List(ctx, page, limit)
 }
 
 func (c ChatGroupService) Update(ctx context.Context, chatGroup *models.ChatGroup) error {
+	ctx, span := tracing.Start(ctx, "ChatGroupService", "Update")
+	defer span.End()
 	return c.repo.Update(ctx, chatGroup)
 }
 
 func (c ChatGroupService) UpdateGroupName(ctx context.Context, chatGroupId string, newGroupName string) error {
+	ctx, span := tracing.Start(ctx, "ChatGroupService", "UpdateGroupName")
+	defer span.End()
 	updateData := map[string]interface{}{
 		"groupName": newGroupName,
 	}
@@ -62,5 +73,7 @@ func (c ChatGroupService) UpdateGroupName(ctx context.Context, chatGroupId strin
 //}
 
 func (c ChatGroupService) Delete(ctx context.Context, chatGroupId string) error {
+	ctx, span := tracing.Start(ctx, "ChatGroupService", "Delete")
+	defer span.End()
 	return c.repo.Delete(ctx, chatGroupId)
 }
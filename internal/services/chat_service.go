@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 )
 
 type chatService interface {
@@ -12,27 +13,37 @@ type chatService interface {
 	ListByUserId(ctx context.Context, userId string) ([]models.Chat, error)
 	//UpdateChat(ctx context.Context, chat *models.Chat) error
 	DeleteChat(ctx context.Context, chatId string) error
+	ShredChat(ctx context.Context, chatId string) error
 }
 
 type ChatService struct {
-	repo repository.ChatRepository
+	repo        repository.ChatRepository
+	chatKeyRepo repository.IChatKeyRepository
 }
 
-func NewChatService(repo repository.ChatRepository) *ChatService {
+func NewChatService(repo repository.ChatRepository, chatKeyRepo repository.IChatKeyRepository) *ChatService {
 	return &ChatService{
-		repo: repo,
+		repo:        repo,
+		chatKeyRepo: chatKeyRepo,
 	}
 }
 
 func (c ChatService) CreateChat(ctx context.Context, chat *models.Chat) error {
-	return c.repo.Create(ctx, chat)
+	ctx, span := tracing.Start(ctx, "ChatService", "CreateChat")
+	defer span.End()
+	_, err := c.repo.Create(ctx, chat)
+	return err
 }
 
 func (c ChatService) GetChat(ctx context.Context, chatId string) (*models.Chat, error) {
+	ctx, span := tracing.Start(ctx, "ChatService", "GetChat")
+	defer span.End()
 	return c.repo.GetByID(ctx, chatId)
 }
 
 func (c ChatService) ListByUserId(ctx context.Context, userId string, page, limit int) ([]models.Chat, error) {
+	ctx, span := tracing.Start(ctx, "ChatService", "ListByUserId")
+	defer span.End()
 	return c.repo.ListByUserId(ctx, userId, page, limit)
 }
 
@@ -40,6 +51,20 @@ func (c ChatService) ListByUserId(ctx context.Context, userId string, page, limi
 //	panic("implement me")
 //}
 
+// DeleteChat deletes the chat and shreds its messages
 func (c ChatService) DeleteChat(ctx context.Context, chatId string) error {
-	return c.repo.Delete(ctx, chatId)
+	ctx, span := tracing.Start(ctx, "ChatService", "DeleteChat")
+	defer span.End()
+	err := c.repo.Delete(ctx, chatId)
+	if err != nil {
+		return err
+	}
+	return c.ShredChat(ctx, chatId)
+}
+
+// ShredChat destroys the chat's data key, every message stored for the chat becomes permanently unreadable
+func (c ChatService) ShredChat(ctx context.Context, chatId string) error {
+	ctx, span := tracing.Start(ctx, "ChatService", "ShredChat")
+	defer span.End()
+	return c.chatKeyRepo.DeleteByChatID(ctx, chatId)
 }
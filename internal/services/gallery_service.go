@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Defined gallery filters, the tabs of the shared media of a chat
+const (
+	GalleryPhotos    = "photos"
+	GalleryVideos    = "videos"
+	GalleryDocuments = "documents"
+	GalleryVoice     = "voice"
+	GalleryLinks     = "links"
+)
+
+// galleryMediaTypes maps the gallery filters listing media to their Media.MediaType
+var galleryMediaTypes = map[string]string{
+	GalleryPhotos:    models.MediaTypeImage,
+	GalleryVideos:    models.MediaTypeVideo,
+	GalleryDocuments: models.MediaTypeDocument,
+	GalleryVoice:     models.MediaTypeAudio,
+}
+
+const (
+	defaultGalleryLimit = 30
+	maxGalleryLimit     = 100
+)
+
+// linkPattern matches the web links of message content
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+type IGalleryService interface {
+	// GetChatGallery lists the media or links shared in a chat userId takes part in, newest first. It continues
+	// after the item of cursor unless it is empty, a limit of 0 is the default page size.
+	GetChatGallery(ctx context.Context, userId string, chatId string, filter string, cursor string, limit int) (*ChatGallery, error)
+}
+
+// ChatGallery is a page of the shared media or links of a chat
+type ChatGallery struct {
+	Items      []GalleryItem
+	NextCursor string // empty on the last page
+}
+
+// GalleryItem is a media or the links of the message that shared it
+type GalleryItem struct {
+	MessageID primitive.ObjectID
+	SentAt    time.Time
+	Media     *models.Media // set unless listing links
+	Links     []string      // set when listing links
+}
+
+type GalleryService struct {
+	iName     string
+	log       *zerolog.Logger
+	mediaRepo repository.MediaRepository
+	msgRepo   repository.MessageRepository
+	chatRepo  repository.ChatRepository
+	urls      *MediaURLs
+}
+
+func NewGalleryService(log *zerolog.Logger, mediaRepo repository.MediaRepository, msgRepo repository.MessageRepository, chatRepo repository.ChatRepository, urls *MediaURLs) *GalleryService {
+	return &GalleryService{
+		iName:     "GalleryService",
+		log:       log,
+		mediaRepo: mediaRepo,
+		msgRepo:   msgRepo,
+		chatRepo:  chatRepo,
+		urls:      urls,
+	}
+}
+
+func (g *GalleryService) GetChatGallery(ctx context.Context, userId string, chatId string, filter string, cursor string, limit int) (*ChatGallery, error) {
+	const kName = "GetChatGallery"
+	ctx, span := tracing.Start(ctx, "GalleryService", "GetChatGallery")
+	defer span.End()
+	logger := logging.FromContext(ctx, g.log)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id")
+	}
+	mediaType, listsMedia := galleryMediaTypes[filter]
+	if !listsMedia && filter != GalleryLinks {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Invalid gallery type",
+			apperrors.InvalidField("type", "must be one of photos, videos, documents, voice or links"))
+	}
+	if cursor != "" && !primitive.IsValidObjectID(cursor) {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Invalid cursor", apperrors.InvalidField("cursor", "must be a cursor returned by the gallery"))
+	}
+	if limit <= 0 {
+		limit = defaultGalleryLimit
+	}
+	limit = min(limit, maxGalleryLimit)
+	if _, err = participantChat(ctx, g.chatRepo, chatId, userID); err != nil {
+		return nil, err
+	}
+
+	var gallery *ChatGallery
+	if listsMedia {
+		gallery, err = g.mediaPage(ctx, chatId, mediaType, cursor, limit)
+	} else {
+		gallery, err = g.linkPage(ctx, chatId, cursor, limit)
+	}
+	if err != nil {
+		logger.Error().Interface(kName, g.iName).Err(err).Str("chatId", chatId).Str("type", filter).Msg("Failed to list chat gallery")
+		return nil, err
+	}
+	return gallery, nil
+}
+
+// mediaPage lists a page of the attached media of mediaType, one more than limit tells whether a next page exists
+func (g *GalleryService) mediaPage(ctx context.Context, chatId string, mediaType string, cursor string, limit int) (*ChatGallery, error) {
+	medias, err := g.mediaRepo.ListGallery(ctx, chatId, mediaType, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	gallery := &ChatGallery{}
+	if len(medias) > limit {
+		medias = medias[:limit]
+		gallery.NextCursor = medias[limit-1].Id.Hex()
+	}
+	for i := range medias {
+		media := &medias[i]
+		g.urls.Sign(media)
+		gallery.Items = append(gallery.Items, GalleryItem{
+			MessageID: media.MessageId,
+			SentAt:    media.MessageId.Timestamp(),
+			Media:     media,
+		})
+	}
+	return gallery, nil
+}
+
+// linkPage lists a page of the messages with links. The links are extracted again as edits may have removed them,
+// so a page can hold fewer items than limit while a next page exists.
+func (g *GalleryService) linkPage(ctx context.Context, chatId string, cursor string, limit int) (*ChatGallery, error) {
+	messages, err := g.msgRepo.ListWithLinks(ctx, chatId, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	gallery := &ChatGallery{}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		gallery.NextCursor = messages[limit-1].ID.Hex()
+	}
+	for _, message := range messages {
+		// messages of a shredded chat stay encrypted & hold nothing to show
+		if message.Encrypted {
+			continue
+		}
+		if links := messageLinks(message.Content); len(links) > 0 {
+			gallery.Items = append(gallery.Items, GalleryItem{
+				MessageID: message.ID,
+				SentAt:    message.Timestamp.Time(),
+				Links:     links,
+			})
+		}
+	}
+	return gallery, nil
+}
+
+// messageLinks returns the distinct web links of message content in order, without trailing punctuation
+func messageLinks(content string) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, link := range linkPattern.FindAllString(content, -1) {
+		link = strings.TrimRight(link, ".,;:!?)]}'")
+		if _, host, _ := strings.Cut(link, "://"); host != "" && !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// healthCheckTimeout bounds every individual readiness check so one hung dependency cannot stall the probe
+const healthCheckTimeout = 2 * time.Second
+
+// IHealthChecker is implemented by any subsystem that must be healthy before the server receives traffic
+type IHealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// HealthCheckerFunc adapts a function to IHealthChecker
+type HealthCheckerFunc struct {
+	CheckName string
+	Fn        func(ctx context.Context) error
+}
+
+func (h HealthCheckerFunc) Name() string {
+	return h.CheckName
+}
+
+func (h HealthCheckerFunc) Check(ctx context.Context) error {
+	return h.Fn(ctx)
+}
+
+type IHealthService interface {
+	// Register adds a readiness checker
+	Register(checker IHealthChecker)
+	// Live reports whether the process is running, it never checks dependencies
+	Live() *models.HealthReport
+	// Ready runs every registered checker concurrently
+	Ready(ctx context.Context) *models.HealthReport
+	// SetShuttingDown makes Ready fail so the orchestrator stops routing traffic before connections are drained
+	SetShuttingDown()
+}
+
+type HealthService struct {
+	iName        string
+	log          *zerolog.Logger
+	mu           sync.RWMutex
+	checkers     []IHealthChecker
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(log *zerolog.Logger, checkers ...IHealthChecker) IHealthService {
+	return &HealthService{
+		iName:    "HealthService",
+		log:      log,
+		checkers: checkers,
+	}
+}
+
+func (h *HealthService) Register(checker IHealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checkers = append(h.checkers, checker)
+}
+
+func (h *HealthService) Live() *models.HealthReport {
+	return &models.HealthReport{Status: models.HealthStatusUp}
+}
+
+func (h *HealthService) Ready(ctx context.Context) *models.HealthReport {
+	const kName = "Ready"
+	logger := logging.FromContext(ctx, h.log)
+
+	h.mu.RLock()
+	checkers := append([]IHealthChecker(nil), h.checkers...)
+	h.mu.RUnlock()
+
+	results := make([]models.HealthCheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	if h.shuttingDown.Load() {
+		results = append(results, models.HealthCheckResult{Name: "shutdown", Status: models.HealthStatusDown, Error: "server is shutting down"})
+	}
+
+	report := &models.HealthReport{Status: models.HealthStatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status != models.HealthStatusUp {
+			report.Status = models.HealthStatusDown
+			logger.Warn().Interface(kName, h.iName).Str("check", result.Name).Str("error", result.Error).Msg("readiness check failed")
+		}
+	}
+	return report
+}
+
+func (h *HealthService) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func runHealthCheck(ctx context.Context, checker IHealthChecker) models.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := models.HealthCheckResult{
+		Name:      checker.Name(),
+		Status:    models.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = models.HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// NewAuthorizationHealthChecker reports down until the authorization policies have been loaded
+func NewAuthorizationHealthChecker(authzSvc IAuthorizationService) IHealthChecker {
+	return HealthCheckerFunc{
+		CheckName: "authorization",
+		Fn: func(ctx context.Context) error {
+			if !authzSvc.PoliciesLoaded() {
+				return errors.New("authorization policies not loaded")
+			}
+			return nil
+		},
+	}
+}
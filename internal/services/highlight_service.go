@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 )
 
 type highlightService interface {
@@ -23,21 +24,31 @@ func NewHighlightService(repo repository.HighlightRepository) *HighlightService
 }
 
 func (h *HighlightService) Create(ctx context.Context, highlight *models.Highlight) error {
+	ctx, span := tracing.Start(ctx, "HighlightService", "Create")
+	defer span.End()
 	return h.repo.Create(ctx, highlight)
 }
 
 func (h *HighlightService) GetHighlightById(ctx context.Context, id string) (*models.Highlight, error) {
+	ctx, span := tracing.Start(ctx, "HighlightService", "GetHighlightById")
+	defer span.End()
 	return h.repo.GetByID(ctx, id)
 }
 
 func (h *HighlightService) GetAllHighlightByUserId(ctx context.Context, userId string, page, limit int) ([]models.Highlight, error) {
+	ctx, span := tracing.Start(ctx, "HighlightService", "GetAllHighlightByUserId")
+	defer span.End()
 	return h.repo.GetByUserId(ctx, userId, page, limit)
 }
 
 func (h *HighlightService) UpdateHighlight(ctx context.Context, highlight *models.Highlight) error {
+	ctx, span := tracing.Start(ctx, "HighlightService", "UpdateHighlight")
+	defer span.End()
 	return h.repo.Update(ctx, highlight)
 }
 
 func (h *HighlightService) DeleteHighlightById(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "HighlightService", "DeleteHighlightById")
+	defer span.End()
 	return h.repo.Delete(ctx, id)
 }
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// IPreKeyAlertNotifier is told when a device's one-time prekeys fall below models.PreKeyLowWatermark
+type IPreKeyAlertNotifier interface {
+	NotifyLowPreKeys(ctx context.Context, userId string, deviceId string, remaining int64)
+}
+
+// IKeyDistributionService distributes the public keys devices need to establish end-to-end encrypted sessions.
+// The server never sees private keys or plaintext, it only relays opaque ciphertext.
+type IKeyDistributionService interface {
+	PublishKeys(ctx context.Context, user *models.User, deviceId string, req *models.PublishKeysRequest) (*models.PreKeyCountResponse, error)
+	UploadOneTimePreKeys(ctx context.Context, user *models.User, deviceId string, preKeys []models.OneTimePreKey) (*models.PreKeyCountResponse, error)
+	GetPreKeyCount(ctx context.Context, user *models.User, deviceId string) (*models.PreKeyCountResponse, error)
+	// GetPreKeyBundles returns one bundle per device of the peer, consuming a one-time prekey for each. Only the users
+	// sharing a chat with the peer may fetch them, at most bundleFetchLimit times per bundleFetchWindow.
+	GetPreKeyBundles(ctx context.Context, user *models.User, peerUserId string) ([]models.PreKeyBundle, error)
+	RemoveDevice(ctx context.Context, user *models.User, deviceId string) error
+}
+
+// a client fetches the bundles of a peer once per session it establishes, every fetch consumes the peer's one-time
+// prekeys, so fetching more often would drain them
+const (
+	bundleFetchLimit  = 10
+	bundleFetchWindow = time.Hour
+)
+
+// maxDevicesPerUser bounds the devices a user can publish keys for, since clients choose their device ids & every
+// device receives a copy of each message sent to the user
+const maxDevicesPerUser = 10
+
+type KeyDistributionService struct {
+	iName         string
+	log           *zerolog.Logger
+	repo          repository.IDeviceKeyRepository
+	chatRepo      repository.ChatRepository
+	notifier      IPreKeyAlertNotifier
+	bundleFetches *rateLimiter // by user & peer
+}
+
+func NewKeyDistributionService(log *zerolog.Logger, repo repository.IDeviceKeyRepository, chatRepo repository.ChatRepository, rateLimitRepo repository.IRateLimitRepository, notifier IPreKeyAlertNotifier) IKeyDistributionService {
+	return &KeyDistributionService{
+		iName:         "KeyDistributionService",
+		log:           log,
+		repo:          repo,
+		chatRepo:      chatRepo,
+		notifier:      notifier,
+		bundleFetches: newRateLimiter(rateLimitRepo, bundleFetchLimit, bundleFetchWindow),
+	}
+}
+
+func (k *KeyDistributionService) PublishKeys(ctx context.Context, user *models.User, deviceId string, req *models.PublishKeysRequest) (*models.PreKeyCountResponse, error) {
+	const kName = "PublishKeys"
+	ctx, span := tracing.Start(ctx, "KeyDistributionService", "PublishKeys")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.log)
+
+	var fields []apperrors.FieldError
+	if deviceId == "" {
+		fields = append(fields, apperrors.RequiredField("deviceId"))
+	}
+	if req.IdentityKey == "" {
+		fields = append(fields, apperrors.RequiredField("identityKey"))
+	}
+	if req.SignedPreKey.PublicKey == "" {
+		fields = append(fields, apperrors.RequiredField("signedPreKey.publicKey"))
+	}
+	if req.SignedPreKey.Signature == "" {
+		fields = append(fields, apperrors.RequiredField("signedPreKey.signature"))
+	}
+	if len(fields) > 0 {
+		logger.Error().Interface(kName, k.iName).Msg("incomplete key bundle")
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Incomplete key bundle", fields...)
+	}
+
+	current, err := k.repo.GetByUserIDAndDeviceID(ctx, user.ID.Hex(), deviceId)
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		devices, err := k.repo.GetByUserID(ctx, user.ID.Hex())
+		if err != nil {
+			logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to get user devices")
+			return nil, err
+		}
+		if len(devices) >= maxDevicesPerUser {
+			logger.Warn().Interface(kName, k.iName).Str("deviceId", deviceId).Int("devices", len(devices)).Msg("device limit reached")
+			return nil, apperrors.Conflict(apperrors.CodeTooManyDevices, fmt.Sprintf("A user can have at most %d devices, remove one first", maxDevicesPerUser))
+		}
+	case err != nil:
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to get device keys")
+		return nil, err
+	case current.IdentityKey != req.IdentityKey:
+		// peers must verify the new identity again, so every rotation is kept in the audit trail
+		logger.Info().Interface(kName, k.iName).Str("audit", "identity_key_rotated").Str("userId", user.ID.Hex()).Str("deviceId", deviceId).Msg("device identity key rotated")
+	}
+
+	_, err = k.repo.Upsert(ctx, &models.DeviceKey{
+		UserID:       user.ID,
+		DeviceID:     deviceId,
+		IdentityKey:  req.IdentityKey,
+		SignedPreKey: req.SignedPreKey,
+	})
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to publish device keys")
+		return nil, err
+	}
+
+	return k.UploadOneTimePreKeys(ctx, user, deviceId, req.OneTimePreKeys)
+}
+
+func (k *KeyDistributionService) UploadOneTimePreKeys(ctx context.Context, user *models.User, deviceId string, preKeys []models.OneTimePreKey) (*models.PreKeyCountResponse, error) {
+	const kName = "UploadOneTimePreKeys"
+	ctx, span := tracing.Start(ctx, "KeyDistributionService", "UploadOneTimePreKeys")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.log)
+
+	// one-time prekeys can only be added for a device that has published its identity
+	device, err := k.repo.GetByUserIDAndDeviceID(ctx, user.ID.Hex(), deviceId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("device has not published its identity key")
+		return nil, err
+	}
+
+	var fields []apperrors.FieldError
+	for i, preKey := range preKeys {
+		if preKey.PublicKey == "" {
+			logger.Error().Interface(kName, k.iName).Int("keyId", preKey.KeyID).Msg("one-time prekey has no public key")
+			fields = append(fields, apperrors.RequiredField(fmt.Sprintf("oneTimePreKeys[%d].publicKey", i)))
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "One-time prekeys require a public key", fields...)
+	}
+
+	err = k.repo.AddOneTimePreKeys(ctx, user.ID.Hex(), deviceId, device.IdentityKey, preKeys)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to upload one-time prekeys")
+		return nil, err
+	}
+	return k.GetPreKeyCount(ctx, user, deviceId)
+}
+
+func (k *KeyDistributionService) GetPreKeyCount(ctx context.Context, user *models.User, deviceId string) (*models.PreKeyCountResponse, error) {
+	const kName = "GetPreKeyCount"
+	ctx, span := tracing.Start(ctx, "KeyDistributionService", "GetPreKeyCount")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.log)
+
+	remaining, err := k.repo.CountOneTimePreKeys(ctx, user.ID.Hex(), deviceId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to count one-time prekeys")
+		return nil, err
+	}
+	return &models.PreKeyCountResponse{
+		DeviceID:  deviceId,
+		Remaining: remaining,
+		Replenish: remaining < models.PreKeyLowWatermark,
+	}, nil
+}
+
+func (k *KeyDistributionService) GetPreKeyBundles(ctx context.Context, user *models.User, peerUserId string) ([]models.PreKeyBundle, error) {
+	const kName = "GetPreKeyBundles"
+	ctx, span := tracing.Start(ctx, "KeyDistributionService", "GetPreKeyBundles")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.log)
+
+	peerID, err := primitive.ObjectIDFromHex(peerUserId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id", apperrors.InvalidField("userId", "must be a valid id"))
+	}
+	// the user's own other devices are always reachable
+	if peerID != user.ID {
+		permitted, err := k.sharesChat(ctx, user.ID, peerID)
+		if err != nil {
+			logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to check the chats shared with peer")
+			return nil, err
+		}
+		if !permitted {
+			logger.Warn().Interface(kName, k.iName).Str("peerUserId", peerUserId).Msg("prekey bundles requested for a peer sharing no chat")
+			return nil, apperrors.Forbidden(apperrors.CodeForbidden, "Prekey bundles are only available for users sharing a chat")
+		}
+	}
+	allowed, err := k.bundleFetches.allow(ctx, "prekeyBundles/"+user.ID.Hex()+"/"+peerUserId, time.Now())
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to count prekey bundle fetches")
+		return nil, err
+	}
+	if !allowed {
+		logger.Warn().Interface(kName, k.iName).Str("peerUserId", peerUserId).Msg("prekey bundle fetches rate limited")
+		return nil, apperrors.RateLimited(apperrors.CodeRateLimited, "Too many prekey bundle requests for this user, try again later")
+	}
+
+	devices, err := k.repo.GetByUserID(ctx, peerUserId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to get peer devices")
+		return nil, err
+	}
+
+	bundles := make([]models.PreKeyBundle, 0, len(devices))
+	for _, device := range devices {
+		preKey, err := k.repo.ConsumeOneTimePreKey(ctx, peerUserId, device.DeviceID, device.IdentityKey)
+		if err != nil {
+			logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to consume one-time prekey")
+			return nil, err
+		}
+		bundles = append(bundles, models.PreKeyBundle{
+			UserID:        device.UserID,
+			DeviceID:      device.DeviceID,
+			IdentityKey:   device.IdentityKey,
+			SignedPreKey:  device.SignedPreKey,
+			OneTimePreKey: preKey,
+		})
+		k.alertIfLow(ctx, peerUserId, device.DeviceID)
+	}
+	return bundles, nil
+}
+
+// sharesChat reports whether userID & peerID take part in a common chat, which permits messaging the peer
+func (k *KeyDistributionService) sharesChat(ctx context.Context, userID primitive.ObjectID, peerID primitive.ObjectID) (bool, error) {
+	chats, err := k.chatRepo.ListByUserId(ctx, userID.Hex(), 1, 0)
+	if err != nil {
+		return false, err
+	}
+	for i := range chats {
+		if chats[i].HasParticipant(peerID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (k *KeyDistributionService) RemoveDevice(ctx context.Context, user *models.User, deviceId string) error {
+	const kName = "RemoveDevice"
+	ctx, span := tracing.Start(ctx, "KeyDistributionService", "RemoveDevice")
+	defer span.End()
+	logger := logging.FromContext(ctx, k.log)
+
+	err := k.repo.DeleteByUserIDAndDeviceID(ctx, user.ID.Hex(), deviceId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to remove device keys")
+		return err
+	}
+	return nil
+}
+
+// alertIfLow notifies the device owner once its one-time prekeys run low, errors are only logged
+// since the bundle has already been handed out
+func (k *KeyDistributionService) alertIfLow(ctx context.Context, userId string, deviceId string) {
+	const kName = "alertIfLow"
+	logger := logging.FromContext(ctx, k.log)
+
+	remaining, err := k.repo.CountOneTimePreKeys(ctx, userId, deviceId)
+	if err != nil {
+		logger.Error().Interface(kName, k.iName).Err(err).Msg("failed to count one-time prekeys")
+		return
+	}
+	if remaining < models.PreKeyLowWatermark && k.notifier != nil {
+		k.notifier.NotifyLowPreKeys(ctx, userId, deviceId, remaining)
+	}
+}
+
+// LogPreKeyAlertNotifier logs low prekey alerts until a real-time channel can deliver them to the device
+type LogPreKeyAlertNotifier struct {
+	iName string
+	log   *zerolog.Logger
+}
+
+func NewLogPreKeyAlertNotifier(log *zerolog.Logger) IPreKeyAlertNotifier {
+	return &LogPreKeyAlertNotifier{
+		iName: "LogPreKeyAlertNotifier",
+		log:   log,
+	}
+}
+
+func (l *LogPreKeyAlertNotifier) NotifyLowPreKeys(ctx context.Context, userId string, deviceId string, remaining int64) {
+	const kName = "NotifyLowPreKeys"
+	ctx, span := tracing.Start(ctx, "LogPreKeyAlertNotifier", "NotifyLowPreKeys")
+	defer span.End()
+	logger := logging.FromContext(ctx, l.log)
+	logger.Warn().Interface(kName, l.iName).Str("userId", userId).Str("deviceId", deviceId).Int64("remaining", remaining).Msg("device is running low on one-time prekeys")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/memory"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"testing"
+)
+
+func TestGetPreKeyBundles(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	chats := memory.NewChatRepository()
+	keys := NewKeyDistributionService(&log, memory.NewDeviceKeyRepository(), chats, memory.NewRateLimitRepository(), nil)
+	alice, bob, mallory := &models.User{ID: primitive.NewObjectID()}, &models.User{ID: primitive.NewObjectID()}, &models.User{ID: primitive.NewObjectID()}
+	if _, err := chats.Create(ctx, &models.Chat{Type: models.ChatTypeDirect, Participants: []primitive.ObjectID{alice.ID, bob.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	preKeys := make([]models.OneTimePreKey, 20)
+	for i := range preKeys {
+		preKeys[i] = models.OneTimePreKey{KeyID: i + 1, PublicKey: "pk"}
+	}
+	_, err := keys.PublishKeys(ctx, bob, "phone", &models.PublishKeysRequest{
+		IdentityKey:    "identity",
+		SignedPreKey:   models.SignedPreKey{KeyID: 1, PublicKey: "spk", Signature: "sig"},
+		OneTimePreKeys: preKeys,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("users sharing no chat are forbidden", func(t *testing.T) {
+		_, err := keys.GetPreKeyBundles(ctx, mallory, bob.ID.Hex())
+		if !errors.Is(err, apperrors.ErrForbidden) {
+			t.Fatalf("expected a forbidden error, got %v", err)
+		}
+		count, err := keys.GetPreKeyCount(ctx, bob, "phone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count.Remaining != int64(len(preKeys)) {
+			t.Fatalf("expected no prekey consumed, %d left", count.Remaining)
+		}
+	})
+
+	t.Run("fetches are rate limited per peer", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			bundles, err := keys.GetPreKeyBundles(ctx, alice, bob.ID.Hex())
+			if err != nil {
+				t.Fatalf("fetch %d: %v", i, err)
+			}
+			if len(bundles) != 1 || bundles[0].OneTimePreKey == nil {
+				t.Fatalf("fetch %d: expected one bundle with a one-time prekey, got %+v", i, bundles)
+			}
+		}
+		_, err := keys.GetPreKeyBundles(ctx, alice, bob.ID.Hex())
+		if !errors.Is(err, apperrors.ErrRateLimited) {
+			t.Fatalf("expected a rate limited error, got %v", err)
+		}
+		if status := apperrors.From(err).HTTPStatus(); status != http.StatusTooManyRequests {
+			t.Fatalf("expected status 429, got %d", status)
+		}
+		// the user's own devices are another peer
+		if _, err = keys.GetPreKeyBundles(ctx, bob, bob.ID.Hex()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPublishKeysDeviceLimit(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	keys := NewKeyDistributionService(&log, memory.NewDeviceKeyRepository(), memory.NewChatRepository(), memory.NewRateLimitRepository(), nil)
+	user := &models.User{ID: primitive.NewObjectID()}
+	publish := func(deviceId, identityKey string) error {
+		_, err := keys.PublishKeys(ctx, user, deviceId, &models.PublishKeysRequest{
+			IdentityKey:  identityKey,
+			SignedPreKey: models.SignedPreKey{KeyID: 1, PublicKey: "spk", Signature: "sig"},
+		})
+		return err
+	}
+	for i := 0; i < 10; i++ {
+		if err := publish(fmt.Sprintf("device-%d", i), "identity"); err != nil {
+			t.Fatalf("device %d: %v", i, err)
+		}
+	}
+
+	err := publish("device-10", "identity")
+	requireCode(t, err, apperrors.CodeTooManyDevices)
+	// known devices still rotate their keys, & a removed device frees a slot
+	if err = publish("device-0", "rotated"); err != nil {
+		t.Fatalf("rotating a known device: %v", err)
+	}
+	if err = keys.RemoveDevice(ctx, user, "device-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = publish("device-10", "identity"); err != nil {
+		t.Fatalf("publishing after a removal: %v", err)
+	}
+}
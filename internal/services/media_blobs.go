@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"time"
+)
+
+// collectBatch is the number of unreferenced blobs deleted per query
+const collectBatch = 100
+
+// MediaBlobs stores the content of media once per owner & file. Content is found by its SHA-256, identical
+// uploads of an owner reference the same blob, while other users never share it & cannot probe for files.
+type MediaBlobs struct {
+	iName      string
+	log        *zerolog.Logger
+	repo       repository.IBlobRepository
+	blobStore  storage.IBlobStore
+	processing *MediaProcessing
+	quarantine bool // new content is pending until MediaScanning scanned it
+}
+
+func NewMediaBlobs(log *zerolog.Logger, repo repository.IBlobRepository, blobStore storage.IBlobStore, processing *MediaProcessing, quarantine bool) *MediaBlobs {
+	return &MediaBlobs{
+		iName:      "MediaBlobs",
+		log:        log,
+		repo:       repo,
+		blobStore:  blobStore,
+		processing: processing,
+		quarantine: quarantine,
+	}
+}
+
+// Store streams content into the blob store & attaches a blob of the sender of media holding it, reusing the blob
+// of an identical upload. It returns the number of bytes read from content, errors of content are returned as is.
+// The media holds a reference to the blob, which is released with Release.
+func (b *MediaBlobs) Store(ctx context.Context, media *models.Media, content io.Reader) (int64, error) {
+	const kName = "Store"
+	ctx, span := tracing.Start(ctx, "MediaBlobs", "Store")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.log)
+
+	// the hash is only known once the content is stored, so it is stored under a key of its own first
+	blob := &models.Blob{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     media.SenderId,
+		ContentType: media.ContentType,
+	}
+	if b.quarantine {
+		blob.ScanStatus = models.ScanStatusPending
+	}
+	blob.StorageKey = "blobs/" + blob.OwnerID.Hex() + "/" + blob.ID.Hex()
+	hash := sha256.New()
+	size, err := b.blobStore.Put(ctx, blob.StorageKey, io.TeeReader(content, hash), media.ContentType)
+	if err != nil {
+		return 0, err
+	}
+	blob.Hash = hex.EncodeToString(hash.Sum(nil))
+	blob.Size = size
+
+	existing, err := b.repo.Acquire(ctx, blob.OwnerID.Hex(), blob.Hash)
+	if err == nil {
+		logger.Debug().Interface(kName, b.iName).Str("blobId", existing.ID.Hex()).Msg("Reusing blob of an identical upload")
+		b.deleteKeys(ctx, blob.StorageKey)
+		b.attach(media, existing)
+		return size, nil
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		b.deleteKeys(ctx, blob.StorageKey)
+		return 0, err
+	}
+
+	if err = b.processing.Process(ctx, blob); err == nil {
+		var created *models.Blob
+		if created, err = b.repo.Create(ctx, blob); err == nil {
+			b.attach(media, created)
+			return size, nil
+		}
+	}
+	b.deleteKeys(ctx, blobKeys(blob.StorageKey, blob.Thumbnails)...)
+	if !errors.Is(err, apperrors.ErrConflict) {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("Failed to record blob")
+		return 0, err
+	}
+	// a concurrent upload of the same file recorded its blob first
+	if existing, err = b.repo.Acquire(ctx, blob.OwnerID.Hex(), blob.Hash); err != nil {
+		logger.Error().Interface(kName, b.iName).Err(err).Msg("Failed to acquire blob of a concurrent upload")
+		return 0, err
+	}
+	b.attach(media, existing)
+	return size, nil
+}
+
+// Release drops the reference of media to its blob, the content is deleted by CollectGarbage once no media
+// references it. The content of media stored before deduplication is deleted right away.
+func (b *MediaBlobs) Release(ctx context.Context, media *models.Media) {
+	if media.BlobID.IsZero() {
+		b.deleteKeys(ctx, blobKeys(media.StorageKey, media.Thumbnails)...)
+		return
+	}
+	if err := b.repo.Release(context.WithoutCancel(ctx), media.BlobID.Hex()); err != nil {
+		logging.FromContext(ctx, b.log).Error().Interface("Release", b.iName).Err(err).Str("blobId", media.BlobID.Hex()).Msg("Failed to release blob")
+	}
+}
+
+// CollectGarbage deletes the blobs no media references every interval until ctx is done
+func (b *MediaBlobs) CollectGarbage(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			b.collectGarbage(ctx)
+		}
+	}
+}
+
+// collectGarbage deletes the blobs without references so far
+func (b *MediaBlobs) collectGarbage(ctx context.Context) {
+	const kName = "collectGarbage"
+	ctx, span := tracing.Start(ctx, "MediaBlobs", "CollectGarbage")
+	defer span.End()
+	logger := logging.FromContext(ctx, b.log)
+
+	collected := 0
+	for {
+		blobs, err := b.repo.ListUnreferenced(ctx, collectBatch)
+		if err != nil {
+			logger.Error().Interface(kName, b.iName).Err(err).Msg("Failed to list unreferenced blobs")
+			return
+		}
+		for _, blob := range blobs {
+			// the record goes first, its conditional delete loses to an upload acquiring the blob in between
+			err = b.repo.DeleteUnreferenced(ctx, blob.ID.Hex())
+			if errors.Is(err, apperrors.ErrConflict) || errors.Is(err, apperrors.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				logger.Error().Interface(kName, b.iName).Err(err).Str("blobId", blob.ID.Hex()).Msg("Failed to delete unreferenced blob")
+				return
+			}
+			b.deleteKeys(ctx, blobKeys(blob.StorageKey, blob.Thumbnails)...)
+			collected++
+		}
+		if len(blobs) < collectBatch {
+			break
+		}
+	}
+	if collected > 0 {
+		logger.Info().Interface(kName, b.iName).Int("blobs", collected).Msg("Deleted unreferenced blobs")
+	}
+}
+
+// deleteKeys deletes keys from the blob store, failures only leave wasted space & are logged
+func (b *MediaBlobs) deleteKeys(ctx context.Context, keys ...string) {
+	logger := logging.FromContext(ctx, b.log)
+	for _, key := range keys {
+		if err := b.blobStore.Delete(context.WithoutCancel(ctx), key); err != nil {
+			logger.Error().Interface("deleteKeys", b.iName).Err(err).Str("key", key).Msg("Failed to delete blob content")
+		}
+	}
+}
+
+// attach points media at the content, metadata & scan verdict of blob
+func attach(media *models.Media, blob *models.Blob) {
+	media.BlobID = blob.ID
+	media.StorageKey = blob.StorageKey
+	media.ContentType = blob.ContentType // of transcoded content
+	media.FileSize = int(blob.Size)
+	media.MediaMetadata = blob.MediaMetadata
+	media.MediaScan = blob.MediaScan
+}
+
+// attach points media at blob, quarantining the content of blobs stored while scanning was disabled
+func (b *MediaBlobs) attach(media *models.Media, blob *models.Blob) {
+	attach(media, blob)
+	if b.quarantine && media.ScanStatus == "" {
+		media.ScanStatus = models.ScanStatusPending
+	}
+}
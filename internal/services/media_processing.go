@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/media"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"strconv"
+)
+
+// MediaProcessing extracts the metadata of stored media content & stores its thumbnails next to it. Content the
+// transcoder accepts is converted first, the metadata is that of the converted content.
+type MediaProcessing struct {
+	iName      string
+	log        *zerolog.Logger
+	blobStore  storage.IBlobStore
+	processor  media.IProcessor
+	transcoder media.ITranscoder // nil keeps content in its uploaded format
+}
+
+func NewMediaProcessing(log *zerolog.Logger, blobStore storage.IBlobStore, processor media.IProcessor, transcoder media.ITranscoder) *MediaProcessing {
+	return &MediaProcessing{
+		iName:      "MediaProcessing",
+		log:        log,
+		blobStore:  blobStore,
+		processor:  processor,
+		transcoder: transcoder,
+	}
+}
+
+// Process sets the size & metadata of blob from its stored content & stores its thumbnails. The content is
+// replaced when it was transcoded or the processor stripped private metadata such as the location from it. The
+// blob must not be recorded when it fails, its content & thumbnails are then deleted with blobKeys.
+func (p *MediaProcessing) Process(ctx context.Context, blob *models.Blob) error {
+	const kName = "Process"
+	transcode := p.transcoder != nil && p.transcoder.Accepts(blob.ContentType)
+	if !transcode && (p.processor == nil || !p.processor.Accepts(blob.ContentType)) {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, "MediaProcessing", "Process")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.log)
+
+	// processors read files, ffmpeg needs to seek in the content
+	path, err := p.download(ctx, blob.StorageKey)
+	if path != "" {
+		defer os.Remove(path)
+	}
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Str("key", blob.StorageKey).Msg("Failed to download media content")
+		return apperrors.Internal(apperrors.CodeInternal, "Failed to process the file").WithErr(err)
+	}
+	if transcode {
+		transcoded, err := p.transcode(ctx, blob, path)
+		if transcoded != "" {
+			defer os.Remove(transcoded)
+		}
+		if err != nil {
+			logger.Error().Interface(kName, p.iName).Err(err).Str("key", blob.StorageKey).Msg("Failed to transcode media")
+			return apperrors.Internal(apperrors.CodeInternal, "Failed to process the file").WithErr(err)
+		}
+		if transcoded != "" {
+			path = transcoded
+		}
+	}
+	if p.processor == nil || !p.processor.Accepts(blob.ContentType) {
+		return nil
+	}
+
+	result, err := p.processor.Process(ctx, path, blob.ContentType)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Str("key", blob.StorageKey).Msg("Failed to process media")
+		return apperrors.Internal(apperrors.CodeInternal, "Failed to process the file").WithErr(err)
+	}
+
+	if result.Sanitized != nil {
+		size, err := p.blobStore.Put(ctx, blob.StorageKey, bytes.NewReader(result.Sanitized), blob.ContentType)
+		if err != nil {
+			logger.Error().Interface(kName, p.iName).Err(err).Str("key", blob.StorageKey).Msg("Failed to store sanitized media content")
+			return apperrors.Internal(apperrors.CodeInternal, "Failed to process the file").WithErr(err)
+		}
+		blob.Size = size
+	}
+	blob.Width, blob.Height = result.Width, result.Height
+	blob.DurationMs = result.Duration.Milliseconds()
+	blob.Blurhash = result.Blurhash
+	blob.Waveform = result.Waveform
+	for _, thumbnail := range result.Thumbnails {
+		key := blob.StorageKey + ".thumbnail-" + strconv.Itoa(thumbnail.Size)
+		if _, err = p.blobStore.Put(ctx, key, bytes.NewReader(thumbnail.Content), thumbnail.ContentType); err != nil {
+			logger.Error().Interface(kName, p.iName).Err(err).Str("key", key).Msg("Failed to store thumbnail")
+			return apperrors.Internal(apperrors.CodeInternal, "Failed to process the file").WithErr(err)
+		}
+		blob.Thumbnails = append(blob.Thumbnails, models.MediaThumbnail{
+			Size:        thumbnail.Size,
+			Width:       thumbnail.Width,
+			Height:      thumbnail.Height,
+			ContentType: thumbnail.ContentType,
+			FileSize:    len(thumbnail.Content),
+			StorageKey:  key,
+		})
+	}
+	logger.Debug().Interface(kName, p.iName).Str("key", blob.StorageKey).Int("thumbnails", len(blob.Thumbnails)).
+		Bool("sanitized", result.Sanitized != nil).Msg("Processed media")
+	return nil
+}
+
+// transcode replaces the content of blob with its transcoded content, it returns the path of the temporary file
+// holding it, the caller removes it. The path is empty when the transcoder kept the content.
+func (p *MediaProcessing) transcode(ctx context.Context, blob *models.Blob, path string) (string, error) {
+	file, err := os.CreateTemp("", "media-transcoded-*")
+	if err != nil {
+		return "", err
+	}
+	dst := file.Name()
+	_ = file.Close()
+	contentType, err := p.transcoder.Transcode(ctx, path, blob.ContentType, dst)
+	if err != nil || contentType == "" {
+		_ = os.Remove(dst)
+		return "", err
+	}
+
+	if file, err = os.Open(dst); err != nil {
+		return dst, err
+	}
+	defer file.Close()
+	size, err := p.blobStore.Put(ctx, blob.StorageKey, file, contentType)
+	if err != nil {
+		return dst, err
+	}
+	blob.ContentType, blob.Size = contentType, size
+	return dst, nil
+}
+
+// download copies the blob under key to a temporary file, the caller removes it
+func (p *MediaProcessing) download(ctx context.Context, key string) (string, error) {
+	blob, err := p.blobStore.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer blob.Close()
+	file, err := os.CreateTemp("", "media-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(file, blob)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return file.Name(), err
+}
+
+// blobKeys are the blob store keys of content & its thumbnails
+func blobKeys(storageKey string, thumbnails []models.MediaThumbnail) []string {
+	var keys []string
+	if storageKey != "" {
+		keys = append(keys, storageKey)
+	}
+	for _, thumbnail := range thumbnails {
+		keys = append(keys, thumbnail.StorageKey)
+	}
+	return keys
+}
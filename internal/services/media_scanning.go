@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/media"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// scanBatch is the number of pending media scanned per query
+const scanBatch = 50
+
+// IScanAlertNotifier is told when the content a user uploaded is blocked by the malware scanner
+type IScanAlertNotifier interface {
+	NotifyMediaBlocked(ctx context.Context, blocked *models.Media)
+}
+
+// MediaScanning releases quarantined media once the scanner cleared their content. Content is scanned once per
+// blob, media sharing a scanned blob take over its verdict.
+type MediaScanning struct {
+	iName     string
+	log       *zerolog.Logger
+	mediaRepo repository.MediaRepository
+	blobRepo  repository.IBlobRepository
+	blobStore storage.IBlobStore
+	scanner   media.IMediaScanner
+	notifier  IScanAlertNotifier
+}
+
+func NewMediaScanning(log *zerolog.Logger, mediaRepo repository.MediaRepository, blobRepo repository.IBlobRepository, blobStore storage.IBlobStore, scanner media.IMediaScanner, notifier IScanAlertNotifier) *MediaScanning {
+	return &MediaScanning{
+		iName:     "MediaScanning",
+		log:       log,
+		mediaRepo: mediaRepo,
+		blobRepo:  blobRepo,
+		blobStore: blobStore,
+		scanner:   scanner,
+		notifier:  notifier,
+	}
+}
+
+// ScanPending scans the pending media every interval until ctx is done
+func (s *MediaScanning) ScanPending(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.scanPending(ctx)
+		}
+	}
+}
+
+// scanPending scans the media pending so far. A failing scanner stops the pass, the media stay pending & are
+// retried on the next one.
+func (s *MediaScanning) scanPending(ctx context.Context) {
+	const kName = "scanPending"
+	ctx, span := tracing.Start(ctx, "MediaScanning", "ScanPending")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.log)
+
+	scanned := 0
+	for {
+		medias, err := s.mediaRepo.ListByScanStatus(ctx, models.ScanStatusPending, scanBatch)
+		if err != nil {
+			logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to list pending media")
+			return
+		}
+		for i := range medias {
+			if err = s.scan(ctx, &medias[i]); err != nil {
+				logger.Error().Interface(kName, s.iName).Err(err).Str("mediaId", medias[i].Id.Hex()).Msg("Failed to scan media")
+				return
+			}
+			scanned++
+		}
+		if len(medias) < scanBatch {
+			break
+		}
+	}
+	if scanned > 0 {
+		logger.Info().Interface(kName, s.iName).Int("media", scanned).Msg("Scanned pending media")
+	}
+}
+
+// scan records the verdict on the content of pending media, scanning its blob unless it was scanned already
+func (s *MediaScanning) scan(ctx context.Context, pending *models.Media) error {
+	const kName = "scan"
+	logger := logging.FromContext(ctx, s.log)
+
+	blob, err := s.blobRepo.GetByID(ctx, pending.BlobID.Hex())
+	if errors.Is(err, apperrors.ErrNotFound) {
+		// the content is gone, there is nothing left to serve
+		logger.Warn().Interface(kName, s.iName).Str("mediaId", pending.Id.Hex()).Msg("Pending media has no blob")
+		return s.record(ctx, pending, models.MediaScan{ScanStatus: models.ScanStatusFailed})
+	}
+	if err != nil {
+		return err
+	}
+	if blob.ScanStatus == "" || blob.ScanStatus == models.ScanStatusPending {
+		if blob.MediaScan, err = s.scanBlob(ctx, blob); err != nil {
+			return err
+		}
+		if err = s.blobRepo.SetScanStatus(ctx, blob.ID.Hex(), blob.ScanStatus, blob.ScanSignature); err != nil {
+			return err
+		}
+	}
+	return s.record(ctx, pending, blob.MediaScan)
+}
+
+// scanBlob streams the content of blob to the scanner, missing content & content the scanner rejects fail the scan
+func (s *MediaScanning) scanBlob(ctx context.Context, blob *models.Blob) (models.MediaScan, error) {
+	const kName = "scanBlob"
+	logger := logging.FromContext(ctx, s.log)
+
+	content, err := s.blobStore.Get(ctx, blob.StorageKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		logger.Warn().Interface(kName, s.iName).Str("blobId", blob.ID.Hex()).Msg("The content of the blob is missing")
+		return models.MediaScan{ScanStatus: models.ScanStatusFailed}, nil
+	}
+	if err != nil {
+		return models.MediaScan{}, err
+	}
+	defer content.Close()
+
+	result, err := s.scanner.Scan(ctx, content)
+	if errors.Is(err, media.ErrScanRejected) {
+		logger.Warn().Interface(kName, s.iName).Err(err).Str("blobId", blob.ID.Hex()).Msg("The scanner rejected the blob")
+		return models.MediaScan{ScanStatus: models.ScanStatusFailed}, nil
+	}
+	if err != nil {
+		return models.MediaScan{}, err
+	}
+	if result.Infected {
+		return models.MediaScan{ScanStatus: models.ScanStatusInfected, ScanSignature: result.Signature}, nil
+	}
+	return models.MediaScan{ScanStatus: models.ScanStatusClean}, nil
+}
+
+// record sets the verdict of pending media & tells the uploader when its content is blocked
+func (s *MediaScanning) record(ctx context.Context, pending *models.Media, verdict models.MediaScan) error {
+	err := s.mediaRepo.SetScanStatus(ctx, pending.Id.Hex(), verdict.ScanStatus, verdict.ScanSignature)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		// deleted while it was scanned
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pending.MediaScan = verdict
+	if !pending.Downloadable() && s.notifier != nil {
+		s.notifier.NotifyMediaBlocked(ctx, pending)
+	}
+	return nil
+}
+
+// LogScanAlertNotifier logs blocked uploads until a real-time channel can deliver them to the uploader
+type LogScanAlertNotifier struct {
+	iName string
+	log   *zerolog.Logger
+}
+
+func NewLogScanAlertNotifier(log *zerolog.Logger) IScanAlertNotifier {
+	return &LogScanAlertNotifier{
+		iName: "LogScanAlertNotifier",
+		log:   log,
+	}
+}
+
+func (l *LogScanAlertNotifier) NotifyMediaBlocked(ctx context.Context, blocked *models.Media) {
+	const kName = "NotifyMediaBlocked"
+	ctx, span := tracing.Start(ctx, "LogScanAlertNotifier", "NotifyMediaBlocked")
+	defer span.End()
+	logger := logging.FromContext(ctx, l.log)
+	logger.Warn().Interface(kName, l.iName).Str("userId", blocked.SenderId.Hex()).Str("mediaId", blocked.Id.Hex()).
+		Str("scanStatus", blocked.ScanStatus).Str("signature", blocked.ScanSignature).Msg("uploaded media was blocked by the malware scanner")
+}
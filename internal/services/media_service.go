@@ -1,48 +1,402 @@
 package services
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
 )
 
-type mediaService interface {
-	CreateMedia(ctx context.Context, media *models.Media) error
-	GetMediaById(ctx context.Context, id string) (*models.Media, error)
+// sniffLen is the number of bytes http.DetectContentType looks at
+const sniffLen = 512
+
+// maxFileNameLen bounds the file names stored with the media
+const maxFileNameLen = 255
+
+var errUploadTooLarge = errors.New("upload exceeds the size limit")
+
+type IMediaService interface {
+	// UploadMedia streams the upload's content to the blob store & records its metadata
+	UploadMedia(ctx context.Context, upload *MediaUpload) (*models.Media, error)
+	// GetMediaById returns the media of a chat userId takes part in
+	GetMediaById(ctx context.Context, userId string, id string) (*models.Media, error)
 	GetAllMediaByChatId(ctx context.Context, chatId string, page, limit int) ([]models.Media, error)
 	GetAllMediaBySenderId(ctx context.Context, senderId string, page, limit int) ([]models.Media, error)
-	UpdateMedia(ctx context.Context, media *models.Media) error
-	DeleteMedia(ctx context.Context, id string) error
-	//DeleteMediaByChatId(ctx context.Context, chatId string) error
-	//DeleteMediaBySenderId(ctx context.Context, chatId string) error
+	// DeleteMedia deletes the media & its content, only the uploader may delete it
+	DeleteMedia(ctx context.Context, userId string, id string) error
+	// GetMediaContent opens the content a signed download URL points to, the whole file unless byteRange is set.
+	// It opens the thumbnail of the given size instead unless thumbnail is 0.
+	GetMediaContent(ctx context.Context, id string, thumbnail int, expires int64, signature string, byteRange *ByteRange) (*MediaContent, error)
+}
+
+// MediaUpload is a file streamed by a client
+type MediaUpload struct {
+	ChatID    string
+	SenderID  string // the authenticated uploader
+	MediaType string // declared by the client, it must match the detected content
+	FileName  string
+	Content   io.Reader
+}
+
+// ByteRange is the single range of a Range header, Start or End is -1 when omitted ("bytes=500-" or "bytes=-500")
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// MediaContent is the open content of a media or of its thumbnail, or a range of it
+type MediaContent struct {
+	Media       *models.Media
+	ContentType string
+	FileName    string // empty for thumbnails
+	Size        int64  // of the whole file
+	Body        io.ReadCloser
+	Offset      int64
+	Length      int64
+	Partial     bool // Body holds the requested range rather than the whole file
+}
+
+// MediaLimits restrict what can be uploaded
+type MediaLimits struct {
+	MaxUploadBytes    int64
+	AllowedTypes      []string // MIME types as detected by http.DetectContentType, without parameters
+	MaxResumableBytes int64    // size limit of resumable uploads
+	ChunkMaxBytes     int64    // size limit of a chunk of a resumable upload
+	UploadExpiry      time.Duration
 }
 
 type MediaService struct {
-	repo repository.MediaRepository
+	iName     string
+	log       *zerolog.Logger
+	repo      repository.MediaRepository
+	chatRepo  repository.ChatRepository
+	blobStore storage.IBlobStore
+	urls      *MediaURLs
+	blobs     *MediaBlobs
+	quotas    IStorageQuotaService
+	limits    MediaLimits
 }
 
-func NewMediaService(repo repository.MediaRepository) *MediaService {
+func NewMediaService(log *zerolog.Logger, repo repository.MediaRepository, chatRepo repository.ChatRepository, blobStore storage.IBlobStore, urls *MediaURLs, blobs *MediaBlobs, quotas IStorageQuotaService, limits MediaLimits) *MediaService {
 	return &MediaService{
-		repo: repo,
+		iName:     "MediaService",
+		log:       log,
+		repo:      repo,
+		chatRepo:  chatRepo,
+		blobStore: blobStore,
+		urls:      urls,
+		blobs:     blobs,
+		quotas:    quotas,
+		limits:    limits,
 	}
 }
 
-func (m *MediaService) CreateMedia(ctx context.Context, media *models.Media) error {
-	return m.repo.Create(ctx, media)
+func (m *MediaService) UploadMedia(ctx context.Context, upload *MediaUpload) (*models.Media, error) {
+	const kName = "UploadMedia"
+	ctx, span := tracing.Start(ctx, "MediaService", "UploadMedia")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.log)
+
+	senderID, err := primitive.ObjectIDFromHex(upload.SenderID)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid sender id", apperrors.InvalidField("senderId", "must be a valid id"))
+	}
+	chat, err := participantChat(ctx, m.chatRepo, upload.ChatID, senderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the size is only known once stored, a full quota rejects the upload before it is read
+	if err = m.quotas.Check(ctx, upload.SenderID, 0); err != nil {
+		return nil, err
+	}
+	content, contentType, mediaType, err := sniffContent(upload.Content, m.limits.AllowedTypes, upload.MediaType)
+	if err != nil {
+		logger.Info().Interface(kName, m.iName).Err(err).Msg("Rejected upload")
+		return nil, err
+	}
+
+	media := &models.Media{
+		Id:              primitive.NewObjectID(),
+		ChatId:          chat.ID,
+		SenderId:        senderID,
+		MediaType:       mediaType,
+		ContentType:     contentType,
+		FileName:        fileName(upload.FileName),
+		UploadTimestamp: primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	size, err := m.blobs.Store(ctx, media, &limitedReader{r: content, remaining: m.limits.MaxUploadBytes})
+	if errors.Is(err, errUploadTooLarge) {
+		return nil, tooLarge("The file is larger than the upload limit")
+	}
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to store media content")
+		return nil, apperrors.Wrap(err, "Failed to store the file")
+	}
+	if err = m.quotas.Check(ctx, upload.SenderID, size); err != nil {
+		m.blobs.Release(ctx, media)
+		return nil, err
+	}
+
+	if err = m.repo.Create(ctx, media); err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Msg("Failed to record media, releasing its content")
+		m.blobs.Release(ctx, media)
+		return nil, err
+	}
+	if err = keepWithinQuota(ctx, m.quotas, m.repo, m.blobs, media); err != nil {
+		logger.Info().Interface(kName, m.iName).Err(err).Str("mediaId", media.Id.Hex()).Msg("Removed media above the storage quota")
+		return nil, err
+	}
+	logger.Info().Interface(kName, m.iName).Str("mediaId", media.Id.Hex()).Str("contentType", contentType).Int64("size", size).Msg("Uploaded media")
+	m.urls.Sign(media)
+	return media, nil
 }
 
-func (m *MediaService) GetMediaById(ctx context.Context, id string) (*models.Media, error) {
-	return m.repo.GetByID(ctx, id)
+func (m *MediaService) GetMediaById(ctx context.Context, userId string, id string) (*models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaService", "GetMediaById")
+	defer span.End()
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id")
+	}
+	media, err := m.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = participantChat(ctx, m.chatRepo, media.ChatId.Hex(), userID); err != nil {
+		return nil, err
+	}
+	m.urls.Sign(media)
+	return media, nil
 }
+
 func (m *MediaService) GetAllMediaByChatId(ctx context.Context, chatId string, page, limit int) ([]models.Media, error) {
-	return m.repo.GetByChatId(ctx, chatId, page, limit)
+	ctx, span := tracing.Start(ctx, "MediaService", "GetAllMediaByChatId")
+	defer span.End()
+	return m.signed(m.repo.GetByChatId(ctx, chatId, page, limit))
 }
+
 func (m *MediaService) GetAllMediaBySenderId(ctx context.Context, senderId string, page, limit int) ([]models.Media, error) {
-	return m.repo.GetBySenderId(ctx, senderId, page, limit)
+	ctx, span := tracing.Start(ctx, "MediaService", "GetAllMediaBySenderId")
+	defer span.End()
+	return m.signed(m.repo.GetBySenderId(ctx, senderId, page, limit))
 }
-func (m *MediaService) UpdateMedia(ctx context.Context, media *models.Media) error {
-	return m.repo.Update(ctx, media)
+
+func (m *MediaService) DeleteMedia(ctx context.Context, userId string, id string) error {
+	ctx, span := tracing.Start(ctx, "MediaService", "DeleteMedia")
+	defer span.End()
+
+	media, err := m.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if media.SenderId.Hex() != userId {
+		return apperrors.Forbidden(apperrors.CodeForbidden, "Only the uploader can delete the media")
+	}
+	// the record goes first, content without a record is only wasted space while a record without content is broken
+	if err = m.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	m.blobs.Release(ctx, media)
+	return nil
 }
-func (m *MediaService) DeleteMedia(ctx context.Context, id string) error {
-	return m.repo.Delete(ctx, id)
+
+func (m *MediaService) GetMediaContent(ctx context.Context, id string, thumbnail int, expires int64, signature string, byteRange *ByteRange) (*MediaContent, error) {
+	const kName = "GetMediaContent"
+	ctx, span := tracing.Start(ctx, "MediaService", "GetMediaContent")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.log)
+
+	// the signature stands in for the membership check, it was only issued to participants of the media's chat
+	if err := m.urls.Verify(id, thumbnail, expires, signature); err != nil {
+		return nil, err
+	}
+	media, err := m.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if media.StorageKey == "" {
+		return nil, apperrors.NotFound(apperrors.CodeMediaNotFound, "The media has no stored content")
+	}
+	if err = checkScanned(media); err != nil {
+		return nil, err
+	}
+
+	key, size := media.StorageKey, int64(media.FileSize)
+	content := &MediaContent{Media: media, ContentType: media.ContentType, FileName: media.FileName}
+	if thumbnail != 0 {
+		index := slices.IndexFunc(media.Thumbnails, func(t models.MediaThumbnail) bool { return t.Size == thumbnail })
+		if index < 0 {
+			return nil, apperrors.NotFound(apperrors.CodeMediaNotFound, "The media has no thumbnail of size "+strconv.Itoa(thumbnail))
+		}
+		key, size = media.Thumbnails[index].StorageKey, int64(media.Thumbnails[index].FileSize)
+		content.ContentType, content.FileName = media.Thumbnails[index].ContentType, ""
+	}
+	content.Size, content.Length = size, size
+	if byteRange != nil {
+		offset, length, ok := byteRange.resolve(size)
+		if !ok {
+			return nil, apperrors.Validation(apperrors.CodeRangeNotSatisfiable, "The range is outside the file of "+strconv.FormatInt(size, 10)+" bytes").
+				WithStatus(http.StatusRequestedRangeNotSatisfiable)
+		}
+		content.Offset, content.Length, content.Partial = offset, length, true
+		content.Body, err = m.blobStore.GetRange(ctx, key, offset, length)
+	} else {
+		content.Body, err = m.blobStore.Get(ctx, key)
+	}
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Str("mediaId", id).Msg("Failed to open media content")
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeMediaNotFound, "The media content is missing")
+		}
+		return nil, apperrors.Internal(apperrors.CodeInternal, "Failed to read the media").WithErr(err)
+	}
+	return content, nil
+}
+
+// signed sets the download URLs of a list of media
+func (m *MediaService) signed(medias []models.Media, err error) ([]models.Media, error) {
+	for i := range medias {
+		m.urls.Sign(&medias[i])
+	}
+	return medias, err
+}
+
+// resolve returns the offset & length of the range within a file of size bytes, ok is false when it lies outside
+func (b ByteRange) resolve(size int64) (offset, length int64, ok bool) {
+	if b.Start < 0 {
+		// a suffix, the last End bytes
+		if b.End <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		length = min(b.End, size)
+		return size - length, length, true
+	}
+	if b.Start >= size || (b.End >= 0 && b.End < b.Start) {
+		return 0, 0, false
+	}
+	end := size - 1
+	if b.End >= 0 && b.End < end {
+		end = b.End
+	}
+	return b.Start, end - b.Start + 1, true
+}
+
+// participantChat returns the chat with the hex chatId when userID takes part in it
+func participantChat(ctx context.Context, chatRepo repository.ChatRepository, chatId string, userID primitive.ObjectID) (*models.Chat, error) {
+	if _, err := primitive.ObjectIDFromHex(chatId); err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid chat id", apperrors.InvalidField("chatId", "must be a valid id"))
+	}
+	chat, err := chatRepo.GetByID(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	if !chat.HasParticipant(userID) {
+		return nil, apperrors.Forbidden(apperrors.CodeNotChatParticipant, "Not a participant of the chat")
+	}
+	return chat, nil
+}
+
+// keepWithinQuota checks the quota of the sender again once media is recorded & removes the media when it is
+// exceeded. Concurrent uploads each pass the check before they are recorded, each one is then checked with the media
+// recorded before it so that only the later ones are removed.
+func keepWithinQuota(ctx context.Context, quotas IStorageQuotaService, mediaRepo repository.MediaRepository, blobs *MediaBlobs, media *models.Media) error {
+	err := quotas.CheckRecorded(ctx, media)
+	if err == nil {
+		return nil
+	}
+	// the record goes first, like on deletion
+	if deleteErr := mediaRepo.Delete(ctx, media.Id.Hex()); deleteErr != nil {
+		return apperrors.Wrap(deleteErr, "Failed to remove the media above the storage quota")
+	}
+	blobs.Release(ctx, media)
+	return err
+}
+
+// sniffContent detects the type of content, the declared type of a file is not trusted. It returns a reader
+// of the whole content, the detected MIME type & the matching media type, which must be declared unless empty.
+func sniffContent(content io.Reader, allowedTypes []string, declared string) (io.Reader, string, string, error) {
+	buffered := bufio.NewReaderSize(content, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", "", apperrors.Validation(apperrors.CodeInvalidBody, "Failed to read the file").WithErr(err)
+	}
+	if len(head) == 0 {
+		return nil, "", "", apperrors.Validation(apperrors.CodeValidation, "The file is empty", apperrors.InvalidField("file", "is empty"))
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !slices.Contains(allowedTypes, contentType) {
+		return nil, "", "", apperrors.Validation(apperrors.CodeUnsupportedMediaType, "Files of type "+contentType+" are not accepted").
+			WithStatus(http.StatusUnsupportedMediaType)
+	}
+	mediaType := models.MediaTypeOf(contentType)
+	if declared != "" && declared != mediaType {
+		return nil, "", "", apperrors.Validation(apperrors.CodeValidation, "The media type does not match the file",
+			apperrors.InvalidField("mediaType", "the file is "+mediaType+" content"))
+	}
+	return buffered, contentType, mediaType, nil
+}
+
+// tooLarge is the error of content above a size limit
+func tooLarge(message string) error {
+	return apperrors.Validation(apperrors.CodeTooLarge, message).WithStatus(http.StatusRequestEntityTooLarge)
+}
+
+// fileName keeps the client's file name for display only, it is never part of a storage key
+func fileName(name string) string {
+	runes := []rune(name)
+	if len(runes) > maxFileNameLen {
+		return string(runes[:maxFileNameLen])
+	}
+	return name
+}
+
+// limitedReader fails with errUploadTooLarge once more than remaining bytes are read,
+// unlike io.LimitReader which silently truncates
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, errUploadTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, errUploadTooLarge
+	}
+	return n, err
+}
+
+// checkScanned blocks the content of media the malware scanner has not cleared
+func checkScanned(media *models.Media) error {
+	switch media.ScanStatus {
+	case models.ScanStatusPending:
+		return apperrors.Conflict(apperrors.CodeMediaScanPending, "The media is being scanned for malware, retry later")
+	case models.ScanStatusInfected:
+		return apperrors.Forbidden(apperrors.CodeMediaInfected, "The media contains malware")
+	case models.ScanStatusFailed:
+		return apperrors.Forbidden(apperrors.CodeMediaScanFailed, "The media could not be scanned for malware")
+	}
+	return nil
 }
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/memory"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestKeepWithinQuota(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	blobStore, err := storage.NewLocalBlobStore(&log, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaRepo := memory.NewMediaRepository()
+	blobs := NewMediaBlobs(&log, memory.NewBlobRepository(), blobStore, &MediaProcessing{}, false)
+	quotas := NewStorageQuotaService(&log, memory.NewSubscriptionRepository(), mediaRepo, PlanQuotas{Free: 100})
+
+	// both uploads passed the check before either was recorded
+	sender, chat := primitive.NewObjectID(), primitive.NewObjectID()
+	first := &models.Media{Id: primitive.NewObjectID(), ChatId: chat, SenderId: sender, MediaType: models.MediaTypeImage, FileSize: 60, BlobID: primitive.NewObjectID()}
+	second := &models.Media{Id: primitive.NewObjectID(), ChatId: chat, SenderId: sender, MediaType: models.MediaTypeImage, FileSize: 60}
+	for _, media := range []*models.Media{first, second} {
+		if err = mediaRepo.Create(ctx, media); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the earlier upload is within the quota whichever is checked first, only the later one is removed
+	if err = keepWithinQuota(ctx, quotas, mediaRepo, blobs, first); err != nil {
+		t.Errorf("keepWithinQuota() = %v for the earlier upload", err)
+	}
+	err = keepWithinQuota(ctx, quotas, mediaRepo, blobs, second)
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeStorageQuotaExceeded {
+		t.Fatalf("keepWithinQuota() = %v, want %s", err, apperrors.CodeStorageQuotaExceeded)
+	}
+	if _, err = mediaRepo.GetByID(ctx, second.Id.Hex()); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("media above the quota is still recorded, GetByID() = %v", err)
+	}
+	if _, err = mediaRepo.GetByID(ctx, first.Id.Hex()); err != nil {
+		t.Errorf("media within the quota was removed, GetByID() = %v", err)
+	}
+
+	// a re-upload of the same file is stored once & charged nothing more
+	again := &models.Media{Id: primitive.NewObjectID(), ChatId: chat, SenderId: sender, MediaType: models.MediaTypeImage, FileSize: 60, BlobID: first.BlobID}
+	if err = mediaRepo.Create(ctx, again); err != nil {
+		t.Fatal(err)
+	}
+	if err = keepWithinQuota(ctx, quotas, mediaRepo, blobs, again); err != nil {
+		t.Errorf("keepWithinQuota() = %v for a deduplicated re-upload", err)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	pkgservices "github.com/mcsamuelshoko/telko-moment-server/pkg/services"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaURLs issues & checks the signed download URLs of stored media
+type MediaURLs struct {
+	signer  pkgservices.IURLSigner
+	baseURL string // the API root the URLs point to, e.g. a CDN in front of /api/v1
+	ttl     time.Duration
+}
+
+func NewMediaURLs(signer pkgservices.IURLSigner, baseURL string, ttl time.Duration) *MediaURLs {
+	return &MediaURLs{signer: signer, baseURL: strings.TrimSuffix(baseURL, "/"), ttl: ttl}
+}
+
+// Sign sets the signed MediaUrl of media with stored content & the URLs of its thumbnails. The expiry is rounded up
+// to the next ttl boundary so every request within a window gets the same URL & a CDN can cache it,
+// URLs stay valid between ttl & twice ttl.
+func (u *MediaURLs) Sign(media *models.Media) {
+	if media.StorageKey == "" {
+		return
+	}
+	expiresAt := time.Now().Truncate(u.ttl).Add(2 * u.ttl)
+	media.MediaUrl = u.url(media.Id.Hex(), 0, expiresAt)
+	media.MediaUrlExpiresAt = expiresAt
+	for i := range media.Thumbnails {
+		media.Thumbnails[i].Url = u.url(media.Id.Hex(), media.Thumbnails[i].Size, expiresAt)
+	}
+}
+
+// Verify checks a download URL of the media with the hex mediaId, or of its thumbnail unless thumbnail is 0
+func (u *MediaURLs) Verify(mediaId string, thumbnail int, expires int64, signature string) error {
+	expiresAt := time.Unix(expires, 0)
+	if !u.signer.Verify(signedResource(mediaId, thumbnail), expiresAt, signature) {
+		return apperrors.Forbidden(apperrors.CodeInvalidSignature, "Invalid download URL")
+	}
+	if !time.Now().Before(expiresAt) {
+		return apperrors.Forbidden(apperrors.CodeURLExpired, "The download URL has expired, fetch the media again for a new one")
+	}
+	return nil
+}
+
+func (u *MediaURLs) url(mediaId string, thumbnail int, expiresAt time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", u.signer.Sign(signedResource(mediaId, thumbnail), expiresAt))
+	if thumbnail > 0 {
+		query.Set("thumbnail", strconv.Itoa(thumbnail))
+	}
+	return u.baseURL + "/media/" + mediaId + "/content?" + query.Encode()
+}
+
+// signedResource is what the signature of a URL covers, the URLs of a media & of its thumbnails are not interchangeable
+func signedResource(mediaId string, thumbnail int) string {
+	if thumbnail > 0 {
+		return mediaId + "/thumbnails/" + strconv.Itoa(thumbnail)
+	}
+	return mediaId
+}
@@ -2,51 +2,267 @@ package services
 
 import (
 	"context"
+	"errors"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/metrics"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
 )
 
 type IMessageService interface {
 	Create(ctx context.Context, message *models.Message) (*models.Message, error)
-	Update(ctx context.Context, message *models.Message) error
-	GetById(ctx context.Context, messageId string) (*models.Message, error)
+	// Update saves an edit of message, which only its sender may make
+	Update(ctx context.Context, userId string, message *models.Message) error
+	// GetById returns the message with messageId when userId takes part in its chat
+	GetById(ctx context.Context, userId string, messageId string) (*models.Message, error)
 	GetBySenderId(ctx context.Context, userId string) (*models.Message, error)
 	GetByChatId(ctx context.Context, chatId string) (*models.Message, error)
-	Delete(ctx context.Context, messageId string) error
+	// Delete removes the message with messageId, which only its sender may do
+	Delete(ctx context.Context, userId string, messageId string) error
+	// MarkPlayed records that userId, a recipient of the voice note, played it
+	MarkPlayed(ctx context.Context, userId string, messageId string) (*models.PlayedReceipt, error)
+	// GetPlayedReceipts lists who played a voice note userId sent, first played first
+	GetPlayedReceipts(ctx context.Context, userId string, messageId string) ([]models.PlayedReceipt, error)
 }
 
 type MessageService struct {
-	iName string
-	repo  repository.MessageRepository
+	iName       string
+	log         *zerolog.Logger
+	repo        repository.MessageRepository
+	mediaRepo   repository.MediaRepository
+	chatRepo    repository.ChatRepository
+	receiptRepo repository.IPlayedReceiptRepository
 }
 
-func NewMessageService(repo repository.MessageRepository) *MessageService {
+func NewMessageService(log *zerolog.Logger, repo repository.MessageRepository, mediaRepo repository.MediaRepository, chatRepo repository.ChatRepository, receiptRepo repository.IPlayedReceiptRepository) *MessageService {
 	return &MessageService{
-		iName: "MessageService",
-		repo:  repo,
+		iName:       "MessageService",
+		log:         log,
+		repo:        repo,
+		mediaRepo:   mediaRepo,
+		chatRepo:    chatRepo,
+		receiptRepo: receiptRepo,
 	}
 }
 
 func (m *MessageService) Create(ctx context.Context, message *models.Message) (*models.Message, error) {
-	return m.repo.Create(ctx, message)
+	ctx, span := tracing.Start(ctx, "MessageService", "Create")
+	defer span.End()
+	if _, err := participantChat(ctx, m.chatRepo, message.ChatID.Hex(), message.SenderID); err != nil {
+		return nil, err
+	}
+	switch message.MessageType {
+	case models.MessageTypeText, models.MessageTypeEncrypted, models.MessageTypeVoiceNote:
+	default:
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Unknown message type",
+			apperrors.InvalidField("messageType", "must be one of text, encrypted or voice_note"))
+	}
+	// end-to-end encrypted messages are relayed as per-device ciphertexts only
+	if message.MessageType == models.MessageTypeEncrypted {
+		if len(message.Ciphertexts) == 0 {
+			return nil, apperrors.Validation(apperrors.CodeValidation, "Encrypted message has no device ciphertexts", apperrors.RequiredField("ciphertexts"))
+		}
+		message.Content = ""
+		message.MediaUrls = nil
+	}
+	attachments, err := m.checkAttachments(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	message.VoiceNote = nil
+	if message.MessageType == models.MessageTypeVoiceNote {
+		if err = voiceNote(message, attachments); err != nil {
+			return nil, err
+		}
+	}
+	message.HasLinks = len(messageLinks(message.Content)) > 0
+	created, err := m.repo.Create(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	// the gallery lists media with the message that sent them, the message is sent even when this fails
+	if err = m.mediaRepo.AttachToMessage(ctx, created.ID.Hex(), created.MediaIDs); err != nil {
+		logging.FromContext(ctx, m.log).Error().Interface("Create", m.iName).Err(err).Str("messageId", created.ID.Hex()).Msg("Failed to attach media to message")
+	}
+	metrics.MessagesSent.WithLabelValues(message.MessageType).Inc()
+	return created, nil
+}
+
+// checkAttachments makes sure the attached media were uploaded by the sender to the message's chat,
+// so a message cannot expose media of another chat. It returns the attached media.
+func (m *MessageService) checkAttachments(ctx context.Context, message *models.Message) ([]models.Media, error) {
+	attachments := make([]models.Media, 0, len(message.MediaIDs))
+	for _, mediaID := range message.MediaIDs {
+		media, err := m.mediaRepo.GetByID(ctx, mediaID.Hex())
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.Validation(apperrors.CodeValidation, "Attached media not found", apperrors.InvalidField("mediaIds", mediaID.Hex()+" does not exist"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if media.ChatId != message.ChatID || media.SenderId != message.SenderID {
+			return nil, apperrors.Validation(apperrors.CodeValidation, "Attached media belongs to another chat or sender",
+				apperrors.InvalidField("mediaIds", mediaID.Hex()+" was not uploaded by the sender to this chat"))
+		}
+		attachments = append(attachments, *media)
+	}
+	return attachments, nil
+}
+
+// voiceNote makes sure a voice note is a single audio attachment & sets the duration & waveform the server
+// extracted from the audio on the message
+func voiceNote(message *models.Message, attachments []models.Media) error {
+	if len(attachments) != 1 || len(message.MediaUrls) > 0 || attachments[0].MediaType != models.MediaTypeAudio {
+		return apperrors.Validation(apperrors.CodeValidation, "A voice note is a single audio attachment",
+			apperrors.InvalidField("mediaIds", "must hold the id of a single audio media"))
+	}
+	if message.Content != "" {
+		return apperrors.Validation(apperrors.CodeValidation, "A voice note has no content", apperrors.InvalidField("content", "must be empty"))
+	}
+	audio := attachments[0]
+	message.VoiceNote = &models.VoiceNote{DurationMs: audio.DurationMs, Waveform: audio.Waveform}
+	return nil
 }
 
-func (m *MessageService) Update(ctx context.Context, message *models.Message) error {
+func (m *MessageService) Update(ctx context.Context, userId string, message *models.Message) error {
+	ctx, span := tracing.Start(ctx, "MessageService", "Update")
+	defer span.End()
+	stored, err := m.sentMessage(ctx, userId, message.ID.Hex())
+	if err != nil {
+		return err
+	}
+	// an edit does not move the message to another chat or sender
+	message.ChatID, message.SenderID = stored.ChatID, stored.SenderID
+	// an edit removing the links cannot unset the flag, the gallery extracts the links again
+	message.HasLinks = len(messageLinks(message.Content)) > 0
 	return m.repo.Update(ctx, message)
 }
 
-func (m *MessageService) GetById(ctx context.Context, messageId string) (*models.Message, error) {
-	return m.repo.GetByID(ctx, messageId)
+func (m *MessageService) GetById(ctx context.Context, userId string, messageId string) (*models.Message, error) {
+	ctx, span := tracing.Start(ctx, "MessageService", "GetById")
+	defer span.End()
+	_, message, err := m.participantMessage(ctx, userId, messageId)
+	return message, err
 }
 
 func (m *MessageService) GetBySenderId(ctx context.Context, userId string) (*models.Message, error) {
+	ctx, span := tracing.Start(ctx, "MessageService", "GetBySenderId")
+	defer span.End()
 	return m.repo.GetBySenderID(ctx, userId)
 }
 
 func (m *MessageService) GetByChatId(ctx context.Context, chatId string) (*models.Message, error) {
+	ctx, span := tracing.Start(ctx, "MessageService", "GetByChatId")
+	defer span.End()
 	return m.repo.GetByChatID(ctx, chatId)
 }
 
-func (m *MessageService) Delete(ctx context.Context, messageId string) error {
-	return m.repo.Delete(ctx, messageId)
+func (m *MessageService) Delete(ctx context.Context, userId string, messageId string) error {
+	ctx, span := tracing.Start(ctx, "MessageService", "Delete")
+	defer span.End()
+	if _, err := m.sentMessage(ctx, userId, messageId); err != nil {
+		return err
+	}
+	if err := m.repo.Delete(ctx, messageId); err != nil {
+		return err
+	}
+	if err := m.receiptRepo.DeleteByMessageId(ctx, messageId); err != nil {
+		return err
+	}
+	// the media stay, only the gallery stops listing them
+	return m.mediaRepo.DetachFromMessage(ctx, messageId)
+}
+
+func (m *MessageService) MarkPlayed(ctx context.Context, userId string, messageId string) (*models.PlayedReceipt, error) {
+	const kName = "MarkPlayed"
+	ctx, span := tracing.Start(ctx, "MessageService", "MarkPlayed")
+	defer span.End()
+	logger := logging.FromContext(ctx, m.log)
+
+	userID, message, err := m.voiceNoteOf(ctx, userId, messageId)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID == userID {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "The sender does not play its own voice note")
+	}
+	if _, err = participantChat(ctx, m.chatRepo, message.ChatID.Hex(), userID); err != nil {
+		return nil, err
+	}
+	receipt, err := m.receiptRepo.MarkPlayed(ctx, &models.PlayedReceipt{
+		MessageID: message.ID,
+		ChatID:    message.ChatID,
+		UserID:    userID,
+		PlayedAt:  time.Now(),
+	})
+	if err != nil {
+		logger.Error().Interface(kName, m.iName).Err(err).Str("messageId", messageId).Msg("Failed to mark voice note played")
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func (m *MessageService) GetPlayedReceipts(ctx context.Context, userId string, messageId string) ([]models.PlayedReceipt, error) {
+	ctx, span := tracing.Start(ctx, "MessageService", "GetPlayedReceipts")
+	defer span.End()
+
+	userID, message, err := m.voiceNoteOf(ctx, userId, messageId)
+	if err != nil {
+		return nil, err
+	}
+	// like read receipts, only the sender learns who listened
+	if message.SenderID != userID {
+		return nil, apperrors.Forbidden(apperrors.CodeForbidden, "Only the sender sees who played a voice note")
+	}
+	return m.receiptRepo.ListByMessageId(ctx, messageId)
+}
+
+// participantMessage parses userId & returns the message with messageId when userId takes part in its chat
+func (m *MessageService) participantMessage(ctx context.Context, userId string, messageId string) (primitive.ObjectID, *models.Message, error) {
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return primitive.NilObjectID, nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id")
+	}
+	message, err := m.repo.GetByID(ctx, messageId)
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+	if _, err = participantChat(ctx, m.chatRepo, message.ChatID.Hex(), userID); err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+	return userID, message, nil
+}
+
+// sentMessage returns the message with messageId when userId sent it & still takes part in its chat
+func (m *MessageService) sentMessage(ctx context.Context, userId string, messageId string) (*models.Message, error) {
+	userID, message, err := m.participantMessage(ctx, userId, messageId)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userID {
+		return nil, apperrors.Forbidden(apperrors.CodeForbidden, "Only the sender can change the message")
+	}
+	return message, nil
+}
+
+// voiceNoteOf parses userId & returns the voice note with messageId
+func (m *MessageService) voiceNoteOf(ctx context.Context, userId string, messageId string) (primitive.ObjectID, *models.Message, error) {
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return primitive.NilObjectID, nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id")
+	}
+	message, err := m.repo.GetByID(ctx, messageId)
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+	if message.MessageType != models.MessageTypeVoiceNote {
+		return primitive.NilObjectID, nil, apperrors.Validation(apperrors.CodeValidation, "The message is not a voice note",
+			apperrors.InvalidField("messageId", "must be the id of a voice note"))
+	}
+	return userID, message, nil
 }
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/memory"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/repositorytest"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+// messageFixture is a direct chat of alice & bob with a message alice sent, & mallory who takes no part in it
+type messageFixture struct {
+	service             *MessageService
+	alice, bob, mallory string
+	chat                primitive.ObjectID
+	message             *models.Message
+}
+
+func newMessageFixture(t *testing.T) *messageFixture {
+	t.Helper()
+	ctx := context.Background()
+	log := zerolog.Nop()
+	keys := repositorytest.NewKeys(t)
+	chats := memory.NewChatRepository()
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	chat, err := chats.Create(ctx, &models.Chat{Type: models.ChatTypeDirect, Participants: []primitive.ObjectID{alice, bob}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := memory.NewMessageRepository(&log, memory.NewChatKeyRepository(keys.Encryption))
+	f := &messageFixture{
+		service: NewMessageService(&log, messages, memory.NewMediaRepository(), chats, memory.NewPlayedReceiptRepository()),
+		alice:   alice.Hex(),
+		bob:     bob.Hex(),
+		mallory: primitive.NewObjectID().Hex(),
+		chat:    chat.ID,
+	}
+	f.message, err = f.service.Create(ctx, &models.Message{ChatID: chat.ID, SenderID: alice, MessageType: models.MessageTypeText, Content: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func requireCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestMessageAccess(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageFixture(t)
+	id := f.message.ID.Hex()
+
+	for _, userId := range []string{f.alice, f.bob} {
+		message, err := f.service.GetById(ctx, userId, id)
+		if err != nil {
+			t.Fatalf("GetById() of a participant = %v", err)
+		}
+		if message.Content != "hi" {
+			t.Errorf("content = %q, want hi", message.Content)
+		}
+	}
+	_, err := f.service.GetById(ctx, f.mallory, id)
+	requireCode(t, err, apperrors.CodeNotChatParticipant)
+	mallory, _ := primitive.ObjectIDFromHex(f.mallory)
+	_, err = f.service.Create(ctx, &models.Message{ChatID: f.chat, SenderID: mallory, MessageType: models.MessageTypeText, Content: "spam"})
+	requireCode(t, err, apperrors.CodeNotChatParticipant)
+
+	edit := *f.message
+	edit.Content = "edited"
+	requireCode(t, f.service.Update(ctx, f.bob, &edit), apperrors.CodeForbidden)
+	requireCode(t, f.service.Update(ctx, f.mallory, &edit), apperrors.CodeNotChatParticipant)
+	requireCode(t, f.service.Delete(ctx, f.bob, id), apperrors.CodeForbidden)
+	requireCode(t, f.service.Delete(ctx, f.mallory, id), apperrors.CodeNotChatParticipant)
+
+	// an edit keeps the chat & sender of the stored message
+	edit.ChatID, edit.SenderID = primitive.NewObjectID(), primitive.NewObjectID()
+	if err = f.service.Update(ctx, f.alice, &edit); err != nil {
+		t.Fatalf("Update() of the sender = %v", err)
+	}
+	message, err := f.service.GetById(ctx, f.bob, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Content != "edited" || message.ChatID != f.chat || message.SenderID.Hex() != f.alice {
+		t.Errorf("updated message = %q in %s from %s", message.Content, message.ChatID.Hex(), message.SenderID.Hex())
+	}
+
+	if err = f.service.Delete(ctx, f.alice, id); err != nil {
+		t.Fatalf("Delete() of the sender = %v", err)
+	}
+	_, err = f.service.GetById(ctx, f.alice, id)
+	requireCode(t, err, apperrors.CodeMessageNotFound)
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ActivityTTL is how long a typing or recording indicator is shown unless refreshed, a client refreshes it while the
+// user keeps typing. Refreshes within half of it are not sent again, so keystrokes are never relayed one by one.
+const ActivityTTL = 6 * time.Second
+
+// streamBuffer is how many events a stream holds for a slow client, later ones are dropped as they are ephemeral
+const streamBuffer = 64
+
+// IPresenceService tracks who is online & what the participants of chats are doing. Presence lives in the memory of
+// the server holding the streams, only when a user was last seen is persisted, lazily, once they went offline.
+// Online & last seen are shown as the user's LastActiveVisibility allows, activities are shown to every participant.
+type IPresenceService interface {
+	// Connect opens a stream of real-time events for the user, who is online until all their streams are closed
+	Connect(ctx context.Context, userId string) (*PresenceStream, error)
+	// SetActivity tells the other participants of the chat that the user is typing, recording or stopped
+	SetActivity(ctx context.Context, userId string, chatId string, activity string) (*models.ChatActivity, error)
+	// GetPresence returns the presence of the user as the viewer may see it
+	GetPresence(ctx context.Context, viewerId string, userId string) (*models.Presence, error)
+	// FlushLastSeen persists when users went offline every interval & once more when ctx is done
+	FlushLastSeen(ctx context.Context, interval time.Duration) error
+	// Close closes every stream, the server is shutting down
+	Close(ctx context.Context) error
+}
+
+// PresenceStream delivers the real-time events of a user until closed
+type PresenceStream struct {
+	ctx     context.Context
+	userID  primitive.ObjectID
+	events  chan models.RealtimeEvent
+	service *PresenceService
+	once    sync.Once
+	done    bool // guarded by the service's lock, set once events are closed
+}
+
+// Events are closed once the stream is, by Close or the server shutting down
+func (s *PresenceStream) Events() <-chan models.RealtimeEvent {
+	return s.events
+}
+
+// Close disconnects the stream, the user goes offline with their last stream
+func (s *PresenceStream) Close() {
+	s.once.Do(func() { s.service.disconnect(s) })
+}
+
+// activity is the indicator a user last sent in a chat
+type activity struct {
+	activity     string
+	sentAt       time.Time
+	participants []primitive.ObjectID
+}
+
+type PresenceService struct {
+	iName        string
+	log          *zerolog.Logger
+	lastSeenRepo repository.ILastSeenRepository
+	settingsRepo repository.ISettingsRepository
+	chatRepo     repository.ChatRepository
+	now          func() time.Time
+
+	mu         sync.Mutex
+	streams    map[primitive.ObjectID][]*PresenceStream
+	activities map[[2]primitive.ObjectID]activity // by chat & user
+	lastSeen   map[primitive.ObjectID]time.Time   // not flushed yet
+	closed     bool
+}
+
+func NewPresenceService(log *zerolog.Logger, lastSeenRepo repository.ILastSeenRepository, settingsRepo repository.ISettingsRepository, chatRepo repository.ChatRepository) IPresenceService {
+	return &PresenceService{
+		iName:        "PresenceService",
+		log:          log,
+		lastSeenRepo: lastSeenRepo,
+		settingsRepo: settingsRepo,
+		chatRepo:     chatRepo,
+		now:          time.Now,
+		streams:      map[primitive.ObjectID][]*PresenceStream{},
+		activities:   map[[2]primitive.ObjectID]activity{},
+		lastSeen:     map[primitive.ObjectID]time.Time{},
+	}
+}
+
+func (p *PresenceService) Connect(ctx context.Context, userId string) (*PresenceStream, error) {
+	const kName = "Connect"
+	ctx, span := tracing.Start(ctx, "PresenceService", "Connect")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.log)
+
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id", apperrors.InvalidField("userId", "must be a valid id"))
+	}
+	chats, err := p.chatRepo.ListByUserId(ctx, userId, 1, 0)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to list chats of user")
+		return nil, err
+	}
+
+	// the stream outlives the request that opened it
+	stream := &PresenceStream{
+		ctx:     context.WithoutCancel(ctx),
+		userID:  userID,
+		events:  make(chan models.RealtimeEvent, streamBuffer),
+		service: p,
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, apperrors.Internal(apperrors.CodeInternal, "The server is shutting down").WithStatus(http.StatusServiceUnavailable)
+	}
+	p.streams[userID] = append(p.streams[userID], stream)
+	first := len(p.streams[userID]) == 1
+	delete(p.lastSeen, userID)
+	p.mu.Unlock()
+
+	if first {
+		p.send(p.audience(ctx, userID, chats), models.RealtimeEvent{
+			Type: models.RealtimeEventPresence,
+			Data: models.Presence{UserID: userID, Online: true},
+		})
+	}
+	// the stream starts with who is online among the participants of the user's chats
+	var snapshot []models.RealtimeEvent
+	contacts := directContacts(userID, chats)
+	others := p.online(coParticipants(userID, chats, false))
+	visibilities := p.visibilities(ctx, others)
+	for _, otherID := range others {
+		if visible(visibilities[otherID], otherID, userID, contacts) {
+			snapshot = append(snapshot, models.RealtimeEvent{Type: models.RealtimeEventPresence, Data: models.Presence{UserID: otherID, Online: true}})
+		}
+	}
+	p.mu.Lock()
+	for _, event := range snapshot {
+		stream.send(event)
+	}
+	p.mu.Unlock()
+	logger.Debug().Interface(kName, p.iName).Str("userId", userId).Bool("online", first).Msg("Connected presence stream")
+	return stream, nil
+}
+
+func (p *PresenceService) SetActivity(ctx context.Context, userId string, chatId string, action string) (*models.ChatActivity, error) {
+	const kName = "SetActivity"
+	ctx, span := tracing.Start(ctx, "PresenceService", "SetActivity")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.log)
+
+	if !slices.Contains([]string{models.ChatActivityTyping, models.ChatActivityRecording, models.ChatActivityStopped}, action) {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Invalid activity",
+			apperrors.InvalidField("activity", "must be typing, recording or stopped"))
+	}
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id", apperrors.InvalidField("userId", "must be a valid id"))
+	}
+	chat, err := participantChat(ctx, p.chatRepo, chatId, userID)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to get chat of activity")
+		return nil, err
+	}
+
+	now := p.now()
+	event := &models.ChatActivity{ChatID: chat.ID, UserID: userID, Activity: action}
+	key := [2]primitive.ObjectID{chat.ID, userID}
+	p.mu.Lock()
+	previous, active := p.activities[key]
+	if action == models.ChatActivityStopped {
+		delete(p.activities, key)
+	} else if active && previous.activity == action && now.Sub(previous.sentAt) < ActivityTTL/2 {
+		// the participants were told recently, their indicator has not expired yet
+		p.mu.Unlock()
+		expiresAt := previous.sentAt.Add(ActivityTTL)
+		event.ExpiresAt = &expiresAt
+		return event, nil
+	} else {
+		expiresAt := now.Add(ActivityTTL)
+		event.ExpiresAt = &expiresAt
+		p.activities[key] = activity{activity: action, sentAt: now, participants: chat.Participants}
+	}
+	p.mu.Unlock()
+
+	if action == models.ChatActivityStopped && !active {
+		return event, nil
+	}
+	p.send(p.online(without(chat.Participants, userID)), models.RealtimeEvent{Type: models.RealtimeEventActivity, Data: *event})
+	return event, nil
+}
+
+func (p *PresenceService) GetPresence(ctx context.Context, viewerId string, userId string) (*models.Presence, error) {
+	const kName = "GetPresence"
+	ctx, span := tracing.Start(ctx, "PresenceService", "GetPresence")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.log)
+
+	viewerID, err := primitive.ObjectIDFromHex(viewerId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id", apperrors.InvalidField("viewerId", "must be a valid id"))
+	}
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id", apperrors.InvalidField("userId", "must be a valid id"))
+	}
+
+	presence := &models.Presence{UserID: userID}
+	if viewerID != userID {
+		chats, err := p.chatRepo.ListByUserId(ctx, viewerId, 1, 0)
+		if err != nil {
+			logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to list chats of viewer")
+			return nil, err
+		}
+		if !visible(p.visibility(ctx, userID), userID, viewerID, directContacts(viewerID, chats)) {
+			return presence, nil
+		}
+	}
+
+	p.mu.Lock()
+	presence.Online = len(p.streams[userID]) > 0
+	lastSeenAt, pending := p.lastSeen[userID]
+	p.mu.Unlock()
+	if presence.Online {
+		return presence, nil
+	}
+	if !pending {
+		lastSeen, err := p.lastSeenRepo.GetByUserId(ctx, userId)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return presence, nil
+		}
+		if err != nil {
+			logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to get last seen of user")
+			return nil, err
+		}
+		lastSeenAt = lastSeen.LastSeenAt
+	}
+	presence.LastSeenAt = &lastSeenAt
+	return presence, nil
+}
+
+func (p *PresenceService) FlushLastSeen(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// the streams were closed before the workers stop, their users went offline now
+			p.flushLastSeen(context.WithoutCancel(ctx))
+			return ctx.Err()
+		case <-ticker.C:
+			p.flushLastSeen(ctx)
+			p.expireActivities()
+		}
+	}
+}
+
+func (p *PresenceService) Close(_ context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	var streams []*PresenceStream
+	for _, userStreams := range p.streams {
+		streams = append(streams, userStreams...)
+	}
+	p.mu.Unlock()
+
+	for _, stream := range streams {
+		stream.Close()
+	}
+	return nil
+}
+
+// disconnect removes stream, the user goes offline with their last stream & their activities stop
+func (p *PresenceService) disconnect(stream *PresenceStream) {
+	const kName = "disconnect"
+	ctx, span := tracing.Start(stream.ctx, "PresenceService", "Disconnect")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.log)
+
+	now := p.now()
+	p.mu.Lock()
+	userStreams := slices.DeleteFunc(p.streams[stream.userID], func(other *PresenceStream) bool { return other == stream })
+	close(stream.events)
+	stream.done = true
+	offline := len(userStreams) == 0
+	var stopped []models.ChatActivity
+	var stoppedFor [][]primitive.ObjectID
+	if offline {
+		delete(p.streams, stream.userID)
+		p.lastSeen[stream.userID] = now
+		for key, active := range p.activities {
+			if key[1] == stream.userID {
+				delete(p.activities, key)
+				stopped = append(stopped, models.ChatActivity{ChatID: key[0], UserID: key[1], Activity: models.ChatActivityStopped})
+				stoppedFor = append(stoppedFor, without(active.participants, stream.userID))
+			}
+		}
+	} else {
+		p.streams[stream.userID] = userStreams
+	}
+	closed := p.closed
+	p.mu.Unlock()
+
+	logger.Debug().Interface(kName, p.iName).Str("userId", stream.userID.Hex()).Bool("offline", offline).Msg("Disconnected presence stream")
+	// nobody is left to tell when the server shuts down
+	if !offline || closed {
+		return
+	}
+	for i := range stopped {
+		p.send(p.online(stoppedFor[i]), models.RealtimeEvent{Type: models.RealtimeEventActivity, Data: stopped[i]})
+	}
+	chats, err := p.chatRepo.ListByUserId(ctx, stream.userID.Hex(), 1, 0)
+	if err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Msg("Failed to list chats of user going offline")
+		return
+	}
+	p.send(p.audience(ctx, stream.userID, chats), models.RealtimeEvent{
+		Type: models.RealtimeEventPresence,
+		Data: models.Presence{UserID: stream.userID, LastSeenAt: &now},
+	})
+}
+
+// flushLastSeen persists when the users went offline, they are kept for the next flush when it fails
+func (p *PresenceService) flushLastSeen(ctx context.Context) {
+	const kName = "flushLastSeen"
+	p.mu.Lock()
+	lastSeen := make([]models.LastSeen, 0, len(p.lastSeen))
+	for userID, at := range p.lastSeen {
+		lastSeen = append(lastSeen, models.LastSeen{UserID: userID, LastSeenAt: at})
+	}
+	p.mu.Unlock()
+	if len(lastSeen) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "PresenceService", "FlushLastSeen")
+	defer span.End()
+	logger := logging.FromContext(ctx, p.log)
+	if err := p.lastSeenRepo.Save(ctx, lastSeen); err != nil {
+		logger.Error().Interface(kName, p.iName).Err(err).Int("users", len(lastSeen)).Msg("Failed to save last seen")
+		return
+	}
+	p.mu.Lock()
+	for _, seen := range lastSeen {
+		// unless the user came back & left again meanwhile
+		if p.lastSeen[seen.UserID].Equal(seen.LastSeenAt) {
+			delete(p.lastSeen, seen.UserID)
+		}
+	}
+	p.mu.Unlock()
+	logger.Debug().Interface(kName, p.iName).Int("users", len(lastSeen)).Msg("Saved last seen")
+}
+
+// expireActivities forgets the indicators the participants no longer show
+func (p *PresenceService) expireActivities() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, active := range p.activities {
+		if p.now().Sub(active.sentAt) >= ActivityTTL {
+			delete(p.activities, key)
+		}
+	}
+}
+
+// audience returns the online participants of chats that may see the presence of userID
+func (p *PresenceService) audience(ctx context.Context, userID primitive.ObjectID, chats []models.Chat) []primitive.ObjectID {
+	switch p.visibility(ctx, userID) {
+	case models.SettingsPrefLastActiveVisibilityNobody:
+		return nil
+	case models.SettingsPrefLastActiveVisibilityContacts:
+		return p.online(coParticipants(userID, chats, true))
+	}
+	return p.online(coParticipants(userID, chats, false))
+}
+
+// visible reports whether the viewer may see the presence of userID given their visibility, contacts are the direct
+// contacts of the viewer
+func visible(visibility string, userID primitive.ObjectID, viewerID primitive.ObjectID, contacts []primitive.ObjectID) bool {
+	switch visibility {
+	case models.SettingsPrefLastActiveVisibilityNobody:
+		return false
+	case models.SettingsPrefLastActiveVisibilityContacts:
+		return slices.Contains(contacts, userID) || userID == viewerID
+	}
+	return true
+}
+
+// visibility returns the LastActiveVisibility of the user, everyone when they have no settings
+func (p *PresenceService) visibility(ctx context.Context, userID primitive.ObjectID) string {
+	return p.visibilities(ctx, []primitive.ObjectID{userID})[userID]
+}
+
+// visibilities returns the LastActiveVisibility of the users with a single settings query, everyone for the users
+// without settings
+func (p *PresenceService) visibilities(ctx context.Context, userIDs []primitive.ObjectID) map[primitive.ObjectID]string {
+	const kName = "visibilities"
+	result := make(map[primitive.ObjectID]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return result
+	}
+	settingsList, err := p.settingsRepo.ListByUserIDs(ctx, userIDs)
+	if err != nil {
+		// hidden rather than shown against the users' choice
+		logging.FromContext(ctx, p.log).Error().Interface(kName, p.iName).Err(err).Int("users", len(userIDs)).Msg("Failed to get settings of users")
+		for _, userID := range userIDs {
+			result[userID] = models.SettingsPrefLastActiveVisibilityNobody
+		}
+		return result
+	}
+	for _, userID := range userIDs {
+		result[userID] = models.SettingsPrefLastActiveVisibilityEveryone
+	}
+	for _, settings := range settingsList {
+		if settings.Preferences.LastActiveVisibility != "" {
+			result[settings.UserId] = settings.Preferences.LastActiveVisibility
+		}
+	}
+	return result
+}
+
+// online filters the users with a connected stream
+func (p *PresenceService) online(userIDs []primitive.ObjectID) []primitive.ObjectID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var online []primitive.ObjectID
+	for _, userID := range userIDs {
+		if len(p.streams[userID]) > 0 {
+			online = append(online, userID)
+		}
+	}
+	return online
+}
+
+// send queues event on the streams of the users
+func (p *PresenceService) send(userIDs []primitive.ObjectID, event models.RealtimeEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, userID := range userIDs {
+		for _, stream := range p.streams[userID] {
+			stream.send(event)
+		}
+	}
+}
+
+// send queues event unless the stream is closed or the client too slow to keep up, the caller holds the service's lock
+func (s *PresenceStream) send(event models.RealtimeEvent) {
+	if s.done {
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+	}
+}
+
+// coParticipants returns the other participants of chats, only of the direct ones when direct is set
+func coParticipants(userID primitive.ObjectID, chats []models.Chat, direct bool) []primitive.ObjectID {
+	var userIDs []primitive.ObjectID
+	for _, chat := range chats {
+		if direct && chat.Type != models.ChatTypeDirect {
+			continue
+		}
+		for _, participant := range chat.Participants {
+			if participant != userID && !slices.Contains(userIDs, participant) {
+				userIDs = append(userIDs, participant)
+			}
+		}
+	}
+	return userIDs
+}
+
+// directContacts are the users sharing a direct chat with userID, the contacts of the LastActiveVisibility
+func directContacts(userID primitive.ObjectID, chats []models.Chat) []primitive.ObjectID {
+	return coParticipants(userID, chats, true)
+}
+
+// without returns userIDs except userID
+func without(userIDs []primitive.ObjectID, userID primitive.ObjectID) []primitive.ObjectID {
+	return slices.DeleteFunc(slices.Clone(userIDs), func(other primitive.ObjectID) bool { return other == userID })
+}
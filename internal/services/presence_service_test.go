@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository/memory"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+// presenceFixture is bob with alice, his direct contact, & carol, who only shares a group with him
+type presenceFixture struct {
+	service           *PresenceService
+	settings          repository.ISettingsRepository
+	lastSeen          *failingLastSeenRepository
+	clock             time.Time
+	alice, bob, carol primitive.ObjectID
+	directChat        primitive.ObjectID
+}
+
+// failingLastSeenRepository fails saving while fail is set
+type failingLastSeenRepository struct {
+	repository.ILastSeenRepository
+	fail bool
+}
+
+func (f *failingLastSeenRepository) Save(ctx context.Context, lastSeen []models.LastSeen) error {
+	if f.fail {
+		return errors.New("save failed")
+	}
+	return f.ILastSeenRepository.Save(ctx, lastSeen)
+}
+
+func newPresenceFixture(t *testing.T) *presenceFixture {
+	t.Helper()
+	ctx := context.Background()
+	log := zerolog.Nop()
+	f := &presenceFixture{
+		settings: memory.NewSettingsRepository(),
+		lastSeen: &failingLastSeenRepository{ILastSeenRepository: memory.NewLastSeenRepository()},
+		clock:    time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC),
+		alice:    primitive.NewObjectID(),
+		bob:      primitive.NewObjectID(),
+		carol:    primitive.NewObjectID(),
+	}
+	chats := memory.NewChatRepository()
+	direct, err := chats.Create(ctx, &models.Chat{Type: models.ChatTypeDirect, Participants: []primitive.ObjectID{f.alice, f.bob}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = chats.Create(ctx, &models.Chat{Type: models.ChatTypeGroup, Participants: []primitive.ObjectID{f.bob, f.carol}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.directChat = direct.ID
+	f.service = NewPresenceService(&log, f.lastSeen, f.settings, chats).(*PresenceService)
+	f.service.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *presenceFixture) setVisibility(t *testing.T, userID primitive.ObjectID, visibility string) {
+	t.Helper()
+	settings := models.GetSettingsDefaultsFromHeaders(map[string]string{})
+	settings.UserId = userID
+	settings.Preferences.LastActiveVisibility = visibility
+	if _, err := f.settings.Create(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *presenceFixture) connect(t *testing.T, userID primitive.ObjectID) *PresenceStream {
+	t.Helper()
+	stream, err := f.service.Connect(context.Background(), userID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stream.Close)
+	return stream
+}
+
+// drain returns the events queued on the stream, they are queued before the service's calls return
+func drain(stream *PresenceStream) []models.RealtimeEvent {
+	var events []models.RealtimeEvent
+	for {
+		select {
+		case event, ok := <-stream.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// presences returns the presence events of userID among events
+func presences(events []models.RealtimeEvent, userID primitive.ObjectID) []models.Presence {
+	var result []models.Presence
+	for _, event := range events {
+		if presence, ok := event.Data.(models.Presence); ok && event.Type == models.RealtimeEventPresence && presence.UserID == userID {
+			result = append(result, presence)
+		}
+	}
+	return result
+}
+
+func activities(events []models.RealtimeEvent) []models.ChatActivity {
+	var result []models.ChatActivity
+	for _, event := range events {
+		if activity, ok := event.Data.(models.ChatActivity); ok && event.Type == models.RealtimeEventActivity {
+			result = append(result, activity)
+		}
+	}
+	return result
+}
+
+func TestPresenceVisibility(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		visibility string
+		alice      bool // bob's direct contact
+		carol      bool // shares a group with bob
+	}{
+		{visibility: models.SettingsPrefLastActiveVisibilityEveryone, alice: true, carol: true},
+		{visibility: models.SettingsPrefLastActiveVisibilityContacts, alice: true, carol: false},
+		{visibility: models.SettingsPrefLastActiveVisibilityNobody, alice: false, carol: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.visibility, func(t *testing.T) {
+			f := newPresenceFixture(t)
+			f.setVisibility(t, f.bob, tt.visibility)
+			viewers := map[primitive.ObjectID]bool{f.alice: tt.alice, f.carol: tt.carol}
+
+			// the snapshot a viewer connecting after bob starts with
+			bobStream := f.connect(t, f.bob)
+			streams := map[primitive.ObjectID]*PresenceStream{}
+			for viewerID, sees := range viewers {
+				streams[viewerID] = f.connect(t, viewerID)
+				got := presences(drain(streams[viewerID]), f.bob)
+				if sees != (len(got) == 1 && got[0].Online) {
+					t.Fatalf("viewer %s: expected bob in the snapshot %v, got %+v", viewerID.Hex(), sees, got)
+				}
+			}
+
+			// the events of bob going offline & online again
+			bobStream.Close()
+			f.connect(t, f.bob)
+			for viewerID, sees := range viewers {
+				got := presences(drain(streams[viewerID]), f.bob)
+				if !sees {
+					if len(got) != 0 {
+						t.Fatalf("viewer %s: expected no events of bob, got %+v", viewerID.Hex(), got)
+					}
+					continue
+				}
+				if len(got) != 2 || got[0].Online || got[0].LastSeenAt == nil || !got[1].Online {
+					t.Fatalf("viewer %s: expected bob offline then online, got %+v", viewerID.Hex(), got)
+				}
+			}
+
+			for viewerID, sees := range viewers {
+				presence, err := f.service.GetPresence(ctx, viewerID.Hex(), f.bob.Hex())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if presence.Online != sees {
+					t.Fatalf("viewer %s: expected online %v, got %+v", viewerID.Hex(), sees, presence)
+				}
+			}
+			// users always see themselves
+			presence, err := f.service.GetPresence(ctx, f.bob.Hex(), f.bob.Hex())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !presence.Online {
+				t.Fatalf("expected bob to see himself online, got %+v", presence)
+			}
+		})
+	}
+
+	t.Run("last seen is hidden too", func(t *testing.T) {
+		f := newPresenceFixture(t)
+		f.setVisibility(t, f.bob, models.SettingsPrefLastActiveVisibilityContacts)
+		f.connect(t, f.bob).Close()
+
+		presence, err := f.service.GetPresence(ctx, f.alice.Hex(), f.bob.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if presence.Online || presence.LastSeenAt == nil || !presence.LastSeenAt.Equal(f.clock) {
+			t.Fatalf("expected alice to see when bob was last seen, got %+v", presence)
+		}
+		presence, err = f.service.GetPresence(ctx, f.carol.Hex(), f.bob.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if presence.Online || presence.LastSeenAt != nil {
+			t.Fatalf("expected carol to see nothing of bob, got %+v", presence)
+		}
+	})
+}
+
+func TestPresenceMultipleStreams(t *testing.T) {
+	ctx := context.Background()
+	f := newPresenceFixture(t)
+	alice := f.connect(t, f.alice)
+	drain(alice)
+
+	phone := f.connect(t, f.bob)
+	laptop := f.connect(t, f.bob)
+	if got := presences(drain(alice), f.bob); len(got) != 1 || !got[0].Online {
+		t.Fatalf("expected bob online once, got %+v", got)
+	}
+
+	// bob stays online with his laptop
+	phone.Close()
+	drain(phone)
+	if _, ok := <-phone.Events(); ok {
+		t.Fatal("expected the events of the closed stream to be closed")
+	}
+	if got := presences(drain(alice), f.bob); len(got) != 0 {
+		t.Fatalf("expected no events while bob has a stream left, got %+v", got)
+	}
+	presence, err := f.service.GetPresence(ctx, f.alice.Hex(), f.bob.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !presence.Online {
+		t.Fatalf("expected bob online, got %+v", presence)
+	}
+
+	laptop.Close()
+	got := presences(drain(alice), f.bob)
+	if len(got) != 1 || got[0].Online || got[0].LastSeenAt == nil || !got[0].LastSeenAt.Equal(f.clock) {
+		t.Fatalf("expected bob offline, got %+v", got)
+	}
+	presence, err = f.service.GetPresence(ctx, f.alice.Hex(), f.bob.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presence.Online || presence.LastSeenAt == nil {
+		t.Fatalf("expected bob last seen, got %+v", presence)
+	}
+
+	// closing twice is harmless
+	laptop.Close()
+}
+
+func TestPresenceActivity(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("repeated activities are not sent again", func(t *testing.T) {
+		f := newPresenceFixture(t)
+		alice := f.connect(t, f.alice)
+		f.connect(t, f.bob)
+		drain(alice)
+
+		first, err := f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityTyping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.clock = f.clock.Add(ActivityTTL/2 - time.Second)
+		again, err := f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityTyping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !again.ExpiresAt.Equal(*first.ExpiresAt) {
+			t.Fatalf("expected the first expiry %v, got %v", first.ExpiresAt, again.ExpiresAt)
+		}
+		if got := activities(drain(alice)); len(got) != 1 || got[0].Activity != models.ChatActivityTyping {
+			t.Fatalf("expected a single typing event, got %+v", got)
+		}
+
+		// a refresh past half of the TTL is sent, so is another activity
+		f.clock = f.clock.Add(time.Second)
+		if _, err = f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityTyping); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityRecording); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityStopped); err != nil {
+			t.Fatal(err)
+		}
+		got := activities(drain(alice))
+		if len(got) != 3 || got[0].Activity != models.ChatActivityTyping || got[1].Activity != models.ChatActivityRecording ||
+			got[2].Activity != models.ChatActivityStopped || got[2].ExpiresAt != nil {
+			t.Fatalf("expected typing, recording & stopped, got %+v", got)
+		}
+
+		// stopping again tells nobody
+		if _, err = f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityStopped); err != nil {
+			t.Fatal(err)
+		}
+		if got := activities(drain(alice)); len(got) != 0 {
+			t.Fatalf("expected no events, got %+v", got)
+		}
+	})
+
+	t.Run("expired activities are forgotten", func(t *testing.T) {
+		f := newPresenceFixture(t)
+		alice := f.connect(t, f.alice)
+		if _, err := f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityTyping); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.service.SetActivity(ctx, f.alice.Hex(), f.directChat.Hex(), models.ChatActivityTyping); err != nil {
+			t.Fatal(err)
+		}
+
+		f.clock = f.clock.Add(ActivityTTL - time.Second)
+		f.service.expireActivities()
+		if len(f.service.activities) != 2 {
+			t.Fatalf("expected both activities kept before they expire, got %d", len(f.service.activities))
+		}
+		f.clock = f.clock.Add(time.Second)
+		f.service.expireActivities()
+		if len(f.service.activities) != 0 {
+			t.Fatalf("expected the expired activities forgotten, got %d", len(f.service.activities))
+		}
+
+		// the indicator expired on the clients, stopping it sends nothing
+		drain(alice)
+		if _, err := f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityStopped); err != nil {
+			t.Fatal(err)
+		}
+		if got := activities(drain(alice)); len(got) != 0 {
+			t.Fatalf("expected no events, got %+v", got)
+		}
+	})
+
+	t.Run("activities stop when the user goes offline", func(t *testing.T) {
+		f := newPresenceFixture(t)
+		alice := f.connect(t, f.alice)
+		bob := f.connect(t, f.bob)
+		if _, err := f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), models.ChatActivityRecording); err != nil {
+			t.Fatal(err)
+		}
+		drain(alice)
+
+		bob.Close()
+		got := activities(drain(alice))
+		if len(got) != 1 || got[0].Activity != models.ChatActivityStopped || got[0].UserID != f.bob {
+			t.Fatalf("expected bob's recording stopped, got %+v", got)
+		}
+	})
+
+	t.Run("only participants set activities", func(t *testing.T) {
+		f := newPresenceFixture(t)
+		if _, err := f.service.SetActivity(ctx, f.carol.Hex(), f.directChat.Hex(), models.ChatActivityTyping); err == nil {
+			t.Fatal("expected an error for a user outside the chat")
+		}
+		if _, err := f.service.SetActivity(ctx, f.bob.Hex(), f.directChat.Hex(), "dancing"); err == nil {
+			t.Fatal("expected an error for an unknown activity")
+		}
+	})
+}
+
+func TestPresenceFlushLastSeen(t *testing.T) {
+	ctx := context.Background()
+	f := newPresenceFixture(t)
+	f.connect(t, f.bob).Close()
+	wentOffline := f.clock
+
+	// a failed save keeps the last seen for the next flush
+	f.lastSeen.fail = true
+	f.service.flushLastSeen(ctx)
+	if _, pending := f.service.lastSeen[f.bob]; !pending {
+		t.Fatal("expected the last seen kept after a failed save")
+	}
+	presence, err := f.service.GetPresence(ctx, f.alice.Hex(), f.bob.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presence.LastSeenAt == nil || !presence.LastSeenAt.Equal(wentOffline) {
+		t.Fatalf("expected the pending last seen, got %+v", presence)
+	}
+
+	f.lastSeen.fail = false
+	f.service.flushLastSeen(ctx)
+	if _, pending := f.service.lastSeen[f.bob]; pending {
+		t.Fatal("expected the last seen flushed")
+	}
+	saved, err := f.lastSeen.GetByUserId(ctx, f.bob.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.LastSeenAt.Equal(wentOffline) {
+		t.Fatalf("expected %v saved, got %v", wentOffline, saved.LastSeenAt)
+	}
+
+	// a later last seen replaces the saved one
+	f.clock = f.clock.Add(time.Minute)
+	f.connect(t, f.bob).Close()
+	f.service.flushLastSeen(ctx)
+	saved, err = f.lastSeen.GetByUserId(ctx, f.bob.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.LastSeenAt.Equal(f.clock) {
+		t.Fatalf("expected %v saved, got %v", f.clock, saved.LastSeenAt)
+	}
+}
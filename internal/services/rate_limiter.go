@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"time"
+)
+
+// rateLimiter allows limit calls per key in fixed windows, the windows are stored so every server shares the limit
+type rateLimiter struct {
+	repo   repository.IRateLimitRepository
+	limit  int64
+	window time.Duration
+}
+
+func newRateLimiter(repo repository.IRateLimitRepository, limit int64, window time.Duration) *rateLimiter {
+	return &rateLimiter{repo: repo, limit: limit, window: window}
+}
+
+// allow counts a call under key & reports whether it is within the limit
+func (r *rateLimiter) allow(ctx context.Context, key string, now time.Time) (bool, error) {
+	calls, err := r.repo.Hit(ctx, key, now.Truncate(r.window), r.window)
+	if err != nil {
+		return false, err
+	}
+	return calls <= r.limit, nil
+}
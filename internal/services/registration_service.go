@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+)
+
+type IRegistrationService interface {
+	// Register creates the user with their settings & authentication session, either all of them are stored or none is
+	Register(ctx context.Context, user *models.User, settings *models.Settings) (*models.User, error)
+}
+
+type RegistrationService struct {
+	iName        string
+	log          *zerolog.Logger
+	unitOfWork   repository.IUnitOfWork
+	userRepo     repository.IUserRepository
+	settingsRepo repository.ISettingsRepository
+	authctRepo   repository.IAuthenticationRepository
+}
+
+func NewRegistrationService(log *zerolog.Logger, unitOfWork repository.IUnitOfWork, userRepo repository.IUserRepository, settingsRepo repository.ISettingsRepository, authctRepo repository.IAuthenticationRepository) IRegistrationService {
+	return &RegistrationService{
+		iName:        "RegistrationService",
+		log:          log,
+		unitOfWork:   unitOfWork,
+		userRepo:     userRepo,
+		settingsRepo: settingsRepo,
+		authctRepo:   authctRepo,
+	}
+}
+
+func (s *RegistrationService) Register(ctx context.Context, user *models.User, settings *models.Settings) (*models.User, error) {
+	const kName = "Register"
+	ctx, span := tracing.Start(ctx, "RegistrationService", "Register")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.log)
+
+	var createdUser *models.User
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		// the repositories transform what they store in place & a transaction may run this more than once: work on copies
+		userDoc, settingsDoc := *user, *settings
+
+		var err error
+		createdUser, err = s.userRepo.Create(ctx, &userDoc)
+		if err != nil {
+			return err
+		}
+		userID := createdUser.ID.Hex()
+		repository.OnRollback(ctx, func(ctx context.Context) error { return s.userRepo.Delete(ctx, userID) })
+
+		settingsDoc.UserId = createdUser.ID
+		createdSettings, err := s.settingsRepo.Create(ctx, &settingsDoc)
+		if err != nil {
+			return err
+		}
+		settingsID := createdSettings.ID.Hex()
+		repository.OnRollback(ctx, func(ctx context.Context) error { return s.settingsRepo.Delete(ctx, settingsID) })
+
+		// the session gets its refresh token on the first login
+		auth := models.GetAuthenticationDefaults()
+		auth.UserID = createdUser.ID
+		auth.IsActive = false
+		if _, err = s.authctRepo.Create(ctx, auth); err != nil {
+			return err
+		}
+		repository.OnRollback(ctx, func(ctx context.Context) error { return s.authctRepo.DeleteByUserID(ctx, userID) })
+		return nil
+	})
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Msg("Failed to register user")
+		return nil, err
+	}
+	logger.Info().Interface(kName, s.iName).Str("userId", createdUser.ID.Hex()).Msg("Registered user")
+	return createdUser, nil
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// expiryBatch is the number of expired uploads deleted per query
+const expiryBatch = 100
+
+type IResumableUploadService interface {
+	// CreateUpload starts an upload to a chat the sender takes part in
+	CreateUpload(ctx context.Context, upload *ResumableUpload) (*models.Upload, error)
+	// GetUpload returns the progress of one of userId's uploads
+	GetUpload(ctx context.Context, userId string, id string) (*models.Upload, error)
+	// UploadChunk stores content as the chunk of the upload at offset
+	UploadChunk(ctx context.Context, userId string, id string, offset int64, content io.Reader) (*models.Upload, error)
+	// CompleteUpload assembles the chunks into the upload's media, completing it again returns the same media
+	CompleteUpload(ctx context.Context, userId string, id string) (*models.Media, error)
+	// DeleteUpload cancels the upload & deletes its chunks, the media of a completed upload is kept
+	DeleteUpload(ctx context.Context, userId string, id string) error
+	// ExpireUploads deletes the expired uploads & their chunks every interval until ctx is done
+	ExpireUploads(ctx context.Context, interval time.Duration) error
+}
+
+// ResumableUpload is the file a client is about to send in chunks
+type ResumableUpload struct {
+	ChatID    string
+	SenderID  string // the authenticated uploader
+	MediaType string // declared by the client, it must match the detected content when set
+	FileName  string
+	Length    int64
+}
+
+type ResumableUploadService struct {
+	iName      string
+	log        *zerolog.Logger
+	uploadRepo repository.IUploadRepository
+	mediaRepo  repository.MediaRepository
+	chatRepo   repository.ChatRepository
+	blobStore  storage.IBlobStore
+	urls       *MediaURLs
+	blobs      *MediaBlobs
+	quotas     IStorageQuotaService
+	limits     MediaLimits
+}
+
+func NewResumableUploadService(log *zerolog.Logger, uploadRepo repository.IUploadRepository, mediaRepo repository.MediaRepository, chatRepo repository.ChatRepository, blobStore storage.IBlobStore, urls *MediaURLs, blobs *MediaBlobs, quotas IStorageQuotaService, limits MediaLimits) *ResumableUploadService {
+	return &ResumableUploadService{
+		iName:      "ResumableUploadService",
+		log:        log,
+		uploadRepo: uploadRepo,
+		mediaRepo:  mediaRepo,
+		chatRepo:   chatRepo,
+		blobStore:  blobStore,
+		urls:       urls,
+		blobs:      blobs,
+		quotas:     quotas,
+		limits:     limits,
+	}
+}
+
+func (r *ResumableUploadService) CreateUpload(ctx context.Context, upload *ResumableUpload) (*models.Upload, error) {
+	const kName = "CreateUpload"
+	ctx, span := tracing.Start(ctx, "ResumableUploadService", "CreateUpload")
+	defer span.End()
+	logger := logging.FromContext(ctx, r.log)
+
+	senderID, err := primitive.ObjectIDFromHex(upload.SenderID)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid sender id", apperrors.InvalidField("senderId", "must be a valid id"))
+	}
+	chat, err := participantChat(ctx, r.chatRepo, upload.ChatID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Length < 1 {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Invalid upload length", apperrors.InvalidField("length", "must be at least 1"))
+	}
+	if upload.Length > r.limits.MaxResumableBytes {
+		return nil, tooLarge("The file is larger than the upload limit of " + strconv.FormatInt(r.limits.MaxResumableBytes, 10) + " bytes")
+	}
+	mediaTypes := []string{models.MediaTypeImage, models.MediaTypeVideo, models.MediaTypeAudio, models.MediaTypeDocument}
+	if upload.MediaType != "" && !slices.Contains(mediaTypes, upload.MediaType) {
+		return nil, apperrors.Validation(apperrors.CodeValidation, "Invalid media type",
+			apperrors.InvalidField("mediaType", "must be one of image, video, audio or document"))
+	}
+	if err = r.quotas.Check(ctx, upload.SenderID, upload.Length); err != nil {
+		return nil, err
+	}
+
+	created, err := r.uploadRepo.Create(ctx, &models.Upload{
+		ChatID:    chat.ID,
+		SenderID:  senderID,
+		MediaID:   primitive.NewObjectID(),
+		MediaType: upload.MediaType,
+		FileName:  fileName(upload.FileName),
+		Length:    upload.Length,
+		Status:    models.UploadStatusUploading,
+		ExpiresAt: time.Now().Add(r.limits.UploadExpiry),
+	})
+	if err != nil {
+		logger.Error().Interface(kName, r.iName).Err(err).Msg("Failed to create upload")
+		return nil, err
+	}
+	logger.Info().Interface(kName, r.iName).Str("uploadId", created.ID.Hex()).Int64("length", created.Length).Msg("Created resumable upload")
+	return created, nil
+}
+
+func (r *ResumableUploadService) GetUpload(ctx context.Context, userId string, id string) (*models.Upload, error) {
+	ctx, span := tracing.Start(ctx, "ResumableUploadService", "GetUpload")
+	defer span.End()
+	return r.senderUpload(ctx, userId, id)
+}
+
+func (r *ResumableUploadService) UploadChunk(ctx context.Context, userId string, id string, offset int64, content io.Reader) (*models.Upload, error) {
+	const kName = "UploadChunk"
+	ctx, span := tracing.Start(ctx, "ResumableUploadService", "UploadChunk")
+	defer span.End()
+	logger := logging.FromContext(ctx, r.log)
+
+	upload, err := r.senderUpload(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	// checked before storing the chunk to spare the transfer, AppendPart checks again against concurrent chunks
+	if upload.Status != models.UploadStatusUploading || upload.Offset != offset {
+		return nil, repository.UploadConflict(upload)
+	}
+
+	// the first chunk decides the type of the whole file
+	contentType := ""
+	if offset == 0 {
+		if content, contentType, _, err = sniffContent(content, r.limits.AllowedTypes, upload.MediaType); err != nil {
+			logger.Info().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Rejected upload")
+			return nil, err
+		}
+	}
+
+	part := "uploads/" + upload.ID.Hex() + "/" + primitive.NewObjectID().Hex()
+	limit := min(upload.Length-upload.Offset, r.limits.ChunkMaxBytes)
+	size, err := r.blobStore.Put(ctx, part, &limitedReader{r: content, remaining: limit}, "application/octet-stream")
+	if errors.Is(err, errUploadTooLarge) {
+		return nil, tooLarge("The chunk is larger than " + strconv.FormatInt(limit, 10) + " bytes")
+	}
+	if err != nil {
+		logger.Error().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Failed to store chunk")
+		return nil, apperrors.Internal(apperrors.CodeInternal, "Failed to store the chunk").WithErr(err)
+	}
+	if size == 0 {
+		r.deleteBlobs(ctx, part)
+		return nil, apperrors.Validation(apperrors.CodeValidation, "The chunk is empty")
+	}
+
+	updated, err := r.uploadRepo.AppendPart(ctx, id, offset, part, size, contentType, time.Now().Add(r.limits.UploadExpiry))
+	if err != nil {
+		logger.Info().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Chunk was not recorded, deleting it")
+		r.deleteBlobs(ctx, part)
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *ResumableUploadService) CompleteUpload(ctx context.Context, userId string, id string) (*models.Media, error) {
+	const kName = "CompleteUpload"
+	ctx, span := tracing.Start(ctx, "ResumableUploadService", "CompleteUpload")
+	defer span.End()
+	logger := logging.FromContext(ctx, r.log)
+
+	upload, err := r.senderUpload(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status == models.UploadStatusCompleted {
+		return r.signedMedia(ctx, upload.MediaID.Hex())
+	}
+	if upload.Offset != upload.Length {
+		return nil, apperrors.Conflict(apperrors.CodeUploadIncomplete,
+			"The upload has received "+strconv.FormatInt(upload.Offset, 10)+" of "+strconv.FormatInt(upload.Length, 10)+" bytes")
+	}
+	if _, err = participantChat(ctx, r.chatRepo, upload.ChatID.Hex(), upload.SenderID); err != nil {
+		return nil, err
+	}
+	// the quota was checked on creation, other uploads may have filled it since; it is checked again once recorded
+	if err = r.quotas.Check(ctx, upload.SenderID.Hex(), upload.Length); err != nil {
+		return nil, err
+	}
+
+	// the media id is fixed on creation, so only one of concurrent completions records the media
+	media := &models.Media{
+		Id:              upload.MediaID,
+		ChatId:          upload.ChatID,
+		SenderId:        upload.SenderID,
+		MediaType:       models.MediaTypeOf(upload.ContentType),
+		ContentType:     upload.ContentType,
+		FileName:        upload.FileName,
+		FileSize:        int(upload.Length),
+		UploadTimestamp: primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	parts := &partsReader{ctx: ctx, blobStore: r.blobStore, keys: upload.Parts}
+	size, err := r.blobs.Store(ctx, media, parts)
+	_ = parts.Close()
+	if err == nil && size != upload.Length {
+		r.blobs.Release(ctx, media)
+		err = errors.New("assembled " + strconv.FormatInt(size, 10) + " bytes of " + strconv.FormatInt(upload.Length, 10))
+	}
+	if err != nil {
+		logger.Error().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Failed to assemble upload")
+		return nil, apperrors.Wrap(err, "Failed to assemble the upload")
+	}
+
+	// the loser of concurrent completions drops its reference, the blob is shared with the winner's media
+	if err = r.mediaRepo.Create(ctx, media); err != nil {
+		r.blobs.Release(ctx, media)
+		if !errors.Is(err, apperrors.ErrConflict) {
+			logger.Error().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Failed to record media, releasing its content")
+			return nil, err
+		}
+	} else if err = keepWithinQuota(ctx, r.quotas, r.mediaRepo, r.blobs, media); err != nil {
+		// the upload keeps its chunks, it completes once storage is freed
+		logger.Info().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Removed media above the storage quota")
+		return nil, err
+	}
+
+	previous, err := r.uploadRepo.Complete(ctx, id, time.Now().Add(r.limits.UploadExpiry))
+	if err != nil && !errors.Is(err, apperrors.ErrConflict) {
+		logger.Error().Interface(kName, r.iName).Err(err).Str("uploadId", id).Msg("Failed to complete upload")
+		return nil, err
+	}
+	if previous != nil {
+		r.deleteBlobs(ctx, previous.Parts...)
+	}
+	logger.Info().Interface(kName, r.iName).Str("uploadId", id).Str("mediaId", media.Id.Hex()).Int64("size", size).Msg("Completed resumable upload")
+	return r.signedMedia(ctx, media.Id.Hex())
+}
+
+func (r *ResumableUploadService) DeleteUpload(ctx context.Context, userId string, id string) error {
+	ctx, span := tracing.Start(ctx, "ResumableUploadService", "DeleteUpload")
+	defer span.End()
+
+	upload, err := r.senderUpload(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+	// the record goes first, like media, chunks without a record are only wasted space
+	if err = r.uploadRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.deleteBlobs(ctx, upload.Parts...)
+	return nil
+}
+
+func (r *ResumableUploadService) ExpireUploads(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			r.expireUploads(ctx)
+		}
+	}
+}
+
+// expireUploads deletes the uploads that have expired so far
+func (r *ResumableUploadService) expireUploads(ctx context.Context) {
+	const kName = "expireUploads"
+	ctx, span := tracing.Start(ctx, "ResumableUploadService", "ExpireUploads")
+	defer span.End()
+	logger := logging.FromContext(ctx, r.log)
+
+	now := time.Now()
+	expired := 0
+	for {
+		uploads, err := r.uploadRepo.ListExpired(ctx, now, expiryBatch)
+		if err != nil {
+			logger.Error().Interface(kName, r.iName).Err(err).Msg("Failed to list expired uploads")
+			return
+		}
+		for _, upload := range uploads {
+			// the chunks go first here, the record is the only reference to them
+			for _, part := range upload.Parts {
+				if err = r.blobStore.Delete(ctx, part); err != nil {
+					logger.Error().Interface(kName, r.iName).Err(err).Str("uploadId", upload.ID.Hex()).Msg("Failed to delete chunk of expired upload, retrying later")
+					return
+				}
+			}
+			if err = r.uploadRepo.Delete(ctx, upload.ID.Hex()); err != nil {
+				logger.Error().Interface(kName, r.iName).Err(err).Str("uploadId", upload.ID.Hex()).Msg("Failed to delete expired upload")
+				return
+			}
+			expired++
+		}
+		if len(uploads) < expiryBatch {
+			break
+		}
+	}
+	if expired > 0 {
+		logger.Info().Interface(kName, r.iName).Int("uploads", expired).Msg("Deleted expired uploads")
+	}
+}
+
+// signedMedia returns the media with its download URL
+func (r *ResumableUploadService) signedMedia(ctx context.Context, id string) (*models.Media, error) {
+	media, err := r.mediaRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.urls.Sign(media)
+	return media, nil
+}
+
+// senderUpload returns the upload when userId is its sender
+func (r *ResumableUploadService) senderUpload(ctx context.Context, userId string, id string) (*models.Upload, error) {
+	upload, err := r.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.SenderID.Hex() != userId {
+		return nil, apperrors.Forbidden(apperrors.CodeForbidden, "Only the uploader can access the upload")
+	}
+	return upload, nil
+}
+
+// deleteBlobs deletes keys from the blob store, failures only leave wasted space & are logged
+func (r *ResumableUploadService) deleteBlobs(ctx context.Context, keys ...string) {
+	logger := logging.FromContext(ctx, r.log)
+	for _, key := range keys {
+		if err := r.blobStore.Delete(context.WithoutCancel(ctx), key); err != nil {
+			logger.Error().Interface("deleteBlobs", r.iName).Err(err).Str("key", key).Msg("Failed to delete blob")
+		}
+	}
+}
+
+// partsReader reads the blobs under keys one after another, opening each only once the previous is read
+type partsReader struct {
+	ctx       context.Context
+	blobStore storage.IBlobStore
+	keys      []string
+	current   io.ReadCloser
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.current == nil {
+			if len(p.keys) == 0 {
+				return 0, io.EOF
+			}
+			current, err := p.blobStore.Get(p.ctx, p.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			p.current, p.keys = current, p.keys[1:]
+		}
+		n, err := p.current.Read(b)
+		if errors.Is(err, io.EOF) {
+			err = p.current.Close()
+			p.current = nil
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.current == nil {
+		return nil
+	}
+	err := p.current.Close()
+	p.current = nil
+	return err
+}
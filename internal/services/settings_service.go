@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 )
 
 type ISettingsService interface {
@@ -25,21 +26,31 @@ func NewSettingsService(repo repository.ISettingsRepository) ISettingsService {
 }
 
 func (s *SettingsService) Create(ctx context.Context, settings *models.Settings) (*models.Settings, error) {
+	ctx, span := tracing.Start(ctx, "SettingsService", "Create")
+	defer span.End()
 	return s.repo.Create(ctx, settings)
 }
 
 func (s *SettingsService) GetById(ctx context.Context, settingsId string) (*models.Settings, error) {
+	ctx, span := tracing.Start(ctx, "SettingsService", "GetById")
+	defer span.End()
 	return s.repo.GetByID(ctx, settingsId)
 }
 
 func (s *SettingsService) GetByUserId(ctx context.Context, userId string) (*models.Settings, error) {
+	ctx, span := tracing.Start(ctx, "SettingsService", "GetByUserId")
+	defer span.End()
 	return s.repo.GetByUserID(ctx, userId)
 }
 
 func (s *SettingsService) Update(ctx context.Context, settings *models.Settings) error {
+	ctx, span := tracing.Start(ctx, "SettingsService", "Update")
+	defer span.End()
 	return s.repo.Update(ctx, settings)
 }
 
 func (s *SettingsService) Delete(ctx context.Context, settingsId string) error {
+	ctx, span := tracing.Start(ctx, "SettingsService", "Delete")
+	defer span.End()
 	return s.repo.Delete(ctx, settingsId)
 }
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
+	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/apperrors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// IStorageQuotaService charges users for the bytes their media take in the blob store: a file uploaded again is
+// deduplicated & only charged once.
+type IStorageQuotaService interface {
+	// GetUsage returns the storage userId's media take, broken down by chat & media type, & the quota of its plan
+	GetUsage(ctx context.Context, userId string) (*StorageReport, error)
+	// Check fails with STORAGE_QUOTA_EXCEEDED unless userId may store additional bytes more
+	Check(ctx context.Context, userId string, additional int64) error
+	// CheckRecorded fails with STORAGE_QUOTA_EXCEEDED when the media recorded up to media, in the order they were
+	// recorded, exceed the quota of its sender. Of concurrent uploads above the quota only the later ones fail.
+	CheckRecorded(ctx context.Context, media *models.Media) error
+}
+
+// PlanQuotas are the storage quotas in bytes per subscription plan, 0 is unlimited
+type PlanQuotas struct {
+	Free       int64
+	Basic      int64
+	Premium    int64
+	Enterprise int64 // shared by the members of an enterprise
+}
+
+// of returns the quota of planType
+func (q PlanQuotas) of(planType string) int64 {
+	switch planType {
+	case models.PlanBasic:
+		return q.Basic
+	case models.PlanPremium:
+		return q.Premium
+	case models.PlanEnterprise:
+		return q.Enterprise
+	default:
+		return q.Free
+	}
+}
+
+// StorageReport is the storage a user takes against the quota of its plan
+type StorageReport struct {
+	PlanType     string
+	EnterpriseID primitive.ObjectID // set on enterprise plans, whose members share the quota
+	QuotaBytes   int64              // 0 is unlimited
+	UsedBytes    int64              // counted against QuotaBytes, the media of every member on enterprise plans
+	UserBytes    int64              // of the media of the user
+	ByChat       []models.StorageUsage
+	ByMediaType  []models.StorageUsage
+}
+
+type StorageQuotaService struct {
+	iName            string
+	log              *zerolog.Logger
+	subscriptionRepo repository.ISubscriptionRepository
+	mediaRepo        repository.MediaRepository
+	quotas           PlanQuotas
+}
+
+func NewStorageQuotaService(log *zerolog.Logger, subscriptionRepo repository.ISubscriptionRepository, mediaRepo repository.MediaRepository, quotas PlanQuotas) *StorageQuotaService {
+	return &StorageQuotaService{
+		iName:            "StorageQuotaService",
+		log:              log,
+		subscriptionRepo: subscriptionRepo,
+		mediaRepo:        mediaRepo,
+		quotas:           quotas,
+	}
+}
+
+func (s *StorageQuotaService) GetUsage(ctx context.Context, userId string) (*StorageReport, error) {
+	const kName = "GetUsage"
+	ctx, span := tracing.Start(ctx, "StorageQuotaService", "GetUsage")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.log)
+
+	report, err := s.report(ctx, userId, true, primitive.NilObjectID)
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Str("userId", userId).Msg("Failed to get storage usage")
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *StorageQuotaService) Check(ctx context.Context, userId string, additional int64) error {
+	const kName = "Check"
+	ctx, span := tracing.Start(ctx, "StorageQuotaService", "Check")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.log)
+
+	report, err := s.report(ctx, userId, false, primitive.NilObjectID)
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Str("userId", userId).Msg("Failed to check storage quota")
+		return err
+	}
+	if report.QuotaBytes > 0 && report.UsedBytes+additional > report.QuotaBytes {
+		logger.Info().Interface(kName, s.iName).Str("userId", userId).Str("plan", report.PlanType).
+			Int64("used", report.UsedBytes).Int64("additional", additional).Int64("quota", report.QuotaBytes).Msg("Storage quota exceeded")
+		return quotaExceeded(report)
+	}
+	return nil
+}
+
+func (s *StorageQuotaService) CheckRecorded(ctx context.Context, media *models.Media) error {
+	const kName = "CheckRecorded"
+	ctx, span := tracing.Start(ctx, "StorageQuotaService", "CheckRecorded")
+	defer span.End()
+	logger := logging.FromContext(ctx, s.log)
+
+	// the media recorded after this one are left out, they are checked when they are recorded
+	report, err := s.report(ctx, media.SenderId.Hex(), false, media.Id)
+	if err != nil {
+		logger.Error().Interface(kName, s.iName).Err(err).Str("mediaId", media.Id.Hex()).Msg("Failed to check storage quota")
+		return err
+	}
+	if report.QuotaBytes > 0 && report.UsedBytes > report.QuotaBytes {
+		logger.Info().Interface(kName, s.iName).Str("userId", media.SenderId.Hex()).Str("mediaId", media.Id.Hex()).Str("plan", report.PlanType).
+			Int64("used", report.UsedBytes).Int64("quota", report.QuotaBytes).Msg("Storage quota exceeded")
+		return quotaExceeded(report)
+	}
+	return nil
+}
+
+// quotaExceeded is the error of storage above the quota of report's plan
+func quotaExceeded(report *StorageReport) error {
+	return apperrors.Forbidden(apperrors.CodeStorageQuotaExceeded,
+		"The storage quota of "+strconv.FormatInt(report.QuotaBytes, 10)+" bytes of the "+report.PlanType+" plan is exceeded")
+}
+
+// report sums the storage of userId & of the members sharing its quota, with the breakdown of its own media
+// when detailed. Only the media up to the id until are counted unless it is zero.
+func (s *StorageQuotaService) report(ctx context.Context, userId string, detailed bool, until primitive.ObjectID) (*StorageReport, error) {
+	userID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID, "Invalid user id")
+	}
+	now := time.Now()
+	report := &StorageReport{PlanType: models.PlanFree}
+	subscription, err := s.subscriptionRepo.GetActiveByUserId(ctx, userId, now)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, err
+	}
+	// subscriptions of unknown plans grant the free plan
+	if subscription != nil && slices.Contains([]string{models.PlanBasic, models.PlanPremium, models.PlanEnterprise}, subscription.PlanType) {
+		report.PlanType = subscription.PlanType
+	}
+	report.QuotaBytes = s.quotas.of(report.PlanType)
+
+	members := []primitive.ObjectID{userID}
+	if report.PlanType == models.PlanEnterprise && subscription != nil && !subscription.EnterpriseId.IsZero() {
+		report.EnterpriseID = subscription.EnterpriseId
+		if members, err = s.subscriptionRepo.ListEnterpriseMembers(ctx, subscription.EnterpriseId.Hex(), now); err != nil {
+			return nil, err
+		}
+	}
+	usage, err := s.mediaRepo.StorageUsage(ctx, members, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range usage {
+		report.UsedBytes += u.Bytes
+	}
+	if !detailed {
+		return report, nil
+	}
+
+	// the breakdown only holds the user's own media, members of an enterprise do not see each other's chats
+	if len(members) != 1 || members[0] != userID {
+		if usage, err = s.mediaRepo.StorageUsage(ctx, []primitive.ObjectID{userID}, until); err != nil {
+			return nil, err
+		}
+	}
+	byChat, byMediaType := map[primitive.ObjectID]*models.StorageUsage{}, map[string]*models.StorageUsage{}
+	for _, u := range usage {
+		report.UserBytes += u.Bytes
+		if byChat[u.ChatId] == nil {
+			byChat[u.ChatId] = &models.StorageUsage{ChatId: u.ChatId}
+		}
+		byChat[u.ChatId].Bytes += u.Bytes
+		byChat[u.ChatId].Count += u.Count
+		if byMediaType[u.MediaType] == nil {
+			byMediaType[u.MediaType] = &models.StorageUsage{MediaType: u.MediaType}
+		}
+		byMediaType[u.MediaType].Bytes += u.Bytes
+		byMediaType[u.MediaType].Count += u.Count
+	}
+	report.ByChat = largestFirst(byChat)
+	report.ByMediaType = largestFirst(byMediaType)
+	return report, nil
+}
+
+// largestFirst lists the usage of groups by descending size
+func largestFirst[K comparable](groups map[K]*models.StorageUsage) []models.StorageUsage {
+	usage := make([]models.StorageUsage, 0, len(groups))
+	for _, group := range groups {
+		usage = append(usage, *group)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Bytes != usage[j].Bytes {
+			return usage[i].Bytes > usage[j].Bytes
+		}
+		return usage[i].ChatId.Hex()+usage[i].MediaType < usage[j].ChatId.Hex()+usage[j].MediaType
+	})
+	return usage
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/models"
 	"github.com/mcsamuelshoko/telko-moment-server/internal/repository"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
@@ -31,6 +32,8 @@ func NewUserService(log *zerolog.Logger, repo repository.IUserRepository) IUserS
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "CreateUser")
+	defer span.End()
 	//// Add business logic here
 	//user.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
 	//user.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
@@ -39,31 +42,45 @@ func (s *UserService) CreateUser(ctx context.Context, user *models.User) (*model
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "GetUserByID")
+	defer span.End()
 
 	return s.repo.GetByID(ctx, id)
 }
 
 func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "GetUserByUsername")
+	defer span.End()
 
 	return s.repo.GetByUsername(ctx, username)
 }
 
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "GetUserByEmail")
+	defer span.End()
 	return s.repo.GetByEmail(ctx, email)
 }
 
 func (s *UserService) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "GetUserByPhoneNumber")
+	defer span.End()
 	return s.repo.GetByPhoneNumber(ctx, phoneNumber)
 }
 
 func (s *UserService) ListUsers(ctx context.Context, page, limit int) ([]models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "ListUsers")
+	defer span.End()
 	return s.repo.List(ctx, page, limit)
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService", "UpdateUser")
+	defer span.End()
 	return s.repo.Update(ctx, user)
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "UserService", "DeleteUser")
+	defer span.End()
 	return s.repo.Delete(ctx, id)
 }
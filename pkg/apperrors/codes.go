@@ -0,0 +1,74 @@
+package apperrors
+
+// Stable error codes returned in the `code` field of error responses.
+// Clients localize on these, so never rename or reuse a code, only add new ones.
+const (
+	// generic
+	CodeInternal        = "INTERNAL_ERROR"
+	CodeUnavailable     = "SERVICE_UNAVAILABLE"
+	CodeInvalidBody     = "INVALID_REQUEST_BODY"
+	CodeInvalidID       = "INVALID_ID"
+	CodeValidation      = "VALIDATION_FAILED"
+	CodeNotFound        = "NOT_FOUND"
+	CodeRouteNotFound   = "ROUTE_NOT_FOUND"
+	CodeBadRequest      = "BAD_REQUEST"
+	CodeMethodNotAllow  = "METHOD_NOT_ALLOWED"
+	CodeTooLarge        = "PAYLOAD_TOO_LARGE"
+	CodeDuplicate       = "DUPLICATE_RESOURCE"
+	CodeForbidden       = "FORBIDDEN"
+	CodeRateLimited     = "RATE_LIMITED"
+	CodeMissingUserCtx  = "USER_CONTEXT_MISSING"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeResponseInvalid = "RESPONSE_INVALID"
+	CodeNotImplemented  = "NOT_IMPLEMENTED"
+
+	// field validation
+	CodeFieldRequired = "FIELD_REQUIRED"
+	CodeFieldInvalid  = "FIELD_INVALID"
+
+	// authentication
+	CodeInvalidCredentials  = "INVALID_CREDENTIALS"
+	CodeInvalidToken        = "INVALID_TOKEN"
+	CodeInvalidRefreshToken = "INVALID_REFRESH_TOKEN"
+	CodeWeakPassword        = "WEAK_PASSWORD"
+	CodeInvalidEmail        = "INVALID_EMAIL"
+	CodeInvalidPhoneNumber  = "INVALID_PHONE_NUMBER"
+	CodeEmailTaken          = "EMAIL_TAKEN"
+	CodePhoneNumberTaken    = "PHONE_NUMBER_TAKEN"
+	CodeUserDisabled        = "USER_DISABLED"
+
+	// resources
+	CodeUserNotFound         = "USER_NOT_FOUND"
+	CodeSettingsNotFound     = "SETTINGS_NOT_FOUND"
+	CodeMessageNotFound      = "MESSAGE_NOT_FOUND"
+	CodeChatNotFound         = "CHAT_NOT_FOUND"
+	CodeChatKeyNotFound      = "CHAT_KEY_NOT_FOUND"
+	CodeDeviceNotFound       = "DEVICE_NOT_FOUND"
+	CodeRefreshNotFound      = "REFRESH_TOKEN_NOT_FOUND"
+	CodeMediaNotFound        = "MEDIA_NOT_FOUND"
+	CodeHighlightNotFound    = "HIGHLIGHT_NOT_FOUND"
+	CodeChatGroupNotFound    = "CHAT_GROUP_NOT_FOUND"
+	CodeUploadNotFound       = "UPLOAD_NOT_FOUND"
+	CodeBlobNotFound         = "BLOB_NOT_FOUND"
+	CodeSubscriptionNotFound = "SUBSCRIPTION_NOT_FOUND"
+	CodeReceiptNotFound      = "RECEIPT_NOT_FOUND"
+	CodeLastSeenNotFound     = "LAST_SEEN_NOT_FOUND"
+
+	// media
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeNotChatParticipant   = "NOT_CHAT_PARTICIPANT"
+	CodeUploadOffsetMismatch = "UPLOAD_OFFSET_MISMATCH"
+	CodeUploadIncomplete     = "UPLOAD_INCOMPLETE"
+	CodeUploadCompleted      = "UPLOAD_COMPLETED"
+	CodeInvalidSignature     = "INVALID_SIGNATURE"
+	CodeURLExpired           = "URL_EXPIRED"
+	CodeRangeNotSatisfiable  = "RANGE_NOT_SATISFIABLE"
+	CodeBlobReferenced       = "BLOB_REFERENCED"
+	CodeMediaScanPending     = "MEDIA_SCAN_PENDING"
+	CodeMediaInfected        = "MEDIA_INFECTED"
+	CodeMediaScanFailed      = "MEDIA_SCAN_FAILED"
+	CodeStorageQuotaExceeded = "STORAGE_QUOTA_EXCEEDED"
+
+	// keys
+	CodeTooManyDevices = "TOO_MANY_DEVICES"
+)
@@ -0,0 +1,183 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind classifies an error, each kind maps onto one HTTP status
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindRateLimited  Kind = "rate_limited"
+	KindInternal     Kind = "internal"
+	KindUnavailable  Kind = "unavailable"
+)
+
+// Sentinels for errors.Is, they match any *Error of the same kind:
+//
+//	if errors.Is(err, apperrors.ErrNotFound) { ... }
+var (
+	ErrValidation   = &Error{Kind: KindValidation}
+	ErrUnauthorized = &Error{Kind: KindUnauthorized}
+	ErrForbidden    = &Error{Kind: KindForbidden}
+	ErrNotFound     = &Error{Kind: KindNotFound}
+	ErrConflict     = &Error{Kind: KindConflict}
+	ErrRateLimited  = &Error{Kind: KindRateLimited}
+	ErrInternal     = &Error{Kind: KindInternal}
+	ErrUnavailable  = &Error{Kind: KindUnavailable}
+)
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error carrying a stable, machine-readable Code clients can localize.
+// Message is safe to show to users, the wrapped Err is only logged.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+	Status  int // overrides the kind's HTTP status when set
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind sentinels (Code unset) by kind, and other *Error values by kind & code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if t.Code == "" {
+		return e.Kind == t.Kind
+	}
+	return e.Kind == t.Kind && e.Code == t.Code
+}
+
+// HTTPStatus returns the status code the kind is served with
+func (e *Error) HTTPStatus() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WithStatus overrides the HTTP status of the kind, for statuses the taxonomy does not model
+func (e *Error) WithStatus(status int) *Error {
+	e.Status = status
+	return e
+}
+
+// WithErr attaches the underlying cause
+func (e *Error) WithErr(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func newError(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code string, message string, fields ...FieldError) *Error {
+	e := newError(KindValidation, code, message)
+	e.Fields = fields
+	return e
+}
+
+func Unauthorized(code string, message string) *Error {
+	return newError(KindUnauthorized, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return newError(KindForbidden, code, message)
+}
+
+func NotFound(code string, message string) *Error {
+	return newError(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return newError(KindConflict, code, message)
+}
+
+func RateLimited(code string, message string) *Error {
+	return newError(KindRateLimited, code, message)
+}
+
+func Internal(code string, message string) *Error {
+	return newError(KindInternal, code, message)
+}
+
+func Unavailable(code string, message string) *Error {
+	return newError(KindUnavailable, code, message)
+}
+
+// Wrap returns err unchanged when it already is a domain error (so a repository NotFound reaches the client as is),
+// anything else becomes an internal error with message & CodeInternal
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return Internal(CodeInternal, message).WithErr(err)
+}
+
+// From returns the domain error in err's chain, or an internal error for anything else
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(CodeInternal, "Internal server error").WithErr(err)
+}
+
+// RequiredField describes a missing required field
+func RequiredField(field string) FieldError {
+	return FieldError{Field: field, Code: CodeFieldRequired, Message: field + " is required"}
+}
+
+// InvalidField describes a field with an invalid value
+func InvalidField(field string, message string) FieldError {
+	return FieldError{Field: field, Code: CodeFieldInvalid, Message: message}
+}
@@ -0,0 +1,170 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Hook is a named step run during shutdown, it must return once ctx is done
+type Hook struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// IManager owns the process lifecycle: it runs the server until SIGINT/SIGTERM, then shuts down in order
+//  1. stop hooks, in registration order (stop accepting connections, drain in-flight requests)
+//  2. background workers started with Go, which see their context cancelled and are awaited
+//  3. close hooks, in reverse registration order like defers (e.g. disconnect MongoDB)
+//
+// All three phases share a single timeout.
+type IManager interface {
+	// Go starts a background worker, ctx is cancelled when shutdown begins
+	Go(name string, fn func(ctx context.Context) error)
+	OnStop(name string, fn func(ctx context.Context) error)
+	OnClose(name string, fn func(ctx context.Context) error)
+	// Run calls serve, which should block (e.g. fiber.App.Listen), and shuts down on a signal or when serve returns
+	Run(serve func() error) error
+	// Shutdown shuts down without serving, for one-off commands that only need the hooks run once they are done
+	Shutdown() error
+}
+
+type manager struct {
+	iName   string
+	log     *zerolog.Logger
+	timeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	workers sync.WaitGroup
+	stops   []Hook
+	closes  []Hook
+}
+
+func NewManager(log *zerolog.Logger, timeout time.Duration) IManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &manager{
+		iName:   "LifecycleManager",
+		log:     log,
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (m *manager) Go(name string, fn func(ctx context.Context) error) {
+	const kName = "Go"
+
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		m.log.Info().Interface(kName, m.iName).Str("worker", name).Msg("worker started")
+		if err := fn(m.ctx); err != nil && !errors.Is(err, context.Canceled) {
+			m.log.Error().Interface(kName, m.iName).Str("worker", name).Err(err).Msg("worker failed")
+			return
+		}
+		m.log.Info().Interface(kName, m.iName).Str("worker", name).Msg("worker stopped")
+	}()
+}
+
+func (m *manager) OnStop(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stops = append(m.stops, Hook{Name: name, Fn: fn})
+}
+
+func (m *manager) OnClose(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closes = append(m.closes, Hook{Name: name, Fn: fn})
+}
+
+func (m *manager) Run(serve func() error) error {
+	const kName = "Run"
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	var serveErr error
+	select {
+	case <-sigCtx.Done():
+		m.log.Info().Interface(kName, m.iName).Msg("shutdown signal received")
+	case serveErr = <-served:
+		if serveErr != nil {
+			m.log.Error().Interface(kName, m.iName).Err(serveErr).Msg("server stopped unexpectedly")
+		}
+	}
+	// restore default signal handling so a second signal kills the process
+	stop()
+
+	return errors.Join(serveErr, m.Shutdown())
+}
+
+// Shutdown runs the stop hooks, drains the workers and runs the close hooks within m.timeout
+func (m *manager) Shutdown() error {
+	const kName = "shutdown"
+
+	m.log.Info().Interface(kName, m.iName).Dur("timeout", m.timeout).Msg("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	stops := append([]Hook(nil), m.stops...)
+	closes := append([]Hook(nil), m.closes...)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, h := range stops {
+		errs = append(errs, m.runHook(ctx, h))
+	}
+
+	// workers
+	m.cancel()
+	drained := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		m.log.Info().Interface(kName, m.iName).Msg("workers drained")
+	case <-ctx.Done():
+		m.log.Error().Interface(kName, m.iName).Msg("timed out waiting for workers")
+		errs = append(errs, errors.New("timed out waiting for workers"))
+	}
+
+	for i := len(closes) - 1; i >= 0; i-- {
+		errs = append(errs, m.runHook(ctx, closes[i]))
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		m.log.Error().Interface(kName, m.iName).Err(err).Msg("shutdown completed with errors")
+		return err
+	}
+	m.log.Info().Interface(kName, m.iName).Msg("shutdown complete")
+	return nil
+}
+
+func (m *manager) runHook(ctx context.Context, h Hook) error {
+	const kName = "runHook"
+
+	start := time.Now()
+	if err := h.Fn(ctx); err != nil {
+		m.log.Error().Interface(kName, m.iName).Str("hook", h.Name).Err(err).Msg("shutdown hook failed")
+		return err
+	}
+	m.log.Info().Interface(kName, m.iName).Str("hook", h.Name).Dur("took", time.Since(start)).Msg("shutdown hook done")
+	return nil
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+)
+
+// Field names added to request scoped loggers
+const (
+	FieldRequestID = "requestId"
+	FieldUserID    = "userId"
+	FieldTraceID   = "traceId"
+)
+
+// WithLogger returns a copy of ctx carrying log, see FromContext
+func WithLogger(ctx context.Context, log *zerolog.Logger) context.Context {
+	return log.WithContext(ctx)
+}
+
+// FromContext returns the request scoped logger stored in ctx,
+// or fallback when ctx does not belong to a request (startup, background workers)
+func FromContext(ctx context.Context, fallback *zerolog.Logger) *zerolog.Logger {
+	if ctx != nil {
+		if log := zerolog.Ctx(ctx); log != nil && log.GetLevel() != zerolog.Disabled {
+			return log
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,141 @@
+package media
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the chunks content is streamed to clamd in
+const clamdChunkSize = 64 * 1024
+
+// ClamdOptions configure a scanner talking to a ClamAV daemon
+type ClamdOptions struct {
+	Address string        // tcp://host:port or unix:///path/to/clamd.ctl
+	Timeout time.Duration // of a whole scan, clamd's StreamMaxLength must allow the largest upload
+}
+
+// clamdScanner streams content to clamd with the INSTREAM command, see clamd(8)
+type clamdScanner struct {
+	iName   string
+	log     *zerolog.Logger
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamdScanner(log *zerolog.Logger, opts ClamdOptions) (IMediaScanner, error) {
+	address, err := url.Parse(opts.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clamd address %q: %w", opts.Address, err)
+	}
+	scanner := &clamdScanner{iName: "ClamdScanner", log: log, network: address.Scheme, timeout: opts.Timeout}
+	switch address.Scheme {
+	case "tcp":
+		scanner.address = address.Host
+	case "unix":
+		scanner.address = address.Path
+	default:
+		return nil, fmt.Errorf("invalid clamd address %q: the scheme must be tcp or unix", opts.Address)
+	}
+	if scanner.address == "" {
+		return nil, fmt.Errorf("invalid clamd address %q: missing host or path", opts.Address)
+	}
+	return scanner, nil
+}
+
+func (c *clamdScanner) Scan(ctx context.Context, content io.Reader) (*ScanResult, error) {
+	const kName = "Scan"
+	logger := logging.FromContext(ctx, c.log)
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	// unblocks the transfer when ctx is cancelled before its deadline
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	contentErr, streamErr := c.stream(conn, content)
+	if contentErr != nil {
+		return nil, fmt.Errorf("failed to read the content: %w", contentErr)
+	}
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		// clamd closes the connection once the content exceeds its limit, its reply tells why the stream failed
+		if streamErr != nil {
+			return nil, fmt.Errorf("failed to stream content to clamd: %w", streamErr)
+		}
+		return nil, fmt.Errorf("failed to read the clamd reply: %w", err)
+	}
+
+	result, err := parseClamdReply(reply)
+	if err != nil {
+		return nil, err
+	}
+	if result.Infected {
+		logger.Warn().Interface(kName, c.iName).Str("signature", result.Signature).Msg("clamd found malware")
+	}
+	return result, nil
+}
+
+// stream sends the INSTREAM command & content as length prefixed chunks, ending with an empty chunk. It returns
+// the errors of reading content apart from those of the connection.
+func (c *clamdScanner) stream(conn net.Conn, content io.Reader) (contentErr error, connErr error) {
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, err := io.ReadFull(content, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, werr := conn.Write(buf[:4+n]); werr != nil {
+				return nil, werr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err, nil
+		}
+	}
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return nil, err
+}
+
+// parseClamdReply parses "stream: OK", "stream: <signature> FOUND" & "<reason> ERROR" replies
+func parseClamdReply(reply string) (*ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &ScanResult{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	case strings.HasSuffix(verdict, " ERROR"):
+		return nil, fmt.Errorf("%w: %s", ErrScanRejected, strings.TrimSuffix(verdict, " ERROR"))
+	default:
+		return nil, fmt.Errorf("unexpected clamd reply %q", reply)
+	}
+}
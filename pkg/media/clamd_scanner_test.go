@@ -0,0 +1,151 @@
+package media_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/media"
+	"github.com/rs/zerolog"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// eicar is the standard antivirus test file, every scanner reports it without it being harmful
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamdLimit is the StreamMaxLength of the fake clamd
+const fakeClamdLimit = 256 * 1024
+
+// fakeClamd serves the INSTREAM command like clamd, it finds the EICAR string & rejects streams above its limit
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveClamd(conn)
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func serveClamd(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	if command, err := reader.ReadString(0); err != nil || command != "zINSTREAM\x00" {
+		_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+	var content bytes.Buffer
+	for {
+		var size uint32
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		if content.Len()+int(size) > fakeClamdLimit {
+			_, _ = conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+			return
+		}
+		if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+			return
+		}
+	}
+	reply := "stream: OK\x00"
+	if strings.Contains(content.String(), eicar) {
+		reply = "stream: Eicar-Test-Signature FOUND\x00"
+	}
+	_, _ = conn.Write([]byte(reply))
+}
+
+func TestClamdScanner(t *testing.T) {
+	testClamdScanner(t, fakeClamd(t))
+}
+
+// TestClamdScannerDaemon runs against the clamd listening at CLAMD_TEST_ADDRESS, e.g. tcp://localhost:3310
+func TestClamdScannerDaemon(t *testing.T) {
+	address := os.Getenv("CLAMD_TEST_ADDRESS")
+	if address == "" {
+		t.Skip("CLAMD_TEST_ADDRESS is not set")
+	}
+	testClamdScanner(t, address)
+}
+
+// testClamdScanner is the behaviour of clamd both the fake & a real daemon show
+func testClamdScanner(t *testing.T, address string) {
+	log := zerolog.Nop()
+	scanner, err := media.NewClamdScanner(&log, media.ClamdOptions{Address: address, Timeout: 30 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// the clean content spans several chunks
+	result, err := scanner.Scan(ctx, bytes.NewReader(bytes.Repeat([]byte("telko"), 30000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Infected {
+		t.Fatalf("clean content reported infected by %q", result.Signature)
+	}
+
+	result, err = scanner.Scan(ctx, strings.NewReader(eicar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Infected || !strings.Contains(result.Signature, "Eicar") {
+		t.Fatalf("expected the EICAR signature, got %+v", result)
+	}
+}
+
+func TestClamdScannerRejectsLargeContent(t *testing.T) {
+	log := zerolog.Nop()
+	scanner, err := media.NewClamdScanner(&log, media.ClamdOptions{Address: fakeClamd(t), Timeout: 30 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = scanner.Scan(context.Background(), bytes.NewReader(make([]byte, 4*fakeClamdLimit)))
+	if !errors.Is(err, media.ErrScanRejected) {
+		t.Fatalf("expected ErrScanRejected, got %v", err)
+	}
+}
+
+func TestClamdScannerUnreachable(t *testing.T) {
+	log := zerolog.Nop()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := "tcp://" + listener.Addr().String()
+	_ = listener.Close()
+
+	scanner, err := media.NewClamdScanner(&log, media.ClamdOptions{Address: address, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = scanner.Scan(context.Background(), strings.NewReader("telko"))
+	if err == nil || errors.Is(err, media.ErrScanRejected) {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+
+	for _, invalid := range []string{"localhost:3310", "http://localhost:3310", "tcp://", "unix://"} {
+		if _, err = media.NewClamdScanner(&log, media.ClamdOptions{Address: invalid}); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+const (
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+)
+
+// exifTypeSizes are the sizes in bytes of the values of the TIFF field types
+var exifTypeSizes = map[uint16]uint64{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// exifBlock locates the TIFF structure of EXIF metadata within content
+type exifBlock struct {
+	start int
+	end   int
+	crcAt int // offset of the CRC of a PNG chunk covering the block, -1 for other formats
+}
+
+// stripLocation zeroes the EXIF GPS data of JPEG, PNG & WebP content in place, the size of the content
+// is unchanged so nothing else is disturbed. It returns the EXIF orientation, 1 when there is none,
+// & whether content was changed. Malformed EXIF data is zeroed as a whole rather than trusted.
+func stripLocation(content []byte, contentType string) (orientation int, changed bool) {
+	orientation = 1
+	for _, block := range exifBlocks(content, contentType) {
+		blockOrientation, blockChanged := stripTIFFLocation(content[block.start:block.end])
+		if blockOrientation != 1 {
+			orientation = blockOrientation
+		}
+		if blockChanged && block.crcAt >= 0 {
+			// the chunk type precedes the data & is part of the CRC
+			binary.BigEndian.PutUint32(content[block.crcAt:], crc32.ChecksumIEEE(content[block.start-4:block.end]))
+		}
+		changed = changed || blockChanged
+	}
+	return orientation, changed
+}
+
+// stripTIFFLocation empties the GPS IFD referenced by IFD0 of tiff
+func stripTIFFLocation(tiff []byte) (orientation int, changed bool) {
+	orientation = 1
+	if len(tiff) < 8 {
+		return orientation, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return orientation, false
+	}
+
+	ifd := uint64(order.Uint32(tiff[4:]))
+	if ifd+2 > uint64(len(tiff)) {
+		clear(tiff)
+		return orientation, true
+	}
+	entries := uint64(order.Uint16(tiff[ifd:]))
+	if ifd+2+entries*12 > uint64(len(tiff)) {
+		clear(tiff)
+		return orientation, true
+	}
+	for i := uint64(0); i < entries; i++ {
+		entry := tiff[ifd+2+i*12:]
+		switch order.Uint16(entry) {
+		case tagOrientation:
+			if value := int(order.Uint16(entry[8:])); order.Uint16(entry[2:]) == 3 && value >= 1 && value <= 8 {
+				orientation = value
+			}
+		case tagGPSInfo:
+			if !clearIFD(tiff, order, uint64(order.Uint32(entry[8:]))) {
+				clear(tiff)
+				return 1, true
+			}
+			changed = true
+		}
+	}
+	return orientation, changed
+}
+
+// clearIFD zeroes the IFD at offset & the values its entries point to, leaving a valid IFD without entries
+func clearIFD(tiff []byte, order binary.ByteOrder, offset uint64) bool {
+	size := uint64(len(tiff))
+	if offset+2 > size {
+		return false
+	}
+	entries := uint64(order.Uint16(tiff[offset:]))
+	end := offset + 2 + entries*12 + 4
+	if end > size {
+		return false
+	}
+	for i := uint64(0); i < entries; i++ {
+		entry := tiff[offset+2+i*12:]
+		typeSize, ok := exifTypeSizes[order.Uint16(entry[2:])]
+		if !ok {
+			return false
+		}
+		valueSize := typeSize * uint64(order.Uint32(entry[4:]))
+		if valueSize <= 4 {
+			continue // stored in the entry itself
+		}
+		valueOffset := uint64(order.Uint32(entry[8:]))
+		if valueOffset+valueSize > size {
+			return false
+		}
+		clear(tiff[valueOffset : valueOffset+valueSize])
+	}
+	clear(tiff[offset:end])
+	return true
+}
+
+// exifBlocks finds the EXIF metadata of JPEG, PNG & WebP content
+func exifBlocks(content []byte, contentType string) []exifBlock {
+	var blocks []exifBlock
+	switch contentType {
+	case "image/jpeg":
+		if !bytes.HasPrefix(content, []byte{0xFF, 0xD8}) {
+			return nil
+		}
+		for pos := 2; pos+4 <= len(content) && content[pos] == 0xFF; {
+			marker := content[pos+1]
+			switch {
+			case marker == 0xFF:
+				pos++ // fill byte
+				continue
+			case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD8):
+				pos += 2 // markers without a segment
+				continue
+			case marker == 0xDA || marker == 0xD9:
+				return blocks // the compressed image data follows, metadata comes before it
+			}
+			length := int(binary.BigEndian.Uint16(content[pos+2:]))
+			end := pos + 2 + length
+			if length < 2 || end > len(content) {
+				return blocks
+			}
+			if marker == 0xE1 && bytes.HasPrefix(content[pos+4:end], []byte("Exif\x00\x00")) {
+				blocks = append(blocks, exifBlock{start: pos + 10, end: end, crcAt: -1})
+			}
+			pos = end
+		}
+	case "image/png":
+		if !bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")) {
+			return nil
+		}
+		for pos := 8; pos+12 <= len(content); {
+			start := pos + 8
+			end := start + int(binary.BigEndian.Uint32(content[pos:]))
+			if end+4 > len(content) || end < start {
+				return blocks
+			}
+			switch string(content[pos+4 : start]) {
+			case "eXIf":
+				blocks = append(blocks, exifBlock{start: start, end: end, crcAt: end})
+			case "IEND":
+				return blocks
+			}
+			pos = end + 4
+		}
+	case "image/webp":
+		if len(content) < 12 || string(content[:4]) != "RIFF" || string(content[8:12]) != "WEBP" {
+			return nil
+		}
+		for pos := 12; pos+8 <= len(content); {
+			start := pos + 8
+			end := start + int(binary.LittleEndian.Uint32(content[pos+4:]))
+			if end > len(content) || end < start {
+				return blocks
+			}
+			if string(content[pos:start-4]) == "EXIF" {
+				// some writers keep the JPEG "Exif" header in the chunk
+				if bytes.HasPrefix(content[start:end], []byte("Exif\x00\x00")) {
+					start += 6
+				}
+				blocks = append(blocks, exifBlock{start: start, end: end, crcAt: -1})
+			}
+			pos = end + (end-pos)%2 // chunks are padded to an even size
+		}
+	}
+	return blocks
+}
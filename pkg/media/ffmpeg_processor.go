@@ -0,0 +1,250 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"image/png"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	waveformLevels = 64   // levels of an audio waveform
+	waveformRate   = 4000 // sample rate audio is decoded at for its waveform
+	waveformWindow = 40   // samples of a window, 10ms at waveformRate
+)
+
+// FFmpegOptions locate the ffmpeg & ffprobe executables
+type FFmpegOptions struct {
+	FFmpegPath  string
+	FFprobePath string
+	Image       ImageOptions // thumbnails of video posters
+}
+
+// ffmpegProcessor reads the duration of audio & video, the waveform of audio & a poster of video with ffmpeg
+type ffmpegProcessor struct {
+	iName string
+	log   *zerolog.Logger
+	opts  FFmpegOptions
+}
+
+// NewFFmpegProcessor processes audio & video with the ffmpeg executables of opts
+func NewFFmpegProcessor(log *zerolog.Logger, opts FFmpegOptions) (IProcessor, error) {
+	for _, path := range []string{opts.FFmpegPath, opts.FFprobePath} {
+		if _, err := exec.LookPath(path); err != nil {
+			log.Error().Err(err).Str("path", path).Msg("ffmpeg executable not found")
+			return nil, err
+		}
+	}
+	return &ffmpegProcessor{
+		iName: "FFmpegProcessor",
+		log:   log,
+		opts:  opts,
+	}, nil
+}
+
+func (p *ffmpegProcessor) Accepts(contentType string) bool {
+	return strings.HasPrefix(contentType, "video/") || strings.HasPrefix(contentType, "audio/") || contentType == "application/ogg"
+}
+
+func (p *ffmpegProcessor) Process(ctx context.Context, path string, contentType string) (*Result, error) {
+	const kName = "Process"
+	logger := logging.FromContext(ctx, p.log)
+
+	// ffmpeg failures are expected for formats it does not know, the content is kept without metadata
+	failed := func(err error, msg string) (*Result, error) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.Warn().Interface(kName, p.iName).Err(err).Str("contentType", contentType).Msg(msg)
+		return &Result{}, nil
+	}
+
+	probe, err := probeMedia(ctx, p.opts.FFprobePath, path)
+	if err != nil {
+		return failed(err, "Failed to probe media")
+	}
+	result := &Result{Duration: probe.duration}
+
+	if probe.width > 0 && strings.HasPrefix(contentType, "video/") {
+		result.Width, result.Height = probe.width, probe.height
+		poster, err := runCommand(ctx, p.opts.FFmpegPath, "-v", "error",
+			"-ss", strconv.FormatFloat(min(1, probe.duration.Seconds()/2), 'f', 3, 64), "-i", path,
+			"-frames:v", "1", "-f", "image2pipe", "-c:v", "png", "pipe:1")
+		if err != nil {
+			return failed(err, "Failed to extract video poster")
+		}
+		img, err := png.Decode(bytes.NewReader(poster))
+		if err != nil {
+			return failed(err, "Failed to decode video poster")
+		}
+		// ffmpeg rotates the frame as the video is displayed
+		if result.Thumbnails, result.Blurhash, err = derivatives(img, 1, posterSizes(img.Bounds().Dx(), img.Bounds().Dy(), p.opts.Image.ThumbnailSizes)); err != nil {
+			return failed(err, "Failed to generate video thumbnails")
+		}
+		return result, nil
+	}
+
+	if result.Waveform, err = p.waveform(ctx, path); err != nil {
+		return failed(err, "Failed to compute waveform")
+	}
+	return result, nil
+}
+
+// probed is what ffprobe reports of media
+type probed struct {
+	format      string // names of the container format, e.g. "ogg" or "mov,mp4,m4a,3gp,3g2,mj2"
+	duration    time.Duration
+	width       int // of the first video stream, as displayed
+	height      int
+	audioCodecs []string // of the audio streams
+	videoCodecs []string // of the video streams, cover art included
+}
+
+func probeMedia(ctx context.Context, ffprobePath string, path string) (*probed, error) {
+	out, err := runCommand(ctx, ffprobePath, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", path)
+	if err != nil {
+		return nil, err
+	}
+	var report struct {
+		Format struct {
+			FormatName string `json:"format_name"`
+			Duration   string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType    string `json:"codec_type"`
+			CodecName    string `json:"codec_name"`
+			Width        int    `json:"width"`
+			Height       int    `json:"height"`
+			SideDataList []struct {
+				Rotation int `json:"rotation"`
+			} `json:"side_data_list"`
+		} `json:"streams"`
+	}
+	if err = json.Unmarshal(out, &report); err != nil {
+		return nil, err
+	}
+
+	result := &probed{format: report.Format.FormatName}
+	if seconds, err := strconv.ParseFloat(report.Format.Duration, 64); err == nil {
+		result.duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, stream := range report.Streams {
+		switch stream.CodecType {
+		case "audio":
+			result.audioCodecs = append(result.audioCodecs, stream.CodecName)
+		case "video":
+			result.videoCodecs = append(result.videoCodecs, stream.CodecName)
+			if len(result.videoCodecs) > 1 {
+				continue
+			}
+			result.width, result.height = stream.Width, stream.Height
+			for _, sideData := range stream.SideDataList {
+				if sideData.Rotation%180 != 0 {
+					result.width, result.height = stream.Height, stream.Width
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// waveform decodes audio to mono samples & reduces their peaks to waveformLevels levels
+func (p *ffmpegProcessor) waveform(ctx context.Context, path string) ([]int, error) {
+	cmd := exec.CommandContext(ctx, p.opts.FFmpegPath, "-v", "error", "-i", path, "-vn",
+		"-ac", "1", "-ar", strconv.Itoa(waveformRate), "-f", "s16le", "pipe:1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// the peaks of short windows first, the number of samples is only known at the end
+	var peaks []int
+	samples := bufio.NewReader(stdout)
+	sample := make([]byte, 2)
+	peak, count := 0, 0
+	for {
+		if _, err = io.ReadFull(samples, sample); err != nil {
+			break
+		}
+		value := int(int16(binary.LittleEndian.Uint16(sample)))
+		peak = max(peak, value, -value)
+		if count++; count == waveformWindow {
+			peaks = append(peaks, peak)
+			peak, count = 0, 0
+		}
+	}
+	if count > 0 {
+		peaks = append(peaks, peak)
+	}
+	if waitErr := cmd.Wait(); waitErr != nil {
+		return nil, commandError(p.opts.FFmpegPath, waitErr, &stderr)
+	}
+	if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if len(peaks) == 0 {
+		return nil, nil
+	}
+
+	levels := make([]int, min(waveformLevels, len(peaks)))
+	loudest := 0
+	for i := range levels {
+		for _, windowPeak := range peaks[i*len(peaks)/len(levels) : (i+1)*len(peaks)/len(levels)] {
+			levels[i] = max(levels[i], windowPeak)
+		}
+		loudest = max(loudest, levels[i])
+	}
+	for i := range levels {
+		if loudest > 0 {
+			levels[i] = levels[i] * 100 / loudest
+		}
+	}
+	return levels, nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(name, err, &stderr)
+	}
+	return out, nil
+}
+
+// posterSizes are the thumbnail sizes of a video poster, the poster is not served otherwise
+// so a poster smaller than the sizes gets a thumbnail of its own size
+func posterSizes(width, height int, sizes []int) []int {
+	longest := max(width, height)
+	var posterSizes []int
+	for _, size := range slices.Sorted(slices.Values(sizes)) {
+		if size >= longest {
+			return append(posterSizes, longest)
+		}
+		posterSizes = append(posterSizes, size)
+	}
+	return posterSizes
+}
+
+func commandError(name string, err error, stderr *bytes.Buffer) error {
+	return fmt.Errorf("%s: %w: %s", filepath.Base(name), err, strings.TrimSpace(stderr.String()))
+}
@@ -0,0 +1,86 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+const (
+	opusContentType = "audio/ogg"
+	opusBitrate     = "64k" // transparent for voice & fair for music
+)
+
+// ffmpegTranscoder converts audio to Opus in Ogg, the format of voice notes on every platform
+type ffmpegTranscoder struct {
+	iName string
+	log   *zerolog.Logger
+	opts  FFmpegOptions
+}
+
+// NewFFmpegTranscoder transcodes audio with the ffmpeg executables of opts, ffmpeg must have the libopus encoder
+func NewFFmpegTranscoder(log *zerolog.Logger, opts FFmpegOptions) (ITranscoder, error) {
+	for _, path := range []string{opts.FFmpegPath, opts.FFprobePath} {
+		if _, err := exec.LookPath(path); err != nil {
+			log.Error().Err(err).Str("path", path).Msg("ffmpeg executable not found")
+			return nil, err
+		}
+	}
+	encoders, err := runCommand(context.Background(), opts.FFmpegPath, "-hide_banner", "-encoders")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(encoders, []byte(" libopus ")) {
+		err = errors.New("ffmpeg has no libopus encoder")
+		log.Error().Err(err).Str("path", opts.FFmpegPath).Msg("ffmpeg cannot transcode audio")
+		return nil, err
+	}
+	return &ffmpegTranscoder{
+		iName: "FFmpegTranscoder",
+		log:   log,
+		opts:  opts,
+	}, nil
+}
+
+func (t *ffmpegTranscoder) Accepts(contentType string) bool {
+	return strings.HasPrefix(contentType, "audio/") || contentType == "application/ogg"
+}
+
+func (t *ffmpegTranscoder) Transcode(ctx context.Context, path string, contentType string, dst string) (string, error) {
+	const kName = "Transcode"
+	logger := logging.FromContext(ctx, t.log)
+
+	// like processing, formats ffmpeg does not know are kept as uploaded
+	skipped := func(err error, msg string) (string, error) {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		logger.Warn().Interface(kName, t.iName).Err(err).Str("contentType", contentType).Msg(msg)
+		return "", nil
+	}
+
+	probe, err := probeMedia(ctx, t.opts.FFprobePath, path)
+	if err != nil {
+		return skipped(err, "Failed to probe audio")
+	}
+	// Ogg may hold video too, which is not audio to transcode
+	if len(probe.audioCodecs) == 0 || (contentType == "application/ogg" && len(probe.videoCodecs) > 0) {
+		return "", nil
+	}
+	if slices.Contains(strings.Split(probe.format, ","), "ogg") && len(probe.audioCodecs) == 1 && probe.audioCodecs[0] == "opus" && len(probe.videoCodecs) == 0 {
+		return "", nil
+	}
+
+	// cover art & tags are dropped, they may hold private metadata
+	if _, err = runCommand(ctx, t.opts.FFmpegPath, "-v", "error", "-y", "-i", path, "-map", "0:a:0", "-map_metadata", "-1",
+		"-c:a", "libopus", "-b:a", opusBitrate, "-f", "ogg", dst); err != nil {
+		return skipped(err, "Failed to transcode audio")
+	}
+	logger.Debug().Interface(kName, t.iName).Str("contentType", contentType).Strs("codecs", probe.audioCodecs).Msg("Transcoded audio to Opus")
+	return opusContentType, nil
+}
@@ -0,0 +1,193 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"slices"
+)
+
+// placeholderSize is the longest edge of the image the blurhash is computed from
+const placeholderSize = 32
+
+// thumbnailQuality is the JPEG quality of opaque thumbnails
+const thumbnailQuality = 80
+
+var imageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// ImageOptions configure the image processor
+type ImageOptions struct {
+	ThumbnailSizes []int // longest edges in pixels of the thumbnails, images are never upscaled
+	MaxPixels      int   // larger images are not decoded, guarding against decompression bombs
+}
+
+// imageProcessor strips the location from images & generates their thumbnails & blurhash
+type imageProcessor struct {
+	iName string
+	log   *zerolog.Logger
+	opts  ImageOptions
+}
+
+// NewImageProcessor processes JPEG, PNG, GIF & WebP images in pure Go
+func NewImageProcessor(log *zerolog.Logger, opts ImageOptions) IProcessor {
+	return &imageProcessor{
+		iName: "ImageProcessor",
+		log:   log,
+		opts:  opts,
+	}
+}
+
+func (p *imageProcessor) Accepts(contentType string) bool {
+	return slices.Contains(imageTypes, contentType)
+}
+
+func (p *imageProcessor) Process(ctx context.Context, path string, contentType string) (*Result, error) {
+	const kName = "Process"
+	logger := logging.FromContext(ctx, p.log)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	// the location goes first, it is stripped even from images that cannot be decoded
+	orientation, changed := stripLocation(content, contentType)
+	if changed {
+		result.Sanitized = content
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		logger.Warn().Interface(kName, p.iName).Err(err).Str("contentType", contentType).Msg("Failed to read image dimensions")
+		return result, nil
+	}
+	result.Width, result.Height = orientedSize(config.Width, config.Height, orientation)
+	if p.opts.MaxPixels > 0 && config.Width*config.Height > p.opts.MaxPixels {
+		logger.Warn().Interface(kName, p.iName).Int("width", config.Width).Int("height", config.Height).Msg("Image is too large to generate thumbnails")
+		return result, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		logger.Warn().Interface(kName, p.iName).Err(err).Str("contentType", contentType).Msg("Failed to decode image")
+		return result, nil
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	result.Thumbnails, result.Blurhash, err = derivatives(img, orientation, p.opts.ThumbnailSizes)
+	if err != nil {
+		logger.Warn().Interface(kName, p.iName).Err(err).Msg("Failed to generate thumbnails")
+	}
+	return result, nil
+}
+
+// derivatives generates the thumbnails & blurhash of img, displayed with the EXIF orientation
+func derivatives(img image.Image, orientation int, sizes []int) ([]Thumbnail, string, error) {
+	bounds := img.Bounds()
+	width, height := orientedSize(bounds.Dx(), bounds.Dy(), orientation)
+	if width == 0 || height == 0 {
+		return nil, "", nil
+	}
+
+	var thumbnails []Thumbnail
+	for _, size := range sizes {
+		if size > max(width, height) {
+			continue // images are not upscaled
+		}
+		thumbnail := resize(img, orientation, size, draw.CatmullRom)
+		content, contentType, err := encode(thumbnail)
+		if err != nil {
+			return nil, "", err
+		}
+		thumbnails = append(thumbnails, Thumbnail{
+			Size:        size,
+			Width:       thumbnail.Bounds().Dx(),
+			Height:      thumbnail.Bounds().Dy(),
+			ContentType: contentType,
+			Content:     content,
+		})
+	}
+
+	xComponents, yComponents := 4, 3
+	if height > width {
+		xComponents, yComponents = 3, 4
+	}
+	return thumbnails, blurhash(resize(img, orientation, placeholderSize, draw.ApproxBiLinear), xComponents, yComponents), nil
+}
+
+// resize scales img down to size pixels on its longest edge & applies the EXIF orientation
+func resize(img image.Image, orientation int, size int, scaler draw.Scaler) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > size {
+		if width >= height {
+			width, height = size, max(1, (height*size+longest/2)/longest)
+		} else {
+			width, height = max(1, (width*size+longest/2)/longest), size
+		}
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	scaler.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+	return orient(scaled, orientation)
+}
+
+// orient transforms img the way the EXIF orientation says it is displayed
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	width, height := orientedSize(w, h, orientation)
+	oriented := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // displayed rotated clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // displayed rotated counterclockwise
+				sx, sy = w-1-y, x
+			}
+			oriented.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return oriented
+}
+
+// orientedSize is the displayed size of an image of width by height pixels with the EXIF orientation
+func orientedSize(width, height, orientation int) (int, int) {
+	if orientation >= 5 && orientation <= 8 {
+		return height, width
+	}
+	return width, height
+}
+
+// encode keeps transparency in PNG & compresses opaque images as JPEG
+func encode(img *image.RGBA) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if img.Opaque() {
+		err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err := png.Encode(&buf, img)
+	return buf.Bytes(), "image/png", err
+}
@@ -0,0 +1,151 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/media"
+	"github.com/rs/zerolog"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// gpsMarker is the latitude of the test EXIF data, it must not survive processing
+const gpsMarker = 0x12345678
+
+func TestImageProcessorStripsLocation(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, filled(400, 200, color.RGBA{R: 200, G: 80, B: 40, A: 255}), nil); err != nil {
+		t.Fatal(err)
+	}
+	content := withExif(encoded.Bytes(), 6)
+	if !bytes.Contains(content, gpsMarkerBytes()) {
+		t.Fatal("test image lacks the GPS marker")
+	}
+
+	result := process(t, content, "image/jpeg")
+	if result.Sanitized == nil {
+		t.Fatal("location was not stripped")
+	}
+	if len(result.Sanitized) != len(content) {
+		t.Errorf("sanitized size = %d, want %d", len(result.Sanitized), len(content))
+	}
+	if bytes.Contains(result.Sanitized, gpsMarkerBytes()) {
+		t.Error("GPS data survived")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(result.Sanitized)); err != nil {
+		t.Errorf("sanitized image does not decode: %v", err)
+	}
+
+	// orientation 6 displays the image rotated, portrait
+	if result.Width != 200 || result.Height != 400 {
+		t.Errorf("size = %dx%d, want 200x400", result.Width, result.Height)
+	}
+	if len(result.Thumbnails) != 2 {
+		t.Fatalf("got %d thumbnails, want 2 as 960 would upscale", len(result.Thumbnails))
+	}
+	small := result.Thumbnails[0]
+	if small.Size != 96 || small.Width != 48 || small.Height != 96 || small.ContentType != "image/jpeg" {
+		t.Errorf("thumbnail = %d %dx%d %s, want 96 48x96 image/jpeg", small.Size, small.Width, small.Height, small.ContentType)
+	}
+	if thumbnail, err := jpeg.Decode(bytes.NewReader(small.Content)); err != nil || thumbnail.Bounds().Dx() != 48 {
+		t.Errorf("thumbnail does not decode to its size: %v", err)
+	}
+	if len(result.Blurhash) != 28 {
+		t.Errorf("blurhash %q has %d characters, want 28 for 3x4 components", result.Blurhash, len(result.Blurhash))
+	}
+}
+
+func TestImageProcessorKeepsTransparency(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, filled(120, 120, color.RGBA{A: 0})); err != nil {
+		t.Fatal(err)
+	}
+
+	result := process(t, encoded.Bytes(), "image/png")
+	if result.Sanitized != nil {
+		t.Error("an image without EXIF data was changed")
+	}
+	if len(result.Thumbnails) != 1 || result.Thumbnails[0].ContentType != "image/png" {
+		t.Errorf("thumbnails = %+v, want a single PNG", result.Thumbnails)
+	}
+}
+
+func TestImageProcessorSkipsUndecodableImages(t *testing.T) {
+	result := process(t, []byte("\xFF\xD8\xFF\xDBnot really a jpeg"), "image/jpeg")
+	if result.Width != 0 || len(result.Thumbnails) != 0 {
+		t.Errorf("result = %+v, want no metadata", result)
+	}
+}
+
+func process(t *testing.T, content []byte, contentType string) *media.Result {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "content")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.Nop()
+	processor := media.NewImageProcessor(&log, media.ImageOptions{ThumbnailSizes: []int{96, 320, 960}, MaxPixels: 1 << 20})
+	if !processor.Accepts(contentType) {
+		t.Fatalf("processor does not accept %s", contentType)
+	}
+	result, err := processor.Process(context.Background(), path, contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func filled(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// withExif inserts an EXIF segment with the orientation & a GPS latitude after the start of a JPEG
+func withExif(jpegContent []byte, orientation uint16) []byte {
+	le := binary.LittleEndian
+	tiff := make([]byte, 92)
+	copy(tiff, "II*\x00")
+	le.PutUint32(tiff[4:], 8)
+	// IFD0: the orientation & the GPS IFD pointer
+	le.PutUint16(tiff[8:], 2)
+	le.PutUint16(tiff[10:], 0x0112)
+	le.PutUint16(tiff[12:], 3)
+	le.PutUint32(tiff[14:], 1)
+	le.PutUint16(tiff[18:], orientation)
+	le.PutUint16(tiff[22:], 0x8825)
+	le.PutUint16(tiff[24:], 4)
+	le.PutUint32(tiff[26:], 1)
+	le.PutUint32(tiff[30:], 38)
+	// GPS IFD: the latitude reference inline & the latitude as 3 rationals at 68
+	le.PutUint16(tiff[38:], 2)
+	le.PutUint16(tiff[40:], 1)
+	le.PutUint16(tiff[42:], 2)
+	le.PutUint32(tiff[44:], 2)
+	copy(tiff[48:], "N")
+	le.PutUint16(tiff[52:], 2)
+	le.PutUint16(tiff[54:], 5)
+	le.PutUint32(tiff[56:], 3)
+	le.PutUint32(tiff[60:], 68)
+	for i := 0; i < 3; i++ {
+		le.PutUint32(tiff[68+i*8:], gpsMarker)
+		le.PutUint32(tiff[72+i*8:], 1)
+	}
+
+	segment := append([]byte{0xFF, 0xE1, 0, 0}, "Exif\x00\x00"...)
+	segment = append(segment, tiff...)
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(segment)-2))
+	return append(append(append([]byte{}, jpegContent[:2]...), segment...), jpegContent[2:]...)
+}
+
+func gpsMarkerBytes() []byte {
+	return binary.LittleEndian.AppendUint32(nil, gpsMarker)
+}
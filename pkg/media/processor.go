@@ -0,0 +1,66 @@
+// Package media extracts metadata & derivatives such as thumbnails from the content of media files.
+package media
+
+import (
+	"context"
+	"time"
+)
+
+// IProcessor extracts the metadata & derivatives of media content. Process fails only when the content
+// cannot be read, metadata that cannot be extracted from it is left out of the result.
+type IProcessor interface {
+	// Accepts reports whether the processor handles content of the MIME type contentType
+	Accepts(contentType string) bool
+	// Process reads the content stored in the file at path
+	Process(ctx context.Context, path string, contentType string) (*Result, error)
+}
+
+// Result is what a processor extracted from content
+type Result struct {
+	Width      int
+	Height     int
+	Duration   time.Duration
+	Blurhash   string // a compact placeholder of images & video posters, see https://blurha.sh
+	Waveform   []int  // peak levels from 0 to 100 of evenly spaced windows of audio
+	Thumbnails []Thumbnail
+	// Sanitized replaces the content when private metadata was stripped from it, nil when the content is unchanged
+	Sanitized []byte
+}
+
+// Thumbnail is a downscaled image of the content, its longest edge is Size pixels
+type Thumbnail struct {
+	Size        int
+	Width       int
+	Height      int
+	ContentType string
+	Content     []byte
+}
+
+// chain hands content to the first of its processors accepting it
+type chain []IProcessor
+
+// Chain combines processors, the first processor accepting a MIME type processes its content
+func Chain(processors ...IProcessor) IProcessor {
+	return chain(processors)
+}
+
+func (c chain) Accepts(contentType string) bool {
+	return c.processor(contentType) != nil
+}
+
+func (c chain) Process(ctx context.Context, path string, contentType string) (*Result, error) {
+	processor := c.processor(contentType)
+	if processor == nil {
+		return &Result{}, nil
+	}
+	return processor.Process(ctx, path, contentType)
+}
+
+func (c chain) processor(contentType string) IProcessor {
+	for _, processor := range c {
+		if processor != nil && processor.Accepts(contentType) {
+			return processor
+		}
+	}
+	return nil
+}
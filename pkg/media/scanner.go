@@ -0,0 +1,22 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrScanRejected is returned by scanners refusing to scan the content itself, e.g. above their size limit,
+// retrying will not help unlike with other errors
+var ErrScanRejected = errors.New("the scanner rejected the content")
+
+// IMediaScanner scans uploaded content for malware
+type IMediaScanner interface {
+	Scan(ctx context.Context, content io.Reader) (*ScanResult, error)
+}
+
+// ScanResult is the verdict of a scanner
+type ScanResult struct {
+	Infected  bool
+	Signature string // name of the detected malware
+}
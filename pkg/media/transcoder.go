@@ -0,0 +1,13 @@
+package media
+
+import "context"
+
+// ITranscoder converts content into a single format every client plays
+type ITranscoder interface {
+	// Accepts reports whether content of the MIME type contentType is converted
+	Accepts(contentType string) bool
+	// Transcode writes the content of the file at path in the target format to the file at dst & returns its MIME
+	// type. Content already in the target format or that cannot be transcoded is left as is, the MIME type is then
+	// empty & dst must be ignored. Transcode only fails when ctx is done.
+	Transcode(ctx context.Context, path string, contentType string, dst string) (string, error)
+}
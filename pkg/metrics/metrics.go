@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+const namespace = "telko"
+
+// Label values for the domain counters
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry holds every collector of the server, a dedicated registry keeps tests & tools free of global state
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration is labelled by route template (e.g. /api/v1/users/:userId), never by raw path, to bound cardinality
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	MongoOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "mongo",
+		Name:      "operation_duration_seconds",
+		Help:      "MongoDB latency by repository and repository method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+
+	MessagesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "domain",
+		Name:      "messages_sent_total",
+		Help:      "Messages stored, by message type.",
+	}, []string{"type"})
+
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "domain",
+		Name:      "logins_total",
+		Help:      "Login attempts, by result.",
+	}, []string{"result"})
+
+	AuthorizationDenials = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "domain",
+		Name:      "authorization_denials_total",
+		Help:      "Requests denied by the authorization service, by action.",
+	}, []string{"action"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		MongoOperationDuration,
+		MessagesSent,
+		Logins,
+		AuthorizationDenials,
+	)
+}
+
+// Handler serves the Prometheus exposition format for Registry
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveMongo starts timing a repository method, call the returned func when the method returns:
+//
+//	defer metrics.ObserveMongo("UserRepository", "GetByID")()
+func ObserveMongo(repository string, method string) func() {
+	start := time.Now()
+	return func() {
+		MongoOperationDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
@@ -30,6 +30,20 @@ func NewAESEncryptionService(key string, log *zerolog.Logger) (IEncryptionServic
 	return &AESEncryptionService{key: keyBytes, log: log}, nil
 }
 
+// GenerateAESKey creates a random hex-encoded key of size bytes (16, 24 or 32) usable with NewAESEncryptionService
+func GenerateAESKey(size int) (string, error) {
+	switch size {
+	case 16, 24, 32:
+	default:
+		return "", errors.New("invalid AES key size")
+	}
+	key := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
 func (s *AESEncryptionService) Encrypt(plaintext string) (string, error) {
 	block, err := aes.NewCipher(s.key)
 	if err != nil {
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/rs/zerolog"
+	"strconv"
+	"time"
+)
+
+// IURLSigner signs resources for a limited time, the signature stands in for authentication
+// on URLs fetched without a token (e.g. by a CDN)
+type IURLSigner interface {
+	// Sign returns a hex-encoded signature of resource valid until expiresAt
+	Sign(resource string, expiresAt time.Time) string
+	// Verify reports whether signature was issued for resource & expiresAt, it does not check the expiry itself
+	Verify(resource string, expiresAt time.Time, signature string) bool
+}
+
+// hmacURLSigner implements IURLSigner using HMAC-SHA256.
+type hmacURLSigner struct {
+	secretKey []byte
+	log       *zerolog.Logger
+}
+
+// NewHMACURLSigner creates a URL signer keyed with the hex-encoded secretKeyHex, which needs at least 32 bytes.
+// Use a key of its own, a signature must not double as a search key.
+func NewHMACURLSigner(log *zerolog.Logger, secretKeyHex string) (IURLSigner, error) {
+	keyBytes, err := hex.DecodeString(secretKeyHex)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to decode URL signing key from hex")
+		return nil, errors.New("invalid URL signing key format")
+	}
+	const minKeyLength = 32
+	if len(keyBytes) < minKeyLength {
+		log.Error().Int("key_length", len(keyBytes)).Int("minimum_required", minKeyLength).Msg("URL signing key is too short")
+		return nil, errors.New("URL signing key provided is too short for security requirements")
+	}
+
+	serviceLogger := log.With().Str("service", "HMACURLSigner").Logger()
+	return &hmacURLSigner{secretKey: keyBytes, log: &serviceLogger}, nil
+}
+
+func (s *hmacURLSigner) Sign(resource string, expiresAt time.Time) string {
+	return hex.EncodeToString(s.mac(resource, expiresAt))
+}
+
+func (s *hmacURLSigner) Verify(resource string, expiresAt time.Time, signature string) bool {
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	// constant time, so the signature cannot be guessed byte by byte
+	return hmac.Equal(signatureBytes, s.mac(resource, expiresAt))
+}
+
+func (s *hmacURLSigner) mac(resource string, expiresAt time.Time) []byte {
+	mac := hmac.New(sha256.New, s.secretKey)
+	// the separator keeps a resource ending in digits from shifting into the expiry
+	_, _ = mac.Write([]byte(resource + "\x00" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return mac.Sum(nil)
+}
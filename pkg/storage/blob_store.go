@@ -0,0 +1,58 @@
+// Package storage stores the content of media files, their metadata is kept by the repositories.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ErrBlobNotFound is returned for keys that hold no blob
+var ErrBlobNotFound = errors.New("blob not found")
+
+// IBlobStore stores blobs under slash separated keys such as "media/<chatId>/<mediaId>"
+type IBlobStore interface {
+	// Put streams r into the blob stored under key, replacing any previous blob, & returns the number of bytes written.
+	// A failed Put leaves no partial blob behind.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error)
+	// Get opens the blob stored under key, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetRange opens length bytes of the blob stored under key from offset, the range must lie within the blob
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes the blob stored under key, deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+var keySegment = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// checkKey rejects keys that could escape the store's root, every segment must be a plain name
+func checkKey(key string) error {
+	for _, segment := range strings.Split(key, "/") {
+		if !keySegment.MatchString(segment) || strings.Contains(segment, "..") {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// contextReader stops reading once ctx is done, so an abandoned upload stops streaming
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// rangeReader reads a range of a blob & closes the whole blob
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,120 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/storage"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	log := zerolog.Nop()
+	store, err := storage.NewLocalBlobStore(&log, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBlobStore(t, store)
+}
+
+// TestS3BlobStore runs against the existing bucket S3_TEST_BUCKET at S3_TEST_ENDPOINT, e.g. a local minio
+func TestS3BlobStore(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+	log := zerolog.Nop()
+	store, err := storage.NewS3BlobStore(&log, storage.S3Options{
+		Endpoint:        endpoint,
+		Bucket:          os.Getenv("S3_TEST_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_TEST_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_TEST_SECRET_ACCESS_KEY"),
+		UseSSL:          os.Getenv("S3_TEST_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBlobStore(t, store)
+}
+
+// testBlobStore is the behaviour every IBlobStore shares
+func testBlobStore(t *testing.T, store storage.IBlobStore) {
+	ctx := context.Background()
+	key := "test/" + primitive.NewObjectID().Hex()
+	content := bytes.Repeat([]byte("telko"), 1000)
+
+	size, err := store.Put(ctx, key, bytes.NewReader(content), "application/octet-stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(content)) {
+		t.Fatalf("size: expected %d, got %d", len(content), size)
+	}
+	requireContent(t, store, key, content)
+
+	ranged, err := store.GetRange(ctx, key, 5, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := io.ReadAll(ranged)
+	_ = ranged.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(part) != "telkotelkote" {
+		t.Fatalf("range: expected %q, got %q", "telkotelkote", part)
+	}
+	if _, err = store.GetRange(ctx, "test/missing", 0, 1); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Fatalf("expected ErrBlobNotFound, got %v", err)
+	}
+
+	// a failing reader leaves nothing behind
+	failing := "test/" + primitive.NewObjectID().Hex()
+	if _, err = store.Put(ctx, failing, io.MultiReader(bytes.NewReader(content), errReader{}), "application/octet-stream"); err == nil {
+		t.Fatal("expected Put to fail with its reader")
+	}
+	if _, err = store.Get(ctx, failing); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Fatalf("expected ErrBlobNotFound after a failed Put, got %v", err)
+	}
+
+	for _, invalid := range []string{"", "../escape", "a//b", "/absolute"} {
+		if _, err = store.Put(ctx, invalid, bytes.NewReader(content), "application/octet-stream"); err == nil {
+			t.Fatalf("expected key %q to be rejected", invalid)
+		}
+	}
+
+	if err = store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Get(ctx, key); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Fatalf("expected ErrBlobNotFound, got %v", err)
+	}
+	// deleting is idempotent
+	if err = store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func requireContent(t *testing.T, store storage.IBlobStore, key string, want []byte) {
+	t.Helper()
+	r, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("content: expected %d bytes, got %d", len(want), len(got))
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("broken reader") }
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/rs/zerolog"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localBlobStore keeps blobs as files under a root directory, for development & single node deployments
+type localBlobStore struct {
+	iName string
+	log   *zerolog.Logger
+	root  string
+}
+
+// NewLocalBlobStore stores blobs under root, creating the directory when it does not exist
+func NewLocalBlobStore(log *zerolog.Logger, root string) (IBlobStore, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(root, 0o750); err != nil {
+		log.Error().Err(err).Str("root", root).Msg("Failed to create the blob store directory")
+		return nil, err
+	}
+	return &localBlobStore{
+		iName: "LocalBlobStore",
+		log:   log,
+		root:  root,
+	}, nil
+}
+
+func (l *localBlobStore) Put(ctx context.Context, key string, r io.Reader, _ string) (int64, error) {
+	const kName = "Put"
+	logger := logging.FromContext(ctx, l.log)
+
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		logger.Error().Interface(kName, l.iName).Err(err).Str("key", key).Msg("Failed to create blob directory")
+		return 0, err
+	}
+
+	// written to a temporary file first & renamed, readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		logger.Error().Interface(kName, l.iName).Err(err).Str("key", key).Msg("Failed to create temporary blob file")
+		return 0, err
+	}
+	written, err := io.Copy(tmp, contextReader{ctx: ctx, r: r})
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		logger.Error().Interface(kName, l.iName).Err(err).Str("key", key).Msg("Failed to write blob")
+		return 0, err
+	}
+	return written, nil
+}
+
+func (l *localBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (l *localBlobStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	blob, err := l.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	file := blob.(*os.File)
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return rangeReader{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+func (l *localBlobStore) Delete(ctx context.Context, key string) error {
+	const kName = "Delete"
+
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logging.FromContext(ctx, l.log).Error().Interface(kName, l.iName).Err(err).Str("key", key).Msg("Failed to delete blob")
+		return err
+	}
+	return nil
+}
+
+func (l *localBlobStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
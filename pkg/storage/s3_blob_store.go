@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/mcsamuelshoko/telko-moment-server/pkg/logging"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog"
+	"io"
+)
+
+// s3PartSize bounds the memory a streamed upload of unknown size buffers, 10000 parts allow objects up to ~160GB
+const s3PartSize = 16 << 20
+
+// S3Options address a bucket on AWS S3 or an S3 compatible server such as MinIO
+type S3Options struct {
+	Endpoint        string // host[:port] without scheme, e.g. s3.amazonaws.com or localhost:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+}
+
+// s3BlobStore keeps blobs as objects of a bucket
+type s3BlobStore struct {
+	iName  string
+	log    *zerolog.Logger
+	client *minio.Client
+	bucket string
+}
+
+// NewS3BlobStore stores blobs in the bucket of opts, the bucket must exist
+func NewS3BlobStore(log *zerolog.Logger, opts S3Options) (IBlobStore, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("the S3 blob store needs an endpoint & a bucket")
+	}
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, ""),
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("endpoint", opts.Endpoint).Msg("Failed to create S3 client")
+		return nil, err
+	}
+	return &s3BlobStore{
+		iName:  "S3BlobStore",
+		log:    log,
+		client: client,
+		bucket: opts.Bucket,
+	}, nil
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error) {
+	const kName = "Put"
+	if err := checkKey(key); err != nil {
+		return 0, err
+	}
+	// the size is unknown, the client uploads in parts & aborts the multipart upload on failure
+	info, err := s.client.PutObject(ctx, s.bucket, key, contextReader{ctx: ctx, r: r}, -1, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    s3PartSize,
+	})
+	if err != nil {
+		logging.FromContext(ctx, s.log).Error().Interface(kName, s.iName).Err(err).Str("key", key).Msg("Failed to put object")
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.getObject(ctx, "Get", key, minio.GetObjectOptions{})
+}
+
+func (s *s3BlobStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, err
+	}
+	return s.getObject(ctx, "GetRange", key, opts)
+}
+
+func (s *s3BlobStore) getObject(ctx context.Context, kName string, key string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, key, opts)
+	if err != nil {
+		return nil, s.mapError(ctx, kName, key, err)
+	}
+	// GetObject is lazy, stat to report missing objects now rather than on the first read
+	if _, err = object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, s.mapError(ctx, kName, key, err)
+	}
+	return object, nil
+}
+
+func (s *s3BlobStore) Delete(ctx context.Context, key string) error {
+	const kName = "Delete"
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	// S3 reports success for missing objects
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return s.mapError(ctx, kName, key, err)
+	}
+	return nil
+}
+
+func (s *s3BlobStore) mapError(ctx context.Context, kName string, key string, err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrBlobNotFound
+	}
+	logging.FromContext(ctx, s.log).Error().Interface(kName, s.iName).Err(err).Str("key", key).Msg("S3 request failed")
+	return err
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// Supported span exporters
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"   // OTLP over HTTP, configured with the standard OTEL_EXPORTER_OTLP_* variables
+	ExporterStdout = "stdout" // pretty printed spans for local debugging
+)
+
+// Span attribute keys shared by the middleware
+const (
+	AttributeRequestID = "http.request.id"
+	AttributeUserID    = "enduser.id"
+)
+
+const tracerName = "github.com/mcsamuelshoko/telko-moment-server"
+
+// Setup installs the global tracer provider & W3C trace context propagator.
+// The returned func flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, log *zerolog.Logger, serviceName string, exporter string, sampleRatio float64) (func(ctx context.Context) error, error) {
+	// always propagate, even when this service does not export, so traces stay connected across services
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone, "":
+		log.Info().Str("tracing", "Setup").Msg("tracing disabled")
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s span exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	log.Info().Str("tracing", "Setup").Str("exporter", exporter).Float64("sampleRatio", sampleRatio).Msg("tracing enabled")
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the server tracer from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a child span named component.method, e.g. UserService.CreateUser
+func Start(ctx context.Context, component string, method string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, component+"."+method)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"strings"
@@ -15,6 +16,21 @@ func GetHeaderMap(c *fiber.Ctx) map[string]string {
 	return headerMap
 }
 
+type headerMapContextKey struct{}
+
+// WithHeaderMap stores the request headers for handlers that only receive a context.Context (strict server handlers)
+func WithHeaderMap(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, headerMapContextKey{}, headers)
+}
+
+// HeaderMapFromContext returns the headers stored by WithHeaderMap, or an empty map when there are none
+func HeaderMapFromContext(ctx context.Context) map[string]string {
+	if headers, ok := ctx.Value(headerMapContextKey{}).(map[string]string); ok {
+		return headers
+	}
+	return map[string]string{}
+}
+
 // GetIP gets the client IP address
 func GetIP(c *fiber.Ctx) string {
 	return c.IP()
@@ -47,12 +63,13 @@ func GetUserAgent(c *fiber.Ctx) string {
 	return c.Get("User-Agent")
 }
 
-// GetRequestID gets or generates a request ID
+// GetRequestID gets or generates a request ID, the ID is echoed in the response & stable for the rest of the request
 func GetRequestID(c *fiber.Ctx) string {
-	requestID := c.Get("X-Request-ID")
+	requestID := c.Get(fiber.HeaderXRequestID)
 	if requestID == "" {
 		requestID = uuid.New().String()
-		c.Set("X-Request-ID", requestID)
+		c.Request().Header.Set(fiber.HeaderXRequestID, requestID)
 	}
+	c.Set(fiber.HeaderXRequestID, requestID)
 	return requestID
 }
@@ -38,3 +38,10 @@ func PaginatedResponse(data interface{}, page, pageSize, total int) fiber.Map {
 		},
 	}
 }
+
+// CodedErrorResponse creates a standardized error response with a stable machine-readable code
+func CodedErrorResponse(code string, message string, details ...interface{}) fiber.Map {
+	resp := ErrorResponse(message, details...)
+	resp["code"] = code
+	return resp
+}